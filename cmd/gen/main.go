@@ -0,0 +1,132 @@
+// gen 开发辅助命令
+//
+// 用法：
+//
+//	go run ./cmd/gen fixtures [-out test/testdata/fixtures]
+//	go run ./cmd/gen manifest [-format openapi|openai|agents] [-rest] [-out file]
+//
+// fixtures 根据每个内置工具的参数 Schema 生成合法与边界非法的示例参数，
+// 供 test 包中的表驱动校验测试使用。修改工具 Schema 后需重新生成。
+//
+// manifest 将全部内置工具导出为 OpenAPI 3.1 文档、OpenAI 函数调用清单或智能体框架使用的工具定义，
+// 与运行中服务器的 /mcp/manifest 端点格式相同（端点只包含当前启用的工具），未指定 -out 时写到标准输出；
+// -rest 为 OpenAPI 文档加入 REST 桥接的工具调用端点。
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/manifest"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/schema"
+	"Weave-Toolkit/internal/tools"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "fixtures":
+		if err := runFixtures(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "gen fixtures: %v\n", err)
+			os.Exit(1)
+		}
+	case "manifest":
+		if err := runManifest(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "gen manifest: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: gen fixtures [-out dir]")
+	fmt.Fprintln(os.Stderr, "       gen manifest [-format openapi|openai|agents] [-rest] [-out file]")
+}
+
+// runFixtures 为每个声明了 Schema 的工具写入一个示例参数文件
+func runFixtures(args []string) error {
+	flags := flag.NewFlagSet("fixtures", flag.ExitOnError)
+	out := flags.String("out", filepath.Join("test", "testdata", "fixtures"), "output directory")
+	flags.Parse(args)
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return err
+	}
+
+	for _, tool := range tools.BuiltinTools(&config.ToolManagerConfig{}) {
+		schemaTool, ok := tool.(tools.SchemaTool)
+		if !ok {
+			continue
+		}
+
+		fixtures, err := schema.GenerateFixtures(tool.Name(), schemaTool.InputSchema())
+		if err != nil {
+			return err
+		}
+		data, err := fixtures.Marshal()
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(*out, schema.FixtureFile(tool.Name()))
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("wrote %s (%d valid, %d invalid)\n", path, len(fixtures.Valid), len(fixtures.Invalid))
+	}
+
+	return nil
+}
+
+// runManifest 导出全部内置工具的清单
+func runManifest(args []string) error {
+	flags := flag.NewFlagSet("manifest", flag.ExitOnError)
+	format := flags.String("format", "openapi", "manifest format: openapi, openai or agents")
+	rest := flags.Bool("rest", false, "include REST bridge paths in the OpenAPI document")
+	out := flags.String("out", "", "output file, defaults to stdout")
+	flags.Parse(args)
+
+	var toolInfos []tools.ToolInfo
+	for _, tool := range tools.BuiltinTools(&config.ToolManagerConfig{}) {
+		toolInfos = append(toolInfos, tools.NewToolInfo(tool, true))
+	}
+
+	var doc interface{}
+	switch *format {
+	case "openapi":
+		info := manifest.Info{Title: "Weave-Toolkit", Version: "1.0.0"}
+		if *rest {
+			info.PathPrefix = mcp.RESTToolsPath
+		}
+		doc = manifest.OpenAPI(toolInfos, info)
+	case "openai":
+		doc = map[string]interface{}{"tools": manifest.OpenAI(toolInfos)}
+	case "agents":
+		doc = map[string]interface{}{"tools": manifest.Agents(toolInfos)}
+	default:
+		return fmt.Errorf("unsupported format: %s", *format)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0644)
+}
@@ -0,0 +1,131 @@
+// loadgen MCP 服务器压测工具
+//
+// 用法：
+//
+//	go run ./cmd/loadgen [-url http://127.0.0.1:8080/mcp] [-tool calculator] [-args '{...}']
+//	                     [-stream] [-c 16] [-n 0] [-d 10s] [-api-key key] [-profile-dir dir]
+//
+// 以 -c 个并发连接向运行中的服务器发送 tools/call 请求，-n 为请求总数（0 为持续 -d 时长），
+// -stream 请求 SSE 流式响应。结束后输出吞吐量与 p50/p90/p99 延迟，流式请求另外输出首个事件的延迟。
+//
+// -profile-dir 在压测前后从管理接口（-admin-url，默认与 -url 同源的 /admin）下载 allocs 剖析，
+// 用 go tool pprof -base 对比即可得到压测期间服务器的内存分配。
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"Weave-Toolkit/internal/loadgen"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "loadgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	target := flag.String("url", "http://127.0.0.1:8080/mcp", "MCP endpoint")
+	apiKey := flag.String("api-key", os.Getenv("MCP_API_KEY"), "API key, defaults to MCP_API_KEY")
+	tool := flag.String("tool", "calculator", "tool to call")
+	args := flag.String("args", `{"operation":"add","a":1,"b":2}`, "tool arguments as JSON")
+	stream := flag.Bool("stream", false, "request SSE streaming responses")
+	concurrency := flag.Int("c", 16, "concurrent requests")
+	requests := flag.Int("n", 0, "total requests, 0 runs for -d")
+	duration := flag.Duration("d", 10*time.Second, "test duration when -n is 0")
+	adminURL := flag.String("admin-url", "", "admin endpoint for profiles, defaults to /admin on the -url host")
+	adminKey := flag.String("admin-key", "", "admin API key, defaults to -api-key")
+	profileDir := flag.String("profile-dir", "", "download allocs profiles from before and after the run into this directory")
+	flag.Parse()
+
+	if !json.Valid([]byte(*args)) {
+		return fmt.Errorf("invalid -args JSON")
+	}
+	if *adminURL == "" {
+		parsed, err := url.Parse(*target)
+		if err != nil {
+			return err
+		}
+		*adminURL = parsed.Scheme + "://" + parsed.Host + "/admin"
+	}
+	if *adminKey == "" {
+		*adminKey = *apiKey
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	before := filepath.Join(*profileDir, "allocs-before.pb.gz")
+	after := filepath.Join(*profileDir, "allocs-after.pb.gz")
+	if *profileDir != "" {
+		if err := os.MkdirAll(*profileDir, 0755); err != nil {
+			return err
+		}
+		if err := fetchProfile(ctx, *adminURL, *adminKey, before); err != nil {
+			return err
+		}
+	}
+
+	report, err := loadgen.Run(ctx, loadgen.Options{
+		URL:         *target,
+		APIKey:      *apiKey,
+		Tool:        *tool,
+		Arguments:   json.RawMessage(*args),
+		Stream:      *stream,
+		Concurrency: *concurrency,
+		Requests:    *requests,
+		Duration:    *duration,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Print(report)
+
+	if *profileDir != "" {
+		if err := fetchProfile(context.Background(), *adminURL, *adminKey, after); err != nil {
+			return err
+		}
+		fmt.Printf("allocation profile: go tool pprof -sample_index=alloc_space -base %s %s\n", before, after)
+	}
+	return nil
+}
+
+// fetchProfile 从管理接口下载 allocs 剖析到文件
+func fetchProfile(ctx context.Context, adminURL, apiKey, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, adminURL+"/debug/pprof/allocs", nil)
+	if err != nil {
+		return err
+	}
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch allocs profile: unexpected status %d", resp.StatusCode)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
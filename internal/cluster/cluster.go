@@ -0,0 +1,160 @@
+// Package cluster 集群模式下多个副本共享的状态后端（Redis）
+//
+// 会话、流式事件缓冲、租户限流计数与异步任务状态保存在同一个 Redis 中，
+// 负载均衡器无需会话粘滞：任意副本都能续传其他副本产生的事件流、读取会话资源、查询与取消任务。
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultPrefix 默认键前缀
+const DefaultPrefix = "weave:"
+
+// connectTimeout 启动时连接 Redis 的超时时间
+const connectTimeout = 5 * time.Second
+
+// Client 集群共享状态客户端
+type Client struct {
+	rdb      *redis.Client
+	prefix   string
+	instance string
+}
+
+// Connect 连接 Redis 并校验可用性，url 形如 redis://[:password@]host:port/db
+//
+// 集群模式下共享状态不可用时各副本的行为将不一致，因此连接失败直接返回错误而不是降级为单机模式。
+func Connect(ctx context.Context, url, prefix string) (*Client, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster redis url: %v", err)
+	}
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	rdb := redis.NewClient(options)
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
+		return nil, fmt.Errorf("cluster redis unavailable at %s: %v", options.Addr, err)
+	}
+
+	return &Client{rdb: rdb, prefix: prefix, instance: newInstanceID()}, nil
+}
+
+// Instance 当前副本的标识
+func (c *Client) Instance() string {
+	return c.instance
+}
+
+// Close 关闭连接
+func (c *Client) Close() error {
+	return c.rdb.Close()
+}
+
+// Key 拼接带前缀的键名
+func (c *Client) Key(parts ...string) string {
+	return c.prefix + strings.Join(parts, ":")
+}
+
+// Put 写入值，ttl 为 0 表示不过期
+func (c *Client) Put(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.rdb.Set(ctx, c.Key(key), value, ttl).Err()
+}
+
+// Get 读取值，不存在时 found 为 false
+func (c *Client) Get(ctx context.Context, key string) (value []byte, found bool, err error) {
+	value, err = c.rdb.Get(ctx, c.Key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Delete 删除键，返回实际删除的数量
+func (c *Client) Delete(ctx context.Context, keys ...string) (int64, error) {
+	full := make([]string, len(keys))
+	for i, key := range keys {
+		full[i] = c.Key(key)
+	}
+	return c.rdb.Del(ctx, full...).Result()
+}
+
+// Touch 刷新键的过期时间
+func (c *Client) Touch(ctx context.Context, ttl time.Duration, keys ...string) error {
+	pipe := c.rdb.Pipeline()
+	for _, key := range keys {
+		pipe.Expire(ctx, c.Key(key), ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Scan 列出匹配模式的键（不含前缀）
+func (c *Client) Scan(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := c.rdb.Scan(ctx, 0, c.Key(pattern), 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, strings.TrimPrefix(iter.Val(), c.prefix))
+	}
+	return keys, iter.Err()
+}
+
+// allowScript 令牌桶限流，使用 Redis 服务器时间避免各副本时钟偏差
+//
+// KEYS[1] 令牌桶；ARGV[1] 容量；ARGV[2] 每毫秒补充的令牌数
+var allowScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate) + 1000)
+return allowed
+`)
+
+// Allow 在所有副本共享的令牌桶中消耗一次额度，实现 tools.RateLimiter
+func (c *Client) Allow(ctx context.Context, key string, perMinute int) (bool, error) {
+	if perMinute <= 0 {
+		return true, nil
+	}
+	allowed, err := allowScript.Run(ctx, c.rdb, []string{c.Key("ratelimit", key)}, perMinute, float64(perMinute)/60000).Int()
+	if err != nil {
+		return false, err
+	}
+	return allowed == 1, nil
+}
+
+// newInstanceID 生成副本标识：主机名与进程号
+func newInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
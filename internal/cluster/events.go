@@ -0,0 +1,240 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 事件流默认参数
+const (
+	defaultMaxEvents = 1000
+	defaultRetention = 5 * time.Minute
+	openStreamTTL    = 24 * time.Hour // 未结束事件流的保留时长，防止异常退出的副本遗留数据
+	maxBlock         = time.Second    // 单次阻塞读取的上限，以便及时响应上下文取消
+)
+
+// ErrStreamNotFound 事件流不存在或已过期
+var ErrStreamNotFound = errors.New("stream not found")
+
+// Event 事件流中的事件
+type Event struct {
+	ID    int64           `json:"id"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+	Time  time.Time       `json:"time"`
+}
+
+// EventLog 基于 Redis Stream 的事件流缓冲
+//
+// 每个事件流对应一个 Stream 与一个元数据哈希；事件 ID 即 Stream 条目 ID 的毫秒部分，
+// 从 1 开始连续递增，与单机模式的游标语义一致。结束标记是一条事件名为空的条目。
+type EventLog struct {
+	c         *Client
+	maxEvents int
+	retention time.Duration
+}
+
+// NewEventLog 创建事件流缓冲
+func NewEventLog(c *Client, maxEvents int, retention time.Duration) *EventLog {
+	if maxEvents <= 0 {
+		maxEvents = defaultMaxEvents
+	}
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+	return &EventLog{c: c, maxEvents: maxEvents, retention: retention}
+}
+
+// appendScript 原子地分配事件 ID 并追加事件
+//
+// KEYS[1] Stream；KEYS[2] 元数据；ARGV: 事件名、数据、时间、最大条数、过期毫秒数、是否为结束标记
+var appendScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	return false
+end
+if redis.call('HGET', KEYS[2], 'done') == '1' then
+	return 0
+end
+local id = redis.call('HINCRBY', KEYS[2], 'seq', 1)
+redis.call('XADD', KEYS[1], 'MAXLEN', ARGV[4], id .. '-0', 'event', ARGV[1], 'data', ARGV[2], 'time', ARGV[3])
+if ARGV[6] == '1' then
+	redis.call('HSET', KEYS[2], 'done', '1')
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[5])
+redis.call('PEXPIRE', KEYS[2], ARGV[5])
+return id
+`)
+
+// Open 为 owner 创建事件流，owner 记录在元数据中，Wait 据此拒绝其他持有者
+func (l *EventLog) Open(ctx context.Context, id, owner string) error {
+	meta := l.c.Key("stream", id, "meta")
+	pipe := l.c.rdb.TxPipeline()
+	pipe.HSet(ctx, meta, "seq", 0, "instance", l.c.instance, "owner", owner)
+	pipe.Expire(ctx, meta, openStreamTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Append 追加事件，超过容量时淘汰最早的事件
+func (l *EventLog) Append(ctx context.Context, id, event string, data json.RawMessage) (Event, error) {
+	now := time.Now()
+	seq, err := l.append(ctx, id, event, data, now, false, openStreamTTL)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{ID: seq, Event: event, Data: data, Time: now}, nil
+}
+
+// Close 写入结束标记，结束后的事件流保留 retention 时长
+func (l *EventLog) Close(ctx context.Context, id string) error {
+	_, err := l.append(ctx, id, "", nil, time.Now(), true, l.retention)
+	return err
+}
+
+// Remove 删除事件流及其元数据，返回删除的事件流数
+func (l *EventLog) Remove(ctx context.Context, ids ...string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	streams := make([]string, len(ids))
+	metas := make([]string, len(ids))
+	for i, id := range ids {
+		streams[i], metas[i] = l.c.Key("stream", id, "events"), l.c.Key("stream", id, "meta")
+	}
+	pipe := l.c.rdb.TxPipeline()
+	pipe.Del(ctx, streams...)
+	removed := pipe.Del(ctx, metas...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int(removed.Val()), nil
+}
+
+// append 执行追加脚本
+func (l *EventLog) append(ctx context.Context, id, event string, data json.RawMessage, at time.Time, done bool, ttl time.Duration) (int64, error) {
+	flag := "0"
+	if done {
+		flag = "1"
+	}
+	keys := []string{l.c.Key("stream", id, "events"), l.c.Key("stream", id, "meta")}
+	seq, err := appendScript.Run(ctx, l.c.rdb, keys,
+		event, string(data), at.UnixNano(), l.maxEvents, ttl.Milliseconds(), flag).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, fmt.Errorf("%w: %s", ErrStreamNotFound, id)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if seq == 0 && !done {
+		return 0, fmt.Errorf("stream already closed: %s", id)
+	}
+	return seq, nil
+}
+
+// Since 获取游标之后的事件，truncated 表示游标之后的部分事件已被淘汰
+func (l *EventLog) Since(ctx context.Context, id string, cursor int64) (events []Event, done bool, truncated bool, err error) {
+	exists, err := l.c.rdb.Exists(ctx, l.c.Key("stream", id, "meta")).Result()
+	if err != nil {
+		return nil, false, false, err
+	}
+	if exists == 0 {
+		return nil, false, false, fmt.Errorf("%w: %s", ErrStreamNotFound, id)
+	}
+
+	entries, err := l.c.rdb.XRange(ctx, l.c.Key("stream", id, "events"), streamID(cursor+1), "+").Result()
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	events = []Event{}
+	for i, entry := range entries {
+		evt := decodeEntry(entry)
+		if i == 0 && evt.ID > cursor+1 {
+			truncated = true
+		}
+		// 结束标记只出现在最后，以它判断结束可避免读到结束状态却漏掉其前的事件
+		if evt.Event == "" {
+			done = true
+			continue
+		}
+		events = append(events, evt)
+	}
+	return events, done, truncated, nil
+}
+
+// Wait 等待游标之后的事件，直到有新事件、流结束、超时或上下文取消；
+// 事件流属于其他持有者时与不存在的事件流同样处理
+func (l *EventLog) Wait(ctx context.Context, id, owner string, cursor int64, wait time.Duration) ([]Event, bool, bool, error) {
+	deadline := time.Now().Add(wait)
+	key := l.c.Key("stream", id, "events")
+
+	current, err := l.c.rdb.HGet(ctx, l.c.Key("stream", id, "meta"), "owner").Result()
+	if errors.Is(err, redis.Nil) || err == nil && current != owner {
+		return nil, false, false, fmt.Errorf("%w: %s", ErrStreamNotFound, id)
+	}
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	for {
+		events, done, truncated, err := l.Since(ctx, id, cursor)
+		if err != nil || len(events) > 0 || done {
+			return events, done, truncated, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return []Event{}, false, false, nil
+		}
+		if remaining > maxBlock {
+			remaining = maxBlock
+		}
+		if remaining < time.Millisecond {
+			// Block 为 0 表示无限期阻塞
+			remaining = time.Millisecond
+		}
+
+		// 阻塞读取仅用于唤醒，事件本身由下一轮 Since 统一读取
+		err = l.c.rdb.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{key, streamID(cursor)},
+			Count:   1,
+			Block:   remaining,
+		}).Err()
+		if ctx.Err() != nil {
+			return nil, false, false, ctx.Err()
+		}
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, false, false, err
+		}
+	}
+}
+
+// streamID 事件 ID 对应的 Stream 条目 ID
+func streamID(id int64) string {
+	return strconv.FormatInt(id, 10) + "-0"
+}
+
+// decodeEntry 将 Stream 条目还原为事件
+func decodeEntry(entry redis.XMessage) Event {
+	evt := Event{}
+	if ms, _, ok := strings.Cut(entry.ID, "-"); ok {
+		evt.ID, _ = strconv.ParseInt(ms, 10, 64)
+	}
+	evt.Event, _ = entry.Values["event"].(string)
+	if data, _ := entry.Values["data"].(string); data != "" {
+		evt.Data = json.RawMessage(data)
+	}
+	if ts, _ := entry.Values["time"].(string); ts != "" {
+		if nanos, err := strconv.ParseInt(ts, 10, 64); err == nil {
+			evt.Time = time.Unix(0, nanos)
+		}
+	}
+	return evt
+}
@@ -0,0 +1,131 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"Weave-Toolkit/internal/jobs"
+)
+
+// JobStore 集群共享的任务存储，实现 jobs.Store
+//
+// 任务以 JSON 保存在 job:<ID> 中，并按创建时间登记在有序集合 jobs 中以便列出；
+// 取消请求通过发布订阅频道转发给执行任务的副本。
+type JobStore struct {
+	c *Client
+}
+
+// NewJobStore 创建任务存储
+func NewJobStore(c *Client) *JobStore {
+	return &JobStore{c: c}
+}
+
+// Save 保存任务状态
+func (s *JobStore) Save(ctx context.Context, job jobs.Job, ttl time.Duration) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	pipe := s.c.rdb.TxPipeline()
+	pipe.Set(ctx, s.c.Key("job", job.ID), data, ttl)
+	pipe.ZAdd(ctx, s.c.Key("jobs"), redis.Z{Score: float64(job.CreatedAt.UnixNano()), Member: job.ID})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Load 获取任务，不存在或已过期时 found 为 false
+func (s *JobStore) Load(ctx context.Context, id string) (*jobs.Job, bool, error) {
+	data, found, err := s.c.Get(ctx, "job:"+id)
+	if err != nil || !found {
+		return nil, false, err
+	}
+
+	var job jobs.Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, false, err
+	}
+	return &job, true, nil
+}
+
+// List 列出所有未过期的任务，顺带清理索引中已过期的任务
+func (s *JobStore) List(ctx context.Context) ([]jobs.Job, error) {
+	ids, err := s.c.rdb.ZRange(ctx, s.c.Key("jobs"), 0, -1).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = s.c.Key("job", id)
+	}
+	values, err := s.c.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]jobs.Job, 0, len(values))
+	var expired []interface{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+		var job jobs.Job
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			continue
+		}
+		list = append(list, job)
+	}
+	if len(expired) > 0 {
+		s.c.rdb.ZRem(ctx, s.c.Key("jobs"), expired...)
+	}
+	return list, nil
+}
+
+// Delete 删除任务
+func (s *JobStore) Delete(ctx context.Context, id string) error {
+	pipe := s.c.rdb.TxPipeline()
+	pipe.Del(ctx, s.c.Key("job", id))
+	pipe.ZRem(ctx, s.c.Key("jobs"), id)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RequestCancel 广播取消请求
+func (s *JobStore) RequestCancel(ctx context.Context, id string) error {
+	return s.c.rdb.Publish(ctx, s.c.Key("jobs", "cancel"), id).Err()
+}
+
+// Cancellations 订阅取消请求，上下文取消后通道关闭
+func (s *JobStore) Cancellations(ctx context.Context) <-chan string {
+	ch := make(chan string)
+	sub := s.c.rdb.Subscribe(ctx, s.c.Key("jobs", "cancel"))
+
+	go func() {
+		defer close(ch)
+		defer sub.Close()
+
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case ch <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,150 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultLeaseTTL 默认领导者租约时长
+const defaultLeaseTTL = 15 * time.Second
+
+// renewScript 续约，仅当租约仍由本副本持有时生效
+var renewScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript 释放租约，仅当租约仍由本副本持有时生效
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Leader 基于 Redis 租约的领导者选举，同一名称在集群中同一时刻至多一个副本当选
+//
+// 当选副本每隔租约的三分之一续约；副本退出或与 Redis 失联后租约过期，其他副本在下一次竞选时接任。
+// 需要全局唯一执行的后台任务（如定时调度）应在 Run 的回调中执行，并在回调的上下文取消时停止。
+type Leader struct {
+	c   *Client
+	key string
+	ttl time.Duration
+
+	mu     sync.RWMutex
+	leader bool
+}
+
+// NewLeader 创建指定名称的领导者选举，ttl 不大于 0 时使用默认租约时长
+func (c *Client) NewLeader(name string, ttl time.Duration) *Leader {
+	if ttl <= 0 {
+		ttl = defaultLeaseTTL
+	}
+	return &Leader{c: c, key: c.Key("leader", name), ttl: ttl}
+}
+
+// IsLeader 本副本当前是否持有租约
+func (l *Leader) IsLeader() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.leader
+}
+
+// Run 持续竞选直到上下文取消；每次当选时以新的上下文调用 onElected，失去租约时取消该上下文
+func (l *Leader) Run(ctx context.Context, onElected func(ctx context.Context)) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	var current *leaderTerm
+	stepDown := func() {
+		if current == nil {
+			return
+		}
+		current.cancel()
+		<-current.done
+		current = nil
+		l.setLeader(false)
+	}
+	defer func() {
+		stepDown()
+		// 主动释放租约，其他副本无需等待租约过期即可接任
+		release, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		releaseScript.Run(release, l.c.rdb, []string{l.key}, l.c.instance)
+	}()
+
+	for {
+		held := l.campaign(ctx, current != nil)
+		switch {
+		case held && current == nil:
+			current = startTerm(ctx, onElected)
+			l.setLeader(true)
+		case !held && current != nil:
+			stepDown()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Every 返回在任期内按间隔执行 task 的当选回调：当选时先执行一次，任期结束时停止
+func Every(interval time.Duration, task func(ctx context.Context)) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			task(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// leaderTerm 一次任期，取消后等待回调返回
+type leaderTerm struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startTerm 开始新的任期并在后台执行回调
+func startTerm(ctx context.Context, onElected func(ctx context.Context)) *leaderTerm {
+	ctx, cancel := context.WithCancel(ctx)
+	term := &leaderTerm{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(term.done)
+		onElected(ctx)
+	}()
+	return term
+}
+
+// campaign 已当选时续约，否则尝试获取租约，返回本副本是否持有租约
+func (l *Leader) campaign(ctx context.Context, leading bool) bool {
+	ctx, cancel := context.WithTimeout(ctx, l.ttl/3)
+	defer cancel()
+
+	if leading {
+		renewed, err := renewScript.Run(ctx, l.c.rdb, []string{l.key}, l.c.instance, l.ttl.Milliseconds()).Int()
+		return err == nil && renewed == 1
+	}
+	acquired, err := l.c.rdb.SetNX(ctx, l.key, l.c.instance, l.ttl).Result()
+	return err == nil && acquired
+}
+
+// setLeader 更新当选状态
+func (l *Leader) setLeader(leader bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.leader = leader
+}
@@ -0,0 +1,246 @@
+// Package envelope 工具参数的信封加密
+//
+// 客户端使用服务器公布的 P-256 公钥（JWK）以 JWE 紧凑序列化加密工具参数：
+// alg 为 ECDH-ES（直接密钥协商，Concat KDF 派生内容密钥），enc 为 A256GCM，
+// 可直接使用常见的 JOSE 库生成。服务器仅在执行工具前于内存中解密，
+// 日志、调用历史与任务持久化中只出现密文。
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// JWE 参数
+const (
+	Algorithm  = "ECDH-ES"
+	Encryption = "A256GCM"
+	curveP256  = "P-256"
+	keyTypeEC  = "EC"
+
+	cekSize   = 32
+	ivSize    = 12
+	tagSize   = 16
+	coordSize = 32
+)
+
+// envelopeField 工具参数中承载密文的字段，参数对象仅包含该字段时视为加密参数
+const envelopeField = "$jwe"
+
+// ErrDecrypt 密文无法解密（格式错误、密钥不匹配或被篡改）
+var ErrDecrypt = werrors.New(werrors.KindInvalidParams, "failed to decrypt arguments")
+
+var b64 = base64.RawURLEncoding
+
+// JWK P-256 公钥
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+}
+
+// JWKSet JWK 集合，/.well-known/jwks.json 的响应格式
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// header JWE 受保护头
+type header struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc"`
+	Kid string `json:"kid,omitempty"`
+	Epk *JWK   `json:"epk"`
+	Apu string `json:"apu,omitempty"`
+	Apv string `json:"apv,omitempty"`
+}
+
+// publicKey 将 JWK 转换为 ECDH 公钥，同时校验点在曲线上
+func (k JWK) publicKey() (*ecdh.PublicKey, error) {
+	if k.Kty != keyTypeEC || k.Crv != curveP256 {
+		return nil, fmt.Errorf("unsupported key type %s/%s", k.Kty, k.Crv)
+	}
+	x, errX := b64.DecodeString(k.X)
+	y, errY := b64.DecodeString(k.Y)
+	if errX != nil || errY != nil || len(x) != coordSize || len(y) != coordSize {
+		return nil, fmt.Errorf("invalid key coordinates")
+	}
+	point := append([]byte{4}, append(x, y...)...)
+	return ecdh.P256().NewPublicKey(point)
+}
+
+// newJWK 由 ECDH 公钥构造 JWK
+func newJWK(pub *ecdh.PublicKey) JWK {
+	point := pub.Bytes() // 0x04 || X || Y
+	return JWK{
+		Kty: keyTypeEC,
+		Crv: curveP256,
+		X:   b64.EncodeToString(point[1 : 1+coordSize]),
+		Y:   b64.EncodeToString(point[1+coordSize:]),
+	}
+}
+
+// Thumbprint RFC 7638 JWK 指纹，用作 kid
+func (k JWK) Thumbprint() string {
+	canonical := fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, k.Crv, k.Kty, k.X, k.Y)
+	sum := sha256.Sum256([]byte(canonical))
+	return b64.EncodeToString(sum[:])
+}
+
+// Wrap 将 JWE 包装为工具参数
+func Wrap(compact string) json.RawMessage {
+	data, _ := json.Marshal(map[string]string{envelopeField: compact})
+	return data
+}
+
+// Unwrap 判断工具参数是否为加密参数并取出 JWE
+func Unwrap(args json.RawMessage) (string, bool) {
+	if len(args) == 0 || !strings.Contains(string(args), envelopeField) {
+		return "", false
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(args, &fields); err != nil || len(fields) != 1 {
+		return "", false
+	}
+	var compact string
+	if err := json.Unmarshal(fields[envelopeField], &compact); err != nil {
+		return "", false
+	}
+	return compact, true
+}
+
+// Encrypt 使用接收方公钥加密，供 Go 客户端与测试使用
+func Encrypt(recipient JWK, plaintext []byte) (string, error) {
+	pub, err := recipient.publicKey()
+	if err != nil {
+		return "", err
+	}
+	ephemeral, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	z, err := ephemeral.ECDH(pub)
+	if err != nil {
+		return "", err
+	}
+
+	epk := newJWK(ephemeral.PublicKey())
+	protected, err := json.Marshal(header{Alg: Algorithm, Enc: Encryption, Kid: recipient.Kid, Epk: &epk})
+	if err != nil {
+		return "", err
+	}
+	encodedHeader := b64.EncodeToString(protected)
+
+	gcm, err := newGCM(concatKDF(z, Encryption, nil, nil))
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, ivSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(encodedHeader))
+	ciphertext, tag := sealed[:len(sealed)-tagSize], sealed[len(sealed)-tagSize:]
+
+	return strings.Join([]string{
+		encodedHeader,
+		"",
+		b64.EncodeToString(iv),
+		b64.EncodeToString(ciphertext),
+		b64.EncodeToString(tag),
+	}, "."), nil
+}
+
+// decrypt 使用私钥解密 JWE 紧凑序列化
+func decrypt(priv *ecdh.PrivateKey, kid, compact string) ([]byte, error) {
+	parts := strings.Split(compact, ".")
+	if len(parts) != 5 || parts[1] != "" {
+		return nil, fmt.Errorf("%w: malformed JWE", ErrDecrypt)
+	}
+
+	rawHeader, err := b64.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid header encoding", ErrDecrypt)
+	}
+	var h header
+	if err := json.Unmarshal(rawHeader, &h); err != nil {
+		return nil, fmt.Errorf("%w: invalid header", ErrDecrypt)
+	}
+	if h.Alg != Algorithm || h.Enc != Encryption {
+		return nil, fmt.Errorf("%w: unsupported alg/enc %s/%s", ErrDecrypt, h.Alg, h.Enc)
+	}
+	if h.Kid != "" && h.Kid != kid {
+		return nil, fmt.Errorf("%w: unknown key %s", ErrDecrypt, h.Kid)
+	}
+	if h.Epk == nil {
+		return nil, fmt.Errorf("%w: missing epk", ErrDecrypt)
+	}
+	epk, err := h.Epk.publicKey()
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid epk: %v", ErrDecrypt, err)
+	}
+	apu, errU := b64.DecodeString(h.Apu)
+	apv, errV := b64.DecodeString(h.Apv)
+	if errU != nil || errV != nil {
+		return nil, fmt.Errorf("%w: invalid apu/apv", ErrDecrypt)
+	}
+
+	iv, errIV := b64.DecodeString(parts[2])
+	ciphertext, errCT := b64.DecodeString(parts[3])
+	tag, errTag := b64.DecodeString(parts[4])
+	if errIV != nil || errCT != nil || errTag != nil || len(iv) != ivSize || len(tag) != tagSize {
+		return nil, fmt.Errorf("%w: malformed JWE", ErrDecrypt)
+	}
+
+	z, err := priv.ECDH(epk)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key agreement failed", ErrDecrypt)
+	}
+	gcm, err := newGCM(concatKDF(z, Encryption, apu, apv))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: authentication failed", ErrDecrypt)
+	}
+	return plaintext, nil
+}
+
+// concatKDF RFC 7518 4.6.2 中 ECDH-ES 直接协商使用的 Concat KDF（SHA-256，单轮即可得到 256 位密钥）
+func concatKDF(z []byte, algorithmID string, apu, apv []byte) []byte {
+	lengthPrefixed := func(b []byte) []byte {
+		out := binary.BigEndian.AppendUint32(nil, uint32(len(b)))
+		return append(out, b...)
+	}
+
+	h := sha256.New()
+	h.Write([]byte{0, 0, 0, 1}) // 轮次计数
+	h.Write(z)
+	h.Write(lengthPrefixed([]byte(algorithmID)))
+	h.Write(lengthPrefixed(apu))
+	h.Write(lengthPrefixed(apv))
+	h.Write(binary.BigEndian.AppendUint32(nil, cekSize*8)) // SuppPubInfo：密钥位数
+	return h.Sum(nil)[:cekSize]
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,118 @@
+package envelope
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"Weave-Toolkit/internal/platform"
+)
+
+// pemPrivateKey PKCS#8 私钥的 PEM 类型
+const pemPrivateKey = "PRIVATE KEY"
+
+// KeyPair 服务器解密密钥
+type KeyPair struct {
+	priv *ecdh.PrivateKey
+	jwk  JWK
+}
+
+// Generate 生成新的 P-256 密钥
+func Generate() (*KeyPair, error) {
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return newKeyPair(priv), nil
+}
+
+// LoadOrCreate 读取 PEM 格式的 PKCS#8 私钥，文件不存在时生成并以 0600 权限保存
+func LoadOrCreate(path string) (*KeyPair, error) {
+	path, err := platform.NormalizePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		key, err := Generate()
+		if err != nil {
+			return nil, err
+		}
+		if err := key.save(path); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key: %v", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemPrivateKey {
+		return nil, fmt.Errorf("encryption key %s: expected PEM %q block", path, pemPrivateKey)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key %s: %v", path, err)
+	}
+
+	var priv *ecdh.PrivateKey
+	switch k := parsed.(type) {
+	case *ecdsa.PrivateKey:
+		priv, err = k.ECDH()
+	case *ecdh.PrivateKey:
+		priv = k
+	default:
+		err = fmt.Errorf("unsupported key type %T", parsed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encryption key %s: %v", path, err)
+	}
+	if priv.Curve() != ecdh.P256() {
+		return nil, fmt.Errorf("encryption key %s: only P-256 keys are supported", path)
+	}
+	return newKeyPair(priv), nil
+}
+
+func newKeyPair(priv *ecdh.PrivateKey) *KeyPair {
+	jwk := newJWK(priv.PublicKey())
+	jwk.Kid = jwk.Thumbprint()
+	jwk.Use = "enc"
+	jwk.Alg = Algorithm
+	return &KeyPair{priv: priv, jwk: jwk}
+}
+
+// save 以 PEM 格式写入私钥
+func (k *KeyPair) save(path string) error {
+	der, err := x509.MarshalPKCS8PrivateKey(k.priv)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: pemPrivateKey, Bytes: der}), 0600)
+}
+
+// PublicJWK 公布给客户端的公钥
+func (k *KeyPair) PublicJWK() JWK {
+	return k.jwk
+}
+
+// KeyID 公钥指纹
+func (k *KeyPair) KeyID() string {
+	return k.jwk.Kid
+}
+
+// Decrypt 解密 JWE 紧凑序列化，头中的 kid 若存在必须与本密钥一致
+func (k *KeyPair) Decrypt(compact string) ([]byte, error) {
+	return decrypt(k.priv, k.jwk.Kid, compact)
+}
@@ -0,0 +1,189 @@
+// Package errors 定义工具与请求处理共用的错误分类
+//
+// 错误分类决定返回给客户端的 JSON-RPC 错误码、HTTP 状态码与 gRPC 状态码，
+// 各传输层按 KindOf 的结果映射，不再逐个比较哨兵错误或匹配错误文本。
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Kind 错误分类
+type Kind string
+
+// 错误分类
+const (
+	KindInternal        Kind = "internal"         // 未分类的执行失败
+	KindNotFound        Kind = "not_found"        // 工具、资源或数据不存在
+	KindInvalidParams   Kind = "invalid_params"   // 参数缺失、类型不正确或无法解密
+	KindTimeout         Kind = "timeout"          // 超过截止时间
+	KindRateLimited     Kind = "rate_limited"     // 超过调用限额
+	KindUnauthorized    Kind = "unauthorized"     // 未鉴权或无权访问
+	KindUnavailable     Kind = "unavailable"      // 熔断中等暂时不可用，可稍后重试
+	KindUpstreamFailure Kind = "upstream_failure" // 依赖的外部服务返回错误
+)
+
+// 工具调用的 JSON-RPC 错误码，不在此列出的分类使用标准错误码
+const (
+	CodeUnavailable     = -32002
+	CodeTimeout         = -32003
+	CodeRateLimited     = -32004
+	CodeUnauthorized    = -32005
+	CodeUpstreamFailure = -32006
+)
+
+// RPCCode 分类对应的 JSON-RPC 错误码，不存在按 MCP 约定视为参数错误
+func (k Kind) RPCCode() int {
+	switch k {
+	case KindNotFound, KindInvalidParams:
+		return -32602
+	case KindTimeout:
+		return CodeTimeout
+	case KindRateLimited:
+		return CodeRateLimited
+	case KindUnauthorized:
+		return CodeUnauthorized
+	case KindUnavailable:
+		return CodeUnavailable
+	case KindUpstreamFailure:
+		return CodeUpstreamFailure
+	default:
+		return -32603
+	}
+}
+
+// HTTPStatus 分类对应的 HTTP 状态码
+func (k Kind) HTTPStatus() int {
+	switch k {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindInvalidParams:
+		return http.StatusBadRequest
+	case KindTimeout:
+		return http.StatusGatewayTimeout
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	case KindUnauthorized:
+		return http.StatusForbidden
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	case KindUpstreamFailure:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GRPCCode 分类对应的 gRPC 状态码
+func (k Kind) GRPCCode() codes.Code {
+	switch k {
+	case KindNotFound:
+		return codes.NotFound
+	case KindInvalidParams:
+		return codes.InvalidArgument
+	case KindTimeout:
+		return codes.DeadlineExceeded
+	case KindRateLimited:
+		return codes.ResourceExhausted
+	case KindUnauthorized:
+		return codes.PermissionDenied
+	case KindUnavailable, KindUpstreamFailure:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// Error 带分类的错误
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error // 原因，可为空
+}
+
+// Error 实现 error
+func (e *Error) Error() string {
+	switch {
+	case e.Err == nil:
+		return e.Message
+	case e.Message == "":
+		return e.Err.Error()
+	default:
+		return e.Message + ": " + e.Err.Error()
+	}
+}
+
+// Unwrap 返回原因
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New 创建带分类的错误，常用作包级哨兵错误，可再以 fmt.Errorf("%w: ...") 附加细节
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Errorf 按格式创建带分类的错误，格式中可使用 %w 包装原因
+func Errorf(kind Kind, format string, args ...any) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap 为错误标记分类，err 为 nil 时返回 nil
+func Wrap(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// NotFound 创建不存在错误
+func NotFound(format string, args ...any) error {
+	return Errorf(KindNotFound, format, args...)
+}
+
+// InvalidParams 创建参数错误
+func InvalidParams(format string, args ...any) error {
+	return Errorf(KindInvalidParams, format, args...)
+}
+
+// Timeout 创建超时错误
+func Timeout(format string, args ...any) error {
+	return Errorf(KindTimeout, format, args...)
+}
+
+// RateLimited 创建超过调用限额错误
+func RateLimited(format string, args ...any) error {
+	return Errorf(KindRateLimited, format, args...)
+}
+
+// Unauthorized 创建无权访问错误
+func Unauthorized(format string, args ...any) error {
+	return Errorf(KindUnauthorized, format, args...)
+}
+
+// UpstreamFailure 创建上游服务失败错误
+func UpstreamFailure(format string, args ...any) error {
+	return Errorf(KindUpstreamFailure, format, args...)
+}
+
+// KindOf 获取错误链中最外层的分类；未分类的截止时间错误视为超时，其余为 KindInternal
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindTimeout
+	}
+	return KindInternal
+}
+
+// Is 错误是否属于指定分类
+func Is(err error, kind Kind) bool {
+	return err != nil && KindOf(err) == kind
+}
@@ -0,0 +1,441 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// function 内置函数，maxArgs 为 -1 表示参数个数不限；decimal 为空表示十进制模式不支持
+type function struct {
+	minArgs, maxArgs int
+	float            func(args []float64, degrees bool) (float64, error)
+	decimal          func(args []*big.Rat) (*big.Rat, error)
+}
+
+func (f function) checkArity(name string, n int) error {
+	switch {
+	case f.minArgs == f.maxArgs && n != f.minArgs:
+		return fmt.Errorf("expression: %s expects %d arguments, got %d", name, f.minArgs, n)
+	case n < f.minArgs:
+		return fmt.Errorf("expression: %s expects at least %d arguments, got %d", name, f.minArgs, n)
+	case f.maxArgs >= 0 && n > f.maxArgs:
+		return fmt.Errorf("expression: %s expects at most %d arguments, got %d", name, f.maxArgs, n)
+	}
+	return nil
+}
+
+var functions map[string]function
+
+func init() {
+	unary := func(f func(float64) float64) func([]float64, bool) (float64, error) {
+		return func(args []float64, _ bool) (float64, error) { return f(args[0]), nil }
+	}
+	// trig 的参数为角度，inverse 的结果为角度，degrees 时按度换算
+	trig := func(f func(float64) float64) func([]float64, bool) (float64, error) {
+		return func(args []float64, degrees bool) (float64, error) {
+			x := args[0]
+			if degrees {
+				x = x * math.Pi / 180
+			}
+			return f(x), nil
+		}
+	}
+	inverse := func(f func(float64) float64) func([]float64, bool) (float64, error) {
+		return func(args []float64, degrees bool) (float64, error) {
+			y := f(args[0])
+			if degrees {
+				y = y * 180 / math.Pi
+			}
+			return y, nil
+		}
+	}
+
+	functions = map[string]function{
+		"sin":   {minArgs: 1, maxArgs: 1, float: trig(math.Sin)},
+		"cos":   {minArgs: 1, maxArgs: 1, float: trig(math.Cos)},
+		"tan":   {minArgs: 1, maxArgs: 1, float: trig(math.Tan)},
+		"asin":  {minArgs: 1, maxArgs: 1, float: inverse(math.Asin)},
+		"acos":  {minArgs: 1, maxArgs: 1, float: inverse(math.Acos)},
+		"atan":  {minArgs: 1, maxArgs: 1, float: inverse(math.Atan)},
+		"sinh":  {minArgs: 1, maxArgs: 1, float: unary(math.Sinh)},
+		"cosh":  {minArgs: 1, maxArgs: 1, float: unary(math.Cosh)},
+		"tanh":  {minArgs: 1, maxArgs: 1, float: unary(math.Tanh)},
+		"exp":   {minArgs: 1, maxArgs: 1, float: unary(math.Exp)},
+		"ln":    {minArgs: 1, maxArgs: 1, float: unary(math.Log)},
+		"log2":  {minArgs: 1, maxArgs: 1, float: unary(math.Log2)},
+		"log10": {minArgs: 1, maxArgs: 1, float: unary(math.Log10)},
+		"cbrt":  {minArgs: 1, maxArgs: 1, float: unary(math.Cbrt)},
+		"atan2": {minArgs: 2, maxArgs: 2, float: func(args []float64, degrees bool) (float64, error) {
+			y := math.Atan2(args[0], args[1])
+			if degrees {
+				y = y * 180 / math.Pi
+			}
+			return y, nil
+		}},
+		"hypot": {minArgs: 2, maxArgs: 2, float: func(args []float64, _ bool) (float64, error) {
+			return math.Hypot(args[0], args[1]), nil
+		}},
+		// log(x) 为常用对数，log(x, b) 以 b 为底
+		"log": {minArgs: 1, maxArgs: 2, float: func(args []float64, _ bool) (float64, error) {
+			if len(args) == 2 {
+				return math.Log(args[0]) / math.Log(args[1]), nil
+			}
+			return math.Log10(args[0]), nil
+		}},
+		"sqrt": {minArgs: 1, maxArgs: 1, float: unary(math.Sqrt), decimal: decimalSqrt},
+		"pow": {minArgs: 2, maxArgs: 2, float: func(args []float64, _ bool) (float64, error) {
+			return math.Pow(args[0], args[1]), nil
+		}, decimal: func(args []*big.Rat) (*big.Rat, error) {
+			return decimalPow(args[0], args[1])
+		}},
+		"abs": {minArgs: 1, maxArgs: 1, float: unary(math.Abs), decimal: func(args []*big.Rat) (*big.Rat, error) {
+			return new(big.Rat).Abs(args[0]), nil
+		}},
+		"floor": {minArgs: 1, maxArgs: 1, float: unary(math.Floor), decimal: func(args []*big.Rat) (*big.Rat, error) {
+			return decimalFloor(args[0]), nil
+		}},
+		"ceil": {minArgs: 1, maxArgs: 1, float: unary(math.Ceil), decimal: func(args []*big.Rat) (*big.Rat, error) {
+			return decimalCeil(args[0]), nil
+		}},
+		"trunc": {minArgs: 1, maxArgs: 1, float: unary(math.Trunc), decimal: func(args []*big.Rat) (*big.Rat, error) {
+			return decimalTrunc(args[0]), nil
+		}},
+		// round(x) 四舍五入到整数（.5 远离零），round(x, n) 保留 n 位小数
+		"round": {minArgs: 1, maxArgs: 2, float: func(args []float64, _ bool) (float64, error) {
+			if len(args) == 1 {
+				return math.Round(args[0]), nil
+			}
+			digits, err := integerArg("round", args[1], 0, 15)
+			if err != nil {
+				return 0, err
+			}
+			scale := math.Pow10(digits)
+			return math.Round(args[0]*scale) / scale, nil
+		}, decimal: func(args []*big.Rat) (*big.Rat, error) {
+			if len(args) == 1 {
+				return decimalRound(args[0]), nil
+			}
+			if !args[1].IsInt() || args[1].Sign() < 0 || args[1].Num().Cmp(big.NewInt(1000)) > 0 {
+				return nil, fmt.Errorf("round: digits must be an integer between 0 and 1000")
+			}
+			scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), args[1].Num(), nil))
+			rounded := decimalRound(new(big.Rat).Mul(args[0], scale))
+			return rounded.Quo(rounded, scale), nil
+		}},
+		"min": {minArgs: 1, maxArgs: -1, float: func(args []float64, _ bool) (float64, error) {
+			result := args[0]
+			for _, v := range args[1:] {
+				result = math.Min(result, v)
+			}
+			return result, nil
+		}, decimal: func(args []*big.Rat) (*big.Rat, error) {
+			result := args[0]
+			for _, v := range args[1:] {
+				if v.Cmp(result) < 0 {
+					result = v
+				}
+			}
+			return result, nil
+		}},
+		"max": {minArgs: 1, maxArgs: -1, float: func(args []float64, _ bool) (float64, error) {
+			result := args[0]
+			for _, v := range args[1:] {
+				result = math.Max(result, v)
+			}
+			return result, nil
+		}, decimal: func(args []*big.Rat) (*big.Rat, error) {
+			result := args[0]
+			for _, v := range args[1:] {
+				if v.Cmp(result) > 0 {
+					result = v
+				}
+			}
+			return result, nil
+		}},
+		"sum": {minArgs: 1, maxArgs: -1, float: func(args []float64, _ bool) (float64, error) {
+			return sumFloats(args), nil
+		}, decimal: func(args []*big.Rat) (*big.Rat, error) {
+			return sumDecimals(args), nil
+		}},
+		"avg": {minArgs: 1, maxArgs: -1, float: func(args []float64, _ bool) (float64, error) {
+			return sumFloats(args) / float64(len(args)), nil
+		}, decimal: func(args []*big.Rat) (*big.Rat, error) {
+			sum := sumDecimals(args)
+			return sum.Quo(sum, new(big.Rat).SetInt64(int64(len(args)))), nil
+		}},
+	}
+}
+
+// evaluator 对语法树求值
+type evaluator struct {
+	opts Options
+}
+
+func (ev *evaluator) float(n node) (float64, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		return n.value, nil
+
+	case *varNode:
+		if v, ok := constants[n.name]; ok {
+			return v, nil
+		}
+		if v, ok := ev.opts.Variables[n.name]; ok {
+			return v, nil
+		}
+		return 0, fmt.Errorf("undefined variable %s", n.name)
+
+	case *unaryNode:
+		x, err := ev.float(n.x)
+		if n.op == '-' {
+			x = -x
+		}
+		return x, err
+
+	case *factorialNode:
+		x, err := ev.float(n.x)
+		if err != nil {
+			return 0, err
+		}
+		k, err := integerArg("factorial", x, 0, maxFloatFactorial)
+		if err != nil {
+			return 0, err
+		}
+		result := 1.0
+		for i := 2; i <= k; i++ {
+			result *= float64(i)
+		}
+		return result, nil
+
+	case *binaryNode:
+		left, err := ev.float(n.left)
+		if err != nil {
+			return 0, err
+		}
+		right, err := ev.float(n.right)
+		if err != nil {
+			return 0, err
+		}
+		switch n.op {
+		case '+':
+			return left + right, nil
+		case '-':
+			return left - right, nil
+		case '*':
+			return left * right, nil
+		case '/':
+			if right == 0 {
+				return 0, ErrDivisionByZero
+			}
+			return left / right, nil
+		case '%':
+			if right == 0 {
+				return 0, ErrDivisionByZero
+			}
+			return math.Mod(left, right), nil
+		default:
+			return math.Pow(left, right), nil
+		}
+
+	case *callNode:
+		args := make([]float64, len(n.args))
+		for i, arg := range n.args {
+			v, err := ev.float(arg)
+			if err != nil {
+				return 0, err
+			}
+			args[i] = v
+		}
+		return functions[n.name].float(args, ev.opts.Degrees)
+	}
+	return 0, fmt.Errorf("unexpected expression node %T", n)
+}
+
+func (ev *evaluator) decimal(n node) (*big.Rat, error) {
+	result, err := ev.decimalNode(n)
+	if err != nil {
+		return nil, err
+	}
+	if result.Num().BitLen() > maxDecimalBits || result.Denom().BitLen() > maxDecimalBits {
+		return nil, fmt.Errorf("intermediate result too large for decimal mode")
+	}
+	return result, nil
+}
+
+func (ev *evaluator) decimalNode(n node) (*big.Rat, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		return new(big.Rat).Set(n.exact), nil
+
+	case *varNode:
+		if _, ok := constants[n.name]; ok {
+			return nil, fmt.Errorf("constant %s: %w", n.name, ErrUnsupportedDecimal)
+		}
+		if v, ok := ev.opts.Exact[n.name]; ok {
+			return new(big.Rat).Set(v), nil
+		}
+		if v, ok := ev.opts.Variables[n.name]; ok {
+			return FloatToDecimal(v)
+		}
+		return nil, fmt.Errorf("undefined variable %s", n.name)
+
+	case *unaryNode:
+		x, err := ev.decimal(n.x)
+		if err != nil {
+			return nil, err
+		}
+		if n.op == '-' {
+			x.Neg(x)
+		}
+		return x, nil
+
+	case *factorialNode:
+		x, err := ev.decimal(n.x)
+		if err != nil {
+			return nil, err
+		}
+		if !x.IsInt() || x.Sign() < 0 || x.Num().Cmp(big.NewInt(maxDecimalFactorial)) > 0 {
+			return nil, fmt.Errorf("factorial: argument must be an integer between 0 and %d", maxDecimalFactorial)
+		}
+		k := x.Num().Int64()
+		if k < 2 {
+			return big.NewRat(1, 1), nil
+		}
+		return new(big.Rat).SetInt(new(big.Int).MulRange(2, k)), nil
+
+	case *binaryNode:
+		left, err := ev.decimal(n.left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := ev.decimal(n.right)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case '+':
+			return left.Add(left, right), nil
+		case '-':
+			return left.Sub(left, right), nil
+		case '*':
+			return left.Mul(left, right), nil
+		case '/':
+			if right.Sign() == 0 {
+				return nil, ErrDivisionByZero
+			}
+			return left.Quo(left, right), nil
+		case '%':
+			if right.Sign() == 0 {
+				return nil, ErrDivisionByZero
+			}
+			// 与 math.Mod 一致，结果符号与被除数相同
+			quotient := decimalTrunc(new(big.Rat).Quo(left, right))
+			return left.Sub(left, quotient.Mul(quotient, right)), nil
+		default:
+			return decimalPow(left, right)
+		}
+
+	case *callNode:
+		fn := functions[n.name]
+		if fn.decimal == nil {
+			return nil, fmt.Errorf("function %s: %w", n.name, ErrUnsupportedDecimal)
+		}
+		args := make([]*big.Rat, len(n.args))
+		for i, arg := range n.args {
+			v, err := ev.decimal(arg)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+		return fn.decimal(args)
+	}
+	return nil, fmt.Errorf("unexpected expression node %T", n)
+}
+
+// integerArg 检查 float64 参数为 [lo, hi] 内的整数
+func integerArg(name string, v float64, lo, hi int) (int, error) {
+	if v != math.Trunc(v) || v < float64(lo) || v > float64(hi) {
+		return 0, fmt.Errorf("%s: argument must be an integer between %d and %d", name, lo, hi)
+	}
+	return int(v), nil
+}
+
+func sumFloats(args []float64) float64 {
+	sum := 0.0
+	for _, v := range args {
+		sum += v
+	}
+	return sum
+}
+
+func sumDecimals(args []*big.Rat) *big.Rat {
+	sum := new(big.Rat)
+	for _, v := range args {
+		sum.Add(sum, v)
+	}
+	return sum
+}
+
+// decimalPow 精确乘方，指数必须为整数，结果位数超过上限时返回错误
+func decimalPow(base, exponent *big.Rat) (*big.Rat, error) {
+	if !exponent.IsInt() {
+		return nil, fmt.Errorf("non-integer exponent: %w", ErrUnsupportedDecimal)
+	}
+	exp := new(big.Int).Abs(exponent.Num())
+	bits := max(base.Num().BitLen(), base.Denom().BitLen())
+	if bits > 1 && (!exp.IsInt64() || exp.Int64() > maxDecimalBits || int64(bits-1)*exp.Int64() > maxDecimalBits) {
+		return nil, fmt.Errorf("intermediate result too large for decimal mode")
+	}
+	if exponent.Sign() < 0 && base.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+
+	num := new(big.Int).Exp(base.Num(), exp, nil)
+	den := new(big.Int).Exp(base.Denom(), exp, nil)
+	if exponent.Sign() < 0 {
+		num, den = den, num
+	}
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+// decimalSqrt 平方根，完全平方数返回精确结果，否则返回约 300 位有效数字的近似值
+func decimalSqrt(args []*big.Rat) (*big.Rat, error) {
+	x := args[0]
+	if x.Sign() < 0 {
+		return nil, fmt.Errorf("sqrt of negative number")
+	}
+	num, den := new(big.Int).Sqrt(x.Num()), new(big.Int).Sqrt(x.Denom())
+	if new(big.Int).Mul(num, num).Cmp(x.Num()) == 0 && new(big.Int).Mul(den, den).Cmp(x.Denom()) == 0 {
+		return new(big.Rat).SetFrac(num, den), nil
+	}
+	root := new(big.Float).SetPrec(1024).SetRat(x)
+	root.Sqrt(root)
+	result, _ := root.Rat(nil)
+	return result, nil
+}
+
+// decimalFloor 向下取整；big.Int.Div 为欧几里得除法，分母为正时即向下取整
+func decimalFloor(x *big.Rat) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Div(x.Num(), x.Denom()))
+}
+
+func decimalCeil(x *big.Rat) *big.Rat {
+	floor := decimalFloor(new(big.Rat).Neg(x))
+	return floor.Neg(floor)
+}
+
+func decimalTrunc(x *big.Rat) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Quo(x.Num(), x.Denom()))
+}
+
+// decimalRound 四舍五入到整数，.5 远离零
+func decimalRound(x *big.Rat) *big.Rat {
+	abs := new(big.Rat).Abs(x)
+	rounded := decimalFloor(abs.Add(abs, big.NewRat(1, 2)))
+	if x.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+	return rounded
+}
@@ -0,0 +1,167 @@
+// Package expr 数学表达式求值
+//
+// 支持 + - * / % 与乘方 ^（右结合，也可写作 **）、一元正负号、后缀阶乘 !、括号、
+// 变量、常量 pi 与 e，以及 sin、ln、pow、min、max 等函数，优先级与常见计算器一致：
+// -2^2 为 -4，2^3^2 为 512。
+//
+// 表达式可按 float64 求值，也可按精确的有理数（十进制）求值：十进制模式下 0.1+0.2 恰好为 0.3，
+// 但只支持可精确计算的运算，三角函数、对数等超越函数会返回错误。
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// 表达式限制
+const (
+	maxExpressionLength = 4096
+	maxDepth            = 100
+	maxFloatFactorial   = 170
+	maxDecimalFactorial = 1000
+	maxDecimalBits      = 1 << 16 // 十进制模式下分子与分母的位数上限，约 2 万位十进制数
+	maxExponent         = 10000   // 数字字面量科学计数法的指数上限
+)
+
+var (
+	// ErrDivisionByZero 除数或取模的模数为零
+	ErrDivisionByZero = errors.New("division by zero")
+	// ErrUnsupportedDecimal 十进制模式不支持的运算
+	ErrUnsupportedDecimal = errors.New("not supported in decimal mode")
+)
+
+// constants 内置常量，不能被变量覆盖
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+// Options 求值选项
+type Options struct {
+	Variables map[string]float64
+	Exact     map[string]*big.Rat // 按有理数求值时优先于 Variables 的精确变量值，避免超出 float64 精度的输入丢失位数
+	Degrees   bool                // 三角函数按角度而非弧度计算
+}
+
+// Expr 解析后的表达式
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse 解析表达式
+func Parse(src string) (*Expr, error) {
+	if len(src) > maxExpressionLength {
+		return nil, fmt.Errorf("expression longer than %d characters", maxExpressionLength)
+	}
+	p := &parser{src: src}
+	p.next()
+	root, err := p.expression(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String 返回原始表达式
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Variables 返回表达式引用的变量名（不含常量），按出现顺序去重
+func (e *Expr) Variables() []string {
+	var names []string
+	seen := make(map[string]bool)
+	var walk func(n node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case *varNode:
+			if _, isConst := constants[n.name]; !isConst && !seen[n.name] {
+				seen[n.name] = true
+				names = append(names, n.name)
+			}
+		case *unaryNode:
+			walk(n.x)
+		case *factorialNode:
+			walk(n.x)
+		case *binaryNode:
+			walk(n.left)
+			walk(n.right)
+		case *callNode:
+			for _, arg := range n.args {
+				walk(arg)
+			}
+		}
+	}
+	walk(e.root)
+	return names
+}
+
+// Eval 按 float64 求值，结果为 NaN 或溢出 float64 时返回错误
+func (e *Expr) Eval(opts Options) (float64, error) {
+	if err := checkVariables(opts.Variables); err != nil {
+		return 0, err
+	}
+	result, err := (&evaluator{opts: opts}).float(e.root)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(result) {
+		return 0, fmt.Errorf("result is not a number")
+	}
+	if math.IsInf(result, 0) {
+		return 0, fmt.Errorf("result exceeds the float64 range")
+	}
+	return result, nil
+}
+
+// EvalDecimal 按有理数精确求值；Exact 中的变量按原值参与计算，其余变量按其最短十进制表示参与计算
+func (e *Expr) EvalDecimal(opts Options) (*big.Rat, error) {
+	if err := checkVariables(opts.Variables); err != nil {
+		return nil, err
+	}
+	if err := checkVariables(opts.Exact); err != nil {
+		return nil, err
+	}
+	return (&evaluator{opts: opts}).decimal(e.root)
+}
+
+// FormatDecimal 将有理数格式化为小数，保留至多 digits 位小数并去掉末尾的 0
+func FormatDecimal(r *big.Rat, digits int) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	text := r.FloatString(digits)
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	if text == "-0" {
+		return "0"
+	}
+	return text
+}
+
+// FloatToDecimal 按 float64 的最短十进制表示转换为有理数，0.1 转换为 1/10 而非其二进制近似值
+func FloatToDecimal(v float64) (*big.Rat, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, fmt.Errorf("%v is not a finite number", v)
+	}
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(v, 'g', -1, 64))
+	return r, nil
+}
+
+func checkVariables[V any](variables map[string]V) error {
+	for name := range variables {
+		if _, isConst := constants[name]; isConst {
+			return fmt.Errorf("cannot redefine constant %s", name)
+		}
+	}
+	return nil
+}
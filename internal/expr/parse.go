@@ -0,0 +1,289 @@
+package expr
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// 节点类型
+type (
+	node interface{}
+
+	numberNode struct {
+		text  string
+		value float64
+		exact *big.Rat
+	}
+
+	varNode struct {
+		name string
+	}
+
+	unaryNode struct {
+		op byte
+		x  node
+	}
+
+	factorialNode struct {
+		x node
+	}
+
+	binaryNode struct {
+		op          byte
+		left, right node
+	}
+
+	callNode struct {
+		name string
+		args []node
+	}
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// 运算符优先级，一元正负号低于乘方，使 -2^2 为 -(2^2)
+const (
+	precAdditive       = 10
+	precMultiplicative = 20
+	precUnary          = 30
+	precPower          = 40
+	precPostfix        = 50
+)
+
+// parser Pratt 解析器
+type parser struct {
+	src   string
+	pos   int
+	tok   token
+	depth int
+	err   error
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("expression: %s at offset %d", fmt.Sprintf(format, args...), p.tok.pos)
+}
+
+// next 读取下一个记号，词法错误记录在 p.err 中并以 EOF 结束
+func (p *parser) next() {
+	for p.pos < len(p.src) && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokenEOF, pos: start}
+		return
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case isDigit(c) || c == '.':
+		for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+			end := p.pos + 1
+			if end < len(p.src) && (p.src[end] == '+' || p.src[end] == '-') {
+				end++
+			}
+			if end < len(p.src) && isDigit(p.src[end]) {
+				for end < len(p.src) && isDigit(p.src[end]) {
+					end++
+				}
+				p.pos = end
+			}
+		}
+		p.tok = token{kind: tokenNumber, text: p.src[start:p.pos], pos: start}
+	case isLetter(c):
+		for p.pos < len(p.src) && (isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		p.tok = token{kind: tokenIdent, text: p.src[start:p.pos], pos: start}
+	case c == '*' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '*':
+		p.pos += 2
+		p.tok = token{kind: tokenOp, text: "^", pos: start}
+	case c == '+' || c == '-' || c == '*' || c == '/' || c == '%' || c == '^' || c == '!':
+		p.pos++
+		p.tok = token{kind: tokenOp, text: string(c), pos: start}
+	case c == '(':
+		p.pos++
+		p.tok = token{kind: tokenLParen, text: "(", pos: start}
+	case c == ')':
+		p.pos++
+		p.tok = token{kind: tokenRParen, text: ")", pos: start}
+	case c == ',':
+		p.pos++
+		p.tok = token{kind: tokenComma, text: ",", pos: start}
+	default:
+		p.tok = token{kind: tokenEOF, pos: start}
+		p.err = p.errorf("unexpected character %q", c)
+	}
+}
+
+// expression 解析优先级高于 minPrec 的表达式
+func (p *parser) expression(minPrec int) (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, p.errorf("expression nested too deeply")
+	}
+
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokenOp {
+		op := p.tok.text[0]
+		if op == '!' {
+			if precPostfix <= minPrec {
+				break
+			}
+			p.next()
+			left = &factorialNode{x: left}
+			continue
+		}
+
+		prec, rightPrec := binaryPrecedence(op)
+		if prec <= minPrec {
+			break
+		}
+		p.next()
+		right, err := p.expression(rightPrec)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return left, nil
+}
+
+// binaryPrecedence 返回二元运算符的优先级与右操作数的最低优先级，乘方为右结合
+func binaryPrecedence(op byte) (int, int) {
+	switch op {
+	case '+', '-':
+		return precAdditive, precAdditive
+	case '*', '/', '%':
+		return precMultiplicative, precMultiplicative
+	default: // '^'
+		return precPower, precPower - 1
+	}
+}
+
+// operand 解析数字、变量、函数调用、括号表达式与一元正负号
+func (p *parser) operand() (node, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	tok := p.tok
+	switch tok.kind {
+	case tokenNumber:
+		if i := strings.IndexAny(tok.text, "eE"); i >= 0 {
+			if exp, err := strconv.Atoi(tok.text[i+1:]); err != nil || exp > maxExponent || exp < -maxExponent {
+				return nil, p.errorf("number %q out of range", tok.text)
+			}
+		}
+		value, err := strconv.ParseFloat(tok.text, 64)
+		exact, ok := new(big.Rat).SetString(tok.text)
+		if err != nil && !isRangeError(err) || !ok {
+			return nil, p.errorf("invalid number %q", tok.text)
+		}
+		p.next()
+		return &numberNode{text: tok.text, value: value, exact: exact}, nil
+
+	case tokenIdent:
+		p.next()
+		if p.tok.kind != tokenLParen {
+			return &varNode{name: tok.text}, nil
+		}
+		if _, ok := functions[tok.text]; !ok {
+			return nil, fmt.Errorf("expression: unknown function %s at offset %d", tok.text, tok.pos)
+		}
+		p.next()
+		var args []node
+		if p.tok.kind != tokenRParen {
+			for {
+				arg, err := p.expression(0)
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if p.tok.kind != tokenComma {
+					break
+				}
+				p.next()
+			}
+		}
+		if p.tok.kind != tokenRParen {
+			return nil, p.errorf("expected ) after arguments of %s", tok.text)
+		}
+		p.next()
+		if err := functions[tok.text].checkArity(tok.text, len(args)); err != nil {
+			return nil, err
+		}
+		return &callNode{name: tok.text, args: args}, nil
+
+	case tokenLParen:
+		p.next()
+		inner, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenRParen {
+			return nil, p.errorf("expected )")
+		}
+		p.next()
+		return inner, nil
+
+	case tokenOp:
+		if tok.text == "-" || tok.text == "+" {
+			p.next()
+			x, err := p.expression(precUnary)
+			if err != nil {
+				return nil, err
+			}
+			return &unaryNode{op: tok.text[0], x: x}, nil
+		}
+	}
+
+	if tok.kind == tokenEOF {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("unexpected %q", tok.text)
+}
+
+func isRangeError(err error) bool {
+	numErr, ok := err.(*strconv.NumError)
+	return ok && numErr.Err == strconv.ErrRange
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
@@ -0,0 +1,430 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"Weave-Toolkit/internal/pagination"
+	"Weave-Toolkit/internal/platform"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // Postgres 驱动
+	_ "modernc.org/sqlite"             // SQLite 驱动（纯 Go，无需 CGO）
+)
+
+// 支持的存储驱动
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// 查询条数限制
+const (
+	DefaultLimit = pagination.DefaultLimit
+	MaxLimit     = pagination.MaxLimit
+)
+
+// Entry 工具调用历史记录
+type Entry struct {
+	ID         int64           `json:"id"`
+	Tool       string          `json:"tool"`
+	Alias      string          `json:"alias,omitempty"`
+	Category   string          `json:"category,omitempty"`
+	Client     string          `json:"client,omitempty"`
+	Status     string          `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	Stream     bool            `json:"stream"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	StartedAt  time.Time       `json:"started_at"`
+	DurationMs int64           `json:"duration_ms"`
+	Receipt    *Receipt        `json:"receipt,omitempty"` // 启用签名时的回执
+}
+
+// Filter 历史记录查询条件
+type Filter struct {
+	Tool   string
+	Client string
+	Status string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+	Cursor *pagination.Cursor // 从该位置之后继续查询
+}
+
+// Store 历史记录存储接口
+type Store interface {
+	Insert(ctx context.Context, entry *Entry) error
+	Query(ctx context.Context, filter Filter) ([]Entry, error)
+	QueryPage(ctx context.Context, filter Filter) (pagination.Page[Entry], error)
+	Close() error
+}
+
+// SQLStore 基于 database/sql 的存储实现，支持 SQLite 与 Postgres
+type SQLStore struct {
+	db     *sql.DB
+	driver string
+}
+
+// Open 打开历史记录存储
+func Open(driver, dsn string) (*SQLStore, error) {
+	var sqlDriver string
+	switch driver {
+	case DriverSQLite, "":
+		driver = DriverSQLite
+		sqlDriver = "sqlite"
+		if dsn == "" {
+			dsn = "data/history.db"
+		}
+		if !strings.HasPrefix(dsn, "file:") && dsn != ":memory:" {
+			path, err := sqlitePath(dsn)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return nil, fmt.Errorf("failed to create history directory: %v", err)
+			}
+			dsn = path
+		}
+	case DriverPostgres:
+		sqlDriver = "pgx"
+		if dsn == "" {
+			return nil, fmt.Errorf("postgres history store requires a DSN")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported history driver: %s", driver)
+	}
+
+	db, err := sql.Open(sqlDriver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history store: %v", err)
+	}
+
+	if driver == DriverSQLite {
+		// SQLite 单写者，避免 database is locked
+		db.SetMaxOpenConns(1)
+	}
+
+	store := &SQLStore{db: db, driver: driver}
+	if err := store.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// sqlitePath 将 SQLite 文件路径转换为本地绝对路径，保留 ? 之后的连接参数
+func sqlitePath(dsn string) (string, error) {
+	path, query, hasQuery := strings.Cut(dsn, "?")
+	path, err := platform.NormalizePath(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid history database path: %v", err)
+	}
+	if hasQuery {
+		path += "?" + query
+	}
+	return path, nil
+}
+
+// migrate 创建表结构
+func (s *SQLStore) migrate() error {
+	idColumn := "INTEGER PRIMARY KEY AUTOINCREMENT"
+	if s.driver == DriverPostgres {
+		idColumn = "BIGSERIAL PRIMARY KEY"
+	}
+
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS tool_calls (
+			id ` + idColumn + `,
+			tool TEXT NOT NULL,
+			alias TEXT,
+			category TEXT,
+			client TEXT,
+			status TEXT NOT NULL,
+			error TEXT,
+			stream BOOLEAN NOT NULL DEFAULT FALSE,
+			arguments TEXT,
+			result TEXT,
+			started_at BIGINT NOT NULL,
+			duration_ms BIGINT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_tool_calls_started_at ON tool_calls (started_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_tool_calls_tool ON tool_calls (tool, started_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_tool_calls_client ON tool_calls (client, started_at)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to migrate history store: %v", err)
+		}
+	}
+
+	// 回执列在旧版本创建的表中不存在，按需补齐
+	if err := s.addColumns("receipt_key", "prev_hash", "hash", "signature"); err != nil {
+		return fmt.Errorf("failed to migrate history store: %v", err)
+	}
+	if _, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_tool_calls_receipt_key ON tool_calls (receipt_key, id)`); err != nil {
+		return fmt.Errorf("failed to migrate history store: %v", err)
+	}
+
+	return nil
+}
+
+// addColumns 为 tool_calls 添加缺少的 TEXT 列
+func (s *SQLStore) addColumns(columns ...string) error {
+	if s.driver == DriverPostgres {
+		for _, column := range columns {
+			if _, err := s.db.Exec(`ALTER TABLE tool_calls ADD COLUMN IF NOT EXISTS ` + column + ` TEXT`); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	rows, err := s.db.Query(`SELECT name FROM pragma_table_info('tool_calls')`)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if existing[column] {
+			continue
+		}
+		if _, err := s.db.Exec(`ALTER TABLE tool_calls ADD COLUMN ` + column + ` TEXT`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Insert 写入一条记录
+func (s *SQLStore) Insert(ctx context.Context, entry *Entry) error {
+	query := s.rebind(`INSERT INTO tool_calls
+		(tool, alias, category, client, status, error, stream, arguments, result, started_at, duration_ms,
+		 receipt_key, prev_hash, hash, signature)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+
+	var receiptKey, prevHash, hash, signature interface{}
+	if entry.Receipt != nil {
+		receiptKey, prevHash, hash, signature = entry.Receipt.KeyID, entry.Receipt.PrevHash, entry.Receipt.Hash, entry.Receipt.Signature
+	}
+
+	_, err := s.db.ExecContext(ctx, query,
+		entry.Tool,
+		entry.Alias,
+		entry.Category,
+		entry.Client,
+		entry.Status,
+		entry.Error,
+		entry.Stream,
+		nullableJSON(entry.Arguments),
+		nullableJSON(entry.Result),
+		entry.StartedAt.UnixMilli(),
+		entry.DurationMs,
+		receiptKey,
+		prevHash,
+		hash,
+		signature,
+	)
+	return err
+}
+
+// Query 按条件查询记录（按开始时间倒序）
+func (s *SQLStore) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	return s.query(ctx, filter, pagination.ClampLimit(filter.Limit))
+}
+
+// QueryPage 按条件分页查询记录
+func (s *SQLStore) QueryPage(ctx context.Context, filter Filter) (pagination.Page[Entry], error) {
+	limit := pagination.ClampLimit(filter.Limit)
+
+	// 多取一条判断是否存在下一页
+	entries, err := s.query(ctx, filter, limit+1)
+	if err != nil {
+		return pagination.Page[Entry]{}, err
+	}
+	return pagination.Trim(entries, limit, entryCursor), nil
+}
+
+// entryCursor 记录的分页排序键
+func entryCursor(entry Entry) pagination.Cursor {
+	return pagination.Cursor{Time: entry.StartedAt, ID: strconv.FormatInt(entry.ID, 10)}
+}
+
+// query 执行查询，limit 为实际读取的行数
+func (s *SQLStore) query(ctx context.Context, filter Filter, limit int) ([]Entry, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Tool != "" {
+		conditions = append(conditions, "tool = ?")
+		args = append(args, filter.Tool)
+	}
+	if filter.Client != "" {
+		conditions = append(conditions, "client = ?")
+		args = append(args, filter.Client)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "started_at >= ?")
+		args = append(args, filter.Since.UnixMilli())
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "started_at < ?")
+		args = append(args, filter.Until.UnixMilli())
+	}
+	if filter.Cursor != nil {
+		id, err := strconv.ParseInt(filter.Cursor.ID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		at := filter.Cursor.Time.UnixMilli()
+		conditions = append(conditions, "(started_at < ? OR (started_at = ? AND id < ?))")
+		args = append(args, at, at, id)
+	}
+
+	query := `SELECT ` + entryColumns + ` FROM tool_calls`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY started_at DESC, id DESC LIMIT " + strconv.Itoa(limit)
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// entryColumns 查询记录时读取的列，与 scanEntry 的顺序一致
+const entryColumns = `id, tool, alias, category, client, status, error, stream, arguments, result, started_at, duration_ms,
+	receipt_key, prev_hash, hash, signature`
+
+// scanEntry 读取一行记录
+func scanEntry(rows *sql.Rows) (Entry, error) {
+	var entry Entry
+	var alias, category, client, errMsg, arguments, result sql.NullString
+	var receiptKey, prevHash, hash, signature sql.NullString
+	var startedAt int64
+
+	if err := rows.Scan(&entry.ID, &entry.Tool, &alias, &category, &client, &entry.Status,
+		&errMsg, &entry.Stream, &arguments, &result, &startedAt, &entry.DurationMs,
+		&receiptKey, &prevHash, &hash, &signature); err != nil {
+		return entry, fmt.Errorf("failed to scan history row: %v", err)
+	}
+
+	entry.Alias = alias.String
+	entry.Category = category.String
+	entry.Client = client.String
+	entry.Error = errMsg.String
+	if arguments.Valid {
+		entry.Arguments = json.RawMessage(arguments.String)
+	}
+	if result.Valid {
+		entry.Result = json.RawMessage(result.String)
+	}
+	entry.StartedAt = time.UnixMilli(startedAt).UTC()
+	if receiptKey.Valid {
+		entry.Receipt = &Receipt{KeyID: receiptKey.String, PrevHash: prevHash.String, Hash: hash.String, Signature: signature.String}
+	}
+	return entry, nil
+}
+
+// LastReceiptHash 指定密钥最后写入的记录的哈希，没有记录时为空
+func (s *SQLStore) LastReceiptHash(ctx context.Context, keyID string) (string, error) {
+	var hash sql.NullString
+	err := s.db.QueryRowContext(ctx, s.rebind(`SELECT hash FROM tool_calls WHERE receipt_key = ? ORDER BY id DESC LIMIT 1`), keyID).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query history: %v", err)
+	}
+	return hash.String, nil
+}
+
+// WalkReceipts 按写入顺序遍历指定密钥签名的记录，fn 返回错误时停止
+func (s *SQLStore) WalkReceipts(ctx context.Context, keyID string, fn func(entry *Entry) error) error {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT `+entryColumns+` FROM tool_calls WHERE receipt_key = ? ORDER BY id`), keyID)
+	if err != nil {
+		return fmt.Errorf("failed to query history: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Close 关闭存储
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+// rebind 将 ? 占位符转换为对应驱动的格式
+func (s *SQLStore) rebind(query string) string {
+	if s.driver != DriverPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// nullableJSON 空 JSON 存为 NULL
+func nullableJSON(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
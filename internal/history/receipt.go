@@ -0,0 +1,199 @@
+package history
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Receipt 调用记录的签名回执
+//
+// 同一密钥签名的记录按写入顺序组成哈希链：Hash 覆盖记录内容与上一条记录的 Hash，
+// Signature 为对 Hash 的 Ed25519 签名。修改、删除或插入记录都会使校验失败。
+type Receipt struct {
+	KeyID     string `json:"key_id"`
+	PrevHash  string `json:"prev_hash,omitempty"` // 同一密钥上一条记录的哈希，链上第一条为空
+	Hash      string `json:"hash"`                // 十六进制 SHA-256
+	Signature string `json:"signature"`           // Base64 编码的 Ed25519 签名
+}
+
+// Signer 为写入的调用记录生成回执
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewSigner 使用 Ed25519 私钥创建签名器
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{key: key, keyID: KeyID(key.Public().(ed25519.PublicKey))}
+}
+
+// LoadSigner 读取 PEM 编码的 PKCS #8 Ed25519 私钥，如 openssl genpkey -algorithm ed25519 生成的文件
+func LoadSigner(path string) (*Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key %s: %v", path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not an Ed25519 key", path)
+	}
+	return NewSigner(key), nil
+}
+
+// LoadPublicKey 读取 PEM 编码的 PKIX Ed25519 公钥
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key %s: %v", path, err)
+	}
+	key, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an Ed25519 key", path)
+	}
+	return key, nil
+}
+
+// readPEM 读取文件中的第一个 PEM 块
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", path)
+	}
+	return block, nil
+}
+
+// KeyID 公钥标识：公钥 SHA-256 的前 8 字节
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// KeyID 签名器的公钥标识
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign 为记录生成接在 prevHash 之后的回执
+func (s *Signer) Sign(entry *Entry, prevHash string) {
+	hash := EntryHash(entry, prevHash)
+	digest, _ := hex.DecodeString(hash)
+	entry.Receipt = &Receipt{
+		KeyID:     s.keyID,
+		PrevHash:  prevHash,
+		Hash:      hash,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, digest)),
+	}
+}
+
+// receiptPayload 参与哈希的记录内容，与存储的列一一对应
+type receiptPayload struct {
+	PrevHash   string `json:"prev_hash"`
+	Tool       string `json:"tool"`
+	Alias      string `json:"alias"`
+	Category   string `json:"category"`
+	Client     string `json:"client"`
+	Status     string `json:"status"`
+	Error      string `json:"error"`
+	Stream     bool   `json:"stream"`
+	Arguments  string `json:"arguments"`
+	Result     string `json:"result"`
+	StartedAt  int64  `json:"started_at"` // Unix 毫秒，与存储精度一致
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// EntryHash 记录内容与上一条哈希的 SHA-256，不包含存储分配的 ID
+func EntryHash(entry *Entry, prevHash string) string {
+	data, _ := json.Marshal(receiptPayload{
+		PrevHash:   prevHash,
+		Tool:       entry.Tool,
+		Alias:      entry.Alias,
+		Category:   entry.Category,
+		Client:     entry.Client,
+		Status:     entry.Status,
+		Error:      entry.Error,
+		Stream:     entry.Stream,
+		Arguments:  string(entry.Arguments),
+		Result:     string(entry.Result),
+		StartedAt:  entry.StartedAt.UnixMilli(),
+		DurationMs: entry.DurationMs,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ErrReceiptInvalid 回执校验失败
+var ErrReceiptInvalid = errors.New("receipt verification failed")
+
+// ChainVerifier 按写入顺序逐条校验同一密钥的哈希链
+type ChainVerifier struct {
+	key   ed25519.PublicKey
+	keyID string
+	head  string
+	count int
+}
+
+// NewChainVerifier 使用公钥创建校验器
+func NewChainVerifier(key ed25519.PublicKey) *ChainVerifier {
+	return &ChainVerifier{key: key, keyID: KeyID(key)}
+}
+
+// Verify 校验下一条记录，失败时返回包装 ErrReceiptInvalid 的错误
+func (v *ChainVerifier) Verify(entry *Entry) error {
+	receipt := entry.Receipt
+	if receipt == nil || receipt.KeyID != v.keyID {
+		return fmt.Errorf("%w: entry %d has no receipt for key %s", ErrReceiptInvalid, entry.ID, v.keyID)
+	}
+	if receipt.PrevHash != v.head {
+		return fmt.Errorf("%w: entry %d does not follow the previous entry (chain broken)", ErrReceiptInvalid, entry.ID)
+	}
+	hash := EntryHash(entry, receipt.PrevHash)
+	if hash != receipt.Hash {
+		return fmt.Errorf("%w: entry %d content does not match its hash", ErrReceiptInvalid, entry.ID)
+	}
+	digest, _ := hex.DecodeString(hash)
+	signature, err := base64.StdEncoding.DecodeString(receipt.Signature)
+	if err != nil || !ed25519.Verify(v.key, digest, signature) {
+		return fmt.Errorf("%w: entry %d has an invalid signature", ErrReceiptInvalid, entry.ID)
+	}
+	v.head = hash
+	v.count++
+	return nil
+}
+
+// Head 最后一条已校验记录的哈希
+func (v *ChainVerifier) Head() string {
+	return v.head
+}
+
+// Count 已校验的记录数
+func (v *ChainVerifier) Count() int {
+	return v.count
+}
+
+// VerifyReceipts 按写入顺序校验存储中指定公钥签名的全部记录，返回记录数与链尾哈希
+func VerifyReceipts(ctx context.Context, store *SQLStore, key ed25519.PublicKey) (int, string, error) {
+	verifier := NewChainVerifier(key)
+	err := store.WalkReceipts(ctx, verifier.keyID, verifier.Verify)
+	return verifier.Count(), verifier.Head(), err
+}
@@ -0,0 +1,120 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/internal/tools"
+)
+
+// recorderBufferSize 异步写入缓冲区大小
+const recorderBufferSize = 1024
+
+// Recorder 将工具调用记录异步写入存储
+type Recorder struct {
+	store  Store
+	logger *logger.Logger
+	queue  chan *Entry
+	once   sync.Once
+	done   chan struct{}
+	signer *Signer // 为空时不签名
+	head   string  // 签名链上最后写入的记录的哈希，只由写入协程访问
+}
+
+// NewRecorder 创建记录器并启动后台写入协程
+func NewRecorder(store Store, logger *logger.Logger) *Recorder {
+	r := &Recorder{
+		store:  store,
+		logger: logger,
+		queue:  make(chan *Entry, recorderBufferSize),
+		done:   make(chan struct{}),
+	}
+
+	go r.loop()
+	return r
+}
+
+// NewSignedRecorder 创建为每条记录签名的记录器，签名链接在存储中同一密钥的最后一条记录之后
+func NewSignedRecorder(store *SQLStore, signer *Signer, logger *logger.Logger) (*Recorder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	head, err := store.LastReceiptHash(ctx, signer.KeyID())
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{
+		store:  store,
+		logger: logger,
+		queue:  make(chan *Entry, recorderBufferSize),
+		done:   make(chan struct{}),
+		signer: signer,
+		head:   head,
+	}
+
+	go r.loop()
+	return r, nil
+}
+
+// Observe 实现 tools.CallObserver，缓冲区满时丢弃记录，不阻塞工具调用
+func (r *Recorder) Observe(ctx context.Context, record tools.CallRecord) {
+	entry := &Entry{
+		Tool:       record.Tool,
+		Alias:      record.Alias,
+		Category:   string(record.Category),
+		Client:     record.Client,
+		Status:     record.Status,
+		Error:      record.Error,
+		Stream:     record.Stream,
+		Arguments:  record.Arguments,
+		StartedAt:  record.StartedAt,
+		DurationMs: record.Duration.Milliseconds(),
+	}
+
+	if record.Result != nil {
+		if data, err := json.Marshal(record.Result); err == nil {
+			entry.Result = data
+		}
+	}
+
+	select {
+	case r.queue <- entry:
+	default:
+		r.logger.Warn().Str("tool", record.Tool).Msg("History buffer full, dropping record")
+	}
+}
+
+// Store 获取底层存储
+func (r *Recorder) Store() Store {
+	return r.store
+}
+
+// Close 写完缓冲区中的记录后关闭存储
+func (r *Recorder) Close() error {
+	r.once.Do(func() {
+		close(r.queue)
+	})
+	<-r.done
+	return r.store.Close()
+}
+
+// loop 后台写入协程
+func (r *Recorder) loop() {
+	defer close(r.done)
+
+	for entry := range r.queue {
+		if r.signer != nil {
+			r.signer.Sign(entry, r.head)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := r.store.Insert(ctx, entry); err != nil {
+			r.logger.Error().Err(err).Str("tool", entry.Tool).Msg("Failed to record tool call history")
+		} else if entry.Receipt != nil {
+			r.head = entry.Receipt.Hash
+		}
+		cancel()
+	}
+}
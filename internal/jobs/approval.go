@@ -0,0 +1,134 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotAwaitingApproval 任务不在等待审批状态，可能已被处理或已超时
+var ErrNotAwaitingApproval = errors.New("job is not awaiting approval")
+
+// 审批结果
+const (
+	DecisionApproved = "approved"
+	DecisionDenied   = "denied"
+	DecisionExpired  = "expired"
+)
+
+// Approval 任务的审批状态
+type Approval struct {
+	ExpiresAt time.Time  `json:"expires_at"`
+	Decision  string     `json:"decision,omitempty"`
+	DecidedBy string     `json:"decided_by,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
+// SubmitForApproval 提交需要人工审批的任务
+//
+// 任务在批准前不进入队列，timeout 内未处理时自动拒绝。
+func (m *Manager) SubmitForApproval(tool string, args json.RawMessage, client, tenant, session string, timeout time.Duration) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil, fmt.Errorf("job manager is shutting down")
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:        generateJobID(),
+		Tool:      tool,
+		Arguments: args,
+		Client:    client,
+		Tenant:    tenant,
+		Session:   session,
+		Instance:  m.cfg.Instance,
+		Status:    StatusAwaitingApproval,
+		Approval:  &Approval{ExpiresAt: now.Add(timeout)},
+		CreatedAt: now,
+	}
+	id := job.ID
+	job.expiry = time.AfterFunc(timeout, func() {
+		m.decide(id, DecisionExpired, "", "approval timed out")
+	})
+
+	m.jobs[job.ID] = job
+	m.persist(job)
+
+	m.logger.Info().
+		Str("job_id", job.ID).
+		Str("tool", tool).
+		Dur("timeout", timeout).
+		Msg("Job awaiting approval")
+
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// Approve 批准任务，任务随即进入执行队列
+func (m *Manager) Approve(id, by string) (*Job, error) {
+	return m.decide(id, DecisionApproved, by, "")
+}
+
+// Deny 拒绝任务
+func (m *Manager) Deny(id, by, reason string) (*Job, error) {
+	return m.decide(id, DecisionDenied, by, reason)
+}
+
+// decide 记录审批结果：批准的任务进入队列，拒绝或超时的任务结束
+func (m *Manager) decide(id, decision, by, reason string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, exists := m.jobs[id]
+	if !exists {
+		return nil, fmt.Errorf("job not found: %s", id)
+	}
+	if job.Status != StatusAwaitingApproval {
+		return nil, fmt.Errorf("%w: %s is %s", ErrNotAwaitingApproval, id, job.Status)
+	}
+
+	// 快照与任务共用 Approval，修改时替换为新的副本
+	now := time.Now()
+	approval := *job.Approval
+	approval.Decision = decision
+	approval.DecidedBy = by
+	approval.Reason = reason
+	approval.DecidedAt = &now
+	job.Approval = &approval
+
+	switch {
+	case decision != DecisionApproved:
+		message := "denied"
+		if reason != "" {
+			message += ": " + reason
+		}
+		m.finishLocked(job, StatusDenied, nil, message)
+	case m.closed:
+		m.finishLocked(job, StatusFailed, nil, "job manager is shutting down")
+	default:
+		job.expiry.Stop()
+		job.expiry = nil
+		job.Status = StatusPending
+		select {
+		case m.queue <- job:
+			m.persist(job)
+			m.notifyLocked(job)
+		default:
+			m.finishLocked(job, StatusFailed, nil, fmt.Sprintf("job queue is full, max size: %d", m.cfg.QueueSize))
+		}
+	}
+
+	m.logger.Info().
+		Str("job_id", id).
+		Str("tool", job.Tool).
+		Str("decision", decision).
+		Str("decided_by", by).
+		Msg("Job approval decided")
+
+	snapshot := *job
+	return &snapshot, nil
+}
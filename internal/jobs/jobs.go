@@ -0,0 +1,595 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/internal/platform"
+)
+
+// Status 任务状态
+type Status string
+
+const (
+	StatusAwaitingApproval Status = "awaiting_approval" // 等待人工审批，批准后进入 pending
+	StatusPending          Status = "pending"
+	StatusRunning          Status = "running"
+	StatusCompleted        Status = "completed"
+	StatusFailed           Status = "failed"
+	StatusCancelled        Status = "cancelled"
+	StatusDenied           Status = "denied" // 审批被拒绝或超时
+)
+
+// Terminal 是否为终止状态
+func (s Status) Terminal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled || s == StatusDenied
+}
+
+// Job 异步工具调用任务
+type Job struct {
+	ID         string          `json:"id"`
+	Tool       string          `json:"tool"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	Client     string          `json:"client,omitempty"`
+	Tenant     string          `json:"tenant,omitempty"`
+	Session    string          `json:"session,omitempty"`  // 提交任务的会话，不属于会话时为空
+	Instance   string          `json:"instance,omitempty"` // 执行任务的副本，仅集群模式下记录
+	Status     Status          `json:"status"`
+	Approval   *Approval       `json:"approval,omitempty"` // 需要审批的任务的审批状态
+	Result     interface{}     `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+
+	cancel context.CancelFunc
+	expiry *time.Timer // 审批超时后自动拒绝
+}
+
+// Runner 任务执行函数，接收任务快照
+type Runner func(ctx context.Context, job Job) (interface{}, error)
+
+// Config 任务管理器配置
+type Config struct {
+	Workers   int           // 工作协程数
+	QueueSize int           // 队列长度
+	Retention time.Duration // 已完成任务保留时长
+	StoreDir  string        // 持久化目录，为空则仅保存在内存
+	Shared    Store         // 集群共享存储，为空则任务仅对本副本可见
+	Instance  string        // 当前副本标识，记录在任务中
+
+	// ErrorText 失败任务保存的错误文本，为空时保存原始错误信息；任务错误经 jobs/get 与 jobs/list 返回，
+	// 应与调用错误一样脱敏
+	ErrorText func(tool string, err error) string
+}
+
+// activeJobTTL 未结束任务在共享存储中的保留时长，防止异常退出的副本遗留任务
+const activeJobTTL = 24 * time.Hour
+
+// remotePollInterval 订阅其他副本执行的任务时轮询共享存储的间隔
+const remotePollInterval = 500 * time.Millisecond
+
+// Store 集群共享的任务存储
+//
+// 任务仍由提交它的副本执行；其他副本通过共享存储查询任务状态，
+// 并通过 RequestCancel 请求执行任务的副本取消任务。
+type Store interface {
+	Save(ctx context.Context, job Job, ttl time.Duration) error
+	Load(ctx context.Context, id string) (*Job, bool, error)
+	List(ctx context.Context) ([]Job, error)
+	Delete(ctx context.Context, id string) error
+	RequestCancel(ctx context.Context, id string) error
+	Cancellations(ctx context.Context) <-chan string
+}
+
+// Manager 异步任务管理器
+type Manager struct {
+	cfg    Config
+	runner Runner
+	logger *logger.Logger
+
+	mu          sync.RWMutex
+	jobs        map[string]*Job
+	subscribers map[string][]chan Job
+	queue       chan *Job
+	closed      bool
+	wg          sync.WaitGroup
+	stopCh      chan struct{}
+}
+
+// NewManager 创建任务管理器
+func NewManager(cfg Config, runner Runner, logger *logger.Logger) *Manager {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = time.Hour
+	}
+
+	return &Manager{
+		cfg:         cfg,
+		runner:      runner,
+		logger:      logger,
+		jobs:        make(map[string]*Job),
+		subscribers: make(map[string][]chan Job),
+		queue:       make(chan *Job, cfg.QueueSize),
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// storeTimeout 单次访问共享存储的超时时间
+const storeTimeout = 5 * time.Second
+
+// Start 加载持久化任务并启动工作协程
+func (m *Manager) Start() error {
+	if m.cfg.StoreDir != "" {
+		storeDir, err := platform.NormalizePath(m.cfg.StoreDir)
+		if err != nil {
+			return fmt.Errorf("invalid job store directory: %v", err)
+		}
+		m.cfg.StoreDir = storeDir
+
+		if err := os.MkdirAll(m.cfg.StoreDir, 0755); err != nil {
+			return fmt.Errorf("failed to create job store directory: %v", err)
+		}
+		if err := m.load(); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < m.cfg.Workers; i++ {
+		m.wg.Add(1)
+		go m.worker()
+	}
+
+	go m.reaper()
+	if m.cfg.Shared != nil {
+		go m.watchCancellations()
+	}
+
+	m.logger.Info().
+		Int("workers", m.cfg.Workers).
+		Int("queue_size", m.cfg.QueueSize).
+		Str("store_dir", m.cfg.StoreDir).
+		Msg("Job manager started")
+
+	return nil
+}
+
+// Submit 提交异步任务
+func (m *Manager) Submit(tool string, args json.RawMessage, client string) (*Job, error) {
+	return m.SubmitTenant(tool, args, client, "")
+}
+
+// SubmitTenant 以租户身份提交任务，tenant 为空时与 Submit 相同
+func (m *Manager) SubmitTenant(tool string, args json.RawMessage, client, tenant string) (*Job, error) {
+	return m.SubmitSession(tool, args, client, tenant, "")
+}
+
+// SubmitSession 在会话中以租户身份提交任务，session 为空时与 SubmitTenant 相同
+func (m *Manager) SubmitSession(tool string, args json.RawMessage, client, tenant, session string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil, fmt.Errorf("job manager is shutting down")
+	}
+
+	job := &Job{
+		ID:        generateJobID(),
+		Tool:      tool,
+		Arguments: args,
+		Client:    client,
+		Tenant:    tenant,
+		Session:   session,
+		Instance:  m.cfg.Instance,
+		Status:    StatusPending,
+		CreatedAt: time.Now(),
+	}
+
+	select {
+	case m.queue <- job:
+	default:
+		return nil, fmt.Errorf("job queue is full, max size: %d", m.cfg.QueueSize)
+	}
+
+	m.jobs[job.ID] = job
+	m.persist(job)
+
+	m.logger.Info().
+		Str("job_id", job.ID).
+		Str("tool", tool).
+		Msg("Job submitted")
+
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// Get 获取任务快照
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.RLock()
+	job, exists := m.jobs[id]
+	if exists {
+		snapshot := *job
+		m.mu.RUnlock()
+		return &snapshot, nil
+	}
+	m.mu.RUnlock()
+
+	return m.loadShared(id)
+}
+
+// loadShared 从共享存储获取其他副本提交的任务
+func (m *Manager) loadShared(id string) (*Job, error) {
+	if m.cfg.Shared == nil {
+		return nil, fmt.Errorf("job not found: %s", id)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	job, found, err := m.cfg.Shared.Load(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job %s: %v", id, err)
+	}
+	if !found {
+		return nil, fmt.Errorf("job not found: %s", id)
+	}
+	return job, nil
+}
+
+// List 列出所有任务（按创建时间倒序）
+func (m *Manager) List() []Job {
+	m.mu.RLock()
+	list := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		list = append(list, *job)
+	}
+	m.mu.RUnlock()
+
+	if m.cfg.Shared != nil {
+		list = m.mergeShared(list)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt)
+	})
+	return list
+}
+
+// Cancel 取消任务
+func (m *Manager) Cancel(id string) (*Job, error) {
+	m.mu.Lock()
+	job, exists := m.jobs[id]
+	if !exists {
+		m.mu.Unlock()
+		return m.cancelShared(id)
+	}
+
+	if job.Status.Terminal() {
+		snapshot := *job
+		m.mu.Unlock()
+		return &snapshot, nil
+	}
+
+	if job.cancel != nil {
+		// 运行中的任务由工作协程在执行返回后更新状态
+		job.cancel()
+		snapshot := *job
+		m.mu.Unlock()
+		return &snapshot, nil
+	}
+
+	m.finishLocked(job, StatusCancelled, nil, "cancelled before start")
+	snapshot := *job
+	m.mu.Unlock()
+	return &snapshot, nil
+}
+
+// Subscribe 订阅任务状态变化，任务进入终止状态后通道关闭
+func (m *Manager) Subscribe(id string) (<-chan Job, func(), error) {
+	m.mu.Lock()
+	job, exists := m.jobs[id]
+	if !exists {
+		m.mu.Unlock()
+		return m.subscribeShared(id)
+	}
+	defer m.mu.Unlock()
+
+	ch := make(chan Job, 4)
+	ch <- *job
+	if job.Status.Terminal() {
+		close(ch)
+		return ch, func() {}, nil
+	}
+
+	m.subscribers[id] = append(m.subscribers[id], ch)
+	unsubscribe := func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		subs := m.subscribers[id]
+		for i, sub := range subs {
+			if sub == ch {
+				m.subscribers[id] = append(subs[:i], subs[i+1:]...)
+				close(ch)
+				break
+			}
+		}
+	}
+	return ch, unsubscribe, nil
+}
+
+// Stats 获取任务统计信息
+func (m *Manager) Stats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := make(map[Status]int)
+	for _, job := range m.jobs {
+		counts[job.Status]++
+	}
+
+	return map[string]interface{}{
+		"workers":     m.cfg.Workers,
+		"queue_size":  m.cfg.QueueSize,
+		"queue_depth": len(m.queue),
+		"total":       len(m.jobs),
+		"awaiting":    counts[StatusAwaitingApproval],
+		"pending":     counts[StatusPending],
+		"running":     counts[StatusRunning],
+		"completed":   counts[StatusCompleted],
+		"failed":      counts[StatusFailed],
+		"cancelled":   counts[StatusCancelled],
+		"denied":      counts[StatusDenied],
+	}
+}
+
+// QueueLoad 队列中等待执行的任务数与队列容量
+func (m *Manager) QueueLoad() (depth, size int) {
+	return len(m.queue), cap(m.queue)
+}
+
+// Shutdown 停止接收新任务并等待运行中的任务完成
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	close(m.queue)
+	close(m.stopCh)
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		// 超时则取消所有仍在运行的任务
+		m.mu.Lock()
+		for _, job := range m.jobs {
+			if job.cancel != nil {
+				job.cancel()
+			}
+		}
+		m.mu.Unlock()
+		return fmt.Errorf("timeout waiting for jobs to finish")
+	}
+}
+
+// worker 工作协程
+func (m *Manager) worker() {
+	defer m.wg.Done()
+
+	for job := range m.queue {
+		m.run(job)
+	}
+}
+
+// run 执行单个任务
+func (m *Manager) run(job *Job) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m.mu.Lock()
+	if job.Status != StatusPending {
+		// 排队期间已被取消
+		m.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	job.Status = StatusRunning
+	job.StartedAt = &now
+	job.cancel = cancel
+	m.persist(job)
+	m.notifyLocked(job)
+	snapshot := *job
+	m.mu.Unlock()
+
+	result, err := m.runner(ctx, snapshot)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job.cancel = nil
+	switch {
+	case ctx.Err() == context.Canceled:
+		m.finishLocked(job, StatusCancelled, nil, "cancelled")
+	case err != nil:
+		m.finishLocked(job, StatusFailed, nil, m.errorText(job.Tool, err))
+	default:
+		m.finishLocked(job, StatusCompleted, result, "")
+	}
+
+	m.logger.Info().
+		Str("job_id", job.ID).
+		Str("tool", job.Tool).
+		Str("status", string(job.Status)).
+		Dur("duration", job.FinishedAt.Sub(*job.StartedAt)).
+		Msg("Job finished")
+}
+
+// errorText 失败任务保存的错误文本
+func (m *Manager) errorText(tool string, err error) string {
+	if m.cfg.ErrorText == nil {
+		return err.Error()
+	}
+	return m.cfg.ErrorText(tool, err)
+}
+
+// finishLocked 将任务置为终止状态（调用方需持有写锁）
+func (m *Manager) finishLocked(job *Job, status Status, result interface{}, errMsg string) {
+	now := time.Now()
+	job.Status = status
+	job.Result = result
+	job.Error = errMsg
+	job.FinishedAt = &now
+	if job.expiry != nil {
+		job.expiry.Stop()
+		job.expiry = nil
+	}
+	m.persist(job)
+	m.notifyLocked(job)
+
+	for _, ch := range m.subscribers[job.ID] {
+		close(ch)
+	}
+	delete(m.subscribers, job.ID)
+}
+
+// notifyLocked 通知订阅者（调用方需持有锁）
+func (m *Manager) notifyLocked(job *Job) {
+	for _, ch := range m.subscribers[job.ID] {
+		select {
+		case ch <- *job:
+		default:
+			// 订阅者处理过慢时丢弃中间状态，终止状态通过通道关闭前的最后一次发送保证
+		}
+	}
+}
+
+// reaper 定期清理过期任务
+func (m *Manager) reaper() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.cleanup()
+		}
+	}
+}
+
+// cleanup 清理本副本超过保留时长的已完成任务，共享存储中的任务由当选副本通过 ReapShared 清理
+func (m *Manager) cleanup() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-m.cfg.Retention)
+	for id, job := range m.jobs {
+		if job.Status.Terminal() && job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			delete(m.jobs, id)
+			if m.cfg.StoreDir != "" {
+				os.Remove(m.jobPath(id))
+			}
+		}
+	}
+}
+
+// persist 持久化任务状态（调用方需持有锁）
+func (m *Manager) persist(job *Job) {
+	m.saveShared(job)
+	if m.cfg.StoreDir == "" {
+		return
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		m.logger.Error().Err(err).Str("job_id", job.ID).Msg("Failed to marshal job")
+		return
+	}
+
+	tmp := m.jobPath(job.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		m.logger.Error().Err(err).Str("job_id", job.ID).Msg("Failed to persist job")
+		return
+	}
+	if err := os.Rename(tmp, m.jobPath(job.ID)); err != nil {
+		m.logger.Error().Err(err).Str("job_id", job.ID).Msg("Failed to persist job")
+	}
+}
+
+// load 从持久化目录加载任务，重启前未完成的任务标记为失败
+func (m *Manager) load() error {
+	entries, err := os.ReadDir(m.cfg.StoreDir)
+	if err != nil {
+		return fmt.Errorf("failed to read job store directory: %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(m.cfg.StoreDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			m.logger.Warn().Str("file", entry.Name()).Err(err).Msg("Skipping corrupt job file")
+			continue
+		}
+		// 任务 ID 用作文件名，不允许跳出持久化目录
+		if _, err := platform.Confine(m.cfg.StoreDir, job.ID+".json"); err != nil || job.ID == "" {
+			m.logger.Warn().Str("file", entry.Name()).Str("job_id", job.ID).Msg("Skipping job file with invalid ID")
+			continue
+		}
+
+		if !job.Status.Terminal() {
+			now := time.Now()
+			job.Status = StatusFailed
+			job.Error = "interrupted by server restart"
+			job.FinishedAt = &now
+			m.persist(&job)
+		}
+
+		m.jobs[job.ID] = &job
+	}
+
+	return nil
+}
+
+// jobPath 任务持久化文件路径
+func (m *Manager) jobPath(id string) string {
+	return filepath.Join(m.cfg.StoreDir, id+".json")
+}
+
+// generateJobID 生成任务ID，随机部分取自 crypto/rand，无法从时间或其他任务ID推测
+func generateJobID() string {
+	buf := make([]byte, 16)
+	// crypto/rand.Read 不会返回错误，熵源不可用时进程直接终止
+	_, _ = rand.Read(buf)
+	return "job_" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,186 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// saveShared 将任务状态写入共享存储（调用方需持有锁）
+func (m *Manager) saveShared(job *Job) {
+	if m.cfg.Shared == nil {
+		return
+	}
+
+	ttl := activeJobTTL
+	if job.Status.Terminal() {
+		ttl = m.cfg.Retention
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := m.cfg.Shared.Save(ctx, *job, ttl); err != nil {
+		m.logger.Error().Err(err).Str("job_id", job.ID).Msg("Failed to save job to shared store")
+	}
+}
+
+// deleteShared 从共享存储删除任务
+func (m *Manager) deleteShared(id string) {
+	if m.cfg.Shared == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := m.cfg.Shared.Delete(ctx, id); err != nil {
+		m.logger.Warn().Err(err).Str("job_id", id).Msg("Failed to delete job from shared store")
+	}
+}
+
+// ReapShared 清理共享存储中超过保留时长的已完成任务，返回清理的任务数
+//
+// 共享存储由所有副本共用，集群中只应由当选的副本定期执行。
+func (m *Manager) ReapShared(ctx context.Context) int {
+	if m.cfg.Shared == nil {
+		return 0
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, storeTimeout)
+	defer cancel()
+	list, err := m.cfg.Shared.List(ctx)
+	if err != nil {
+		m.logger.Warn().Err(err).Msg("Failed to list jobs from shared store")
+		return 0
+	}
+
+	cutoff := time.Now().Add(-m.cfg.Retention)
+	removed := 0
+	for _, job := range list {
+		if job.Status.Terminal() && job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			m.deleteShared(job.ID)
+			removed++
+		}
+	}
+	return removed
+}
+
+// mergeShared 合并其他副本提交的任务，本副本的任务以内存中的状态为准
+func (m *Manager) mergeShared(list []Job) []Job {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	shared, err := m.cfg.Shared.List(ctx)
+	if err != nil {
+		m.logger.Warn().Err(err).Msg("Failed to list jobs from shared store")
+		return list
+	}
+
+	local := make(map[string]bool, len(list))
+	for _, job := range list {
+		local[job.ID] = true
+	}
+	for _, job := range shared {
+		if !local[job.ID] {
+			list = append(list, job)
+		}
+	}
+	return list
+}
+
+// cancelShared 请求执行任务的副本取消任务，返回请求时的任务快照
+func (m *Manager) cancelShared(id string) (*Job, error) {
+	job, err := m.loadShared(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status.Terminal() {
+		return job, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := m.cfg.Shared.RequestCancel(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to cancel job %s: %v", id, err)
+	}
+	return job, nil
+}
+
+// subscribeShared 订阅其他副本执行的任务，通过轮询共享存储获取状态变化
+func (m *Manager) subscribeShared(id string) (<-chan Job, func(), error) {
+	job, err := m.loadShared(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan Job, 4)
+	ch <- *job
+	if job.Status.Terminal() {
+		close(ch)
+		return ch, func() {}, nil
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(remotePollInterval)
+		defer ticker.Stop()
+
+		last := job.Status
+		for {
+			select {
+			case <-stop:
+				return
+			case <-m.stopCh:
+				return
+			case <-ticker.C:
+			}
+
+			current, err := m.loadShared(id)
+			if err != nil {
+				// 任务已过期或共享存储不可用，结束订阅
+				return
+			}
+			if current.Status == last {
+				continue
+			}
+			last = current.Status
+			select {
+			case ch <- *current:
+			default:
+			}
+			if current.Status.Terminal() {
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() { close(stop) })
+	}
+	return ch, unsubscribe, nil
+}
+
+// watchCancellations 处理其他副本转发的取消请求
+func (m *Manager) watchCancellations() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-m.stopCh
+		cancel()
+	}()
+
+	for id := range m.cfg.Shared.Cancellations(ctx) {
+		// 只处理本副本执行的任务，避免取消请求在副本之间反复转发
+		m.mu.RLock()
+		_, local := m.jobs[id]
+		m.mu.RUnlock()
+		if !local {
+			continue
+		}
+		if _, err := m.Cancel(id); err == nil {
+			m.logger.Info().Str("job_id", id).Msg("Job cancelled by another instance")
+		}
+	}
+}
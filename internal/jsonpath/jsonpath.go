@@ -0,0 +1,719 @@
+// Package jsonpath JSONPath 查询
+//
+// 支持常用的 Goessner JSONPath 语法：根 $、子成员 .name 与 ['name']、通配符 * 、
+// 递归下降 ..、数组下标（支持负数）、并集 [0,2] 与 ['a','b']、切片 [start:end:step]，
+// 以及过滤表达式 [?(...)]：可比较 @ 或 $ 开头的路径与数字、字符串、布尔、null 字面量，
+// 支持 == != < <= > >=、&&、||、! 与括号，单独的路径表示存在性判断。
+// 文档为 encoding/json 解码得到的值（map[string]interface{}、[]interface{}、float64 等），
+// 对象成员按键名排序遍历，结果顺序稳定。
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Path 编译后的 JSONPath
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+// segment 路径中的一段，descendant 表示先展开所有后代节点再选择
+type segment struct {
+	descendant bool
+	sel        selector
+}
+
+// selector 从单个节点选择子节点
+type selector interface {
+	selectFrom(value interface{}, out []interface{}) []interface{}
+}
+
+// Compile 编译 JSONPath 表达式
+func Compile(expr string) (*Path, error) {
+	p := &parser{src: expr}
+	p.skipSpace()
+	if !p.consume("$") {
+		return nil, p.errorf("path must start with $")
+	}
+	segments, err := p.segments()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.rest())
+	}
+	return &Path{expr: expr, segments: segments}, nil
+}
+
+// Query 编译并执行 JSONPath 表达式
+func Query(expr string, doc interface{}) ([]interface{}, error) {
+	path, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return path.Query(doc), nil
+}
+
+// String 原始表达式
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Query 返回所有匹配的节点，无匹配时返回空切片
+func (p *Path) Query(doc interface{}) []interface{} {
+	return evaluate(p.segments, doc, doc)
+}
+
+// evaluate 从 current 开始依次应用各段，root 供过滤表达式中的 $ 使用
+func evaluate(segments []segment, current, root interface{}) []interface{} {
+	nodes := []interface{}{current}
+	for _, seg := range segments {
+		if seg.descendant {
+			var expanded []interface{}
+			for _, node := range nodes {
+				expanded = descendants(node, expanded)
+			}
+			nodes = expanded
+		}
+		next := []interface{}{}
+		for _, node := range nodes {
+			if filter, ok := seg.sel.(*filterSelector); ok {
+				next = filter.selectWithRoot(node, root, next)
+				continue
+			}
+			next = seg.sel.selectFrom(node, next)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// descendants 按先序追加节点自身及其所有后代
+func descendants(value interface{}, out []interface{}) []interface{} {
+	out = append(out, value)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			out = descendants(v[key], out)
+		}
+	case []interface{}:
+		for _, item := range v {
+			out = descendants(item, out)
+		}
+	}
+	return out
+}
+
+// children 对象成员值（按键名排序）或数组元素
+func children(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			out = append(out, v[key])
+		}
+		return out
+	case []interface{}:
+		return v
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// wildcardSelector *
+type wildcardSelector struct{}
+
+func (wildcardSelector) selectFrom(value interface{}, out []interface{}) []interface{} {
+	return append(out, children(value)...)
+}
+
+// unionSelector 成员名与下标的并集，单个名称或下标也用它表示
+type unionSelector struct {
+	items []interface{} // string 或 int
+}
+
+func (s *unionSelector) selectFrom(value interface{}, out []interface{}) []interface{} {
+	for _, item := range s.items {
+		switch key := item.(type) {
+		case string:
+			if obj, ok := value.(map[string]interface{}); ok {
+				if member, exists := obj[key]; exists {
+					out = append(out, member)
+				}
+			}
+		case int:
+			if arr, ok := value.([]interface{}); ok {
+				if key < 0 {
+					key += len(arr)
+				}
+				if key >= 0 && key < len(arr) {
+					out = append(out, arr[key])
+				}
+			}
+		}
+	}
+	return out
+}
+
+// sliceSelector [start:end:step]
+type sliceSelector struct {
+	start, end *int
+	step       int
+}
+
+func (s *sliceSelector) selectFrom(value interface{}, out []interface{}) []interface{} {
+	arr, ok := value.([]interface{})
+	if !ok {
+		return out
+	}
+	for _, i := range s.indices(len(arr)) {
+		out = append(out, arr[i])
+	}
+	return out
+}
+
+// indices 长度为 n 的数组中被选中的下标
+func (s *sliceSelector) indices(n int) []int {
+	if s.step == 0 {
+		return nil
+	}
+	bound := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+		i := *p
+		if i < 0 {
+			i += n
+		}
+		return min(max(i, -1), n)
+	}
+
+	var out []int
+	if s.step > 0 {
+		for i := max(bound(s.start, 0), 0); i < bound(s.end, n); i += s.step {
+			out = append(out, i)
+		}
+	} else {
+		for i := min(bound(s.start, n-1), n-1); i > bound(s.end, -1); i += s.step {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// filterSelector [?(expr)]，对数组元素或对象成员值逐个求值
+type filterSelector struct {
+	expr expression
+}
+
+func (s *filterSelector) selectFrom(value interface{}, out []interface{}) []interface{} {
+	return s.selectWithRoot(value, value, out)
+}
+
+func (s *filterSelector) selectWithRoot(value, root interface{}, out []interface{}) []interface{} {
+	for _, child := range children(value) {
+		if truthy(s.expr.eval(child, root)) {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+// expression 过滤表达式节点，返回值为 JSON 值或 bool，不存在时返回 missing
+type expression interface {
+	eval(current, root interface{}) interface{}
+}
+
+// missing 路径无匹配
+type missingValue struct{}
+
+var missing = missingValue{}
+
+func truthy(v interface{}) bool {
+	switch b := v.(type) {
+	case missingValue:
+		return false
+	case bool:
+		return b
+	}
+	return true
+}
+
+// pathExpr @ 或 $ 开头的路径，取第一个匹配节点
+type pathExpr struct {
+	fromRoot bool
+	segments []segment
+}
+
+func (e *pathExpr) eval(current, root interface{}) interface{} {
+	start := current
+	if e.fromRoot {
+		start = root
+	}
+	nodes := evaluate(e.segments, start, root)
+	if len(nodes) == 0 {
+		return missing
+	}
+	return nodes[0]
+}
+
+// literalExpr 字面量
+type literalExpr struct {
+	value interface{}
+}
+
+func (e *literalExpr) eval(_, _ interface{}) interface{} {
+	return e.value
+}
+
+// notExpr !expr
+type notExpr struct {
+	operand expression
+}
+
+func (e *notExpr) eval(current, root interface{}) interface{} {
+	return !truthy(e.operand.eval(current, root))
+}
+
+// logicalExpr && 与 ||
+type logicalExpr struct {
+	and         bool
+	left, right expression
+}
+
+func (e *logicalExpr) eval(current, root interface{}) interface{} {
+	left := truthy(e.left.eval(current, root))
+	if e.and != left {
+		return left
+	}
+	return truthy(e.right.eval(current, root))
+}
+
+// compareExpr 比较运算，任一侧不存在或类型不可比较时为 false（!= 除外）
+type compareExpr struct {
+	op          string
+	left, right expression
+}
+
+func (e *compareExpr) eval(current, root interface{}) interface{} {
+	left, right := e.left.eval(current, root), e.right.eval(current, root)
+	_, leftMissing := left.(missingValue)
+	_, rightMissing := right.(missingValue)
+	if leftMissing || rightMissing {
+		return e.op == "!=" && leftMissing != rightMissing
+	}
+
+	switch e.op {
+	case "==":
+		return reflect.DeepEqual(left, right)
+	case "!=":
+		return !reflect.DeepEqual(left, right)
+	}
+
+	var cmp int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return false
+		}
+		switch {
+		case l < r:
+			cmp = -1
+		case l > r:
+			cmp = 1
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return false
+		}
+		cmp = strings.Compare(l, r)
+	default:
+		return false
+	}
+
+	switch e.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// parser 递归下降解析器
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("jsonpath: %s at offset %d", fmt.Sprintf(format, args...), p.pos)
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) rest() string {
+	return p.src[p.pos:]
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) consume(s string) bool {
+	if strings.HasPrefix(p.rest(), s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// segments 解析 $ 或 @ 之后的各段
+func (p *parser) segments() ([]segment, error) {
+	var segments []segment
+	for !p.eof() {
+		switch {
+		case p.consume(".."):
+			sel, err := p.memberOrBracket()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment{descendant: true, sel: sel})
+		case p.consume("."):
+			sel, err := p.member()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment{sel: sel})
+		case p.peek() == '[':
+			sel, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment{sel: sel})
+		default:
+			return segments, nil
+		}
+	}
+	return segments, nil
+}
+
+// memberOrBracket .. 之后可跟成员名、* 或方括号
+func (p *parser) memberOrBracket() (selector, error) {
+	if p.peek() == '[' {
+		return p.bracket()
+	}
+	return p.member()
+}
+
+// member 点号之后的成员名或 *
+func (p *parser) member() (selector, error) {
+	if p.consume("*") {
+		return wildcardSelector{}, nil
+	}
+	start := p.pos
+	for !p.eof() {
+		c := p.src[p.pos]
+		if c == '.' || c == '[' || c == ' ' || c == ')' || c == '=' || c == '!' || c == '<' || c == '>' || c == '&' || c == '|' {
+			break
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, p.errorf("expected member name")
+	}
+	return &unionSelector{items: []interface{}{p.src[start:p.pos]}}, nil
+}
+
+// bracket 方括号选择器
+func (p *parser) bracket() (selector, error) {
+	p.consume("[")
+	p.skipSpace()
+
+	var sel selector
+	switch {
+	case p.consume("*"):
+		sel = wildcardSelector{}
+	case p.consume("?"):
+		p.skipSpace()
+		if !p.consume("(") {
+			return nil, p.errorf("expected ( after ?")
+		}
+		expr, err := p.orExpr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(")") {
+			return nil, p.errorf("expected ) to close filter")
+		}
+		sel = &filterSelector{expr: expr}
+	default:
+		var err error
+		if sel, err = p.unionOrSlice(); err != nil {
+			return nil, err
+		}
+	}
+
+	p.skipSpace()
+	if !p.consume("]") {
+		return nil, p.errorf("expected ]")
+	}
+	return sel, nil
+}
+
+// unionOrSlice 下标、名称的并集或切片
+func (p *parser) unionOrSlice() (selector, error) {
+	var items []interface{}
+	for {
+		p.skipSpace()
+		switch c := p.peek(); {
+		case c == '\'' || c == '"':
+			name, err := p.quoted()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, name)
+		case c == ':' || c == '-' || c >= '0' && c <= '9':
+			start, hasStart, err := p.optionalInt()
+			if err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+			if p.peek() == ':' {
+				if len(items) > 0 {
+					return nil, p.errorf("slices cannot be combined with a union")
+				}
+				return p.slice(start, hasStart)
+			}
+			if !hasStart {
+				return nil, p.errorf("expected index")
+			}
+			items = append(items, start)
+		default:
+			return nil, p.errorf("unexpected %q in brackets", p.rest())
+		}
+
+		p.skipSpace()
+		if !p.consume(",") {
+			return &unionSelector{items: items}, nil
+		}
+	}
+}
+
+// slice 解析切片中 start 之后的部分
+func (p *parser) slice(start int, hasStart bool) (selector, error) {
+	sel := &sliceSelector{step: 1}
+	if hasStart {
+		sel.start = &start
+	}
+	p.consume(":")
+	p.skipSpace()
+	end, hasEnd, err := p.optionalInt()
+	if err != nil {
+		return nil, err
+	}
+	if hasEnd {
+		sel.end = &end
+	}
+	p.skipSpace()
+	if p.consume(":") {
+		p.skipSpace()
+		step, hasStep, err := p.optionalInt()
+		if err != nil {
+			return nil, err
+		}
+		if hasStep {
+			if step == 0 {
+				return nil, p.errorf("slice step cannot be zero")
+			}
+			sel.step = step
+		}
+	}
+	return sel, nil
+}
+
+func (p *parser) optionalInt() (int, bool, error) {
+	start := p.pos
+	p.consume("-")
+	for !p.eof() && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		return 0, false, nil
+	}
+	n, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		return 0, false, p.errorf("invalid integer %q", p.src[start:p.pos])
+	}
+	return n, true, nil
+}
+
+// quoted 单引号或双引号字符串，支持反斜杠转义
+func (p *parser) quoted() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+	var b strings.Builder
+	for !p.eof() {
+		c := p.src[p.pos]
+		p.pos++
+		switch {
+		case c == '\\' && !p.eof():
+			b.WriteByte(p.src[p.pos])
+			p.pos++
+		case c == quote:
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) orExpr() (expression, error) {
+	left, err := p.andExpr()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.consume("||") {
+			return left, nil
+		}
+		right, err := p.andExpr()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{left: left, right: right}
+	}
+}
+
+func (p *parser) andExpr() (expression, error) {
+	left, err := p.unaryExpr()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.consume("&&") {
+			return left, nil
+		}
+		right, err := p.unaryExpr()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{and: true, left: left, right: right}
+	}
+}
+
+func (p *parser) unaryExpr() (expression, error) {
+	p.skipSpace()
+	if strings.HasPrefix(p.rest(), "!=") {
+		return nil, p.errorf("unexpected !=")
+	}
+	if p.consume("!") {
+		operand, err := p.unaryExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{operand: operand}, nil
+	}
+	if p.consume("(") {
+		expr, err := p.orExpr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+		return expr, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (expression, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			right, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			return &compareExpr{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) operand() (expression, error) {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		segments, err := p.segments()
+		if err != nil {
+			return nil, err
+		}
+		return &pathExpr{fromRoot: c == '$', segments: segments}, nil
+	case c == '\'' || c == '"':
+		s, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		return &literalExpr{value: s}, nil
+	case c == '-' || c >= '0' && c <= '9':
+		start := p.pos
+		p.pos++
+		for !p.eof() && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+		n, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", p.src[start:p.pos])
+		}
+		return &literalExpr{value: n}, nil
+	case p.consume("true"):
+		return &literalExpr{value: true}, nil
+	case p.consume("false"):
+		return &literalExpr{value: false}, nil
+	case p.consume("null"):
+		return &literalExpr{value: nil}, nil
+	}
+	return nil, p.errorf("expected operand")
+}
@@ -0,0 +1,114 @@
+package jsonpath
+
+// location 匹配的节点及其在父节点中的位置，根节点的 set 为 nil
+type location struct {
+	value interface{}
+	set   func(interface{})
+}
+
+// Replace 将所有匹配的节点替换为 replace 的返回值，返回替换的节点数
+//
+// doc 中的对象与数组被原地修改；根节点本身不会被替换。
+func (p *Path) Replace(doc interface{}, replace func(value interface{}) interface{}) int {
+	nodes := []location{{value: doc}}
+	for _, seg := range p.segments {
+		if seg.descendant {
+			var expanded []location
+			for _, node := range nodes {
+				expanded = descendantLocations(node, expanded)
+			}
+			nodes = expanded
+		}
+		next := []location{}
+		for _, node := range nodes {
+			next = selectLocations(seg.sel, node.value, doc, next)
+		}
+		nodes = next
+	}
+
+	replaced := 0
+	for _, node := range nodes {
+		if node.set != nil {
+			node.set(replace(node.value))
+			replaced++
+		}
+	}
+	return replaced
+}
+
+// selectLocations 与 selector.selectFrom 的选择规则相同，额外记录节点位置
+func selectLocations(sel selector, value, root interface{}, out []location) []location {
+	switch s := sel.(type) {
+	case wildcardSelector:
+		return append(out, childLocations(value)...)
+	case *unionSelector:
+		for _, item := range s.items {
+			switch key := item.(type) {
+			case string:
+				if obj, ok := value.(map[string]interface{}); ok {
+					if member, exists := obj[key]; exists {
+						out = append(out, memberLocation(obj, key, member))
+					}
+				}
+			case int:
+				if arr, ok := value.([]interface{}); ok {
+					if key < 0 {
+						key += len(arr)
+					}
+					if key >= 0 && key < len(arr) {
+						out = append(out, elementLocation(arr, key))
+					}
+				}
+			}
+		}
+	case *sliceSelector:
+		if arr, ok := value.([]interface{}); ok {
+			for _, i := range s.indices(len(arr)) {
+				out = append(out, elementLocation(arr, i))
+			}
+		}
+	case *filterSelector:
+		for _, child := range childLocations(value) {
+			if truthy(s.expr.eval(child.value, root)) {
+				out = append(out, child)
+			}
+		}
+	}
+	return out
+}
+
+// descendantLocations 按先序追加节点自身及其所有后代
+func descendantLocations(node location, out []location) []location {
+	out = append(out, node)
+	for _, child := range childLocations(node.value) {
+		out = descendantLocations(child, out)
+	}
+	return out
+}
+
+// childLocations 对象成员（按键名排序）或数组元素的位置
+func childLocations(value interface{}) []location {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make([]location, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			out = append(out, memberLocation(v, key, v[key]))
+		}
+		return out
+	case []interface{}:
+		out := make([]location, 0, len(v))
+		for i := range v {
+			out = append(out, elementLocation(v, i))
+		}
+		return out
+	}
+	return nil
+}
+
+func memberLocation(obj map[string]interface{}, key string, value interface{}) location {
+	return location{value: value, set: func(v interface{}) { obj[key] = v }}
+}
+
+func elementLocation(arr []interface{}, i int) location {
+	return location{value: arr[i], set: func(v interface{}) { arr[i] = v }}
+}
@@ -0,0 +1,177 @@
+// Package jsonstream 流式 JSON 编码
+//
+// encoding/json 在写出前将整个值编码到内存中，大型工具结果在响应期间会额外占用一份完整副本，
+// 高并发时这些临时缓冲成为主要的内存分配来源。实现 Marshaler 的值按字段直接写入底层 Writer，
+// 长字符串边转义边输出；写缓冲与其余值的编码缓冲通过 sync.Pool 复用。
+// 输出与 json.Marshal 逐字节一致（包括 HTML 字符转义）。
+package jsonstream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"unicode/utf8"
+)
+
+// bufferSize 写缓冲大小，超过后分段写入底层 Writer
+const bufferSize = 32 * 1024
+
+// maxPooledBuffer 放回池中的编码缓冲容量上限，避免个别大值长期占用内存
+const maxPooledBuffer = 64 * 1024
+
+// Marshaler 可直接写入 Stream 的值
+type Marshaler interface {
+	MarshalJSONStream(s *Stream)
+}
+
+// Stream 将 JSON 写入底层 Writer 的编码器，出现第一个错误后忽略后续写入
+type Stream struct {
+	w   *bufio.Writer
+	err error
+}
+
+var streamPool = sync.Pool{
+	New: func() interface{} {
+		return &Stream{w: bufio.NewWriterSize(nil, bufferSize)}
+	},
+}
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// Encode 将 v 编码写入 w；出错时返回错误，此前已写入 w 的部分不会撤回
+func Encode(w io.Writer, v interface{}) error {
+	s := streamPool.Get().(*Stream)
+	s.w.Reset(w)
+	s.err = nil
+
+	s.Value(v)
+	if s.err == nil {
+		s.err = s.w.Flush()
+	}
+	err := s.err
+
+	s.w.Reset(nil)
+	s.err = nil
+	streamPool.Put(s)
+	return err
+}
+
+// Err 返回编码过程中的第一个错误
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// Raw 原样写入已编码的 JSON 片段
+func (s *Stream) Raw(data string) {
+	if s.err != nil {
+		return
+	}
+	if _, err := s.w.WriteString(data); err != nil {
+		s.err = err
+	}
+}
+
+// Value 写入任意值：Marshaler 直接写入，其余值通过 encoding/json 编码到复用的缓冲后写入
+func (s *Stream) Value(v interface{}) {
+	if s.err != nil {
+		return
+	}
+	switch value := v.(type) {
+	case nil:
+		s.Raw("null")
+	case Marshaler:
+		value.MarshalJSONStream(s)
+	case string:
+		s.String(value)
+	default:
+		buf := bufferPool.Get().(*bytes.Buffer)
+		buf.Reset()
+		if err := json.NewEncoder(buf).Encode(v); err != nil {
+			s.err = err
+		} else if _, err := s.w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))); err != nil {
+			s.err = err
+		}
+		if buf.Cap() <= maxPooledBuffer {
+			bufferPool.Put(buf)
+		}
+	}
+}
+
+const hex = "0123456789abcdef"
+
+// String 写入转义后的字符串，规则与 encoding/json 相同
+func (s *Stream) String(str string) {
+	if s.err != nil {
+		return
+	}
+	w := s.w
+	w.WriteByte('"')
+	start := 0
+	for i := 0; i < len(str); {
+		if b := str[i]; b < utf8.RuneSelf {
+			if safeByte(b) {
+				i++
+				continue
+			}
+			w.WriteString(str[start:i])
+			switch b {
+			case '\\', '"':
+				w.WriteByte('\\')
+				w.WriteByte(b)
+			case '\b':
+				w.WriteString(`\b`)
+			case '\f':
+				w.WriteString(`\f`)
+			case '\n':
+				w.WriteString(`\n`)
+			case '\r':
+				w.WriteString(`\r`)
+			case '\t':
+				w.WriteString(`\t`)
+			default:
+				// 其余控制字符与 HTML 敏感字符 <、>、& 以 \u00XX 表示
+				w.WriteString(`\u00`)
+				w.WriteByte(hex[b>>4])
+				w.WriteByte(hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(str[i:])
+		if r == utf8.RuneError && size == 1 {
+			// 无效的 UTF-8 替换为 U+FFFD
+			w.WriteString(str[start:i])
+			w.WriteString("\ufffd")
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			// 行分隔符与段分隔符在 JavaScript 字符串中不合法
+			w.WriteString(str[start:i])
+			w.WriteString(`\u202`)
+			w.WriteByte(hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	w.WriteString(str[start:])
+	if err := w.WriteByte('"'); err != nil {
+		s.err = err
+	}
+}
+
+// safeByte ASCII 字符无需转义
+func safeByte(b byte) bool {
+	return b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&'
+}
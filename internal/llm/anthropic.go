@@ -0,0 +1,209 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// anthropicVersion Messages API 版本
+const anthropicVersion = "2023-06-01"
+
+// anthropicProvider Anthropic Messages API，system 提示单独传递，max_tokens 为必填参数
+type anthropicProvider struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// anthropicBlock 内容块，用于 text、tool_use 与 tool_result
+type anthropicBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"` // 字符串或内容块列表
+}
+
+// anthropicMessages 转换对话消息：工具调用转为 tool_use 块，
+// 连续的 tool 消息合并为一条包含 tool_result 块的 user 消息
+func anthropicMessages(messages []Message) []anthropicMessage {
+	converted := make([]anthropicMessage, 0, len(messages))
+	for _, message := range messages {
+		switch {
+		case message.Role == "tool":
+			block := anthropicBlock{Type: "tool_result", ToolUseID: message.ToolCallID, Content: message.Content}
+			if n := len(converted); n > 0 && converted[n-1].Role == "user" {
+				if blocks, ok := converted[n-1].Content.([]anthropicBlock); ok && blocks[0].Type == "tool_result" {
+					converted[n-1].Content = append(blocks, block)
+					continue
+				}
+			}
+			converted = append(converted, anthropicMessage{Role: "user", Content: []anthropicBlock{block}})
+		case len(message.ToolCalls) > 0:
+			var blocks []anthropicBlock
+			if message.Content != "" {
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: message.Content})
+			}
+			for _, call := range message.ToolCalls {
+				input := call.Arguments
+				if len(input) == 0 {
+					input = json.RawMessage(`{}`)
+				}
+				blocks = append(blocks, anthropicBlock{Type: "tool_use", ID: call.ID, Name: call.Name, Input: input})
+			}
+			converted = append(converted, anthropicMessage{Role: message.Role, Content: blocks})
+		default:
+			converted = append(converted, anthropicMessage{Role: message.Role, Content: message.Content})
+		}
+	}
+	return converted
+}
+
+func (p *anthropicProvider) body(req Request, stream bool) map[string]interface{} {
+	body := map[string]interface{}{
+		"model":      req.Model,
+		"messages":   anthropicMessages(req.Messages),
+		"max_tokens": req.maxTokens(),
+	}
+	if len(req.Tools) > 0 {
+		tools := make([]map[string]interface{}, 0, len(req.Tools))
+		for _, tool := range req.Tools {
+			tools = append(tools, map[string]interface{}{
+				"name":         tool.Name,
+				"description":  tool.Description,
+				"input_schema": tool.Parameters,
+			})
+		}
+		body["tools"] = tools
+	}
+	if req.System != "" {
+		body["system"] = req.System
+	}
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	if stream {
+		body["stream"] = true
+	}
+	return body
+}
+
+func (p *anthropicProvider) headers() map[string]string {
+	return map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+}
+
+func (p *anthropicProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	var response struct {
+		Model      string           `json:"model"`
+		Content    []anthropicBlock `json:"content"`
+		StopReason string           `json:"stop_reason"`
+		Usage      anthropicUsage   `json:"usage"`
+	}
+	if err := postJSON(ctx, p.client, p.baseURL+"/v1/messages", p.headers(), p.body(req, false), &response); err != nil {
+		return nil, err
+	}
+
+	var content strings.Builder
+	var toolCalls []ToolCall
+	for _, block := range response.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "tool_use":
+			arguments, err := toolArguments(block.Input)
+			if err != nil {
+				return nil, err
+			}
+			toolCalls = append(toolCalls, ToolCall{ID: block.ID, Name: block.Name, Arguments: arguments})
+		}
+	}
+	if content.Len() == 0 && len(toolCalls) == 0 && response.StopReason == "" {
+		return nil, ErrEmptyResponse
+	}
+	return &Response{
+		Model:        response.Model,
+		Content:      content.String(),
+		ToolCalls:    toolCalls,
+		FinishReason: response.StopReason,
+		Usage:        Usage(response.Usage),
+	}, nil
+}
+
+func (p *anthropicProvider) Stream(ctx context.Context, req Request, onDelta func(delta string)) (*Response, error) {
+	resp, err := post(ctx, p.client, p.baseURL+"/v1/messages", p.headers(), p.body(req, true))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &Response{Model: req.Model}
+	var content strings.Builder
+	err = readLines(resp.Body, func(line string) (bool, error) {
+		data, ok := sseData(line)
+		if !ok || data == "" {
+			return true, nil
+		}
+		var event struct {
+			Type    string `json:"type"`
+			Message struct {
+				Model string         `json:"model"`
+				Usage anthropicUsage `json:"usage"`
+			} `json:"message"`
+			Delta struct {
+				Type       string `json:"type"`
+				Text       string `json:"text"`
+				StopReason string `json:"stop_reason"`
+			} `json:"delta"`
+			Usage anthropicUsage `json:"usage"`
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return false, fmt.Errorf("invalid llm stream event: %v", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			result.Model = event.Message.Model
+			result.Usage.InputTokens = event.Message.Usage.InputTokens
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+				content.WriteString(event.Delta.Text)
+				onDelta(event.Delta.Text)
+			}
+		case "message_delta":
+			result.FinishReason = event.Delta.StopReason
+			result.Usage.OutputTokens = event.Usage.OutputTokens
+		case "message_stop":
+			return false, nil
+		case "error":
+			return false, fmt.Errorf("llm provider error: %s: %s", event.Error.Type, event.Error.Message)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Content = content.String()
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Embeddings 向量化结果，Vectors 与输入一一对应
+type Embeddings struct {
+	Model   string      `json:"model"`
+	Vectors [][]float64 `json:"vectors"`
+	Usage   Usage       `json:"usage"`
+}
+
+// Embedder 文本向量化服务
+type Embedder interface {
+	Embed(ctx context.Context, model string, inputs []string) (*Embeddings, error)
+}
+
+// NewEmbedder 创建向量化服务客户端，Anthropic 不提供向量化接口
+func NewEmbedder(opts Options) (Embedder, error) {
+	if opts.Type == ProviderAnthropic {
+		return nil, fmt.Errorf("provider type %q does not support embeddings", opts.Type)
+	}
+	provider, err := NewProvider(opts)
+	if err != nil {
+		return nil, err
+	}
+	return provider.(Embedder), nil
+}
+
+func (p *openAIProvider) Embed(ctx context.Context, model string, inputs []string) (*Embeddings, error) {
+	var response struct {
+		Model string `json:"model"`
+		Data  []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+		Usage struct {
+			PromptTokens int `json:"prompt_tokens"`
+		} `json:"usage"`
+	}
+	body := map[string]interface{}{"model": model, "input": inputs}
+	if err := postJSON(ctx, p.client, p.baseURL+"/embeddings", p.headers(), body, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Data) != len(inputs) {
+		return nil, fmt.Errorf("provider returned %d embeddings for %d inputs", len(response.Data), len(inputs))
+	}
+	// 按 index 排序，兼容服务不保证返回顺序
+	sort.Slice(response.Data, func(i, j int) bool { return response.Data[i].Index < response.Data[j].Index })
+
+	result := &Embeddings{Model: model, Vectors: make([][]float64, len(inputs))}
+	if response.Model != "" {
+		result.Model = response.Model
+	}
+	for i, item := range response.Data {
+		result.Vectors[i] = item.Embedding
+	}
+	result.Usage.InputTokens = response.Usage.PromptTokens
+	return result, nil
+}
+
+func (p *ollamaProvider) Embed(ctx context.Context, model string, inputs []string) (*Embeddings, error) {
+	var response struct {
+		Model           string      `json:"model"`
+		Embeddings      [][]float64 `json:"embeddings"`
+		PromptEvalCount int         `json:"prompt_eval_count"`
+		Error           string      `json:"error"`
+	}
+	body := map[string]interface{}{"model": model, "input": inputs}
+	if err := postJSON(ctx, p.client, p.baseURL+"/api/embed", nil, body, &response); err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("llm provider error: %s", response.Error)
+	}
+	if len(response.Embeddings) != len(inputs) {
+		return nil, fmt.Errorf("provider returned %d embeddings for %d inputs", len(response.Embeddings), len(inputs))
+	}
+
+	result := &Embeddings{Model: model, Vectors: response.Embeddings, Usage: Usage{InputTokens: response.PromptEvalCount}}
+	if response.Model != "" {
+		result.Model = response.Model
+	}
+	return result, nil
+}
@@ -0,0 +1,252 @@
+// Package llm 大模型服务客户端
+//
+// 以统一的 Provider 接口封装 OpenAI（及兼容接口）、Anthropic 与 Ollama 的对话补全，
+// 支持一次性返回与流式返回，OpenAI 与 Ollama 另提供文本向量化接口。各工具共用同一套客户端，不在此处处理配置与密钥来源。
+// 请求可附带函数工具定义，Complete 返回模型请求的工具调用，由调用方执行后以 tool 消息回传。
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// 服务类型
+const (
+	ProviderOpenAI           = "openai"
+	ProviderOpenAICompatible = "openai_compatible"
+	ProviderAnthropic        = "anthropic"
+	ProviderOllama           = "ollama"
+)
+
+// 默认服务地址
+const (
+	DefaultOpenAIURL    = "https://api.openai.com/v1"
+	DefaultAnthropicURL = "https://api.anthropic.com"
+	DefaultOllamaURL    = "http://localhost:11434"
+)
+
+const (
+	// DefaultMaxTokens 未指定时的最大输出 token 数，Anthropic 要求必须提供
+	DefaultMaxTokens = 1024
+	errorBodyBytes   = 512
+	requestTimeout   = 5 * time.Minute
+	maxStreamLine    = 1 << 20
+)
+
+// ErrEmptyResponse 服务返回的结果中没有内容
+var ErrEmptyResponse = werrors.New(werrors.KindUpstreamFailure, "empty response from provider")
+
+// Message 对话消息
+type Message struct {
+	Role       string     `json:"role"` // system, user, assistant, tool
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // assistant 消息中模型请求的工具调用
+	ToolCallID string     `json:"tool_call_id,omitempty"` // tool 消息对应的工具调用 ID
+}
+
+// ToolCall 模型请求的工具调用
+type ToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"` // JSON 对象
+}
+
+// ToolDefinition 提供给模型的函数工具
+type ToolDefinition struct {
+	Name        string
+	Description string
+	Parameters  interface{} // 参数的 JSON Schema
+}
+
+// Request 补全请求
+type Request struct {
+	Model       string
+	System      string
+	Messages    []Message
+	Temperature *float64
+	MaxTokens   int
+	Tools       []ToolDefinition // 仅 Complete 返回工具调用，Stream 只输出文本
+}
+
+// Usage token 用量，服务未返回时为 0
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Response 补全结果
+type Response struct {
+	Model        string     `json:"model"`
+	Content      string     `json:"content"`
+	ToolCalls    []ToolCall `json:"tool_calls,omitempty"`
+	FinishReason string     `json:"finish_reason,omitempty"`
+	Usage        Usage      `json:"usage"`
+}
+
+// Provider 大模型服务
+type Provider interface {
+	// Complete 等待完整结果
+	Complete(ctx context.Context, req Request) (*Response, error)
+	// Stream 每收到一段输出调用 onDelta，返回拼接后的完整结果
+	Stream(ctx context.Context, req Request, onDelta func(delta string)) (*Response, error)
+}
+
+// Options 服务连接参数
+type Options struct {
+	Type   string // openai, openai_compatible, anthropic, ollama
+	URL    string // 为空时使用官方或本地默认地址，openai_compatible 必须提供
+	APIKey string
+	Client *http.Client // 为空时使用默认客户端
+}
+
+// NewProvider 创建大模型服务客户端
+func NewProvider(opts Options) (Provider, error) {
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	base := func(fallback string) string {
+		if opts.URL == "" {
+			return fallback
+		}
+		return strings.TrimRight(opts.URL, "/")
+	}
+
+	switch opts.Type {
+	case ProviderOpenAI:
+		if opts.APIKey == "" {
+			return nil, fmt.Errorf("api key is required for %s", opts.Type)
+		}
+		return &openAIProvider{client: client, baseURL: base(DefaultOpenAIURL), apiKey: opts.APIKey, includeUsage: true}, nil
+	case ProviderOpenAICompatible:
+		if opts.URL == "" {
+			return nil, fmt.Errorf("url is required for %s", opts.Type)
+		}
+		return &openAIProvider{client: client, baseURL: base(""), apiKey: opts.APIKey}, nil
+	case ProviderAnthropic:
+		if opts.APIKey == "" {
+			return nil, fmt.Errorf("api key is required for %s", opts.Type)
+		}
+		return &anthropicProvider{client: client, baseURL: base(DefaultAnthropicURL), apiKey: opts.APIKey}, nil
+	case ProviderOllama:
+		return &ollamaProvider{client: client, baseURL: base(DefaultOllamaURL)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported provider type %q", opts.Type)
+	}
+}
+
+// post 发送 JSON 请求，非 2xx 状态码返回包含响应片段的错误；调用方负责关闭响应体
+func post(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, body interface{}) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("llm request failed: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, errorBodyBytes))
+		return nil, fmt.Errorf("llm provider returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return resp, nil
+}
+
+// postJSON 发送请求并解码 JSON 响应
+func postJSON(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, body, out interface{}) error {
+	resp, err := post(ctx, client, endpoint, headers, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid llm response: %v", err)
+	}
+	return nil
+}
+
+// readLines 逐行读取流式响应，handle 返回 false 时停止
+func readLines(body io.Reader, handle func(line string) (bool, error)) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64<<10), maxStreamLine)
+	for scanner.Scan() {
+		more, err := handle(scanner.Text())
+		if err != nil || !more {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read llm stream: %v", err)
+	}
+	return nil
+}
+
+// sseData 返回 SSE data 行的内容，其余行返回 false
+func sseData(line string) (string, bool) {
+	data, ok := strings.CutPrefix(line, "data:")
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSpace(data), true
+}
+
+// messages 将 system 提示与对话消息合并为 OpenAI 与 Ollama 使用的消息列表
+func (r Request) messages() []Message {
+	if r.System == "" {
+		return r.Messages
+	}
+	return append([]Message{{Role: "system", Content: r.System}}, r.Messages...)
+}
+
+// functionTools OpenAI 与 Ollama 使用的函数工具列表
+func (r Request) functionTools() []map[string]interface{} {
+	tools := make([]map[string]interface{}, 0, len(r.Tools))
+	for _, tool := range r.Tools {
+		tools = append(tools, map[string]interface{}{
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":        tool.Name,
+				"description": tool.Description,
+				"parameters":  tool.Parameters,
+			},
+		})
+	}
+	return tools
+}
+
+// toolArguments 规范化工具调用参数，为空时视为空对象
+func toolArguments(raw []byte) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return json.RawMessage(`{}`), nil
+	}
+	if !json.Valid(raw) {
+		return nil, fmt.Errorf("invalid tool call arguments: %s", raw)
+	}
+	return json.RawMessage(raw), nil
+}
+
+func (r Request) maxTokens() int {
+	if r.MaxTokens > 0 {
+		return r.MaxTokens
+	}
+	return DefaultMaxTokens
+}
@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ollamaProvider Ollama /api/chat 接口，流式响应为逐行的 JSON 对象
+type ollamaProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+type ollamaResponse struct {
+	Model   string `json:"model"`
+	Message struct {
+		Content   string           `json:"content"`
+		ToolCalls []ollamaToolCall `json:"tool_calls"`
+	} `json:"message"`
+	Done            bool   `json:"done"`
+	DoneReason      string `json:"done_reason"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	Error           string `json:"error"`
+}
+
+// ollamaMessage 对话消息，工具调用参数为 JSON 对象
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+func ollamaMessages(messages []Message) []ollamaMessage {
+	converted := make([]ollamaMessage, 0, len(messages))
+	for _, message := range messages {
+		item := ollamaMessage{Role: message.Role, Content: message.Content}
+		for _, call := range message.ToolCalls {
+			var toolCall ollamaToolCall
+			toolCall.Function.Name = call.Name
+			toolCall.Function.Arguments = call.Arguments
+			item.ToolCalls = append(item.ToolCalls, toolCall)
+		}
+		converted = append(converted, item)
+	}
+	return converted
+}
+
+func (p *ollamaProvider) body(req Request, stream bool) map[string]interface{} {
+	options := map[string]interface{}{}
+	if req.Temperature != nil {
+		options["temperature"] = *req.Temperature
+	}
+	if req.MaxTokens > 0 {
+		options["num_predict"] = req.MaxTokens
+	}
+	body := map[string]interface{}{
+		"model":    req.Model,
+		"messages": ollamaMessages(req.messages()),
+		"stream":   stream,
+		"options":  options,
+	}
+	if len(req.Tools) > 0 {
+		body["tools"] = req.functionTools()
+	}
+	return body
+}
+
+// finish 记录最后一个响应中的结束原因与用量
+func (p *ollamaProvider) finish(result *Response, response ollamaResponse) {
+	if response.Model != "" {
+		result.Model = response.Model
+	}
+	result.FinishReason = response.DoneReason
+	result.Usage = Usage{InputTokens: response.PromptEvalCount, OutputTokens: response.EvalCount}
+}
+
+func (p *ollamaProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	var response ollamaResponse
+	if err := postJSON(ctx, p.client, p.baseURL+"/api/chat", nil, p.body(req, false), &response); err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("llm provider error: %s", response.Error)
+	}
+	result := &Response{Model: req.Model, Content: response.Message.Content}
+	p.finish(result, response)
+	// Ollama 的工具调用没有 ID，按调用时间与序号生成，tool 消息按顺序对应
+	prefix := "call_" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	for i, call := range response.Message.ToolCalls {
+		arguments, err := toolArguments(call.Function.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		result.ToolCalls = append(result.ToolCalls, ToolCall{ID: fmt.Sprintf("%s_%d", prefix, i), Name: call.Function.Name, Arguments: arguments})
+	}
+	return result, nil
+}
+
+func (p *ollamaProvider) Stream(ctx context.Context, req Request, onDelta func(delta string)) (*Response, error) {
+	resp, err := post(ctx, p.client, p.baseURL+"/api/chat", nil, p.body(req, true))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &Response{Model: req.Model}
+	var content strings.Builder
+	err = readLines(resp.Body, func(line string) (bool, error) {
+		if strings.TrimSpace(line) == "" {
+			return true, nil
+		}
+		var chunk ollamaResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return false, fmt.Errorf("invalid llm stream event: %v", err)
+		}
+		if chunk.Error != "" {
+			return false, fmt.Errorf("llm provider error: %s", chunk.Error)
+		}
+		if delta := chunk.Message.Content; delta != "" {
+			content.WriteString(delta)
+			onDelta(delta)
+		}
+		if chunk.Done {
+			p.finish(result, chunk)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Content = content.String()
+	return result, nil
+}
@@ -0,0 +1,171 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// openAIProvider OpenAI chat completions 接口，也用于 vLLM、LM Studio 等兼容服务
+type openAIProvider struct {
+	client       *http.Client
+	baseURL      string
+	apiKey       string
+	includeUsage bool // 流式请求时要求返回用量，部分兼容服务不支持该参数
+}
+
+type openAIChoice struct {
+	Message struct {
+		Content   string           `json:"content"`
+		ToolCalls []openAIToolCall `json:"tool_calls"`
+	} `json:"message"`
+	Delta struct {
+		Content string `json:"content"`
+	} `json:"delta"`
+	FinishReason *string `json:"finish_reason"`
+}
+
+type openAIResponse struct {
+	Model   string         `json:"model"`
+	Choices []openAIChoice `json:"choices"`
+	Usage   *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// openAIMessage 对话消息，工具调用参数以 JSON 字符串传递
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    string           `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"` // function
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+func openAIMessages(messages []Message) []openAIMessage {
+	converted := make([]openAIMessage, 0, len(messages))
+	for _, message := range messages {
+		item := openAIMessage{Role: message.Role, Content: message.Content, ToolCallID: message.ToolCallID}
+		for _, call := range message.ToolCalls {
+			toolCall := openAIToolCall{ID: call.ID, Type: "function"}
+			toolCall.Function.Name = call.Name
+			toolCall.Function.Arguments = string(call.Arguments)
+			item.ToolCalls = append(item.ToolCalls, toolCall)
+		}
+		converted = append(converted, item)
+	}
+	return converted
+}
+
+func (p *openAIProvider) body(req Request, stream bool) map[string]interface{} {
+	body := map[string]interface{}{
+		"model":    req.Model,
+		"messages": openAIMessages(req.messages()),
+	}
+	if len(req.Tools) > 0 {
+		body["tools"] = req.functionTools()
+	}
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	if req.MaxTokens > 0 {
+		body["max_tokens"] = req.MaxTokens
+	}
+	if stream {
+		body["stream"] = true
+		if p.includeUsage {
+			body["stream_options"] = map[string]bool{"include_usage": true}
+		}
+	}
+	return body
+}
+
+func (p *openAIProvider) headers() map[string]string {
+	if p.apiKey == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + p.apiKey}
+}
+
+// merge 将一次响应或一个流式片段合并到结果中，返回片段中的增量文本
+func (p *openAIProvider) merge(result *Response, chunk openAIResponse) string {
+	if chunk.Model != "" {
+		result.Model = chunk.Model
+	}
+	if chunk.Usage != nil {
+		result.Usage = Usage{InputTokens: chunk.Usage.PromptTokens, OutputTokens: chunk.Usage.CompletionTokens}
+	}
+	if len(chunk.Choices) == 0 {
+		return ""
+	}
+	choice := chunk.Choices[0]
+	if choice.FinishReason != nil {
+		result.FinishReason = *choice.FinishReason
+	}
+	return choice.Message.Content + choice.Delta.Content
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	var response openAIResponse
+	if err := postJSON(ctx, p.client, p.baseURL+"/chat/completions", p.headers(), p.body(req, false), &response); err != nil {
+		return nil, err
+	}
+	if len(response.Choices) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	result := &Response{Model: req.Model}
+	result.Content = p.merge(result, response)
+	for _, call := range response.Choices[0].Message.ToolCalls {
+		arguments, err := toolArguments([]byte(call.Function.Arguments))
+		if err != nil {
+			return nil, err
+		}
+		result.ToolCalls = append(result.ToolCalls, ToolCall{ID: call.ID, Name: call.Function.Name, Arguments: arguments})
+	}
+	return result, nil
+}
+
+func (p *openAIProvider) Stream(ctx context.Context, req Request, onDelta func(delta string)) (*Response, error) {
+	resp, err := post(ctx, p.client, p.baseURL+"/chat/completions", p.headers(), p.body(req, true))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &Response{Model: req.Model}
+	var content strings.Builder
+	err = readLines(resp.Body, func(line string) (bool, error) {
+		data, ok := sseData(line)
+		if !ok || data == "" {
+			return true, nil
+		}
+		if data == "[DONE]" {
+			return false, nil
+		}
+		var chunk openAIResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return false, fmt.Errorf("invalid llm stream event: %v", err)
+		}
+		if delta := p.merge(result, chunk); delta != "" {
+			content.WriteString(delta)
+			onDelta(delta)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Content = content.String()
+	return result, nil
+}
@@ -0,0 +1,265 @@
+// Package loadgen MCP 服务器压测
+//
+// 以固定并发向 /mcp 端点发送 tools/call 请求（可选 SSE 流式响应），记录每次请求的耗时，
+// 汇总吞吐量与 p50/p90/p99 延迟。cmd/loadgen 与 test 包中的基准测试共用此实现，
+// 用于发现锁与调度等改动带来的性能回退。
+package loadgen
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options 压测参数
+type Options struct {
+	URL         string          // MCP 端点，如 http://127.0.0.1:8080/mcp
+	APIKey      string          // 服务器配置了 MCP_API_KEY 时需要
+	Tool        string          // 调用的工具
+	Arguments   json.RawMessage // 工具参数
+	Stream      bool            // 通过 Accept: text/event-stream 请求流式响应
+	Concurrency int             // 并发请求数，默认 1
+	Requests    int             // 请求总数，0 为持续到 Duration 结束
+	Duration    time.Duration   // 压测时长，Requests 为 0 时生效
+	Client      *http.Client    // 为空时使用按并发数配置连接池的客户端
+}
+
+// Report 压测结果
+type Report struct {
+	Requests   int
+	Errors     int
+	FirstError string        // 第一个失败请求的错误信息
+	Elapsed    time.Duration // 压测总耗时
+	latencies  []time.Duration
+	firstEvent []time.Duration // 流式请求收到第一个事件的耗时
+}
+
+// Run 执行压测，ctx 取消时提前结束并返回已完成请求的统计
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.URL == "" || opts.Tool == "" {
+		return nil, fmt.Errorf("url and tool are required")
+	}
+	if opts.Requests <= 0 && opts.Duration <= 0 {
+		return nil, fmt.Errorf("either requests or duration must be set")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if len(opts.Arguments) == 0 {
+		opts.Arguments = json.RawMessage(`{}`)
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Transport: &http.Transport{
+			MaxIdleConns:        opts.Concurrency,
+			MaxIdleConnsPerHost: opts.Concurrency,
+		}}
+	}
+	if opts.Requests <= 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	var (
+		issued  int64
+		mu      sync.Mutex
+		report  = &Report{}
+		wg      sync.WaitGroup
+		started = time.Now()
+	)
+	for worker := 0; worker < opts.Concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var latencies, firstEvent []time.Duration
+			var errors int
+			var firstError string
+			for ctx.Err() == nil {
+				n := atomic.AddInt64(&issued, 1)
+				if opts.Requests > 0 && n > int64(opts.Requests) {
+					break
+				}
+				begin := time.Now()
+				first, err := call(ctx, opts, n)
+				if ctx.Err() != nil && opts.Requests <= 0 {
+					// 压测时长结束时被中断的请求不计入统计
+					break
+				}
+				latencies = append(latencies, time.Since(begin))
+				if first > 0 {
+					firstEvent = append(firstEvent, first)
+				}
+				if err != nil {
+					if errors == 0 {
+						firstError = err.Error()
+					}
+					errors++
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.latencies = append(report.latencies, latencies...)
+			report.firstEvent = append(report.firstEvent, firstEvent...)
+			report.Errors += errors
+			if report.FirstError == "" {
+				report.FirstError = firstError
+			}
+		}()
+	}
+	wg.Wait()
+
+	report.Elapsed = time.Since(started)
+	report.Requests = len(report.latencies)
+	sort.Slice(report.latencies, func(i, j int) bool { return report.latencies[i] < report.latencies[j] })
+	sort.Slice(report.firstEvent, func(i, j int) bool { return report.firstEvent[i] < report.firstEvent[j] })
+	return report, nil
+}
+
+// call 发送一次 tools/call 请求，流式请求同时返回收到第一个事件的耗时
+func call(ctx context.Context, opts Options, id int64) (time.Duration, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      opts.Tool,
+			"arguments": opts.Arguments,
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if opts.APIKey != "" {
+		req.Header.Set("X-API-Key", opts.APIKey)
+	}
+	if opts.Stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	begin := time.Now()
+	resp, err := opts.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	if opts.Stream {
+		return readEvents(resp.Body, begin)
+	}
+	var result struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	if result.Error != nil {
+		return 0, fmt.Errorf("rpc error %d: %s", result.Error.Code, result.Error.Message)
+	}
+	return 0, nil
+}
+
+// readEvents 读取完整的 SSE 响应，出现 error 事件或未收到 done 事件时返回错误
+func readEvents(body io.Reader, begin time.Time) (time.Duration, error) {
+	var first time.Duration
+	var event string
+	done := false
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+			if first == 0 {
+				first = time.Since(begin)
+			}
+		case strings.HasPrefix(line, "data: "):
+			switch event {
+			case "error":
+				return first, fmt.Errorf("stream error: %s", strings.TrimPrefix(line, "data: "))
+			case "done":
+				done = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return first, err
+	}
+	if !done {
+		return first, fmt.Errorf("stream ended without done event")
+	}
+	return first, nil
+}
+
+// Percentile 返回延迟的 p 分位数（0 到 1）
+func (r *Report) Percentile(p float64) time.Duration {
+	return percentile(r.latencies, p)
+}
+
+// FirstEventPercentile 返回流式请求收到第一个事件耗时的 p 分位数
+func (r *Report) FirstEventPercentile(p float64) time.Duration {
+	return percentile(r.firstEvent, p)
+}
+
+// Throughput 每秒完成的请求数
+func (r *Report) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Elapsed.Seconds()
+}
+
+// String 以文本形式输出统计结果
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "requests:   %d (%d errors) in %s\n", r.Requests, r.Errors, r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(&b, "throughput: %.1f req/s\n", r.Throughput())
+	fmt.Fprintf(&b, "latency:    p50 %s  p90 %s  p99 %s  max %s\n",
+		r.Percentile(0.5), r.Percentile(0.9), r.Percentile(0.99), r.Percentile(1))
+	if len(r.firstEvent) > 0 {
+		fmt.Fprintf(&b, "first event: p50 %s  p90 %s  p99 %s\n",
+			r.FirstEventPercentile(0.5), r.FirstEventPercentile(0.9), r.FirstEventPercentile(0.99))
+	}
+	if r.FirstError != "" {
+		fmt.Fprintf(&b, "first error: %s\n", r.FirstError)
+	}
+	return b.String()
+}
+
+// percentile 按最近秩法取已排序样本的分位数
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
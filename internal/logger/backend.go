@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+// Backend 接收结构化日志的外部日志库
+//
+// 方法签名与 *slog.Logger 一致，可直接传入 slog.Default()；zap 等日志库包装为该接口即可接收日志。
+// args 为交替的字段名与字段值。
+type Backend interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// NewWithBackend 创建将日志转发到 backend 的日志管理器
+//
+// 日志不写入文件，时间戳由 backend 记录；全局日志级别（SetLevel）仍然生效，backend 可再自行过滤。
+func NewWithBackend(backend Backend) *Logger {
+	return &Logger{
+		Logger: zerolog.New(&backendWriter{backend: backend}).Level(zerolog.TraceLevel),
+	}
+}
+
+// backendWriter 将 zerolog 输出的 JSON 日志解码后转发到 Backend
+type backendWriter struct {
+	backend Backend
+}
+
+// Write 实现 io.Writer，级别取日志中的 level 字段
+func (w *backendWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel 实现 zerolog.LevelWriter
+func (w *backendWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	message, args, err := decodeEvent(p)
+	if err != nil {
+		return 0, err
+	}
+
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		w.backend.Debug(message, args...)
+	case zerolog.WarnLevel:
+		w.backend.Warn(message, args...)
+	case zerolog.ErrorLevel, zerolog.FatalLevel, zerolog.PanicLevel:
+		w.backend.Error(message, args...)
+	default:
+		w.backend.Info(message, args...)
+	}
+	return len(p), nil
+}
+
+// decodeEvent 按字段顺序解码一条 JSON 日志，返回消息与除 level、message 外的字段
+func decodeEvent(p []byte) (string, []any, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return "", nil, fmt.Errorf("invalid log event: %s", p)
+	}
+
+	var message string
+	var args []any
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", nil, err
+		}
+		key, _ := tok.(string)
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return "", nil, err
+		}
+
+		switch key {
+		case zerolog.LevelFieldName:
+		case zerolog.MessageFieldName:
+			message, _ = value.(string)
+		default:
+			args = append(args, key, normalizeNumber(value))
+		}
+	}
+	return message, args, nil
+}
+
+// normalizeNumber 将字段中的数字转换为 int64 或 float64，便于 backend 按类型输出
+func normalizeNumber(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeNumber(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeNumber(item)
+		}
+	}
+	return value
+}
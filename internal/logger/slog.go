@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// 日志后端
+const (
+	BackendZerolog = "zerolog" // 默认，控制台彩色输出并写入日志文件
+	BackendSlog    = "slog"    // log/slog JSON 输出到标准输出并写入日志文件
+)
+
+// LevelTrace trace 级别对应的 slog 级别
+const LevelTrace = slog.LevelDebug - 4
+
+// LevelFatal fatal、panic 级别对应的 slog 级别
+const LevelFatal = slog.LevelError + 4
+
+// New 按后端名称创建写入日志目录的日志管理器，空名称使用 zerolog
+func New(logDir, level, backend string) (*Logger, error) {
+	switch backend {
+	case "", BackendZerolog:
+		return NewLogger(logDir, level)
+	case BackendSlog:
+		return newSlogLogger(logDir, level)
+	default:
+		return nil, fmt.Errorf("unknown log backend: %q", backend)
+	}
+}
+
+// newSlogLogger 创建以 slog JSON handler 输出到标准输出与日志文件的日志管理器
+func newSlogLogger(logDir, level string) (*Logger, error) {
+	file, err := openLogFile(logDir)
+	if err != nil {
+		return nil, err
+	}
+
+	logLevel, err := zerolog.ParseLevel(level)
+	if err != nil {
+		logLevel = zerolog.InfoLevel
+	}
+	zerolog.SetGlobalLevel(logLevel)
+
+	// 级别由全局级别过滤，handler 接收所有级别
+	handler := slog.NewJSONHandler(io.MultiWriter(os.Stdout, file), &slog.HandlerOptions{Level: LevelTrace})
+	log := NewWithHandler(handler)
+	log.file = file
+	return log, nil
+}
+
+// NewWithHandler 创建将日志转发到 slog.Handler 的日志管理器
+//
+// 与 NewWithBackend 不同，trace、fatal 级别映射为 LevelTrace、LevelFatal，嵌套对象转换为 slog.Group，
+// 嵌入应用的 handler 可按级别与属性类型处理日志。全局日志级别（SetLevel）仍然生效。
+func NewWithHandler(handler slog.Handler) *Logger {
+	return &Logger{
+		Logger: zerolog.New(&handlerWriter{handler: handler}).Level(zerolog.TraceLevel),
+	}
+}
+
+// handlerWriter 将 zerolog 输出的 JSON 日志解码为 slog.Record 交给 handler 处理
+type handlerWriter struct {
+	handler slog.Handler
+}
+
+// Write 实现 io.Writer
+func (w *handlerWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel 实现 zerolog.LevelWriter，handler 未启用该级别时跳过解码
+func (w *handlerWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	ctx := context.Background()
+	lvl := slogLevel(level)
+	if !w.handler.Enabled(ctx, lvl) {
+		return len(p), nil
+	}
+
+	message, args, err := decodeEvent(p)
+	if err != nil {
+		return 0, err
+	}
+
+	record := slog.NewRecord(time.Now(), lvl, message, 0)
+	for i := 0; i+1 < len(args); i += 2 {
+		key, _ := args[i].(string)
+		record.AddAttrs(toAttr(key, args[i+1]))
+	}
+	if err := w.handler.Handle(ctx, record); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// slogLevel zerolog 级别对应的 slog 级别，未带级别的日志按 info 处理
+func slogLevel(level zerolog.Level) slog.Level {
+	switch level {
+	case zerolog.TraceLevel:
+		return LevelTrace
+	case zerolog.DebugLevel:
+		return slog.LevelDebug
+	case zerolog.WarnLevel:
+		return slog.LevelWarn
+	case zerolog.ErrorLevel:
+		return slog.LevelError
+	case zerolog.FatalLevel, zerolog.PanicLevel:
+		return LevelFatal
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// toAttr 将解码后的字段转换为 slog 属性，对象转换为按键排序的 Group
+func toAttr(key string, value any) slog.Attr {
+	switch v := value.(type) {
+	case string:
+		return slog.String(key, v)
+	case int64:
+		return slog.Int64(key, v)
+	case float64:
+		return slog.Float64(key, v)
+	case bool:
+		return slog.Bool(key, v)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		attrs := make([]any, 0, len(keys))
+		for _, k := range keys {
+			attrs = append(attrs, toAttr(k, v[k]))
+		}
+		return slog.Group(key, attrs...)
+	default:
+		return slog.Any(key, v)
+	}
+}
@@ -0,0 +1,285 @@
+// Package manifest 将已注册工具导出为 OpenAPI 文档、OpenAI 函数调用清单与智能体框架使用的工具定义，
+// 供不使用 MCP 的客户端按同一组工具及参数 Schema 集成。
+package manifest
+
+import (
+	"sort"
+
+	"Weave-Toolkit/internal/schema"
+	"Weave-Toolkit/internal/tools"
+)
+
+// OpenAPIVersion 导出的 OpenAPI 版本，3.1 的 Schema 与 JSON Schema 2020-12 兼容，可直接使用工具的参数 Schema
+const OpenAPIVersion = "3.1.0"
+
+// Info 文档信息
+type Info struct {
+	Title      string
+	Version    string
+	ServerURL  string // 为空时不输出 servers
+	PathPrefix string // REST 桥接的工具端点前缀，设置后为每个工具输出 POST {PathPrefix}/{name}
+	BearerAuth bool   // 端点需要 Authorization: Bearer 鉴权
+}
+
+// OpenAPIDocument OpenAPI 文档
+type OpenAPIDocument struct {
+	OpenAPI    string                 `json:"openapi"`
+	Info       OpenAPIInfo            `json:"info"`
+	Servers    []OpenAPIServer        `json:"servers,omitempty"`
+	Paths      map[string]interface{} `json:"paths"`
+	Components OpenAPIComponents      `json:"components"`
+	Security   []map[string][]string  `json:"security,omitempty"`
+}
+
+// OpenAPIInfo 文档信息
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIServer 服务地址
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+// OpenAPIComponents 可复用组件，每个工具的参数 Schema 以工具名为键
+type OpenAPIComponents struct {
+	Schemas         map[string]*schema.Schema         `json:"schemas"`
+	SecuritySchemes map[string]map[string]interface{} `json:"securitySchemes,omitempty"`
+}
+
+// OpenAITool OpenAI 函数调用清单中的工具
+type OpenAITool struct {
+	Type     string         `json:"type"` // function
+	Function OpenAIFunction `json:"function"`
+}
+
+// OpenAIFunction 函数定义
+type OpenAIFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  *schema.Schema `json:"parameters"`
+}
+
+// AgentTool 智能体框架使用的工具定义
+//
+// 与 OpenAI Responses API 的函数工具格式相同，parameters 与 strict 对应 OpenAI Agents SDK
+// FunctionTool 的 params_json_schema 与 strict_json_schema；inputSchema 与 MCP tools/list 相同，
+// 供 LangChain MCP 适配器等按 MCP 工具解析。
+type AgentTool struct {
+	Type        string         `json:"type"` // function
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  interface{}    `json:"parameters"` // strict 为 true 时为严格模式 Schema，否则为原始 Schema
+	Strict      bool           `json:"strict"`
+	InputSchema *schema.Schema `json:"inputSchema"`
+}
+
+// OpenAPI 生成 OpenAPI 文档
+func OpenAPI(toolInfos []tools.ToolInfo, info Info) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info: OpenAPIInfo{
+			Title:       info.Title,
+			Version:     info.Version,
+			Description: "Arguments of the registered tools, keyed by tool name",
+		},
+		Paths:      map[string]interface{}{},
+		Components: OpenAPIComponents{Schemas: make(map[string]*schema.Schema, len(toolInfos))},
+	}
+	if info.ServerURL != "" {
+		doc.Servers = []OpenAPIServer{{URL: info.ServerURL}}
+	}
+	if info.BearerAuth {
+		doc.Components.SecuritySchemes = map[string]map[string]interface{}{
+			"bearerAuth": {"type": "http", "scheme": "bearer"},
+		}
+		doc.Security = []map[string][]string{{"bearerAuth": {}}}
+	}
+	for _, tool := range toolInfos {
+		arguments := *inputSchema(tool)
+		if arguments.Description == "" {
+			arguments.Description = tool.Description
+		}
+		doc.Components.Schemas[tool.Name] = &arguments
+		if info.PathPrefix != "" {
+			doc.Paths[info.PathPrefix+"/"+tool.Name] = map[string]interface{}{"post": toolOperation(tool)}
+		}
+	}
+	return doc
+}
+
+// toolOperation 工具调用的 REST 操作，请求体为工具参数，响应为 MCP tools/call 的结果
+func toolOperation(tool tools.ToolInfo) map[string]interface{} {
+	jsonContent := func(s interface{}) map[string]interface{} {
+		return map[string]interface{}{"application/json": map[string]interface{}{"schema": s}}
+	}
+	errorResponse := func(description string) map[string]interface{} {
+		return map[string]interface{}{"description": description, "content": jsonContent(errorSchema)}
+	}
+	return map[string]interface{}{
+		"operationId": tool.Name,
+		"summary":     tool.Description,
+		"tags":        []string{string(tool.Category)},
+		"requestBody": map[string]interface{}{
+			"required": true,
+			"content":  jsonContent(map[string]string{"$ref": "#/components/schemas/" + tool.Name}),
+		},
+		"responses": map[string]interface{}{
+			"200": map[string]interface{}{"description": "Tool result", "content": jsonContent(resultSchema)},
+			"400": errorResponse("Invalid arguments"),
+			"404": errorResponse("Tool not found or disabled"),
+			"422": errorResponse("Tool execution failed"),
+			"500": map[string]interface{}{"description": "Tool panicked", "content": jsonContent(resultSchema)},
+		},
+	}
+}
+
+// resultSchema 工具调用结果
+var resultSchema = schema.Object(map[string]*schema.Schema{
+	"content": {Type: schema.TypeArray, Items: schema.Object(map[string]*schema.Schema{
+		"type":     {Type: schema.TypeString, Enum: []interface{}{"text", "image"}},
+		"text":     {Type: schema.TypeString},
+		"data":     {Description: "Base64 encoded image data"},
+		"mimeType": {Type: schema.TypeString},
+	}, "type")},
+	"isError": {Type: schema.TypeBoolean, Description: "The tool failed and content describes the error"},
+}, "content")
+
+// errorSchema 错误响应
+var errorSchema = schema.Object(map[string]*schema.Schema{
+	"tool":  {Type: schema.TypeString},
+	"error": {Type: schema.TypeString},
+}, "error")
+
+// OpenAI 生成 OpenAI 函数调用清单，可直接作为 chat completions 请求的 tools 字段，按工具名排序
+func OpenAI(toolInfos []tools.ToolInfo) []OpenAITool {
+	manifest := make([]OpenAITool, 0, len(toolInfos))
+	for _, tool := range sortedTools(toolInfos) {
+		manifest = append(manifest, OpenAITool{
+			Type: "function",
+			Function: OpenAIFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  inputSchema(tool),
+			},
+		})
+	}
+	return manifest
+}
+
+// Agents 生成智能体框架使用的工具定义，按工具名排序；参数 Schema 无法以严格模式表示的工具 strict 为 false
+func Agents(toolInfos []tools.ToolInfo) []AgentTool {
+	agentTools := make([]AgentTool, 0, len(toolInfos))
+	for _, tool := range sortedTools(toolInfos) {
+		input := inputSchema(tool)
+		agentTool := AgentTool{
+			Type:        "function",
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  input,
+			InputSchema: input,
+		}
+		if strict, ok := StrictSchema(input); ok {
+			agentTool.Parameters = strict
+			agentTool.Strict = true
+		}
+		agentTools = append(agentTools, agentTool)
+	}
+	return agentTools
+}
+
+// StrictSchema 将参数 Schema 转换为 OpenAI 严格模式（structured outputs）要求的形式
+//
+// 每个对象列出全部属性为 required 并禁止额外属性，可选属性改为可取 null（工具参数校验将 null 视同未提供），
+// 并去掉严格模式不支持的 minLength、maxLength 与 default。根不是对象、存在未声明类型的值、
+// 未声明属性的开放对象或未声明元素的数组时无法表示，ok 为 false。
+func StrictSchema(s *schema.Schema) (map[string]interface{}, bool) {
+	if s.Type != schema.TypeObject {
+		return nil, false
+	}
+	return strictSchema(s, false)
+}
+
+func strictSchema(s *schema.Schema, nullable bool) (map[string]interface{}, bool) {
+	if s.Type == "" {
+		return nil, false
+	}
+	strict := map[string]interface{}{"type": s.Type}
+	if nullable {
+		strict["type"] = []string{s.Type, "null"}
+	}
+	if s.Description != "" {
+		strict["description"] = s.Description
+	}
+	if len(s.Enum) > 0 {
+		enum := append([]interface{}(nil), s.Enum...)
+		if nullable {
+			enum = append(enum, nil)
+		}
+		strict["enum"] = enum
+	}
+	if s.Minimum != nil {
+		strict["minimum"] = *s.Minimum
+	}
+	if s.Maximum != nil {
+		strict["maximum"] = *s.Maximum
+	}
+	if s.MinItems != nil {
+		strict["minItems"] = *s.MinItems
+	}
+	if s.MaxItems != nil {
+		strict["maxItems"] = *s.MaxItems
+	}
+
+	switch s.Type {
+	case schema.TypeObject:
+		closed := s.AdditionalProperties != nil && !*s.AdditionalProperties
+		if len(s.Properties) == 0 && !closed {
+			return nil, false
+		}
+		required := make(map[string]bool, len(s.Required))
+		for _, name := range s.Required {
+			required[name] = true
+		}
+		names := make([]string, 0, len(s.Properties))
+		properties := make(map[string]interface{}, len(s.Properties))
+		for name, property := range s.Properties {
+			converted, ok := strictSchema(property, !required[name])
+			if !ok {
+				return nil, false
+			}
+			properties[name] = converted
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		strict["properties"] = properties
+		strict["required"] = names
+		strict["additionalProperties"] = false
+	case schema.TypeArray:
+		if s.Items == nil {
+			return nil, false
+		}
+		items, ok := strictSchema(s.Items, false)
+		if !ok {
+			return nil, false
+		}
+		strict["items"] = items
+	}
+	return strict, true
+}
+
+// inputSchema 工具的参数 Schema，未声明时接受任意对象
+func inputSchema(tool tools.ToolInfo) *schema.Schema {
+	if tool.InputSchema != nil {
+		return tool.InputSchema
+	}
+	return schema.Object(map[string]*schema.Schema{})
+}
+
+func sortedTools(toolInfos []tools.ToolInfo) []tools.ToolInfo {
+	sorted := append([]tools.ToolInfo(nil), toolInfos...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
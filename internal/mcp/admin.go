@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/middleware"
+)
+
+// setupAdminServer 创建独立的管理端监听
+//
+// 管理接口与公开的 /mcp 端点隔离在不同端口，使用 MCP_ADMIN_API_KEY 单独鉴权，
+// 可仅在内网或本机暴露。
+func (s *Server) setupAdminServer() {
+	engine := gin.New()
+	engine.Use(
+		middleware.AccessLogMiddleware(s.logger, s.config.AccessLogFormat),
+		middleware.RecoveryMiddleware(s.logger),
+		middleware.RequestIDMiddleware(),
+	)
+	s.registerAdminRoutes(engine.Group("", middleware.APIKeyMiddleware(s.config.AdminAPIKey)))
+
+	s.adminSrv = &http.Server{
+		Addr:         s.config.AdminAddress,
+		Handler:      engine,
+		ReadTimeout:  s.config.ReadTimeout,
+		WriteTimeout: s.config.WriteTimeout,
+		IdleTimeout:  s.config.IdleTimeout,
+	}
+}
+
+// registerAdminRoutes 注册管理接口
+func (s *Server) registerAdminRoutes(group *gin.RouterGroup) {
+	group.GET("/stats", s.handleStats)
+	group.POST("/config/reload", s.handleAdminReload)
+	group.GET("/log-level", s.handleAdminLogLevel)
+	group.PUT("/log-level", s.handleAdminSetLogLevel)
+	group.GET("/tools", s.handleAdminTools)
+	group.POST("/tools/:name/enable", s.handleAdminToolEnable)
+	group.POST("/tools/:name/disable", s.handleAdminToolDisable)
+	group.GET("/sessions", s.handleAdminSessions)
+	group.DELETE("/sessions/:id", s.handleAdminSessionDelete)
+	group.GET("/drain", s.handleAdminDrainStatus)
+	group.POST("/drain", s.handleAdminDrainStart)
+	group.DELETE("/drain", s.handleAdminDrainStop)
+	group.GET("/history", s.handleAdminHistory)
+	group.GET("/jobs", s.handleAdminJobs)
+	group.GET("/approvals", s.handleAdminApprovals)
+	group.POST("/approvals/:id/approve", s.handleAdminApprove)
+	group.POST("/approvals/:id/deny", s.handleAdminDeny)
+	group.GET("/policy/audit", s.handleAdminPolicyAudit)
+	group.GET("/tenants", s.handleAdminTenants)
+	group.GET("/metering", s.handleAdminMetering)
+	if !s.config.NoDebug {
+		group.GET("/debug/runtime", s.handleDebugRuntime)
+		registerPprofRoutes(group)
+	}
+}
+
+// toolConfig 获取当前工具配置
+func (s *Server) toolConfig() config.ToolManagerConfig {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.config.ToolConfig
+}
+
+// beginOp 记录开始执行的操作
+func (s *Server) beginOp() {
+	s.activeOps.Add(1)
+	atomic.AddInt64(&s.activeCount, 1)
+}
+
+// endOp 记录操作结束
+func (s *Server) endOp() {
+	atomic.AddInt64(&s.activeCount, -1)
+	s.activeOps.Done()
+}
+
+// handleAdminReload 重新读取工具配置文件并应用
+func (s *Server) handleAdminReload(c *gin.Context) {
+	toolConfig, err := config.LoadToolConfig()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.configMu.Lock()
+	s.config.ToolConfig = *toolConfig
+	s.configMu.Unlock()
+
+	s.toolMgr.ReloadConfig(toolConfig)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "reloaded",
+		"tools":  s.toolMgr.GetAllTools(),
+	})
+}
+
+// handleAdminLogLevel 获取当前日志级别
+func (s *Server) handleAdminLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"level": s.logger.GetLevel()})
+}
+
+// handleAdminSetLogLevel 调整日志级别
+func (s *Server) handleAdminSetLogLevel(c *gin.Context) {
+	var req struct {
+		Level string `json:"level"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := s.logger.SetLevel(req.Level); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.logger.Info().Str("level", req.Level).Msg("Log level changed")
+	c.JSON(http.StatusOK, gin.H{"level": s.logger.GetLevel()})
+}
+
+// handleAdminTools 获取所有工具及启用状态
+func (s *Server) handleAdminTools(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"tools": s.toolMgr.GetAllTools()})
+}
+
+// handleAdminToolEnable 启用工具
+func (s *Server) handleAdminToolEnable(c *gin.Context) {
+	if err := s.toolMgr.EnableTool(c.Param("name")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tool": c.Param("name"), "enabled": true})
+}
+
+// handleAdminToolDisable 禁用工具
+func (s *Server) handleAdminToolDisable(c *gin.Context) {
+	if err := s.toolMgr.DisableTool(c.Param("name")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tool": c.Param("name"), "enabled": false})
+}
+
+// handleAdminSessionDelete 强制结束会话
+func (s *Server) handleAdminSessionDelete(c *gin.Context) {
+	if !s.endSession(c.Param("id"), sessionEndAdmin) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// handleAdminDrainStatus 获取排空状态
+func (s *Server) handleAdminDrainStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, s.drainStatus())
+}
+
+// handleAdminDrainStart 开始排空：拒绝新请求，向进行中的流推送关闭通知，正在执行的操作可在排空窗口内完成
+//
+// 排空窗口结束时终止剩余的流。健康检查同时返回 503，负载均衡器可据此摘除实例后再停止进程。
+func (s *Server) handleAdminDrainStart(c *gin.Context) {
+	s.setDraining(true)
+	signal := s.startDrain(true)
+	s.logger.Warn().
+		Time("deadline", signal.deadline).
+		Int64("active_operations", signal.initialOps).
+		Msg("Server draining, rejecting new requests")
+	c.JSON(http.StatusOK, s.drainStatus())
+}
+
+// handleAdminDrainStop 结束排空，恢复接收请求；已收到关闭通知的流不受影响
+func (s *Server) handleAdminDrainStop(c *gin.Context) {
+	s.setDraining(false)
+	s.cancelDrain()
+	s.logger.Info().Msg("Server drain cancelled, accepting requests")
+	c.JSON(http.StatusOK, s.drainStatus())
+}
+
+// setDraining 设置排空标志
+func (s *Server) setDraining(draining bool) {
+	s.shutdownMu.Lock()
+	defer s.shutdownMu.Unlock()
+	s.draining = draining
+}
+
+// drainStatus 排空状态与进度
+//
+// 排空开始后 phase 为 draining，操作全部完成后为 drained，窗口结束仍有操作时为 closed（剩余的流已被终止）。
+func (s *Server) drainStatus() gin.H {
+	s.shutdownMu.RLock()
+	draining, shuttingDown := s.draining, s.shuttingDown
+	signal := *s.drain
+	s.shutdownMu.RUnlock()
+
+	active := atomic.LoadInt64(&s.activeCount)
+	status := gin.H{
+		"draining":          draining,
+		"shutting_down":     shuttingDown,
+		"active_operations": active,
+	}
+	if signal.startedAt.IsZero() {
+		return status
+	}
+
+	phase := shutdownPhaseDraining
+	switch {
+	case active == 0:
+		phase = drainPhaseDrained
+	case signal.closed.Err() != nil:
+		phase = shutdownPhaseClosed
+	}
+	remaining := time.Until(signal.deadline)
+	if remaining < 0 {
+		remaining = 0
+	}
+	status["phase"] = phase
+	status["started_at"] = signal.startedAt.Format(time.RFC3339)
+	status["deadline"] = signal.deadline.Format(time.RFC3339)
+	status["remaining_seconds"] = remaining.Seconds()
+	status["initial_operations"] = signal.initialOps
+	return status
+}
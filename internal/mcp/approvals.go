@@ -0,0 +1,175 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/jobs"
+	"Weave-Toolkit/internal/pagination"
+)
+
+// approvalProgressInterval 流式调用等待审批期间推送进度的间隔
+const approvalProgressInterval = 5 * time.Second
+
+// submitApproval 将需要审批的调用提交为等待审批的任务
+func (s *Server) submitApproval(ctx context.Context, toolName string, arguments json.RawMessage, conn *MCPConnection) (*jobs.Job, error) {
+	client := ""
+	if conn != nil && conn.ClientInfo != nil {
+		client = conn.ClientInfo.Name
+	}
+	job, err := s.jobMgr.SubmitForApproval(toolName, arguments, client, requestTenant(ctx), sessionFromContext(ctx).id(), s.toolMgr.ApprovalTimeout())
+	if err != nil {
+		return nil, err
+	}
+	sessionFromContext(ctx).trackJob(job.ID)
+	return job, nil
+}
+
+// approvalResult tools/call 提交审批后返回的任务信息，客户端通过 jobs/get 查询结果
+func approvalResult(job *jobs.Job) map[string]interface{} {
+	return map[string]interface{}{
+		"jobId":     job.ID,
+		"status":    job.Status,
+		"expiresAt": job.Approval.ExpiresAt,
+	}
+}
+
+// streamApproval 流式调用需要审批的工具：等待期间推送进度，批准后推送执行结果
+//
+// 客户端断开后任务仍等待审批并在批准后执行，可通过 jobs/get 查询。
+func (s *Server) streamApproval(ctx context.Context, emit streamEmitter, params map[string]interface{}, toolName string, arguments json.RawMessage, conn *MCPConnection) {
+	job, err := s.submitApproval(ctx, toolName, arguments, conn)
+	if err != nil {
+		emit(StreamEventError, map[string]interface{}{"message": err.Error()})
+		return
+	}
+	updates, unsubscribe, err := s.jobMgr.Subscribe(job.ID)
+	if err != nil {
+		emit(StreamEventError, map[string]interface{}{"message": err.Error()})
+		return
+	}
+	defer unsubscribe()
+
+	var token interface{}
+	if meta, ok := params["_meta"].(map[string]interface{}); ok {
+		token = meta["progressToken"]
+	}
+	total := job.Approval.ExpiresAt.Sub(job.CreatedAt)
+	progress := func() {
+		data := map[string]interface{}{
+			"method":   "notifications/progress",
+			"progress": time.Since(job.CreatedAt).Seconds(),
+			"total":    total.Seconds(),
+			"message":  fmt.Sprintf("waiting for approval of job %s", job.ID),
+		}
+		if token != nil {
+			data["progressToken"] = token
+		}
+		emit(StreamEventProgress, data)
+	}
+	progress()
+
+	ticker := time.NewTicker(approvalProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if current, err := s.jobMgr.Get(job.ID); err == nil && current.Status == jobs.StatusAwaitingApproval {
+				progress()
+			}
+		case update, ok := <-updates:
+			if !ok {
+				s.finishApproval(emit, job.ID)
+				return
+			}
+			emit(StreamEventJob, update)
+		}
+	}
+}
+
+// finishApproval 推送审批任务的最终结果
+func (s *Server) finishApproval(emit streamEmitter, id string) {
+	job, err := s.jobMgr.Get(id)
+	if err != nil {
+		emit(StreamEventError, map[string]interface{}{"message": err.Error()})
+		return
+	}
+	if job.Status != jobs.StatusCompleted {
+		emit(StreamEventError, map[string]interface{}{
+			"message": job.Error,
+			"job":     job,
+		})
+		return
+	}
+	emit(StreamEventDone, map[string]interface{}{"result": job.Result})
+}
+
+// handleAdminApprovals 分页列出等待审批的任务
+func (s *Server) handleAdminApprovals(c *gin.Context) {
+	params, err := pagination.ParseParams(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list := s.jobMgr.List()
+	pending := list[:0]
+	for _, job := range list {
+		if job.Status == jobs.StatusAwaitingApproval {
+			pending = append(pending, job)
+		}
+	}
+
+	page := pagination.Paginate(pending, params, func(job jobs.Job) pagination.Cursor {
+		return pagination.Cursor{Time: job.CreatedAt, ID: job.ID}
+	})
+	c.JSON(http.StatusOK, page.Response("approvals"))
+}
+
+// handleAdminApprove 批准等待审批的任务
+func (s *Server) handleAdminApprove(c *gin.Context) {
+	s.decideApproval(c, true)
+}
+
+// handleAdminDeny 拒绝等待审批的任务
+func (s *Server) handleAdminDeny(c *gin.Context) {
+	s.decideApproval(c, false)
+}
+
+// decideApproval 处理审批请求，请求体可选，如 {"by":"alice","reason":"..."}
+func (s *Server) decideApproval(c *gin.Context, approve bool) {
+	var req struct {
+		By     string `json:"by"`
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+
+	var job *jobs.Job
+	var err error
+	if approve {
+		job, err = s.jobMgr.Approve(c.Param("id"), req.By)
+	} else {
+		job, err = s.jobMgr.Deny(c.Param("id"), req.By, req.Reason)
+	}
+	switch {
+	case errors.Is(err, jobs.ErrNotAwaitingApproval):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, job)
+	}
+}
@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/tools"
+)
+
+// BlobsPath 上传文件接口
+const BlobsPath = "/mcp/blobs"
+
+// handleBlobUpload 上传文件，返回可作为工具参数或通过 resources/read 读取的 blob:// URI
+//
+// 请求体为文件内容（Content-Type 为文件类型，?name= 指定文件名），或 multipart/form-data
+// 中名为 file 的部分；?chunked=true 创建分块上传，请求体为第一块，其余部分通过 PATCH 追加。
+func (s *Server) handleBlobUpload(c *gin.Context) {
+	store := s.toolMgr.Blobs()
+	if store == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "blob uploads are disabled"})
+		return
+	}
+	owner := tools.BlobOwner(c.Request.Context())
+	complete := c.Query("chunked") != "true"
+
+	var body io.Reader = c.Request.Body
+	name, mimeType := c.Query("name"), mediaType(c.GetHeader("Content-Type"))
+	if mimeType == "multipart/form-data" {
+		part, err := multipartFile(c.Request)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer part.Close()
+		body = part
+		if name == "" {
+			name = part.FileName()
+		}
+		mimeType = mediaType(part.Header.Get("Content-Type"))
+	}
+
+	info, err := store.Create(owner, name, mimeType, body, complete)
+	if err != nil {
+		blobError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, info)
+}
+
+// handleBlobAppend 向分块上传追加一块：?offset= 为已上传的大小，?final=true 完成上传
+func (s *Server) handleBlobAppend(c *gin.Context) {
+	store := s.toolMgr.Blobs()
+	if store == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "blob uploads are disabled"})
+		return
+	}
+	offset, err := strconv.ParseInt(c.Query("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset is required"})
+		return
+	}
+
+	info, err := store.Append(tools.BlobOwner(c.Request.Context()), c.Param("id"), offset, c.Request.Body, c.Query("final") == "true")
+	if err != nil {
+		blobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+// handleBlobsList 列出请求方已完成上传的文件
+func (s *Server) handleBlobsList(c *gin.Context) {
+	store := s.toolMgr.Blobs()
+	if store == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "blob uploads are disabled"})
+		return
+	}
+	blobs := store.List(tools.BlobOwner(c.Request.Context()))
+	if blobs == nil {
+		blobs = []tools.BlobInfo{}
+	}
+	c.JSON(http.StatusOK, gin.H{"blobs": blobs})
+}
+
+// handleBlobDelete 删除上传的文件
+func (s *Server) handleBlobDelete(c *gin.Context) {
+	store := s.toolMgr.Blobs()
+	if store == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "blob uploads are disabled"})
+		return
+	}
+	if err := store.Delete(tools.BlobOwner(c.Request.Context()), c.Param("id")); err != nil {
+		blobError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// readBlobResource 以 resources/read 读取上传的文件，大小受 resources.max_file_bytes 限制
+func (s *Server) readBlobResource(ctx context.Context, uri string) (*resourceContents, error) {
+	store := s.toolMgr.Blobs()
+	if store == nil {
+		return nil, werrors.NotFound("resource not found: %s", uri)
+	}
+	file, info, err := store.Open(tools.BlobOwner(ctx), uri)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if maxFileBytes := s.resourceMaxFileBytes(); info.Size > maxFileBytes {
+		return nil, werrors.InvalidParams("blob is larger than %d bytes", maxFileBytes)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	content, binary := encodeResourceData(data)
+	contents := newResourceContents(uri, info.MimeType, content, binary)
+	contents.etag = `"` + info.SHA256 + `"`
+	return contents, nil
+}
+
+// multipartFile 定位 multipart 请求中名为 file 的部分，不缓冲整个请求体
+func multipartFile(r *http.Request) (*multipart.Part, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, errors.New("multipart request has no file part")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// mediaType 去除 Content-Type 的参数，无法解析时返回空
+func mediaType(contentType string) string {
+	media, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(media)
+}
+
+// blobError 按错误类型返回状态码：超过单个文件上限为 413，存储已满为 507
+func blobError(c *gin.Context, err error) {
+	status := werrors.KindOf(err).HTTPStatus()
+	switch {
+	case errors.Is(err, tools.ErrBlobTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, tools.ErrBlobStoreFull):
+		status = http.StatusInsufficientStorage
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// blobStats 上传文件存储的统计信息，关闭上传时为空
+func (s *Server) blobStats() map[string]interface{} {
+	if store := s.toolMgr.Blobs(); store != nil {
+		return store.Stats()
+	}
+	return nil
+}
@@ -0,0 +1,423 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/llm"
+	"Weave-Toolkit/internal/manifest"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/middleware"
+)
+
+// ChatCompletionsPath OpenAI 兼容的对话补全端点
+const ChatCompletionsPath = "/v1/chat/completions"
+
+const (
+	// chatClientName 未携带 X-MCP-Client-Name 时对话补全使用的客户端名称
+	chatClientName = "chat"
+	// defaultChatMaxSteps 单次请求最多执行的模型调用轮数
+	defaultChatMaxSteps = 8
+)
+
+// ErrChatStepLimit 达到最大轮数时模型仍在请求工具调用
+var ErrChatStepLimit = errors.New("tool call step limit reached")
+
+// chatCompletionRequest OpenAI chat completions 请求中支持的字段
+type chatCompletionRequest struct {
+	Model               string        `json:"model"`
+	Messages            []chatMessage `json:"messages"`
+	Temperature         *float64      `json:"temperature"`
+	MaxTokens           int           `json:"max_tokens"`
+	MaxCompletionTokens int           `json:"max_completion_tokens"`
+	Stream              bool          `json:"stream"`
+	StreamOptions       struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options"`
+	Tools      []chatTool      `json:"tools"`
+	ToolChoice json.RawMessage `json:"tool_choice"`
+}
+
+// chatMessage 请求中的对话消息，content 为字符串或文本片段列表
+type chatMessage struct {
+	Role       string          `json:"role"` // system, developer, user, assistant, tool
+	Content    json.RawMessage `json:"content"`
+	ToolCalls  []chatToolCall  `json:"tool_calls"`
+	ToolCallID string          `json:"tool_call_id"`
+}
+
+// chatTool 客户端自带的函数工具
+type chatTool struct {
+	Type     string `json:"type"` // function
+	Function struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		Parameters  json.RawMessage `json:"parameters"`
+	} `json:"function"`
+}
+
+// chatToolCall 工具调用，参数为 JSON 字符串；index 仅在流式片段中输出
+type chatToolCall struct {
+	Index    *int   `json:"index,omitempty"`
+	ID       string `json:"id"`
+	Type     string `json:"type"` // function
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// chatResponseMessage 响应中的 assistant 消息，只有工具调用时省略 content
+type chatResponseMessage struct {
+	Role      string         `json:"role,omitempty"`
+	Content   *string        `json:"content,omitempty"`
+	ToolCalls []chatToolCall `json:"tool_calls,omitempty"`
+}
+
+type chatChoice struct {
+	Index        int                  `json:"index"`
+	Message      *chatResponseMessage `json:"message,omitempty"`
+	Delta        *chatResponseMessage `json:"delta,omitempty"`
+	FinishReason *string              `json:"finish_reason"`
+}
+
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type chatCompletion struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"` // chat.completion 或 chat.completion.chunk
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   *chatUsage   `json:"usage,omitempty"`
+}
+
+// handleChatCompletions 实现 OpenAI chat completions 接口
+//
+// 请求转发给配置的大模型服务（MCP_CHAT_PROVIDER，为空时使用 llm 工具的默认服务），并附带当前启用的工具；
+// 模型请求的工具调用在本地执行，结果作为 tool 消息回传，直到模型给出最终回答或达到 MCP_CHAT_MAX_STEPS。
+// 客户端在 tools 中自带的函数不在本地执行：模型请求这些函数时返回其调用（finish_reason 为 tool_calls），
+// 由客户端执行后在下一次请求中回传，同一轮中的本地工具调用不执行。stream=true 时以 SSE 片段返回最终结果。
+func (s *Server) handleChatCompletions(c *gin.Context) {
+	if s.isShuttingDown() {
+		chatError(c, http.StatusServiceUnavailable, "server is shutting down")
+		return
+	}
+
+	s.beginOp()
+	defer s.endOp()
+
+	clientName := c.GetHeader(ClientNameHeader)
+	if clientName == "" {
+		clientName = chatClientName
+	}
+	middleware.SetMCPRequestInfo(c, "chat.completions", "", clientName)
+
+	var req chatCompletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		chatError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	llmReq, err := chatLLMRequest(req)
+	if err != nil {
+		chatError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// 客户端自带的函数优先，同名的本地工具不再提供；tool_choice 为 none 时不提供本地工具
+	clientTools := make(map[string]bool, len(req.Tools))
+	for _, tool := range req.Tools {
+		parameters := tool.Function.Parameters
+		if len(parameters) == 0 {
+			parameters = json.RawMessage(`{"type":"object"}`)
+		}
+		clientTools[tool.Function.Name] = true
+		llmReq.Tools = append(llmReq.Tools, llm.ToolDefinition{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  parameters,
+		})
+	}
+	var toolChoice string
+	_ = json.Unmarshal(req.ToolChoice, &toolChoice)
+	if toolChoice != "none" {
+		for _, tool := range manifest.OpenAI(s.tenantTools(c.Request.Context())) {
+			if clientTools[tool.Function.Name] {
+				continue
+			}
+			llmReq.Tools = append(llmReq.Tools, llm.ToolDefinition{
+				Name:        tool.Function.Name,
+				Description: tool.Function.Description,
+				Parameters:  tool.Function.Parameters,
+			})
+		}
+	}
+
+	conn, err := s.connPool.Acquire(&ClientInfo{Name: clientName, Version: "1.0.0"})
+	if err != nil {
+		chatError(c, http.StatusTooManyRequests, "connection limit exceeded: "+err.Error())
+		return
+	}
+	defer s.connPool.Release(conn)
+
+	ctx := tools.WithClient(c.Request.Context(), clientName)
+	ctx = tools.WithLocale(ctx, c.GetHeader("Accept-Language"))
+	ctx = tools.WithCircuitBreakers(ctx, s.toolMgr.CircuitBreakers())
+	result, err := s.runChat(ctx, clientName, llmReq, clientTools)
+	if err != nil {
+		chatError(c, chatErrorStatus(err), err.Error())
+		return
+	}
+
+	completion := chatCompletion{
+		ID:      "chatcmpl-" + randomString(24),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   result.Model,
+	}
+	if completion.Model == "" {
+		completion.Model = llmReq.Model
+	}
+	usage := &chatUsage{
+		PromptTokens:     result.Usage.InputTokens,
+		CompletionTokens: result.Usage.OutputTokens,
+		TotalTokens:      result.Usage.InputTokens + result.Usage.OutputTokens,
+	}
+	message := &chatResponseMessage{Role: "assistant", ToolCalls: chatToolCalls(result.ToolCalls, req.Stream)}
+	if result.Content != "" || len(result.ToolCalls) == 0 {
+		message.Content = &result.Content
+	}
+	finishReason := chatFinishReason(result)
+
+	if !req.Stream {
+		completion.Choices = []chatChoice{{Message: message, FinishReason: &finishReason}}
+		completion.Usage = usage
+		c.JSON(http.StatusOK, completion)
+		return
+	}
+
+	// 流式响应：内容片段、结束片段、可选的用量片段，最后为 [DONE]
+	setSSEHeaders(c)
+	completion.Object = "chat.completion.chunk"
+	completion.Choices = []chatChoice{{Delta: message}}
+	s.writeChatChunk(c, completion)
+	completion.Choices = []chatChoice{{Delta: &chatResponseMessage{}, FinishReason: &finishReason}}
+	s.writeChatChunk(c, completion)
+	if req.StreamOptions.IncludeUsage {
+		completion.Choices = []chatChoice{}
+		completion.Usage = usage
+		s.writeChatChunk(c, completion)
+	}
+	fmt.Fprint(c.Writer, "data: [DONE]\n\n")
+	c.Writer.Flush()
+}
+
+// chatLLMRequest 将请求转换为大模型补全请求，system 与 developer 消息合并为 system 提示
+func chatLLMRequest(req chatCompletionRequest) (llm.Request, error) {
+	llmReq := llm.Request{Model: req.Model, Temperature: req.Temperature, MaxTokens: req.MaxTokens}
+	if req.MaxCompletionTokens > 0 {
+		llmReq.MaxTokens = req.MaxCompletionTokens
+	}
+	var system []string
+	for i, message := range req.Messages {
+		content, err := chatContent(message.Content)
+		if err != nil {
+			return llm.Request{}, fmt.Errorf("messages[%d]: %v", i, err)
+		}
+		switch message.Role {
+		case "system", "developer":
+			system = append(system, content)
+		case "user", "assistant", "tool":
+			item := llm.Message{Role: message.Role, Content: content, ToolCallID: message.ToolCallID}
+			for _, call := range message.ToolCalls {
+				arguments := json.RawMessage(call.Function.Arguments)
+				if strings.TrimSpace(call.Function.Arguments) == "" {
+					arguments = json.RawMessage(`{}`)
+				} else if !json.Valid(arguments) {
+					return llm.Request{}, fmt.Errorf("messages[%d]: invalid tool call arguments", i)
+				}
+				item.ToolCalls = append(item.ToolCalls, llm.ToolCall{ID: call.ID, Name: call.Function.Name, Arguments: arguments})
+			}
+			llmReq.Messages = append(llmReq.Messages, item)
+		default:
+			return llm.Request{}, fmt.Errorf("messages[%d]: unsupported role %q", i, message.Role)
+		}
+	}
+	if len(llmReq.Messages) == 0 {
+		return llm.Request{}, fmt.Errorf("messages is required")
+	}
+	llmReq.System = strings.Join(system, "\n\n")
+	return llmReq, nil
+}
+
+// chatContent 读取消息内容，仅支持文本
+func chatContent(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", fmt.Errorf("content must be a string or an array of content parts")
+	}
+	var content strings.Builder
+	for _, part := range parts {
+		if part.Type != "text" {
+			return "", fmt.Errorf("unsupported content part type %q", part.Type)
+		}
+		content.WriteString(part.Text)
+	}
+	return content.String(), nil
+}
+
+// runChat 循环调用模型并在本地执行工具调用，返回的用量为各轮之和
+func (s *Server) runChat(ctx context.Context, clientName string, req llm.Request, clientTools map[string]bool) (*tools.LLMResult, error) {
+	maxSteps := s.config.ChatMaxSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultChatMaxSteps
+	}
+	llmTool := tools.NewLLMTool(s.toolConfig().LLM)
+
+	var usage llm.Usage
+	for step := 0; step < maxSteps; step++ {
+		result, err := llmTool.Complete(ctx, s.config.ChatProvider, req, nil)
+		if err != nil {
+			return nil, err
+		}
+		usage.InputTokens += result.Usage.InputTokens
+		usage.OutputTokens += result.Usage.OutputTokens
+		result.Usage = usage
+		if len(result.ToolCalls) == 0 {
+			return result, nil
+		}
+
+		var pending []llm.ToolCall
+		for _, call := range result.ToolCalls {
+			if clientTools[call.Name] {
+				pending = append(pending, call)
+			}
+		}
+		if len(pending) > 0 {
+			result.ToolCalls = pending
+			return result, nil
+		}
+
+		req.Messages = append(req.Messages, llm.Message{Role: "assistant", Content: result.Content, ToolCalls: result.ToolCalls})
+		for _, call := range result.ToolCalls {
+			req.Messages = append(req.Messages, llm.Message{
+				Role:       "tool",
+				ToolCallID: call.ID,
+				Content:    s.chatToolOutput(ctx, clientName, call),
+			})
+		}
+	}
+	return nil, fmt.Errorf("%w: %d", ErrChatStepLimit, maxSteps)
+}
+
+// chatToolOutput 执行工具调用，结果或错误以文本形式回传给模型
+func (s *Server) chatToolOutput(ctx context.Context, clientName string, call llm.ToolCall) string {
+	name := s.toolMgr.ResolveAlias(clientName, call.Name)
+	if !s.toolEnabled(ctx, name) {
+		return "error: tool not found: " + call.Name
+	}
+	result, err := s.toolMgr.CallTool(ctx, name, call.Arguments)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+
+	var output []string
+	for _, content := range result.Content {
+		if content.Type == ContentTypeText {
+			output = append(output, content.Text)
+		} else {
+			output = append(output, fmt.Sprintf("[%s %s]", content.Type, content.MimeType))
+		}
+	}
+	text := strings.Join(output, "\n")
+	if result.IsError {
+		return "error: " + text
+	}
+	return text
+}
+
+// chatToolCalls 转换为响应中的工具调用，流式片段带有 index
+func chatToolCalls(calls []llm.ToolCall, stream bool) []chatToolCall {
+	converted := make([]chatToolCall, 0, len(calls))
+	for i, call := range calls {
+		item := chatToolCall{ID: call.ID, Type: "function"}
+		if stream {
+			index := i
+			item.Index = &index
+		}
+		item.Function.Name = call.Name
+		item.Function.Arguments = string(call.Arguments)
+		converted = append(converted, item)
+	}
+	return converted
+}
+
+// chatFinishReason 将各服务的结束原因映射为 OpenAI 的取值
+func chatFinishReason(result *tools.LLMResult) string {
+	switch {
+	case len(result.ToolCalls) > 0:
+		return "tool_calls"
+	case result.FinishReason == "length" || result.FinishReason == "max_tokens":
+		return "length"
+	default:
+		return "stop"
+	}
+}
+
+// chatErrorStatus 按错误分类映射状态码：参数与模型选择问题为 400，超时为 504，达到轮数上限为 422，
+// 未分类的错误视为上游服务失败
+func chatErrorStatus(err error) int {
+	if errors.Is(err, ErrChatStepLimit) {
+		return http.StatusUnprocessableEntity
+	}
+	kind := werrors.KindOf(err)
+	if kind == werrors.KindInternal {
+		kind = werrors.KindUpstreamFailure
+	}
+	return kind.HTTPStatus()
+}
+
+// chatError 以 OpenAI 的错误格式响应
+func chatError(c *gin.Context, status int, message string) {
+	errorType := "server_error"
+	if status < http.StatusInternalServerError {
+		errorType = "invalid_request_error"
+	}
+	c.JSON(status, gin.H{"error": gin.H{"message": message, "type": errorType}})
+}
+
+// writeChatChunk 写出一个 SSE 片段
+func (s *Server) writeChatChunk(c *gin.Context, chunk chatCompletion) {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to marshal chat completion chunk")
+		return
+	}
+	if _, err := fmt.Fprintf(c.Writer, "data: %s\n\n", data); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to write chat completion chunk")
+		return
+	}
+	c.Writer.Flush()
+}
@@ -0,0 +1,416 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"Weave-Toolkit/internal/cluster"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/jobs"
+	"Weave-Toolkit/internal/logger"
+)
+
+// clusterTimeout 单次访问集群共享存储的超时时间
+const clusterTimeout = 5 * time.Second
+
+// joinCluster 连接集群共享存储，并让事件流、会话与租户限流使用共享存储
+func (s *Server) joinCluster() error {
+	client, err := cluster.Connect(context.Background(), s.config.ClusterRedisURL, s.config.ClusterPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to join cluster: %v", err)
+	}
+
+	s.cluster = client
+	s.events = newClusterEvents(client, s.config.StreamBufferSize, s.config.StreamRetention, s.logger)
+	s.sessions.shareSessions(client)
+	s.toolMgr.SetRateLimiter(client)
+
+	s.logger.Info().Str("instance", client.Instance()).Msg("Cluster mode enabled")
+	return nil
+}
+
+// sharedJobs 集群模式下的共享任务存储，单机模式返回 nil
+func (s *Server) sharedJobs() jobs.Store {
+	if s.cluster == nil {
+		return nil
+	}
+	return cluster.NewJobStore(s.cluster)
+}
+
+// instance 当前副本标识，单机模式为空
+func (s *Server) instance() string {
+	if s.cluster == nil {
+		return ""
+	}
+	return s.cluster.Instance()
+}
+
+// sharedJobReapInterval 当选副本清理共享任务存储的间隔
+const sharedJobReapInterval = time.Minute
+
+// startLeaderTasks 集群模式下竞选维护任务的执行者，当选期间定时清理共享存储中过期的任务，
+// 失去租约或服务器关闭时停止；单机模式不做任何事
+//
+// 会话清理、提示词目录刷新与用量写入处理的是各副本自己的内存状态，仍在每个副本上执行。
+func (s *Server) startLeaderTasks() {
+	if s.cluster == nil {
+		return
+	}
+	leader := s.cluster.NewLeader("maintenance", 0)
+	s.leaderDone = make(chan struct{})
+	go func() {
+		defer close(s.leaderDone)
+		leader.Run(s.shutdownCtx, cluster.Every(sharedJobReapInterval, func(ctx context.Context) {
+			if removed := s.jobMgr.ReapShared(ctx); removed > 0 {
+				s.logger.Info().Int("jobs", removed).Msg("Removed expired jobs from shared store")
+			}
+		}))
+	}()
+}
+
+// closeCluster 关闭集群共享存储连接，先等待竞选结束以释放租约
+func (s *Server) closeCluster() {
+	if s.cluster == nil {
+		return
+	}
+	if s.leaderDone != nil {
+		<-s.leaderDone
+	}
+	if err := s.cluster.Close(); err != nil {
+		s.logger.Warn().Err(err).Msg("Failed to close cluster connection")
+	}
+}
+
+// EventStore 流式事件缓冲，单机模式使用 EventBuffer，集群模式使用共享存储
+type EventStore interface {
+	Open(owner string) string
+	Append(streamID, event string, data interface{}) (BufferedEvent, error)
+	Close(streamID string)
+	Remove(streamIDs ...string) int
+	Since(streamID string, cursor int64) (events []BufferedEvent, done bool, truncated bool, err error)
+	Wait(ctx context.Context, streamID, owner string, cursor int64, wait time.Duration) ([]BufferedEvent, bool, bool, error)
+}
+
+// clusterEvents 集群模式的事件流缓冲，任意副本都可以续传其他副本产生的事件流
+type clusterEvents struct {
+	log    *cluster.EventLog
+	logger *logger.Logger
+}
+
+// newClusterEvents 创建集群事件流缓冲
+func newClusterEvents(client *cluster.Client, maxEvents int, retention time.Duration, logger *logger.Logger) *clusterEvents {
+	return &clusterEvents{log: cluster.NewEventLog(client, maxEvents, retention), logger: logger}
+}
+
+// Open 为 owner 创建新的事件流并返回流ID
+func (e *clusterEvents) Open(owner string) string {
+	id := newRandomID("stream")
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+	if err := e.log.Open(ctx, id, owner); err != nil {
+		// 后续追加会失败并记录错误，流式调用本身不受影响，只是无法续传
+		e.logger.Error().Err(err).Str("stream_id", id).Msg("Failed to open shared event stream")
+	}
+	return id
+}
+
+// Append 追加事件
+func (e *clusterEvents) Append(streamID, event string, data interface{}) (BufferedEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return BufferedEvent{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+	evt, err := e.log.Append(ctx, streamID, event, payload)
+	if err != nil {
+		return BufferedEvent{}, streamError(err)
+	}
+	return BufferedEvent(evt), nil
+}
+
+// Close 标记事件流结束
+func (e *clusterEvents) Close(streamID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+	if err := e.log.Close(ctx, streamID); err != nil {
+		e.logger.Error().Err(err).Str("stream_id", streamID).Msg("Failed to close shared event stream")
+	}
+}
+
+// Remove 删除事件流
+func (e *clusterEvents) Remove(streamIDs ...string) int {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+	removed, err := e.log.Remove(ctx, streamIDs...)
+	if err != nil {
+		e.logger.Error().Err(err).Strs("stream_ids", streamIDs).Msg("Failed to remove shared event streams")
+	}
+	return removed
+}
+
+// Since 获取游标之后的事件
+func (e *clusterEvents) Since(streamID string, cursor int64) ([]BufferedEvent, bool, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+	events, done, truncated, err := e.log.Since(ctx, streamID, cursor)
+	if err != nil {
+		return nil, false, false, streamError(err)
+	}
+	return bufferedEvents(events), done, truncated, nil
+}
+
+// Wait 等待游标之后的事件
+func (e *clusterEvents) Wait(ctx context.Context, streamID, owner string, cursor int64, wait time.Duration) ([]BufferedEvent, bool, bool, error) {
+	events, done, truncated, err := e.log.Wait(ctx, streamID, owner, cursor, wait)
+	if err != nil {
+		return nil, false, false, streamError(err)
+	}
+	return bufferedEvents(events), done, truncated, nil
+}
+
+// bufferedEvents 转换共享存储中的事件
+func bufferedEvents(events []cluster.Event) []BufferedEvent {
+	list := make([]BufferedEvent, len(events))
+	for i, evt := range events {
+		list[i] = BufferedEvent(evt)
+	}
+	return list
+}
+
+// streamError 将事件流不存在转换为统一的错误类型
+func streamError(err error) error {
+	if errors.Is(err, cluster.ErrStreamNotFound) {
+		return werrors.NotFound("%s", err.Error())
+	}
+	return err
+}
+
+// sessionBackend 会话共享存储
+type sessionBackend interface {
+	Put(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Delete(ctx context.Context, keys ...string) (int64, error)
+	Touch(ctx context.Context, ttl time.Duration, keys ...string) error
+	Scan(ctx context.Context, pattern string) ([]string, error)
+}
+
+// 会话在共享存储中的键：概要用于判断是否需要重新加载，数据包含资源内容
+const (
+	sessionInfoKey = "session:info:"
+	sessionDataKey = "session:data:"
+)
+
+// sharedSessionInfo 共享存储中的会话概要
+type sharedSessionInfo struct {
+	SessionInfo
+	Revision int64 `json:"revision"`
+}
+
+// sharedResource 共享存储中的会话资源
+type sharedResource struct {
+	SessionResource
+	Data []byte `json:"data"`
+}
+
+// sharedSessionData 共享存储中的会话资源
+type sharedSessionData struct {
+	Revision  int64            `json:"revision"`
+	Resources []sharedResource `json:"resources"`
+}
+
+// shareSessions 启用会话共享：各副本写穿共享存储，本地缺失或修订号不一致时从共享存储加载
+//
+// 同一会话在多个副本上并发发布资源时以最后一次写入为准。
+func (st *SessionStore) shareSessions(backend sessionBackend) {
+	st.shared = backend
+}
+
+// saveLocked 将会话写入共享存储（调用方需持有会话锁）
+func (st *SessionStore) saveLocked(s *Session) {
+	if st.shared == nil {
+		return
+	}
+
+	s.revision = time.Now().UnixNano()
+	data := sharedSessionData{Revision: s.revision, Resources: make([]sharedResource, 0, len(s.order))}
+	for _, name := range s.order {
+		resource := s.resources[name]
+		data.Resources = append(data.Resources, sharedResource{SessionResource: *resource, Data: resource.Data})
+	}
+	info := sharedSessionInfo{SessionInfo: s.infoLocked(), Revision: s.revision}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+	// 先写数据后写概要，其他副本读到新修订号时一定能读到对应的数据
+	if err := st.putJSON(ctx, sessionDataKey+s.ID, data); err != nil {
+		st.logger.Error().Err(err).Str("session_id", s.ID).Msg("Failed to save session to shared store")
+		return
+	}
+	if err := st.putJSON(ctx, sessionInfoKey+s.ID, info); err != nil {
+		st.logger.Error().Err(err).Str("session_id", s.ID).Msg("Failed to save session to shared store")
+	}
+}
+
+// putJSON 以会话空闲时长为过期时间写入 JSON
+func (st *SessionStore) putJSON(ctx context.Context, key string, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return st.shared.Put(ctx, key, payload, st.ttl)
+}
+
+// getJSON 读取 JSON，不存在时 found 为 false
+func (st *SessionStore) getJSON(ctx context.Context, key string, v interface{}) (bool, error) {
+	payload, found, err := st.shared.Get(ctx, key)
+	if err != nil || !found {
+		return false, err
+	}
+	return true, json.Unmarshal(payload, v)
+}
+
+// refresh 与共享存储同步会话，返回最新的会话
+//
+// 共享存储不可用时沿用本地会话；会话已在其他副本删除或过期时同时删除本地会话。
+func (st *SessionStore) refresh(id string, local *Session) (*Session, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+
+	var info sharedSessionInfo
+	found, err := st.getJSON(ctx, sessionInfoKey+id, &info)
+	if err != nil {
+		st.logger.Warn().Err(err).Str("session_id", id).Msg("Failed to load session from shared store")
+		return local, local != nil
+	}
+	if !found {
+		if local != nil {
+			st.mu.Lock()
+			delete(st.sessions, id)
+			st.mu.Unlock()
+		}
+		return nil, false
+	}
+
+	if err := st.shared.Touch(ctx, st.ttl, sessionInfoKey+id, sessionDataKey+id); err != nil {
+		st.logger.Warn().Err(err).Str("session_id", id).Msg("Failed to refresh session expiry")
+	}
+
+	if local != nil {
+		local.mu.Lock()
+		current := local.revision == info.Revision
+		local.mu.Unlock()
+		if current {
+			return local, true
+		}
+	}
+
+	var data sharedSessionData
+	found, err = st.getJSON(ctx, sessionDataKey+id, &data)
+	if err != nil || !found {
+		st.logger.Warn().Err(err).Str("session_id", id).Msg("Failed to load session resources from shared store")
+		return local, local != nil
+	}
+
+	session := local
+	if session == nil {
+		session = &Session{
+			ID:           info.ID,
+			Client:       info.Client,
+			Version:      info.Version,
+			Locale:       info.Locale,
+			Capabilities: info.Capabilities,
+			Tenant:       info.Tenant,
+			CreatedAt:    info.CreatedAt,
+			store:        st,
+		}
+	}
+
+	session.mu.Lock()
+	session.resources = make(map[string]*SessionResource, len(data.Resources))
+	session.order = session.order[:0]
+	session.bytes = 0
+	for _, shared := range data.Resources {
+		resource := shared.SessionResource
+		resource.Data = shared.Data
+		session.resources[resource.Name] = &resource
+		session.order = append(session.order, resource.Name)
+		session.bytes += resource.Size
+	}
+	session.evicted = info.Evicted
+	session.rejected = info.Rejected
+	session.revision = data.Revision
+	session.lastActive = time.Now()
+	session.mu.Unlock()
+
+	if local == nil {
+		st.mu.Lock()
+		if existing, ok := st.sessions[id]; ok {
+			// 并发加载时保留先加入的会话，避免上下文中持有不同的会话对象
+			session = existing
+		} else {
+			st.sessions[id] = session
+		}
+		st.mu.Unlock()
+	}
+	return session, true
+}
+
+// sharedExists 共享存储中是否仍有会话，共享存储不可用时视为存在
+func (st *SessionStore) sharedExists(id string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+
+	_, found, err := st.shared.Get(ctx, sessionInfoKey+id)
+	if err != nil {
+		st.logger.Warn().Err(err).Str("session_id", id).Msg("Failed to check session in shared store")
+		return true
+	}
+	return found
+}
+
+// deleteShared 从共享存储删除会话，返回会话是否存在
+func (st *SessionStore) deleteShared(id string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+
+	deleted, err := st.shared.Delete(ctx, sessionInfoKey+id, sessionDataKey+id)
+	if err != nil {
+		st.logger.Error().Err(err).Str("session_id", id).Msg("Failed to delete session from shared store")
+		return false
+	}
+	return deleted > 0
+}
+
+// mergeShared 合并其他副本上的会话概要
+func (st *SessionStore) mergeShared(list []SessionInfo) []SessionInfo {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+
+	keys, err := st.shared.Scan(ctx, sessionInfoKey+"*")
+	if err != nil {
+		st.logger.Warn().Err(err).Msg("Failed to list sessions from shared store")
+		return list
+	}
+
+	local := make(map[string]bool, len(list))
+	for _, info := range list {
+		local[info.ID] = true
+	}
+	for _, key := range keys {
+		if local[strings.TrimPrefix(key, sessionInfoKey)] {
+			continue
+		}
+		var info sharedSessionInfo
+		if found, err := st.getJSON(ctx, key, &info); err == nil && found {
+			list = append(list, info.SessionInfo)
+		}
+	}
+	return list
+}
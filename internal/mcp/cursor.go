@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// listCursor tools/list、resources/list 与 prompts/list 的分页游标，记录下一页的起始位置
+type listCursor struct {
+	Offset int `json:"o"`
+}
+
+// encodeListCursor 编码为不透明的游标字符串
+func encodeListCursor(offset int) string {
+	data, _ := json.Marshal(listCursor{Offset: offset})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// listPage 按 params.cursor 截取一页，pageSize 不大于 0 时返回全部；还有后续内容时返回下一页游标
+func listPage[T any](req map[string]interface{}, items []T, pageSize int) ([]T, string, error) {
+	offset := 0
+	if params, ok := req["params"].(map[string]interface{}); ok && params["cursor"] != nil {
+		value, ok := params["cursor"].(string)
+		if !ok {
+			return nil, "", fmt.Errorf("%w: invalid cursor", errInvalidParams)
+		}
+		data, err := base64.RawURLEncoding.DecodeString(value)
+		var cursor listCursor
+		if err != nil || json.Unmarshal(data, &cursor) != nil || cursor.Offset < 0 || cursor.Offset > len(items) {
+			return nil, "", fmt.Errorf("%w: invalid cursor", errInvalidParams)
+		}
+		offset = cursor.Offset
+	}
+
+	items = items[offset:]
+	if pageSize <= 0 || len(items) <= pageSize {
+		return items, "", nil
+	}
+	return items[:pageSize], encodeListCursor(offset + pageSize), nil
+}
+
+// pagedList 生成列表响应，有下一页时附带 nextCursor
+func pagedList(field string, items interface{}, nextCursor string) map[string]interface{} {
+	result := map[string]interface{}{field: items}
+	if nextCursor != "" {
+		result["nextCursor"] = nextCursor
+	}
+	return result
+}
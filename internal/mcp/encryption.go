@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/envelope"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/tools"
+)
+
+// JWKSPath 公布参数加密公钥的端点
+const JWKSPath = "/.well-known/jwks.json"
+
+// ErrPlaintextArguments 服务器要求加密参数时收到明文参数
+var ErrPlaintextArguments = werrors.New(werrors.KindInvalidParams, "tool arguments must be encrypted (use encryptedArguments)")
+
+// toolCallArguments 读取 tools/call 的参数
+//
+// encryptedArguments 为 JWE 紧凑序列化时包装为加密参数原样传递，由工具管理器在执行前解密；
+// 配置 MCP_REQUIRE_ENCRYPTION 后拒绝非空的明文参数。
+func (s *Server) toolCallArguments(params map[string]interface{}) (json.RawMessage, error) {
+	if compact, ok := params["encryptedArguments"].(string); ok {
+		if s.encryptionKey == nil {
+			return nil, tools.ErrEncryptionDisabled
+		}
+		return envelope.Wrap(compact), nil
+	}
+
+	arguments, err := json.Marshal(params["arguments"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid arguments: %v", err)
+	}
+	if _, encrypted := envelope.Unwrap(arguments); !encrypted && s.config.RequireEncrypt && !emptyArguments(params["arguments"]) {
+		return nil, ErrPlaintextArguments
+	}
+	return arguments, nil
+}
+
+// emptyArguments 未提供参数或参数为空对象
+func emptyArguments(arguments interface{}) bool {
+	if arguments == nil {
+		return true
+	}
+	m, ok := arguments.(map[string]interface{})
+	return ok && len(m) == 0
+}
+
+// encryptionCapability initialize 响应中声明的参数加密能力
+func (s *Server) encryptionCapability() map[string]interface{} {
+	return map[string]interface{}{
+		"jwksUri":  JWKSPath,
+		"kid":      s.encryptionKey.KeyID(),
+		"alg":      envelope.Algorithm,
+		"enc":      envelope.Encryption,
+		"required": s.config.RequireEncrypt,
+	}
+}
+
+// handleJWKS 公布参数加密公钥
+func (s *Server) handleJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, envelope.JWKSet{Keys: []envelope.JWK{s.encryptionKey.PublicJWK()}})
+}
@@ -0,0 +1,204 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// BufferedEvent 缓冲的流式事件
+type BufferedEvent struct {
+	ID    int64           `json:"id"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+	Time  time.Time       `json:"time"`
+}
+
+// eventStream 单个流的事件缓冲
+type eventStream struct {
+	owner   string // 打开事件流的租户与会话，只有同一持有者可以拉取
+	events  []BufferedEvent
+	nextID  int64
+	done    bool
+	updated time.Time
+	notify  chan struct{} // 有新事件时关闭并替换，用于唤醒等待者
+}
+
+// EventBuffer 流式事件缓冲区，支持断线续传与长轮询
+type EventBuffer struct {
+	mu        sync.Mutex
+	streams   map[string]*eventStream
+	maxEvents int
+	retention time.Duration
+}
+
+// NewEventBuffer 创建事件缓冲区
+func NewEventBuffer(maxEvents int, retention time.Duration) *EventBuffer {
+	if maxEvents <= 0 {
+		maxEvents = 1000
+	}
+	if retention <= 0 {
+		retention = 5 * time.Minute
+	}
+
+	return &EventBuffer{
+		streams:   make(map[string]*eventStream),
+		maxEvents: maxEvents,
+		retention: retention,
+	}
+}
+
+// Open 为 owner 创建新的事件流并返回流ID
+func (b *EventBuffer) Open(owner string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.cleanupLocked()
+
+	id := newRandomID("stream")
+	b.streams[id] = &eventStream{
+		owner:   owner,
+		updated: time.Now(),
+		notify:  make(chan struct{}),
+	}
+	return id
+}
+
+// Append 追加事件，超过容量时丢弃最早的事件
+func (b *EventBuffer) Append(streamID, event string, data interface{}) (BufferedEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return BufferedEvent{}, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stream, exists := b.streams[streamID]
+	if !exists {
+		return BufferedEvent{}, werrors.NotFound("stream not found: %s", streamID)
+	}
+
+	stream.nextID++
+	evt := BufferedEvent{
+		ID:    stream.nextID,
+		Event: event,
+		Data:  payload,
+		Time:  time.Now(),
+	}
+
+	stream.events = append(stream.events, evt)
+	if len(stream.events) > b.maxEvents {
+		stream.events = stream.events[len(stream.events)-b.maxEvents:]
+	}
+	stream.updated = evt.Time
+	b.wakeLocked(stream)
+
+	return evt, nil
+}
+
+// Close 标记事件流结束
+func (b *EventBuffer) Close(streamID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if stream, exists := b.streams[streamID]; exists {
+		stream.done = true
+		stream.updated = time.Now()
+		b.wakeLocked(stream)
+	}
+}
+
+// Remove 删除事件流及其缓冲的事件，等待中的长轮询随即返回流不存在
+func (b *EventBuffer) Remove(streamIDs ...string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	removed := 0
+	for _, id := range streamIDs {
+		if stream, exists := b.streams[id]; exists {
+			delete(b.streams, id)
+			b.wakeLocked(stream)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Since 获取游标之后的事件，truncated 表示游标之后的部分事件已被淘汰
+func (b *EventBuffer) Since(streamID string, cursor int64) (events []BufferedEvent, done bool, truncated bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stream, exists := b.streams[streamID]
+	if !exists {
+		return nil, false, false, werrors.NotFound("stream not found: %s", streamID)
+	}
+
+	events, truncated = stream.since(cursor)
+	return events, stream.done, truncated, nil
+}
+
+// Wait 等待游标之后的事件，直到有新事件、流结束、超时或上下文取消；
+// 事件流属于其他持有者时与不存在的事件流同样处理
+func (b *EventBuffer) Wait(ctx context.Context, streamID, owner string, cursor int64, wait time.Duration) ([]BufferedEvent, bool, bool, error) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		b.mu.Lock()
+		stream, exists := b.streams[streamID]
+		if !exists || stream.owner != owner {
+			b.mu.Unlock()
+			return nil, false, false, werrors.NotFound("stream not found: %s", streamID)
+		}
+
+		events, truncated := stream.since(cursor)
+		if len(events) > 0 || stream.done {
+			done := stream.done
+			b.mu.Unlock()
+			return events, done, truncated, nil
+		}
+		notify := stream.notify
+		b.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			return []BufferedEvent{}, false, false, nil
+		case <-ctx.Done():
+			return nil, false, false, ctx.Err()
+		}
+	}
+}
+
+// since 获取游标之后的事件（调用方需持有锁）
+func (s *eventStream) since(cursor int64) ([]BufferedEvent, bool) {
+	events := []BufferedEvent{}
+	truncated := len(s.events) > 0 && s.events[0].ID > cursor+1
+	for _, evt := range s.events {
+		if evt.ID > cursor {
+			events = append(events, evt)
+		}
+	}
+	return events, truncated
+}
+
+// wakeLocked 唤醒等待者（调用方需持有锁）
+func (b *EventBuffer) wakeLocked(stream *eventStream) {
+	close(stream.notify)
+	stream.notify = make(chan struct{})
+}
+
+// cleanupLocked 清理已结束且超过保留时长的事件流（调用方需持有锁）
+func (b *EventBuffer) cleanupLocked() {
+	cutoff := time.Now().Add(-b.retention)
+	for id, stream := range b.streams {
+		if stream.done && stream.updated.Before(cutoff) {
+			delete(b.streams, id)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package mcp
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/internal/tools"
+)
+
+// 文件资源默认限制
+const (
+	defaultResourceCacheBytes   = 64 << 20
+	defaultResourceMaxFileBytes = 16 << 20
+)
+
+// resourceContents resources/read 的结果，etag 不为空时 HTTP 响应携带 ETag 并支持 If-None-Match
+type resourceContents struct {
+	Contents []map[string]interface{} `json:"contents"`
+	etag     string
+}
+
+// newResourceContents 创建单条内容的读取结果，binary 时 content 为 base64 编码
+func newResourceContents(uri, mimeType, content string, binary bool) *resourceContents {
+	item := map[string]interface{}{"uri": uri, "mimeType": mimeType}
+	if binary {
+		item["blob"] = content
+	} else {
+		item["text"] = content
+	}
+	return &resourceContents{Contents: []map[string]interface{}{item}, etag: contentETag(mimeType, content)}
+}
+
+// contentETag 按内容计算强 ETag
+func contentETag(mimeType, content string) string {
+	hash := sha256.New()
+	io.WriteString(hash, mimeType)
+	hash.Write([]byte{0})
+	io.WriteString(hash, content)
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches 判断 If-None-Match 是否包含 etag，比较时忽略弱校验前缀
+func etagMatches(header, etag string) bool {
+	if header == "" || etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// fileCacheEntry 缓存的文件内容，修改时间或大小变化即失效
+type fileCacheEntry struct {
+	path     string
+	modTime  time.Time
+	size     int64
+	mimeType string
+	content  string // 文本或 base64 编码的二进制内容
+	binary   bool
+}
+
+// fileCache 文件资源内容的 LRU 缓存，按本地路径、修改时间与大小命中，
+// 多个客户端重复读取同一个大文件时不必每次读盘与编码
+type fileCache struct {
+	mu      sync.Mutex
+	order   *list.List // 最近使用的在前
+	entries map[string]*list.Element
+	bytes   int64
+	hits    int64
+	misses  int64
+}
+
+// FileCacheStats 文件资源缓存统计
+type FileCacheStats struct {
+	Entries int   `json:"entries"`
+	Bytes   int64 `json:"bytes"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+}
+
+func newFileCache() *fileCache {
+	return &fileCache{order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// get 获取与文件当前状态一致的缓存内容
+func (fc *fileCache) get(path string, info fs.FileInfo) (*fileCacheEntry, bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if elem, ok := fc.entries[path]; ok {
+		entry := elem.Value.(*fileCacheEntry)
+		if entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+			fc.order.MoveToFront(elem)
+			fc.hits++
+			return entry, true
+		}
+		fc.removeLocked(elem)
+	}
+	fc.misses++
+	return nil, false
+}
+
+// put 写入缓存并淘汰最久未使用的内容直到不超过 limit，超过 limit 的内容不缓存
+func (fc *fileCache) put(entry *fileCacheEntry, limit int64) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if elem, ok := fc.entries[entry.path]; ok {
+		fc.removeLocked(elem)
+	}
+	cost := int64(len(entry.content))
+	if cost > limit {
+		return
+	}
+	fc.entries[entry.path] = fc.order.PushFront(entry)
+	fc.bytes += cost
+	for fc.bytes > limit {
+		fc.removeLocked(fc.order.Back())
+	}
+}
+
+// removeLocked 移除缓存项，调用方需持有锁
+func (fc *fileCache) removeLocked(elem *list.Element) {
+	entry := fc.order.Remove(elem).(*fileCacheEntry)
+	delete(fc.entries, entry.path)
+	fc.bytes -= int64(len(entry.content))
+}
+
+// Stats 获取缓存统计
+func (fc *fileCache) Stats() FileCacheStats {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return FileCacheStats{Entries: len(fc.entries), Bytes: fc.bytes, Hits: fc.hits, Misses: fc.misses}
+}
+
+// resourceRoots 获取请求可访问的根目录：租户请求为租户的根目录，否则为 resources.roots
+func (s *Server) resourceRoots(ctx context.Context) map[string]string {
+	if tenant := tools.TenantFromContext(ctx); tenant != nil {
+		return tenant.Config.Roots
+	}
+	return s.toolConfig().Resources.Roots
+}
+
+// readFileResource 读取根目录内的文件资源，内容未变化时直接使用缓存
+func (s *Server) readFileResource(ctx context.Context, uri string) (*resourceContents, error) {
+	path, err := fileURIPath(uri)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidParams, err)
+	}
+
+	roots := s.resourceRoots(ctx)
+	names := make([]string, 0, len(roots))
+	for name := range roots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		dir, err := platform.NormalizePath(roots[name])
+		if err != nil || !platform.IsWithin(dir, path) {
+			continue
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			continue
+		}
+		entry, err := s.readRootFile(dir, rel, path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, werrors.NotFound("resource not found: %s", uri)
+			}
+			return nil, err
+		}
+		item := map[string]interface{}{"uri": uri, "mimeType": entry.mimeType}
+		if entry.binary {
+			item["blob"] = entry.content
+		} else {
+			item["text"] = entry.content
+		}
+		etag := `"` + strconv.FormatInt(entry.size, 36) + "-" + strconv.FormatInt(entry.modTime.UnixNano(), 36) + `"`
+		return &resourceContents{Contents: []map[string]interface{}{item}, etag: etag}, nil
+	}
+	return nil, werrors.NotFound("resource not found: %s", uri)
+}
+
+// readRootFile 通过 os.Root 打开根目录内的文件，符号链接不能指向根目录之外
+func (s *Server) readRootFile(dir, rel, path string) (*fileCacheEntry, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	file, err := root.Open(rel)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%w: %s is not a regular file", errInvalidParams, filepath.ToSlash(rel))
+	}
+
+	resources := s.toolConfig().Resources
+	maxFileBytes := s.resourceMaxFileBytes()
+	if info.Size() > maxFileBytes {
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", errInvalidParams, filepath.ToSlash(rel), maxFileBytes)
+	}
+
+	if entry, ok := s.files.get(path, info); ok {
+		return entry, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxFileBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxFileBytes {
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", errInvalidParams, filepath.ToSlash(rel), maxFileBytes)
+	}
+	entry := &fileCacheEntry{path: path, modTime: info.ModTime(), size: info.Size(), mimeType: mime.TypeByExtension(filepath.Ext(path))}
+	if entry.mimeType == "" {
+		entry.mimeType = http.DetectContentType(data)
+	}
+	entry.content, entry.binary = encodeResourceData(data)
+	// 读取期间文件被修改时大小与 Stat 结果不一致，此时不缓存
+	if int64(len(data)) == info.Size() {
+		cacheBytes := resources.CacheBytes
+		if cacheBytes == 0 {
+			cacheBytes = defaultResourceCacheBytes
+		}
+		if cacheBytes > 0 {
+			s.files.put(entry, cacheBytes)
+		}
+	}
+	return entry, nil
+}
+
+// resourceMaxFileBytes resources/read 可读取的单个文件大小上限
+func (s *Server) resourceMaxFileBytes() int64 {
+	if maxFileBytes := s.toolConfig().Resources.MaxFileBytes; maxFileBytes > 0 {
+		return maxFileBytes
+	}
+	return defaultResourceMaxFileBytes
+}
+
+// encodeResourceData UTF-8 文本原样返回，其他内容以 base64 编码并标记为二进制
+func encodeResourceData(data []byte) (string, bool) {
+	if utf8.Valid(data) && !bytes.ContainsRune(data, 0) {
+		return string(data), false
+	}
+	return base64.StdEncoding.EncodeToString(data), true
+}
+
+// fileURIPath 将 file:// URI 转换为本地绝对路径
+func fileURIPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", fmt.Errorf("invalid file uri: %s", uri)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("remote file uri not supported: %s", uri)
+	}
+	path := u.Path
+	// Windows 盘符路径：file:///C:/data
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("invalid file uri: %s", uri)
+	}
+	return filepath.Clean(path), nil
+}
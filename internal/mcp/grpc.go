@@ -0,0 +1,298 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/metering"
+	"Weave-Toolkit/internal/pb/weavev1"
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/internal/tools"
+)
+
+// grpcClientName 未携带 x-mcp-client-name 元数据时 gRPC 调用使用的客户端名称
+const grpcClientName = "grpc"
+
+// toolService 实现 weave.v1.ToolService，与 /mcp 共用工具管理器、连接数上限与排空状态
+type toolService struct {
+	weavev1.UnimplementedToolServiceServer
+	server *Server
+}
+
+// newGRPCServer 创建 gRPC 服务器
+//
+// 配置 API Key 时校验 authorization（Bearer）或 x-api-key 元数据；
+// 配置 TLS 证书时与 HTTP 端口使用同一证书。同时注册反射服务，便于 grpcurl 等工具调试。
+func (s *Server) newGRPCServer() (*grpc.Server, error) {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.grpcUnaryAuth),
+		grpc.ChainStreamInterceptor(s.grpcStreamAuth),
+	}
+	if s.config.MaxRequestSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(int(s.config.MaxRequestSize)))
+	}
+	if s.tlsEnabled() {
+		creds, err := credentials.NewServerTLSFromFile(s.config.TLSCertFile, s.config.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	srv := grpc.NewServer(opts...)
+	weavev1.RegisterToolServiceServer(srv, &toolService{server: s})
+	reflection.Register(srv)
+	return srv, nil
+}
+
+// serveGRPC 启动 gRPC 监听，同样支持 unix socket 地址
+func (s *Server) serveGRPC() error {
+	listener, err := platform.Listen(s.config.GRPCAddress)
+	if err != nil {
+		return err
+	}
+	return s.grpcSrv.Serve(listener)
+}
+
+// grpcAuthorize 校验请求元数据中的 API Key 并确定所属租户，规则与 HTTP 端点相同，
+// 租户通过 x-tenant-id 元数据指定
+func (s *Server) grpcAuthorize(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	provided := firstMetadata(md, "x-api-key")
+	if provided == "" {
+		provided = strings.TrimPrefix(firstMetadata(md, "authorization"), "Bearer ")
+	}
+	tenant, err := s.resolveTenant(provided, firstMetadata(md, TenantHeader))
+	switch {
+	case errors.Is(err, errUnauthorized):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case tenant != nil:
+		ctx = tools.WithTenant(ctx, tenant)
+	}
+	return metering.WithAPIKey(ctx, provided), nil
+}
+
+func (s *Server) grpcUnaryAuth(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.grpcAuthorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) grpcStreamAuth(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	// 反射服务只暴露接口定义，不需要鉴权
+	if strings.HasPrefix(info.FullMethod, "/grpc.reflection.") {
+		return handler(srv, stream)
+	}
+	ctx, err := s.grpcAuthorize(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &tenantServerStream{ServerStream: stream, ctx: ctx})
+}
+
+// tenantServerStream 携带租户上下文的服务端流
+type tenantServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ts *tenantServerStream) Context() context.Context {
+	return ts.ctx
+}
+
+// firstMetadata 读取元数据的第一个值
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ListTools 列出启用的工具，按名称排序
+func (ts *toolService) ListTools(ctx context.Context, req *weavev1.ListToolsRequest) (*weavev1.ListToolsResponse, error) {
+	toolInfos := ts.server.tenantTools(ctx)
+	sort.Slice(toolInfos, func(i, j int) bool { return toolInfos[i].Name < toolInfos[j].Name })
+
+	resp := &weavev1.ListToolsResponse{}
+	for _, info := range toolInfos {
+		if req.GetCategory() != "" && string(info.Category) != req.GetCategory() {
+			continue
+		}
+		tool := &weavev1.Tool{
+			Name:        info.Name,
+			Description: info.Description,
+			Category:    string(info.Category),
+		}
+		if info.InputSchema != nil {
+			inputSchema, err := toStruct(info.InputSchema)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "invalid input schema of %s: %v", info.Name, err)
+			}
+			tool.InputSchema = inputSchema
+		}
+		resp.Tools = append(resp.Tools, tool)
+	}
+	return resp, nil
+}
+
+// CallTool 调用工具
+func (ts *toolService) CallTool(ctx context.Context, req *weavev1.CallToolRequest) (*weavev1.CallToolResponse, error) {
+	var resp *weavev1.CallToolResponse
+	err := ts.call(ctx, req, func(ctx context.Context, name string, arguments json.RawMessage) error {
+		result, err := ts.server.toolMgr.CallTool(ctx, name, arguments)
+		if err != nil {
+			return err
+		}
+		resp, err = toCallToolResponse(result)
+		return err
+	})
+	return resp, err
+}
+
+// CallToolStream 流式调用工具，不支持流式输出的工具在文本结果超过一个片段时按片段返回，否则只返回结果消息
+func (ts *toolService) CallToolStream(req *weavev1.CallToolRequest, stream weavev1.ToolService_CallToolStreamServer) error {
+	return ts.call(stream.Context(), req, func(ctx context.Context, name string, arguments json.RawMessage) error {
+		var sendErr error
+		result, err := ts.server.toolMgr.CallToolStream(ctx, name, arguments, func(content string, index int) {
+			if sendErr != nil {
+				return
+			}
+			sendErr = stream.Send(&weavev1.CallToolStreamResponse{
+				Event: &weavev1.CallToolStreamResponse_Chunk{Chunk: &weavev1.Chunk{Content: content, Index: int32(index)}},
+			})
+		})
+		if err != nil {
+			return err
+		}
+		if sendErr != nil {
+			return sendErr
+		}
+		resp, err := toCallToolResponse(result)
+		if err != nil {
+			return err
+		}
+		return stream.Send(&weavev1.CallToolStreamResponse{
+			Event: &weavev1.CallToolStreamResponse_Result{Result: resp},
+		})
+	})
+}
+
+// call 完成两种调用共用的排空检查、别名解析、参数解析与连接获取，再执行 invoke
+func (ts *toolService) call(ctx context.Context, req *weavev1.CallToolRequest, invoke func(ctx context.Context, name string, arguments json.RawMessage) error) error {
+	s := ts.server
+	if s.isShuttingDown() {
+		return status.Error(codes.Unavailable, "server is shutting down")
+	}
+
+	s.beginOp()
+	defer s.endOp()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	clientName := firstMetadata(md, ClientNameHeader)
+	if clientName == "" {
+		clientName = grpcClientName
+	}
+	name := s.toolMgr.ResolveAlias(clientName, req.GetName())
+	if !s.toolEnabled(ctx, name) {
+		return status.Error(codes.NotFound, "tool not found: "+name)
+	}
+
+	params := map[string]interface{}{}
+	if req.GetEncryptedArguments() != "" {
+		params["encryptedArguments"] = req.GetEncryptedArguments()
+	} else if req.GetArguments() != nil {
+		params["arguments"] = req.GetArguments().AsMap()
+	}
+	arguments, err := s.toolCallArguments(params)
+	if err != nil {
+		return grpcError(err)
+	}
+
+	conn, err := s.connPool.Acquire(&ClientInfo{Name: clientName, Version: "1.0.0"})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, "connection limit exceeded: "+err.Error())
+	}
+	defer s.connPool.Release(conn)
+
+	ctx = tools.WithClient(ctx, clientName)
+	ctx = tools.WithLocale(ctx, firstMetadata(md, "accept-language"))
+	ctx = tools.WithRequestID(ctx, firstMetadata(md, "x-request-id"))
+	if err := invoke(ctx, name, arguments); err != nil {
+		return grpcError(err)
+	}
+	return nil
+}
+
+// grpcError 将工具调用错误映射为 gRPC 状态码，与 restErrorStatus 的分类一致
+func grpcError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(werrors.KindOf(err).GRPCCode(), err.Error())
+}
+
+// toCallToolResponse 转换调用结果，image 内容的 base64 数据解码为原始字节
+func toCallToolResponse(result *tools.ToolCallResult) (*weavev1.CallToolResponse, error) {
+	resp := &weavev1.CallToolResponse{IsError: result.IsError}
+	for _, content := range result.Content {
+		item := &weavev1.Content{Type: content.Type, Text: content.Text, MimeType: content.MimeType}
+		if encoded, ok := content.Data.(string); ok && encoded != "" {
+			data, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "invalid %s content data: %v", content.Type, err)
+			}
+			item.Data = data
+		}
+		resp.Content = append(resp.Content, item)
+	}
+	return resp, nil
+}
+
+// toStruct 将 JSON 可序列化的值转换为 protobuf Struct
+func toStruct(v interface{}) (*structpb.Struct, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	result := &structpb.Struct{}
+	if err := protojson.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// stopGRPC 等待进行中的 RPC 结束后关闭 gRPC 服务器，ctx 结束时强制断开
+func (s *Server) stopGRPC(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcSrv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.logger.Warn().Msg("gRPC server shutdown timed out, closing remaining connections")
+		s.grpcSrv.Stop()
+	}
+}
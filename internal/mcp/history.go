@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/history"
+	"Weave-Toolkit/internal/pagination"
+)
+
+// HistoryURIRecent 最近工具调用历史资源
+const HistoryURIRecent = "history://recent"
+
+// historyRecentLimit history://recent 返回的记录数
+const historyRecentLimit = 50
+
+// readRecentHistory 读取最近的工具调用记录
+func (s *Server) readRecentHistory() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	entries, err := s.history.Store().Query(ctx, history.Filter{Limit: historyRecentLimit})
+	if err != nil {
+		return "", err
+	}
+
+	content, err := json.MarshalIndent(map[string]interface{}{"entries": entries}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// handleAdminHistory 按工具、客户端、时间范围和状态分页查询调用历史
+func (s *Server) handleAdminHistory(c *gin.Context) {
+	if s.history == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "history store is disabled"})
+		return
+	}
+
+	params, err := pagination.ParseParams(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := s.history.Store().QueryPage(c.Request.Context(), history.Filter{
+		Tool:   c.Query("tool"),
+		Client: c.Query("client"),
+		Status: c.Query("status"),
+		Since:  params.Since,
+		Until:  params.Until,
+		Limit:  params.Limit,
+		Cursor: params.Cursor,
+	})
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query history")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page.Response("entries"))
+}
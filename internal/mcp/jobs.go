@@ -0,0 +1,207 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/jobs"
+	"Weave-Toolkit/internal/pagination"
+	"Weave-Toolkit/internal/tools"
+)
+
+// defaultJobWorkspaceTTL 异步任务完成后工作区的默认保留时长
+const defaultJobWorkspaceTTL = 10 * time.Minute
+
+// errJobOwnerRequired 未配置租户且不在会话中的请求无法确定任务归属，不能提交之后需要查询的任务
+var errJobOwnerRequired = werrors.New(werrors.KindInvalidParams, "async jobs require a session or a tenant")
+
+// jobOwned 请求提交的任务能否归属到租户或会话
+func jobOwned(ctx context.Context) bool {
+	return requestTenant(ctx) != "" || sessionFromContext(ctx) != nil
+}
+
+// submitJob 提交异步工具调用任务
+//
+// 租户提交的任务在执行时沿用该租户的工具目录与调用限额；请求既无租户也无会话时拒绝提交。
+func (s *Server) submitJob(ctx context.Context, toolName string, arguments json.RawMessage, conn *MCPConnection) (interface{}, error) {
+	if !jobOwned(ctx) {
+		return nil, errJobOwnerRequired
+	}
+	client := ""
+	if conn != nil && conn.ClientInfo != nil {
+		client = conn.ClientInfo.Name
+	}
+	job, err := s.jobMgr.SubmitSession(toolName, arguments, client, requestTenant(ctx), sessionFromContext(ctx).id())
+	if err != nil {
+		return nil, err
+	}
+	sessionFromContext(ctx).trackJob(job.ID)
+
+	return map[string]interface{}{
+		"jobId":  job.ID,
+		"status": job.Status,
+	}, nil
+}
+
+// handleJobsGet 处理任务查询请求
+func (s *Server) handleJobsGet(ctx context.Context, req map[string]interface{}) (interface{}, error) {
+	jobID, err := jobIDFromParams(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ownedJob(ctx, jobID)
+}
+
+// handleJobsList 处理任务列表请求，只列出请求可见的任务
+func (s *Server) handleJobsList(ctx context.Context) (interface{}, error) {
+	list := s.jobMgr.List()
+	owned := list[:0]
+	for _, job := range list {
+		if jobVisible(ctx, &job) {
+			owned = append(owned, job)
+		}
+	}
+	return map[string]interface{}{
+		"jobs": owned,
+	}, nil
+}
+
+// ownedJob 获取任务快照，请求不可见的任务按不存在处理
+func (s *Server) ownedJob(ctx context.Context, id string) (*jobs.Job, error) {
+	job, err := s.jobMgr.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !jobVisible(ctx, job) {
+		return nil, werrors.NotFound("job not found: %s", id)
+	}
+	return job, nil
+}
+
+// jobVisible 判断请求能否看到任务：配置租户时租户只能看到自己提交的任务；未配置租户时，
+// 在会话中提交的任务只对同一会话可见
+//
+// 客户端名称由调用方自行声明，不能用于区分归属；既不属于租户也不属于会话的任务只能通过管理接口查看。
+func jobVisible(ctx context.Context, job *jobs.Job) bool {
+	if tenant := tools.TenantFromContext(ctx); tenant != nil {
+		return job.Tenant == tenant.Name
+	}
+	return job.Session != "" && sessionFromContext(ctx).id() == job.Session
+}
+
+// handleAdminJobs 按状态、客户端和创建时间分页查询任务
+func (s *Server) handleAdminJobs(c *gin.Context) {
+	params, err := pagination.ParseParams(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status, client := c.Query("status"), c.Query("client")
+	list := s.jobMgr.List()
+	filtered := list[:0]
+	for _, job := range list {
+		if status != "" && string(job.Status) != status {
+			continue
+		}
+		if client != "" && job.Client != client {
+			continue
+		}
+		filtered = append(filtered, job)
+	}
+
+	page := pagination.Paginate(filtered, params, func(job jobs.Job) pagination.Cursor {
+		return pagination.Cursor{Time: job.CreatedAt, ID: job.ID}
+	})
+	c.JSON(http.StatusOK, page.Response("jobs"))
+}
+
+// handleJobsCancel 处理任务取消请求
+func (s *Server) handleJobsCancel(ctx context.Context, req map[string]interface{}) (interface{}, error) {
+	jobID, err := jobIDFromParams(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.ownedJob(ctx, jobID); err != nil {
+		return nil, err
+	}
+
+	return s.jobMgr.Cancel(jobID)
+}
+
+// handleJobEvents 以 SSE 推送任务状态变化，任务结束后发送完成事件
+//
+// 与 jobs/get 相同，请求需携带提交任务的会话ID。
+func (s *Server) handleJobEvents(c *gin.Context) {
+	if _, err := s.ownedJob(s.jobEventsContext(c), c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	updates, unsubscribe, err := s.jobMgr.Subscribe(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	defer unsubscribe()
+
+	setSSEHeaders(c)
+
+	ctx := c.Request.Context()
+	drainNotice := s.currentDrain().notice.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-drainNotice:
+			// 任务状态已持久化，客户端可在服务恢复后通过 jobs/get 查询
+			s.sendStreamEvent(c.Writer, StreamEventShutdown, s.shutdownNotice(shutdownPhaseClosed))
+			return
+		case job, ok := <-updates:
+			if !ok {
+				// 通道关闭表示任务已结束，发送最终状态
+				final, err := s.jobMgr.Get(c.Param("id"))
+				if err != nil {
+					s.sendStreamError(c.Writer, err.Error())
+					return
+				}
+				s.sendStreamEvent(c.Writer, StreamEventDone, map[string]interface{}{
+					"method": "notifications/jobs/completed",
+					"job":    final,
+				})
+				return
+			}
+			s.sendStreamEvent(c.Writer, StreamEventJob, job)
+		}
+	}
+}
+
+// jobEventsContext 按 /mcp 请求的规则在上下文中记录任务 SSE 请求的会话
+func (s *Server) jobEventsContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if session, exists := s.tenantSession(ctx, c.GetHeader(SessionIDHeader)); exists {
+		ctx = withSession(ctx, session)
+	}
+	return ctx
+}
+
+// jobIDFromParams 从请求参数中提取任务ID
+func jobIDFromParams(req map[string]interface{}) (string, error) {
+	params, ok := req["params"].(map[string]interface{})
+	if !ok {
+		return "", errInvalidParams
+	}
+
+	jobID, ok := params["jobId"].(string)
+	if !ok || jobID == "" {
+		return "", fmt.Errorf("%w: missing or invalid jobId", errInvalidParams)
+	}
+
+	return jobID, nil
+}
@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// errMethodNotFound 请求的方法不存在
+var errMethodNotFound = errors.New("method not found")
+
+// errInvalidParams 请求参数缺失或类型不正确
+var errInvalidParams = werrors.New(werrors.KindInvalidParams, "invalid params")
+
+// rpcErrorCode 将处理请求的错误按分类映射为 JSON-RPC 错误码
+func rpcErrorCode(err error) int {
+	if errors.Is(err, errMethodNotFound) {
+		return ErrorCodeMethodNotFound
+	}
+	return werrors.KindOf(err).RPCCode()
+}
+
+// validateEnvelope 检查 JSON-RPC 请求信封：jsonrpc 必须为 "2.0"，method 必须为字符串，
+// id 存在时必须为字符串或数字
+func validateEnvelope(req map[string]interface{}) (string, error) {
+	if version, _ := req["jsonrpc"].(string); version != "2.0" {
+		return "", fmt.Errorf(`jsonrpc must be "2.0"`)
+	}
+	method, ok := req["method"].(string)
+	if !ok || method == "" {
+		return "", fmt.Errorf("missing or invalid method")
+	}
+	if id, exists := req["id"]; exists {
+		switch id.(type) {
+		case string, float64:
+		default:
+			return "", fmt.Errorf("id must be a string or number")
+		}
+	}
+	return method, nil
+}
+
+// responseID 错误响应中回显的请求 ID，ID 不合法时为 null
+func responseID(req map[string]interface{}) interface{} {
+	switch id := req["id"].(type) {
+	case string, float64:
+		return id
+	default:
+		return nil
+	}
+}
+
+// isNotification 不带 id 的请求为通知，不返回响应
+func isNotification(req map[string]interface{}) bool {
+	_, exists := req["id"]
+	return !exists
+}
+
+// negotiateProtocolVersion 客户端请求的版本受支持时使用该版本，否则使用服务器的最新版本
+func negotiateProtocolVersion(requested string) string {
+	if slices.Contains(SupportedProtocolVersions, requested) {
+		return requested
+	}
+	return ProtocolVersion
+}
+
+// checkProtocolVersionHeader 校验 MCP-Protocol-Version 请求头，未携带时按兼容旧客户端处理
+func checkProtocolVersionHeader(c *gin.Context) error {
+	version := c.GetHeader(ProtocolVersionHeader)
+	if version == "" || slices.Contains(SupportedProtocolVersions, version) {
+		return nil
+	}
+	return fmt.Errorf("unsupported %s: %s", ProtocolVersionHeader, version)
+}
+
+// handleNotification 处理客户端通知，按 Streamable HTTP 规范返回 202 且没有响应体
+func (s *Server) handleNotification(c *gin.Context, method string) {
+	s.logger.Debug().Str("method", method).Msg("Received notification")
+	c.Status(http.StatusAccepted)
+}
+
+// sendRPCErrorResponse 返回回显请求 ID 的 JSON-RPC 错误响应
+func (s *Server) sendRPCErrorResponse(c *gin.Context, id interface{}, message string, code int) {
+	c.JSON(http.StatusOK, gin.H{
+		"jsonrpc": "2.0",
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+		"id": id,
+	})
+}
+
+// recoverRPC 处理 JSON-RPC 请求时 panic 则返回 -32603 错误响应，req 为已解析的请求
+//
+// 响应已开始写出（如 SSE）时继续交由恢复中间件中止请求。
+func (s *Server) recoverRPC(c *gin.Context, req *map[string]interface{}) {
+	err := recover()
+	if err == nil {
+		return
+	}
+	if err == http.ErrAbortHandler || c.Writer.Written() {
+		panic(err)
+	}
+
+	s.logger.Error().
+		Interface("panic", err).
+		Str("request_id", c.GetString("request_id")).
+		Bytes("stack", debug.Stack()).
+		Msg("Recovered from panic while handling JSON-RPC request")
+
+	c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+		"jsonrpc": "2.0",
+		"error": gin.H{
+			"code":    ErrorCodeInternalError,
+			"message": "Internal error",
+		},
+		"id": responseID(*req),
+	})
+}
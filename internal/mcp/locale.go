@@ -0,0 +1,26 @@
+package mcp
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// requestLocale 确定本次请求的区域设置
+//
+// 优先级：tools/call 参数 _meta.locale > 本次请求 clientInfo.locale >
+// 会话初始化时声明的 locale > Accept-Language 请求头。均未提供时工具使用默认的美式格式。
+func requestLocale(c *gin.Context, req map[string]interface{}, clientInfo *ClientInfo, session *Session) string {
+	if params, ok := req["params"].(map[string]interface{}); ok {
+		if meta, ok := params["_meta"].(map[string]interface{}); ok {
+			if locale, ok := meta["locale"].(string); ok && locale != "" {
+				return locale
+			}
+		}
+	}
+	if clientInfo != nil && clientInfo.Locale != "" {
+		return clientInfo.Locale
+	}
+	if session != nil && session.Locale != "" {
+		return session.Locale
+	}
+	return c.GetHeader("Accept-Language")
+}
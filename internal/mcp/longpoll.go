@@ -0,0 +1,196 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/tools"
+)
+
+// defaultLongPollWait 长轮询默认最大等待时间
+const defaultLongPollWait = 25 * time.Second
+
+// handleLongPollStart 以长轮询方式发起流式工具调用，立即返回事件流ID
+//
+// 适用于代理会中断 SSE 的网络环境：工具在后台执行，事件写入缓冲区，
+// 客户端通过 GET /mcp/events?stream=ID&cursor=N 拉取。
+func (s *Server) handleLongPollStart(c *gin.Context) {
+	if s.isShuttingDown() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Service unavailable - server is shutting down",
+		})
+		return
+	}
+
+	var req map[string]interface{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.sendGinErrorResponse(c, "Invalid JSON", -32700)
+		return
+	}
+
+	if method, _ := req["method"].(string); method != MethodToolsCall {
+		s.sendGinErrorResponse(c, "Only tools/call method is supported for streaming", -32600)
+		return
+	}
+
+	clientInfo := extractClientInfo(req)
+	if name := c.GetHeader(ClientNameHeader); name != "" && clientInfo.Name == "unknown" {
+		clientInfo.Name = name
+	}
+	annotateAccessLog(c, req, clientInfo)
+	conn, err := s.connPool.Acquire(clientInfo)
+	if err != nil {
+		s.sendGinErrorResponse(c, fmt.Sprintf("Connection limit exceeded: %v", err), -32000)
+		return
+	}
+
+	session, err := s.resolveSession(c, MethodToolsCall, conn.ClientInfo)
+	if err != nil {
+		s.connPool.Release(conn)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	locale := requestLocale(c, req, conn.ClientInfo, session)
+
+	streamID := s.events.Open(streamOwner(c.Request.Context(), session))
+	session.trackStream(streamID)
+	endSession := session.begin()
+	emitter := &lockedEmitter{emit: func(event string, data interface{}) {
+		if _, err := s.events.Append(streamID, event, data); err != nil {
+			s.logger.Error().Err(err).Str("stream_id", streamID).Msg("Failed to buffer stream event")
+		}
+	}}
+
+	// 工具执行不随本次 HTTP 请求结束而取消，仅在排空窗口结束或请求的截止时间到达时取消
+	background, cancel := s.detachedContext(c.Request.Context())
+	s.beginOp()
+	go func() {
+		defer cancel()
+		defer endSession()
+		defer s.endOp()
+		defer s.connPool.Release(conn)
+		defer s.events.Close(streamID)
+		defer emitter.Close()
+
+		stopWatch := s.watchShutdown(emitter)
+		defer stopWatch()
+
+		ctx := s.sessionContext(tools.WithClient(background, conn.ClientInfo.Name), session)
+		ctx = tools.WithLocale(ctx, locale)
+		s.runStream(emitter, func(emit streamEmitter) {
+			s.handleStreamToolsCall(ctx, emit, req, conn)
+		})
+	}()
+
+	c.Header(StreamIDHeader, streamID)
+	c.JSON(http.StatusOK, gin.H{
+		"jsonrpc": "2.0",
+		"result": gin.H{
+			"streamId": streamID,
+			"cursor":   0,
+		},
+		"id": req["id"],
+	})
+}
+
+// handleLongPoll 拉取游标之后的缓冲事件，无新事件时最多等待 wait 时长
+//
+// 请求需来自打开事件流的同一租户，并携带同一会话ID，否则按事件流不存在处理。
+func (s *Server) handleLongPoll(c *gin.Context) {
+	streamID := c.Query("stream")
+	if streamID == "" {
+		streamID = c.GetHeader(StreamIDHeader)
+	}
+	if streamID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing stream id"})
+		return
+	}
+
+	var cursor int64
+	if v := c.Query("cursor"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+		cursor = parsed
+	} else if v := c.GetHeader("Last-Event-ID"); v != "" {
+		if parsed, err := strconv.ParseInt(v, 10, 64); err == nil {
+			cursor = parsed
+		}
+	}
+
+	wait := s.longPollMaxWait()
+	if v := c.Query("wait"); v != "" {
+		requested, err := time.ParseDuration(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wait duration"})
+			return
+		}
+		if requested < wait {
+			wait = requested
+		}
+	}
+
+	ctx, cancel := s.streamContext(c.Request.Context())
+	defer cancel()
+
+	owner := requestTenant(c.Request.Context()) + "/" + c.GetHeader(SessionIDHeader)
+	events, done, truncated, err := s.events.Wait(ctx, streamID, owner, cursor, wait)
+	if err != nil {
+		if s.drained() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Service unavailable - server is shutting down",
+			})
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	next := cursor
+	if len(events) > 0 {
+		next = events[len(events)-1].ID
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"streamId":  streamID,
+		"events":    events,
+		"cursor":    next,
+		"done":      done,
+		"truncated": truncated,
+	})
+}
+
+// streamOwner 事件流的持有者：打开事件流的租户与会话，未配置租户或不属于会话时对应部分为空
+func streamOwner(ctx context.Context, session *Session) string {
+	owner := requestTenant(ctx) + "/"
+	if session != nil {
+		owner += session.ID
+	}
+	return owner
+}
+
+// longPollMaxWait 长轮询最大等待时间，不超过 HTTP 写超时
+func (s *Server) longPollMaxWait() time.Duration {
+	wait := s.config.LongPollMaxWait
+	if wait <= 0 {
+		wait = defaultLongPollWait
+	}
+	if s.config.WriteTimeout > 0 && wait >= s.config.WriteTimeout {
+		wait = s.config.WriteTimeout - time.Second
+		if wait < 0 {
+			wait = 0
+		}
+	}
+	return wait
+}
@@ -0,0 +1,42 @@
+package mcp
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/manifest"
+)
+
+// handleManifestOpenAPI 以 OpenAPI 3.1 文档导出当前启用的工具及其参数 Schema
+func (s *Server) handleManifestOpenAPI(c *gin.Context) {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+
+	info := manifest.Info{
+		Title:      "Weave-Toolkit",
+		Version:    "1.0.0",
+		ServerURL:  scheme + "://" + c.Request.Host,
+		BearerAuth: s.config.APIKey != "",
+	}
+	// 启用 REST 桥接时为每个工具输出调用端点
+	if s.config.RESTEnabled {
+		info.PathPrefix = RESTToolsPath
+	}
+	c.JSON(http.StatusOK, manifest.OpenAPI(s.tenantTools(c.Request.Context()), info))
+}
+
+// handleManifestOpenAI 以 OpenAI 函数调用清单导出当前启用的工具
+func (s *Server) handleManifestOpenAI(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"tools": manifest.OpenAI(s.tenantTools(c.Request.Context()))})
+}
+
+// handleManifestAgents 以 LangChain 与 OpenAI Agents SDK 可直接使用的工具定义导出当前启用的工具，参数 Schema 尽量为严格模式
+func (s *Server) handleManifestAgents(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"tools": manifest.Agents(s.tenantTools(c.Request.Context()))})
+}
@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"encoding/json"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// 运行时元信息资源
+const (
+	MetaURIPrefix   = "weave://meta/"
+	MetaURIRuntime  = "weave://meta/runtime"
+	MetaURIFeatures = "weave://meta/features"
+	MetaURILimits   = "weave://meta/limits"
+	MetaURITools    = "weave://meta/tools"
+)
+
+// metaResources 元信息资源列表
+func (s *Server) metaResources() []ResourceInfo {
+	resources := []ResourceInfo{
+		{URI: MetaURIRuntime, Name: "runtime", MimeType: "application/json", Description: "Go runtime, build and process information"},
+		{URI: MetaURIFeatures, Name: "features", MimeType: "application/json", Description: "Server features enabled in this deployment"},
+		{URI: MetaURILimits, Name: "limits", MimeType: "application/json", Description: "Resolved connection, timeout and category limits"},
+		{URI: MetaURITools, Name: "tools", MimeType: "application/json", Description: "Registered tools with categories"},
+	}
+
+	for _, tool := range s.toolMgr.GetTools() {
+		resources = append(resources, ResourceInfo{
+			URI:         MetaURITools + "/" + tool.Name,
+			Name:        "tools/" + tool.Name,
+			MimeType:    "application/json",
+			Description: tool.Description,
+		})
+	}
+
+	return resources
+}
+
+// readMetaResource 读取元信息资源，返回 JSON 文本
+func (s *Server) readMetaResource(uri string) (string, error) {
+	var data interface{}
+
+	switch {
+	case uri == MetaURIRuntime:
+		data = s.runtimeInfo()
+	case uri == MetaURIFeatures:
+		data = s.featureInfo()
+	case uri == MetaURILimits:
+		data = s.limitInfo()
+	case uri == MetaURITools:
+		toolInfos := s.toolMgr.GetTools()
+		sort.Slice(toolInfos, func(i, j int) bool { return toolInfos[i].Name < toolInfos[j].Name })
+		data = map[string]interface{}{"tools": toolInfos}
+	case strings.HasPrefix(uri, MetaURITools+"/"):
+		name := strings.TrimPrefix(uri, MetaURITools+"/")
+		found := false
+		for _, tool := range s.toolMgr.GetTools() {
+			if tool.Name == name {
+				data = tool
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", werrors.NotFound("resource not found: %s", uri)
+		}
+	default:
+		return "", werrors.NotFound("resource not found: %s", uri)
+	}
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// runtimeInfo 运行时信息
+func (s *Server) runtimeInfo() map[string]interface{} {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	info := map[string]interface{}{
+		"go_version":     runtime.Version(),
+		"os":             runtime.GOOS,
+		"arch":           runtime.GOARCH,
+		"num_cpu":        runtime.NumCPU(),
+		"gomaxprocs":     runtime.GOMAXPROCS(0),
+		"goroutines":     runtime.NumGoroutine(),
+		"heap_alloc":     mem.HeapAlloc,
+		"started_at":     s.startedAt.Format(time.RFC3339),
+		"uptime_seconds": int64(time.Since(s.startedAt).Seconds()),
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info["module"] = build.Main.Path
+		info["module_version"] = build.Main.Version
+	}
+
+	return info
+}
+
+// featureInfo 已启用功能
+func (s *Server) featureInfo() map[string]interface{} {
+	categories := make(map[string]bool)
+	for category, cfg := range s.toolMgr.GetCategories() {
+		categories[string(category)] = cfg.Enabled
+	}
+
+	toolConfig := s.toolConfig()
+	return map[string]interface{}{
+		"protocol_version": ProtocolVersion,
+		"streaming":        true,
+		"async_jobs":       true,
+		"webhooks":         len(toolConfig.Webhooks) > 0,
+		"tool_aliases":     len(toolConfig.Aliases) > 0 || len(toolConfig.ClientAliases) > 0,
+		"metrics":          toolConfig.Global.EnableMetrics,
+		"tracing":          toolConfig.Global.EnableTracing,
+		"categories":       categories,
+	}
+}
+
+// limitInfo 生效的限制配置
+func (s *Server) limitInfo() map[string]interface{} {
+	categories := make(map[string]interface{})
+	for category, cfg := range s.toolMgr.GetCategories() {
+		categories[string(category)] = cfg
+	}
+
+	toolConfig := s.toolConfig()
+	toolTimeout := s.config.ToolTimeout
+	if toolConfig.Global.DefaultTimeout > 0 {
+		toolTimeout = time.Duration(toolConfig.Global.DefaultTimeout) * time.Second
+	}
+
+	return map[string]interface{}{
+		"max_connections":      s.connPool.maxSize,
+		"max_request_size":     s.config.MaxRequestSize,
+		"tool_timeout":         toolTimeout.String(),
+		"tool_timeouts":        toolConfig.ToolTimeouts,
+		"read_timeout":         s.config.ReadTimeout.String(),
+		"write_timeout":        s.config.WriteTimeout.String(),
+		"idle_timeout":         s.config.IdleTimeout.String(),
+		"max_concurrent_calls": toolConfig.Global.MaxConcurrentCalls,
+		"jobs":                 s.jobMgr.Stats(),
+		"categories":           categories,
+	}
+}
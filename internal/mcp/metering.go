@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/metering"
+)
+
+// handleAdminMetering 按日期范围、租户与 API Key 导出每日用量汇总
+//
+// from 与 to 为 UTC 日期（YYYY-MM-DD，闭区间），默认为本月初至今天；api_key 可以是
+// 原始 Key 或其指纹；format=csv 时以 CSV 附件返回，否则返回 JSON 与合计。
+func (s *Server) handleAdminMetering(c *gin.Context) {
+	if s.meter == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "usage metering is disabled"})
+		return
+	}
+
+	now := time.Now().UTC()
+	filter := metering.Filter{
+		From:   c.DefaultQuery("from", now.Format("2006-01")+"-01"),
+		To:     c.DefaultQuery("to", now.Format(metering.DateLayout)),
+		Tenant: c.Query("tenant"),
+		APIKey: c.Query("api_key"),
+	}
+	for _, date := range []string{filter.From, filter.To} {
+		if _, err := time.Parse(metering.DateLayout, date); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid date %q, expected YYYY-MM-DD", date)})
+			return
+		}
+	}
+	if filter.From > filter.To {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	rollups := s.meter.Query(filter)
+	if len(rollups) == 0 && filter.APIKey != "" {
+		// 传入的可能是原始 Key
+		filter.APIKey = metering.Fingerprint(filter.APIKey)
+		rollups = s.meter.Query(filter)
+	}
+
+	switch format := c.DefaultQuery("format", "json"); format {
+	case "csv":
+		var buf bytes.Buffer
+		if err := metering.WriteCSV(&buf, rollups); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="usage-%s-%s.csv"`, filter.From, filter.To))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	case "json":
+		if rollups == nil {
+			rollups = []metering.Rollup{}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"from":    filter.From,
+			"to":      filter.To,
+			"rollups": rollups,
+			"total":   metering.Total(rollups),
+		})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported format: " + format})
+	}
+}
@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/tools"
+)
+
+// MetricsPath Prometheus 指标端点
+const MetricsPath = "/metrics"
+
+// circuitStateValues 熔断器状态的指标取值
+var circuitStateValues = map[string]int{
+	tools.CircuitClosed:   0,
+	tools.CircuitHalfOpen: 1,
+	tools.CircuitOpen:     2,
+}
+
+// handleMetrics 以 Prometheus 文本格式导出熔断器与会话指标
+func (s *Server) handleMetrics(c *gin.Context) {
+	stats := s.toolMgr.CircuitBreakers().Stats()
+
+	var buf bytes.Buffer
+	writeMetric(&buf, "weave_circuit_breaker_state", "gauge",
+		"Circuit breaker state per tool or upstream: 0 closed, 1 half-open, 2 open.",
+		stats, func(item tools.BreakerStats) string { return strconv.Itoa(circuitStateValues[item.State]) })
+	writeMetric(&buf, "weave_circuit_breaker_consecutive_failures", "gauge",
+		"Consecutive failures counted by the circuit breaker.",
+		stats, func(item tools.BreakerStats) string { return strconv.Itoa(item.Failures) })
+	writeMetric(&buf, "weave_circuit_breaker_trips_total", "counter",
+		"Number of times the circuit breaker has opened.",
+		stats, func(item tools.BreakerStats) string { return strconv.FormatInt(item.Trips, 10) })
+
+	sessions := s.sessions.Stats()
+	fmt.Fprintf(&buf, "# HELP weave_sessions_active Sessions that have not ended or expired.\n# TYPE weave_sessions_active gauge\nweave_sessions_active %d\n", sessions["sessions"])
+	fmt.Fprintf(&buf, "# HELP weave_sessions_expired_total Sessions reaped after staying idle longer than the session TTL.\n# TYPE weave_sessions_expired_total counter\nweave_sessions_expired_total %d\n", sessions["expired"])
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", buf.Bytes())
+}
+
+// writeMetric 写入一个指标族，每个熔断器一个样本，name 标签为工具名或上游服务
+func writeMetric(buf *bytes.Buffer, name, kind, help string, stats []tools.BreakerStats, value func(tools.BreakerStats) string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	for _, item := range stats {
+		fmt.Fprintf(buf, "%s{name=%s} %s\n", name, strconv.Quote(item.Name), value(item))
+	}
+}
@@ -0,0 +1,165 @@
+package mcp
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 通知流参数
+const (
+	notificationQueueSize = 16               // 每个订阅者缓冲的通知数，写满时丢弃新通知
+	notificationKeepAlive = 25 * time.Second // 空闲时发送注释行的间隔，避免代理断开连接
+)
+
+// notificationSubscriber 通过 GET /mcp 订阅服务器通知的会话
+type notificationSubscriber struct {
+	sessionID string
+	tenant    string // 租户名称，非租户请求为空
+	messages  chan map[string]interface{}
+	closed    chan struct{} // 会话结束时关闭，通知流随之结束
+}
+
+// notificationHub 向订阅的会话推送服务器主动发送的通知
+type notificationHub struct {
+	mu          sync.Mutex
+	subscribers map[*notificationSubscriber]struct{}
+	sent        int64
+	dropped     int64
+}
+
+// newNotificationHub 创建通知中心
+func newNotificationHub() *notificationHub {
+	return &notificationHub{subscribers: make(map[*notificationSubscriber]struct{})}
+}
+
+// subscribe 订阅通知，返回取消订阅的函数
+func (h *notificationHub) subscribe(sessionID, tenant string) (*notificationSubscriber, func()) {
+	sub := &notificationSubscriber{
+		sessionID: sessionID,
+		tenant:    tenant,
+		messages:  make(chan map[string]interface{}, notificationQueueSize),
+		closed:    make(chan struct{}),
+	}
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub, func() {
+		h.mu.Lock()
+		delete(h.subscribers, sub)
+		h.mu.Unlock()
+	}
+}
+
+// closeSession 结束会话的全部通知流，返回结束的流数
+func (h *notificationHub) closeSession(sessionID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	closed := 0
+	for sub := range h.subscribers {
+		if sub.sessionID == sessionID {
+			delete(h.subscribers, sub)
+			close(sub.closed)
+			closed++
+		}
+	}
+	return closed
+}
+
+// broadcast 向 match 返回 true 的订阅者发送通知，返回送达的订阅者数
+//
+// 发送不阻塞：订阅者的缓冲区已满时丢弃该通知。
+func (h *notificationHub) broadcast(method string, params map[string]interface{}, match func(*notificationSubscriber) bool) int {
+	message := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+	}
+	if params != nil {
+		message["params"] = params
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delivered := 0
+	for sub := range h.subscribers {
+		if match != nil && !match(sub) {
+			continue
+		}
+		select {
+		case sub.messages <- message:
+			delivered++
+			h.sent++
+		default:
+			h.dropped++
+		}
+	}
+	return delivered
+}
+
+// Stats 通知中心统计信息
+func (h *notificationHub) Stats() map[string]interface{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return map[string]interface{}{
+		"subscribers": len(h.subscribers),
+		"sent":        h.sent,
+		"dropped":     h.dropped,
+	}
+}
+
+// handleNotificationStream 为会话打开 SSE 通知流，推送列表变化等服务器通知
+//
+// 请求需接受 text/event-stream 并携带会话ID；流保持打开期间会话不会因空闲过期。
+func (s *Server) handleNotificationStream(c *gin.Context) {
+	if !strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		c.JSON(http.StatusNotAcceptable, gin.H{"error": "notification stream requires Accept: text/event-stream"})
+		return
+	}
+	id := c.GetHeader(SessionIDHeader)
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing " + SessionIDHeader + " header"})
+		return
+	}
+	if _, exists := s.tenantSession(c.Request.Context(), id); !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+
+	sub, unsubscribe := s.notifications.subscribe(id, requestTenant(c.Request.Context()))
+	defer unsubscribe()
+
+	setSSEHeaders(c)
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	drainNotice := s.currentDrain().notice.Done()
+	keepAlive := time.NewTicker(notificationKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.closed:
+			return
+		case <-drainNotice:
+			s.sendStreamEvent(c.Writer, StreamEventShutdown, s.shutdownNotice(shutdownPhaseClosed))
+			return
+		case message := <-sub.messages:
+			s.sendStreamEvent(c.Writer, StreamEventMessage, message)
+		case <-keepAlive.C:
+			// 保持会话活跃，会话被删除后结束流
+			if _, exists := s.tenantSession(ctx, id); !exists {
+				return
+			}
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"log/slog"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/internal/tools"
+)
+
+// Logger 服务器日志接口，方法签名与 *slog.Logger 一致
+type Logger = logger.Backend
+
+// ConfigSource 服务器配置的来源
+type ConfigSource interface {
+	Load() (*config.Config, error)
+}
+
+// ConfigSourceFunc 以函数作为配置来源
+type ConfigSourceFunc func() (*config.Config, error)
+
+// Load 实现 ConfigSource
+func (f ConfigSourceFunc) Load() (*config.Config, error) {
+	return f()
+}
+
+// EnvConfig 与独立服务相同，从环境变量、.env 与 tool-config.json 加载配置
+var EnvConfig ConfigSource = ConfigSourceFunc(config.Load)
+
+// ServerConfig 嵌入应用常用的最小配置，未列出的配置使用默认值
+type ServerConfig struct {
+	Address        string                    // HTTP 监听地址，支持 unix: 地址
+	APIKey         string                    // 为空时不鉴权
+	MaxConnections int                       // 为 0 时使用默认值 100
+	ToolTimeout    time.Duration             // 工具未配置超时时的默认超时
+	ShutdownDrain  time.Duration             // 关闭时的排空窗口
+	JobStoreDir    string                    // 异步任务持久化目录，为空时不持久化
+	Tools          *config.ToolManagerConfig // 为空时启用全部分类
+}
+
+// Config 展开为完整的服务器配置
+func (sc ServerConfig) Config() *config.Config {
+	toolConfig := sc.Tools
+	if toolConfig == nil {
+		toolConfig = DefaultToolConfig()
+	}
+	return &config.Config{
+		ServerAddress:  sc.Address,
+		APIKey:         sc.APIKey,
+		MaxConnections: sc.MaxConnections,
+		ToolTimeout:    sc.ToolTimeout,
+		ShutdownDrain:  sc.ShutdownDrain,
+		JobStoreDir:    sc.JobStoreDir,
+		ToolConfig:     *toolConfig,
+	}
+}
+
+// DefaultToolConfig 启用全部分类的工具配置，每个分类最多注册 100 个工具
+func DefaultToolConfig() *config.ToolManagerConfig {
+	categories := make(map[string]config.CategoryConfig)
+	for _, category := range []tools.ToolCategory{tools.CategoryMath, tools.CategoryAI, tools.CategorySystem, tools.CategoryUtility} {
+		categories[string(category)] = config.CategoryConfig{Enabled: true, MaxTools: 100}
+	}
+	return &config.ToolManagerConfig{Categories: categories}
+}
+
+// serverOptions New 的选项
+type serverOptions struct {
+	config    *config.Config
+	source    ConfigSource
+	logger    *logger.Logger
+	tools     []tools.Tool             // 为 nil 时注册所有内置工具
+	providers []tools.ResourceProvider // 为 nil 时按配置注册内置资源提供者
+}
+
+// ServerOption 创建服务器的选项
+type ServerOption func(*serverOptions)
+
+// WithConfig 使用完整的服务器配置，优先于 WithServerConfig 与 WithConfigSource
+func WithConfig(cfg *config.Config) ServerOption {
+	return func(o *serverOptions) {
+		o.config = cfg
+	}
+}
+
+// WithServerConfig 使用最小配置
+func WithServerConfig(sc ServerConfig) ServerOption {
+	return WithConfig(sc.Config())
+}
+
+// WithConfigSource 从指定来源加载配置，如 EnvConfig
+func WithConfigSource(source ConfigSource) ServerOption {
+	return func(o *serverOptions) {
+		o.source = source
+	}
+}
+
+// WithLogger 将日志转发到嵌入应用的日志库，如 slog.Default()
+//
+// 传入 *slog.Logger 时直接交给其 handler 处理，保留 trace 等级别与字段类型。
+func WithLogger(log Logger) ServerOption {
+	if sl, ok := log.(*slog.Logger); ok {
+		return WithLogHandler(sl.Handler())
+	}
+	return func(o *serverOptions) {
+		o.logger = logger.NewWithBackend(log)
+	}
+}
+
+// WithLogHandler 将日志转发到嵌入应用的 slog.Handler
+func WithLogHandler(handler slog.Handler) ServerOption {
+	return func(o *serverOptions) {
+		o.logger = logger.NewWithHandler(handler)
+	}
+}
+
+// WithLogManager 使用已创建的日志管理器，如独立服务写入日志目录的日志
+func WithLogManager(log *logger.Logger) ServerOption {
+	return func(o *serverOptions) {
+		o.logger = log
+	}
+}
+
+// WithTools 只注册指定工具，不注册内置工具
+func WithTools(toolList ...tools.Tool) ServerOption {
+	return func(o *serverOptions) {
+		o.tools = append([]tools.Tool{}, toolList...)
+	}
+}
+
+// WithResourceProviders 只注册指定的资源提供者，不注册按 resources 配置创建的内置提供者
+func WithResourceProviders(providers ...tools.ResourceProvider) ServerOption {
+	return func(o *serverOptions) {
+		o.providers = append([]tools.ResourceProvider{}, providers...)
+	}
+}
+
+// New 按选项创建 MCP 服务器
+//
+// 未提供配置时使用 ServerConfig 的默认值，未提供日志时转发到 slog.Default()，
+// 未使用 WithTools 时注册所有内置工具。
+func New(opts ...ServerOption) (*Server, error) {
+	var o serverOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cfg := o.config
+	if cfg == nil && o.source != nil {
+		loaded, err := o.source.Load()
+		if err != nil {
+			return nil, err
+		}
+		cfg = loaded
+	}
+	if cfg == nil {
+		cfg = ServerConfig{}.Config()
+	}
+
+	log := o.logger
+	if log == nil {
+		log = logger.NewWithHandler(slog.Default().Handler())
+	}
+	return newServer(cfg, log, o.tools, o.providers)
+}
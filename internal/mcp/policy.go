@@ -0,0 +1,37 @@
+package mcp
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/pagination"
+	"Weave-Toolkit/internal/tools"
+)
+
+// handleAdminPolicyAudit 分页列出被内容安全策略拒绝的调用，可按 tool、tenant 过滤
+func (s *Server) handleAdminPolicyAudit(c *gin.Context) {
+	params, err := pagination.ParseParams(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tool, tenant := c.Query("tool"), c.Query("tenant")
+	list := s.toolMgr.PolicyAudit()
+	filtered := list[:0]
+	for _, entry := range list {
+		if tool != "" && entry.Tool != tool {
+			continue
+		}
+		if tenant != "" && entry.Tenant != tenant {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+
+	page := pagination.Paginate(filtered, params, func(entry tools.PolicyAuditEntry) pagination.Cursor {
+		return pagination.Cursor{Time: entry.Time, ID: entry.ID}
+	})
+	c.JSON(http.StatusOK, page.Response("entries"))
+}
@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"math"
+	"net/http"
+	"runtime/debug"
+	"runtime/metrics"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultPressureLimit 负载系数达到该值时就绪检查返回 503
+const defaultPressureLimit = 0.85
+
+// 负载状态
+const (
+	PressureOK       = "ok"
+	PressureDegraded = "degraded" // 接近容量上限，应扩容或分流
+	PressureDraining = "draining" // 正在排空或关闭
+)
+
+// 进程占用内存的 runtime/metrics 指标，与 GOMEMLIMIT 的统计口径一致
+const (
+	metricMemoryTotal    = "/memory/classes/total:bytes"
+	metricMemoryReleased = "/memory/classes/heap/released:bytes"
+)
+
+// LoadFactor 单项负载指标，Utilization 为 Current/Limit，超过 1 表示已有请求排队
+type LoadFactor struct {
+	Current     float64 `json:"current"`
+	Limit       float64 `json:"limit"`
+	Utilization float64 `json:"utilization"`
+}
+
+// PressureReport 负载报告
+type PressureReport struct {
+	Status    string                `json:"status"`
+	Pressure  float64               `json:"pressure"` // 各项指标利用率的最大值
+	Limit     float64               `json:"limit"`    // 判定为 degraded 的阈值
+	Factors   map[string]LoadFactor `json:"factors"`
+	Timestamp string                `json:"timestamp"`
+}
+
+// NewPressureReport 创建负载报告，limit 为判定为 degraded 的阈值
+func NewPressureReport(limit float64) *PressureReport {
+	return &PressureReport{
+		Status:    PressureOK,
+		Limit:     limit,
+		Factors:   make(map[string]LoadFactor),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
+
+// Add 记录一项负载指标并更新状态，上限未知（<= 0）时不参与评估
+func (r *PressureReport) Add(name string, current, limit float64) {
+	if limit <= 0 {
+		return
+	}
+	factor := LoadFactor{
+		Current:     current,
+		Limit:       limit,
+		Utilization: math.Round(current/limit*1000) / 1000,
+	}
+	r.Factors[name] = factor
+	r.Pressure = math.Max(r.Pressure, factor.Utilization)
+	if r.Status == PressureOK && r.Pressure >= r.Limit {
+		r.Status = PressureDegraded
+	}
+}
+
+// Drain 标记实例正在排空
+func (r *PressureReport) Drain() {
+	r.Status = PressureDraining
+}
+
+// pressureLimit 判定为 degraded 的负载阈值
+func (s *Server) pressureLimit() float64 {
+	if s.config.PressureLimit > 0 {
+		return s.config.PressureLimit
+	}
+	return defaultPressureLimit
+}
+
+// memoryBudget 内存预算，未配置时使用 GOMEMLIMIT，均未设置时返回 0
+func (s *Server) memoryBudget() int64 {
+	if s.config.MemoryBudget > 0 {
+		return s.config.MemoryBudget
+	}
+	if limit := debug.SetMemoryLimit(-1); limit < math.MaxInt64 {
+		return limit
+	}
+	return 0
+}
+
+// memoryInUse 进程从操作系统获取且未归还的内存
+func memoryInUse() uint64 {
+	samples := []metrics.Sample{{Name: metricMemoryTotal}, {Name: metricMemoryReleased}}
+	metrics.Read(samples)
+	var values [2]uint64
+	for i, sample := range samples {
+		if sample.Value.Kind() == metrics.KindUint64 {
+			values[i] = sample.Value.Uint64()
+		}
+	}
+	return values[0] - values[1]
+}
+
+// pressureReport 汇总执行并发、任务队列、连接与内存的负载
+func (s *Server) pressureReport() *PressureReport {
+	report := NewPressureReport(s.pressureLimit())
+
+	inUse, capacity, waiting := s.toolMgr.PoolLoad()
+	report.Add("concurrency", float64(inUse)+float64(waiting), float64(capacity))
+
+	depth, size := s.jobMgr.QueueLoad()
+	report.Add("queue", float64(depth), float64(size))
+
+	s.connPool.mu.RLock()
+	active, maxSize := s.connPool.active, s.connPool.maxSize
+	s.connPool.mu.RUnlock()
+	report.Add("connections", float64(active), float64(maxSize))
+
+	report.Add("memory", float64(memoryInUse()), float64(s.memoryBudget()))
+
+	if s.isShuttingDown() {
+		report.Drain()
+	}
+	return report
+}
+
+// handlePressure 负载报告端点，始终返回 200，供 HPA 或负载均衡器采集
+func (s *Server) handlePressure(c *gin.Context) {
+	c.JSON(http.StatusOK, s.pressureReport())
+}
+
+// handleReadiness 就绪检查端点，负载达到阈值或排空中返回 503，使流量在硬性失败前转移
+func (s *Server) handleReadiness(c *gin.Context) {
+	report := s.pressureReport()
+	status := http.StatusOK
+	if report.Status != PressureOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/profiling"
+)
+
+// startProfiling 配置 MCP_PROFILING_URL 时启动持续剖析，开始关闭时上报最后一个周期后退出
+func (s *Server) startProfiling() {
+	if s.config.ProfileURL == "" {
+		return
+	}
+
+	shipper := profiling.NewShipper(profiling.Config{
+		ServerURL: s.config.ProfileURL,
+		AppName:   s.config.ProfileApp,
+		Tags:      profiling.ParseTags(s.config.ProfileTags),
+		Interval:  s.config.ProfileInterval,
+		AuthToken: s.config.ProfileToken,
+	}, s.logger)
+
+	go func() {
+		if err := shipper.Run(s.shutdownCtx); err != nil {
+			s.logger.Error().Err(err).Msg("Continuous profiling failed")
+		}
+	}()
+}
+
+// registerPprofRoutes 在管理接口下提供 pprof 端点，供 Parca 等拉取式剖析服务采集
+//
+// net/http/pprof 的索引页依赖固定的 /debug/pprof/ 前缀，这里按名称分发，挂载在 /admin 下同样可用。
+func registerPprofRoutes(group *gin.RouterGroup) {
+	group.GET("/debug/pprof/", gin.WrapF(pprof.Index))
+	group.GET("/debug/pprof/:profile", func(c *gin.Context) {
+		switch name := c.Param("profile"); name {
+		case "cmdline":
+			pprof.Cmdline(c.Writer, c.Request)
+		case "profile":
+			pprof.Profile(c.Writer, c.Request)
+		case "symbol":
+			pprof.Symbol(c.Writer, c.Request)
+		case "trace":
+			pprof.Trace(c.Writer, c.Request)
+		default:
+			pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+		}
+	})
+	group.POST("/debug/pprof/symbol", gin.WrapF(pprof.Symbol))
+}
+
+// handleDebugRuntime 返回协程数、堆与 GC 统计、构建信息与运行时长，用于线上排查
+//
+// 读取 MemStats 会短暂暂停所有协程，不适合高频采集；持续监控请使用 /metrics。
+func (s *Server) handleDebugRuntime(c *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	gc := gin.H{
+		"num_gc":          mem.NumGC,
+		"num_forced_gc":   mem.NumForcedGC,
+		"next_gc":         mem.NextGC,
+		"pause_total_ms":  float64(mem.PauseTotalNs) / float64(time.Millisecond),
+		"gc_cpu_fraction": mem.GCCPUFraction,
+		"memory_limit":    debug.SetMemoryLimit(-1), // 负数参数只读取当前值
+	}
+	if mem.NumGC > 0 {
+		gc["last_gc"] = time.Unix(0, int64(mem.LastGC)).Format(time.RFC3339Nano)
+		gc["last_pause_ms"] = float64(mem.PauseNs[(mem.NumGC+255)%256]) / float64(time.Millisecond)
+	}
+
+	build := gin.H{
+		"go_version": runtime.Version(),
+		"os":         runtime.GOOS,
+		"arch":       runtime.GOARCH,
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		build["path"] = info.Path
+		build["module"] = info.Main.Path
+		build["module_version"] = info.Main.Version
+		settings := make(map[string]string, len(info.Settings))
+		for _, setting := range info.Settings {
+			settings[setting.Key] = setting.Value
+		}
+		build["settings"] = settings // vcs.revision、vcs.time 与编译参数
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"started_at":     s.startedAt.Format(time.RFC3339),
+		"uptime_seconds": int64(time.Since(s.startedAt).Seconds()),
+		"goroutines":     runtime.NumGoroutine(),
+		"gomaxprocs":     runtime.GOMAXPROCS(0),
+		"num_cpu":        runtime.NumCPU(),
+		"cgo_calls":      runtime.NumCgoCall(),
+		"heap": gin.H{
+			"alloc":       mem.HeapAlloc,
+			"inuse":       mem.HeapInuse,
+			"idle":        mem.HeapIdle,
+			"released":    mem.HeapReleased,
+			"sys":         mem.HeapSys,
+			"objects":     mem.HeapObjects,
+			"total_alloc": mem.TotalAlloc,
+			"mallocs":     mem.Mallocs,
+			"frees":       mem.Frees,
+			"stack_inuse": mem.StackInuse,
+			"process_sys": mem.Sys,
+		},
+		"gc":    gc,
+		"build": build,
+	})
+}
@@ -0,0 +1,497 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/internal/tools"
+)
+
+// 提示词默认参数
+const (
+	defaultPromptPollInterval = 2 * time.Second
+	defaultPromptEmbedBytes   = 1 << 20 // 渲染时嵌入内容的默认总大小上限
+	maxPromptDepth            = 8       // 提示词嵌套引用的最大层数
+)
+
+// promptPlaceholder 匹配模板中的 {{name}} 参数
+var promptPlaceholder = regexp.MustCompile(`\{\{([A-Za-z_][A-Za-z0-9_.-]*)\}\}`)
+
+// promptFileExts 提示词目录中识别的文件类型，同名文件按此顺序优先
+var promptFileExts = []string{".json", ".md", ".txt"}
+
+// promptLibrary 从目录加载的提示词库，目录变化时重新加载
+type promptLibrary struct {
+	dir    string
+	logger *logger.Logger
+
+	mu      sync.RWMutex
+	prompts map[string]config.PromptConfig
+	state   string // 提示词文件的名称、大小与修改时间，用于发现目录变化
+}
+
+// newPromptLibrary 加载提示词目录，目录不存在时提示词库为空
+func newPromptLibrary(dir string, logger *logger.Logger) *promptLibrary {
+	library := &promptLibrary{dir: dir, logger: logger, prompts: map[string]config.PromptConfig{}}
+	library.reload()
+	return library
+}
+
+// reload 目录有变化时重新加载，返回提示词集合是否改变
+func (pl *promptLibrary) reload() bool {
+	entries, err := os.ReadDir(pl.dir)
+	if err != nil && !os.IsNotExist(err) {
+		pl.logger.Warn().Err(err).Str("dir", pl.dir).Msg("Failed to read prompt directory")
+		return false
+	}
+
+	files := map[string]os.FileInfo{}
+	var state strings.Builder
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || promptFileExt(entry.Name()) == "" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files[entry.Name()] = info
+		fmt.Fprintf(&state, "%s:%d:%d\n", entry.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+
+	pl.mu.RLock()
+	unchanged := state.String() == pl.state
+	pl.mu.RUnlock()
+	if unchanged {
+		return false
+	}
+
+	prompts := map[string]config.PromptConfig{}
+	for _, ext := range promptFileExts {
+		for file := range files {
+			if filepath.Ext(file) != ext {
+				continue
+			}
+			name := strings.TrimSuffix(file, ext)
+			if _, exists := prompts[name]; exists {
+				pl.logger.Warn().Str("file", file).Msg("Prompt already defined by another file, skipping")
+				continue
+			}
+			prompt, err := loadPromptFile(filepath.Join(pl.dir, file))
+			if err != nil {
+				pl.logger.Warn().Err(err).Str("file", file).Msg("Skipping invalid prompt file")
+				continue
+			}
+			prompts[name] = prompt
+		}
+	}
+
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	changed := !reflect.DeepEqual(prompts, pl.prompts)
+	pl.prompts, pl.state = prompts, state.String()
+	return changed
+}
+
+// watch 按间隔检查目录，提示词集合变化时调用 onChange，ctx 取消后返回
+func (pl *promptLibrary) watch(ctx context.Context, interval time.Duration, onChange func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if pl.reload() {
+				pl.logger.Info().Str("dir", pl.dir).Int("prompts", pl.count()).Msg("Prompt directory changed")
+				onChange()
+			}
+		}
+	}
+}
+
+// list 列出提示词，按名称排序
+func (pl *promptLibrary) list() []PromptInfo {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	return promptInfos(pl.prompts)
+}
+
+// get 按名称获取提示词
+func (pl *promptLibrary) get(name string) (config.PromptConfig, bool) {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	prompt, exists := pl.prompts[name]
+	return prompt, exists
+}
+
+// count 提示词数量
+func (pl *promptLibrary) count() int {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	return len(pl.prompts)
+}
+
+// promptFileExt 返回提示词文件的类型，不是提示词文件时返回空
+func promptFileExt(name string) string {
+	ext := filepath.Ext(name)
+	for _, known := range promptFileExts {
+		if ext == known {
+			return ext
+		}
+	}
+	return ""
+}
+
+// loadPromptFile 读取提示词文件：.json 为完整定义，其他文件的内容作为模板
+func loadPromptFile(path string) (config.PromptConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config.PromptConfig{}, err
+	}
+
+	if filepath.Ext(path) != ".json" {
+		template := string(data)
+		return config.PromptConfig{Template: template, Arguments: templateArguments(template)}, nil
+	}
+
+	var prompt config.PromptConfig
+	if err := json.Unmarshal(data, &prompt); err != nil {
+		return config.PromptConfig{}, fmt.Errorf("invalid prompt definition: %v", err)
+	}
+	if err := validatePrompt(prompt); err != nil {
+		return config.PromptConfig{}, err
+	}
+	return prompt, nil
+}
+
+// validatePrompt 检查提示词定义：需要模板或消息，每条消息只能有一种内容
+func validatePrompt(prompt config.PromptConfig) error {
+	if prompt.Template == "" && len(prompt.Messages) == 0 {
+		return fmt.Errorf("prompt has no template or messages")
+	}
+	seen := map[string]bool{}
+	for _, arg := range prompt.Arguments {
+		if arg.Name == "" {
+			return fmt.Errorf("prompt argument name is empty")
+		}
+		if seen[arg.Name] {
+			return fmt.Errorf("prompt argument %s is declared twice", arg.Name)
+		}
+		seen[arg.Name] = true
+		if arg.Default != "" && len(arg.Enum) > 0 && !containsString(arg.Enum, arg.Default) {
+			return fmt.Errorf("default of prompt argument %s is not one of its enum values", arg.Name)
+		}
+	}
+	for i, message := range prompt.Messages {
+		if message.Role != "" && message.Role != "user" && message.Role != "assistant" {
+			return fmt.Errorf("message %d: role must be user or assistant", i)
+		}
+		kinds := 0
+		for _, set := range []bool{message.Text != "", message.Resource != "", message.Tool != nil, message.Prompt != ""} {
+			if set {
+				kinds++
+			}
+		}
+		if kinds != 1 {
+			return fmt.Errorf("message %d: exactly one of text, resource, tool or prompt is required", i)
+		}
+		if message.Tool != nil && message.Tool.Name == "" {
+			return fmt.Errorf("message %d: tool name is empty", i)
+		}
+	}
+	return nil
+}
+
+// templateArguments 按出现顺序列出模板中的参数，均视为必填
+func templateArguments(template string) []config.PromptArgumentConfig {
+	var arguments []config.PromptArgumentConfig
+	seen := map[string]bool{}
+	for _, match := range promptPlaceholder.FindAllStringSubmatch(template, -1) {
+		if name := match[1]; !seen[name] {
+			seen[name] = true
+			arguments = append(arguments, config.PromptArgumentConfig{Name: name, Required: true})
+		}
+	}
+	return arguments
+}
+
+// promptInfos 将提示词定义转换为列表项，按名称排序
+func promptInfos(prompts map[string]config.PromptConfig) []PromptInfo {
+	infos := make([]PromptInfo, 0, len(prompts))
+	for name, prompt := range prompts {
+		info := PromptInfo{Name: name, Description: prompt.Description}
+		for _, arg := range prompt.Arguments {
+			info.Arguments = append(info.Arguments, PromptArgument{
+				Name:        arg.Name,
+				Description: arg.Description,
+				Required:    arg.Required && arg.Default == "",
+				Default:     arg.Default,
+				Enum:        arg.Enum,
+			})
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// startPromptLibrary 加载 prompts.dir 并在后台检查变化，提示词变化时通知非租户的订阅者
+//
+// 目录只在启动时读取配置，重新加载工具配置不会更换目录。
+func (s *Server) startPromptLibrary() {
+	cfg := s.config.ToolConfig.Prompts
+	if cfg.Dir == "" {
+		return
+	}
+	s.prompts = newPromptLibrary(cfg.Dir, s.logger)
+	s.logger.Info().Str("dir", cfg.Dir).Int("prompts", s.prompts.count()).Msg("Loaded prompt directory")
+
+	interval := time.Duration(cfg.PollInterval) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultPromptPollInterval
+	}
+	go s.prompts.watch(s.shutdownCtx, interval, func() {
+		// 租户使用各自配置中的提示词库，不受目录变化影响
+		s.notifications.broadcast(NotificationPromptsListChanged, nil, func(sub *notificationSubscriber) bool {
+			return sub.tenant == ""
+		})
+	})
+}
+
+// renderPrompt 按名称渲染提示词，租户请求使用租户的提示词库，其他请求使用 prompts.dir
+func (s *Server) renderPrompt(ctx context.Context, name string, arguments map[string]interface{}) (map[string]interface{}, error) {
+	lookup := func(name string) (config.PromptConfig, bool) {
+		if s.prompts == nil {
+			return config.PromptConfig{}, false
+		}
+		return s.prompts.get(name)
+	}
+	if tenant := tools.TenantFromContext(ctx); tenant != nil {
+		lookup = func(name string) (config.PromptConfig, bool) {
+			prompt, exists := tenant.Config.Prompts[name]
+			return prompt, exists
+		}
+	}
+
+	prompt, exists := lookup(name)
+	if !exists {
+		return nil, werrors.NotFound("prompt not found: %s", name)
+	}
+	maxBytes := s.toolConfig().Prompts.MaxEmbedBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultPromptEmbedBytes
+	}
+
+	renderer := &promptRenderer{server: s, lookup: lookup, maxBytes: maxBytes}
+	messages, err := renderer.render(ctx, name, prompt, arguments)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"description": prompt.Description,
+		"messages":    messages,
+	}, nil
+}
+
+// promptRenderer 渲染提示词，展开嵌入的资源、工具结果与其他提示词
+type promptRenderer struct {
+	server   *Server
+	lookup   func(name string) (config.PromptConfig, bool)
+	maxBytes int64
+	embedded int64    // 已嵌入的资源与工具结果字节数
+	stack    []string // 正在渲染的提示词，用于发现循环引用
+}
+
+// render 将参数代入提示词，模板为第一条用户消息，其后依次为 messages 展开后的消息
+func (r *promptRenderer) render(ctx context.Context, name string, prompt config.PromptConfig, arguments map[string]interface{}) ([]map[string]interface{}, error) {
+	for _, active := range r.stack {
+		if active == name {
+			return nil, werrors.InvalidParams("prompt includes itself: %s", strings.Join(append(r.stack, name), " -> "))
+		}
+	}
+	if len(r.stack) >= maxPromptDepth {
+		return nil, werrors.InvalidParams("prompt includes are nested deeper than %d levels", maxPromptDepth)
+	}
+	if err := validatePrompt(prompt); err != nil {
+		return nil, werrors.InvalidParams("invalid prompt %s: %v", name, err)
+	}
+	r.stack = append(r.stack, name)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+
+	replacer, err := promptReplacer(prompt, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []map[string]interface{}{}
+	if prompt.Template != "" {
+		messages = append(messages, promptMessage("user", textContent(replacer.Replace(prompt.Template))))
+	}
+	for _, message := range prompt.Messages {
+		role := message.Role
+		if role == "" {
+			role = "user"
+		}
+
+		switch {
+		case message.Prompt != "":
+			included, exists := r.lookup(message.Prompt)
+			if !exists {
+				return nil, werrors.NotFound("prompt %s includes unknown prompt: %s", name, message.Prompt)
+			}
+			nested, err := r.render(ctx, message.Prompt, included, arguments)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, nested...)
+
+		case message.Resource != "":
+			uri := replacer.Replace(message.Resource)
+			contents, err := r.server.readResourceContents(ctx, uri)
+			if err != nil {
+				return nil, fmt.Errorf("prompt %s: %w", name, err)
+			}
+			for _, item := range contents.Contents {
+				text, _ := item["text"].(string)
+				blob, _ := item["blob"].(string)
+				if err := r.embed(len(text) + len(blob)); err != nil {
+					return nil, err
+				}
+				messages = append(messages, promptMessage(role, map[string]interface{}{"type": "resource", "resource": item}))
+			}
+
+		case message.Tool != nil:
+			content, err := r.callTool(ctx, name, message.Tool, replacer)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range content {
+				messages = append(messages, promptMessage(role, item))
+			}
+
+		default:
+			messages = append(messages, promptMessage(role, textContent(replacer.Replace(message.Text))))
+		}
+	}
+	return messages, nil
+}
+
+// callTool 调用提示词引用的工具，返回结果中的各条内容
+func (r *promptRenderer) callTool(ctx context.Context, name string, tool *config.PromptToolConfig, replacer *strings.Replacer) ([]map[string]interface{}, error) {
+	arguments, err := json.Marshal(replaceArguments(tool.Arguments, replacer))
+	if err != nil {
+		return nil, werrors.InvalidParams("prompt %s: invalid arguments for tool %s: %v", name, tool.Name, err)
+	}
+	result, err := r.server.toolMgr.CallTool(ctx, tool.Name, arguments)
+	if err != nil {
+		return nil, fmt.Errorf("prompt %s: %w", name, err)
+	}
+
+	if result.IsError {
+		message := ""
+		if len(result.Content) > 0 {
+			message = result.Content[0].Text
+		}
+		return nil, werrors.Errorf(werrors.KindInternal, "prompt %s: tool %s failed: %s", name, tool.Name, message)
+	}
+
+	content := make([]map[string]interface{}, 0, len(result.Content))
+	for _, item := range result.Content {
+		if item.Type == "image" {
+			data := fmt.Sprint(item.Data)
+			if err := r.embed(len(data)); err != nil {
+				return nil, err
+			}
+			content = append(content, map[string]interface{}{"type": "image", "data": data, "mimeType": item.MimeType})
+			continue
+		}
+		if err := r.embed(len(item.Text)); err != nil {
+			return nil, err
+		}
+		content = append(content, textContent(item.Text))
+	}
+	return content, nil
+}
+
+// embed 累计嵌入的内容大小，超过上限时返回错误
+func (r *promptRenderer) embed(size int) error {
+	r.embedded += int64(size)
+	if r.embedded > r.maxBytes {
+		return werrors.InvalidParams("embedded prompt content exceeds %d bytes", r.maxBytes)
+	}
+	return nil
+}
+
+// promptReplacer 按提示词声明的参数生成 {{name}} 替换规则
+//
+// 未提供的参数使用默认值，没有默认值的可选参数替换为空；缺少必填参数时错误中列出全部缺少的参数，
+// 取值不在 enum 中时返回允许的取值。
+func promptReplacer(prompt config.PromptConfig, arguments map[string]interface{}) (*strings.Replacer, error) {
+	replacements := []string{}
+	var missing []string
+	for _, arg := range prompt.Arguments {
+		value := arg.Default
+		if provided, ok := arguments[arg.Name]; ok && provided != nil {
+			value = fmt.Sprint(provided)
+		} else if arg.Required && arg.Default == "" {
+			missing = append(missing, arg.Name)
+			continue
+		}
+		if len(arg.Enum) > 0 && value != "" && !containsString(arg.Enum, value) {
+			return nil, fmt.Errorf("%w: argument %s must be one of: %s", errInvalidParams, arg.Name, strings.Join(arg.Enum, ", "))
+		}
+		replacements = append(replacements, "{{"+arg.Name+"}}", value)
+	}
+	if len(missing) == 1 {
+		return nil, fmt.Errorf("%w: missing required argument: %s", errInvalidParams, missing[0])
+	}
+	if len(missing) > 1 {
+		return nil, fmt.Errorf("%w: missing required arguments: %s", errInvalidParams, strings.Join(missing, ", "))
+	}
+	return strings.NewReplacer(replacements...), nil
+}
+
+// replaceArguments 复制工具参数，替换其中字符串值的 {{name}}
+func replaceArguments(value interface{}, replacer *strings.Replacer) interface{} {
+	switch v := value.(type) {
+	case string:
+		return replacer.Replace(v)
+	case map[string]interface{}:
+		replaced := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			replaced[key] = replaceArguments(item, replacer)
+		}
+		return replaced
+	case []interface{}:
+		replaced := make([]interface{}, len(v))
+		for i, item := range v {
+			replaced[i] = replaceArguments(item, replacer)
+		}
+		return replaced
+	default:
+		return v
+	}
+}
+
+// promptMessage 构造提示词消息
+func promptMessage(role string, content map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"role": role, "content": content}
+}
+
+// textContent 构造文本内容
+func textContent(text string) map[string]interface{} {
+	return map[string]interface{}{"type": "text", "text": text}
+}
@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"time"
+)
+
+// 会话清理参数
+const (
+	defaultSessionReapInterval = time.Minute
+	minSessionReapInterval     = 10 * time.Millisecond
+)
+
+// 会话结束的原因，写入日志
+const (
+	sessionEndIdle   = "idle"
+	sessionEndClient = "client"
+	sessionEndAdmin  = "admin"
+)
+
+// startSessionReaper 按间隔清理空闲超过 TTL 的会话，服务器关闭时停止
+//
+// 未配置间隔时取 TTL 的一半，且不超过一分钟。
+func (s *Server) startSessionReaper() {
+	interval := s.config.SessionReapInterval
+	if interval <= 0 {
+		interval = min(s.sessions.TTL()/2, defaultSessionReapInterval)
+	}
+	interval = max(interval, minSessionReapInterval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.shutdownCtx.Done():
+				return
+			case <-ticker.C:
+				s.reapSessions()
+			}
+		}
+	}()
+}
+
+// reapSessions 清理一轮空闲会话，返回清理的会话数
+func (s *Server) reapSessions() int {
+	expired := s.sessions.Reap()
+	for _, session := range expired {
+		s.releaseSession(session, sessionEndIdle)
+	}
+	return len(expired)
+}
+
+// endSession 删除会话并释放其关联的通知流、事件流与任务，返回会话是否存在
+func (s *Server) endSession(id, reason string) bool {
+	session, exists := s.sessions.remove(id)
+	if session != nil {
+		s.releaseSession(session, reason)
+	}
+	return exists
+}
+
+// releaseSession 释放已删除会话在本副本上关联的通知流、事件流缓冲与未完成的异步任务
+func (s *Server) releaseSession(session *Session, reason string) {
+	streams, jobIDs := session.owned()
+	subscriptions := s.notifications.closeSession(session.ID)
+	removed := s.events.Remove(streams...)
+	cancelled := 0
+	for _, id := range jobIDs {
+		job, err := s.jobMgr.Get(id)
+		if err != nil || job.Status.Terminal() {
+			continue
+		}
+		if _, err := s.jobMgr.Cancel(id); err == nil {
+			cancelled++
+		}
+	}
+
+	s.logger.Info().
+		Str("session_id", session.ID).
+		Str("client", session.Client).
+		Str("reason", reason).
+		Dur("idle", session.idleFor()).
+		Int("subscriptions", subscriptions).
+		Int("streams", removed).
+		Int("jobs_cancelled", cancelled).
+		Msg("Session ended, released resources")
+}
@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/jsonstream"
+)
+
+// rpcResult JSON-RPC 成功响应，字段顺序与 gin.H 编码时的键排序一致
+type rpcResult struct {
+	id     interface{}
+	result interface{}
+}
+
+// MarshalJSONStream 写入响应信封，结果实现 jsonstream.Marshaler 时直接流式写出
+func (r rpcResult) MarshalJSONStream(s *jsonstream.Stream) {
+	s.Raw(`{"id":`)
+	s.Value(r.id)
+	s.Raw(`,"jsonrpc":"2.0","result":`)
+	s.Value(r.result)
+	s.Raw("}")
+}
+
+// writeRPCResult 以流式编码写出 JSON-RPC 成功响应，大型工具结果不在内存中完整缓冲；
+// 编码失败且尚未写出任何内容时改为返回内部错误
+func (s *Server) writeRPCResult(c *gin.Context, id, result interface{}) {
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := jsonstream.Encode(c.Writer, rpcResult{id: id, result: result}); err != nil {
+		if !c.Writer.Written() {
+			s.sendGinErrorResponse(c, "Failed to encode result: "+err.Error(), -32603)
+			return
+		}
+		s.logger.Error().Err(err).Msg("Failed to write response")
+	}
+}
@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/middleware"
+)
+
+// RESTToolsPath REST 桥接的工具端点前缀
+const RESTToolsPath = "/api/tools"
+
+// restClientName 未携带 X-MCP-Client-Name 时 REST 调用使用的客户端名称
+const restClientName = "rest"
+
+// handleRESTTools 列出可通过 REST 调用的工具
+func (s *Server) handleRESTTools(c *gin.Context) {
+	toolInfos := s.tenantTools(c.Request.Context())
+	sort.Slice(toolInfos, func(i, j int) bool { return toolInfos[i].Name < toolInfos[j].Name })
+	c.JSON(http.StatusOK, gin.H{"tools": toolInfos})
+}
+
+// handleRESTToolCall 以请求体作为参数调用工具
+//
+// 请求体为工具参数的 JSON 对象，Content-Type 为 application/jose 时为 JWE 紧凑序列化的加密参数；
+// async=true 时提交异步任务并返回 202。与 /mcp 共用鉴权、连接数上限、排空与请求大小限制。
+func (s *Server) handleRESTToolCall(c *gin.Context) {
+	if s.isShuttingDown() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Service unavailable - server is shutting down",
+		})
+		return
+	}
+
+	s.beginOp()
+	defer s.endOp()
+
+	clientName := c.GetHeader(ClientNameHeader)
+	if clientName == "" {
+		clientName = restClientName
+	}
+	name := s.toolMgr.ResolveAlias(clientName, c.Param("name"))
+	middleware.SetMCPRequestInfo(c, "rest", name, clientName)
+	if !s.toolEnabled(c.Request.Context(), name) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tool not found: " + name})
+		return
+	}
+
+	body := c.Request.Body
+	if s.config.MaxRequestSize > 0 {
+		body = http.MaxBytesReader(c.Writer, body, s.config.MaxRequestSize)
+	}
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "failed to read request body"})
+		return
+	}
+
+	params := map[string]interface{}{}
+	if c.ContentType() == "application/jose" {
+		params["encryptedArguments"] = strings.TrimSpace(string(raw))
+	} else if len(bytes.TrimSpace(raw)) > 0 {
+		var arguments interface{}
+		if err := json.Unmarshal(raw, &arguments); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON body"})
+			return
+		}
+		params["arguments"] = arguments
+	}
+	arguments, err := s.toolCallArguments(params)
+	if err != nil {
+		restError(c, name, err)
+		return
+	}
+
+	conn, err := s.connPool.Acquire(&ClientInfo{Name: clientName, Version: "1.0.0"})
+	if err != nil {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "connection limit exceeded: " + err.Error()})
+		return
+	}
+	defer s.connPool.Release(conn)
+
+	if c.Query("async") == "true" {
+		job, err := s.submitJob(c.Request.Context(), name, arguments, conn)
+		if errors.Is(err, errJobOwnerRequired) {
+			restError(c, name, err)
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"tool": name, "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, job)
+		return
+	}
+
+	ctx := tools.WithClient(c.Request.Context(), clientName)
+	ctx = tools.WithLocale(ctx, c.GetHeader("Accept-Language"))
+	result, err := s.toolMgr.CallTool(ctx, name, arguments)
+	if err != nil {
+		restError(c, name, err)
+		return
+	}
+
+	status := http.StatusOK
+	if result.IsError {
+		status = http.StatusInternalServerError
+	}
+	c.JSON(status, result)
+}
+
+// toolEnabled 工具已注册、处于启用状态且在请求所属租户的工具目录中
+func (s *Server) toolEnabled(ctx context.Context, name string) bool {
+	for _, tool := range s.tenantTools(ctx) {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// restError 以映射后的状态码返回工具调用错误，熔断时通过 Retry-After 告知可重试的秒数
+func restError(c *gin.Context, name string, err error) {
+	var circuitErr *tools.CircuitOpenError
+	if errors.As(err, &circuitErr) {
+		retryAfter := int((circuitErr.RetryAfter + time.Second - 1) / time.Second)
+		c.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+	}
+	c.JSON(restErrorStatus(err), gin.H{"tool": name, "error": err.Error()})
+}
+
+// restErrorStatus 将工具调用错误按分类映射为 HTTP 状态码：参数问题为 400，超过租户调用限额为 429，
+// 熔断中为 503，超时为 504，未分类的执行失败为 422
+func restErrorStatus(err error) int {
+	kind := werrors.KindOf(err)
+	if kind == werrors.KindInternal {
+		return http.StatusUnprocessableEntity
+	}
+	return kind.HTTPStatus()
+}
@@ -1,744 +1,1416 @@
-package mcp
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"sync"
-	"time"
-
-	"github.com/gin-gonic/gin"
-
-	"Weave-Toolkit/config"
-	"Weave-Toolkit/internal/logger"
-	"Weave-Toolkit/internal/tools"
-	"Weave-Toolkit/middleware"
-)
-
-// ConnectionPool 连接池
-type ConnectionPool struct {
-	pool    chan *MCPConnection
-	maxSize int
-	active  int
-	mu      sync.RWMutex
-	logger  *logger.Logger
-}
-
-// MCPConnection MCP 连接
-type MCPConnection struct {
-	ID         string
-	ClientInfo *ClientInfo
-	CreatedAt  time.Time
-	LastActive time.Time
-	Session    map[string]interface{}
-}
-
-// ClientInfo 客户端信息
-type ClientInfo struct {
-	Name    string `json:"name"`
-	Version string `json:"version"`
-}
-
-// Server MCP 服务器
-type Server struct {
-	config       *config.Config
-	logger       *logger.Logger
-	ginEngine    *gin.Engine
-	httpSrv      *http.Server
-	toolMgr      *tools.ToolManager
-	connPool     *ConnectionPool // 连接池
-	activeOps    sync.WaitGroup  // 等待正在执行的操作
-	shuttingDown bool            // 关闭标志
-	shutdownMu   sync.RWMutex    // 关闭状态锁
-}
-
-// NewServer 创建新的 MCP 服务器
-func NewServer(cfg *config.Config, logger *logger.Logger) (*Server, error) {
-	// 初始化工具管理器
-	toolManager := tools.NewToolManager(logger, &cfg.ToolConfig)
-
-	// 注册所有工具
-	toolManager.RegisterAllTools()
-
-	server := &Server{
-		config:  cfg,
-		logger:  logger,
-		toolMgr: toolManager,
-	}
-
-	server.setupGinServer()
-
-	// 初始化连接池
-	maxConnections := 100 // 默认最大连接数
-	if cfg.MaxConnections > 0 {
-		maxConnections = cfg.MaxConnections
-	}
-	server.connPool = NewConnectionPool(maxConnections, logger)
-
-	return server, nil
-}
-
-// NewConnectionPool 创建新的连接池
-func NewConnectionPool(maxSize int, logger *logger.Logger) *ConnectionPool {
-	return &ConnectionPool{
-		pool:    make(chan *MCPConnection, maxSize),
-		maxSize: maxSize,
-		logger:  logger,
-	}
-}
-
-// Acquire 获取连接
-func (cp *ConnectionPool) Acquire(clientInfo *ClientInfo) (*MCPConnection, error) {
-	select {
-	case conn := <-cp.pool:
-		// 从池中获取连接
-		conn.LastActive = time.Now()
-		cp.mu.Lock()
-		cp.active++
-		cp.mu.Unlock()
-		return conn, nil
-	default:
-		// 池为空，创建新连接
-		if cp.active >= cp.maxSize {
-			return nil, fmt.Errorf("connection pool exhausted, max size: %d", cp.maxSize)
-		}
-
-		conn := &MCPConnection{
-			ID:         generateConnectionID(),
-			ClientInfo: clientInfo,
-			CreatedAt:  time.Now(),
-			LastActive: time.Now(),
-			Session:    make(map[string]interface{}),
-		}
-
-		cp.mu.Lock()
-		cp.active++
-		cp.mu.Unlock()
-
-		cp.logger.Debug().
-			Str("connection_id", conn.ID).
-			Str("client", clientInfo.Name).
-			Msg("Created new connection")
-
-		return conn, nil
-	}
-}
-
-// Release 释放连接
-func (cp *ConnectionPool) Release(conn *MCPConnection) {
-	conn.LastActive = time.Now()
-
-	select {
-	case cp.pool <- conn:
-		// 成功放回池中
-		cp.mu.Lock()
-		cp.active--
-		cp.mu.Unlock()
-
-		cp.logger.Debug().
-			Str("connection_id", conn.ID).
-			Msg("Connection released to pool")
-	default:
-		// 池已满，关闭连接
-		cp.mu.Lock()
-		cp.active--
-		cp.mu.Unlock()
-
-		cp.logger.Debug().
-			Str("connection_id", conn.ID).
-			Msg("Connection closed (pool full)")
-	}
-}
-
-// Stats 获取连接池统计信息
-func (cp *ConnectionPool) Stats() map[string]interface{} {
-	cp.mu.RLock()
-	defer cp.mu.RUnlock()
-
-	return map[string]interface{}{
-		"max_size":  cp.maxSize,
-		"active":    cp.active,
-		"pool_size": len(cp.pool),
-		"available": cap(cp.pool) - len(cp.pool),
-	}
-}
-
-// generateConnectionID 生成连接ID
-func generateConnectionID() string {
-	return fmt.Sprintf("conn_%d_%s", time.Now().UnixNano(), randomString(8))
-}
-
-// randomString 生成随机字符串
-func randomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[time.Now().UnixNano()%int64(len(charset))]
-	}
-	return string(b)
-}
-
-// Start 启动 MCP 服务器
-func (s *Server) Start(ctx context.Context) error {
-	s.logger.Info().Str("address", s.config.ServerAddress).Msg("Starting MCP server")
-
-	errChan := make(chan error, 1)
-
-	go func() {
-		if err := s.httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errChan <- err
-		}
-	}()
-
-	select {
-	case err := <-errChan:
-		return err
-	case <-ctx.Done():
-		return s.Stop()
-	}
-}
-
-// Stop 停止 MCP 服务器
-func (s *Server) Stop() error {
-	s.logger.Info().Msg("Stopping MCP server")
-
-	// 设置关闭标志，拒绝新请求
-	s.shutdownMu.Lock()
-	s.shuttingDown = true
-	s.shutdownMu.Unlock()
-
-	s.logger.Info().Msg("Waiting for active operations to complete...")
-
-	// 等待所有正在执行的操作完成（最多等待30秒）
-	done := make(chan struct{})
-	go func() {
-		s.activeOps.Wait()
-		close(done)
-	}()
-
-	select {
-	case <-done:
-		s.logger.Info().Msg("All active operations completed")
-	case <-time.After(30 * time.Second):
-		s.logger.Warn().Msg("Timeout waiting for active operations, forcing shutdown")
-	}
-
-	// 关闭 HTTP 服务器
-	return s.httpSrv.Shutdown(context.Background())
-}
-
-// isShuttingDown 检查服务器是否正在关闭
-func (s *Server) isShuttingDown() bool {
-	s.shutdownMu.RLock()
-	defer s.shutdownMu.RUnlock()
-	return s.shuttingDown
-}
-
-func (s *Server) handleMCPRequest(c *gin.Context) {
-	// 检查服务器是否正在关闭
-	if s.isShuttingDown() {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error": "Service unavailable - server is shutting down",
-		})
-		return
-	}
-
-	// 增加活跃操作计数
-	s.activeOps.Add(1)
-	defer s.activeOps.Done()
-
-	// 绑定 JSON 请求体
-	var req map[string]interface{}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		s.sendGinErrorResponse(c, "Invalid JSON", -32700)
-		return
-	}
-
-	method, ok := req["method"].(string)
-	if !ok {
-		s.sendGinErrorResponse(c, "Missing or invalid method", -32600)
-		return
-	}
-
-	// 获取客户端信息并创建连接
-	clientInfo := extractClientInfo(req)
-	conn, err := s.connPool.Acquire(clientInfo)
-	if err != nil {
-		s.sendGinErrorResponse(c, fmt.Sprintf("Connection limit exceeded: %v", err), -32000)
-		return
-	}
-	defer s.connPool.Release(conn)
-
-	// 在处理请求前更新连接活跃时间
-	conn.LastActive = time.Now()
-
-	// 处理 MCP 请求
-	result, err := s.handleMCPOperation(c.Request.Context(), method, req, conn)
-	if err != nil {
-		s.sendGinErrorResponse(c, err.Error(), -32603)
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"jsonrpc": "2.0",
-		"result":  result,
-		"id":      req["id"],
-	})
-}
-
-func (s *Server) handleMCPOperation(ctx context.Context, method string, req map[string]interface{}, conn *MCPConnection) (interface{}, error) {
-	switch method {
-	case MethodInitialize:
-		return s.handleInitialize(req)
-	case "notifications/initialized":
-		return s.handleInitializedNotification(req)
-	case MethodToolsList:
-		return s.handleToolsList()
-	case MethodToolsCall:
-		return s.handleToolsCall(ctx, req, conn)
-	case MethodResourcesList:
-		return s.handleResourcesList()
-	case MethodResourcesRead:
-		return s.handleResourcesRead(ctx, req, conn)
-	case MethodPromptsList:
-		return s.handlePromptsList()
-	case MethodPromptsGet:
-		return s.handlePromptsGet(ctx, req, conn)
-	case MethodRootsList:
-		return s.handleRootsList()
-	default:
-		return nil, fmt.Errorf("unsupported method: %s", method)
-	}
-}
-
-func (s *Server) handleInitialize(req map[string]interface{}) (interface{}, error) {
-	response := map[string]interface{}{
-		"protocolVersion": ProtocolVersion,
-		"serverInfo": map[string]interface{}{
-			"name":    "Weave-Toolkit",
-			"version": "1.0.0",
-		},
-		"capabilities": map[string]interface{}{
-			"roots": map[string]interface{}{
-				"listChanged": false,
-			},
-			"resources": map[string]interface{}{
-				"listChanged": false,
-			},
-			"tools": map[string]interface{}{
-				"listChanged": false,
-			},
-			"prompts": map[string]interface{}{
-				"listChanged": false,
-			},
-		},
-	}
-
-	return response, nil
-}
-
-func (s *Server) handleInitializedNotification(req map[string]interface{}) (interface{}, error) {
-	return nil, nil
-}
-
-func (s *Server) handleToolsList() (interface{}, error) {
-	toolInfos := s.toolMgr.GetTools()
-
-	// MCP 协议格式
-	var tools []map[string]interface{}
-	for _, tool := range toolInfos {
-		tools = append(tools, map[string]interface{}{
-			"name":        tool.Name,
-			"description": tool.Description,
-			"inputSchema": map[string]interface{}{
-				"type":       "object",
-				"properties": map[string]interface{}{},
-			},
-		})
-	}
-
-	return map[string]interface{}{
-		"tools": tools,
-	}, nil
-}
-
-func (s *Server) handleToolsCall(ctx context.Context, req map[string]interface{}, conn *MCPConnection) (interface{}, error) {
-	params, ok := req["params"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid params")
-	}
-
-	toolName, ok := params["name"].(string)
-	if !ok {
-		return nil, fmt.Errorf("missing or invalid tool name")
-	}
-
-	arguments, err := json.Marshal(params["arguments"])
-	if err != nil {
-		return nil, fmt.Errorf("invalid arguments: %v", err)
-	}
-
-	result, err := s.toolMgr.CallTool(ctx, toolName, arguments)
-	if err != nil {
-		return nil, err
-	}
-
-	return result, nil
-}
-
-// handleResourcesList 处理资源列表请求
-func (s *Server) handleResourcesList() (interface{}, error) {
-	// 返回空资源列表（可根据需要扩展）
-	return map[string]interface{}{
-		"resources": []interface{}{},
-	}, nil
-}
-
-// handleResourcesRead 处理资源读取请求
-func (s *Server) handleResourcesRead(ctx context.Context, req map[string]interface{}, conn *MCPConnection) (interface{}, error) {
-	params, ok := req["params"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid params")
-	}
-
-	uri, ok := params["uri"].(string)
-	if !ok {
-		return nil, fmt.Errorf("missing or invalid uri")
-	}
-
-	// 可以支持文件系统、数据库、HTTP资源等
-	content, err := s.readResource(uri)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read resource: %v", err)
-	}
-
-	return map[string]interface{}{
-		"contents": []map[string]interface{}{
-			{
-				"uri":      uri,
-				"mimeType": "text/plain",
-				"text":     content,
-			},
-		},
-	}, nil
-}
-
-// handlePromptsList 处理提示词列表请求
-func (s *Server) handlePromptsList() (interface{}, error) {
-	// 返回空提示词列表（可根据需要扩展）
-	return map[string]interface{}{
-		"prompts": []interface{}{},
-	}, nil
-}
-
-// handlePromptsGet 处理提示词获取请求
-func (s *Server) handlePromptsGet(ctx context.Context, req map[string]interface{}, conn *MCPConnection) (interface{}, error) {
-	params, ok := req["params"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid params")
-	}
-
-	name, ok := params["name"].(string)
-	if !ok {
-		return nil, fmt.Errorf("missing or invalid prompt name")
-	}
-
-	// 提示词获取（可根据需要扩展）
-	prompt, err := s.getPrompt(name)
-	if err != nil {
-		return nil, fmt.Errorf("prompt not found: %s", name)
-	}
-
-	return prompt, nil
-}
-
-// handleRootsList 处理根目录列表请求
-func (s *Server) handleRootsList() (interface{}, error) {
-	// 返回空根目录列表（可根据需要扩展）
-	return map[string]interface{}{
-		"roots": []interface{}{},
-	}, nil
-}
-
-// readResource 读取资源内容
-func (s *Server) readResource(uri string) (string, error) {
-	// 可以扩展支持文件系统、HTTP资源等
-	if uri == "file:///example.txt" {
-		return "This is an example resource content.", nil
-	}
-
-	return "", fmt.Errorf("resource not found: %s", uri)
-}
-
-// getPrompt 获取提示词
-func (s *Server) getPrompt(name string) (map[string]interface{}, error) {
-	// 可以扩展支持数据库、配置文件等
-	prompts := map[string]map[string]interface{}{
-		"example_prompt": {
-			"description": "An example prompt for demonstration",
-			"arguments": []map[string]interface{}{
-				{
-					"name":        "topic",
-					"description": "The topic to write about",
-					"required":    true,
-				},
-			},
-		},
-	}
-
-	if prompt, exists := prompts[name]; exists {
-		return prompt, nil
-	}
-
-	return nil, fmt.Errorf("prompt not found")
-}
-
-// extractClientInfo 从请求中提取客户端信息
-func extractClientInfo(req map[string]interface{}) *ClientInfo {
-	clientInfo := &ClientInfo{
-		Name:    "unknown",
-		Version: "1.0.0",
-	}
-
-	// 尝试从初始化请求中获取客户端信息
-	if params, ok := req["params"].(map[string]interface{}); ok {
-		if client, ok := params["clientInfo"].(map[string]interface{}); ok {
-			if name, ok := client["name"].(string); ok {
-				clientInfo.Name = name
-			}
-			if version, ok := client["version"].(string); ok {
-				clientInfo.Version = version
-			}
-		}
-	}
-
-	return clientInfo
-}
-
-// setupGinServer 设置 Gin 服务器
-func (s *Server) setupGinServer() {
-	// 模式
-	if s.config.LogLevel == "debug" {
-		gin.SetMode(gin.DebugMode)
-	} else {
-		gin.SetMode(gin.ReleaseMode)
-	}
-
-	s.ginEngine = gin.New()
-
-	// 全局中间件
-	s.ginEngine.Use(
-		middleware.LoggingMiddleware(s.logger),  // 日志中间件
-		middleware.RecoveryMiddleware(s.logger), // 崩溃恢复
-		middleware.RequestIDMiddleware(),        // 请求 ID 追踪
-	)
-
-	// MCP 协议端点
-	mcpGroup := s.ginEngine.Group("/mcp")
-	{
-		mcpGroup.POST("", s.handleMCPRequest)
-		mcpGroup.POST("/stream", s.handleMCPStreamRequest)
-	}
-
-	// 健康检查端点
-	healthGroup := s.ginEngine.Group("/health")
-	{
-		healthGroup.GET("", s.handleHealthCheck)
-		healthGroup.GET("/stats", s.handleStats)
-	}
-
-	// 设置 HTTP 服务器
-	s.httpSrv = &http.Server{
-		Addr:         s.config.ServerAddress,
-		Handler:      s.ginEngine,
-		ReadTimeout:  s.config.ReadTimeout,
-		WriteTimeout: s.config.WriteTimeout,
-		IdleTimeout:  s.config.IdleTimeout,
-	}
-}
-
-// handleStats 统计信息端点
-func (s *Server) handleStats(c *gin.Context) {
-	stats := s.connPool.Stats()
-
-	c.JSON(http.StatusOK, gin.H{
-		"server_info": gin.H{
-			"name":    "Weave-Toolkit",
-			"version": "1.0.0",
-		},
-		"connections": stats,
-		"timestamp":   time.Now().Format(time.RFC3339),
-	})
-}
-
-// handleMCPStreamRequest 处理流式 MCP 请求
-func (s *Server) handleMCPStreamRequest(c *gin.Context) {
-	// 检查服务器是否正在关闭
-	if s.isShuttingDown() {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error": "Service unavailable - server is shutting down",
-		})
-		return
-	}
-
-	// 增加活跃操作计数
-	s.activeOps.Add(1)
-	defer s.activeOps.Done()
-
-	// 设置 SSE 响应头
-	c.Writer.Header().Set("Content-Type", "text/event-stream")
-	c.Writer.Header().Set("Cache-Control", "no-cache")
-	c.Writer.Header().Set("Connection", "keep-alive")
-	c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-	c.Writer.Header().Set("Access-Control-Allow-Headers", "Cache-Control")
-
-	// 绑定 JSON 请求体
-	var req map[string]interface{}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		s.sendStreamError(c.Writer, "Invalid JSON")
-		return
-	}
-
-	method, ok := req["method"].(string)
-	if !ok {
-		s.sendStreamError(c.Writer, "Missing or invalid method")
-		return
-	}
-
-	if method != MethodToolsCall {
-		s.sendStreamError(c.Writer, "Only tools/call method is supported for streaming")
-		return
-	}
-
-	// 获取客户端信息并创建连接
-	clientInfo := extractClientInfo(req)
-	conn, err := s.connPool.Acquire(clientInfo)
-	if err != nil {
-		s.sendStreamError(c.Writer, fmt.Sprintf("Connection limit exceeded: %v", err))
-		return
-	}
-	defer s.connPool.Release(conn)
-
-	// 在处理请求前更新连接活跃时间
-	conn.LastActive = time.Now()
-
-	// 处理流式工具调用
-	s.handleStreamToolsCall(c.Request.Context(), c.Writer, req, conn)
-}
-
-// handleStreamToolsCall 处理流式工具调用
-func (s *Server) handleStreamToolsCall(ctx context.Context, w http.ResponseWriter, req map[string]interface{}, conn *MCPConnection) {
-	params, ok := req["params"].(map[string]interface{})
-	if !ok {
-		s.sendStreamError(w, "Invalid params")
-		return
-	}
-
-	toolName, ok := params["name"].(string)
-	if !ok {
-		s.sendStreamError(w, "Missing or invalid tool name")
-		return
-	}
-
-	arguments, err := json.Marshal(params["arguments"])
-	if err != nil {
-		s.sendStreamError(w, fmt.Sprintf("Invalid arguments: %v", err))
-		return
-	}
-
-	// 发送开始事件
-	s.sendStreamEvent(w, StreamEventToolCall, map[string]interface{}{
-		"tool":   toolName,
-		"status": "started",
-	})
-
-	// 调用工具并获取流式结果
-	result, err := s.toolMgr.CallToolStream(ctx, toolName, arguments, func(content string, index int) {
-		// 发送内容事件
-		s.sendStreamEvent(w, StreamEventContent, map[string]interface{}{
-			"type":    ContentTypeText,
-			"content": content,
-			"index":   index,
-		})
-	})
-
-	if err != nil {
-		// 发送错误事件
-		s.sendStreamEvent(w, StreamEventError, map[string]interface{}{
-			"message": err.Error(),
-		})
-		return
-	}
-
-	// 发送完成事件
-	s.sendStreamEvent(w, StreamEventDone, map[string]interface{}{
-		"result": result,
-	})
-}
-
-// sendStreamEvent 发送流式事件
-func (s *Server) sendStreamEvent(w http.ResponseWriter, event string, data interface{}) {
-	eventData, err := json.Marshal(data)
-	if err != nil {
-		s.logger.Error().Err(err).Msg("Failed to marshal stream event data")
-		return
-	}
-
-	eventStr := fmt.Sprintf("event: %s\ndata: %s\n\n", event, string(eventData))
-
-	if _, err := w.Write([]byte(eventStr)); err != nil {
-		s.logger.Error().Err(err).Msg("Failed to write stream event")
-		return
-	}
-
-	// 刷新缓冲区
-	if flusher, ok := w.(http.Flusher); ok {
-		flusher.Flush()
-	}
-}
-
-// sendStreamError 发送流式错误
-func (s *Server) sendStreamError(w http.ResponseWriter, message string) {
-	s.sendStreamEvent(w, StreamEventError, map[string]interface{}{
-		"message": message,
-	})
-}
-
-// sendGinErrorResponse 错误响应
-func (s *Server) sendGinErrorResponse(c *gin.Context, message string, code int) {
-	c.JSON(http.StatusOK, gin.H{
-		"jsonrpc": "2.0",
-		"error": gin.H{
-			"code":    code,
-			"message": message,
-		},
-		"id": nil,
-	})
-}
-
-func (s *Server) sendErrorResponse(w http.ResponseWriter, message string, code int) {
-	response := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"error": map[string]interface{}{
-			"code":    code,
-			"message": message,
-		},
-		"id": nil,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusBadRequest)
-	json.NewEncoder(w).Encode(response)
-}
-
-// handleHealthCheck 健康检查端点
-func (s *Server) handleHealthCheck(c *gin.Context) {
-	stats := s.connPool.Stats()
-
-	c.JSON(http.StatusOK, gin.H{
-		"status":      "healthy",
-		"timestamp":   time.Now().Format(time.RFC3339),
-		"connections": stats,
-	})
-}
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/cluster"
+	"Weave-Toolkit/internal/envelope"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/history"
+	"Weave-Toolkit/internal/jobs"
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/internal/metering"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/middleware"
+)
+
+// ConnectionPool 连接池
+type ConnectionPool struct {
+	pool    chan *MCPConnection
+	maxSize int
+	active  int
+	mu      sync.RWMutex
+	logger  *logger.Logger
+}
+
+// MCPConnection MCP 连接
+type MCPConnection struct {
+	ID         string
+	ClientInfo *ClientInfo
+	CreatedAt  time.Time
+	LastActive time.Time
+	Session    map[string]interface{}
+}
+
+// ClientInfo 客户端信息
+type ClientInfo struct {
+	Name         string                   `json:"name"`
+	Version      string                   `json:"version"`
+	Locale       string                   `json:"locale,omitempty"`       // BCP 47 语言标签，如 de-DE
+	Capabilities tools.ClientCapabilities `json:"capabilities,omitempty"` // initialize 时声明的能力
+}
+
+// Server MCP 服务器
+type Server struct {
+	config        *config.Config
+	logger        *logger.Logger
+	ginEngine     *gin.Engine
+	httpSrv       *http.Server
+	toolMgr       *tools.ToolManager
+	jobMgr        *jobs.Manager // 异步任务管理器
+	history       *history.Recorder
+	meter         *metering.Meter     // 用量计量，未启用时为空
+	events        EventStore          // 流式事件缓冲区
+	cluster       *cluster.Client     // 集群共享状态，单机模式时为空
+	leaderDone    chan struct{}       // 集群维护任务的竞选结束时关闭，单机模式时为空
+	sessions      *SessionStore       // 会话及其发布的资源
+	files         *fileCache          // 文件资源内容缓存
+	prompts       *promptLibrary      // 从目录加载的提示词库，未配置时为空
+	notifications *notificationHub    // 通过 GET /mcp 订阅的服务器通知
+	connPool      *ConnectionPool     // 连接池
+	activeOps     sync.WaitGroup      // 等待正在执行的操作
+	activeCount   int64               // 正在执行的操作数
+	shuttingDown  bool                // 关闭标志
+	draining      bool                // 排空标志，由管理接口设置
+	shutdownMu    sync.RWMutex        // 关闭状态锁
+	startedAt     time.Time           // 启动时间
+	adminSrv      *http.Server        // 独立的管理端监听，未配置时为空
+	grpcSrv       *grpc.Server        // gRPC 监听，未配置时为空
+	usage         *UsageTracker       // 端点调用统计
+	tenantUsage   *TenantUsageTracker // 按租户的工具调用统计
+	configMu      sync.RWMutex        // 工具配置锁，配置可在运行时重载
+
+	shutdownCtx   context.Context    // 开始关闭时取消，用于停止后台任务
+	beginShutdown context.CancelFunc // 触发关闭
+	drain         *drainSignal       // 当前排空的通知与终止信号，受 shutdownMu 保护
+	encryptionKey *envelope.KeyPair  // 参数解密密钥，未配置时不支持加密参数
+}
+
+// NewServer 创建新的 MCP 服务器
+func NewServer(cfg *config.Config, logger *logger.Logger) (*Server, error) {
+	return New(WithConfig(cfg), WithLogManager(logger))
+}
+
+// NewServerWithTools 创建只提供指定工具的 MCP 服务器，供嵌入工具框架的应用与测试使用
+//
+// 工具按所属分类注册，分类需在 cfg.ToolConfig 中启用；重新加载配置时同样只注册这些工具。
+func NewServerWithTools(cfg *config.Config, logger *logger.Logger, toolList ...tools.Tool) (*Server, error) {
+	return New(WithConfig(cfg), WithLogManager(logger), WithTools(toolList...))
+}
+
+// newServer 创建 MCP 服务器，toolList 为 nil 时注册所有内置工具
+func newServer(cfg *config.Config, logger *logger.Logger, toolList []tools.Tool, providers []tools.ResourceProvider) (*Server, error) {
+	// 初始化工具管理器
+	toolManager := tools.NewToolManager(logger, &cfg.ToolConfig)
+	toolManager.SetDefaultTimeout(cfg.ToolTimeout)
+	toolManager.SetStreamChunkSize(cfg.StreamChunkSize)
+	toolManager.SetStrictOutput(cfg.DevMode)
+	if toolList != nil {
+		toolManager.UseTools(toolList...)
+	}
+	if providers != nil {
+		toolManager.UseResourceProviders(providers...)
+	}
+
+	// 注册所有工具
+	toolManager.RegisterAllTools()
+
+	server := &Server{
+		config:        cfg,
+		logger:        logger,
+		toolMgr:       toolManager,
+		events:        NewEventBuffer(cfg.StreamBufferSize, cfg.StreamRetention),
+		sessions:      NewSessionStore(cfg.SessionSoftQuota, cfg.SessionHardQuota, cfg.SessionTTL, logger),
+		files:         newFileCache(),
+		notifications: newNotificationHub(),
+		usage:         NewUsageTracker(),
+		tenantUsage:   NewTenantUsageTracker(),
+		startedAt:     time.Now(),
+	}
+	toolManager.AddCallObserver(server.tenantUsage.Observe)
+
+	// 加载参数加密密钥，需在注册路由前完成
+	if cfg.EncryptKeyFile != "" {
+		key, err := envelope.LoadOrCreate(cfg.EncryptKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load encryption key: %v", err)
+		}
+		server.encryptionKey = key
+		toolManager.SetArgumentDecrypter(key)
+		logger.Info().Str("kid", key.KeyID()).Msg("Encrypted tool arguments enabled")
+	} else if cfg.RequireEncrypt {
+		return nil, fmt.Errorf("MCP_REQUIRE_ENCRYPTION requires MCP_ENCRYPTION_KEY_FILE")
+	}
+
+	// 集群模式：会话、事件流、租户限流与任务状态保存在共享存储中
+	if cfg.ClusterRedisURL != "" {
+		if err := server.joinCluster(); err != nil {
+			return nil, err
+		}
+	}
+
+	server.shutdownCtx, server.beginShutdown = context.WithCancel(context.Background())
+	server.drain = newDrainSignal()
+	server.startPromptLibrary()
+
+	server.setupGinServer()
+
+	if cfg.GRPCAddress != "" {
+		grpcSrv, err := server.newGRPCServer()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gRPC server: %v", err)
+		}
+		server.grpcSrv = grpcSrv
+	}
+
+	// 初始化连接池
+	maxConnections := 100 // 默认最大连接数
+	if cfg.MaxConnections > 0 {
+		maxConnections = cfg.MaxConnections
+	}
+	server.connPool = NewConnectionPool(maxConnections, logger)
+
+	// 清理异常退出时遗留的工作区
+	jobWorkspaceTTL := cfg.JobWorkspaceTTL
+	if jobWorkspaceTTL <= 0 {
+		jobWorkspaceTTL = defaultJobWorkspaceTTL
+	}
+	if removed, err := toolManager.SweepWorkspaces(jobWorkspaceTTL); err != nil {
+		logger.Warn().Err(err).Msg("Failed to sweep tool workspaces")
+	} else if removed > 0 {
+		logger.Info().Int("removed", removed).Msg("Removed stale tool workspaces")
+	}
+
+	// 初始化异步任务管理器
+	server.jobMgr = jobs.NewManager(jobs.Config{
+		Workers:   cfg.JobWorkers,
+		QueueSize: cfg.JobQueueSize,
+		Retention: cfg.JobRetention,
+		StoreDir:  cfg.JobStoreDir,
+		Shared:    server.sharedJobs(),
+		Instance:  server.instance(),
+		ErrorText: toolManager.PublicErrorText,
+	}, func(ctx context.Context, job jobs.Job) (interface{}, error) {
+		// 任务结果可能引用工作区中的文件，完成后保留一段时间再删除
+		if ws := toolManager.NewWorkspace(); ws != nil {
+			ctx = tools.WithWorkspace(ctx, ws)
+			defer ws.RemoveAfter(jobWorkspaceTTL)
+		}
+		if job.Approval != nil {
+			ctx = tools.WithApproved(ctx)
+		}
+		if job.Tenant != "" {
+			tenant, err := server.tenant(job.Tenant)
+			if err != nil {
+				return nil, err
+			}
+			ctx = tools.WithTenant(ctx, tenant)
+		}
+		return toolManager.CallTool(tools.WithClient(ctx, job.Client), job.Tool, job.Arguments)
+	}, logger)
+	if err := server.jobMgr.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start job manager: %v", err)
+	}
+
+	// 初始化工具调用历史存储
+	if cfg.HistoryEnabled {
+		store, err := history.Open(cfg.HistoryDriver, cfg.HistoryDSN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open history store: %v", err)
+		}
+		if cfg.HistorySignKey != "" {
+			signer, err := history.LoadSigner(cfg.HistorySignKey)
+			if err != nil {
+				store.Close()
+				return nil, fmt.Errorf("failed to load history signing key: %v", err)
+			}
+			if server.history, err = history.NewSignedRecorder(store, signer, logger); err != nil {
+				store.Close()
+				return nil, fmt.Errorf("failed to open history store: %v", err)
+			}
+		} else {
+			server.history = history.NewRecorder(store, logger)
+		}
+		toolManager.AddCallObserver(server.history.Observe)
+	}
+
+	// 初始化用量计量
+	if cfg.MeteringEnabled {
+		server.meter = metering.New(metering.Config{Dir: cfg.MeteringDir, FlushInterval: cfg.MeteringFlush}, logger)
+		if err := server.meter.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start usage metering: %v", err)
+		}
+		toolManager.AddCallObserver(server.meter.Observe)
+	}
+
+	server.startSessionReaper()
+	server.startLeaderTasks()
+	return server, nil
+}
+
+// NewConnectionPool 创建新的连接池
+func NewConnectionPool(maxSize int, logger *logger.Logger) *ConnectionPool {
+	return &ConnectionPool{
+		pool:    make(chan *MCPConnection, maxSize),
+		maxSize: maxSize,
+		logger:  logger,
+	}
+}
+
+// Acquire 获取连接
+func (cp *ConnectionPool) Acquire(clientInfo *ClientInfo) (*MCPConnection, error) {
+	select {
+	case conn := <-cp.pool:
+		// 从池中获取连接，更新为当前请求的客户端信息
+		conn.ClientInfo = clientInfo
+		conn.LastActive = time.Now()
+		cp.mu.Lock()
+		cp.active++
+		cp.mu.Unlock()
+		return conn, nil
+	default:
+		// 池为空，创建新连接
+		if cp.active >= cp.maxSize {
+			return nil, fmt.Errorf("connection pool exhausted, max size: %d", cp.maxSize)
+		}
+
+		conn := &MCPConnection{
+			ID:         generateConnectionID(),
+			ClientInfo: clientInfo,
+			CreatedAt:  time.Now(),
+			LastActive: time.Now(),
+			Session:    make(map[string]interface{}),
+		}
+
+		cp.mu.Lock()
+		cp.active++
+		cp.mu.Unlock()
+
+		cp.logger.Debug().
+			Str("connection_id", conn.ID).
+			Str("client", clientInfo.Name).
+			Msg("Created new connection")
+
+		return conn, nil
+	}
+}
+
+// Release 释放连接
+func (cp *ConnectionPool) Release(conn *MCPConnection) {
+	conn.LastActive = time.Now()
+
+	select {
+	case cp.pool <- conn:
+		// 成功放回池中
+		cp.mu.Lock()
+		cp.active--
+		cp.mu.Unlock()
+
+		cp.logger.Debug().
+			Str("connection_id", conn.ID).
+			Msg("Connection released to pool")
+	default:
+		// 池已满，关闭连接
+		cp.mu.Lock()
+		cp.active--
+		cp.mu.Unlock()
+
+		cp.logger.Debug().
+			Str("connection_id", conn.ID).
+			Msg("Connection closed (pool full)")
+	}
+}
+
+// Stats 获取连接池统计信息
+func (cp *ConnectionPool) Stats() map[string]interface{} {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	return map[string]interface{}{
+		"max_size":  cp.maxSize,
+		"active":    cp.active,
+		"pool_size": len(cp.pool),
+		"available": cap(cp.pool) - len(cp.pool),
+	}
+}
+
+// generateConnectionID 生成连接ID
+func generateConnectionID() string {
+	return fmt.Sprintf("conn_%d_%s", time.Now().UnixNano(), randomString(8))
+}
+
+// newRandomID 生成带前缀的随机ID，用于会话、事件流等持有者凭 ID 访问的对象，
+// 随机部分取自 crypto/rand，无法从时间或其他ID推测
+func newRandomID(prefix string) string {
+	buf := make([]byte, 16)
+	// crypto/rand.Read 不会返回错误，熵源不可用时进程直接终止
+	_, _ = rand.Read(buf)
+	return prefix + "_" + hex.EncodeToString(buf)
+}
+
+// randomString 生成由字母与数字组成的随机字符串，随机性取自 crypto/rand
+func randomString(length int) string {
+	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	b := make([]byte, length)
+	_, _ = rand.Read(b)
+	for i := range b {
+		// 256 不是 62 的整数倍，取模带来的偏差对非凭据用途的ID可以忽略
+		b[i] = charset[int(b[i])%len(charset)]
+	}
+	return string(b)
+}
+
+// Handler 返回主端口的 HTTP 处理器，供测试与基准测试在进程内提供服务
+func (s *Server) Handler() http.Handler {
+	return s.ginEngine
+}
+
+// GRPCServer 返回 gRPC 服务器，未配置 gRPC 地址时为空；供测试在进程内的监听上提供服务
+func (s *Server) GRPCServer() *grpc.Server {
+	return s.grpcSrv
+}
+
+// ToolManager 返回服务器的工具管理器，供嵌入应用注册调用观察者或启停工具
+func (s *Server) ToolManager() *tools.ToolManager {
+	return s.toolMgr
+}
+
+// Start 启动 MCP 服务器
+func (s *Server) Start(ctx context.Context) error {
+	s.logger.Info().
+		Str("address", s.config.ServerAddress).
+		Bool("tls", s.tlsEnabled()).
+		Bool("h2c", s.config.H2CEnabled).
+		Msg("Starting MCP server")
+
+	errChan := make(chan error, 3)
+
+	s.startProfiling()
+
+	go func() {
+		if err := s.listenAndServe(); err != nil && err != http.ErrServerClosed {
+			errChan <- err
+		}
+	}()
+
+	if s.adminSrv != nil {
+		s.logger.Info().Str("address", s.config.AdminAddress).Msg("Starting admin server")
+		go func() {
+			if err := s.serveAdmin(); err != nil && err != http.ErrServerClosed {
+				errChan <- fmt.Errorf("admin server: %v", err)
+			}
+		}()
+	}
+
+	if s.grpcSrv != nil {
+		s.logger.Info().Str("address", s.config.GRPCAddress).Msg("Starting gRPC server")
+		go func() {
+			if err := s.serveGRPC(); err != nil && err != grpc.ErrServerStopped {
+				errChan <- fmt.Errorf("gRPC server: %v", err)
+			}
+		}()
+	}
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return s.Stop()
+	}
+}
+
+// Stop 停止 MCP 服务器
+func (s *Server) Stop() error {
+	s.logger.Info().Msg("Stopping MCP server")
+
+	// 设置关闭标志，拒绝新请求
+	s.shutdownMu.Lock()
+	s.shuttingDown = true
+	s.shutdownMu.Unlock()
+
+	// 通知活跃的流式请求服务器即将关闭，管理接口已开始排空时沿用其排空窗口
+	s.beginShutdown()
+	signal := s.startDrain(false)
+
+	drain := time.Until(signal.deadline)
+	s.logger.Info().Dur("drain_timeout", drain).Msg("Waiting for active operations to complete...")
+
+	// 在排空窗口内等待正在执行的操作完成，超时后终止剩余的流并等待其发送终止事件
+	if s.waitActiveOps(drain) {
+		s.logger.Info().Msg("All active operations completed")
+	} else {
+		s.logger.Warn().Msg("Drain window elapsed, terminating remaining streams")
+		signal.end()
+		if !s.waitActiveOps(shutdownGracePeriod) {
+			s.logger.Warn().Msg("Timeout waiting for active operations, forcing shutdown")
+		}
+	}
+	signal.end()
+
+	// 等待异步任务完成
+	jobCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.jobMgr.Shutdown(jobCtx); err != nil {
+		s.logger.Warn().Err(err).Msg("Async jobs did not finish before shutdown")
+	}
+
+	if err := s.toolMgr.Close(); err != nil {
+		s.logger.Warn().Err(err).Msg("Failed to release tool resources")
+	}
+
+	if s.history != nil {
+		if err := s.history.Close(); err != nil {
+			s.logger.Warn().Err(err).Msg("Failed to close history store")
+		}
+	}
+
+	if s.meter != nil {
+		s.meter.Close()
+	}
+
+	// 关闭 HTTP 服务器，超时后强制断开剩余连接
+	defer s.closeCluster()
+	ctx, cancelShutdown := context.WithTimeout(context.Background(), shutdownGracePeriod)
+	defer cancelShutdown()
+	if s.adminSrv != nil {
+		if err := s.adminSrv.Shutdown(ctx); err != nil {
+			s.adminSrv.Close()
+		}
+	}
+	if s.grpcSrv != nil {
+		s.stopGRPC(ctx)
+	}
+	if err := s.httpSrv.Shutdown(ctx); err != nil {
+		s.logger.Warn().Err(err).Msg("HTTP server shutdown timed out, closing remaining connections")
+		return s.httpSrv.Close()
+	}
+	return nil
+}
+
+// isShuttingDown 检查服务器是否正在关闭或排空，此时拒绝新请求
+func (s *Server) isShuttingDown() bool {
+	s.shutdownMu.RLock()
+	defer s.shutdownMu.RUnlock()
+	return s.shuttingDown || s.draining
+}
+
+func (s *Server) handleMCPRequest(c *gin.Context) {
+	// 检查服务器是否正在关闭
+	if s.isShuttingDown() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Service unavailable - server is shutting down",
+		})
+		return
+	}
+
+	// 增加活跃操作计数
+	s.beginOp()
+	defer s.endOp()
+
+	var req map[string]interface{}
+	defer s.recoverRPC(c, &req)
+
+	// 绑定 JSON 请求体
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.sendGinErrorResponse(c, "Invalid JSON", ErrorCodeParseError)
+		return
+	}
+
+	method, err := validateEnvelope(req)
+	if err != nil {
+		s.sendRPCErrorResponse(c, responseID(req), "Invalid Request: "+err.Error(), ErrorCodeInvalidRequest)
+		return
+	}
+	if method != MethodInitialize {
+		if err := checkProtocolVersionHeader(c); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"jsonrpc": "2.0",
+				"error": gin.H{
+					"code":    ErrorCodeInvalidRequest,
+					"message": err.Error(),
+				},
+				"id": responseID(req),
+			})
+			return
+		}
+	}
+	if isNotification(req) {
+		s.handleNotification(c, method)
+		return
+	}
+
+	// 客户端通过 Accept 声明接受 SSE 时，tools/call 以流式响应返回
+	if method == MethodToolsCall && acceptsEventStream(c) {
+		setSSEHeaders(c)
+		s.streamToolsCall(c, req)
+		return
+	}
+
+	// 获取客户端信息并创建连接
+	clientInfo := extractClientInfo(req)
+	if name := c.GetHeader(ClientNameHeader); name != "" && clientInfo.Name == "unknown" {
+		clientInfo.Name = name
+	}
+	annotateAccessLog(c, req, clientInfo)
+	conn, err := s.connPool.Acquire(clientInfo)
+	if err != nil {
+		s.sendRPCErrorResponse(c, req["id"], fmt.Sprintf("Connection limit exceeded: %v", err), -32000)
+		return
+	}
+	defer s.connPool.Release(conn)
+
+	// 在处理请求前更新连接活跃时间
+	conn.LastActive = time.Now()
+
+	session, err := s.resolveSession(c, method, conn.ClientInfo)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"jsonrpc": "2.0",
+			"error": gin.H{
+				"code":    -32001,
+				"message": err.Error(),
+			},
+			"id": req["id"],
+		})
+		return
+	}
+	// 处理请求期间会话不会因空闲过期
+	defer session.begin()()
+
+	// 处理 MCP 请求
+	ctx := s.sessionContext(tools.WithClient(c.Request.Context(), conn.ClientInfo.Name), session)
+	ctx = tools.WithLocale(ctx, requestLocale(c, req, conn.ClientInfo, session))
+	result, err := s.handleMCPOperation(ctx, method, req, conn)
+	if rpcErr, ok := toolCallError(err); ok {
+		c.JSON(http.StatusOK, gin.H{
+			"jsonrpc": "2.0",
+			"error":   rpcErr,
+			"id":      req["id"],
+		})
+		return
+	}
+	if err != nil {
+		s.sendRPCErrorResponse(c, req["id"], err.Error(), rpcErrorCode(err))
+		return
+	}
+
+	// 资源内容未变化时返回 304，客户端沿用已缓存的结果
+	if contents, ok := result.(*resourceContents); ok && contents.etag != "" {
+		c.Header("ETag", contents.etag)
+		c.Header("Cache-Control", "no-cache")
+		if etagMatches(c.GetHeader("If-None-Match"), contents.etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	s.writeRPCResult(c, req["id"], result)
+}
+
+func (s *Server) handleMCPOperation(ctx context.Context, method string, req map[string]interface{}, conn *MCPConnection) (interface{}, error) {
+	switch method {
+	case MethodInitialize:
+		return s.handleInitialize(req)
+	case "notifications/initialized":
+		return s.handleInitializedNotification(req)
+	case MethodPing:
+		return map[string]interface{}{}, nil
+	case MethodToolsList:
+		return s.handleToolsList(ctx, req, conn)
+	case MethodToolsCall:
+		return s.handleToolsCall(ctx, req, conn)
+	case MethodResourcesList:
+		return s.handleResourcesList(ctx, req)
+	case MethodResourcesRead:
+		return s.handleResourcesRead(ctx, req, conn)
+	case MethodResourcesTemplatesList:
+		return s.handleResourceTemplatesList(ctx, req)
+	case MethodPromptsList:
+		return s.handlePromptsList(ctx, req)
+	case MethodPromptsGet:
+		return s.handlePromptsGet(ctx, req, conn)
+	case MethodRootsList:
+		return s.handleRootsList(ctx)
+	case MethodJobsGet:
+		return s.handleJobsGet(ctx, req)
+	case MethodJobsList:
+		return s.handleJobsList(ctx)
+	case MethodJobsCancel:
+		return s.handleJobsCancel(ctx, req)
+	default:
+		return nil, fmt.Errorf("%w: %s", errMethodNotFound, method)
+	}
+}
+
+func (s *Server) handleInitialize(req map[string]interface{}) (interface{}, error) {
+	requested := ""
+	if params, ok := req["params"].(map[string]interface{}); ok {
+		requested, _ = params["protocolVersion"].(string)
+	}
+	response := map[string]interface{}{
+		"protocolVersion": negotiateProtocolVersion(requested),
+		"serverInfo": map[string]interface{}{
+			"name":    "Weave-Toolkit",
+			"version": "1.0.0",
+		},
+		"capabilities": map[string]interface{}{
+			"roots": map[string]interface{}{
+				"listChanged": false,
+			},
+			"resources": map[string]interface{}{
+				"listChanged": false,
+			},
+			"tools": map[string]interface{}{
+				"listChanged": false,
+			},
+			"prompts": map[string]interface{}{
+				// 从目录加载提示词时，目录变化通过通知流发送 notifications/prompts/list_changed
+				"listChanged": s.prompts != nil,
+			},
+		},
+	}
+	if s.encryptionKey != nil {
+		capabilities := response["capabilities"].(map[string]interface{})
+		capabilities["experimental"] = map[string]interface{}{
+			"encryptedArguments": s.encryptionCapability(),
+		}
+	}
+
+	return response, nil
+}
+
+func (s *Server) handleInitializedNotification(req map[string]interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (s *Server) handleToolsList(ctx context.Context, req map[string]interface{}, conn *MCPConnection) (interface{}, error) {
+	filter, err := toolListFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	toolInfos, nextCursor, err := listPage(req, filter.Filter(s.tenantTools(ctx)), s.config.ListPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// MCP 协议格式
+	tools := []map[string]interface{}{}
+	for _, tool := range toolInfos {
+		var inputSchema interface{} = map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		}
+		if tool.InputSchema != nil {
+			inputSchema = tool.InputSchema
+		}
+		item := map[string]interface{}{
+			"name":        s.toolMgr.ExposedName(conn.ClientInfo.Name, tool.Name),
+			"description": tool.Description,
+			"inputSchema": inputSchema,
+		}
+		if tool.OutputSchema != nil {
+			item["outputSchema"] = tool.OutputSchema
+		}
+		if len(tool.Tags) > 0 {
+			item["_meta"] = map[string]interface{}{"tags": tool.Tags}
+		}
+		tools = append(tools, item)
+	}
+
+	return pagedList("tools", tools, nextCursor), nil
+}
+
+// toolListFilter 解析 tools/list 的 params.filter，未指定时不筛选
+func toolListFilter(req map[string]interface{}) (tools.ToolFilter, error) {
+	var filter tools.ToolFilter
+	params, _ := req["params"].(map[string]interface{})
+	raw, ok := params["filter"]
+	if !ok || raw == nil {
+		return filter, nil
+	}
+	data, err := json.Marshal(raw)
+	if err == nil {
+		err = json.Unmarshal(data, &filter)
+	}
+	if err != nil {
+		return filter, fmt.Errorf("%w: invalid filter: %v", errInvalidParams, err)
+	}
+	return filter, nil
+}
+
+func (s *Server) handleToolsCall(ctx context.Context, req map[string]interface{}, conn *MCPConnection) (interface{}, error) {
+	params, ok := req["params"].(map[string]interface{})
+	if !ok {
+		return nil, errInvalidParams
+	}
+
+	toolName, ok := params["name"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing or invalid tool name", errInvalidParams)
+	}
+	toolName = s.resolveToolName(conn, toolName)
+
+	arguments, err := s.toolCallArguments(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 需要审批的调用以任务形式等待批准，客户端通过 jobs/get 查询结果，因此请求需属于会话或租户
+	if s.toolMgr.RequiresApproval(ctx, toolName) {
+		if !jobOwned(ctx) {
+			return nil, errJobOwnerRequired
+		}
+		job, err := s.submitApproval(ctx, toolName, arguments, conn)
+		if err != nil {
+			return nil, err
+		}
+		return approvalResult(job), nil
+	}
+
+	// 异步模式：立即返回任务ID
+	if async, _ := params["async"].(bool); async {
+		return s.submitJob(ctx, toolName, arguments, conn)
+	}
+
+	ctx, cancel, err := s.withTimeoutHint(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	result, err := s.toolMgr.CallTool(ctx, toolName, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// handleResourcesList 处理资源列表请求
+func (s *Server) handleResourcesList(ctx context.Context, req map[string]interface{}) (interface{}, error) {
+	resources := s.metaResources()
+	toolResources := s.toolMgr.Resources()
+	if tools.TenantFromContext(ctx) == nil {
+		for _, provider := range s.toolMgr.ResourceProviders() {
+			toolResources = append(toolResources, provider.Resources()...)
+		}
+	}
+	for _, res := range toolResources {
+		resources = append(resources, ResourceInfo{
+			URI:         res.URI,
+			Name:        res.Name,
+			MimeType:    res.MimeType,
+			Description: res.Description,
+		})
+	}
+	if session := sessionFromContext(ctx); session != nil {
+		for _, res := range session.Resources() {
+			resources = append(resources, ResourceInfo{
+				URI:         res.URI,
+				Name:        res.Name,
+				MimeType:    res.MimeType,
+				Description: "Resource published by a tool in this session",
+			})
+		}
+	}
+	if store := s.toolMgr.Blobs(); store != nil {
+		for _, blob := range store.List(tools.BlobOwner(ctx)) {
+			resources = append(resources, ResourceInfo{
+				URI:         blob.URI,
+				Name:        blob.Name,
+				MimeType:    blob.MimeType,
+				Description: "Uploaded file",
+			})
+		}
+	}
+	if s.history != nil {
+		resources = append(resources, ResourceInfo{
+			URI:         HistoryURIRecent,
+			Name:        "history/recent",
+			MimeType:    "application/json",
+			Description: "Most recent tool invocations and results",
+		})
+	}
+
+	resources, nextCursor, err := listPage(req, resources, s.config.ListPageSize)
+	if err != nil {
+		return nil, err
+	}
+	return pagedList("resources", resources, nextCursor), nil
+}
+
+// handleResourceTemplatesList 处理资源模板列表请求：服务器内置的参数化资源与工具公开的资源模板
+func (s *Server) handleResourceTemplatesList(ctx context.Context, req map[string]interface{}) (interface{}, error) {
+	templates := []ResourceTemplateInfo{
+		{URITemplate: MetaURITools + "/{name}", Name: "tools", MimeType: "application/json", Description: "Metadata of a registered tool"},
+		{URITemplate: tools.ResultURIPrefix + "{id}{?offset}", Name: "result", MimeType: "text/plain", Description: "Remainder of a truncated tool result, starting at byte offset"},
+	}
+	if sessionFromContext(ctx) != nil {
+		templates = append(templates, ResourceTemplateInfo{URITemplate: SessionURIPrefix + "{name}", Name: "session", Description: "Resource published by a tool in this session"})
+	}
+	if len(s.resourceRoots(ctx)) > 0 {
+		templates = append(templates, ResourceTemplateInfo{URITemplate: "file://{+path}", Name: "file", Description: "File inside one of the roots listed by roots/list"})
+	}
+	if s.toolMgr.Blobs() != nil {
+		templates = append(templates, ResourceTemplateInfo{URITemplate: tools.BlobURIPrefix + "{id}", Name: "blob", Description: "File uploaded through " + BlobsPath})
+	}
+	toolTemplates := s.toolMgr.ResourceTemplates()
+	if tools.TenantFromContext(ctx) == nil {
+		for _, provider := range s.toolMgr.ResourceProviders() {
+			toolTemplates = append(toolTemplates, provider.ResourceTemplates()...)
+		}
+	}
+	for _, template := range toolTemplates {
+		templates = append(templates, ResourceTemplateInfo{
+			URITemplate: template.URITemplate,
+			Name:        template.Name,
+			MimeType:    template.MimeType,
+			Description: template.Description,
+		})
+	}
+
+	templates, nextCursor, err := listPage(req, templates, s.config.ListPageSize)
+	if err != nil {
+		return nil, err
+	}
+	return pagedList("resourceTemplates", templates, nextCursor), nil
+}
+
+// handleResourcesRead 处理资源读取请求
+func (s *Server) handleResourcesRead(ctx context.Context, req map[string]interface{}, conn *MCPConnection) (interface{}, error) {
+	params, ok := req["params"].(map[string]interface{})
+	if !ok {
+		return nil, errInvalidParams
+	}
+
+	uri, ok := params["uri"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing or invalid uri", errInvalidParams)
+	}
+	return s.readResourceContents(ctx, uri)
+}
+
+// readResourceContents 按 URI 读取资源内容，供 resources/read 与提示词渲染使用
+func (s *Server) readResourceContents(ctx context.Context, uri string) (*resourceContents, error) {
+	// 会话中工具发布的资源
+	if strings.HasPrefix(uri, SessionURIPrefix) {
+		return s.readSessionResource(ctx, uri)
+	}
+
+	// 根目录内的文件
+	if strings.HasPrefix(uri, "file:") {
+		return s.readFileResource(ctx, uri)
+	}
+
+	// 客户端上传的文件
+	if tools.IsBlobURI(uri) {
+		return s.readBlobResource(ctx, uri)
+	}
+
+	// 按 scheme 注册的资源提供者（https://、db://、env:// 等），租户请求不可用
+	if tools.TenantFromContext(ctx) == nil {
+		if data, mimeType, ok, err := s.toolMgr.ReadProviderResource(ctx, uri); ok {
+			if err != nil {
+				return nil, err
+			}
+			content, binary := encodeResourceData(data)
+			return newResourceContents(uri, mimeType, content, binary), nil
+		}
+	}
+
+	// 可以支持数据库、HTTP资源等
+	content, mimeType, err := s.readResource(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource: %v", err)
+	}
+
+	return newResourceContents(uri, mimeType, content, false), nil
+}
+
+// handlePromptsList 处理提示词列表请求，租户请求返回租户的提示词库
+func (s *Server) handlePromptsList(ctx context.Context, req map[string]interface{}) (interface{}, error) {
+	// 非租户请求返回 prompts.dir 中的提示词，未配置时为空
+	prompts := []PromptInfo{}
+	if tenant := tools.TenantFromContext(ctx); tenant != nil {
+		prompts = tenantPrompts(tenant)
+	} else if s.prompts != nil {
+		prompts = s.prompts.list()
+	}
+
+	prompts, nextCursor, err := listPage(req, prompts, s.config.ListPageSize)
+	if err != nil {
+		return nil, err
+	}
+	return pagedList("prompts", prompts, nextCursor), nil
+}
+
+// handlePromptsGet 处理提示词获取请求
+func (s *Server) handlePromptsGet(ctx context.Context, req map[string]interface{}, conn *MCPConnection) (interface{}, error) {
+	params, ok := req["params"].(map[string]interface{})
+	if !ok {
+		return nil, errInvalidParams
+	}
+
+	name, ok := params["name"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing or invalid prompt name", errInvalidParams)
+	}
+
+	// 租户只能获取自己提示词库中的提示词
+	arguments, _ := params["arguments"].(map[string]interface{})
+	return s.renderPrompt(ctx, name, arguments)
+}
+
+// handleRootsList 处理根目录列表请求，租户请求返回租户配置的根目录，否则返回 resources.roots
+func (s *Server) handleRootsList(ctx context.Context) (interface{}, error) {
+	roots, err := listRoots(s.resourceRoots(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"roots": roots,
+	}, nil
+}
+
+// readResource 读取资源内容，返回内容与 MIME 类型
+func (s *Server) readResource(ctx context.Context, uri string) (string, string, error) {
+	// 服务器运行时元信息
+	if strings.HasPrefix(uri, MetaURIPrefix) {
+		content, err := s.readMetaResource(uri)
+		return content, "application/json", err
+	}
+
+	// 工具调用历史
+	if uri == HistoryURIRecent && s.history != nil {
+		content, err := s.readRecentHistory()
+		return content, "application/json", err
+	}
+
+	// 工具公开的资源
+	if content, mimeType, ok, err := s.toolMgr.ReadResource(uri); ok {
+		return content, mimeType, err
+	}
+
+	// 与工具公开的资源模板匹配的资源
+	if content, mimeType, ok, err := s.toolMgr.ReadTemplateResource(ctx, uri); ok {
+		return content, mimeType, err
+	}
+
+	// 可以扩展支持 HTTP 资源等
+	return "", "", werrors.NotFound("resource not found: %s", uri)
+}
+
+// resolveToolName 按客户端别名配置解析规范工具名
+func (s *Server) resolveToolName(conn *MCPConnection, name string) string {
+	canonical := s.toolMgr.ResolveAlias(conn.ClientInfo.Name, name)
+	if canonical != name {
+		s.logger.Debug().
+			Str("client", conn.ClientInfo.Name).
+			Str("alias", name).
+			Str("tool", canonical).
+			Msg("Tool alias resolved")
+	}
+	return canonical
+}
+
+// extractClientInfo 从请求中提取客户端信息
+func extractClientInfo(req map[string]interface{}) *ClientInfo {
+	clientInfo := &ClientInfo{
+		Name:    "unknown",
+		Version: "1.0.0",
+	}
+
+	// 尝试从初始化请求中获取客户端信息
+	if params, ok := req["params"].(map[string]interface{}); ok {
+		if client, ok := params["clientInfo"].(map[string]interface{}); ok {
+			if name, ok := client["name"].(string); ok {
+				clientInfo.Name = name
+			}
+			if version, ok := client["version"].(string); ok {
+				clientInfo.Version = version
+			}
+			if locale, ok := client["locale"].(string); ok {
+				clientInfo.Locale = locale
+			}
+		}
+		if capabilities, ok := params["capabilities"].(map[string]interface{}); ok {
+			clientInfo.Capabilities = tools.ClientCapabilities(capabilities)
+		}
+	}
+
+	return clientInfo
+}
+
+// annotateAccessLog 将解码后的 JSON-RPC 方法、工具名与客户端写入访问日志
+func annotateAccessLog(c *gin.Context, req map[string]interface{}, clientInfo *ClientInfo) {
+	method, _ := req["method"].(string)
+	tool := ""
+	if method == MethodToolsCall {
+		if params, ok := req["params"].(map[string]interface{}); ok {
+			tool, _ = params["name"].(string)
+		}
+	}
+	middleware.SetMCPRequestInfo(c, method, tool, clientInfo.Name)
+}
+
+// setupGinServer 设置 Gin 服务器
+func (s *Server) setupGinServer() {
+	// 模式
+	if s.config.LogLevel == "debug" {
+		gin.SetMode(gin.DebugMode)
+	} else {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	s.ginEngine = gin.New()
+
+	// 全局中间件：日志在最外层以记录恢复后的状态码，CORS 预检在鉴权之前返回
+	s.ginEngine.Use(
+		middleware.AccessLogMiddleware(s.logger, s.config.AccessLogFormat), // 访问日志
+		middleware.RecoveryMiddleware(s.logger),                            // 崩溃恢复
+		middleware.RequestIDMiddleware(),                                   // 请求 ID 追踪
+		requestContextMiddleware(),                                         // 请求 ID 传递给工具
+		middleware.CORSMiddleware(s.config.CORSOrigin),                     // 跨域
+		s.usage.Middleware(),                                               // 端点调用统计
+	)
+
+	// 响应压缩
+	if encodings := s.compressionEncodings(); len(encodings) > 0 {
+		s.ginEngine.Use(middleware.CompressionMiddleware(encodings, s.config.CompressMinSize))
+	}
+
+	// MCP 协议端点：配置 MCP_API_KEY 时需要鉴权，租户 API Key 同样可以访问
+	mcpGroup := s.ginEngine.Group("/mcp", s.tenantMiddleware(), s.requestTimeoutMiddleware())
+	{
+		mcpGroup.POST("", s.handleMCPRequest)
+		mcpGroup.GET("", s.handleNotificationStream)
+		mcpGroup.DELETE("", s.handleSessionDelete)
+		mcpGroup.POST("/stream", s.handleLegacyStream)
+		mcpGroup.GET("/jobs/:id/events", s.handleJobEvents)
+		mcpGroup.POST("/events", s.handleLongPollStart)
+		mcpGroup.GET("/events", s.handleLongPoll)
+		mcpGroup.GET("/manifest/openapi.json", s.handleManifestOpenAPI)
+		mcpGroup.GET("/manifest/openai.json", s.handleManifestOpenAI)
+		mcpGroup.GET("/manifest/agents.json", s.handleManifestAgents)
+		mcpGroup.GET("/usage", s.handleTenantUsage)
+		mcpGroup.POST("/blobs", s.handleBlobUpload)
+		mcpGroup.GET("/blobs", s.handleBlobsList)
+		mcpGroup.PATCH("/blobs/:id", s.handleBlobAppend)
+		mcpGroup.DELETE("/blobs/:id", s.handleBlobDelete)
+	}
+
+	// REST 桥接：与 /mcp 使用相同的鉴权
+	if s.config.RESTEnabled {
+		restGroup := s.ginEngine.Group(RESTToolsPath, s.tenantMiddleware(), s.requestTimeoutMiddleware())
+		restGroup.GET("", s.handleRESTTools)
+		restGroup.POST("/:name", s.handleRESTToolCall)
+	}
+
+	// OpenAI 兼容的对话补全：与 /mcp 使用相同的鉴权
+	if s.config.ChatEnabled {
+		s.ginEngine.POST(ChatCompletionsPath, s.tenantMiddleware(), s.requestTimeoutMiddleware(), s.handleChatCompletions)
+	}
+
+	// 参数加密公钥
+	if s.encryptionKey != nil {
+		s.ginEngine.GET(JWKSPath, s.handleJWKS)
+	}
+
+	// Webhook 触发端点
+	s.ginEngine.POST("/webhooks/:name", s.handleWebhook)
+
+	// 管理端点：配置独立监听地址时仅在管理端口提供
+	if s.config.AdminAddress == "" {
+		s.registerAdminRoutes(s.ginEngine.Group("/admin", middleware.APIKeyMiddleware(s.config.APIKey)))
+	} else {
+		s.setupAdminServer()
+	}
+
+	// 健康检查端点
+	healthGroup := s.ginEngine.Group("/health")
+	{
+		healthGroup.GET("", s.handleHealthCheck)
+		healthGroup.GET("/stats", s.handleStats)
+		healthGroup.GET("/pressure", s.handlePressure)
+		healthGroup.GET("/ready", s.handleReadiness)
+	}
+
+	// Prometheus 指标端点
+	if s.toolConfig().Global.EnableMetrics {
+		s.ginEngine.GET(MetricsPath, s.handleMetrics)
+	}
+
+	// 设置 HTTP 服务器
+	s.httpSrv = &http.Server{
+		Addr:         s.config.ServerAddress,
+		Handler:      s.ginEngine,
+		ReadTimeout:  s.config.ReadTimeout,
+		WriteTimeout: s.config.WriteTimeout,
+		IdleTimeout:  s.config.IdleTimeout,
+	}
+	s.configureHTTP2(s.httpSrv)
+}
+
+// handleStats 统计信息端点
+func (s *Server) handleStats(c *gin.Context) {
+	stats := s.connPool.Stats()
+
+	c.JSON(http.StatusOK, gin.H{
+		"server_info": gin.H{
+			"name":    "Weave-Toolkit",
+			"version": "1.0.0",
+		},
+		"connections":      stats,
+		"executions":       s.toolMgr.PoolStats(),
+		"jobs":             s.jobMgr.Stats(),
+		"sessions":         s.sessions.Stats(),
+		"usage":            s.usage.Stats(),
+		"circuit_breakers": s.toolMgr.CircuitBreakers().Stats(),
+		"results":          s.toolMgr.ResultStats(),
+		"resource_cache":   s.files.Stats(),
+		"blobs":            s.blobStats(),
+		"notifications":    s.notifications.Stats(),
+		"tool_calls":       s.toolMgr.CallStats(),
+		"timestamp":        time.Now().Format(time.RFC3339),
+	})
+}
+
+// handleMCPStreamRequest 处理流式 MCP 请求
+func (s *Server) handleMCPStreamRequest(c *gin.Context) {
+	// 检查服务器是否正在关闭
+	if s.isShuttingDown() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Service unavailable - server is shutting down",
+		})
+		return
+	}
+
+	// 增加活跃操作计数
+	s.beginOp()
+	defer s.endOp()
+
+	// 设置 SSE 响应头
+	setSSEHeaders(c)
+
+	// 绑定 JSON 请求体
+	var req map[string]interface{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.sendStreamError(c.Writer, "Invalid JSON")
+		return
+	}
+
+	method, ok := req["method"].(string)
+	if !ok {
+		s.sendStreamError(c.Writer, "Missing or invalid method")
+		return
+	}
+
+	if method != MethodToolsCall {
+		s.sendStreamError(c.Writer, "Only tools/call method is supported for streaming")
+		return
+	}
+
+	s.streamToolsCall(c, req)
+}
+
+// streamToolsCall 以 SSE 响应执行流式工具调用，调用方已设置 SSE 响应头并完成请求校验
+func (s *Server) streamToolsCall(c *gin.Context, req map[string]interface{}) {
+	method, _ := req["method"].(string)
+
+	// 获取客户端信息并创建连接
+	clientInfo := extractClientInfo(req)
+	if name := c.GetHeader(ClientNameHeader); name != "" && clientInfo.Name == "unknown" {
+		clientInfo.Name = name
+	}
+	annotateAccessLog(c, req, clientInfo)
+	conn, err := s.connPool.Acquire(clientInfo)
+	if err != nil {
+		s.sendStreamError(c.Writer, fmt.Sprintf("Connection limit exceeded: %v", err))
+		return
+	}
+	defer s.connPool.Release(conn)
+
+	// 在处理请求前更新连接活跃时间
+	conn.LastActive = time.Now()
+
+	session, err := s.resolveSession(c, method, conn.ClientInfo)
+	if err != nil {
+		s.sendStreamError(c.Writer, err.Error())
+		return
+	}
+	defer session.begin()()
+
+	// 事件同时写入缓冲区，客户端断线后可通过 /mcp/events 续传
+	streamID := s.events.Open(streamOwner(c.Request.Context(), session))
+	defer s.events.Close(streamID)
+	session.trackStream(streamID)
+	c.Writer.Header().Set(StreamIDHeader, streamID)
+
+	emitter := &lockedEmitter{emit: func(event string, data interface{}) {
+		evt, err := s.events.Append(streamID, event, data)
+		if err != nil {
+			s.logger.Error().Err(err).Str("stream_id", streamID).Msg("Failed to buffer stream event")
+			return
+		}
+		s.writeBufferedEvent(c.Writer, evt)
+	}}
+	defer emitter.Close()
+
+	// 服务器关闭时推送通知，排空窗口结束后取消工具执行
+	stopWatch := s.watchShutdown(emitter)
+	defer stopWatch()
+	ctx, cancel := s.streamContext(c.Request.Context())
+	defer cancel()
+
+	// 处理流式工具调用
+	ctx = s.sessionContext(tools.WithClient(ctx, conn.ClientInfo.Name), session)
+	ctx = tools.WithLocale(ctx, requestLocale(c, req, conn.ClientInfo, session))
+	s.runStream(emitter, func(emit streamEmitter) {
+		s.handleStreamToolsCall(ctx, emit, req, conn)
+	})
+}
+
+// streamEmitter 流式事件输出函数
+type streamEmitter func(event string, data interface{})
+
+// handleStreamToolsCall 处理流式工具调用
+func (s *Server) handleStreamToolsCall(ctx context.Context, emit streamEmitter, req map[string]interface{}, conn *MCPConnection) {
+	params, ok := req["params"].(map[string]interface{})
+	if !ok {
+		emit(StreamEventError, map[string]interface{}{"message": "Invalid params"})
+		return
+	}
+
+	toolName, ok := params["name"].(string)
+	if !ok {
+		emit(StreamEventError, map[string]interface{}{"message": "Missing or invalid tool name"})
+		return
+	}
+	toolName = s.resolveToolName(conn, toolName)
+
+	arguments, err := s.toolCallArguments(params)
+	if err != nil {
+		emit(StreamEventError, map[string]interface{}{"message": err.Error()})
+		return
+	}
+
+	if s.toolMgr.RequiresApproval(ctx, toolName) {
+		emit(StreamEventToolCall, map[string]interface{}{
+			"tool":   toolName,
+			"status": string(jobs.StatusAwaitingApproval),
+		})
+		s.streamApproval(ctx, emit, params, toolName, arguments, conn)
+		return
+	}
+
+	ctx, cancel, err := s.withTimeoutHint(ctx, params)
+	if err != nil {
+		emit(StreamEventError, map[string]interface{}{"message": err.Error()})
+		return
+	}
+	defer cancel()
+
+	// 发送开始事件
+	emit(StreamEventToolCall, map[string]interface{}{
+		"tool":   toolName,
+		"status": "started",
+	})
+
+	// 调用工具并推送流式事件，成功时以 done 事件结束
+	_, err = s.toolMgr.CallToolEvents(ctx, toolName, arguments, streamEvents(emit, params))
+	if err != nil {
+		// 发送错误事件，熔断与超时附带错误码
+		if rpcErr, ok := toolCallError(err); ok {
+			emit(StreamEventError, rpcErr)
+			return
+		}
+		emit(StreamEventError, map[string]interface{}{
+			"message": err.Error(),
+		})
+	}
+}
+
+// writeBufferedEvent 以 SSE 格式写出带 ID 的缓冲事件
+func (s *Server) writeBufferedEvent(w http.ResponseWriter, evt BufferedEvent) {
+	eventStr := fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", evt.ID, evt.Event, string(evt.Data))
+
+	if _, err := w.Write([]byte(eventStr)); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to write stream event")
+		return
+	}
+
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// sendStreamEvent 发送流式事件
+func (s *Server) sendStreamEvent(w http.ResponseWriter, event string, data interface{}) {
+	eventData, err := json.Marshal(data)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to marshal stream event data")
+		return
+	}
+
+	eventStr := fmt.Sprintf("event: %s\ndata: %s\n\n", event, string(eventData))
+
+	if _, err := w.Write([]byte(eventStr)); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to write stream event")
+		return
+	}
+
+	// 刷新缓冲区
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// sendStreamError 发送流式错误
+func (s *Server) sendStreamError(w http.ResponseWriter, message string) {
+	s.sendStreamEvent(w, StreamEventError, map[string]interface{}{
+		"message": message,
+	})
+}
+
+// sendGinErrorResponse 错误响应
+func (s *Server) sendGinErrorResponse(c *gin.Context, message string, code int) {
+	s.sendRPCErrorResponse(c, nil, message, code)
+}
+
+func (s *Server) sendErrorResponse(w http.ResponseWriter, message string, code int) {
+	response := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+		"id": nil,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleHealthCheck 健康检查端点
+func (s *Server) handleHealthCheck(c *gin.Context) {
+	stats := s.connPool.Stats()
+
+	// 排空或关闭中的实例返回 503，便于负载均衡器摘除
+	if s.isShuttingDown() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":      "draining",
+			"timestamp":   time.Now().Format(time.RFC3339),
+			"connections": stats,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":      "healthy",
+		"timestamp":   time.Now().Format(time.RFC3339),
+		"connections": stats,
+	})
+}
@@ -0,0 +1,625 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/internal/pagination"
+	"Weave-Toolkit/internal/tools"
+)
+
+// SessionURIPrefix 会话资源 URI 前缀
+const SessionURIPrefix = "session://resources/"
+
+// 会话默认参数
+const (
+	defaultSessionSoftQuota = 8 << 20  // 8 MiB
+	defaultSessionHardQuota = 16 << 20 // 16 MiB
+	defaultSessionTTL       = 30 * time.Minute
+	maxSessionTracked       = 1000 // 每个会话记录的事件流与任务数上限，超出时丢弃最早的记录
+)
+
+// SessionResource 会话中发布的资源
+type SessionResource struct {
+	URI         string    `json:"uri"`
+	Name        string    `json:"name"`
+	MimeType    string    `json:"mimeType"`
+	Size        int64     `json:"size"`
+	PublishedAt time.Time `json:"publishedAt"`
+	Data        []byte    `json:"-"`
+}
+
+// SessionInfo 会话概要及内存占用
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	Client     string    `json:"client"`
+	Version    string    `json:"client_version,omitempty"`
+	Locale     string    `json:"locale,omitempty"`
+	Tenant     string    `json:"tenant,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastActive time.Time `json:"last_active"`
+	Resources  int       `json:"resources"`
+	Bytes      int64     `json:"bytes"`
+	SoftQuota  int64     `json:"soft_quota"`
+	HardQuota  int64     `json:"hard_quota"`
+	Evicted    int64     `json:"evicted"`
+	Rejected   int64     `json:"rejected"`
+
+	Capabilities tools.ClientCapabilities `json:"capabilities,omitempty"`
+}
+
+// Session MCP 会话，持有工具发布的资源
+type Session struct {
+	ID           string
+	Client       string
+	Version      string                   // initialize 时客户端声明的版本
+	Locale       string                   // initialize 时客户端声明的区域设置
+	Capabilities tools.ClientCapabilities // initialize 时客户端声明的能力，之后的请求据此判断客户端支持的功能
+	Tenant       string                   // 创建会话的租户，未配置租户时为空；其他租户的请求查找不到该会话
+	CreatedAt    time.Time
+
+	store      *SessionStore
+	mu         sync.Mutex
+	lastActive time.Time
+	resources  map[string]*SessionResource
+	order      []string // 按发布时间排序的资源名，用于淘汰最早的资源
+	bytes      int64
+	evicted    int64
+	rejected   int64
+	revision   int64    // 集群模式下最近一次写入共享存储的修订号，与共享存储不一致时重新加载
+	busy       int      // 正在处理的请求数，处理中的会话不会因空闲过期
+	streams    []string // 本副本上该会话打开的事件流，会话过期时释放缓冲
+	jobs       []string // 本副本上该会话提交的异步任务，会话过期时取消未完成的任务
+}
+
+// SessionStore 会话存储
+//
+// 软配额：会话占用超出后记录警告并淘汰最早发布的资源，直到回到软配额以内；
+// 硬配额：任何时刻占用都不超过该值，超出的发布请求直接拒绝。
+type SessionStore struct {
+	mu        sync.Mutex
+	sessions  map[string]*Session
+	softQuota int64
+	hardQuota int64
+	ttl       time.Duration
+	expired   int64          // 因空闲过期被清理的会话数
+	shared    sessionBackend // 集群共享存储，为空则会话仅对本副本可见
+	logger    *logger.Logger
+}
+
+// NewSessionStore 创建会话存储
+func NewSessionStore(softQuota, hardQuota int64, ttl time.Duration, logger *logger.Logger) *SessionStore {
+	if hardQuota <= 0 {
+		hardQuota = defaultSessionHardQuota
+	}
+	if softQuota <= 0 || softQuota > hardQuota {
+		softQuota = defaultSessionSoftQuota
+		if softQuota > hardQuota {
+			softQuota = hardQuota
+		}
+	}
+	if ttl <= 0 {
+		ttl = defaultSessionTTL
+	}
+
+	return &SessionStore{
+		sessions:  make(map[string]*Session),
+		softQuota: softQuota,
+		hardQuota: hardQuota,
+		ttl:       ttl,
+		logger:    logger,
+	}
+}
+
+// Create 创建会话
+func (st *SessionStore) Create(client string) *Session {
+	return st.create(&ClientInfo{Name: client}, "")
+}
+
+// create 创建属于 tenant 的会话并记录客户端声明的版本、区域设置与能力
+func (st *SessionStore) create(clientInfo *ClientInfo, tenant string) *Session {
+	st.mu.Lock()
+	now := time.Now()
+	session := &Session{
+		ID:           newRandomID("session"),
+		Client:       clientInfo.Name,
+		Version:      clientInfo.Version,
+		Locale:       clientInfo.Locale,
+		Capabilities: clientInfo.Capabilities,
+		Tenant:       tenant,
+		CreatedAt:    now,
+		store:        st,
+		lastActive:   now,
+		resources:    make(map[string]*SessionResource),
+	}
+	st.sessions[session.ID] = session
+	st.mu.Unlock()
+
+	session.mu.Lock()
+	st.saveLocked(session)
+	session.mu.Unlock()
+	return session
+}
+
+// Get 获取会话并更新活跃时间
+func (st *SessionStore) Get(id string) (*Session, bool) {
+	st.mu.Lock()
+	session, exists := st.sessions[id]
+	st.mu.Unlock()
+
+	if st.shared != nil {
+		session, exists = st.refresh(id, session)
+	}
+	if !exists {
+		return nil, false
+	}
+
+	session.mu.Lock()
+	session.lastActive = time.Now()
+	session.mu.Unlock()
+	return session, true
+}
+
+// Delete 删除会话并释放其资源
+func (st *SessionStore) Delete(id string) bool {
+	_, exists := st.remove(id)
+	return exists
+}
+
+// remove 删除会话，返回本副本上的会话对象（会话仅存在于其他副本时为 nil）
+func (st *SessionStore) remove(id string) (*Session, bool) {
+	st.mu.Lock()
+	session, exists := st.sessions[id]
+	delete(st.sessions, id)
+	st.mu.Unlock()
+
+	if st.shared != nil && st.deleteShared(id) {
+		exists = true
+	}
+	return session, exists
+}
+
+// List 获取所有会话概要
+func (st *SessionStore) List() []SessionInfo {
+	st.mu.Lock()
+	sessions := make([]*Session, 0, len(st.sessions))
+	for _, session := range st.sessions {
+		sessions = append(sessions, session)
+	}
+	st.mu.Unlock()
+
+	list := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		list = append(list, session.Info())
+	}
+	if st.shared != nil {
+		list = st.mergeShared(list)
+	}
+	return list
+}
+
+// Stats 获取会话统计信息
+func (st *SessionStore) Stats() map[string]interface{} {
+	var total int64
+	list := st.List()
+	for _, info := range list {
+		total += info.Bytes
+	}
+
+	st.mu.Lock()
+	expired := st.expired
+	st.mu.Unlock()
+
+	return map[string]interface{}{
+		"sessions":   len(list),
+		"bytes":      total,
+		"soft_quota": st.softQuota,
+		"hard_quota": st.hardQuota,
+		"expired":    expired,
+	}
+}
+
+// Reap 删除空闲超过 TTL 的会话并返回，调用方负责释放会话关联的事件流与任务
+//
+// 正在处理请求的会话不会过期。集群模式下共享存储中仍存在的会话在其他副本上可能仍然活跃，
+// 保留本地会话，由共享存储的过期时间决定会话何时结束。
+func (st *SessionStore) Reap() []*Session {
+	cutoff := time.Now().Add(-st.ttl)
+	st.mu.Lock()
+	var idle []*Session
+	for _, session := range st.sessions {
+		session.mu.Lock()
+		if session.busy == 0 && session.lastActive.Before(cutoff) {
+			idle = append(idle, session)
+		}
+		session.mu.Unlock()
+	}
+	st.mu.Unlock()
+
+	var expired []*Session
+	for _, session := range idle {
+		if st.shared != nil && st.sharedExists(session.ID) {
+			continue
+		}
+		st.mu.Lock()
+		if st.sessions[session.ID] == session {
+			delete(st.sessions, session.ID)
+			st.expired++
+			expired = append(expired, session)
+		}
+		st.mu.Unlock()
+	}
+	return expired
+}
+
+// Expired 因空闲过期被清理的会话数
+func (st *SessionStore) Expired() int64 {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.expired
+}
+
+// TTL 会话空闲时长
+func (st *SessionStore) TTL() time.Duration {
+	return st.ttl
+}
+
+// begin 标记会话开始处理请求，返回结束时调用的函数；会话为 nil 时不做任何事
+func (s *Session) begin() func() {
+	if s == nil {
+		return func() {}
+	}
+	s.mu.Lock()
+	s.busy++
+	s.lastActive = time.Now()
+	s.mu.Unlock()
+	return func() {
+		s.mu.Lock()
+		s.busy--
+		s.lastActive = time.Now()
+		s.mu.Unlock()
+	}
+}
+
+// trackStream 记录会话打开的事件流
+func (s *Session) trackStream(streamID string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.streams = appendTracked(s.streams, streamID)
+	s.mu.Unlock()
+}
+
+// trackJob 记录会话提交的异步任务
+func (s *Session) trackJob(jobID string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.jobs = appendTracked(s.jobs, jobID)
+	s.mu.Unlock()
+}
+
+// owned 会话打开的事件流与提交的任务
+func (s *Session) owned() (streams, jobs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.streams...), append([]string(nil), s.jobs...)
+}
+
+// idleFor 会话已空闲的时长
+func (s *Session) idleFor() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Since(s.lastActive)
+}
+
+// appendTracked 追加记录，超过上限时丢弃最早的记录
+func appendTracked(list []string, id string) []string {
+	if len(list) >= maxSessionTracked {
+		list = append(list[:0], list[1:]...)
+	}
+	return append(list, id)
+}
+
+// Publish 发布资源，实现 tools.ResourcePublisher
+func (s *Session) Publish(name, mimeType string, data []byte) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("resource name is required")
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	size := int64(len(data))
+	used := s.bytes
+	if existing, ok := s.resources[name]; ok {
+		used -= existing.Size
+	}
+
+	if used+size > s.store.hardQuota {
+		s.rejected++
+		s.store.logger.Warn().
+			Str("session_id", s.ID).
+			Str("resource", name).
+			Int64("size", size).
+			Int64("used", used).
+			Int64("hard_quota", s.store.hardQuota).
+			Msg("Session hard quota exceeded, resource rejected")
+		return "", fmt.Errorf("session memory quota exceeded: %d + %d bytes > %d", used, size, s.store.hardQuota)
+	}
+
+	s.removeLocked(name)
+	resource := &SessionResource{
+		URI:         SessionURIPrefix + name,
+		Name:        name,
+		MimeType:    mimeType,
+		Size:        size,
+		PublishedAt: time.Now(),
+		Data:        append([]byte(nil), data...),
+	}
+	s.resources[name] = resource
+	s.order = append(s.order, name)
+	s.bytes += size
+
+	if s.bytes > s.store.softQuota {
+		s.evictLocked(name)
+	}
+	s.store.saveLocked(s)
+
+	return resource.URI, nil
+}
+
+// evictLocked 淘汰最早发布的资源直到回到软配额以内，keep 为刚发布的资源（调用方需持有锁）
+func (s *Session) evictLocked(keep string) {
+	var evicted []string
+	for s.bytes > s.store.softQuota && len(s.order) > 0 {
+		name := s.order[0]
+		if name == keep {
+			break
+		}
+		s.removeLocked(name)
+		s.evicted++
+		evicted = append(evicted, name)
+	}
+
+	s.store.logger.Warn().
+		Str("session_id", s.ID).
+		Int64("used", s.bytes).
+		Int64("soft_quota", s.store.softQuota).
+		Strs("evicted", evicted).
+		Msg("Session soft quota exceeded, evicted oldest resources")
+}
+
+// removeLocked 删除资源（调用方需持有锁）
+func (s *Session) removeLocked(name string) {
+	resource, exists := s.resources[name]
+	if !exists {
+		return
+	}
+
+	delete(s.resources, name)
+	s.bytes -= resource.Size
+	for i, n := range s.order {
+		if n == name {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// Resource 按 URI 获取资源
+func (s *Session) Resource(uri string) (*SessionResource, bool) {
+	name := strings.TrimPrefix(uri, SessionURIPrefix)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resource, exists := s.resources[name]
+	return resource, exists
+}
+
+// ReadResource 按 URI 读取资源内容，实现 tools.ResourceReader
+func (s *Session) ReadResource(uri string) ([]byte, string, error) {
+	resource, exists := s.Resource(uri)
+	if !exists {
+		return nil, "", fmt.Errorf("resource not found: %s", uri)
+	}
+	return resource.Data, resource.MimeType, nil
+}
+
+// Resources 获取会话中的资源列表（按发布时间排序）
+func (s *Session) Resources() []SessionResource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]SessionResource, 0, len(s.order))
+	for _, name := range s.order {
+		list = append(list, *s.resources[name])
+	}
+	return list
+}
+
+// Info 获取会话概要
+func (s *Session) Info() SessionInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.infoLocked()
+}
+
+// infoLocked 获取会话概要（调用方需持有锁）
+func (s *Session) infoLocked() SessionInfo {
+	return SessionInfo{
+		ID:         s.ID,
+		Client:     s.Client,
+		Version:    s.Version,
+		Locale:     s.Locale,
+		Tenant:     s.Tenant,
+		CreatedAt:  s.CreatedAt,
+		LastActive: s.lastActive,
+		Resources:  len(s.resources),
+		Bytes:      s.bytes,
+		SoftQuota:  s.store.softQuota,
+		HardQuota:  s.store.hardQuota,
+		Evicted:    s.evicted,
+		Rejected:   s.rejected,
+
+		Capabilities: s.Capabilities,
+	}
+}
+
+// id 返回会话ID，不属于会话时为空
+func (s *Session) id() string {
+	if s == nil {
+		return ""
+	}
+	return s.ID
+}
+
+// sessionContextKey 会话上下文键
+type sessionContextKey struct{}
+
+// withSession 在上下文中记录当前会话
+func withSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, session)
+}
+
+// sessionFromContext 从上下文中获取当前会话
+func sessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionContextKey{}).(*Session)
+	return session
+}
+
+// resolveSession 关联请求所属的会话
+//
+// initialize 请求创建新会话并通过响应头返回会话ID；其他请求按请求头查找会话，
+// 未携带会话ID的请求不属于任何会话，携带了未知、已过期或属于其他租户的会话ID时返回错误。
+// 属于会话的请求沿用 initialize 时声明的客户端名称、版本与能力。
+func (s *Server) resolveSession(c *gin.Context, method string, clientInfo *ClientInfo) (*Session, error) {
+	if method == MethodInitialize {
+		session := s.sessions.create(clientInfo, requestTenant(c.Request.Context()))
+		c.Header(SessionIDHeader, session.ID)
+		return session, nil
+	}
+
+	id := c.GetHeader(SessionIDHeader)
+	if id == "" {
+		return nil, nil
+	}
+
+	session, exists := s.tenantSession(c.Request.Context(), id)
+	if !exists {
+		return nil, fmt.Errorf("session not found: %s", id)
+	}
+	if session.Client != "" {
+		clientInfo.Name = session.Client
+		clientInfo.Version = session.Version
+	}
+	clientInfo.Capabilities = session.Capabilities
+	return session, nil
+}
+
+// tenantSession 查找请求租户的会话，会话属于其他租户时与不存在的会话同样处理，不泄露会话是否存在
+func (s *Server) tenantSession(ctx context.Context, id string) (*Session, bool) {
+	session, exists := s.sessions.Get(id)
+	if !exists || session.Tenant != requestTenant(ctx) {
+		return nil, false
+	}
+	return session, true
+}
+
+// requestTenant 返回请求所属的租户名，未配置租户时为空
+func requestTenant(ctx context.Context) string {
+	if tenant := tools.TenantFromContext(ctx); tenant != nil {
+		return tenant.Name
+	}
+	return ""
+}
+
+// sessionContext 在上下文中记录会话，并允许工具向该会话发布与读取资源
+func (s *Server) sessionContext(ctx context.Context, session *Session) context.Context {
+	if session == nil {
+		return ctx
+	}
+	ctx = tools.WithResourceReader(withSession(ctx, session), session)
+	ctx = tools.WithSessionID(ctx, session.ID)
+	ctx = tools.WithClientCapabilities(ctx, session.Capabilities)
+	return tools.WithPublisher(ctx, session)
+}
+
+// readSessionResource 读取当前会话中发布的资源
+func (s *Server) readSessionResource(ctx context.Context, uri string) (*resourceContents, error) {
+	session := sessionFromContext(ctx)
+	if session == nil {
+		return nil, fmt.Errorf("session resources require the %s header", SessionIDHeader)
+	}
+
+	resource, exists := session.Resource(uri)
+	if !exists {
+		return nil, fmt.Errorf("resource not found: %s", uri)
+	}
+
+	if isTextMimeType(resource.MimeType) {
+		return newResourceContents(resource.URI, resource.MimeType, string(resource.Data), false), nil
+	}
+	return newResourceContents(resource.URI, resource.MimeType, base64.StdEncoding.EncodeToString(resource.Data), true), nil
+}
+
+// isTextMimeType 判断资源是否以文本形式返回
+func isTextMimeType(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "text/") ||
+		strings.HasPrefix(mimeType, "application/json") ||
+		strings.HasSuffix(mimeType, "+json")
+}
+
+// handleSessionDelete 客户端结束会话，释放其发布的资源
+func (s *Server) handleSessionDelete(c *gin.Context) {
+	id := c.GetHeader(SessionIDHeader)
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing " + SessionIDHeader + " header"})
+		return
+	}
+
+	if _, exists := s.tenantSession(c.Request.Context(), id); !exists || !s.endSession(id, sessionEndClient) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// handleAdminSessions 分页查询会话及其内存占用
+func (s *Server) handleAdminSessions(c *gin.Context) {
+	params, err := pagination.ParseParams(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client := c.Query("client")
+	list := s.sessions.List()
+	filtered := list[:0]
+	for _, info := range list {
+		if client != "" && info.Client != client {
+			continue
+		}
+		filtered = append(filtered, info)
+	}
+
+	page := pagination.Paginate(filtered, params, func(info SessionInfo) pagination.Cursor {
+		return pagination.Cursor{Time: info.CreatedAt, ID: info.ID}
+	})
+	c.JSON(http.StatusOK, page.Response("sessions"))
+}
@@ -0,0 +1,237 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultDrainTimeout 未配置排空窗口时的默认值
+const defaultDrainTimeout = 30 * time.Second
+
+// shutdownGracePeriod 排空窗口结束后等待流发送终止事件、关闭连接的时间
+const shutdownGracePeriod = 5 * time.Second
+
+// 关闭通知阶段
+const (
+	shutdownPhaseDraining = "draining" // 已停止接收新请求，正在执行的流可在排空窗口内完成
+	shutdownPhaseClosed   = "closed"   // 排空窗口结束，流被终止
+	drainPhaseDrained     = "drained"  // 排空期间所有操作已完成，仅用于排空状态
+)
+
+// drainSignal 一次排空的通知与终止信号
+//
+// 关闭与管理接口发起的排空共用同一信号；管理接口取消排空后替换为新的信号，
+// 已收到通知的流保留原信号。
+type drainSignal struct {
+	notice     context.Context    // 开始排空时取消，用于通知活跃流
+	begin      context.CancelFunc // 触发通知
+	closed     context.Context    // 排空窗口结束时取消，用于终止仍在执行的流
+	end        context.CancelFunc // 结束排空窗口
+	startedAt  time.Time          // 开始排空的时间，未开始时为零值
+	deadline   time.Time          // 排空窗口结束的时间
+	initialOps int64              // 开始排空时正在执行的操作数
+	timer      *time.Timer        // 管理接口发起的排空在窗口结束时终止剩余的流
+}
+
+// newDrainSignal 创建未开始的排空信号
+func newDrainSignal() *drainSignal {
+	signal := &drainSignal{}
+	signal.notice, signal.begin = context.WithCancel(context.Background())
+	signal.closed, signal.end = context.WithCancel(context.Background())
+	return signal
+}
+
+// currentDrain 获取当前排空信号
+func (s *Server) currentDrain() *drainSignal {
+	s.shutdownMu.RLock()
+	defer s.shutdownMu.RUnlock()
+	return s.drain
+}
+
+// startDrain 开始排空：通知活跃流并记录进度基线，已在排空时沿用原有窗口
+//
+// terminate 为 true 时在窗口结束后终止剩余的流（管理接口发起）；关闭流程自行决定终止时机。
+func (s *Server) startDrain(terminate bool) *drainSignal {
+	s.shutdownMu.Lock()
+	defer s.shutdownMu.Unlock()
+
+	signal := s.drain
+	if signal.startedAt.IsZero() {
+		window := s.drainTimeout()
+		signal.startedAt = time.Now()
+		signal.deadline = signal.startedAt.Add(window)
+		signal.initialOps = atomic.LoadInt64(&s.activeCount)
+		signal.begin()
+		if terminate {
+			signal.timer = time.AfterFunc(window, signal.end)
+		}
+	}
+	return signal
+}
+
+// cancelDrain 取消管理接口发起的排空，之后开始的流使用新的信号；关闭过程中不可取消
+func (s *Server) cancelDrain() {
+	s.shutdownMu.Lock()
+	defer s.shutdownMu.Unlock()
+
+	if s.shuttingDown || s.drain.startedAt.IsZero() {
+		return
+	}
+	if s.drain.timer != nil {
+		s.drain.timer.Stop()
+	}
+	s.drain = newDrainSignal()
+}
+
+// lockedEmitter 串行化流事件输出
+//
+// 关闭通知与工具输出来自不同协程，需要互斥写入；流结束后迟到的事件直接丢弃，
+// 避免在请求处理函数返回后继续写 ResponseWriter。
+type lockedEmitter struct {
+	mu     sync.Mutex
+	emit   streamEmitter
+	closed bool
+}
+
+// Emit 输出事件
+func (l *lockedEmitter) Emit(event string, data interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return
+	}
+	l.emit(event, data)
+}
+
+// Terminate 输出最后一个事件并停止输出
+func (l *lockedEmitter) Terminate(event string, data interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return
+	}
+	l.emit(event, data)
+	l.closed = true
+}
+
+// Close 停止输出事件
+func (l *lockedEmitter) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+}
+
+// drainTimeout 关闭时等待活跃操作完成的排空窗口
+func (s *Server) drainTimeout() time.Duration {
+	if s.config.ShutdownDrain > 0 {
+		return s.config.ShutdownDrain
+	}
+	return defaultDrainTimeout
+}
+
+// streamContext 派生流式请求上下文，排空窗口结束时取消
+func (s *Server) streamContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	stop := context.AfterFunc(s.currentDrain().closed, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// detachedContext 派生不随请求结束而取消的后台上下文，保留请求的租户等值与截止时间，
+// 排空窗口结束时取消
+func (s *Server) detachedContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx := context.WithoutCancel(parent)
+	if deadline, ok := parent.Deadline(); ok {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadline(ctx, deadline)
+		ctx, cancel := s.streamContext(ctx)
+		return ctx, func() {
+			cancel()
+			cancelDeadline()
+		}
+	}
+	return s.streamContext(ctx)
+}
+
+// watchShutdown 服务器开始关闭或排空时向流推送通知，排空窗口结束时发送终止事件并停止输出
+//
+// 终止事件不依赖工具响应取消，客户端总能在连接断开前收到明确的结束事件。
+// 返回的函数用于在流正常结束时取消监听。
+func (s *Server) watchShutdown(emitter *lockedEmitter) func() {
+	signal := s.currentDrain()
+	stopNotice := context.AfterFunc(signal.notice, func() {
+		emitter.Emit(StreamEventShutdown, s.shutdownNotice(shutdownPhaseDraining))
+	})
+	stopTerminate := context.AfterFunc(signal.closed, func() {
+		emitter.Terminate(StreamEventShutdown, s.shutdownNotice(shutdownPhaseClosed))
+	})
+	return func() {
+		stopNotice()
+		stopTerminate()
+	}
+}
+
+// runStream 以 emitter 输出 fn 产生的事件
+//
+// 排空窗口结束时工具被取消，此后工具返回的取消错误等事件不再输出，流以终止事件结束，
+// 避免客户端先收到取消错误而无法区分服务器关闭。
+func (s *Server) runStream(emitter *lockedEmitter, fn func(emit streamEmitter)) {
+	fn(func(event string, data interface{}) {
+		if s.drained() {
+			return
+		}
+		emitter.Emit(event, data)
+	})
+	if s.drained() {
+		emitter.Terminate(StreamEventShutdown, s.shutdownNotice(shutdownPhaseClosed))
+	}
+}
+
+// shutdownNotice 关闭通知内容，管理接口发起的排空与进程关闭以 message 区分
+func (s *Server) shutdownNotice(phase string) map[string]interface{} {
+	s.shutdownMu.RLock()
+	shuttingDown, deadline := s.shuttingDown, s.drain.deadline
+	s.shutdownMu.RUnlock()
+
+	message := "server draining"
+	if shuttingDown {
+		message = "server shutting down"
+	}
+	notice := map[string]interface{}{
+		"method":       "notifications/server/shutdown",
+		"message":      message,
+		"phase":        phase,
+		"drainTimeout": s.drainTimeout().String(),
+	}
+	if !deadline.IsZero() {
+		notice["deadline"] = deadline.Format(time.RFC3339)
+	}
+	return notice
+}
+
+// drained 排空窗口是否已结束
+func (s *Server) drained() bool {
+	return s.currentDrain().closed.Err() != nil
+}
+
+// waitActiveOps 等待活跃操作完成，超时返回 false
+func (s *Server) waitActiveOps(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		s.activeOps.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
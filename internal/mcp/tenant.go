@@ -0,0 +1,243 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/metering"
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/internal/tools"
+)
+
+// TenantHeader 指定租户的请求头，仅在使用全局 API Key 或未配置 API Key 时生效
+const TenantHeader = "X-Tenant-ID"
+
+var (
+	// errUnauthorized API Key 既不是全局 Key 也不属于任何租户
+	errUnauthorized = errors.New("unauthorized")
+	// errUnknownTenant 请求指定的租户未配置
+	errUnknownTenant = errors.New("unknown tenant")
+)
+
+// resolveTenant 按 API Key 或租户头确定请求所属的租户
+//
+// 租户 API Key 直接映射到租户并忽略租户头；全局 API Key（或未配置 API Key 时的任意请求）
+// 可通过租户头代表指定租户调用，未携带租户头时使用完整的工具目录。
+func (s *Server) resolveTenant(apiKey, tenantName string) (*tools.Tenant, error) {
+	tenants := s.toolConfig().Tenants
+	if apiKey != "" {
+		for name, tenantConfig := range tenants {
+			for _, key := range tenantConfig.ResolveAPIKeys() {
+				if subtle.ConstantTimeCompare([]byte(apiKey), []byte(key)) == 1 {
+					return &tools.Tenant{Name: name, Config: tenantConfig}, nil
+				}
+			}
+		}
+	}
+
+	if s.config.APIKey != "" && subtle.ConstantTimeCompare([]byte(apiKey), []byte(s.config.APIKey)) != 1 {
+		return nil, errUnauthorized
+	}
+	if tenantName == "" {
+		return nil, nil
+	}
+	tenantConfig, ok := tenants[tenantName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownTenant, tenantName)
+	}
+	return &tools.Tenant{Name: tenantName, Config: tenantConfig}, nil
+}
+
+// tenant 按名称获取租户的当前配置
+func (s *Server) tenant(name string) (*tools.Tenant, error) {
+	tenantConfig, ok := s.toolConfig().Tenants[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownTenant, name)
+	}
+	return &tools.Tenant{Name: name, Config: tenantConfig}, nil
+}
+
+// tenantMiddleware 鉴权并将请求所属的租户写入请求上下文
+//
+// 取代 /mcp、REST 桥接与对话补全端点上的 APIKeyMiddleware，API Key 的读取方式相同。
+func (s *Server) tenantMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader("X-API-Key")
+		if provided == "" {
+			provided = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		}
+
+		tenant, err := s.resolveTenant(provided, c.GetHeader(TenantHeader))
+		switch {
+		case errors.Is(err, errUnauthorized):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := metering.WithAPIKey(c.Request.Context(), provided)
+		if tenant != nil {
+			ctx = tools.WithTenant(ctx, tenant)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// tenantTools 获取请求所属租户可使用的启用工具
+func (s *Server) tenantTools(ctx context.Context) []tools.ToolInfo {
+	return s.toolMgr.TenantTools(tools.TenantFromContext(ctx))
+}
+
+// listRoots 将命名的根目录转换为 file:// URI 列表，按名称排序
+func listRoots(dirs map[string]string) ([]RootInfo, error) {
+	names := make([]string, 0, len(dirs))
+	for name := range dirs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	roots := make([]RootInfo, 0, len(names))
+	for _, name := range names {
+		dir, err := platform.NormalizePath(dirs[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid root %s: %v", name, err)
+		}
+		path := filepath.ToSlash(dir)
+		if !strings.HasPrefix(path, "/") {
+			// Windows 盘符路径
+			path = "/" + path
+		}
+		roots = append(roots, RootInfo{URI: (&url.URL{Scheme: "file", Path: path}).String(), Name: name})
+	}
+	return roots, nil
+}
+
+// tenantPrompts 列出租户的提示词，按名称排序
+func tenantPrompts(tenant *tools.Tenant) []PromptInfo {
+	return promptInfos(tenant.Config.Prompts)
+}
+
+// tenantUsage 单个租户的工具调用统计
+type tenantUsage struct {
+	Calls       int64            `json:"calls"`
+	Errors      int64            `json:"errors"`
+	RateLimited int64            `json:"rate_limited"` // 因超过调用限额被拒绝的次数，计入 errors
+	DurationMs  int64            `json:"duration_ms"`  // 累计调用耗时
+	Tools       map[string]int64 `json:"tools"`        // 各工具的调用次数
+	LastCall    time.Time        `json:"last_call"`
+}
+
+// TenantUsageTracker 按租户统计工具调用，作为工具调用观察者注册
+type TenantUsageTracker struct {
+	mu      sync.Mutex
+	tenants map[string]*tenantUsage
+}
+
+// NewTenantUsageTracker 创建租户调用统计
+func NewTenantUsageTracker() *TenantUsageTracker {
+	return &TenantUsageTracker{tenants: make(map[string]*tenantUsage)}
+}
+
+// Observe 记录租户发起的调用，未关联租户的调用不计入
+func (tu *TenantUsageTracker) Observe(_ context.Context, record tools.CallRecord) {
+	if record.Tenant == "" {
+		return
+	}
+
+	tu.mu.Lock()
+	defer tu.mu.Unlock()
+
+	usage, exists := tu.tenants[record.Tenant]
+	if !exists {
+		usage = &tenantUsage{Tools: make(map[string]int64)}
+		tu.tenants[record.Tenant] = usage
+	}
+	usage.Calls++
+	if record.Status == tools.CallStatusError {
+		usage.Errors++
+		if record.ErrorKind == werrors.KindRateLimited {
+			usage.RateLimited++
+		}
+	}
+	usage.DurationMs += record.Duration.Milliseconds()
+	usage.Tools[record.Tool]++
+	usage.LastCall = record.StartedAt
+}
+
+// Usage 获取租户的调用统计快照
+func (tu *TenantUsageTracker) Usage(tenant string) tenantUsage {
+	tu.mu.Lock()
+	defer tu.mu.Unlock()
+
+	snapshot := tenantUsage{Tools: make(map[string]int64)}
+	if usage, exists := tu.tenants[tenant]; exists {
+		snapshot = *usage
+		snapshot.Tools = make(map[string]int64, len(usage.Tools))
+		for tool, calls := range usage.Tools {
+			snapshot.Tools[tool] = calls
+		}
+	}
+	return snapshot
+}
+
+// handleTenantUsage 返回请求所属租户自己的调用统计
+func (s *Server) handleTenantUsage(c *gin.Context) {
+	tenant := tools.TenantFromContext(c.Request.Context())
+	if tenant == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "request is not associated with a tenant"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"tenant": tenant.Name,
+		"usage":  s.tenantUsage.Usage(tenant.Name),
+	})
+}
+
+// handleAdminTenants 列出租户配置摘要与调用统计，不返回 API Key
+func (s *Server) handleAdminTenants(c *gin.Context) {
+	tenants := s.toolConfig().Tenants
+	names := make([]string, 0, len(tenants))
+	for name := range tenants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := make([]gin.H, 0, len(names))
+	for _, name := range names {
+		tenantConfig := tenants[name]
+		roots := make([]string, 0, len(tenantConfig.Roots))
+		for root := range tenantConfig.Roots {
+			roots = append(roots, root)
+		}
+		sort.Strings(roots)
+		tenant := &tools.Tenant{Name: name, Config: tenantConfig}
+
+		list = append(list, gin.H{
+			"name":           name,
+			"api_keys":       len(tenantConfig.ResolveAPIKeys()),
+			"categories":     tenantConfig.Categories,
+			"disabled_tools": tenantConfig.DisabledTools,
+			"rate_limit":     tenantConfig.RateLimit,
+			"roots":          roots,
+			"prompts":        len(tenantConfig.Prompts),
+			"tools":          len(s.toolMgr.TenantTools(tenant)),
+			"usage":          s.tenantUsage.Usage(name),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"tenants": list})
+}
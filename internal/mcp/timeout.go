@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/tools"
+)
+
+// RequestTimeoutHeader 请求的超时时间（如 30s 或秒数），作为工具调用的截止时间
+const RequestTimeoutHeader = "X-MCP-Timeout"
+
+// 请求截止时间的来源，用于错误信息
+const (
+	timeoutSourceHeader = RequestTimeoutHeader + " header"
+	timeoutSourceMeta   = "_meta.timeout"
+)
+
+// parseRequestTimeout 解析请求超时，支持 Go 时长格式与整数秒
+func parseRequestTimeout(value, source string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		value = strconv.Itoa(seconds) + "s"
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s: %q", source, value)
+	}
+	return timeout, nil
+}
+
+// clampRequestTimeout 将请求的超时限制在 MCP_MAX_REQUEST_TIMEOUT 以内，未配置时不限制
+func (s *Server) clampRequestTimeout(timeout time.Duration) time.Duration {
+	if limit := s.config.MaxRequestTimeout; limit > 0 && timeout > limit {
+		return limit
+	}
+	return timeout
+}
+
+// withTimeoutHint 按 tools/call 参数中的 _meta.timeout（Go 时长格式或秒数）缩短调用的截止时间
+//
+// 提示超过 MCP_MAX_REQUEST_TIMEOUT 时按上限处理；已有更早的截止时间（如 X-MCP-Timeout）时以更早者为准。
+func (s *Server) withTimeoutHint(ctx context.Context, params map[string]interface{}) (context.Context, context.CancelFunc, error) {
+	meta, _ := params["_meta"].(map[string]interface{})
+	var timeout time.Duration
+	switch value := meta["timeout"].(type) {
+	case nil:
+		return ctx, func() {}, nil
+	case float64:
+		timeout = time.Duration(value * float64(time.Second))
+		if timeout <= 0 {
+			return nil, nil, fmt.Errorf("%w: invalid %s: %v", errInvalidParams, timeoutSourceMeta, value)
+		}
+	case string:
+		parsed, err := parseRequestTimeout(value, timeoutSourceMeta)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", errInvalidParams, err)
+		}
+		timeout = parsed
+	default:
+		return nil, nil, fmt.Errorf("%w: invalid %s: %v", errInvalidParams, timeoutSourceMeta, value)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.clampRequestTimeout(timeout))
+	return ctx, cancel, nil
+}
+
+// requestTimeoutMiddleware 为携带 X-MCP-Timeout 的请求设置截止时间，工具调用的超时不超过该时间
+func (s *Server) requestTimeoutMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.GetHeader(RequestTimeoutHeader)
+		if value == "" {
+			c.Next()
+			return
+		}
+
+		timeout, err := parseRequestTimeout(value, timeoutSourceHeader)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), s.clampRequestTimeout(timeout))
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// toolCallError 将熔断与超时错误转换为带独立错误码与 data 的 JSON-RPC 错误，其他错误返回 false
+func toolCallError(err error) (gin.H, bool) {
+	var circuitErr *tools.CircuitOpenError
+	var timeoutErr *tools.TimeoutError
+	switch {
+	case errors.As(err, &circuitErr):
+		return gin.H{
+			"code":    ErrorCodeCircuitOpen,
+			"message": err.Error(),
+			"data": gin.H{
+				"name":       circuitErr.Name,
+				"retryAfter": circuitErr.RetryAfter.Seconds(),
+			},
+		}, true
+	case errors.As(err, &timeoutErr):
+		source := "tool"
+		if timeoutErr.Requested {
+			source = "request"
+		}
+		return gin.H{
+			"code":    ErrorCodeToolTimeout,
+			"message": err.Error(),
+			"data": gin.H{
+				"tool":    timeoutErr.Tool,
+				"timeout": timeoutErr.Timeout.Seconds(),
+				"source":  source,
+			},
+		}, true
+	default:
+		return nil, false
+	}
+}
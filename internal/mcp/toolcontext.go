@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/tools"
+)
+
+// requestContextMiddleware 将请求ID写入请求上下文，工具日志通过 tools.RequestIDFromContext 与访问日志关联
+func requestContextMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if requestID := c.GetString("request_id"); requestID != "" {
+			c.Request = c.Request.WithContext(tools.WithRequestID(c.Request.Context(), requestID))
+		}
+		c.Next()
+	}
+}
+
+// streamEvents 将流式调用事件转换为 SSE 事件：片段为 content，进度为 progress，日志为 log，结果为 done
+//
+// progress 与 log 的 data 分别与 MCP 的 notifications/progress 与 notifications/message 一致；
+// 请求在 params._meta.progressToken 中携带令牌时原样返回，便于客户端关联请求。
+func streamEvents(emit streamEmitter, params map[string]interface{}) tools.EventSink {
+	var token interface{}
+	if meta, ok := params["_meta"].(map[string]interface{}); ok {
+		token = meta["progressToken"]
+	}
+	return func(event tools.StreamEvent) {
+		switch event.Type {
+		case tools.EventPartial:
+			emit(StreamEventContent, map[string]interface{}{
+				"type":    ContentTypeText,
+				"content": event.Content,
+				"index":   event.Index,
+			})
+		case tools.EventProgress:
+			data := map[string]interface{}{
+				"method":   "notifications/progress",
+				"progress": event.Progress,
+			}
+			if token != nil {
+				data["progressToken"] = token
+			}
+			if event.Total > 0 {
+				data["total"] = event.Total
+			}
+			if event.Message != "" {
+				data["message"] = event.Message
+			}
+			emit(StreamEventProgress, data)
+		case tools.EventLog:
+			emit(StreamEventLog, map[string]interface{}{
+				"method": "notifications/message",
+				"level":  event.Level,
+				"data":   event.Message,
+			})
+		case tools.EventFinal:
+			emit(StreamEventDone, map[string]interface{}{
+				"result": event.Result,
+			})
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/middleware"
+)
+
+// HTTP/2 默认参数
+const (
+	defaultHTTP2MaxStreams = 250
+	defaultHTTP2StreamBuf  = 1 << 20 // 单个流的接收窗口
+
+	// http2ConnBufStreams 连接级接收窗口为单流窗口的倍数
+	//
+	// 标准库默认连接窗口与单流窗口相同，一个大请求体即可占满整个连接窗口，
+	// 使同一连接上的其他小请求在流控上等待；放大连接窗口后单个流最多占用其中一部分。
+	http2ConnBufStreams = 8
+)
+
+// configureHTTP2 配置 HTTP/2 协议与流控参数
+//
+// 配置 TLS 证书时通过 ALPN 协商 h2；启用 h2c 时明文端口同时接受
+// prior-knowledge 的 HTTP/2 连接，适用于服务网格等内部部署。
+// 响应方向由标准库写调度器按帧在各流之间轮转，SSE 每个事件单独刷新，
+// 大的流式响应不会独占连接。
+func (s *Server) configureHTTP2(srv *http.Server) {
+	maxStreams := s.config.HTTP2MaxStreams
+	if maxStreams <= 0 {
+		maxStreams = defaultHTTP2MaxStreams
+	}
+	streamBuf := s.config.HTTP2StreamBuf
+	if streamBuf <= 0 {
+		streamBuf = defaultHTTP2StreamBuf
+	}
+
+	srv.HTTP2 = &http.HTTP2Config{
+		MaxConcurrentStreams:          maxStreams,
+		MaxReceiveBufferPerStream:     streamBuf,
+		MaxReceiveBufferPerConnection: streamBuf * http2ConnBufStreams,
+	}
+
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(s.config.H2CEnabled)
+	srv.Protocols = protocols
+}
+
+// tlsEnabled 是否配置了 TLS 证书
+func (s *Server) tlsEnabled() bool {
+	return s.config.TLSCertFile != "" && s.config.TLSKeyFile != ""
+}
+
+// listenAndServe 根据 TLS 配置启动监听
+//
+// 监听地址以 unix: 开头时监听 unix socket（Windows 下不支持，启动时报错）。
+func (s *Server) listenAndServe() error {
+	listener, err := platform.Listen(s.config.ServerAddress)
+	if err != nil {
+		return err
+	}
+	if s.tlsEnabled() {
+		return s.httpSrv.ServeTLS(listener, s.config.TLSCertFile, s.config.TLSKeyFile)
+	}
+	return s.httpSrv.Serve(listener)
+}
+
+// serveAdmin 启动独立的管理端监听，同样支持 unix socket 地址
+func (s *Server) serveAdmin() error {
+	listener, err := platform.Listen(s.config.AdminAddress)
+	if err != nil {
+		return err
+	}
+	return s.adminSrv.Serve(listener)
+}
+
+// setSSEHeaders 设置 SSE 响应头
+//
+// Connection 为 HTTP/1.x 的逐跳头，HTTP/2 禁止携带连接级头部，仅在 HTTP/1.x 下设置。
+func setSSEHeaders(c *gin.Context) {
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	if c.Request.ProtoMajor < 2 {
+		c.Writer.Header().Set("Connection", "keep-alive")
+	}
+}
+
+// compressionEncodings 解析响应压缩编码配置，未配置时优先 zstd，"none" 表示关闭压缩
+func (s *Server) compressionEncodings() []string {
+	value := strings.TrimSpace(s.config.Compression)
+	if value == "" {
+		return []string{middleware.EncodingZstd, middleware.EncodingGzip}
+	}
+	if strings.EqualFold(value, "none") || strings.EqualFold(value, "off") {
+		return nil
+	}
+
+	var encodings []string
+	for _, name := range strings.Split(value, ",") {
+		switch name = strings.ToLower(strings.TrimSpace(name)); name {
+		case middleware.EncodingZstd, middleware.EncodingGzip:
+			encodings = append(encodings, name)
+		default:
+			s.logger.Warn().Str("encoding", name).Msg("Ignoring unsupported compression encoding")
+		}
+	}
+	return encodings
+}
@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// legacyStreamPath 已弃用的流式端点
+const legacyStreamPath = "/mcp/stream"
+
+// legacyStreamNotice 弃用说明，同时用于响应头与日志
+const legacyStreamNotice = "POST /mcp/stream is deprecated; send tools/call to POST /mcp with Accept: text/event-stream"
+
+// endpointUsage 单个端点的调用统计
+type endpointUsage struct {
+	Requests int64     `json:"requests"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// legacyClientUsage 仍在使用弃用端点的客户端
+type legacyClientUsage struct {
+	Client   string    `json:"client"`
+	Requests int64     `json:"requests"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// UsageTracker 按路由统计端点调用次数，用于判断弃用端点何时可以移除
+type UsageTracker struct {
+	mu        sync.Mutex
+	endpoints map[string]*endpointUsage
+	legacy    map[string]*legacyClientUsage
+}
+
+// NewUsageTracker 创建端点调用统计
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{
+		endpoints: make(map[string]*endpointUsage),
+		legacy:    make(map[string]*legacyClientUsage),
+	}
+}
+
+// Middleware 统计匹配到路由的请求，未匹配的路径不计入
+func (ut *UsageTracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			return
+		}
+
+		ut.mu.Lock()
+		defer ut.mu.Unlock()
+
+		key := c.Request.Method + " " + route
+		usage, exists := ut.endpoints[key]
+		if !exists {
+			usage = &endpointUsage{}
+			ut.endpoints[key] = usage
+		}
+		usage.Requests++
+		usage.LastUsed = time.Now()
+	}
+}
+
+// recordLegacy 记录使用弃用端点的客户端，返回是否为该客户端的首次使用
+func (ut *UsageTracker) recordLegacy(client string) bool {
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+
+	usage, exists := ut.legacy[client]
+	if !exists {
+		usage = &legacyClientUsage{Client: client}
+		ut.legacy[client] = usage
+	}
+	usage.Requests++
+	usage.LastUsed = time.Now()
+	return !exists
+}
+
+// Stats 端点调用统计
+func (ut *UsageTracker) Stats() map[string]interface{} {
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+
+	endpoints := make(map[string]endpointUsage, len(ut.endpoints))
+	for key, usage := range ut.endpoints {
+		endpoints[key] = *usage
+	}
+
+	clients := make([]legacyClientUsage, 0, len(ut.legacy))
+	for _, usage := range ut.legacy {
+		clients = append(clients, *usage)
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].LastUsed.After(clients[j].LastUsed)
+	})
+
+	return map[string]interface{}{
+		"endpoints":             endpoints,
+		"legacy_stream_clients": clients,
+	}
+}
+
+// handleLegacyStream 兼容已弃用的 /mcp/stream 端点
+//
+// 响应携带 Deprecation 与 Link 头指向替代方式，每个客户端首次使用时记录警告日志；
+// 配置 MCP_DISABLE_LEGACY_STREAM 后返回 410。
+func (s *Server) handleLegacyStream(c *gin.Context) {
+	c.Header("Deprecation", "true")
+	c.Header("Link", `</mcp>; rel="successor-version"`)
+	c.Header("Warning", fmt.Sprintf(`299 - "%s"`, legacyStreamNotice))
+
+	if s.config.NoLegacyStream {
+		c.JSON(http.StatusGone, gin.H{
+			"error": legacyStreamNotice,
+		})
+		return
+	}
+
+	client := c.GetHeader(ClientNameHeader)
+	if client == "" {
+		client = c.Request.UserAgent()
+	}
+	if s.usage.recordLegacy(client) {
+		s.logger.Warn().
+			Str("client", client).
+			Str("client_ip", c.ClientIP()).
+			Msg("Client is using deprecated endpoint " + legacyStreamPath)
+	}
+
+	s.handleMCPStreamRequest(c)
+}
+
+// acceptsEventStream 请求是否声明接受 SSE 响应
+func acceptsEventStream(c *gin.Context) bool {
+	for _, value := range c.Request.Header.Values("Accept") {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, _, _ := strings.Cut(part, ";")
+			if strings.EqualFold(strings.TrimSpace(mediaType), "text/event-stream") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/internal/webhook"
+	"Weave-Toolkit/middleware"
+)
+
+// handleWebhook 处理外部 Webhook 触发请求，将事件负载映射为工具调用
+func (s *Server) handleWebhook(c *gin.Context) {
+	if s.isShuttingDown() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Service unavailable - server is shutting down",
+		})
+		return
+	}
+
+	s.beginOp()
+	defer s.endOp()
+
+	name := c.Param("name")
+	hook, exists := s.toolConfig().Webhooks[name]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found: " + name})
+		return
+	}
+
+	body := c.Request.Body
+	if s.config.MaxRequestSize > 0 {
+		body = http.MaxBytesReader(c.Writer, body, s.config.MaxRequestSize)
+	}
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "failed to read request body"})
+		return
+	}
+
+	if err := webhook.Verify(hook.Provider, hook.ResolveSecret(), hook.Header, c.Request.Header, raw); err != nil {
+		s.logger.Warn().
+			Str("webhook", name).
+			Str("client_ip", c.ClientIP()).
+			Err(err).
+			Msg("Webhook signature verification failed")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+		return
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON payload"})
+		return
+	}
+
+	event := webhook.EventType(hook.Provider, c.Request.Header, payload)
+	if len(hook.Events) > 0 && !containsString(hook.Events, event) {
+		// 未订阅的事件直接确认，避免来源方重试
+		c.JSON(http.StatusAccepted, gin.H{
+			"webhook": name,
+			"event":   event,
+			"status":  "ignored",
+		})
+		return
+	}
+
+	headers := make(map[string]interface{}, len(c.Request.Header))
+	for k := range c.Request.Header {
+		headers[k] = c.Request.Header.Get(k)
+	}
+
+	args, err := webhook.RenderArguments(hook.Arguments, map[string]interface{}{
+		"payload": payload,
+		"headers": headers,
+		"event":   event,
+		"webhook": name,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to render arguments: " + err.Error()})
+		return
+	}
+
+	arguments, err := json.Marshal(args)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid arguments: " + err.Error()})
+		return
+	}
+
+	s.logger.Info().
+		Str("webhook", name).
+		Str("event", event).
+		Str("tool", hook.Tool).
+		Msg("Webhook triggered tool call")
+
+	middleware.SetMCPRequestInfo(c, "webhook", hook.Tool, "webhook:"+name)
+	ctx := tools.WithClient(c.Request.Context(), "webhook:"+name)
+	result, err := s.toolMgr.CallTool(ctx, hook.Tool, arguments)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"webhook": name,
+			"event":   event,
+			"tool":    hook.Tool,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhook": name,
+		"event":   event,
+		"tool":    hook.Tool,
+		"result":  result,
+	})
+}
+
+// containsString 判断切片中是否包含指定字符串
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
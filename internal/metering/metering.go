@@ -0,0 +1,374 @@
+// Package metering 按租户与 API Key 汇总工具调用用量并按天持久化，供计费导出
+//
+// 每个 UTC 日期的汇总保存为存储目录下的 <日期>.json，启动时加载已有汇总继续累计。
+// 记录 API Key 时只保存其指纹，不保存原始 Key。
+package metering
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/internal/tools"
+)
+
+// DateLayout 汇总日期格式
+const DateLayout = "2006-01-02"
+
+// 默认配置
+const (
+	DefaultDir           = "data/metering"
+	DefaultFlushInterval = time.Minute
+)
+
+// Rollup 某个 UTC 日期内单个租户与 API Key 的用量汇总
+type Rollup struct {
+	Date             string  `json:"date,omitempty"`
+	Tenant           string  `json:"tenant,omitempty"`
+	APIKey           string  `json:"api_key,omitempty"` // API Key 指纹
+	Calls            int64   `json:"calls"`
+	Errors           int64   `json:"errors"`
+	ExecutionSeconds float64 `json:"execution_seconds"`
+	BytesIn          int64   `json:"bytes_in"`  // 工具参数大小
+	BytesOut         int64   `json:"bytes_out"` // 工具结果大小
+}
+
+// add 累加另一条汇总的用量
+func (r *Rollup) add(other Rollup) {
+	r.Calls += other.Calls
+	r.Errors += other.Errors
+	r.ExecutionSeconds += other.ExecutionSeconds
+	r.BytesIn += other.BytesIn
+	r.BytesOut += other.BytesOut
+}
+
+// rollupKey 汇总键
+type rollupKey struct {
+	date   string
+	tenant string
+	apiKey string
+}
+
+// Config 计量配置
+type Config struct {
+	Dir           string        // 持久化目录，默认 data/metering
+	FlushInterval time.Duration // 写入间隔，默认 1 分钟
+}
+
+// Meter 工具调用用量计量，作为工具调用观察者注册
+type Meter struct {
+	cfg     Config
+	mu      sync.Mutex
+	rollups map[rollupKey]*Rollup
+	dirty   map[string]bool // 有未写入变化的日期
+	logger  *logger.Logger
+	stopCh  chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	started bool
+}
+
+// New 创建用量计量
+func New(cfg Config, logger *logger.Logger) *Meter {
+	if cfg.Dir == "" {
+		cfg.Dir = DefaultDir
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultFlushInterval
+	}
+	return &Meter{
+		cfg:     cfg,
+		rollups: make(map[rollupKey]*Rollup),
+		dirty:   make(map[string]bool),
+		logger:  logger,
+		stopCh:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start 加载已持久化的汇总并启动定时写入
+func (m *Meter) Start() error {
+	dir, err := platform.NormalizePath(m.cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("invalid metering directory: %v", err)
+	}
+	m.cfg.Dir = dir
+
+	if err := os.MkdirAll(m.cfg.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create metering directory: %v", err)
+	}
+	if err := m.load(); err != nil {
+		return err
+	}
+
+	m.started = true
+	go m.flushLoop()
+	return nil
+}
+
+// apiKeyContextKey API Key 指纹上下文键
+type apiKeyContextKey struct{}
+
+// WithAPIKey 在上下文中记录请求使用的 API Key 指纹
+func WithAPIKey(ctx context.Context, apiKey string) context.Context {
+	if apiKey == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, apiKeyContextKey{}, Fingerprint(apiKey))
+}
+
+// APIKeyFromContext 从上下文中获取 API Key 指纹
+func APIKeyFromContext(ctx context.Context) string {
+	fingerprint, _ := ctx.Value(apiKeyContextKey{}).(string)
+	return fingerprint
+}
+
+// Fingerprint API Key 的 SHA-256 前 12 位十六进制，用于区分 Key 而不泄露 Key
+func Fingerprint(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Observe 实现 tools.CallObserver，按调用开始时间的 UTC 日期累计用量
+func (m *Meter) Observe(ctx context.Context, record tools.CallRecord) {
+	usage := Rollup{
+		Date:             record.StartedAt.UTC().Format(DateLayout),
+		Tenant:           record.Tenant,
+		APIKey:           APIKeyFromContext(ctx),
+		Calls:            1,
+		ExecutionSeconds: record.Duration.Seconds(),
+		BytesIn:          int64(len(record.Arguments)),
+	}
+	if record.Status == tools.CallStatusError {
+		usage.Errors = 1
+	}
+	if record.Result != nil {
+		if data, err := json.Marshal(record.Result); err == nil {
+			usage.BytesOut = int64(len(data))
+		}
+	}
+	m.Record(usage)
+}
+
+// Record 累加一条用量
+func (m *Meter) Record(usage Rollup) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := rollupKey{date: usage.Date, tenant: usage.Tenant, apiKey: usage.APIKey}
+	rollup, exists := m.rollups[key]
+	if !exists {
+		rollup = &Rollup{Date: usage.Date, Tenant: usage.Tenant, APIKey: usage.APIKey}
+		m.rollups[key] = rollup
+	}
+	rollup.add(usage)
+	m.dirty[usage.Date] = true
+}
+
+// Filter 用量查询条件，日期为闭区间，空字段不过滤
+type Filter struct {
+	From   string
+	To     string
+	Tenant string
+	APIKey string // API Key 指纹
+}
+
+// Query 按日期、租户与 API Key 排序返回符合条件的汇总
+func (m *Meter) Query(filter Filter) []Rollup {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var rollups []Rollup
+	for key, rollup := range m.rollups {
+		if filter.From != "" && key.date < filter.From {
+			continue
+		}
+		if filter.To != "" && key.date > filter.To {
+			continue
+		}
+		if filter.Tenant != "" && key.tenant != filter.Tenant {
+			continue
+		}
+		if filter.APIKey != "" && key.apiKey != filter.APIKey {
+			continue
+		}
+		rollups = append(rollups, *rollup)
+	}
+	sortRollups(rollups)
+	return rollups
+}
+
+// Total 汇总多条用量，结果不含日期、租户与 API Key
+func Total(rollups []Rollup) Rollup {
+	var total Rollup
+	for _, rollup := range rollups {
+		total.add(rollup)
+	}
+	return total
+}
+
+// csvHeader CSV 导出的列
+var csvHeader = []string{"date", "tenant", "api_key", "calls", "errors", "execution_seconds", "bytes_in", "bytes_out"}
+
+// WriteCSV 以 CSV 格式导出汇总，首行为列名
+func WriteCSV(w io.Writer, rollups []Rollup) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, rollup := range rollups {
+		if err := writer.Write([]string{
+			rollup.Date,
+			rollup.Tenant,
+			rollup.APIKey,
+			strconv.FormatInt(rollup.Calls, 10),
+			strconv.FormatInt(rollup.Errors, 10),
+			strconv.FormatFloat(rollup.ExecutionSeconds, 'f', 3, 64),
+			strconv.FormatInt(rollup.BytesIn, 10),
+			strconv.FormatInt(rollup.BytesOut, 10),
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Flush 写入有变化的日期汇总
+func (m *Meter) Flush() error {
+	m.mu.Lock()
+	byDate := make(map[string][]Rollup, len(m.dirty))
+	for date := range m.dirty {
+		byDate[date] = []Rollup{}
+	}
+	for key, rollup := range m.rollups {
+		if rollups, dirty := byDate[key.date]; dirty {
+			byDate[key.date] = append(rollups, *rollup)
+		}
+	}
+	m.dirty = make(map[string]bool)
+	m.mu.Unlock()
+
+	var firstErr error
+	for date, rollups := range byDate {
+		if err := m.writeDate(date, rollups); err != nil {
+			m.logger.Error().Err(err).Str("date", date).Msg("Failed to persist usage rollups")
+			// 下次写入时重试
+			m.mu.Lock()
+			m.dirty[date] = true
+			m.mu.Unlock()
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// writeDate 以临时文件加重命名的方式写入单日汇总
+func (m *Meter) writeDate(date string, rollups []Rollup) error {
+	sortRollups(rollups)
+	data, err := json.MarshalIndent(rollups, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(m.cfg.Dir, date+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// load 加载持久化目录中的汇总
+func (m *Meter) load() error {
+	entries, err := os.ReadDir(m.cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read metering directory: %v", err)
+	}
+
+	for _, entry := range entries {
+		date, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		if _, err := time.Parse(DateLayout, date); err != nil {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(m.cfg.Dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var rollups []Rollup
+		if err := json.Unmarshal(data, &rollups); err != nil {
+			m.logger.Warn().Str("file", entry.Name()).Err(err).Msg("Skipping corrupt usage rollup file")
+			continue
+		}
+		for _, rollup := range rollups {
+			rollup.Date = date
+			key := rollupKey{date: date, tenant: rollup.Tenant, apiKey: rollup.APIKey}
+			loaded := rollup
+			m.rollups[key] = &loaded
+		}
+	}
+	return nil
+}
+
+// flushLoop 定时写入，停止时最后写入一次
+func (m *Meter) flushLoop() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.Flush()
+		case <-m.stopCh:
+			m.Flush()
+			return
+		}
+	}
+}
+
+// Close 停止定时写入并写入剩余的汇总
+func (m *Meter) Close() error {
+	m.once.Do(func() {
+		close(m.stopCh)
+	})
+	if !m.started {
+		return nil
+	}
+	<-m.done
+	return nil
+}
+
+// sortRollups 按日期、租户与 API Key 排序
+func sortRollups(rollups []Rollup) {
+	sort.Slice(rollups, func(i, j int) bool {
+		a, b := rollups[i], rollups[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		return a.APIKey < b.APIKey
+	})
+}
@@ -0,0 +1,185 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// 列表条数限制
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// Cursor 分页位置
+//
+// 列表统一按 (Time, ID) 倒序排列，游标指向上一页的最后一条记录，
+// 下一页从严格位于其后的记录开始，插入新记录不会导致重复或遗漏。
+type Cursor struct {
+	Time time.Time `json:"t"`
+	ID   string    `json:"id"`
+}
+
+// Encode 编码为不透明的游标字符串
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(cursorPayload{Time: c.Time.UnixNano(), ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// After 判断排序键为 key 的记录是否位于游标之后
+func (c Cursor) After(key Cursor) bool {
+	if key.Time.Equal(c.Time) {
+		return key.ID < c.ID
+	}
+	return key.Time.Before(c.Time)
+}
+
+// cursorPayload 游标序列化格式
+type cursorPayload struct {
+	Time int64  `json:"t"`
+	ID   string `json:"id"`
+}
+
+// Decode 解码游标字符串
+func Decode(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.ID == "" {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &Cursor{Time: time.Unix(0, payload.Time).UTC(), ID: payload.ID}, nil
+}
+
+// Params 列表查询参数
+type Params struct {
+	Limit  int
+	Cursor *Cursor
+	Since  time.Time
+	Until  time.Time
+}
+
+// ParseParams 解析 limit、cursor、since、until 查询参数
+func ParseParams(query url.Values) (Params, error) {
+	params := Params{Limit: DefaultLimit}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return params, fmt.Errorf("invalid limit")
+		}
+		params.Limit = ClampLimit(limit)
+	}
+
+	if v := query.Get("cursor"); v != "" {
+		cursor, err := Decode(v)
+		if err != nil {
+			return params, err
+		}
+		params.Cursor = cursor
+	}
+
+	for _, field := range []struct {
+		name   string
+		target *time.Time
+	}{
+		{"since", &params.Since},
+		{"until", &params.Until},
+	} {
+		if v := query.Get(field.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return params, fmt.Errorf("invalid %s, expected RFC3339", field.name)
+			}
+			*field.target = t
+		}
+	}
+
+	return params, nil
+}
+
+// ClampLimit 限制单页条数
+func ClampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+	return limit
+}
+
+// Page 分页结果
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// Response 生成统一的列表响应，field 为列表字段名
+func (p Page[T]) Response(field string) map[string]interface{} {
+	resp := map[string]interface{}{
+		field:      p.Items,
+		"count":    len(p.Items),
+		"has_more": p.NextCursor != "",
+	}
+	if p.NextCursor != "" {
+		resp["next_cursor"] = p.NextCursor
+	}
+	return resp
+}
+
+// Trim 由多取一条的查询结果生成分页，items 需已按 (Time, ID) 倒序排列
+func Trim[T any](items []T, limit int, key func(T) Cursor) Page[T] {
+	limit = ClampLimit(limit)
+	if items == nil {
+		items = []T{}
+	}
+	if len(items) <= limit {
+		return Page[T]{Items: items}
+	}
+
+	items = items[:limit]
+	return Page[T]{
+		Items:      items,
+		NextCursor: key(items[limit-1]).Encode(),
+	}
+}
+
+// Paginate 对内存中的列表排序、按游标和时间范围过滤并分页
+func Paginate[T any](items []T, params Params, key func(T) Cursor) Page[T] {
+	sorted := make([]T, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return key(sorted[i]).After(key(sorted[j]))
+	})
+
+	limit := ClampLimit(params.Limit)
+	selected := make([]T, 0, limit+1)
+	for _, item := range sorted {
+		k := key(item)
+		if params.Cursor != nil && !params.Cursor.After(k) {
+			continue
+		}
+		if !params.Since.IsZero() && k.Time.Before(params.Since) {
+			continue
+		}
+		if !params.Until.IsZero() && !k.Time.Before(params.Until) {
+			continue
+		}
+		selected = append(selected, item)
+		if len(selected) > limit {
+			break
+		}
+	}
+
+	return Trim(selected, limit, key)
+}
@@ -0,0 +1,619 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: weave/v1/tools.proto
+
+// 工具服务的 gRPC 接口，与 HTTP 端点共用工具管理器、鉴权与连接数上限，
+// 供服务间以强类型客户端低延迟调用工具。
+
+package weavev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListToolsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 只列出该分类的工具，为空时列出全部
+	Category      string `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListToolsRequest) Reset() {
+	*x = ListToolsRequest{}
+	mi := &file_weave_v1_tools_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListToolsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListToolsRequest) ProtoMessage() {}
+
+func (x *ListToolsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weave_v1_tools_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListToolsRequest.ProtoReflect.Descriptor instead.
+func (*ListToolsRequest) Descriptor() ([]byte, []int) {
+	return file_weave_v1_tools_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListToolsRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+type ListToolsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tools         []*Tool                `protobuf:"bytes,1,rep,name=tools,proto3" json:"tools,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListToolsResponse) Reset() {
+	*x = ListToolsResponse{}
+	mi := &file_weave_v1_tools_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListToolsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListToolsResponse) ProtoMessage() {}
+
+func (x *ListToolsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weave_v1_tools_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListToolsResponse.ProtoReflect.Descriptor instead.
+func (*ListToolsResponse) Descriptor() ([]byte, []int) {
+	return file_weave_v1_tools_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListToolsResponse) GetTools() []*Tool {
+	if x != nil {
+		return x.Tools
+	}
+	return nil
+}
+
+// Tool 工具信息
+type Tool struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Category    string                 `protobuf:"bytes,3,opt,name=category,proto3" json:"category,omitempty"`
+	// 参数的 JSON Schema，工具未声明时为空
+	InputSchema   *structpb.Struct `protobuf:"bytes,4,opt,name=input_schema,json=inputSchema,proto3" json:"input_schema,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tool) Reset() {
+	*x = Tool{}
+	mi := &file_weave_v1_tools_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tool) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tool) ProtoMessage() {}
+
+func (x *Tool) ProtoReflect() protoreflect.Message {
+	mi := &file_weave_v1_tools_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tool.ProtoReflect.Descriptor instead.
+func (*Tool) Descriptor() ([]byte, []int) {
+	return file_weave_v1_tools_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Tool) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Tool) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Tool) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Tool) GetInputSchema() *structpb.Struct {
+	if x != nil {
+		return x.InputSchema
+	}
+	return nil
+}
+
+type CallToolRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 工具名或客户端别名
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// 工具参数
+	Arguments *structpb.Struct `protobuf:"bytes,2,opt,name=arguments,proto3" json:"arguments,omitempty"`
+	// JWE 紧凑序列化的加密参数，设置时忽略 arguments
+	EncryptedArguments string `protobuf:"bytes,3,opt,name=encrypted_arguments,json=encryptedArguments,proto3" json:"encrypted_arguments,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *CallToolRequest) Reset() {
+	*x = CallToolRequest{}
+	mi := &file_weave_v1_tools_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallToolRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallToolRequest) ProtoMessage() {}
+
+func (x *CallToolRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weave_v1_tools_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallToolRequest.ProtoReflect.Descriptor instead.
+func (*CallToolRequest) Descriptor() ([]byte, []int) {
+	return file_weave_v1_tools_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CallToolRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CallToolRequest) GetArguments() *structpb.Struct {
+	if x != nil {
+		return x.Arguments
+	}
+	return nil
+}
+
+func (x *CallToolRequest) GetEncryptedArguments() string {
+	if x != nil {
+		return x.EncryptedArguments
+	}
+	return ""
+}
+
+type CallToolResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Content []*Content             `protobuf:"bytes,1,rep,name=content,proto3" json:"content,omitempty"`
+	// 工具执行出错，内容为错误说明
+	IsError       bool `protobuf:"varint,2,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallToolResponse) Reset() {
+	*x = CallToolResponse{}
+	mi := &file_weave_v1_tools_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallToolResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallToolResponse) ProtoMessage() {}
+
+func (x *CallToolResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weave_v1_tools_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallToolResponse.ProtoReflect.Descriptor instead.
+func (*CallToolResponse) Descriptor() ([]byte, []int) {
+	return file_weave_v1_tools_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CallToolResponse) GetContent() []*Content {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *CallToolResponse) GetIsError() bool {
+	if x != nil {
+		return x.IsError
+	}
+	return false
+}
+
+// Content 调用结果内容
+type Content struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// text 或 image
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Text string `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	// image 内容的原始数据
+	Data          []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	MimeType      string `protobuf:"bytes,4,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Content) Reset() {
+	*x = Content{}
+	mi := &file_weave_v1_tools_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Content) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Content) ProtoMessage() {}
+
+func (x *Content) ProtoReflect() protoreflect.Message {
+	mi := &file_weave_v1_tools_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Content.ProtoReflect.Descriptor instead.
+func (*Content) Descriptor() ([]byte, []int) {
+	return file_weave_v1_tools_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Content) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Content) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Content) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Content) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+type CallToolStreamResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*CallToolStreamResponse_Chunk
+	//	*CallToolStreamResponse_Result
+	Event         isCallToolStreamResponse_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallToolStreamResponse) Reset() {
+	*x = CallToolStreamResponse{}
+	mi := &file_weave_v1_tools_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallToolStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallToolStreamResponse) ProtoMessage() {}
+
+func (x *CallToolStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weave_v1_tools_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallToolStreamResponse.ProtoReflect.Descriptor instead.
+func (*CallToolStreamResponse) Descriptor() ([]byte, []int) {
+	return file_weave_v1_tools_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CallToolStreamResponse) GetEvent() isCallToolStreamResponse_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *CallToolStreamResponse) GetChunk() *Chunk {
+	if x != nil {
+		if x, ok := x.Event.(*CallToolStreamResponse_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+func (x *CallToolStreamResponse) GetResult() *CallToolResponse {
+	if x != nil {
+		if x, ok := x.Event.(*CallToolStreamResponse_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+type isCallToolStreamResponse_Event interface {
+	isCallToolStreamResponse_Event()
+}
+
+type CallToolStreamResponse_Chunk struct {
+	// 工具推送的输出片段
+	Chunk *Chunk `protobuf:"bytes,1,opt,name=chunk,proto3,oneof"`
+}
+
+type CallToolStreamResponse_Result struct {
+	// 调用结果，总是最后一条消息
+	Result *CallToolResponse `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*CallToolStreamResponse_Chunk) isCallToolStreamResponse_Event() {}
+
+func (*CallToolStreamResponse_Result) isCallToolStreamResponse_Event() {}
+
+// Chunk 流式输出片段
+type Chunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Index         int32                  `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Chunk) Reset() {
+	*x = Chunk{}
+	mi := &file_weave_v1_tools_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Chunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Chunk) ProtoMessage() {}
+
+func (x *Chunk) ProtoReflect() protoreflect.Message {
+	mi := &file_weave_v1_tools_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Chunk.ProtoReflect.Descriptor instead.
+func (*Chunk) Descriptor() ([]byte, []int) {
+	return file_weave_v1_tools_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Chunk) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Chunk) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+var File_weave_v1_tools_proto protoreflect.FileDescriptor
+
+const file_weave_v1_tools_proto_rawDesc = "" +
+	"\n" +
+	"\x14weave/v1/tools.proto\x12\bweave.v1\x1a\x1cgoogle/protobuf/struct.proto\".\n" +
+	"\x10ListToolsRequest\x12\x1a\n" +
+	"\bcategory\x18\x01 \x01(\tR\bcategory\"9\n" +
+	"\x11ListToolsResponse\x12$\n" +
+	"\x05tools\x18\x01 \x03(\v2\x0e.weave.v1.ToolR\x05tools\"\x94\x01\n" +
+	"\x04Tool\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bcategory\x18\x03 \x01(\tR\bcategory\x12:\n" +
+	"\finput_schema\x18\x04 \x01(\v2\x17.google.protobuf.StructR\vinputSchema\"\x8d\x01\n" +
+	"\x0fCallToolRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x125\n" +
+	"\targuments\x18\x02 \x01(\v2\x17.google.protobuf.StructR\targuments\x12/\n" +
+	"\x13encrypted_arguments\x18\x03 \x01(\tR\x12encryptedArguments\"Z\n" +
+	"\x10CallToolResponse\x12+\n" +
+	"\acontent\x18\x01 \x03(\v2\x11.weave.v1.ContentR\acontent\x12\x19\n" +
+	"\bis_error\x18\x02 \x01(\bR\aisError\"b\n" +
+	"\aContent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\x12\x1b\n" +
+	"\tmime_type\x18\x04 \x01(\tR\bmimeType\"\x80\x01\n" +
+	"\x16CallToolStreamResponse\x12'\n" +
+	"\x05chunk\x18\x01 \x01(\v2\x0f.weave.v1.ChunkH\x00R\x05chunk\x124\n" +
+	"\x06result\x18\x02 \x01(\v2\x1a.weave.v1.CallToolResponseH\x00R\x06resultB\a\n" +
+	"\x05event\"7\n" +
+	"\x05Chunk\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\tR\acontent\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x05R\x05index2\xe7\x01\n" +
+	"\vToolService\x12D\n" +
+	"\tListTools\x12\x1a.weave.v1.ListToolsRequest\x1a\x1b.weave.v1.ListToolsResponse\x12A\n" +
+	"\bCallTool\x12\x19.weave.v1.CallToolRequest\x1a\x1a.weave.v1.CallToolResponse\x12O\n" +
+	"\x0eCallToolStream\x12\x19.weave.v1.CallToolRequest\x1a .weave.v1.CallToolStreamResponse0\x01B+Z)Weave-Toolkit/internal/pb/weavev1;weavev1b\x06proto3"
+
+var (
+	file_weave_v1_tools_proto_rawDescOnce sync.Once
+	file_weave_v1_tools_proto_rawDescData []byte
+)
+
+func file_weave_v1_tools_proto_rawDescGZIP() []byte {
+	file_weave_v1_tools_proto_rawDescOnce.Do(func() {
+		file_weave_v1_tools_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_weave_v1_tools_proto_rawDesc), len(file_weave_v1_tools_proto_rawDesc)))
+	})
+	return file_weave_v1_tools_proto_rawDescData
+}
+
+var file_weave_v1_tools_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_weave_v1_tools_proto_goTypes = []any{
+	(*ListToolsRequest)(nil),       // 0: weave.v1.ListToolsRequest
+	(*ListToolsResponse)(nil),      // 1: weave.v1.ListToolsResponse
+	(*Tool)(nil),                   // 2: weave.v1.Tool
+	(*CallToolRequest)(nil),        // 3: weave.v1.CallToolRequest
+	(*CallToolResponse)(nil),       // 4: weave.v1.CallToolResponse
+	(*Content)(nil),                // 5: weave.v1.Content
+	(*CallToolStreamResponse)(nil), // 6: weave.v1.CallToolStreamResponse
+	(*Chunk)(nil),                  // 7: weave.v1.Chunk
+	(*structpb.Struct)(nil),        // 8: google.protobuf.Struct
+}
+var file_weave_v1_tools_proto_depIdxs = []int32{
+	2, // 0: weave.v1.ListToolsResponse.tools:type_name -> weave.v1.Tool
+	8, // 1: weave.v1.Tool.input_schema:type_name -> google.protobuf.Struct
+	8, // 2: weave.v1.CallToolRequest.arguments:type_name -> google.protobuf.Struct
+	5, // 3: weave.v1.CallToolResponse.content:type_name -> weave.v1.Content
+	7, // 4: weave.v1.CallToolStreamResponse.chunk:type_name -> weave.v1.Chunk
+	4, // 5: weave.v1.CallToolStreamResponse.result:type_name -> weave.v1.CallToolResponse
+	0, // 6: weave.v1.ToolService.ListTools:input_type -> weave.v1.ListToolsRequest
+	3, // 7: weave.v1.ToolService.CallTool:input_type -> weave.v1.CallToolRequest
+	3, // 8: weave.v1.ToolService.CallToolStream:input_type -> weave.v1.CallToolRequest
+	1, // 9: weave.v1.ToolService.ListTools:output_type -> weave.v1.ListToolsResponse
+	4, // 10: weave.v1.ToolService.CallTool:output_type -> weave.v1.CallToolResponse
+	6, // 11: weave.v1.ToolService.CallToolStream:output_type -> weave.v1.CallToolStreamResponse
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_weave_v1_tools_proto_init() }
+func file_weave_v1_tools_proto_init() {
+	if File_weave_v1_tools_proto != nil {
+		return
+	}
+	file_weave_v1_tools_proto_msgTypes[6].OneofWrappers = []any{
+		(*CallToolStreamResponse_Chunk)(nil),
+		(*CallToolStreamResponse_Result)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_weave_v1_tools_proto_rawDesc), len(file_weave_v1_tools_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_weave_v1_tools_proto_goTypes,
+		DependencyIndexes: file_weave_v1_tools_proto_depIdxs,
+		MessageInfos:      file_weave_v1_tools_proto_msgTypes,
+	}.Build()
+	File_weave_v1_tools_proto = out.File
+	file_weave_v1_tools_proto_goTypes = nil
+	file_weave_v1_tools_proto_depIdxs = nil
+}
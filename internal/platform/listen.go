@@ -0,0 +1,61 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// UnixSocketPrefix 监听地址使用该前缀时监听 unix socket，如 unix:/run/mcp.sock
+const UnixSocketPrefix = "unix:"
+
+// ErrUnixSocketUnsupported 当前平台不支持 unix socket 监听
+var ErrUnixSocketUnsupported = errors.New("unix socket listeners are not supported on this platform")
+
+// SupportsUnixSockets 当前平台是否支持 unix socket 监听
+func SupportsUnixSockets() bool {
+	return unixSockets
+}
+
+// Listen 按地址创建监听，空地址与 net/http 一致监听 :http
+func Listen(address string) (net.Listener, error) {
+	if !strings.HasPrefix(address, UnixSocketPrefix) {
+		if address == "" {
+			address = ":http"
+		}
+		return net.Listen("tcp", address)
+	}
+
+	if !unixSockets {
+		return nil, fmt.Errorf("%w: %s", ErrUnixSocketUnsupported, address)
+	}
+
+	path, err := NormalizePath(strings.TrimPrefix(address, UnixSocketPrefix))
+	if err != nil {
+		return nil, err
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	return net.Listen("unix", path)
+}
+
+// removeStaleSocket 删除上次异常退出遗留的 socket 文件，普通文件不会被删除
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat socket %s: %v", path, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("socket path %s exists and is not a socket", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale socket %s: %v", path, err)
+	}
+	return nil
+}
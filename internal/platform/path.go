@@ -0,0 +1,69 @@
+// Package platform 封装与操作系统相关的路径与监听处理
+//
+// 配置中的路径统一使用正斜杠书写（如 data/jobs），在各平台上转换为本地分隔符；
+// Windows 下路径比较不区分大小写，且不支持 unix socket 监听。
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrOutsideRoot 路径超出根目录
+var ErrOutsideRoot = errors.New("path escapes root directory")
+
+// NormalizePath 将配置中的路径转换为本地绝对路径
+//
+// 支持 ~ 表示用户主目录，正斜杠转换为本地分隔符。Windows 下超过 MAX_PATH 的
+// 绝对路径由 os 包自动添加 \\?\ 前缀，因此这里始终返回绝对路径。
+func NormalizePath(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("empty path")
+	}
+
+	path = filepath.FromSlash(path)
+	if path == "~" || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve home directory: %v", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %v", path, err)
+	}
+	return abs, nil
+}
+
+// Confine 将相对路径限定在根目录内
+//
+// name 必须是本地相对路径：不能为绝对路径、不能以 .. 跳出根目录，
+// Windows 下也不能带盘符、UNC 前缀或 NUL、CON 等保留设备名。
+func Confine(root, name string) (string, error) {
+	local := filepath.FromSlash(name)
+	if !filepath.IsLocal(local) {
+		return "", fmt.Errorf("%w: %s", ErrOutsideRoot, name)
+	}
+	return filepath.Join(root, local), nil
+}
+
+// IsWithin 判断 path 是否位于 root 目录内（含 root 本身）
+func IsWithin(root, path string) bool {
+	root = filepath.Clean(root)
+	path = filepath.Clean(path)
+	if caseInsensitivePaths {
+		root = strings.ToLower(root)
+		path = strings.ToLower(path)
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || filepath.IsLocal(rel)
+}
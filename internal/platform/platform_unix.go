@@ -0,0 +1,11 @@
+//go:build !windows
+
+package platform
+
+const (
+	// caseInsensitivePaths 路径比较是否忽略大小写
+	caseInsensitivePaths = false
+
+	// unixSockets 是否支持 unix socket 监听
+	unixSockets = true
+)
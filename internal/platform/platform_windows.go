@@ -0,0 +1,11 @@
+//go:build windows
+
+package platform
+
+const (
+	// caseInsensitivePaths NTFS 默认不区分大小写
+	caseInsensitivePaths = true
+
+	// unixSockets Windows 下不提供 unix socket 监听
+	unixSockets = false
+)
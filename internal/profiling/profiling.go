@@ -0,0 +1,184 @@
+// Package profiling 持续性能剖析
+//
+// 按固定周期连续采集 CPU profile，并在每个周期结束时附带一份堆 profile，
+// 以 pprof 格式推送到 Pyroscope 兼容的 /ingest 接口。工具执行期间的 pprof 标签
+// （tool、category）随 CPU 样本一并上报，可按工具定位热点。
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"Weave-Toolkit/internal/logger"
+)
+
+// 默认配置
+const (
+	DefaultAppName  = "weave-toolkit"
+	defaultInterval = 15 * time.Second
+	uploadTimeout   = 10 * time.Second
+	cpuSampleRate   = 100 // runtime/pprof 默认采样频率（Hz）
+)
+
+// Config 持续剖析配置
+type Config struct {
+	ServerURL string            // Pyroscope 服务地址，如 http://pyroscope:4040
+	AppName   string            // 应用名称
+	Tags      map[string]string // 附加到所有 profile 的静态标签
+	Interval  time.Duration     // 采集与上报周期
+	AuthToken string            // 可选的 Bearer token
+}
+
+// Shipper 周期性采集并上报 profile
+type Shipper struct {
+	cfg    Config
+	client *http.Client
+	logger *logger.Logger
+}
+
+// NewShipper 创建 profile 上报器
+func NewShipper(cfg Config, logger *logger.Logger) *Shipper {
+	if cfg.AppName == "" {
+		cfg.AppName = DefaultAppName
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	cfg.ServerURL = strings.TrimRight(cfg.ServerURL, "/")
+	return &Shipper{
+		cfg:    cfg,
+		client: &http.Client{Timeout: uploadTimeout},
+		logger: logger,
+	}
+}
+
+// ParseTags 解析 "k1=v1,k2=v2" 格式的标签
+func ParseTags(s string) map[string]string {
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && key != "" {
+			tags[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return tags
+}
+
+// Run 持续采集直到上下文取消，退出前上报最后一个周期
+//
+// 运行期间进程内的 CPU profile 被占用，/debug/pprof/profile 会返回错误。
+func (s *Shipper) Run(ctx context.Context) error {
+	s.logger.Info().
+		Str("server", s.cfg.ServerURL).
+		Str("app", s.cfg.AppName).
+		Dur("interval", s.cfg.Interval).
+		Msg("Continuous profiling started")
+
+	for {
+		var cpu bytes.Buffer
+		from := time.Now()
+		if err := pprof.StartCPUProfile(&cpu); err != nil {
+			return fmt.Errorf("failed to start CPU profile: %v", err)
+		}
+
+		timer := time.NewTimer(s.cfg.Interval)
+		stopped := false
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			stopped = true
+		case <-timer.C:
+		}
+		pprof.StopCPUProfile()
+		until := time.Now()
+
+		// 退出时上下文已取消，使用独立的超时完成最后一次上报
+		uploadCtx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+		s.ship(uploadCtx, "cpu", from, until, cpu.Bytes())
+		var heap bytes.Buffer
+		if err := pprof.Lookup("heap").WriteTo(&heap, 0); err == nil {
+			s.ship(uploadCtx, "inuse_space", from, until, heap.Bytes())
+		}
+		cancel()
+
+		if stopped {
+			s.logger.Info().Msg("Continuous profiling stopped")
+			return nil
+		}
+	}
+}
+
+// ship 上报一个 profile，失败只记录日志，不影响下一个周期
+func (s *Shipper) ship(ctx context.Context, profileType string, from, until time.Time, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	if err := s.upload(ctx, profileType, from, until, data); err != nil {
+		s.logger.Warn().Str("profile", profileType).Err(err).Msg("Failed to upload profile")
+	}
+}
+
+// upload 以 multipart 表单上传 pprof 数据到 Pyroscope /ingest
+func (s *Shipper) upload(ctx context.Context, profileType string, from, until time.Time, data []byte) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("profile", "profile.pprof")
+	if err != nil {
+		return err
+	}
+	part.Write(data)
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("name", s.seriesName(profileType))
+	query.Set("from", strconv.FormatInt(from.Unix(), 10))
+	query.Set("until", strconv.FormatInt(until.Unix(), 10))
+	query.Set("format", "pprof")
+	query.Set("spyName", "gospy")
+	query.Set("sampleRate", strconv.Itoa(cpuSampleRate))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.ServerURL+"/ingest?"+query.Encode(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if s.cfg.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.AuthToken)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("ingest returned %s", resp.Status)
+	}
+	return nil
+}
+
+// seriesName Pyroscope 序列名，格式为 app.type{k=v,...}，标签按名称排序
+func (s *Shipper) seriesName(profileType string) string {
+	keys := make([]string, 0, len(s.cfg.Tags))
+	for key := range s.cfg.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + s.cfg.Tags[key]
+	}
+	return fmt.Sprintf("%s.%s{%s}", s.cfg.AppName, profileType, strings.Join(pairs, ","))
+}
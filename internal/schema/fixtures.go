@@ -0,0 +1,290 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Fixture 示例参数
+type Fixture struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// Fixtures 一个工具的全部示例参数
+type Fixtures struct {
+	Tool    string    `json:"tool"`
+	Valid   []Fixture `json:"valid"`
+	Invalid []Fixture `json:"invalid"`
+}
+
+// sampleString 未指定枚举与默认值时字符串属性的示例值
+const sampleString = "sample"
+
+// GenerateFixtures 根据对象 Schema 生成合法参数与边界非法参数
+//
+// 合法参数包括仅含必填属性、包含全部属性、每个枚举值以及各项数值与长度限制的边界值；
+// 非法参数覆盖缺少必填属性、类型错误、枚举外取值、越过边界和未声明的属性。
+// 属性按名称排序处理，相同 Schema 的生成结果保持稳定。
+func GenerateFixtures(tool string, s *Schema) (*Fixtures, error) {
+	if s == nil || s.Type != TypeObject {
+		return nil, fmt.Errorf("tool %s: fixtures require an object schema", tool)
+	}
+
+	f := &Fixtures{Tool: tool}
+	add := func(list *[]Fixture, name string, args interface{}) {
+		data, _ := json.Marshal(args)
+		*list = append(*list, Fixture{Name: name, Arguments: data})
+	}
+
+	minimal := make(map[string]interface{})
+	for _, name := range s.Required {
+		if prop, ok := s.Properties[name]; ok {
+			minimal[name] = sampleValue(prop)
+		}
+	}
+	full := make(map[string]interface{})
+	for _, name := range sortedKeys(s.Properties) {
+		full[name] = sampleValue(s.Properties[name])
+	}
+	with := func(name string, value interface{}) map[string]interface{} {
+		args := make(map[string]interface{}, len(full))
+		for k, v := range full {
+			args[k] = v
+		}
+		args[name] = value
+		return args
+	}
+
+	add(&f.Valid, "required properties only", minimal)
+	add(&f.Valid, "all properties", full)
+
+	add(&f.Invalid, "arguments not an object", []interface{}{})
+	for _, name := range s.Required {
+		args := make(map[string]interface{}, len(full))
+		for k, v := range full {
+			if k != name {
+				args[k] = v
+			}
+		}
+		add(&f.Invalid, fmt.Sprintf("missing required %s", name), args)
+	}
+	if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+		add(&f.Invalid, "unexpected property", with("unexpected_property", true))
+	}
+
+	for _, name := range sortedKeys(s.Properties) {
+		prop := s.Properties[name]
+		for _, boundary := range validBoundaries(prop) {
+			add(&f.Valid, fmt.Sprintf("%s %s", name, boundary.name), with(name, boundary.value))
+		}
+		for _, boundary := range invalidBoundaries(prop) {
+			add(&f.Invalid, fmt.Sprintf("%s %s", name, boundary.name), with(name, boundary.value))
+		}
+	}
+
+	return f, nil
+}
+
+// boundary 带说明的边界取值
+type boundary struct {
+	name  string
+	value interface{}
+}
+
+// validBoundaries 属性的合法边界取值
+func validBoundaries(s *Schema) []boundary {
+	var values []boundary
+	for _, v := range s.Enum {
+		values = append(values, boundary{fmt.Sprintf("= %v", v), v})
+	}
+	if len(s.Enum) > 0 {
+		return values
+	}
+
+	switch s.Type {
+	case TypeNumber, TypeInteger:
+		if s.Minimum != nil {
+			values = append(values, boundary{"at minimum", *s.Minimum})
+		}
+		if s.Maximum != nil {
+			values = append(values, boundary{"at maximum", *s.Maximum})
+		}
+	case TypeString:
+		if s.MinLength != nil {
+			values = append(values, boundary{"at min length", strings.Repeat("a", *s.MinLength)})
+		}
+		if s.MaxLength != nil {
+			values = append(values, boundary{"at max length", strings.Repeat("a", *s.MaxLength)})
+		}
+	case TypeArray:
+		if s.MinItems != nil {
+			values = append(values, boundary{"at min items", sampleItems(s, *s.MinItems)})
+		}
+		if s.MaxItems != nil {
+			values = append(values, boundary{"at max items", sampleItems(s, *s.MaxItems)})
+		}
+	}
+	return values
+}
+
+// invalidBoundaries 属性的非法取值：类型错误、枚举外取值与越界
+func invalidBoundaries(s *Schema) []boundary {
+	var values []boundary
+	if s.Type != "" {
+		values = append(values, boundary{"wrong type", wrongTypeValue(s.Type)})
+	}
+	if len(s.Enum) > 0 {
+		values = append(values, boundary{"not in enum", notInEnum(s)})
+		return values
+	}
+
+	switch s.Type {
+	case TypeNumber, TypeInteger:
+		if s.Minimum != nil {
+			values = append(values, boundary{"below minimum", *s.Minimum - 1})
+		}
+		if s.Maximum != nil {
+			values = append(values, boundary{"above maximum", *s.Maximum + 1})
+		}
+		if s.Type == TypeInteger {
+			values = append(values, boundary{"not an integer", sampleNumber(s) + 0.5})
+		}
+	case TypeString:
+		if s.MinLength != nil && *s.MinLength > 0 {
+			values = append(values, boundary{"below min length", strings.Repeat("a", *s.MinLength-1)})
+		}
+		if s.MaxLength != nil {
+			values = append(values, boundary{"above max length", strings.Repeat("a", *s.MaxLength+1)})
+		}
+	case TypeArray:
+		if s.MinItems != nil && *s.MinItems > 0 {
+			values = append(values, boundary{"below min items", sampleItems(s, *s.MinItems-1)})
+		}
+		if s.MaxItems != nil {
+			values = append(values, boundary{"above max items", sampleItems(s, *s.MaxItems+1)})
+		}
+		if s.Items != nil && s.Items.Type != "" {
+			count := 1
+			if s.MinItems != nil && *s.MinItems > count {
+				count = *s.MinItems
+			}
+			items := sampleItems(s, count)
+			items[0] = wrongTypeValue(s.Items.Type)
+			values = append(values, boundary{"item wrong type", items})
+		}
+	}
+	return values
+}
+
+// sampleValue 满足 Schema 约束的示例值
+func sampleValue(s *Schema) interface{} {
+	if s.Default != nil {
+		return s.Default
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[0]
+	}
+
+	switch s.Type {
+	case "":
+		// 未声明类型时接受任意值，使用字符串作为示例
+		return sampleString
+	case TypeString:
+		value := sampleString
+		if s.MinLength != nil && len(value) < *s.MinLength {
+			value += strings.Repeat("a", *s.MinLength-len(value))
+		}
+		if s.MaxLength != nil && len(value) > *s.MaxLength {
+			value = value[:*s.MaxLength]
+		}
+		return value
+	case TypeNumber, TypeInteger:
+		return sampleNumber(s)
+	case TypeBoolean:
+		return true
+	case TypeArray:
+		count := 1
+		if s.MinItems != nil {
+			count = *s.MinItems
+		}
+		return sampleItems(s, count)
+	case TypeObject:
+		obj := make(map[string]interface{})
+		for _, name := range s.Required {
+			if prop, ok := s.Properties[name]; ok {
+				obj[name] = sampleValue(prop)
+			}
+		}
+		return obj
+	}
+	return nil
+}
+
+// sampleNumber 满足上下限的示例数值
+func sampleNumber(s *Schema) float64 {
+	switch {
+	case s.Minimum != nil:
+		return *s.Minimum
+	case s.Maximum != nil && *s.Maximum < 1:
+		return *s.Maximum
+	}
+	return 1
+}
+
+// sampleItems 生成指定数量的数组元素
+func sampleItems(s *Schema, count int) []interface{} {
+	items := make([]interface{}, count)
+	for i := range items {
+		if s.Items != nil {
+			items[i] = sampleValue(s.Items)
+		} else {
+			items[i] = sampleString
+		}
+	}
+	return items
+}
+
+// wrongTypeValue 与声明类型不符的值
+func wrongTypeValue(schemaType string) interface{} {
+	switch schemaType {
+	case TypeString:
+		return 12345
+	case TypeNumber, TypeInteger:
+		return "not-a-number"
+	case TypeBoolean:
+		return "true"
+	case TypeArray:
+		return "not-an-array"
+	}
+	return "not-an-object"
+}
+
+// notInEnum 枚举之外的同类型取值
+func notInEnum(s *Schema) interface{} {
+	if s.Type == TypeNumber || s.Type == TypeInteger {
+		max := 0.0
+		for _, v := range s.Enum {
+			if n, ok := normalizeNumber(v).(float64); ok && n > max {
+				max = n
+			}
+		}
+		return max + 1
+	}
+	return "__not_in_enum__"
+}
+
+// Marshal 以缩进格式编码，作为 testdata 中的文件内容
+func (f *Fixtures) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// FixtureFile 工具示例参数在 testdata 目录下的文件名
+func FixtureFile(tool string) string {
+	return tool + ".json"
+}
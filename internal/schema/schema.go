@@ -0,0 +1,255 @@
+// Package schema 工具参数的 JSON Schema 描述与校验
+//
+// 仅支持工具参数常用的子集：type、properties、required、enum、
+// minimum/maximum、minLength/maxLength、items、minItems/maxItems 与 additionalProperties。
+// 可选属性取值为 null 时视同未提供，兼容将空切片编码为 null 的客户端。
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// JSON Schema 类型
+const (
+	TypeObject  = "object"
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+	TypeArray   = "array"
+)
+
+// Schema JSON Schema 子集
+type Schema struct {
+	Type                 string             `json:"type,omitempty"` // 为空时接受任意 JSON 值
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+// Object 创建对象类型的 Schema
+func Object(properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: TypeObject, Properties: properties, Required: required}
+}
+
+// Closed 不允许未声明的属性
+func (s *Schema) Closed() *Schema {
+	closed := false
+	s.AdditionalProperties = &closed
+	return s
+}
+
+// Float 返回数值指针，便于设置 Minimum/Maximum
+func Float(v float64) *float64 {
+	return &v
+}
+
+// Int 返回整数指针，便于设置长度限制
+func Int(v int) *int {
+	return &v
+}
+
+// ValidationError 参数校验错误
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidateJSON 解析 JSON 参数并按 Schema 校验
+func (s *Schema) ValidateJSON(data json.RawMessage) error {
+	var value interface{}
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return &ValidationError{Message: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	return s.Validate(value)
+}
+
+// Validate 按 Schema 校验已解码的 JSON 值
+func (s *Schema) Validate(value interface{}) error {
+	return s.validate("", value)
+}
+
+func (s *Schema) validate(path string, value interface{}) error {
+	fail := func(format string, args ...interface{}) error {
+		return &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if !matchesType(s.Type, value) {
+		return fail("expected %s, got %s", s.Type, typeName(value))
+	}
+
+	if len(s.Enum) > 0 && !containsValue(s.Enum, value) {
+		return fail("must be one of %s", formatEnum(s.Enum))
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			return fail("must be >= %v", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			return fail("must be <= %v", *s.Maximum)
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			return fail("length must be >= %d", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return fail("length must be <= %d", *s.MaxLength)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return fail("must contain at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return fail("must contain at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if value, exists := v[name]; !exists || value == nil {
+				return fail("missing required property %q", name)
+			}
+		}
+		for _, name := range sortedKeys(v) {
+			if v[name] == nil {
+				continue
+			}
+			prop, declared := s.Properties[name]
+			if !declared {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return fail("unexpected property %q", name)
+				}
+				continue
+			}
+			if err := prop.validate(joinPath(path, name), v[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchesType 判断值是否符合类型，未声明类型时接受任意值
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "":
+		return true
+	case TypeObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case TypeString:
+		_, ok := value.(string)
+		return ok
+	case TypeNumber:
+		_, ok := value.(float64)
+		return ok
+	case TypeInteger:
+		v, ok := value.(float64)
+		return ok && v == math.Trunc(v)
+	case TypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case TypeArray:
+		_, ok := value.([]interface{})
+		return ok
+	}
+	return false
+}
+
+// typeName JSON 值的类型名称
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return TypeObject
+	case string:
+		return TypeString
+	case float64:
+		return TypeNumber
+	case bool:
+		return TypeBoolean
+	case []interface{}:
+		return TypeArray
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// containsValue 判断枚举中是否包含该值（数值统一按 float64 比较）
+func containsValue(enum []interface{}, value interface{}) bool {
+	for _, candidate := range enum {
+		if normalizeNumber(candidate) == normalizeNumber(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeNumber(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return v
+}
+
+func formatEnum(enum []interface{}) string {
+	parts := make([]string, len(enum))
+	for i, v := range enum {
+		parts[i] = fmt.Sprintf("%v", v)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// sortedKeys 按名称排序的属性名，保证校验与生成结果稳定
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,237 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// ErrToolNameConflict 工具名不合法，或与已注册工具的名称、命名空间冲突
+var ErrToolNameConflict = werrors.New(werrors.KindInvalidParams, "tool name conflict")
+
+// toolNamePattern 工具名由 "." 分隔的若干段组成，每段为字母、数字、下划线或连字符
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// aliasTable 工具别名表
+type aliasTable struct {
+	global     map[string]string                 // 别名 -> 工具名
+	clients    map[string]map[string]string      // 客户端 -> 别名 -> 工具名
+	reverse    map[string]map[string]string      // 客户端 -> 工具名 -> 别名
+	namespaces map[string]config.NamespaceConfig // 命名空间 -> 设置
+	namespaced map[string]string                 // 由命名空间设置生成的名称 -> 工具名
+	ambiguous  map[string][]string               // 对应多个工具而不生效的名称 -> 工具名
+}
+
+// newAliasTable 创建别名表
+func newAliasTable(global map[string]string, clients map[string]map[string]string, namespaces map[string]config.NamespaceConfig) *aliasTable {
+	table := &aliasTable{
+		global:     make(map[string]string),
+		clients:    make(map[string]map[string]string),
+		reverse:    make(map[string]map[string]string),
+		namespaces: make(map[string]config.NamespaceConfig),
+		namespaced: make(map[string]string),
+		ambiguous:  make(map[string][]string),
+	}
+	for namespace, settings := range namespaces {
+		table.namespaces[namespace] = settings
+	}
+
+	for alias, canonical := range global {
+		table.global[alias] = canonical
+	}
+
+	for client, aliases := range clients {
+		table.clients[client] = make(map[string]string)
+		table.reverse[client] = make(map[string]string)
+
+		// 排序保证同一工具配置多个别名时选择稳定
+		names := make([]string, 0, len(aliases))
+		for alias := range aliases {
+			names = append(names, alias)
+		}
+		sort.Strings(names)
+
+		for _, alias := range names {
+			canonical := aliases[alias]
+			table.clients[client][alias] = canonical
+			if _, exists := table.reverse[client][canonical]; !exists {
+				table.reverse[client][canonical] = alias
+			}
+		}
+	}
+
+	return table
+}
+
+// validateAliases 校验别名配置，移除与已注册工具冲突或指向未知工具的别名（调用方需持有写锁）
+func (tm *ToolManager) validateAliases() {
+	check := func(scope string, aliases map[string]string) {
+		for alias, canonical := range aliases {
+			if tm.findToolLocked(alias) != nil {
+				tm.logger.Warn().
+					Str("alias", alias).
+					Str("scope", scope).
+					Msg("Alias collides with registered tool name, ignoring")
+				delete(aliases, alias)
+				continue
+			}
+			if tm.findToolLocked(canonical) == nil {
+				tm.logger.Warn().
+					Str("alias", alias).
+					Str("tool", canonical).
+					Str("scope", scope).
+					Msg("Alias refers to unknown tool")
+			}
+		}
+	}
+
+	check("global", tm.aliases.global)
+	for client, aliases := range tm.aliases.clients {
+		check("client:"+client, aliases)
+		for canonical, alias := range tm.aliases.reverse[client] {
+			if _, exists := aliases[alias]; !exists {
+				delete(tm.aliases.reverse[client], canonical)
+			}
+		}
+	}
+	tm.resolveNamespacesLocked()
+}
+
+// resolveNamespacesLocked 按命名空间设置为已注册工具生成附加名称（调用方需持有写锁）
+//
+// 与已注册工具或全局别名重名的名称不生效；同一名称对应多个工具时记录为有歧义，调用时返回候选工具。
+func (tm *ToolManager) resolveNamespacesLocked() {
+	candidates := make(map[string][]string)
+	for _, categoryMgr := range tm.categories {
+		for name := range categoryMgr.tools {
+			namespace, short := splitToolName(name)
+			settings, ok := tm.aliases.namespaces[namespace]
+			if namespace == "" || !ok {
+				continue
+			}
+			if settings.Alias != "" {
+				candidates[settings.Alias+"."+short] = append(candidates[settings.Alias+"."+short], name)
+			}
+			if settings.ShortNames {
+				candidates[short] = append(candidates[short], name)
+			}
+		}
+	}
+
+	tm.aliases.namespaced = make(map[string]string)
+	tm.aliases.ambiguous = make(map[string][]string)
+	for name, tools := range candidates {
+		sort.Strings(tools)
+		switch {
+		case tm.findToolLocked(name) != nil:
+			tm.logger.Warn().Str("name", name).Strs("tools", tools).Msg("Namespace name collides with registered tool name, ignoring")
+		case tm.aliases.global[name] != "":
+			// 显式配置的别名优先
+		case len(tools) > 1:
+			tm.logger.Warn().Str("name", name).Strs("tools", tools).Msg("Namespace name is ambiguous, ignoring")
+			tm.aliases.ambiguous[name] = tools
+		default:
+			tm.aliases.namespaced[name] = tools[0]
+		}
+	}
+}
+
+// checkToolNameLocked 检查待注册的工具名：格式合法，未在其他分类注册，且不与已注册工具的命名空间冲突（调用方需持有锁）
+func (tm *ToolManager) checkToolNameLocked(name string, category ToolCategory) error {
+	if !toolNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: invalid tool name %q", ErrToolNameConflict, name)
+	}
+	for cat, categoryMgr := range tm.categories {
+		for registered := range categoryMgr.tools {
+			switch {
+			case registered == name && cat != category:
+				return fmt.Errorf("%w: %s is already registered in category %s", ErrToolNameConflict, name, cat)
+			case strings.HasPrefix(registered, name+"."):
+				return fmt.Errorf("%w: %s is the namespace of registered tool %s", ErrToolNameConflict, name, registered)
+			case strings.HasPrefix(name, registered+"."):
+				return fmt.Errorf("%w: namespace of %s is registered tool %s", ErrToolNameConflict, name, registered)
+			}
+		}
+	}
+	return nil
+}
+
+// splitToolName 将工具名拆分为命名空间与短名称，不带命名空间时命名空间为空
+func splitToolName(name string) (namespace, short string) {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
+// toolNotFound 工具不存在时的错误，名称有歧义时列出候选工具
+func (tm *ToolManager) toolNotFound(name string) error {
+	tm.mu.RLock()
+	tools := tm.aliases.ambiguous[name]
+	tm.mu.RUnlock()
+	if len(tools) > 0 {
+		return fmt.Errorf("%w: %s is ambiguous, use one of: %s", ErrToolNotFound, name, strings.Join(tools, ", "))
+	}
+	return fmt.Errorf("%w: %s", ErrToolNotFound, name)
+}
+
+// isToolNameConflict 判断注册失败是否由工具名冲突引起
+func isToolNameConflict(err error) bool {
+	return errors.Is(err, ErrToolNameConflict)
+}
+
+// findToolLocked 按名称查找已注册工具，不检查分类是否启用（调用方需持有锁）
+func (tm *ToolManager) findToolLocked(name string) Tool {
+	for _, categoryMgr := range tm.categories {
+		if t, exists := categoryMgr.tools[name]; exists {
+			return t
+		}
+	}
+	return nil
+}
+
+// ResolveAlias 将客户端使用的工具名解析为规范工具名
+//
+// 解析顺序：已注册工具名 > 客户端别名 > 全局别名 > 命名空间别名与短名称。未匹配时原样返回。
+func (tm *ToolManager) ResolveAlias(client, name string) string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if tm.findToolLocked(name) != nil {
+		return name
+	}
+
+	if aliases, ok := tm.aliases.clients[client]; ok {
+		if canonical, ok := aliases[name]; ok {
+			return canonical
+		}
+	}
+
+	if canonical, ok := tm.aliases.global[name]; ok {
+		return canonical
+	}
+
+	if canonical, ok := tm.aliases.namespaced[name]; ok {
+		return canonical
+	}
+
+	return name
+}
+
+// ExposedName 获取工具对指定客户端展示的名称
+func (tm *ToolManager) ExposedName(client, canonical string) string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if reverse, ok := tm.aliases.reverse[client]; ok {
+		if alias, ok := reverse[canonical]; ok {
+			return alias
+		}
+	}
+	return canonical
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// ErrApprovalRequired 工具调用需要人工审批，只能通过 tools/call 提交后等待管理员批准
+var ErrApprovalRequired = werrors.New(werrors.KindUnauthorized, "tool call requires approval")
+
+// defaultApprovalTimeout 等待审批的默认时长
+const defaultApprovalTimeout = 5 * time.Minute
+
+// approvedContextKey 已批准调用的上下文键
+type approvedContextKey struct{}
+
+// WithApproved 标记调用已获管理员批准，ToolManager 不再要求审批
+func WithApproved(ctx context.Context) context.Context {
+	return context.WithValue(ctx, approvedContextKey{}, true)
+}
+
+// approvedFromContext 调用是否已获批准
+func approvedFromContext(ctx context.Context) bool {
+	approved, _ := ctx.Value(approvedContextKey{}).(bool)
+	return approved
+}
+
+// RequiresApproval 工具调用是否需要人工审批，name 可以是别名
+//
+// 工具不存在或不在租户的工具目录中时返回 false，由调用本身返回工具不存在错误。
+func (tm *ToolManager) RequiresApproval(ctx context.Context, name string) bool {
+	name = tm.ResolveAlias("", name)
+	entry, found := tm.lookupTool(name)
+	if !found || !TenantFromContext(ctx).Allows(name, entry.category, entry.tags) {
+		return false
+	}
+	return entry.approval
+}
+
+// ApprovalTimeout 等待审批的时长，超时后调用自动被拒绝
+func (tm *ToolManager) ApprovalTimeout() time.Duration {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	if tm.approvals.Timeout > 0 {
+		return time.Duration(tm.approvals.Timeout) * time.Second
+	}
+	return defaultApprovalTimeout
+}
+
+// requiresApprovalLocked 按 approvals 配置判断工具是否需要审批（调用方需持有锁）
+func (tm *ToolManager) requiresApprovalLocked(name string, tags []string) bool {
+	for _, tool := range tm.approvals.Tools {
+		if tool == name {
+			return true
+		}
+	}
+	for _, tag := range tm.approvals.Tags {
+		if hasTag(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
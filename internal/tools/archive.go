@@ -0,0 +1,711 @@
+package tools
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/internal/schema"
+)
+
+// archive 默认限制
+const (
+	defaultArchiveMaxArchiveBytes = 512 << 20
+	defaultArchiveMaxExtractBytes = 1 << 30
+	defaultArchiveMaxEntries      = 10000
+)
+
+// 归档格式
+const (
+	ArchiveFormatZip   = "zip"
+	ArchiveFormatTarGz = "tar.gz"
+	ArchiveFormatTar   = "tar"
+)
+
+// ErrArchiveQuota 归档或解压内容超过配置的大小或条目数上限
+var ErrArchiveQuota = errors.New("archive quota exceeded")
+
+// ArchiveTool 归档工具
+//
+// 在配置的根目录内创建、列出与解压 zip、tar.gz 与 tar 归档。所有路径相对于根目录，
+// 文件访问经过 os.Root，符号链接不能指向根目录之外；解压时拒绝绝对路径与以 .. 跳出目标目录的条目
+// （zip-slip），跳过符号链接、硬链接与设备文件。解压按实际写入的字节数计算配额，失败时删除本次创建的文件。
+type ArchiveTool struct {
+	config config.ArchiveConfig
+}
+
+// ArchiveArgs 归档参数
+type ArchiveArgs struct {
+	Op        string   `json:"op"` // list, create, extract
+	Root      string   `json:"root"`
+	Archive   string   `json:"archive"`   // 归档文件路径，list 与 extract 也可以是上传的 blob:// URI
+	Format    string   `json:"format"`    // zip, tar.gz, tar，默认按扩展名判断
+	Sources   []string `json:"sources"`   // create：要归档的文件或目录
+	Dest      string   `json:"dest"`      // extract：目标目录，默认为根目录
+	Entries   []string `json:"entries"`   // extract：只解压匹配的条目，支持通配符与目录前缀
+	Overwrite bool     `json:"overwrite"` // 覆盖已存在的归档或文件
+}
+
+// ArchiveResult 归档结果
+type ArchiveResult struct {
+	Op        string         `json:"op"`
+	Root      string         `json:"root"`
+	Archive   string         `json:"archive"`
+	Format    string         `json:"format"`
+	Entries   []ArchiveEntry `json:"entries,omitempty"` // list
+	Files     int            `json:"files"`             // 归档或解压的文件数
+	Bytes     int64          `json:"bytes"`             // 文件内容总大小
+	Size      int64          `json:"size,omitempty"`    // 归档文件大小
+	Skipped   []string       `json:"skipped,omitempty"` // 被跳过的符号链接等条目
+	Truncated bool           `json:"truncated,omitempty"`
+}
+
+// ArchiveEntry 归档条目
+type ArchiveEntry struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Dir      bool      `json:"dir,omitempty"`
+	Mode     string    `json:"mode"`
+	Modified time.Time `json:"modified"`
+}
+
+// NewArchiveTool 创建归档工具
+func NewArchiveTool(cfg config.ArchiveConfig) *ArchiveTool {
+	if cfg.MaxArchiveBytes <= 0 {
+		cfg.MaxArchiveBytes = defaultArchiveMaxArchiveBytes
+	}
+	if cfg.MaxExtractBytes <= 0 {
+		cfg.MaxExtractBytes = defaultArchiveMaxExtractBytes
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaultArchiveMaxEntries
+	}
+	return &ArchiveTool{config: cfg}
+}
+
+func (at *ArchiveTool) Name() string {
+	return "archive"
+}
+
+func (at *ArchiveTool) Description() string {
+	return "Create, list and extract zip and tar.gz archives within configured root directories"
+}
+
+func (at *ArchiveTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (at *ArchiveTool) Tags() []string {
+	return []string{TagFilesystem, TagDestructive}
+}
+
+func (at *ArchiveTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"op":        {Type: schema.TypeString, Description: "Operation", Enum: []interface{}{"list", "create", "extract"}},
+		"root":      {Type: schema.TypeString, Description: "Configured root directory, defaults to the default root"},
+		"archive":   {Type: schema.TypeString, Description: "Archive path relative to the root, or an uploaded blob:// URI (list, extract)", MinLength: schema.Int(1)},
+		"format":    {Type: schema.TypeString, Description: "Archive format, inferred from the extension by default", Enum: []interface{}{ArchiveFormatZip, ArchiveFormatTarGz, ArchiveFormatTar}},
+		"sources":   {Type: schema.TypeArray, Description: "Files or directories to archive, relative to the root (create)", Items: &schema.Schema{Type: schema.TypeString, MinLength: schema.Int(1)}, MinItems: schema.Int(1)},
+		"dest":      {Type: schema.TypeString, Description: "Directory to extract into, relative to the root; defaults to the root (extract)"},
+		"entries":   {Type: schema.TypeArray, Description: "Only extract these entries; supports wildcards and directory prefixes (extract)", Items: &schema.Schema{Type: schema.TypeString, MinLength: schema.Int(1)}},
+		"overwrite": {Type: schema.TypeBoolean, Description: "Replace an existing archive (create) or existing files (extract)"},
+	}, "op", "archive").Closed()
+}
+
+func (at *ArchiveTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var archiveArgs ArchiveArgs
+	if err := json.Unmarshal(args, &archiveArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	if IsBlobURI(archiveArgs.Archive) {
+		return at.executeBlob(ctx, archiveArgs)
+	}
+
+	rootName, rootDir, err := at.root(ctx, archiveArgs.Root)
+	if err != nil {
+		return nil, err
+	}
+	archivePath, err := platform.Confine(".", archiveArgs.Archive)
+	if err != nil {
+		return nil, err
+	}
+	format, err := archiveFormat(archivePath, archiveArgs.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := os.OpenRoot(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open root %s: %v", rootName, err)
+	}
+	defer root.Close()
+
+	result := ArchiveResult{Op: archiveArgs.Op, Root: rootName, Archive: filepath.ToSlash(archivePath), Format: format}
+	switch archiveArgs.Op {
+	case "list", "extract":
+		file, size, err := at.openArchive(root, archivePath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		if archiveArgs.Op == "list" {
+			err = at.list(file, size, format, &result)
+		} else {
+			err = at.extract(ctx, root, file, size, format, archiveArgs, &result)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case "create":
+		if err := at.create(ctx, root, archivePath, format, archiveArgs, &result); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported op: %s", archiveArgs.Op)
+	}
+	return json.Marshal(result)
+}
+
+// executeBlob 列出或解压客户端上传的 blob:// 归档，格式按上传时的文件名判断，解压到根目录中
+func (at *ArchiveTool) executeBlob(ctx context.Context, args ArchiveArgs) (json.RawMessage, error) {
+	if args.Op != "list" && args.Op != "extract" {
+		return nil, fmt.Errorf("uploaded blobs can only be listed or extracted")
+	}
+	file, info, err := OpenBlob(ctx, args.Archive)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if info.Size > at.config.MaxArchiveBytes {
+		return nil, fmt.Errorf("%w: archive is larger than %d bytes", ErrArchiveQuota, at.config.MaxArchiveBytes)
+	}
+	format, err := archiveFormat(info.Name, args.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := ArchiveResult{Op: args.Op, Archive: args.Archive, Format: format}
+	if args.Op == "list" {
+		if err := at.list(file, info.Size, format, &result); err != nil {
+			return nil, err
+		}
+		return json.Marshal(result)
+	}
+
+	rootName, rootDir, err := at.root(ctx, args.Root)
+	if err != nil {
+		return nil, err
+	}
+	root, err := os.OpenRoot(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open root %s: %v", rootName, err)
+	}
+	defer root.Close()
+	result.Root = rootName
+	if err := at.extract(ctx, root, file, info.Size, format, args, &result); err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// root 解析根目录，未指定时使用默认根目录或唯一配置的根目录
+//
+// 租户发起的调用只能使用租户配置的根目录。
+func (at *ArchiveTool) root(ctx context.Context, name string) (string, string, error) {
+	roots, defaultRoot := at.config.Roots, at.config.Default
+	if tenant := TenantFromContext(ctx); tenant != nil {
+		roots, defaultRoot = tenant.Config.Roots, ""
+	}
+	if name == "" {
+		switch {
+		case defaultRoot != "":
+			name = defaultRoot
+		case len(roots) == 1:
+			for only := range roots {
+				name = only
+			}
+		case len(roots) == 0:
+			return "", "", fmt.Errorf("no archive root configured")
+		default:
+			available := make([]string, 0, len(roots))
+			for root := range roots {
+				available = append(available, root)
+			}
+			sort.Strings(available)
+			return "", "", fmt.Errorf("root is required, available: %s", strings.Join(available, ", "))
+		}
+	}
+	dir, ok := roots[name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown archive root: %s", name)
+	}
+	dir, err := platform.NormalizePath(dir)
+	if err != nil {
+		return "", "", err
+	}
+	return name, dir, nil
+}
+
+// archiveFormat 确定归档格式，未指定时按扩展名判断
+func archiveFormat(name, format string) (string, error) {
+	switch format {
+	case ArchiveFormatZip, ArchiveFormatTarGz, ArchiveFormatTar:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return ArchiveFormatZip, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ArchiveFormatTarGz, nil
+	case strings.HasSuffix(lower, ".tar"):
+		return ArchiveFormatTar, nil
+	}
+	return "", fmt.Errorf("cannot infer archive format of %s, specify format", filepath.ToSlash(name))
+}
+
+// openArchive 打开归档文件并检查大小上限
+func (at *ArchiveTool) openArchive(root *os.Root, name string) (*os.File, int64, error) {
+	file, err := root.Open(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	if !info.Mode().IsRegular() {
+		file.Close()
+		return nil, 0, fmt.Errorf("%s is not a regular file", filepath.ToSlash(name))
+	}
+	if info.Size() > at.config.MaxArchiveBytes {
+		file.Close()
+		return nil, 0, fmt.Errorf("%w: archive is larger than %d bytes", ErrArchiveQuota, at.config.MaxArchiveBytes)
+	}
+	return file, info.Size(), nil
+}
+
+// archiveItem 归档中的一个条目，open 仅对普通文件有效
+type archiveItem struct {
+	name string
+	mode fs.FileMode
+	size int64
+	mod  time.Time
+	open func() (io.ReadCloser, error)
+}
+
+// walkArchive 依次访问归档条目，fn 返回错误时停止
+func (at *ArchiveTool) walkArchive(file *os.File, size int64, format string, fn func(archiveItem) error) error {
+	if format == ArchiveFormatZip {
+		reader, err := zip.NewReader(file, size)
+		if err != nil {
+			return fmt.Errorf("invalid zip archive: %v", err)
+		}
+		for _, f := range reader.File {
+			if err := fn(archiveItem{name: f.Name, mode: f.Mode(), size: int64(f.UncompressedSize64), mod: f.Modified, open: f.Open}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var stream io.Reader = file
+	if format == ArchiveFormatTarGz {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("invalid gzip stream: %v", err)
+		}
+		defer gz.Close()
+		stream = gz
+	}
+	reader := tar.NewReader(stream)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid tar archive: %v", err)
+		}
+		item := archiveItem{name: header.Name, mode: header.FileInfo().Mode(), size: header.Size, mod: header.ModTime}
+		item.open = func() (io.ReadCloser, error) { return io.NopCloser(reader), nil }
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+}
+
+func (at *ArchiveTool) list(file *os.File, size int64, format string, result *ArchiveResult) error {
+	return at.walkArchive(file, size, format, func(item archiveItem) error {
+		if len(result.Entries) >= at.config.MaxEntries {
+			result.Truncated = true
+			return nil
+		}
+		result.Entries = append(result.Entries, ArchiveEntry{
+			Name:     item.name,
+			Size:     item.size,
+			Dir:      item.mode.IsDir(),
+			Mode:     item.mode.String(),
+			Modified: item.mod,
+		})
+		if item.mode.IsRegular() {
+			result.Files++
+			result.Bytes += item.size
+		}
+		return nil
+	})
+}
+
+// archiveWriter 统一 zip 与 tar 的写入
+type archiveWriter interface {
+	add(name string, info fs.FileInfo, content io.Reader) error
+	Close() error
+}
+
+type zipArchiveWriter struct{ w *zip.Writer }
+
+func (z *zipArchiveWriter) add(name string, info fs.FileInfo, content io.Reader) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	} else {
+		header.Method = zip.Deflate
+	}
+	w, err := z.w.CreateHeader(header)
+	if err != nil || content == nil {
+		return err
+	}
+	_, err = io.Copy(w, content)
+	return err
+}
+
+func (z *zipArchiveWriter) Close() error {
+	return z.w.Close()
+}
+
+type tarArchiveWriter struct {
+	w  *tar.Writer
+	gz *gzip.Writer
+}
+
+func (t *tarArchiveWriter) add(name string, info fs.FileInfo, content io.Reader) error {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	// 不记录本机的用户与组
+	header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+	if err := t.w.WriteHeader(header); err != nil || content == nil {
+		return err
+	}
+	_, err = io.Copy(t.w, content)
+	return err
+}
+
+func (t *tarArchiveWriter) Close() error {
+	err := t.w.Close()
+	if t.gz != nil {
+		if gzErr := t.gz.Close(); err == nil {
+			err = gzErr
+		}
+	}
+	return err
+}
+
+// quotaWriter 写入超过上限时返回 ErrArchiveQuota
+type quotaWriter struct {
+	w       io.Writer
+	written int64
+	limit   int64
+}
+
+func (q *quotaWriter) Write(p []byte) (int, error) {
+	if q.written+int64(len(p)) > q.limit {
+		return 0, fmt.Errorf("%w: archive would exceed %d bytes", ErrArchiveQuota, q.limit)
+	}
+	n, err := q.w.Write(p)
+	q.written += int64(n)
+	return n, err
+}
+
+func (at *ArchiveTool) create(ctx context.Context, root *os.Root, name, format string, args ArchiveArgs, result *ArchiveResult) error {
+	if len(args.Sources) == 0 {
+		return fmt.Errorf("sources is required for create")
+	}
+	sources := make([]string, len(args.Sources))
+	for i, source := range args.Sources {
+		local, err := platform.Confine(".", source)
+		if err != nil {
+			return err
+		}
+		sources[i] = local
+	}
+
+	if err := rootMkdirAll(root, filepath.Dir(name)); err != nil {
+		return err
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if args.Overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := root.OpenFile(name, flags, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("archive %s already exists, set overwrite to replace it", filepath.ToSlash(name))
+	}
+	if err != nil {
+		return err
+	}
+	out := &quotaWriter{w: file, limit: at.config.MaxArchiveBytes}
+
+	var writer archiveWriter
+	switch format {
+	case ArchiveFormatZip:
+		writer = &zipArchiveWriter{w: zip.NewWriter(out)}
+	case ArchiveFormatTarGz:
+		gz := gzip.NewWriter(out)
+		writer = &tarArchiveWriter{w: tar.NewWriter(gz), gz: gz}
+	default:
+		writer = &tarArchiveWriter{w: tar.NewWriter(out)}
+	}
+
+	err = at.addSources(ctx, root, writer, name, sources, result)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		root.Remove(name)
+		return err
+	}
+	result.Size = out.written
+	return nil
+}
+
+// addSources 将源文件与目录写入归档，跳过符号链接与特殊文件，以及归档文件自身
+func (at *ArchiveTool) addSources(ctx context.Context, root *os.Root, writer archiveWriter, archiveName string, sources []string, result *ArchiveResult) error {
+	fsys := root.FS()
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		err := fs.WalkDir(fsys, filepath.ToSlash(source), func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if seen[name] || filepath.FromSlash(name) == archiveName {
+				return nil
+			}
+			seen[name] = true
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && !info.Mode().IsRegular() {
+				result.Skipped = append(result.Skipped, name)
+				return nil
+			}
+			if result.Files >= at.config.MaxEntries {
+				return fmt.Errorf("%w: more than %d files", ErrArchiveQuota, at.config.MaxEntries)
+			}
+			if info.IsDir() {
+				if name == "." {
+					return nil
+				}
+				return writer.add(name, info, nil)
+			}
+			if result.Bytes+info.Size() > at.config.MaxExtractBytes {
+				return fmt.Errorf("%w: sources exceed %d bytes", ErrArchiveQuota, at.config.MaxExtractBytes)
+			}
+
+			file, err := fsys.Open(name)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			if err := writer.add(name, info, file); err != nil {
+				return err
+			}
+			result.Files++
+			result.Bytes += info.Size()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (at *ArchiveTool) extract(ctx context.Context, root *os.Root, file *os.File, size int64, format string, args ArchiveArgs, result *ArchiveResult) error {
+	dest := "."
+	if args.Dest != "" {
+		local, err := platform.Confine(".", args.Dest)
+		if err != nil {
+			return err
+		}
+		dest = local
+	}
+
+	// 失败时按创建的逆序删除本次新建的文件与目录
+	var created []string
+	cleanup := func() {
+		for i := len(created) - 1; i >= 0; i-- {
+			root.Remove(created[i])
+		}
+	}
+	mkdirs := func(dir string) error {
+		made, err := rootMkdirAllTracked(root, dir)
+		created = append(created, made...)
+		return err
+	}
+	if err := mkdirs(dest); err != nil {
+		return err
+	}
+
+	err := at.walkArchive(file, size, format, func(item archiveItem) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		entry := strings.TrimPrefix(item.name, "./")
+		if entry == "" || entry == "." || !matchArchiveEntry(args.Entries, entry) {
+			return nil
+		}
+		target, err := platform.Confine(dest, entry)
+		if err != nil {
+			return fmt.Errorf("unsafe entry %q: %w", item.name, err)
+		}
+
+		switch {
+		case item.mode.IsDir():
+			return mkdirs(target)
+		case !item.mode.IsRegular():
+			result.Skipped = append(result.Skipped, item.name)
+			return nil
+		}
+		if result.Files >= at.config.MaxEntries {
+			return fmt.Errorf("%w: more than %d files", ErrArchiveQuota, at.config.MaxEntries)
+		}
+		if err := mkdirs(filepath.Dir(target)); err != nil {
+			return err
+		}
+
+		_, statErr := root.Lstat(target)
+		exists := statErr == nil
+		if exists && !args.Overwrite {
+			return fmt.Errorf("%s already exists, set overwrite to replace it", filepath.ToSlash(target))
+		}
+		perm := item.mode.Perm()&0755 | 0600
+		out, err := root.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			created = append(created, target)
+		}
+
+		content, err := item.open()
+		if err != nil {
+			out.Close()
+			return err
+		}
+		remaining := at.config.MaxExtractBytes - result.Bytes
+		n, err := io.Copy(out, io.LimitReader(content, remaining+1))
+		content.Close()
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %v", item.name, err)
+		}
+		if n > remaining {
+			return fmt.Errorf("%w: extracted content exceeds %d bytes", ErrArchiveQuota, at.config.MaxExtractBytes)
+		}
+		result.Files++
+		result.Bytes += n
+		return nil
+	})
+	if err != nil {
+		cleanup()
+		return err
+	}
+	return nil
+}
+
+// matchArchiveEntry 条目名等于模式、位于模式表示的目录下或匹配通配符，模式为空时全部匹配
+func matchArchiveEntry(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	name = strings.TrimSuffix(name, "/")
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "./"), "/")
+		if name == pattern || strings.HasPrefix(name, pattern+"/") {
+			return true
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// rootMkdirAll 在根目录内逐级创建目录
+func rootMkdirAll(root *os.Root, dir string) error {
+	_, err := rootMkdirAllTracked(root, dir)
+	return err
+}
+
+// rootMkdirAllTracked 逐级创建目录，返回新建的目录（由外到内）
+func rootMkdirAllTracked(root *os.Root, dir string) ([]string, error) {
+	if dir == "." || dir == "" {
+		return nil, nil
+	}
+	info, err := root.Stat(dir)
+	if err == nil {
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", filepath.ToSlash(dir))
+		}
+		return nil, nil
+	}
+	made, err := rootMkdirAllTracked(root, filepath.Dir(dir))
+	if err != nil {
+		return made, err
+	}
+	if err := root.Mkdir(dir, 0755); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return made, nil
+		}
+		return made, err
+	}
+	return append(made, dir), nil
+}
@@ -0,0 +1,491 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/platform"
+)
+
+// BlobURIPrefix 上传文件的资源 URI 前缀
+const BlobURIPrefix = "blob://"
+
+// 上传文件存储的默认参数
+const (
+	DefaultBlobTTL           = time.Hour
+	DefaultBlobMaxBytes      = 100 << 20
+	DefaultBlobMaxTotalBytes = 1 << 30
+	blobDirName              = "weave-blobs"
+	blobStorePattern         = "store-*"
+	blobCopyBufferSize       = 32 << 10
+)
+
+var (
+	// ErrBlobTooLarge 上传的文件超过单个文件大小上限
+	ErrBlobTooLarge = errors.New("blob size limit exceeded")
+	// ErrBlobStoreFull 上传会超过存储的总大小上限
+	ErrBlobStoreFull = errors.New("blob store is full")
+)
+
+// BlobInfo 上传文件的信息
+type BlobInfo struct {
+	ID       string    `json:"id"`
+	URI      string    `json:"uri"`
+	Name     string    `json:"name,omitempty"` // 上传时的文件名
+	MimeType string    `json:"mimeType"`
+	Size     int64     `json:"size"`
+	SHA256   string    `json:"sha256,omitempty"` // 上传完成后计算
+	Complete bool      `json:"complete"`         // 分块上传未完成时为 false，不能读取
+	Expires  time.Time `json:"expires"`
+}
+
+// blobEntry 存储中的文件，owner 为上传者的租户名称，非租户请求为空
+type blobEntry struct {
+	info    BlobInfo
+	owner   string
+	path    string
+	writing bool // 正在写入，拒绝并发的分块
+}
+
+// BlobStore 上传文件的临时存储
+//
+// 客户端通过 /mcp/blobs 上传的文件保存在本进程的存储目录中，以 blob://{id} 作为工具参数或通过
+// resources/read 读取，避免将大文件以 base64 放入 JSON-RPC 请求。文件只对上传它的租户可见，
+// 保留 ttl 后删除（分块上传每收到一块重新计时）；单个文件与全部文件的大小均有上限。
+type BlobStore struct {
+	root     string
+	ttl      time.Duration
+	maxBytes int64
+	maxTotal int64
+
+	once sync.Once
+	dir  string
+	err  error
+
+	mu    sync.Mutex
+	blobs map[string]*blobEntry
+	size  int64
+	now   func() time.Time
+}
+
+// NewBlobStore 创建上传文件存储，并删除此前进程遗留的过期存储目录
+func NewBlobStore(cfg config.BlobConfig) (*BlobStore, error) {
+	root := cfg.Dir
+	if root == "" {
+		root = filepath.Join(os.TempDir(), blobDirName)
+	}
+	root, err := platform.NormalizePath(root)
+	if err != nil {
+		return nil, err
+	}
+	bs := &BlobStore{
+		root:     root,
+		ttl:      time.Duration(cfg.TTL) * time.Second,
+		maxBytes: cfg.MaxBytes,
+		maxTotal: cfg.MaxTotalBytes,
+		blobs:    make(map[string]*blobEntry),
+		now:      time.Now,
+	}
+	if bs.ttl <= 0 {
+		bs.ttl = DefaultBlobTTL
+	}
+	if bs.maxBytes <= 0 {
+		bs.maxBytes = DefaultBlobMaxBytes
+	}
+	if bs.maxTotal <= 0 {
+		bs.maxTotal = DefaultBlobMaxTotalBytes
+	}
+	bs.sweepStale()
+	return bs, nil
+}
+
+// SetClock 替换时钟，供测试推进时间
+func (bs *BlobStore) SetClock(now func() time.Time) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.now = now
+}
+
+// MaxBytes 单个文件大小上限
+func (bs *BlobStore) MaxBytes() int64 {
+	return bs.maxBytes
+}
+
+// sweepStale 删除修改时间早于 ttl 的遗留存储目录（进程异常退出时未清理）
+func (bs *BlobStore) sweepStale() {
+	entries, err := os.ReadDir(bs.root)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-bs.ttl)
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), strings.TrimSuffix(blobStorePattern, "*")) {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && info.ModTime().Before(cutoff) {
+			os.RemoveAll(filepath.Join(bs.root, entry.Name()))
+		}
+	}
+}
+
+// storeDir 本进程的存储目录，首次上传时创建
+func (bs *BlobStore) storeDir() (string, error) {
+	bs.once.Do(func() {
+		if err := os.MkdirAll(bs.root, 0700); err != nil {
+			bs.err = fmt.Errorf("failed to create blob root: %v", err)
+			return
+		}
+		bs.dir, bs.err = os.MkdirTemp(bs.root, blobStorePattern)
+	})
+	return bs.dir, bs.err
+}
+
+// Create 保存上传的文件；complete 为 false 时创建分块上传，r 的内容为第一块，之后通过 Append 追加
+func (bs *BlobStore) Create(owner, name, mimeType string, r io.Reader, complete bool) (BlobInfo, error) {
+	dir, err := bs.storeDir()
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return BlobInfo{}, err
+	}
+	id := hex.EncodeToString(buf)
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	entry := &blobEntry{
+		info:    BlobInfo{ID: id, URI: BlobURIPrefix + id, Name: name, MimeType: mimeType},
+		owner:   owner,
+		path:    filepath.Join(dir, id),
+		writing: true,
+	}
+
+	bs.mu.Lock()
+	bs.sweepLocked()
+	entry.info.Expires = bs.now().Add(bs.ttl)
+	bs.blobs[id] = entry
+	bs.mu.Unlock()
+
+	if err := bs.write(entry, r, complete); err != nil {
+		bs.mu.Lock()
+		bs.removeLocked(entry)
+		bs.mu.Unlock()
+		return BlobInfo{}, err
+	}
+	return bs.finish(entry, complete)
+}
+
+// Append 在分块上传的 offset 处追加一块，offset 必须等于已上传的大小；final 为 true 时完成上传
+func (bs *BlobStore) Append(owner, id string, offset int64, r io.Reader, final bool) (BlobInfo, error) {
+	bs.mu.Lock()
+	bs.sweepLocked()
+	entry, ok := bs.blobs[id]
+	switch {
+	case !ok || entry.owner != owner:
+		bs.mu.Unlock()
+		return BlobInfo{}, werrors.NotFound("blob not found: %s", id)
+	case entry.info.Complete:
+		bs.mu.Unlock()
+		return BlobInfo{}, werrors.InvalidParams("blob %s is already complete", id)
+	case entry.writing:
+		bs.mu.Unlock()
+		return BlobInfo{}, werrors.InvalidParams("blob %s is receiving another chunk", id)
+	case offset != entry.info.Size:
+		size := entry.info.Size
+		bs.mu.Unlock()
+		return BlobInfo{}, werrors.InvalidParams("offset %d does not match uploaded size %d", offset, size)
+	}
+	entry.writing = true
+	entry.info.Expires = bs.now().Add(bs.ttl)
+	bs.mu.Unlock()
+
+	if err := bs.write(entry, r, final); err != nil {
+		// 丢弃本块已写入的内容，客户端可从原 offset 重新上传
+		bs.mu.Lock()
+		if _, exists := bs.blobs[id]; exists {
+			bs.size -= entry.info.Size - offset
+			entry.info.Size = offset
+			entry.writing = false
+			os.Truncate(entry.path, offset)
+		}
+		bs.mu.Unlock()
+		return BlobInfo{}, err
+	}
+	return bs.finish(entry, final)
+}
+
+// write 将 r 追加到文件，逐块预占单个文件与存储的大小额度
+func (bs *BlobStore) write(entry *blobEntry, r io.Reader, final bool) error {
+	file, err := os.OpenFile(entry.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buf := make([]byte, blobCopyBufferSize)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			if err := bs.reserve(entry, int64(n)); err != nil {
+				return err
+			}
+			if _, err := file.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	if final {
+		return file.Sync()
+	}
+	return nil
+}
+
+// reserve 预占 n 字节
+func (bs *BlobStore) reserve(entry *blobEntry, n int64) error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.blobs[entry.info.ID] != entry {
+		return werrors.NotFound("blob was deleted: %s", entry.info.ID)
+	}
+	if entry.info.Size+n > bs.maxBytes {
+		return fmt.Errorf("%w (%d bytes)", ErrBlobTooLarge, bs.maxBytes)
+	}
+	if bs.size+n > bs.maxTotal {
+		return fmt.Errorf("%w (%d bytes)", ErrBlobStoreFull, bs.maxTotal)
+	}
+	entry.info.Size += n
+	bs.size += n
+	return nil
+}
+
+// finish 结束一次写入，完成上传时计算 SHA-256
+func (bs *BlobStore) finish(entry *blobEntry, complete bool) (BlobInfo, error) {
+	var digest string
+	if complete {
+		sum, err := fileSHA256(entry.path)
+		if err != nil {
+			bs.mu.Lock()
+			bs.removeLocked(entry)
+			bs.mu.Unlock()
+			return BlobInfo{}, err
+		}
+		digest = sum
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	entry.writing = false
+	if complete {
+		entry.info.Complete = true
+		entry.info.SHA256 = digest
+	}
+	return entry.info, nil
+}
+
+// fileSHA256 计算文件内容的 SHA-256
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Open 打开已完成上传的文件，调用方负责关闭
+func (bs *BlobStore) Open(owner, uri string) (*os.File, BlobInfo, error) {
+	id, err := blobID(uri)
+	if err != nil {
+		return nil, BlobInfo{}, err
+	}
+
+	bs.mu.Lock()
+	bs.sweepLocked()
+	entry, ok := bs.blobs[id]
+	if !ok || entry.owner != owner {
+		bs.mu.Unlock()
+		return nil, BlobInfo{}, werrors.NotFound("blob not found: %s", uri)
+	}
+	info, path := entry.info, entry.path
+	bs.mu.Unlock()
+
+	if !info.Complete {
+		return nil, BlobInfo{}, werrors.InvalidParams("blob upload is not complete: %s", uri)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, BlobInfo{}, werrors.NotFound("blob not found: %s", uri)
+		}
+		return nil, BlobInfo{}, err
+	}
+	return file, info, nil
+}
+
+// ReadAll 读取已完成上传的文件的全部内容
+func (bs *BlobStore) ReadAll(owner, uri string) ([]byte, BlobInfo, error) {
+	file, info, err := bs.Open(owner, uri)
+	if err != nil {
+		return nil, BlobInfo{}, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	return data, info, err
+}
+
+// Delete 删除上传的文件
+func (bs *BlobStore) Delete(owner, id string) error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	entry, ok := bs.blobs[id]
+	if !ok || entry.owner != owner {
+		return werrors.NotFound("blob not found: %s", id)
+	}
+	bs.removeLocked(entry)
+	return nil
+}
+
+// List 列出上传者已完成上传的文件，按过期时间排序
+func (bs *BlobStore) List(owner string) []BlobInfo {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.sweepLocked()
+
+	var blobs []BlobInfo
+	for _, entry := range bs.blobs {
+		if entry.owner == owner && entry.info.Complete {
+			blobs = append(blobs, entry.info)
+		}
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Expires.Before(blobs[j].Expires) })
+	return blobs
+}
+
+// Stats 获取存储的文件数与字节数
+func (bs *BlobStore) Stats() map[string]interface{} {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.sweepLocked()
+	return map[string]interface{}{
+		"blobs":     len(bs.blobs),
+		"bytes":     bs.size,
+		"max_bytes": bs.maxTotal,
+	}
+}
+
+// Close 删除本进程的存储目录
+func (bs *BlobStore) Close() error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.blobs = make(map[string]*blobEntry)
+	bs.size = 0
+	if bs.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(bs.dir)
+}
+
+// sweepLocked 删除过期且未在写入的文件（调用方需持有锁）
+func (bs *BlobStore) sweepLocked() {
+	now := bs.now()
+	for _, entry := range bs.blobs {
+		if !entry.writing && !entry.info.Expires.After(now) {
+			bs.removeLocked(entry)
+		}
+	}
+}
+
+// removeLocked 删除文件并释放额度（调用方需持有锁）
+func (bs *BlobStore) removeLocked(entry *blobEntry) {
+	if _, ok := bs.blobs[entry.info.ID]; !ok {
+		return
+	}
+	delete(bs.blobs, entry.info.ID)
+	bs.size -= entry.info.Size
+	os.Remove(entry.path)
+}
+
+// blobID 解析 blob://{id}
+func blobID(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme+"://" != BlobURIPrefix || parsed.Host == "" {
+		return "", werrors.InvalidParams("invalid blob uri: %s", uri)
+	}
+	return parsed.Host, nil
+}
+
+// blobStoreContextKey 上传文件存储上下文键
+type blobStoreContextKey struct{}
+
+// WithBlobStore 在上下文中设置上传文件存储
+func WithBlobStore(ctx context.Context, store *BlobStore) context.Context {
+	return context.WithValue(ctx, blobStoreContextKey{}, store)
+}
+
+// BlobStoreFromContext 获取上传文件存储，未启用时返回 nil
+func BlobStoreFromContext(ctx context.Context) *BlobStore {
+	store, _ := ctx.Value(blobStoreContextKey{}).(*BlobStore)
+	return store
+}
+
+// OpenBlob 打开工具参数中的 blob:// URI，只能打开发起调用的租户上传的文件
+func OpenBlob(ctx context.Context, uri string) (*os.File, BlobInfo, error) {
+	store := BlobStoreFromContext(ctx)
+	if store == nil {
+		return nil, BlobInfo{}, werrors.InvalidParams("blob uploads are disabled")
+	}
+	return store.Open(BlobOwner(ctx), uri)
+}
+
+// ReadBlob 读取工具参数中 blob:// URI 的全部内容，超过 maxBytes 时返回错误
+func ReadBlob(ctx context.Context, uri string, maxBytes int64) ([]byte, BlobInfo, error) {
+	file, info, err := OpenBlob(ctx, uri)
+	if err != nil {
+		return nil, BlobInfo{}, err
+	}
+	defer file.Close()
+	if maxBytes > 0 && info.Size > maxBytes {
+		return nil, BlobInfo{}, werrors.InvalidParams("blob exceeds %d bytes", maxBytes)
+	}
+	data, err := io.ReadAll(file)
+	return data, info, err
+}
+
+// IsBlobURI 判断参数是否为 blob:// URI
+func IsBlobURI(value string) bool {
+	return strings.HasPrefix(value, BlobURIPrefix)
+}
+
+// BlobOwner 上传文件的归属：发起请求的租户名称，非租户请求为空
+func BlobOwner(ctx context.Context) string {
+	if tenant := TenantFromContext(ctx); tenant != nil {
+		return tenant.Name
+	}
+	return ""
+}
@@ -0,0 +1,291 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// defaultBreakerCooldown 未配置熔断时长时的默认值
+const defaultBreakerCooldown = 30 * time.Second
+
+// 熔断器状态
+const (
+	CircuitClosed   = "closed"    // 正常放行
+	CircuitOpen     = "open"      // 熔断中，直接拒绝
+	CircuitHalfOpen = "half_open" // 熔断时长已过，放行一次试探调用
+)
+
+// ErrCircuitOpen 工具或上游服务因连续失败被熔断
+var ErrCircuitOpen = werrors.New(werrors.KindUnavailable, "circuit open")
+
+// CircuitOpenError 熔断拒绝的结构化错误，RetryAfter 为距离允许试探调用的剩余时间
+type CircuitOpenError struct {
+	Name       string        // 工具名或 "<类型>:<服务名>" 形式的上游服务
+	Failures   int           // 熔断前的连续失败次数
+	LastError  string        // 最近一次失败的错误
+	RetryAfter time.Duration // 为 0 时表示试探调用正在进行
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: %s failed %d times in a row, retry after %s", ErrCircuitOpen, e.Name, e.Failures, e.RetryAfter.Round(time.Second))
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// BreakerStats 熔断器状态快照
+type BreakerStats struct {
+	Name       string     `json:"name"`
+	State      string     `json:"state"`
+	Failures   int        `json:"failures"` // 当前连续失败次数
+	Trips      int64      `json:"trips"`    // 累计熔断次数
+	LastError  string     `json:"last_error,omitempty"`
+	OpenedAt   *time.Time `json:"opened_at,omitempty"`
+	RetryAfter float64    `json:"retry_after_seconds,omitempty"`
+}
+
+// circuitBreaker 单个工具或上游服务的熔断状态
+type circuitBreaker struct {
+	state     string
+	failures  int
+	trips     int64
+	lastError string
+	openedAt  time.Time
+	trial     bool // 半开状态下试探调用正在进行
+}
+
+// CircuitBreakers 按名称管理的熔断器：连续失败达到阈值后在熔断时长内直接拒绝调用，
+// 之后放行一次试探调用，成功则恢复，失败则重新熔断
+type CircuitBreakers struct {
+	threshold int
+	cooldown  time.Duration
+	mu        sync.Mutex
+	breakers  map[string]*circuitBreaker
+	now       func() time.Time
+	// errorText 生成保存在熔断器状态中的错误文本，为空时保存错误原文
+	errorText func(name string, err error) string
+}
+
+// NewCircuitBreakers 创建熔断器集合，阈值不大于 0 时不熔断
+func NewCircuitBreakers(cfg config.CircuitBreakerConfig) *CircuitBreakers {
+	cooldown := time.Duration(cfg.Cooldown) * time.Second
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &CircuitBreakers{
+		threshold: cfg.FailureThreshold,
+		cooldown:  cooldown,
+		breakers:  make(map[string]*circuitBreaker),
+		now:       time.Now,
+	}
+}
+
+// SetClock 替换时钟，供测试推进时间
+func (cb *CircuitBreakers) SetClock(now func() time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.now = now
+}
+
+// SetErrorText 设置熔断器状态中保存的错误文本；状态经 /health/stats 公开且会返回给被拒绝的调用方，
+// 文本应去掉参数、凭据与个人信息
+func (cb *CircuitBreakers) SetErrorText(errorText func(name string, err error) string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.errorText = errorText
+}
+
+// describe 按 SetErrorText 的设置生成错误文本
+func (cb *CircuitBreakers) describe(name string, err error) string {
+	if err == nil {
+		return ""
+	}
+	cb.mu.Lock()
+	errorText := cb.errorText
+	cb.mu.Unlock()
+	if errorText == nil {
+		return err.Error()
+	}
+	return errorText(name, err)
+}
+
+// Allow 检查是否放行调用，放行后必须以 Record 或 Abandon 结束
+func (cb *CircuitBreakers) Allow(name string) error {
+	if cb == nil || cb.threshold <= 0 {
+		return nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	breaker, exists := cb.breakers[name]
+	if !exists {
+		return nil
+	}
+	switch breaker.state {
+	case CircuitOpen:
+		if remaining := cb.cooldown - cb.now().Sub(breaker.openedAt); remaining > 0 {
+			return &CircuitOpenError{Name: name, Failures: breaker.failures, LastError: breaker.lastError, RetryAfter: remaining}
+		}
+		breaker.state = CircuitHalfOpen
+		breaker.trial = true
+	case CircuitHalfOpen:
+		if breaker.trial {
+			return &CircuitOpenError{Name: name, Failures: breaker.failures, LastError: breaker.lastError}
+		}
+		breaker.trial = true
+	}
+	return nil
+}
+
+// Record 记录放行调用的结果，调用方取消（context.Canceled）不计为失败
+func (cb *CircuitBreakers) Record(name string, err error) {
+	if cb == nil || cb.threshold <= 0 {
+		return
+	}
+	cb.record(name, err, cb.describe(name, err))
+}
+
+// record 按 err 计数，状态中只保存调用方给出的错误文本 text
+func (cb *CircuitBreakers) record(name string, err error, text string) {
+	if cb == nil || cb.threshold <= 0 {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	breaker, exists := cb.breakers[name]
+	if !exists {
+		if err == nil || errors.Is(err, context.Canceled) {
+			return
+		}
+		breaker = &circuitBreaker{state: CircuitClosed}
+		cb.breakers[name] = breaker
+	}
+	breaker.trial = false
+
+	switch {
+	case err == nil:
+		breaker.state = CircuitClosed
+		breaker.failures = 0
+	case errors.Is(err, context.Canceled):
+	default:
+		breaker.failures++
+		breaker.lastError = text
+		if breaker.state == CircuitHalfOpen || (breaker.state == CircuitClosed && breaker.failures >= cb.threshold) {
+			breaker.state = CircuitOpen
+			breaker.openedAt = cb.now()
+			breaker.trips++
+		}
+	}
+}
+
+// Abandon 放弃已放行但未执行的调用，半开状态下允许下一次试探
+func (cb *CircuitBreakers) Abandon(name string) {
+	if cb == nil || cb.threshold <= 0 {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if breaker, exists := cb.breakers[name]; exists {
+		breaker.trial = false
+	}
+}
+
+// Stats 按名称排序的熔断器状态，只包含发生过失败的工具与上游服务
+func (cb *CircuitBreakers) Stats() []BreakerStats {
+	if cb == nil {
+		return nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	stats := make([]BreakerStats, 0, len(cb.breakers))
+	for name, breaker := range cb.breakers {
+		item := BreakerStats{
+			Name:      name,
+			State:     breaker.state,
+			Failures:  breaker.failures,
+			Trips:     breaker.trips,
+			LastError: breaker.lastError,
+		}
+		if breaker.state != CircuitClosed {
+			openedAt := breaker.openedAt
+			item.OpenedAt = &openedAt
+		}
+		if breaker.state == CircuitOpen {
+			if remaining := cb.cooldown - cb.now().Sub(breaker.openedAt); remaining > 0 {
+				item.RetryAfter = remaining.Seconds()
+			}
+		}
+		stats = append(stats, item)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// breakersContextKey 熔断器上下文键
+type breakersContextKey struct{}
+
+// upstreamBreakers 调用期间的熔断器，并记录是否有上游服务调用失败
+type upstreamBreakers struct {
+	breakers  *CircuitBreakers
+	errorText func(err error) string // 本次工具调用的错误文本，为空时使用熔断器的设置
+	failed    atomic.Bool
+}
+
+// WithCircuitBreakers 在上下文中提供熔断器，工具据此对上游服务熔断
+func WithCircuitBreakers(ctx context.Context, breakers *CircuitBreakers) context.Context {
+	return context.WithValue(ctx, breakersContextKey{}, &upstreamBreakers{breakers: breakers})
+}
+
+// callUpstream 经上下文中的熔断器调用上游服务，name 形如 "llm:<服务名>"；
+// 上下文中没有熔断器时直接调用
+func callUpstream(ctx context.Context, name string, call func() error) error {
+	upstream, _ := ctx.Value(breakersContextKey{}).(*upstreamBreakers)
+	if upstream == nil {
+		return call()
+	}
+	if err := upstream.breakers.Allow(name); err != nil {
+		upstream.failed.Store(true)
+		return err
+	}
+	err := call()
+	if err != nil && upstream.errorText != nil {
+		upstream.breakers.record(name, err, upstream.errorText(err))
+	} else {
+		upstream.breakers.Record(name, err)
+	}
+	if err != nil {
+		upstream.failed.Store(true)
+	}
+	return err
+}
+
+// recordCall 记录工具调用结果，状态中保存 text 而非错误原文；上游服务失败导致的错误已由上游熔断器计数，
+// 不计入工具本身，避免一个上游服务故障使整个工具熔断
+func (cb *CircuitBreakers) recordCall(ctx context.Context, name string, err error, text string) {
+	if upstream, _ := ctx.Value(breakersContextKey{}).(*upstreamBreakers); err != nil && upstream != nil && upstream.failed.Load() {
+		cb.Abandon(name)
+		return
+	}
+	cb.record(name, err, text)
+}
+
+// withCallBreakers 在上下文中提供熔断器，上游服务失败时按 errorText 保存错误文本
+func withCallBreakers(ctx context.Context, breakers *CircuitBreakers, errorText func(err error) string) context.Context {
+	return context.WithValue(ctx, breakersContextKey{}, &upstreamBreakers{breakers: breakers, errorText: errorText})
+}
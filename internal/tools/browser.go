@@ -0,0 +1,432 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/schema"
+)
+
+// browser 默认限制
+const (
+	defaultBrowserMaxTabs            = 4
+	defaultBrowserIdleTimeout        = 300
+	defaultBrowserTimeout            = 30
+	defaultBrowserViewportWidth      = 1280
+	defaultBrowserViewportHeight     = 800
+	defaultBrowserMaxContentBytes    = 256 << 10
+	defaultBrowserMaxScreenshotBytes = 5 << 20
+	maxBrowserDelay                  = 10000
+	maxBrowserBlockedReported        = 20
+)
+
+// 提取内容类型
+const (
+	BrowserExtractText = "text"
+	BrowserExtractHTML = "html"
+	BrowserExtractNone = "none"
+)
+
+// browserExtractScript 提取页面或匹配元素的文本或 HTML，参数为选择器与属性名
+const browserExtractScript = `((sel, prop) => {
+	if (!sel) {
+		return [prop === "outerHTML" ? document.documentElement.outerHTML : (document.body ? document.body.innerText : "")];
+	}
+	return Array.from(document.querySelectorAll(sel), e => e[prop] || "");
+})(%s, %q)`
+
+// BrowserTool 无头浏览器工具
+//
+// 通过 chromedp 驱动 Chrome 渲染依赖 JavaScript 的页面，可等待元素出现、提取渲染后的文本或 HTML
+// 并截图，截图以图片内容返回。浏览器进程在首次调用时启动并在调用间共享，每次调用使用独立标签页，
+// 空闲超时后自动关闭。页面发出的每个请求都按 http_fetch 的访问策略检查，不符合的请求被拦截。
+type BrowserTool struct {
+	config config.BrowserConfig
+	fetch  *HTTPFetchTool
+	pool   *browserPool
+}
+
+// BrowserArgs 浏览器参数
+type BrowserArgs struct {
+	URL        string `json:"url"`
+	WaitFor    string `json:"wait_for"` // 等待可见的 CSS 选择器
+	Delay      int    `json:"delay"`    // 加载完成后额外等待的毫秒数
+	Selector   string `json:"selector"` // 提取内容的 CSS 选择器，为空时提取整个页面
+	Extract    string `json:"extract"`  // text, html, none
+	Screenshot bool   `json:"screenshot"`
+	FullPage   bool   `json:"full_page"`
+}
+
+// BrowserResult 浏览器结果
+type BrowserResult struct {
+	URL        string   `json:"url"` // 导航后的最终地址
+	Title      string   `json:"title"`
+	Content    []string `json:"content,omitempty"` // 页面或各匹配元素的内容
+	Truncated  bool     `json:"truncated,omitempty"`
+	Blocked    []string `json:"blocked,omitempty"` // 被访问策略拦截的请求
+	Screenshot string   `json:"screenshot,omitempty"`
+	MimeType   string   `json:"mime_type,omitempty"`
+}
+
+// NewBrowserTool 创建无头浏览器工具
+func NewBrowserTool(cfg config.BrowserConfig, fetch *HTTPFetchTool) *BrowserTool {
+	if cfg.MaxTabs <= 0 {
+		cfg.MaxTabs = defaultBrowserMaxTabs
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = defaultBrowserIdleTimeout
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultBrowserTimeout
+	}
+	if cfg.ViewportWidth <= 0 {
+		cfg.ViewportWidth = defaultBrowserViewportWidth
+	}
+	if cfg.ViewportHeight <= 0 {
+		cfg.ViewportHeight = defaultBrowserViewportHeight
+	}
+	if cfg.MaxContentBytes <= 0 {
+		cfg.MaxContentBytes = defaultBrowserMaxContentBytes
+	}
+	if cfg.MaxScreenshotBytes <= 0 {
+		cfg.MaxScreenshotBytes = defaultBrowserMaxScreenshotBytes
+	}
+	return &BrowserTool{config: cfg, fetch: fetch, pool: newBrowserPool(cfg)}
+}
+
+func (bt *BrowserTool) Name() string {
+	return "browser"
+}
+
+func (bt *BrowserTool) Description() string {
+	return "Load a web page in a headless browser, wait for JavaScript-rendered elements, extract the rendered text or HTML and optionally take a screenshot"
+}
+
+func (bt *BrowserTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (bt *BrowserTool) Tags() []string {
+	return []string{TagNetwork, TagSlow}
+}
+
+func (bt *BrowserTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"url":        {Type: schema.TypeString, Description: "Absolute http or https URL to load", MinLength: schema.Int(1)},
+		"wait_for":   {Type: schema.TypeString, Description: "CSS selector to wait for until it is visible"},
+		"delay":      {Type: schema.TypeInteger, Description: "Additional milliseconds to wait after the page has loaded", Minimum: schema.Float(0), Maximum: schema.Float(maxBrowserDelay)},
+		"selector":   {Type: schema.TypeString, Description: "CSS selector of the elements to extract; the whole page when omitted"},
+		"extract":    {Type: schema.TypeString, Description: "Content to extract", Enum: []interface{}{BrowserExtractText, BrowserExtractHTML, BrowserExtractNone}, Default: BrowserExtractText},
+		"screenshot": {Type: schema.TypeBoolean, Description: "Return a PNG screenshot as image content"},
+		"full_page":  {Type: schema.TypeBoolean, Description: "Capture the whole page instead of the viewport"},
+	}, "url").Closed()
+}
+
+func (bt *BrowserTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var browserArgs BrowserArgs
+	if err := json.Unmarshal(args, &browserArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	prop := "innerText"
+	switch browserArgs.Extract {
+	case "", BrowserExtractText:
+	case BrowserExtractHTML:
+		prop = "outerHTML"
+	case BrowserExtractNone:
+		prop = ""
+	default:
+		return nil, fmt.Errorf("unsupported extract: %s", browserArgs.Extract)
+	}
+	if browserArgs.Delay < 0 || browserArgs.Delay > maxBrowserDelay {
+		return nil, fmt.Errorf("delay must be between 0 and %d", maxBrowserDelay)
+	}
+
+	target, err := url.Parse(browserArgs.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %v", err)
+	}
+	if err := bt.fetch.checkResolved(ctx, target); err != nil {
+		return nil, err
+	}
+
+	tabCtx, release, err := bt.pool.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	tabCtx, cancel := context.WithTimeout(tabCtx, time.Duration(bt.config.Timeout)*time.Second)
+	defer cancel()
+
+	blocked := bt.interceptRequests(tabCtx)
+
+	actions := []chromedp.Action{
+		fetch.Enable(),
+		chromedp.EmulateViewport(int64(bt.config.ViewportWidth), int64(bt.config.ViewportHeight)),
+		chromedp.Navigate(target.String()),
+	}
+	if browserArgs.WaitFor != "" {
+		actions = append(actions, chromedp.WaitVisible(browserArgs.WaitFor, chromedp.ByQuery))
+	}
+	if browserArgs.Delay > 0 {
+		actions = append(actions, chromedp.Sleep(time.Duration(browserArgs.Delay)*time.Millisecond))
+	}
+
+	var result BrowserResult
+	var content []string
+	actions = append(actions, chromedp.Location(&result.URL), chromedp.Title(&result.Title))
+	if prop != "" {
+		selector, _ := json.Marshal(browserArgs.Selector)
+		actions = append(actions, chromedp.Evaluate(fmt.Sprintf(browserExtractScript, selector, prop), &content))
+	}
+	var screenshot []byte
+	if browserArgs.Screenshot {
+		if browserArgs.FullPage {
+			actions = append(actions, chromedp.FullScreenshot(&screenshot, 100))
+		} else {
+			actions = append(actions, chromedp.CaptureScreenshot(&screenshot))
+		}
+	}
+
+	if err := chromedp.Run(tabCtx, actions...); err != nil {
+		if ctx.Err() == nil && tabCtx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("browser timed out after %ds", bt.config.Timeout)
+		}
+		return nil, fmt.Errorf("browser failed: %w", err)
+	}
+
+	result.Content, result.Truncated = limitContent(content, bt.config.MaxContentBytes)
+	result.Blocked = blocked.list()
+	if len(screenshot) > 0 {
+		if len(screenshot) > bt.config.MaxScreenshotBytes {
+			return nil, fmt.Errorf("screenshot exceeds %d bytes", bt.config.MaxScreenshotBytes)
+		}
+		result.Screenshot = base64.StdEncoding.EncodeToString(screenshot)
+		result.MimeType = "image/png"
+	}
+	return json.Marshal(result)
+}
+
+// ResultContent 截图以图片内容返回，并附带不含图片数据的文本结果
+func (bt *BrowserTool) ResultContent(result json.RawMessage) ([]ToolCallContent, error) {
+	var browserResult BrowserResult
+	if err := json.Unmarshal(result, &browserResult); err != nil {
+		return nil, err
+	}
+	if browserResult.Screenshot == "" {
+		return nil, nil
+	}
+	image := browserResult.Screenshot
+	browserResult.Screenshot = ""
+	summary, err := json.Marshal(browserResult)
+	if err != nil {
+		return nil, err
+	}
+	return []ToolCallContent{
+		{Type: "image", Data: image, MimeType: browserResult.MimeType},
+		{Type: "text", Text: string(summary)},
+	}, nil
+}
+
+// Close 关闭共享的浏览器进程
+func (bt *BrowserTool) Close() error {
+	bt.pool.close()
+	return nil
+}
+
+// blockedRequests 被拦截的请求地址
+type blockedRequests struct {
+	mu   sync.Mutex
+	urls []string
+}
+
+func (b *blockedRequests) add(u string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.urls) < maxBrowserBlockedReported {
+		b.urls = append(b.urls, u)
+	}
+}
+
+func (b *blockedRequests) list() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.urls...)
+}
+
+// interceptRequests 按访问策略放行或拦截标签页发出的请求；data、blob 与 about 地址不经过网络，直接放行
+func (bt *BrowserTool) interceptRequests(tabCtx context.Context) *blockedRequests {
+	blocked := &blockedRequests{}
+	chromedp.ListenTarget(tabCtx, func(ev interface{}) {
+		paused, ok := ev.(*fetch.EventRequestPaused)
+		if !ok {
+			return
+		}
+		go func() {
+			execCtx := cdp.WithExecutor(tabCtx, chromedp.FromContext(tabCtx).Target)
+			u, err := url.Parse(paused.Request.URL)
+			if err == nil {
+				switch u.Scheme {
+				case "data", "blob", "about":
+				default:
+					err = bt.fetch.checkResolved(tabCtx, u)
+				}
+			}
+			if err != nil {
+				blocked.add(paused.Request.URL)
+				fetch.FailRequest(paused.RequestID, network.ErrorReasonBlockedByClient).Do(execCtx)
+				return
+			}
+			fetch.ContinueRequest(paused.RequestID).Do(execCtx)
+		}()
+	})
+	return blocked
+}
+
+// limitContent 将内容总大小限制在 maxBytes 以内，按 UTF-8 字符边界截断
+func limitContent(content []string, maxBytes int) ([]string, bool) {
+	remaining := maxBytes
+	for i, s := range content {
+		if len(s) <= remaining {
+			remaining -= len(s)
+			continue
+		}
+		cut := remaining
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		content[i] = strings.ToValidUTF8(s[:cut], "")
+		return content[:i+1], true
+	}
+	return content, false
+}
+
+// browserPool 共享的浏览器进程
+//
+// 首次使用时启动浏览器，启动失败时下次调用重试；以信号量限制同时打开的标签页数，
+// 最后一个标签页关闭后开始计时，空闲超时后关闭浏览器进程。
+type browserPool struct {
+	config config.BrowserConfig
+	tabs   chan struct{}
+
+	mu        sync.Mutex
+	browser   context.Context
+	cancel    context.CancelFunc
+	active    int
+	idleTimer *time.Timer
+}
+
+func newBrowserPool(cfg config.BrowserConfig) *browserPool {
+	return &browserPool{config: cfg, tabs: make(chan struct{}, cfg.MaxTabs)}
+}
+
+// acquire 打开新标签页，调用方上下文取消时标签页随之关闭
+func (p *browserPool) acquire(ctx context.Context) (context.Context, func(), error) {
+	select {
+	case p.tabs <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil, fmt.Errorf("no browser tab available (limit %d): %v", cap(p.tabs), ctx.Err())
+	}
+
+	p.mu.Lock()
+	if p.browser == nil || p.browser.Err() != nil {
+		if err := p.start(); err != nil {
+			p.mu.Unlock()
+			<-p.tabs
+			return nil, nil, err
+		}
+	}
+	if p.idleTimer != nil {
+		p.idleTimer.Stop()
+		p.idleTimer = nil
+	}
+	p.active++
+	tabCtx, closeTab := chromedp.NewContext(p.browser)
+	p.mu.Unlock()
+
+	stop := context.AfterFunc(ctx, closeTab)
+	release := func() {
+		stop()
+		closeTab()
+		<-p.tabs
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.active--
+		if p.active == 0 && p.browser != nil {
+			p.idleTimer = time.AfterFunc(time.Duration(p.config.IdleTimeout)*time.Second, p.closeIdle)
+		}
+	}
+	return tabCtx, release, nil
+}
+
+// start 启动浏览器进程，调用方持有锁
+func (p *browserPool) start() error {
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.WindowSize(p.config.ViewportWidth, p.config.ViewportHeight),
+		chromedp.Flag("no-proxy-server", true),
+		chromedp.Flag("mute-audio", true),
+	)
+	if p.config.ExecPath != "" {
+		opts = append(opts, chromedp.ExecPath(p.config.ExecPath))
+	}
+	if p.config.NoSandbox {
+		opts = append(opts, chromedp.NoSandbox)
+	}
+
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), opts...)
+	browser, cancelBrowser := chromedp.NewContext(allocCtx)
+	// 首次 Run 的上下文决定浏览器进程的生命周期，不能附加超时
+	if err := chromedp.Run(browser); err != nil {
+		cancelBrowser()
+		cancelAlloc()
+		return fmt.Errorf("failed to start browser: %v", err)
+	}
+
+	p.browser = browser
+	p.cancel = func() {
+		cancelBrowser()
+		cancelAlloc()
+	}
+	return nil
+}
+
+// closeIdle 空闲超时后关闭浏览器
+func (p *browserPool) closeIdle() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active == 0 {
+		p.shutdown()
+	}
+}
+
+func (p *browserPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.shutdown()
+}
+
+// shutdown 关闭浏览器进程，调用方持有锁
+func (p *browserPool) shutdown() {
+	if p.idleTimer != nil {
+		p.idleTimer.Stop()
+		p.idleTimer = nil
+	}
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+	p.browser = nil
+}
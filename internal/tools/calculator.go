@@ -1,71 +1,253 @@
-package tools
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-)
-
-// CalculatorTool 计算器工具
-type CalculatorTool struct{}
-
-// CalculatorArgs 计算器参数
-type CalculatorArgs struct {
-	Operation string    `json:"operation"` // add, subtract, multiply, divide
-	A         float64   `json:"a"`
-	B         float64   `json:"b"`
-	Operands  []float64 `json:"operands"`
-}
-
-// CalculatorResult 计算结果
-type CalculatorResult struct {
-	Result float64 `json:"result"`
-}
-
-func (ct *CalculatorTool) Name() string {
-	return "calculator"
-}
-
-func (ct *CalculatorTool) Description() string {
-	return "Perform basic arithmetic operations (add, subtract, multiply, divide)"
-}
-
-func (ct *CalculatorTool) Category() ToolCategory {
-	return CategoryMath
-}
-
-func (ct *CalculatorTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
-	var calcArgs CalculatorArgs
-	if err := json.Unmarshal(args, &calcArgs); err != nil {
-		return nil, fmt.Errorf("invalid arguments: %v", err)
-	}
-
-	// 支持两种参数格式：{"a":10,"b":20} 或 {"operands":[10,20]}
-	var a, b float64
-	if len(calcArgs.Operands) >= 2 {
-		a = calcArgs.Operands[0]
-		b = calcArgs.Operands[1]
-	} else {
-		a = calcArgs.A
-		b = calcArgs.B
-	}
-
-	var result float64
-	switch calcArgs.Operation {
-	case "add":
-		result = a + b
-	case "subtract":
-		result = a - b
-	case "multiply":
-		result = a * b
-	case "divide":
-		if b == 0 {
-			return nil, fmt.Errorf("division by zero")
-		}
-		result = a / b
-	default:
-		return nil, fmt.Errorf("unsupported operation: %s", calcArgs.Operation)
-	}
-
-	return json.Marshal(CalculatorResult{Result: result})
-}
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"Weave-Toolkit/internal/expr"
+	"Weave-Toolkit/internal/schema"
+)
+
+// calculator 默认限制
+const (
+	defaultDecimalPrecision = 20
+	maxDecimalPrecision     = 1000
+	maxRoundDigits          = 15
+)
+
+// CalculatorTool 计算器工具
+//
+// 对两个或多个操作数执行四则运算与取模，对操作数列表求和、最值与均值，对单个操作数开方、取绝对值与舍入，
+// 或通过 evaluate 计算带括号、变量与函数的表达式。
+// decimal 模式按精确的十进制有理数计算，数值参数按原始十进制位数读取，避免 0.1+0.2 这类二进制浮点误差，
+// 适用于金额等不能容忍舍入误差的计算。
+type CalculatorTool struct{}
+
+// CalculatorArgs 计算器参数
+type CalculatorArgs struct {
+	Operation  string             `json:"operation"` // add, subtract, multiply, divide, mod, pow, sum, min, max, mean, sqrt, abs, round, evaluate
+	A          float64            `json:"a"`
+	B          float64            `json:"b"`
+	Operands   []float64          `json:"operands"`
+	Expression string             `json:"expression,omitempty"` // evaluate 的表达式
+	Variables  map[string]float64 `json:"variables,omitempty"`  // 表达式中的变量
+	Decimal    bool               `json:"decimal,omitempty"`    // 按十进制精确计算
+	Precision  *int               `json:"precision,omitempty"`  // decimal 模式下 value 保留的小数位数
+	Digits     int                `json:"digits,omitempty"`     // round 保留的小数位数
+	Angle      string             `json:"angle,omitempty"`      // 三角函数的角度单位：rad, deg
+}
+
+// CalculatorResult 计算结果
+type CalculatorResult struct {
+	Result    float64 `json:"result"`
+	Value     string  `json:"value,omitempty"`     // decimal 模式下的精确结果
+	Formatted string  `json:"formatted,omitempty"` // 客户端指定区域设置时按其格式化的结果
+	Locale    string  `json:"locale,omitempty"`
+}
+
+func (ct *CalculatorTool) Name() string {
+	return "calculator"
+}
+
+func (ct *CalculatorTool) Description() string {
+	return "Perform arithmetic and reductions (sum, min, max, mean) over a list of operands, mod, pow, sqrt, abs and round, or evaluate expressions with precedence, parentheses, variables and functions such as sin, ln and pow; optional exact decimal mode"
+}
+
+func (ct *CalculatorTool) Category() ToolCategory {
+	return CategoryMath
+}
+
+func (ct *CalculatorTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"operation": {
+			Type:        schema.TypeString,
+			Description: "add, subtract, multiply, divide and mod apply left to right over two or more operands; sum, min, max and mean reduce one or more operands; pow takes base and exponent; sqrt, abs and round take a single operand; evaluate computes an expression",
+			Enum:        []interface{}{"add", "subtract", "multiply", "divide", "mod", "pow", "sum", "min", "max", "mean", "sqrt", "abs", "round", "evaluate"},
+		},
+		"a":          {Type: schema.TypeNumber, Description: "First operand, or the only operand of sqrt, abs and round"},
+		"b":          {Type: schema.TypeNumber, Description: "Second operand"},
+		"operands":   {Type: schema.TypeArray, Description: "Operands as a list of any length, overrides a and b", Items: &schema.Schema{Type: schema.TypeNumber}, MinItems: schema.Int(1)},
+		"expression": {Type: schema.TypeString, Description: "Expression for evaluate, e.g. 2 * sin(pi / 6) + x ^ 2", MinLength: schema.Int(1)},
+		"variables":  {Type: schema.TypeObject, Description: "Variable values referenced by the expression"},
+		"decimal":    {Type: schema.TypeBoolean, Description: "Compute exactly in arbitrary-precision decimal, keeping every digit of the numeric arguments, and return the result as a decimal string in value; transcendental functions are not available"},
+		"precision":  {Type: schema.TypeInteger, Description: "Decimal places of value in decimal mode", Default: defaultDecimalPrecision, Minimum: schema.Float(0), Maximum: schema.Float(maxDecimalPrecision)},
+		"digits":     {Type: schema.TypeInteger, Description: "Decimal places kept by round", Default: 0, Minimum: schema.Float(0), Maximum: schema.Float(maxRoundDigits)},
+		"angle":      {Type: schema.TypeString, Description: "Angle unit for trigonometric functions", Enum: []interface{}{"rad", "deg"}, Default: "rad"},
+	}, "operation")
+}
+
+func (ct *CalculatorTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var calcArgs CalculatorArgs
+	if err := json.Unmarshal(args, &calcArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+
+	var calculation *expr.Expr
+	var opts expr.Options
+	if calcArgs.Operation == "evaluate" {
+		if calcArgs.Expression == "" {
+			return nil, fmt.Errorf("expression is required for evaluate")
+		}
+		parsed, err := expr.Parse(calcArgs.Expression)
+		if err != nil {
+			return nil, err
+		}
+		calculation = parsed
+		opts = expr.Options{Variables: calcArgs.Variables, Degrees: calcArgs.Angle == "deg"}
+	} else {
+		// 支持两种参数格式：{"a":10,"b":20} 或 {"operands":[10,20,30]}；单操作数运算只取 a
+		operands := operandList(calcArgs.Operation, calcArgs.Operands, calcArgs.A, calcArgs.B)
+		source, variables, err := operandsExpression(calcArgs.Operation, operands, calcArgs.Digits)
+		if err != nil {
+			return nil, err
+		}
+		if calculation, err = expr.Parse(source); err != nil {
+			return nil, err
+		}
+		opts = expr.Options{Variables: variables}
+	}
+
+	var calcResult CalculatorResult
+	if calcArgs.Decimal {
+		precision := defaultDecimalPrecision
+		if calcArgs.Precision != nil {
+			precision = min(max(*calcArgs.Precision, 0), maxDecimalPrecision)
+		}
+		exactVariables, err := decimalVariables(args, calcArgs.Operation)
+		if err != nil {
+			return nil, err
+		}
+		opts.Exact = exactVariables
+		exact, err := calculation.EvalDecimal(opts)
+		if err != nil {
+			return nil, err
+		}
+		calcResult.Result, _ = exact.Float64()
+		if math.IsInf(calcResult.Result, 0) {
+			return nil, fmt.Errorf("result exceeds the float64 range")
+		}
+		calcResult.Value = expr.FormatDecimal(exact, precision)
+	} else {
+		result, err := calculation.Eval(opts)
+		if err != nil {
+			return nil, err
+		}
+		calcResult.Result = result
+	}
+
+	if tag, ok := contextLocale(ctx); ok {
+		formatter := NewFormatter(tag)
+		calcResult.Formatted = formatter.Number(calcResult.Result)
+		calcResult.Locale = formatter.Locale()
+	}
+	return json.Marshal(calcResult)
+}
+
+// exactArguments 以 json.Number 解码的数值参数，保留超出 float64 精度的十进制位数
+type exactArguments struct {
+	A         json.Number            `json:"a"`
+	B         json.Number            `json:"b"`
+	Operands  []json.Number          `json:"operands"`
+	Variables map[string]json.Number `json:"variables"`
+}
+
+// decimalVariables 重新解码参数中的数值，返回 decimal 模式下与求值变量同名的精确值，
+// 使 12345678901234567890.12 这类超出 float64 精度的金额按原值参与计算
+func decimalVariables(args json.RawMessage, operation string) (map[string]*big.Rat, error) {
+	decoder := json.NewDecoder(bytes.NewReader(args))
+	decoder.UseNumber()
+	var exact exactArguments
+	if err := decoder.Decode(&exact); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+
+	numbers := exact.Variables
+	if operation != "evaluate" {
+		operands := operandList(operation, exact.Operands, exact.A, exact.B)
+		numbers = make(map[string]json.Number, len(operands))
+		for i, v := range operands {
+			numbers["x"+strconv.Itoa(i)] = v
+		}
+	}
+	values := make(map[string]*big.Rat, len(numbers))
+	for name, number := range numbers {
+		if number == "" {
+			values[name] = new(big.Rat)
+			continue
+		}
+		value, ok := new(big.Rat).SetString(number.String())
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not a decimal number", ErrInvalidArguments, number)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// operandList 返回参与运算的操作数：operands 优先，否则取 a、b，单操作数运算只取 a
+func operandList[T any](operation string, operands []T, a, b T) []T {
+	if len(operands) > 0 {
+		return operands
+	}
+	if unaryOperations[operation] {
+		return []T{a}
+	}
+	return []T{a, b}
+}
+
+// 左结合的二元运算符
+var operandOperators = map[string]string{"add": " + ", "subtract": " - ", "multiply": " * ", "divide": " / ", "mod": " % "}
+
+// 对一个或多个操作数归约的函数
+var reductionFunctions = map[string]string{"sum": "sum", "min": "min", "max": "max", "mean": "avg"}
+
+// 只接受一个操作数的运算
+var unaryOperations = map[string]bool{"sqrt": true, "abs": true, "round": true}
+
+// operandsExpression 将操作数运算转换为以变量 x0、x1... 表示操作数的表达式，与 evaluate 共用求值逻辑
+func operandsExpression(operation string, operands []float64, digits int) (string, map[string]float64, error) {
+	names := make([]string, len(operands))
+	variables := make(map[string]float64, len(operands))
+	for i, v := range operands {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", nil, fmt.Errorf("operand %d is not a finite number", i)
+		}
+		names[i] = "x" + strconv.Itoa(i)
+		variables[names[i]] = v
+	}
+
+	if operator, ok := operandOperators[operation]; ok {
+		if len(operands) < 2 {
+			return "", nil, fmt.Errorf("%s requires at least 2 operands, got %d", operation, len(operands))
+		}
+		return strings.Join(names, operator), variables, nil
+	}
+	if function, ok := reductionFunctions[operation]; ok {
+		return function + "(" + strings.Join(names, ", ") + ")", variables, nil
+	}
+	switch {
+	case operation == "pow":
+		if len(operands) != 2 {
+			return "", nil, fmt.Errorf("pow requires exactly 2 operands, got %d", len(operands))
+		}
+		return "x0 ^ x1", variables, nil
+	case unaryOperations[operation]:
+		if len(operands) != 1 {
+			return "", nil, fmt.Errorf("%s requires exactly 1 operand, got %d", operation, len(operands))
+		}
+		if operation != "round" {
+			return operation + "(x0)", variables, nil
+		}
+		if digits < 0 || digits > maxRoundDigits {
+			return "", nil, fmt.Errorf("digits must be between 0 and %d", maxRoundDigits)
+		}
+		return "round(x0, " + strconv.Itoa(digits) + ")", variables, nil
+	}
+	return "", nil, fmt.Errorf("unsupported operation: %s", operation)
+}
@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// callStatsSamples 每个工具与分类保留的最近耗时样本数，分位数按这些样本计算
+const callStatsSamples = 1024
+
+// ToolCallStats 单个工具或分类的调用统计
+type ToolCallStats struct {
+	Name        string       `json:"name"`
+	Category    ToolCategory `json:"category,omitempty"` // 仅工具统计
+	Calls       int64        `json:"calls"`              // 已结束的调用数，包括参数校验失败等未执行的调用
+	Errors      int64        `json:"errors"`
+	Running     int64        `json:"running"` // 正在执行的调用数
+	AvgMs       float64      `json:"avg_ms"`
+	P50Ms       float64      `json:"p50_ms"`
+	P95Ms       float64      `json:"p95_ms"`
+	P99Ms       float64      `json:"p99_ms"`
+	LastError   string       `json:"last_error,omitempty"`
+	LastErrorAt *time.Time   `json:"last_error_at,omitempty"`
+}
+
+// callCounter 单个工具或分类的累计数据
+type callCounter struct {
+	category    ToolCategory
+	calls       int64
+	errors      int64
+	running     int64
+	total       time.Duration
+	samples     []time.Duration // 环形缓冲
+	next        int
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// CallStats 按工具与分类统计调用次数、错误、耗时分位数与正在执行的调用
+type CallStats struct {
+	mu         sync.Mutex
+	tools      map[string]*callCounter
+	categories map[ToolCategory]*callCounter
+}
+
+// NewCallStats 创建调用统计
+func NewCallStats() *CallStats {
+	return &CallStats{
+		tools:      make(map[string]*callCounter),
+		categories: make(map[ToolCategory]*callCounter),
+	}
+}
+
+// counters 获取工具及其分类的累计数据，调用方持有锁
+func (cs *CallStats) counters(name string, category ToolCategory) (*callCounter, *callCounter) {
+	tool, exists := cs.tools[name]
+	if !exists {
+		tool = &callCounter{category: category}
+		cs.tools[name] = tool
+	}
+	tool.category = category
+	group, exists := cs.categories[category]
+	if !exists {
+		group = &callCounter{}
+		cs.categories[category] = group
+	}
+	return tool, group
+}
+
+// begin 记录开始执行的调用，返回结束时调用的函数
+func (cs *CallStats) begin(name string, category ToolCategory) func() {
+	cs.mu.Lock()
+	tool, group := cs.counters(name, category)
+	tool.running++
+	group.running++
+	cs.mu.Unlock()
+
+	return func() {
+		cs.mu.Lock()
+		tool.running--
+		group.running--
+		cs.mu.Unlock()
+	}
+}
+
+// record 记录已结束的调用，不存在的工具（分类为空）不计入，避免任意名称占用内存
+func (cs *CallStats) record(record CallRecord) {
+	if record.Category == "" {
+		return
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	tool, group := cs.counters(record.Tool, record.Category)
+	for _, counter := range []*callCounter{tool, group} {
+		counter.calls++
+		counter.total += record.Duration
+		if len(counter.samples) < callStatsSamples {
+			counter.samples = append(counter.samples, record.Duration)
+		} else {
+			counter.samples[counter.next] = record.Duration
+			counter.next = (counter.next + 1) % callStatsSamples
+		}
+		if record.Status == CallStatusError {
+			counter.errors++
+			counter.lastError = record.Error
+			counter.lastErrorAt = record.StartedAt.Add(record.Duration)
+		}
+	}
+}
+
+// Stats 按名称排序的工具统计与分类统计
+func (cs *CallStats) Stats() (tools, categories []ToolCallStats) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	tools = make([]ToolCallStats, 0, len(cs.tools))
+	for name, counter := range cs.tools {
+		item := counter.snapshot(name)
+		item.Category = counter.category
+		tools = append(tools, item)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+
+	categories = make([]ToolCallStats, 0, len(cs.categories))
+	for category, counter := range cs.categories {
+		categories = append(categories, counter.snapshot(string(category)))
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Name < categories[j].Name })
+	return tools, categories
+}
+
+// snapshot 汇总累计数据，调用方持有锁
+func (c *callCounter) snapshot(name string) ToolCallStats {
+	item := ToolCallStats{
+		Name:      name,
+		Calls:     c.calls,
+		Errors:    c.errors,
+		Running:   c.running,
+		LastError: c.lastError,
+	}
+	if c.calls > 0 {
+		item.AvgMs = milliseconds(c.total / time.Duration(c.calls))
+	}
+	if len(c.samples) > 0 {
+		sorted := append([]time.Duration(nil), c.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		item.P50Ms = milliseconds(percentile(sorted, 0.50))
+		item.P95Ms = milliseconds(percentile(sorted, 0.95))
+		item.P99Ms = milliseconds(percentile(sorted, 0.99))
+	}
+	if !c.lastErrorAt.IsZero() {
+		lastErrorAt := c.lastErrorAt
+		item.LastErrorAt = &lastErrorAt
+	}
+	return item
+}
+
+// milliseconds 以毫秒表示耗时，保留三位小数
+func milliseconds(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Microsecond)) / 1000
+}
+
+// percentile 按最近秩法取已排序样本的分位数
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// CallStats 获取按工具与分类汇总的调用统计
+func (tm *ToolManager) CallStats() map[string]interface{} {
+	tools, categories := tm.stats.Stats()
+	return map[string]interface{}{
+		"tools":      tools,
+		"categories": categories,
+	}
+}
@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"context"
+	"strings"
+)
+
+// 客户端可在 initialize 中声明的能力
+const (
+	CapabilitySampling    = "sampling"
+	CapabilityElicitation = "elicitation"
+	CapabilityRoots       = "roots"
+)
+
+// ClientCapabilities initialize 时客户端声明的能力，保持客户端发送的原始结构
+type ClientCapabilities map[string]interface{}
+
+// Supports 客户端是否声明了能力，name 可用 . 访问子项，如 roots.listChanged、experimental.streaming
+//
+// 能力存在且值不为 null 或 false 即视为支持，空对象 {} 表示支持。
+func (c ClientCapabilities) Supports(name string) bool {
+	var value interface{} = map[string]interface{}(c)
+	for _, part := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if value, ok = object[part]; !ok {
+			return false
+		}
+	}
+	return value != nil && value != false
+}
+
+// clientCapabilitiesContextKey 客户端能力上下文键
+type clientCapabilitiesContextKey struct{}
+
+// WithClientCapabilities 在上下文中记录会话客户端声明的能力
+func WithClientCapabilities(ctx context.Context, capabilities ClientCapabilities) context.Context {
+	return context.WithValue(ctx, clientCapabilitiesContextKey{}, capabilities)
+}
+
+// ClientCapabilitiesFromContext 获取客户端声明的能力，不属于会话的调用返回 nil
+func ClientCapabilitiesFromContext(ctx context.Context) ClientCapabilities {
+	capabilities, _ := ctx.Value(clientCapabilitiesContextKey{}).(ClientCapabilities)
+	return capabilities
+}
+
+// ClientSupports 发起调用的客户端是否声明了能力，工具据此决定是否使用依赖客户端的功能
+func ClientSupports(ctx context.Context, name string) bool {
+	return ClientCapabilitiesFromContext(ctx).Supports(name)
+}
@@ -0,0 +1,324 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/wcharczuk/go-chart/v2"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/schema"
+)
+
+// chart 工具默认限制
+const (
+	defaultChartWidth     = 800
+	defaultChartHeight    = 480
+	minChartSize          = 100
+	defaultChartMaxWidth  = 2000
+	defaultChartMaxHeight = 2000
+	defaultChartMaxPoints = 10000
+)
+
+// 图表格式与 MIME 类型
+const (
+	ChartFormatPNG = "png"
+	ChartFormatSVG = "svg"
+)
+
+var chartMimeTypes = map[string]string{
+	ChartFormatPNG: "image/png",
+	ChartFormatSVG: "image/svg+xml",
+}
+
+// chartRenderer go-chart 中各类图表共有的渲染方法
+type chartRenderer interface {
+	Render(rp chart.RendererProvider, w io.Writer) error
+}
+
+// ChartTool 图表工具
+//
+// 根据 JSON 数据系列渲染折线图、柱状图或饼图，结果以 PNG 或 SVG 图片内容返回，
+// 同时附带不含图片数据的文本摘要，便于不支持图片的客户端展示。
+type ChartTool struct {
+	config config.ChartConfig
+}
+
+// ChartSeries 数据系列
+type ChartSeries struct {
+	Name   string    `json:"name"`
+	Values []float64 `json:"values"`
+	X      []float64 `json:"x"` // 折线图的横坐标，默认为 0, 1, 2...
+}
+
+// ChartArgs 图表参数
+type ChartArgs struct {
+	Type   string        `json:"type"` // line, bar, pie
+	Title  string        `json:"title"`
+	Series []ChartSeries `json:"series"`
+	Labels []string      `json:"labels"` // 柱状图与饼图的分类名称，折线图的横坐标刻度
+	XLabel string        `json:"x_label"`
+	YLabel string        `json:"y_label"`
+	Width  int           `json:"width"`
+	Height int           `json:"height"`
+	Format string        `json:"format"` // png, svg
+}
+
+// ChartResult 图表结果，Data 为 base64 编码的图片
+type ChartResult struct {
+	Type     string `json:"type"`
+	Format   string `json:"format"`
+	MimeType string `json:"mime_type"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Series   int    `json:"series"`
+	Points   int    `json:"points"`
+	Data     string `json:"data,omitempty"`
+}
+
+// NewChartTool 创建图表工具
+func NewChartTool(cfg config.ChartConfig) *ChartTool {
+	if cfg.MaxWidth <= 0 {
+		cfg.MaxWidth = defaultChartMaxWidth
+	}
+	if cfg.MaxHeight <= 0 {
+		cfg.MaxHeight = defaultChartMaxHeight
+	}
+	if cfg.MaxPoints <= 0 {
+		cfg.MaxPoints = defaultChartMaxPoints
+	}
+	return &ChartTool{config: cfg}
+}
+
+func (ct *ChartTool) Name() string {
+	return "chart"
+}
+
+func (ct *ChartTool) Description() string {
+	return "Render line, bar or pie charts from JSON series data and return the PNG or SVG image"
+}
+
+func (ct *ChartTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (ct *ChartTool) InputSchema() *schema.Schema {
+	series := schema.Object(map[string]*schema.Schema{
+		"name":   {Type: schema.TypeString, Description: "Series name shown in the legend"},
+		"values": {Type: schema.TypeArray, Description: "Data values", Items: &schema.Schema{Type: schema.TypeNumber}, MinItems: schema.Int(1)},
+		"x":      {Type: schema.TypeArray, Description: "X values for line charts, same length as values", Items: &schema.Schema{Type: schema.TypeNumber}},
+	}, "values").Closed()
+
+	return schema.Object(map[string]*schema.Schema{
+		"type":    {Type: schema.TypeString, Description: "Chart type; bar and pie use the first series only", Enum: []interface{}{"line", "bar", "pie"}},
+		"title":   {Type: schema.TypeString, Description: "Chart title"},
+		"series":  {Type: schema.TypeArray, Description: "Data series", Items: series, MinItems: schema.Int(1)},
+		"labels":  {Type: schema.TypeArray, Description: "Category names for bar and pie, x tick labels for line", Items: &schema.Schema{Type: schema.TypeString}},
+		"x_label": {Type: schema.TypeString, Description: "X axis name"},
+		"y_label": {Type: schema.TypeString, Description: "Y axis name"},
+		"width":   {Type: schema.TypeInteger, Description: "Image width in pixels", Default: defaultChartWidth, Minimum: schema.Float(minChartSize)},
+		"height":  {Type: schema.TypeInteger, Description: "Image height in pixels", Default: defaultChartHeight, Minimum: schema.Float(minChartSize)},
+		"format":  {Type: schema.TypeString, Description: "Image format", Enum: []interface{}{ChartFormatPNG, ChartFormatSVG}, Default: ChartFormatPNG},
+	}, "type", "series").Closed()
+}
+
+func (ct *ChartTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var chartArgs ChartArgs
+	if err := json.Unmarshal(args, &chartArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+
+	if chartArgs.Width == 0 {
+		chartArgs.Width = defaultChartWidth
+	}
+	if chartArgs.Height == 0 {
+		chartArgs.Height = defaultChartHeight
+	}
+	if chartArgs.Width < minChartSize || chartArgs.Width > ct.config.MaxWidth ||
+		chartArgs.Height < minChartSize || chartArgs.Height > ct.config.MaxHeight {
+		return nil, fmt.Errorf("chart size must be between %dx%d and %dx%d", minChartSize, minChartSize, ct.config.MaxWidth, ct.config.MaxHeight)
+	}
+	if chartArgs.Format == "" {
+		chartArgs.Format = ChartFormatPNG
+	}
+	mimeType, ok := chartMimeTypes[chartArgs.Format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format: %s", chartArgs.Format)
+	}
+
+	if len(chartArgs.Series) == 0 {
+		return nil, fmt.Errorf("at least one series is required")
+	}
+	points := 0
+	for i, series := range chartArgs.Series {
+		if len(series.Values) == 0 {
+			return nil, fmt.Errorf("series %d has no values", i)
+		}
+		for _, v := range series.Values {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, fmt.Errorf("series %d contains a non-finite value", i)
+			}
+		}
+		points += len(series.Values)
+	}
+	if points > ct.config.MaxPoints {
+		return nil, fmt.Errorf("too many data points: %d exceeds limit %d", points, ct.config.MaxPoints)
+	}
+
+	var renderer chartRenderer
+	switch chartArgs.Type {
+	case "line":
+		graph, err := lineChart(chartArgs)
+		if err != nil {
+			return nil, err
+		}
+		renderer = graph
+	case "bar":
+		graph, err := barChart(chartArgs)
+		if err != nil {
+			return nil, err
+		}
+		renderer = graph
+	case "pie":
+		graph, err := pieChart(chartArgs)
+		if err != nil {
+			return nil, err
+		}
+		renderer = graph
+	default:
+		return nil, fmt.Errorf("unsupported chart type: %s", chartArgs.Type)
+	}
+
+	provider := chart.PNG
+	if chartArgs.Format == ChartFormatSVG {
+		provider = chart.SVG
+	}
+	var buf bytes.Buffer
+	if err := renderer.Render(provider, &buf); err != nil {
+		return nil, fmt.Errorf("render chart: %v", err)
+	}
+
+	return json.Marshal(ChartResult{
+		Type:     chartArgs.Type,
+		Format:   chartArgs.Format,
+		MimeType: mimeType,
+		Width:    chartArgs.Width,
+		Height:   chartArgs.Height,
+		Series:   len(chartArgs.Series),
+		Points:   points,
+		Data:     base64.StdEncoding.EncodeToString(buf.Bytes()),
+	})
+}
+
+// ResultContent 以图片内容返回图表，并附带不含图片数据的文本摘要
+func (ct *ChartTool) ResultContent(result json.RawMessage) ([]ToolCallContent, error) {
+	var chartResult ChartResult
+	if err := json.Unmarshal(result, &chartResult); err != nil {
+		return nil, err
+	}
+	image := chartResult.Data
+	chartResult.Data = ""
+	summary, err := json.Marshal(chartResult)
+	if err != nil {
+		return nil, err
+	}
+	return []ToolCallContent{
+		{Type: "image", Data: image, MimeType: chartResult.MimeType},
+		{Type: "text", Text: string(summary)},
+	}, nil
+}
+
+// lineChart 构建折线图，多个系列时显示图例
+func lineChart(args ChartArgs) (*chart.Chart, error) {
+	graph := &chart.Chart{
+		Title:  args.Title,
+		Width:  args.Width,
+		Height: args.Height,
+		XAxis:  chart.XAxis{Name: args.XLabel},
+		YAxis:  chart.YAxis{Name: args.YLabel},
+	}
+	for i, series := range args.Series {
+		x := series.X
+		if len(x) == 0 {
+			x = make([]float64, len(series.Values))
+			for j := range x {
+				x[j] = float64(j)
+			}
+		} else if len(x) != len(series.Values) {
+			return nil, fmt.Errorf("series %d: x has %d values, expected %d", i, len(x), len(series.Values))
+		}
+		graph.Series = append(graph.Series, chart.ContinuousSeries{
+			Name:    series.Name,
+			XValues: x,
+			YValues: series.Values,
+		})
+	}
+	if len(args.Labels) > 0 {
+		ticks := make([]chart.Tick, len(args.Labels))
+		for i, label := range args.Labels {
+			ticks[i] = chart.Tick{Value: float64(i), Label: label}
+		}
+		graph.XAxis.Ticks = ticks
+	}
+	if len(args.Series) > 1 {
+		graph.Elements = []chart.Renderable{chart.Legend(graph)}
+	}
+	return graph, nil
+}
+
+// barChart 构建柱状图，使用第一个系列的数据
+func barChart(args ChartArgs) (*chart.BarChart, error) {
+	bars, err := chartValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return &chart.BarChart{
+		Title:  args.Title,
+		Width:  args.Width,
+		Height: args.Height,
+		Bars:   bars,
+		YAxis:  chart.YAxis{Name: args.YLabel},
+	}, nil
+}
+
+// pieChart 构建饼图，使用第一个系列的数据，值不能为负
+func pieChart(args ChartArgs) (*chart.PieChart, error) {
+	values, err := chartValues(args)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		if v.Value < 0 {
+			return nil, fmt.Errorf("pie chart values must not be negative")
+		}
+	}
+	return &chart.PieChart{
+		Title:  args.Title,
+		Width:  args.Width,
+		Height: args.Height,
+		Values: values,
+	}, nil
+}
+
+// chartValues 将第一个系列与分类名称组合为带标签的值
+func chartValues(args ChartArgs) ([]chart.Value, error) {
+	series := args.Series[0]
+	if len(args.Labels) > 0 && len(args.Labels) != len(series.Values) {
+		return nil, fmt.Errorf("labels has %d entries, expected %d", len(args.Labels), len(series.Values))
+	}
+	values := make([]chart.Value, len(series.Values))
+	for i, v := range series.Values {
+		label := strconv.Itoa(i + 1)
+		if len(args.Labels) > 0 {
+			label = args.Labels[i]
+		}
+		values[i] = chart.Value{Label: label, Value: v}
+	}
+	return values, nil
+}
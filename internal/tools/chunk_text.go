@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"Weave-Toolkit/internal/chunk"
+	"Weave-Toolkit/internal/schema"
+)
+
+// chunk_text 默认参数
+const (
+	defaultChunkTextChars  = 1000
+	defaultChunkTextTokens = 512
+	maxChunkTextBytes      = 4 << 20
+	maxChunkTextChunks     = 1000
+)
+
+// ChunkTextTool 文本切分工具
+//
+// 按段落、句子或固定大小将长文本切分为片段，大小可按字符或估算的 token 数计算，
+// 便于调用方在上下文长度受限时逐段处理文本。
+type ChunkTextTool struct{}
+
+// ChunkTextArgs 切分参数，size 未指定时字符单位为 1000、token 单位为 512
+type ChunkTextArgs struct {
+	Text     string `json:"text"`
+	Strategy string `json:"strategy"` // paragraph, sentence, fixed
+	Unit     string `json:"unit"`     // characters, tokens
+	Size     int    `json:"size"`
+	Overlap  int    `json:"overlap"`
+}
+
+// ChunkTextResult 切分结果，片段数超过上限时只返回前面的片段
+type ChunkTextResult struct {
+	Strategy  string        `json:"strategy"`
+	Unit      string        `json:"unit"`
+	Size      int           `json:"size"`
+	Count     int           `json:"count"`
+	Truncated bool          `json:"truncated,omitempty"`
+	Chunks    []chunk.Chunk `json:"chunks"`
+}
+
+func (ct *ChunkTextTool) Name() string {
+	return "chunk_text"
+}
+
+func (ct *ChunkTextTool) Description() string {
+	return "Split long text into overlapping chunks by paragraph, sentence or fixed size, measured in characters or estimated tokens"
+}
+
+func (ct *ChunkTextTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (ct *ChunkTextTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"text":     {Type: schema.TypeString, Description: "Text to split", MinLength: schema.Int(1)},
+		"strategy": {Type: schema.TypeString, Description: "Boundaries to keep intact", Enum: []interface{}{chunk.StrategyParagraph, chunk.StrategySentence, chunk.StrategyFixed}, Default: chunk.StrategyParagraph},
+		"unit":     {Type: schema.TypeString, Description: "Unit for size and overlap", Enum: []interface{}{chunk.UnitCharacters, chunk.UnitTokens}, Default: chunk.UnitCharacters},
+		"size":     {Type: schema.TypeInteger, Description: "Maximum chunk size, 1000 characters or 512 tokens by default", Minimum: schema.Float(1)},
+		"overlap":  {Type: schema.TypeInteger, Description: "Size shared by adjacent chunks", Minimum: schema.Float(0)},
+	}, "text").Closed()
+}
+
+func (ct *ChunkTextTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var chunkArgs ChunkTextArgs
+	if err := json.Unmarshal(args, &chunkArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	if len(chunkArgs.Text) > maxChunkTextBytes {
+		return nil, fmt.Errorf("text exceeds %d bytes", maxChunkTextBytes)
+	}
+
+	opts := chunk.Options{Size: chunkArgs.Size, Overlap: chunkArgs.Overlap, Strategy: chunkArgs.Strategy, Unit: chunkArgs.Unit}
+	if opts.Strategy == "" {
+		opts.Strategy = chunk.StrategyParagraph
+	}
+	if opts.Unit == "" {
+		opts.Unit = chunk.UnitCharacters
+	}
+	if opts.Size <= 0 {
+		opts.Size = defaultChunkTextChars
+		if opts.Unit == chunk.UnitTokens {
+			opts.Size = defaultChunkTextTokens
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	chunks := chunk.Split(chunkArgs.Text, opts)
+	result := ChunkTextResult{Strategy: opts.Strategy, Unit: opts.Unit, Size: opts.Size, Count: len(chunks), Chunks: chunks}
+	if len(chunks) > maxChunkTextChunks {
+		result.Chunks = chunks[:maxChunkTextChunks]
+		result.Truncated = true
+	}
+	if result.Chunks == nil {
+		result.Chunks = []chunk.Chunk{}
+	}
+	return json.Marshal(result)
+}
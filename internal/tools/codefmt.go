@@ -0,0 +1,456 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/scanner"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/schema"
+)
+
+// codefmt 默认配置
+const (
+	defaultCodefmtPrettier       = "prettier"
+	defaultCodefmtBlack          = "black"
+	defaultCodefmtTimeout        = 10
+	defaultCodefmtMaxSourceBytes = 256 << 10
+	// codefmtMaxOutputFactor 子进程输出的保留上限，相对代码大小上限的倍数
+	codefmtMaxOutputFactor = 4
+	// codefmtFileToken linter 参数中代码片段文件路径的占位符
+	codefmtFileToken = "{file}"
+)
+
+// 诊断级别
+const (
+	CodefmtSeverityError   = "error"
+	CodefmtSeverityWarning = "warning"
+)
+
+// codefmtExtensions 各语言代码片段文件的扩展名，prettier 据此选择解析器
+var codefmtExtensions = map[string]string{
+	"go":         ".go",
+	"python":     ".py",
+	"javascript": ".js",
+	"typescript": ".ts",
+	"json":       ".json",
+	"css":        ".css",
+	"scss":       ".scss",
+	"html":       ".html",
+	"markdown":   ".md",
+	"yaml":       ".yaml",
+	"graphql":    ".graphql",
+}
+
+// codefmtDefaultPattern 默认的诊断格式 file:line[:column]: message
+var codefmtDefaultPattern = regexp.MustCompile(`^.*?:(?P<line>\d+):(?:(?P<column>\d+):)?\s*(?P<message>.+)$`)
+
+// codefmtPositionPatterns 从格式化器的错误信息中提取行列号，依次匹配 black 的 "3:4:" 与 prettier 的 "(3:4)"
+var codefmtPositionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\((\d+):(\d+)\)`),
+	regexp.MustCompile(`(\d+):(\d+):`),
+}
+
+// CodefmtTool 代码格式化与检查工具
+//
+// Go 代码在进程内按 gofmt 规则格式化，Python 调用 black，JavaScript、TypeScript、JSON、CSS、
+// HTML、Markdown、YAML 等调用 prettier，均通过标准输入输出传递代码。lint 为 true 时再运行配置中
+// 适用于该语言的 linter，按正则从输出中解析诊断。子进程受超时限制，输出超过上限的部分被丢弃。
+type CodefmtTool struct {
+	config config.CodefmtConfig
+}
+
+// CodefmtArgs 格式化参数
+type CodefmtArgs struct {
+	Code      string `json:"code"`
+	Language  string `json:"language"`
+	Format    *bool  `json:"format"`     // 默认 true
+	Lint      bool   `json:"lint"`       // 运行适用于该语言的 linter
+	LineWidth int    `json:"line_width"` // black 与 prettier 的行宽
+}
+
+// CodefmtResult 格式化结果
+type CodefmtResult struct {
+	Language    string              `json:"language"`
+	Formatted   string              `json:"formatted"`
+	Changed     bool                `json:"changed"`
+	Diagnostics []CodefmtDiagnostic `json:"diagnostics,omitempty"`
+}
+
+// CodefmtDiagnostic 格式化错误或 linter 诊断
+type CodefmtDiagnostic struct {
+	Source   string `json:"source"` // gofmt、black、prettier 或 linter 名称
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// NewCodefmtTool 创建代码格式化工具
+func NewCodefmtTool(cfg config.CodefmtConfig) *CodefmtTool {
+	if cfg.Prettier == "" {
+		cfg.Prettier = defaultCodefmtPrettier
+	}
+	if cfg.Black == "" {
+		cfg.Black = defaultCodefmtBlack
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultCodefmtTimeout
+	}
+	if cfg.MaxSourceBytes <= 0 {
+		cfg.MaxSourceBytes = defaultCodefmtMaxSourceBytes
+	}
+	return &CodefmtTool{config: cfg}
+}
+
+func (ct *CodefmtTool) Name() string {
+	return "codefmt"
+}
+
+func (ct *CodefmtTool) Description() string {
+	return "Format code snippets with gofmt, black or prettier and run configured linters, returning formatted code and diagnostics"
+}
+
+func (ct *CodefmtTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (ct *CodefmtTool) Tags() []string {
+	return []string{TagExec}
+}
+
+func (ct *CodefmtTool) InputSchema() *schema.Schema {
+	names := make([]string, 0, len(codefmtExtensions))
+	for language := range codefmtExtensions {
+		names = append(names, language)
+	}
+	sort.Strings(names)
+	languages := make([]interface{}, len(names))
+	for i, language := range names {
+		languages[i] = language
+	}
+	return schema.Object(map[string]*schema.Schema{
+		"code":       {Type: schema.TypeString, Description: "Source code to format"},
+		"language":   {Type: schema.TypeString, Description: "Language of the code", Enum: languages},
+		"format":     {Type: schema.TypeBoolean, Description: "Format the code", Default: true},
+		"lint":       {Type: schema.TypeBoolean, Description: "Run the configured linters for the language", Default: false},
+		"line_width": {Type: schema.TypeInteger, Description: "Maximum line width for black and prettier", Minimum: schema.Float(20), Maximum: schema.Float(400)},
+	}, "code", "language").Closed()
+}
+
+func (ct *CodefmtTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var fmtArgs CodefmtArgs
+	if err := json.Unmarshal(args, &fmtArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	if _, ok := codefmtExtensions[fmtArgs.Language]; !ok {
+		return nil, fmt.Errorf("unsupported language: %s", fmtArgs.Language)
+	}
+	if len(fmtArgs.Code) > ct.config.MaxSourceBytes {
+		return nil, fmt.Errorf("code exceeds %d bytes", ct.config.MaxSourceBytes)
+	}
+
+	result := CodefmtResult{Language: fmtArgs.Language, Formatted: fmtArgs.Code}
+	if fmtArgs.Format == nil || *fmtArgs.Format {
+		formatted, diagnostics, err := ct.format(ctx, fmtArgs)
+		if err != nil {
+			return nil, err
+		}
+		if diagnostics == nil {
+			result.Formatted = formatted
+			result.Changed = formatted != fmtArgs.Code
+		}
+		result.Diagnostics = append(result.Diagnostics, diagnostics...)
+	}
+
+	if fmtArgs.Lint {
+		diagnostics, err := ct.lint(ctx, fmtArgs.Language, result.Formatted)
+		if err != nil {
+			return nil, err
+		}
+		result.Diagnostics = append(result.Diagnostics, diagnostics...)
+	}
+	return json.Marshal(result)
+}
+
+// format 格式化代码，代码无法解析时返回诊断而非错误
+func (ct *CodefmtTool) format(ctx context.Context, args CodefmtArgs) (string, []CodefmtDiagnostic, error) {
+	switch args.Language {
+	case "go":
+		return gofmtSource(args.Code)
+	case "python":
+		cmdArgs := []string{"-q"}
+		if args.LineWidth > 0 {
+			cmdArgs = append(cmdArgs, "--line-length", strconv.Itoa(args.LineWidth))
+		}
+		return ct.runFormatter(ctx, "black", ct.config.Black, append(cmdArgs, "-"), args.Code)
+	}
+	cmdArgs := []string{"--stdin-filepath", "snippet" + codefmtExtensions[args.Language]}
+	if args.LineWidth > 0 {
+		cmdArgs = append(cmdArgs, "--print-width", strconv.Itoa(args.LineWidth))
+	}
+	return ct.runFormatter(ctx, "prettier", ct.config.Prettier, cmdArgs, args.Code)
+}
+
+// gofmtSource 按 gofmt 规则格式化，支持完整文件以及声明或语句片段
+func gofmtSource(code string) (string, []CodefmtDiagnostic, error) {
+	formatted, err := format.Source([]byte(code))
+	if err == nil {
+		return string(formatted), nil, nil
+	}
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		return "", []CodefmtDiagnostic{{Source: "gofmt", Severity: CodefmtSeverityError, Message: err.Error()}}, nil
+	}
+	diagnostics := make([]CodefmtDiagnostic, 0, len(list))
+	for _, e := range list {
+		diagnostics = append(diagnostics, CodefmtDiagnostic{
+			Source:   "gofmt",
+			Line:     e.Pos.Line,
+			Column:   e.Pos.Column,
+			Severity: CodefmtSeverityError,
+			Message:  e.Msg,
+		})
+	}
+	return "", diagnostics, nil
+}
+
+// runFormatter 运行外部格式化器，退出码非零时将错误输出作为诊断
+func (ct *CodefmtTool) runFormatter(ctx context.Context, source, command string, args []string, code string) (string, []CodefmtDiagnostic, error) {
+	stdout, stderr, err := ct.run(ctx, command, args, code)
+	if errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+		return "", nil, err
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", nil, fmt.Errorf("%s is not available: %v", source, err)
+		}
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = exitErr.Error()
+		}
+		diagnostic := CodefmtDiagnostic{Source: source, Severity: CodefmtSeverityError, Message: message}
+		for _, pattern := range codefmtPositionPatterns {
+			if match := pattern.FindStringSubmatch(message); match != nil {
+				diagnostic.Line, _ = strconv.Atoi(match[1])
+				diagnostic.Column, _ = strconv.Atoi(match[2])
+				break
+			}
+		}
+		return "", []CodefmtDiagnostic{diagnostic}, nil
+	}
+	if stdout.truncated {
+		return "", nil, fmt.Errorf("%s output exceeds %d bytes", source, stdout.limit)
+	}
+	return stdout.String(), nil, nil
+}
+
+// lint 运行适用于该语言的 linter，按名称顺序返回诊断
+func (ct *CodefmtTool) lint(ctx context.Context, language, code string) ([]CodefmtDiagnostic, error) {
+	var names []string
+	for name, linter := range ct.config.Linters {
+		for _, l := range linter.Languages {
+			if l == language {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no linter configured for %s", language)
+	}
+	sort.Strings(names)
+
+	var file string
+	for _, name := range names {
+		if argsContain(ct.config.Linters[name].Command, codefmtFileToken) {
+			path, cleanup, err := snippetFile(ctx, "snippet"+codefmtExtensions[language], code)
+			if err != nil {
+				return nil, err
+			}
+			defer cleanup()
+			file = path
+			break
+		}
+	}
+
+	var diagnostics []CodefmtDiagnostic
+	for _, name := range names {
+		found, err := ct.runLinter(ctx, name, ct.config.Linters[name], code, file)
+		if err != nil {
+			return nil, err
+		}
+		diagnostics = append(diagnostics, found...)
+	}
+	return diagnostics, nil
+}
+
+func argsContain(args []string, token string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// snippetFile 将代码写入调用工作区，没有工作区时写入临时目录
+func snippetFile(ctx context.Context, name, code string) (string, func(), error) {
+	if ws, ok := WorkspaceFromContext(ctx); ok {
+		if err := ws.WriteFile(name, []byte(code)); err != nil {
+			return "", nil, err
+		}
+		path, err := ws.Path(name)
+		return path, func() {}, err
+	}
+	dir, err := os.MkdirTemp("", "weave-codefmt-*")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(code), 0600); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return path, cleanup, nil
+}
+
+// runLinter 运行 linter 并从标准输出与错误输出中解析诊断
+//
+// linter 发现问题时通常以非零状态退出，因此只有未能解析出任何诊断时才将错误输出作为诊断返回。
+func (ct *CodefmtTool) runLinter(ctx context.Context, name string, linter config.CodefmtLinterConfig, code, file string) ([]CodefmtDiagnostic, error) {
+	if len(linter.Command) == 0 {
+		return nil, fmt.Errorf("linter %s has no command", name)
+	}
+	pattern := codefmtDefaultPattern
+	if linter.Pattern != "" {
+		compiled, err := regexp.Compile(linter.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("linter %s: invalid pattern: %v", name, err)
+		}
+		pattern = compiled
+	}
+
+	args := make([]string, len(linter.Command)-1)
+	for i, arg := range linter.Command[1:] {
+		args[i] = strings.ReplaceAll(arg, codefmtFileToken, file)
+	}
+	stdin := code
+	if file != "" && argsContain(linter.Command, codefmtFileToken) {
+		stdin = ""
+	}
+	stdout, stderr, err := ct.run(ctx, linter.Command[0], args, stdin)
+	if errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+		return nil, fmt.Errorf("linter %s: %w", name, err)
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return []CodefmtDiagnostic{{Source: name, Severity: CodefmtSeverityError, Message: fmt.Sprintf("linter is not available: %v", err)}}, nil
+	}
+
+	var diagnostics []CodefmtDiagnostic
+	for _, line := range strings.Split(stdout.String()+"\n"+stderr.String(), "\n") {
+		if diagnostic, ok := parseDiagnostic(pattern, strings.TrimRight(line, "\r")); ok {
+			diagnostic.Source = name
+			diagnostics = append(diagnostics, diagnostic)
+		}
+	}
+	if len(diagnostics) == 0 && err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = exitErr.Error()
+		}
+		diagnostics = append(diagnostics, CodefmtDiagnostic{Source: name, Severity: CodefmtSeverityError, Message: message})
+	}
+	return diagnostics, nil
+}
+
+// parseDiagnostic 按命名分组 line、column、severity、message 解析一行输出
+func parseDiagnostic(pattern *regexp.Regexp, line string) (CodefmtDiagnostic, bool) {
+	match := pattern.FindStringSubmatch(line)
+	if match == nil {
+		return CodefmtDiagnostic{}, false
+	}
+	diagnostic := CodefmtDiagnostic{Severity: CodefmtSeverityWarning}
+	for i, group := range pattern.SubexpNames() {
+		switch group {
+		case "line":
+			diagnostic.Line, _ = strconv.Atoi(match[i])
+		case "column":
+			diagnostic.Column, _ = strconv.Atoi(match[i])
+		case "severity":
+			if match[i] != "" {
+				diagnostic.Severity = strings.ToLower(match[i])
+			}
+		case "message":
+			diagnostic.Message = strings.TrimSpace(match[i])
+		}
+	}
+	if diagnostic.Message == "" {
+		diagnostic.Message = strings.TrimSpace(line)
+	}
+	return diagnostic, true
+}
+
+// run 运行子进程，代码从标准输入传入，输出超过上限的部分被丢弃
+//
+// 子进程的超时不超过调用的截止时间，调用先到期时返回调用上下文的错误。
+func (ct *CodefmtTool) run(parent context.Context, command string, args []string, stdin string) (*cappedBuffer, *cappedBuffer, error) {
+	ctx, cancel := context.WithTimeout(parent, time.Duration(ct.config.Timeout)*time.Second)
+	defer cancel()
+
+	limit := ct.config.MaxSourceBytes * codefmtMaxOutputFactor
+	stdout := &cappedBuffer{limit: limit}
+	stderr := &cappedBuffer{limit: limit}
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if parent.Err() != nil {
+		err = parent.Err()
+	} else if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("%s timed out after %ds: %w", command, ct.config.Timeout, context.DeadlineExceeded)
+	}
+	return stdout, stderr, err
+}
+
+// cappedBuffer 只保留前 limit 字节，写入总是成功，避免子进程因管道关闭而失败
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	room := b.limit - b.buf.Len()
+	if len(p) > room {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
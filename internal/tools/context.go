@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"Weave-Toolkit/internal/llm"
+)
+
+// ToolContext 工具调用的请求上下文
+//
+// 由传输层与 ToolManager 在调用前写入 context，工具通过 ToolContextFrom 或单独的 *FromContext 函数读取，
+// 不需要在工具签名中传递回调。
+type ToolContext struct {
+	Client    string
+	SessionID string // 调用不属于任何会话时为空
+	Tenant    *Tenant
+	RequestID string
+	Logger    *zerolog.Logger  // 附带 tool、client、request_id 等关联字段
+	Progress  ProgressReporter // 调用方不接收进度时忽略上报
+	Sampler   Sampler          // 未配置大模型服务时为 nil
+	HTTP      *HTTPClients     // 访问外部服务的共享客户端
+	Blobs     *BlobStore       // 客户端上传的文件，关闭上传时为 nil
+}
+
+// ToolContextFrom 汇总上下文中的请求信息
+func ToolContextFrom(ctx context.Context) ToolContext {
+	return ToolContext{
+		Client:    ClientFromContext(ctx),
+		SessionID: SessionIDFromContext(ctx),
+		Tenant:    TenantFromContext(ctx),
+		RequestID: RequestIDFromContext(ctx),
+		Logger:    LoggerFromContext(ctx),
+		Progress:  ProgressFromContext(ctx),
+		Sampler:   SamplerFromContext(ctx),
+		HTTP:      HTTPClientsFromContext(ctx),
+		Blobs:     BlobStoreFromContext(ctx),
+	}
+}
+
+// requestIDContextKey 请求ID上下文键
+type requestIDContextKey struct{}
+
+// WithRequestID 在上下文中记录请求ID（X-Request-ID）
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext 从上下文中获取请求ID
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// sessionIDContextKey 会话ID上下文键
+type sessionIDContextKey struct{}
+
+// WithSessionID 在上下文中记录调用所属的会话ID
+func WithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDContextKey{}, sessionID)
+}
+
+// SessionIDFromContext 从上下文中获取会话ID
+func SessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionIDContextKey{}).(string)
+	return sessionID
+}
+
+// LoggerFromContext 获取本次调用的日志器，未设置时返回不输出的日志器
+//
+// ToolManager 在调用前写入附带 tool、client、tenant、session_id 与 request_id 字段的日志器，
+// 工具日志可与访问日志按请求关联。
+func LoggerFromContext(ctx context.Context) *zerolog.Logger {
+	return zerolog.Ctx(ctx)
+}
+
+// ProgressReporter 接收工具的执行进度
+type ProgressReporter interface {
+	// Report 上报进度，total 未知时为 0，message 可为空
+	Report(progress, total float64, message string)
+}
+
+// ProgressFunc 以函数实现 ProgressReporter
+type ProgressFunc func(progress, total float64, message string)
+
+// Report 实现 ProgressReporter
+func (f ProgressFunc) Report(progress, total float64, message string) {
+	f(progress, total, message)
+}
+
+// progressContextKey 进度上报上下文键
+type progressContextKey struct{}
+
+// WithProgress 在上下文中设置进度接收者
+func WithProgress(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, reporter)
+}
+
+// ProgressFromContext 获取进度接收者，调用方不接收进度时返回忽略上报的实现，工具可直接调用
+func ProgressFromContext(ctx context.Context) ProgressReporter {
+	if reporter, ok := ctx.Value(progressContextKey{}).(ProgressReporter); ok && reporter != nil {
+		return reporter
+	}
+	return ProgressFunc(func(float64, float64, string) {})
+}
+
+// Sampler 供工具请求大模型补全，如对中间结果做总结或分类
+type Sampler interface {
+	CreateMessage(ctx context.Context, req llm.Request) (*LLMResult, error)
+}
+
+// samplerContextKey 补全接口上下文键
+type samplerContextKey struct{}
+
+// WithSampler 在上下文中设置补全接口，优先于 ToolManager 按 llm 配置提供的默认实现
+func WithSampler(ctx context.Context, sampler Sampler) context.Context {
+	return context.WithValue(ctx, samplerContextKey{}, sampler)
+}
+
+// SamplerFromContext 获取补全接口，不可用时返回 nil
+func SamplerFromContext(ctx context.Context) Sampler {
+	sampler, _ := ctx.Value(samplerContextKey{}).(Sampler)
+	return sampler
+}
+
+// Sample 使用上下文中的补全接口执行补全
+func Sample(ctx context.Context, req llm.Request) (*LLMResult, error) {
+	sampler := SamplerFromContext(ctx)
+	if sampler == nil {
+		return nil, fmt.Errorf("no sampling provider available")
+	}
+	return sampler.CreateMessage(ctx, req)
+}
+
+// llmSampler 以 llm 工具的默认服务实现 Sampler
+type llmSampler struct {
+	tool *LLMTool
+}
+
+// CreateMessage 实现 Sampler
+func (s llmSampler) CreateMessage(ctx context.Context, req llm.Request) (*LLMResult, error) {
+	return s.tool.Complete(ctx, "", req, nil)
+}
+
+// callContext 为本次调用写入关联日志器，上下文中没有补全接口且配置了大模型服务时提供默认实现
+func (tm *ToolManager) callContext(ctx context.Context, name string) context.Context {
+	fields := tm.logger.With().Str("tool", name)
+	if client := ClientFromContext(ctx); client != "" {
+		fields = fields.Str("client", client)
+	}
+	if tenant := TenantFromContext(ctx); tenant != nil {
+		fields = fields.Str("tenant", tenant.Name)
+	}
+	if sessionID := SessionIDFromContext(ctx); sessionID != "" {
+		fields = fields.Str("session_id", sessionID)
+	}
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		fields = fields.Str("request_id", requestID)
+	}
+	ctx = fields.Logger().WithContext(ctx)
+
+	if _, ok := ctx.Value(httpClientsContextKey{}).(*HTTPClients); !ok {
+		tm.mu.RLock()
+		clients := tm.http
+		tm.mu.RUnlock()
+		ctx = WithHTTPClients(ctx, clients)
+	}
+
+	if BlobStoreFromContext(ctx) == nil && tm.blobs != nil {
+		ctx = WithBlobStore(ctx, tm.blobs)
+	}
+
+	if SamplerFromContext(ctx) == nil {
+		tm.mu.RLock()
+		llmConfig := tm.toolConfig.LLM
+		tm.mu.RUnlock()
+		if len(llmConfig.Providers) > 0 {
+			ctx = WithSampler(ctx, llmSampler{tool: NewLLMTool(llmConfig)})
+		}
+	}
+	return ctx
+}
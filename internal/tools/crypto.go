@@ -0,0 +1,420 @@
+package tools
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math"
+	"math/big"
+	"strings"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/schema"
+)
+
+// crypto 工具默认限制
+const (
+	defaultTokenBytes     = 32
+	maxTokenBytes         = 1024
+	defaultPasswordLength = 20
+	minPasswordLength     = 8
+	maxPasswordLength     = 256
+)
+
+// 密码字符集
+const (
+	passwordLower     = "abcdefghijklmnopqrstuvwxyz"
+	passwordUpper     = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	passwordDigits    = "0123456789"
+	passwordSymbols   = "!#$%&()*+,-./:;<=>?@[]^_{|}~"
+	passwordAmbiguous = "Il1O0o|`'\""
+)
+
+// hmacAlgorithms 支持的 HMAC 摘要算法
+var hmacAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// CryptoTool 加密辅助工具
+//
+// 计算与校验 HMAC 签名、生成安全随机令牌与满足策略的密码、以常量时间比较两个值。
+// 密钥可在配置中按名称定义并通过 key_name 引用，避免出现在调用参数、日志与调用历史中。
+type CryptoTool struct {
+	keys map[string]config.CryptoKeyConfig
+}
+
+// CryptoArgs 加密操作参数
+type CryptoArgs struct {
+	Op              string `json:"op"`        // hmac, verify, random, compare
+	Algorithm       string `json:"algorithm"` // sha1, sha256, sha384, sha512
+	Key             string `json:"key"`
+	KeyName         string `json:"key_name"`
+	KeyEncoding     string `json:"key_encoding"` // utf8, hex, base64
+	Message         string `json:"message"`
+	MessageEncoding string `json:"message_encoding"` // utf8, hex, base64
+	Encoding        string `json:"encoding"`         // 签名与令牌的编码：hex, base64, base64url
+	Signature       string `json:"signature"`
+
+	Kind             string `json:"kind"` // token, password
+	Length           int    `json:"length"`
+	Lowercase        *bool  `json:"lowercase"`
+	Uppercase        *bool  `json:"uppercase"`
+	Digits           *bool  `json:"digits"`
+	Symbols          *bool  `json:"symbols"`
+	MinLowercase     int    `json:"min_lowercase"`
+	MinUppercase     int    `json:"min_uppercase"`
+	MinDigits        int    `json:"min_digits"`
+	MinSymbols       int    `json:"min_symbols"`
+	SymbolSet        string `json:"symbol_set"`
+	ExcludeAmbiguous bool   `json:"exclude_ambiguous"`
+
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+// CryptoResult 加密操作结果
+type CryptoResult struct {
+	Op          string  `json:"op"`
+	Algorithm   string  `json:"algorithm,omitempty"`
+	Value       string  `json:"value,omitempty"`
+	Encoding    string  `json:"encoding,omitempty"`
+	Valid       *bool   `json:"valid,omitempty"`        // verify 与 compare 的结果
+	EntropyBits float64 `json:"entropy_bits,omitempty"` // 随机值的熵
+}
+
+// NewCryptoTool 创建加密辅助工具
+func NewCryptoTool(cfg config.CryptoConfig) *CryptoTool {
+	return &CryptoTool{keys: cfg.Keys}
+}
+
+func (ct *CryptoTool) Name() string {
+	return "crypto"
+}
+
+func (ct *CryptoTool) Description() string {
+	return "Compute and verify HMAC signatures, generate secure random tokens and passwords, and compare values in constant time"
+}
+
+func (ct *CryptoTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (ct *CryptoTool) InputSchema() *schema.Schema {
+	byteEncodings := []interface{}{"utf8", "hex", "base64"}
+	return schema.Object(map[string]*schema.Schema{
+		"op":                {Type: schema.TypeString, Description: "Operation", Enum: []interface{}{"hmac", "verify", "random", "compare"}},
+		"algorithm":         {Type: schema.TypeString, Description: "HMAC digest", Enum: []interface{}{"sha256", "sha1", "sha384", "sha512"}, Default: "sha256"},
+		"key":               {Type: schema.TypeString, Description: "HMAC key; prefer key_name to keep keys out of call history"},
+		"key_name":          {Type: schema.TypeString, Description: "Name of a key configured on the server"},
+		"key_encoding":      {Type: schema.TypeString, Description: "Encoding of key", Enum: byteEncodings, Default: "utf8"},
+		"message":           {Type: schema.TypeString, Description: "Message to sign or verify"},
+		"message_encoding":  {Type: schema.TypeString, Description: "Encoding of message", Enum: byteEncodings, Default: "utf8"},
+		"encoding":          {Type: schema.TypeString, Description: "Encoding of signatures and tokens", Enum: []interface{}{"hex", "base64", "base64url"}, Default: "hex"},
+		"signature":         {Type: schema.TypeString, Description: "Expected signature for verify, an algorithm= prefix is ignored"},
+		"kind":              {Type: schema.TypeString, Description: "Random value kind", Enum: []interface{}{"token", "password"}, Default: "token"},
+		"length":            {Type: schema.TypeInteger, Description: "Token size in bytes or password length in characters", Minimum: schema.Float(1), Maximum: schema.Float(maxTokenBytes)},
+		"lowercase":         {Type: schema.TypeBoolean, Description: "Allow lowercase letters in passwords", Default: true},
+		"uppercase":         {Type: schema.TypeBoolean, Description: "Allow uppercase letters in passwords", Default: true},
+		"digits":            {Type: schema.TypeBoolean, Description: "Allow digits in passwords", Default: true},
+		"symbols":           {Type: schema.TypeBoolean, Description: "Allow symbols in passwords", Default: true},
+		"min_lowercase":     {Type: schema.TypeInteger, Description: "Minimum lowercase letters", Minimum: schema.Float(0)},
+		"min_uppercase":     {Type: schema.TypeInteger, Description: "Minimum uppercase letters", Minimum: schema.Float(0)},
+		"min_digits":        {Type: schema.TypeInteger, Description: "Minimum digits", Minimum: schema.Float(0)},
+		"min_symbols":       {Type: schema.TypeInteger, Description: "Minimum symbols", Minimum: schema.Float(0)},
+		"symbol_set":        {Type: schema.TypeString, Description: "Symbols to draw from instead of the default set", MinLength: schema.Int(1)},
+		"exclude_ambiguous": {Type: schema.TypeBoolean, Description: "Exclude look-alike characters such as l, 1, O and 0"},
+		"a":                 {Type: schema.TypeString, Description: "First value for compare"},
+		"b":                 {Type: schema.TypeString, Description: "Second value for compare"},
+	}, "op").Closed()
+}
+
+func (ct *CryptoTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var cryptoArgs CryptoArgs
+	if err := json.Unmarshal(args, &cryptoArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+
+	result := CryptoResult{Op: cryptoArgs.Op}
+	var err error
+	switch cryptoArgs.Op {
+	case "hmac", "verify":
+		err = ct.sign(cryptoArgs, &result)
+	case "random":
+		if cryptoArgs.Kind == "password" {
+			err = generatePassword(cryptoArgs, &result)
+		} else {
+			err = generateToken(cryptoArgs, &result)
+		}
+	case "compare":
+		// 比较摘要而非原值，长度不同时也不会提前返回
+		a, b := sha256.Sum256([]byte(cryptoArgs.A)), sha256.Sum256([]byte(cryptoArgs.B))
+		valid := subtle.ConstantTimeCompare(a[:], b[:]) == 1
+		result.Valid = &valid
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", cryptoArgs.Op)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// sign 计算 HMAC，verify 时与给定签名做常量时间比较
+func (ct *CryptoTool) sign(args CryptoArgs, result *CryptoResult) error {
+	algorithm := strings.ToLower(args.Algorithm)
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+	newHash, ok := hmacAlgorithms[algorithm]
+	if !ok {
+		return fmt.Errorf("unsupported algorithm: %s", args.Algorithm)
+	}
+
+	key, err := ct.resolveKey(args)
+	if err != nil {
+		return err
+	}
+	message, err := decodeBytes(args.Message, args.MessageEncoding)
+	if err != nil {
+		return fmt.Errorf("invalid message: %v", err)
+	}
+
+	mac := hmac.New(newHash, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	result.Algorithm = algorithm
+	result.Encoding = outputEncoding(args.Encoding)
+	if args.Op == "hmac" {
+		result.Value, err = encodeBytes(sum, result.Encoding)
+		return err
+	}
+
+	if args.Signature == "" {
+		return fmt.Errorf("signature is required for verify")
+	}
+	signature := strings.TrimPrefix(args.Signature, algorithm+"=")
+	expected, err := decodeBytes(signature, result.Encoding)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	valid := hmac.Equal(sum, expected)
+	result.Valid = &valid
+	return nil
+}
+
+// resolveKey 优先使用配置中的命名密钥
+func (ct *CryptoTool) resolveKey(args CryptoArgs) ([]byte, error) {
+	if args.KeyName != "" {
+		keyConfig, exists := ct.keys[args.KeyName]
+		if !exists {
+			return nil, fmt.Errorf("unknown key: %s", args.KeyName)
+		}
+		secret := keyConfig.ResolveSecret()
+		if secret == "" {
+			return nil, fmt.Errorf("key %s is not set", args.KeyName)
+		}
+		key, err := decodeBytes(secret, keyConfig.Encoding)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %v", args.KeyName, err)
+		}
+		return key, nil
+	}
+
+	if args.Key == "" {
+		return nil, fmt.Errorf("key or key_name is required")
+	}
+	key, err := decodeBytes(args.Key, args.KeyEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %v", err)
+	}
+	return key, nil
+}
+
+// generateToken 生成随机字节并编码
+func generateToken(args CryptoArgs, result *CryptoResult) error {
+	size := args.Length
+	if size <= 0 {
+		size = defaultTokenBytes
+	}
+	if size > maxTokenBytes {
+		return fmt.Errorf("token length must be <= %d bytes", maxTokenBytes)
+	}
+
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return err
+	}
+	result.Encoding = outputEncoding(args.Encoding)
+	value, err := encodeBytes(buf, result.Encoding)
+	if err != nil {
+		return err
+	}
+	result.Value = value
+	result.EntropyBits = float64(size * 8)
+	return nil
+}
+
+// passwordClass 密码字符类别
+type passwordClass struct {
+	name    string
+	chars   string
+	enabled bool
+	min     int
+}
+
+// generatePassword 按策略生成密码：先从每个类别抽取最少数量的字符，其余从所有启用的字符中抽取，最后打乱顺序
+func generatePassword(args CryptoArgs, result *CryptoResult) error {
+	length := args.Length
+	if length <= 0 {
+		length = defaultPasswordLength
+	}
+	if length < minPasswordLength || length > maxPasswordLength {
+		return fmt.Errorf("password length must be between %d and %d", minPasswordLength, maxPasswordLength)
+	}
+
+	enabled := func(flag *bool) bool { return flag == nil || *flag }
+	symbols := passwordSymbols
+	if args.SymbolSet != "" {
+		symbols = args.SymbolSet
+	}
+	classes := []passwordClass{
+		{"lowercase", passwordLower, enabled(args.Lowercase), args.MinLowercase},
+		{"uppercase", passwordUpper, enabled(args.Uppercase), args.MinUppercase},
+		{"digits", passwordDigits, enabled(args.Digits), args.MinDigits},
+		{"symbols", symbols, enabled(args.Symbols), args.MinSymbols},
+	}
+
+	var pool []rune
+	required := 0
+	for i := range classes {
+		class := &classes[i]
+		if args.ExcludeAmbiguous {
+			class.chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(passwordAmbiguous, r) {
+					return -1
+				}
+				return r
+			}, class.chars)
+		}
+		if class.min < 0 {
+			return fmt.Errorf("min_%s must not be negative", class.name)
+		}
+		if !class.enabled || class.chars == "" {
+			if class.min > 0 {
+				return fmt.Errorf("min_%s requires %s to be enabled", class.name, class.name)
+			}
+			continue
+		}
+		pool = append(pool, []rune(class.chars)...)
+		required += class.min
+	}
+	if len(pool) == 0 {
+		return fmt.Errorf("password policy allows no characters")
+	}
+	if required > length {
+		return fmt.Errorf("password policy requires %d characters but length is %d", required, length)
+	}
+
+	password := make([]rune, 0, length)
+	for _, class := range classes {
+		if !class.enabled {
+			continue
+		}
+		chars := []rune(class.chars)
+		for i := 0; i < class.min; i++ {
+			r, err := randomChoice(chars)
+			if err != nil {
+				return err
+			}
+			password = append(password, r)
+		}
+	}
+	for len(password) < length {
+		r, err := randomChoice(pool)
+		if err != nil {
+			return err
+		}
+		password = append(password, r)
+	}
+
+	// Fisher-Yates 打乱，避免必选字符固定出现在开头
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+
+	result.Value = string(password)
+	result.EntropyBits = math.Round(float64(length)*math.Log2(float64(len(pool)))*10) / 10
+	return nil
+}
+
+// randomInt 返回 [0, n) 内均匀分布的随机整数
+func randomInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
+
+func randomChoice(chars []rune) (rune, error) {
+	i, err := randomInt(len(chars))
+	if err != nil {
+		return 0, err
+	}
+	return chars[i], nil
+}
+
+// outputEncoding 签名与令牌的编码，默认 hex
+func outputEncoding(encoding string) string {
+	if encoding == "" {
+		return "hex"
+	}
+	return strings.ToLower(encoding)
+}
+
+// encodeBytes 按编码输出字节
+func encodeBytes(data []byte, encoding string) (string, error) {
+	switch encoding {
+	case "hex":
+		return hex.EncodeToString(data), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(data), nil
+	case "base64url":
+		return base64.RawURLEncoding.EncodeToString(data), nil
+	}
+	return "", fmt.Errorf("unsupported encoding: %s", encoding)
+}
+
+// decodeBytes 按编码解析字节，base64 同时接受标准与 URL 安全字母表、有无填充
+func decodeBytes(value, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "", "utf8":
+		return []byte(value), nil
+	case "hex":
+		return hex.DecodeString(value)
+	case "base64", "base64url":
+		value = strings.TrimRight(value, "=")
+		if strings.ContainsAny(value, "-_") {
+			return base64.RawURLEncoding.DecodeString(value)
+		}
+		return base64.RawStdEncoding.DecodeString(value)
+	}
+	return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+}
@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/llm"
+	"Weave-Toolkit/internal/schema"
+)
+
+// embeddings 工具默认限制
+const (
+	defaultEmbeddingsMaxInputs     = 128
+	defaultEmbeddingsMaxInputBytes = 1 << 20
+)
+
+// EmbeddingsTool 文本向量化工具
+//
+// 通过配置的向量化服务（OpenAI、Ollama 或 OpenAI 兼容接口）计算文本向量，
+// 结果可直接用于 vector_search 工具或由调用方自行存储。
+type EmbeddingsTool struct {
+	config config.EmbeddingsConfig
+}
+
+// EmbeddingsArgs 向量化参数，input 与 inputs 至少提供一个，input 排在 inputs 之前
+type EmbeddingsArgs struct {
+	Input    string   `json:"input"`
+	Inputs   []string `json:"inputs"`
+	Provider string   `json:"provider"`
+	Model    string   `json:"model"`
+}
+
+// EmbeddingsResult 向量化结果，Embeddings 与输入一一对应
+type EmbeddingsResult struct {
+	Provider   string      `json:"provider"`
+	Model      string      `json:"model"`
+	Dimensions int         `json:"dimensions"`
+	Embeddings [][]float64 `json:"embeddings"`
+	Usage      llm.Usage   `json:"usage"`
+}
+
+// NewEmbeddingsTool 创建文本向量化工具
+func NewEmbeddingsTool(cfg config.EmbeddingsConfig) *EmbeddingsTool {
+	if cfg.MaxInputs <= 0 {
+		cfg.MaxInputs = defaultEmbeddingsMaxInputs
+	}
+	if cfg.MaxInputBytes <= 0 {
+		cfg.MaxInputBytes = defaultEmbeddingsMaxInputBytes
+	}
+	return &EmbeddingsTool{config: cfg}
+}
+
+func (et *EmbeddingsTool) Name() string {
+	return "embeddings"
+}
+
+func (et *EmbeddingsTool) Description() string {
+	return "Compute embedding vectors for one or more texts using a configured provider (OpenAI, Ollama or OpenAI-compatible)"
+}
+
+func (et *EmbeddingsTool) Category() ToolCategory {
+	return CategoryAI
+}
+
+func (et *EmbeddingsTool) Tags() []string {
+	return []string{TagNetwork, TagLLM}
+}
+
+func (et *EmbeddingsTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"input":    {Type: schema.TypeString, Description: "Text to embed", MinLength: schema.Int(1)},
+		"inputs":   {Type: schema.TypeArray, Description: "Texts to embed, in order", Items: &schema.Schema{Type: schema.TypeString, MinLength: schema.Int(1)}, MinItems: schema.Int(1)},
+		"provider": {Type: schema.TypeString, Description: "Configured provider name, defaults to the configured default"},
+		"model":    {Type: schema.TypeString, Description: "Embedding model, defaults to the provider's model", MinLength: schema.Int(1)},
+	}).Closed()
+}
+
+func (et *EmbeddingsTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var embedArgs EmbeddingsArgs
+	if err := json.Unmarshal(args, &embedArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+
+	var inputs []string
+	if embedArgs.Input != "" {
+		inputs = append(inputs, embedArgs.Input)
+	}
+	inputs = append(inputs, embedArgs.Inputs...)
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("input or inputs is required")
+	}
+
+	result, err := et.Embed(ctx, embedArgs.Provider, embedArgs.Model, inputs)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// Embed 使用指定服务与模型（为空时使用默认值）计算向量；vector_search 等工具借此复用服务配置与限制
+func (et *EmbeddingsTool) Embed(ctx context.Context, providerName, model string, inputs []string) (*EmbeddingsResult, error) {
+	if len(inputs) > et.config.MaxInputs {
+		return nil, fmt.Errorf("too many inputs: %d exceeds limit %d", len(inputs), et.config.MaxInputs)
+	}
+	size := 0
+	for _, input := range inputs {
+		if input == "" {
+			return nil, fmt.Errorf("inputs must not be empty")
+		}
+		size += len(input)
+	}
+	if size > et.config.MaxInputBytes {
+		return nil, fmt.Errorf("inputs exceed %d bytes", et.config.MaxInputBytes)
+	}
+
+	name, providerCfg, err := selectProvider("embeddings", et.config.Providers, et.config.Default, providerName)
+	if err != nil {
+		return nil, err
+	}
+	if model == "" {
+		model = providerCfg.Model
+	}
+	if model == "" {
+		return nil, fmt.Errorf("model is required, provider %s has no default model", name)
+	}
+	if len(providerCfg.Models) > 0 && model != providerCfg.Model && !slices.Contains(providerCfg.Models, model) {
+		return nil, fmt.Errorf("%w: %s on provider %s", ErrModelNotAllowed, model, name)
+	}
+
+	embedder, err := llm.NewEmbedder(llm.Options{Type: providerCfg.Type, URL: providerCfg.URL, APIKey: providerCfg.ResolveAPIKey(), Client: HTTPClient(ctx, llmRequestTimeout)})
+	if err != nil {
+		return nil, fmt.Errorf("provider %s: %v", name, err)
+	}
+	var embeddings *llm.Embeddings
+	err = callUpstream(ctx, "embeddings:"+name, func() (err error) {
+		embeddings, err = embedder.Embed(ctx, model, inputs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EmbeddingsResult{Provider: name, Model: embeddings.Model, Embeddings: embeddings.Vectors, Usage: embeddings.Usage}
+	if len(embeddings.Vectors) > 0 {
+		result.Dimensions = len(embeddings.Vectors[0])
+	}
+	return result, nil
+}
+
+// EmbedAll 按数量与大小限制分批计算向量，适合一次处理大量片段
+func (et *EmbeddingsTool) EmbedAll(ctx context.Context, providerName, model string, inputs []string) ([][]float64, error) {
+	vectors := make([][]float64, 0, len(inputs))
+	for start := 0; start < len(inputs); {
+		end, size := start, 0
+		for end < len(inputs) && end-start < et.config.MaxInputs && (end == start || size+len(inputs[end]) <= et.config.MaxInputBytes) {
+			size += len(inputs[end])
+			end++
+		}
+		result, err := et.Embed(ctx, providerName, model, inputs[start:end])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, result.Embeddings...)
+		start = end
+	}
+	return vectors, nil
+}
@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// StreamEventType 流式调用事件类型
+type StreamEventType string
+
+// 流式调用事件类型
+const (
+	EventPartial  StreamEventType = "partial"  // 输出片段，Index 从 0 开始递增
+	EventProgress StreamEventType = "progress" // 执行进度
+	EventLog      StreamEventType = "log"      // 面向调用方的日志消息
+	EventFinal    StreamEventType = "final"    // 调用结果，成功时为最后一个事件
+)
+
+// LogLevel 日志消息级别，取值与 MCP notifications/message 一致
+type LogLevel string
+
+// 日志消息级别
+const (
+	LogDebug   LogLevel = "debug"
+	LogInfo    LogLevel = "info"
+	LogWarning LogLevel = "warning"
+	LogError   LogLevel = "error"
+)
+
+// StreamEvent 流式调用事件
+type StreamEvent struct {
+	Type     StreamEventType `json:"type"`
+	Index    int             `json:"index,omitempty"`    // partial
+	Content  string          `json:"content,omitempty"`  // partial
+	Progress float64         `json:"progress,omitempty"` // progress
+	Total    float64         `json:"total,omitempty"`    // progress，未知时为 0
+	Level    LogLevel        `json:"level,omitempty"`    // log
+	Message  string          `json:"message,omitempty"`  // progress、log
+	Result   *ToolCallResult `json:"result,omitempty"`   // final
+}
+
+// EventSink 接收流式调用事件，事件按发生顺序串行送达
+type EventSink func(event StreamEvent)
+
+// Emitter 流式工具推送事件的接口，片段序号由 Emitter 维护，工具不需要自行计数
+//
+// 调用结果即 Stream 的返回值，由 ToolManager 作为 final 事件送达调用方。
+type Emitter interface {
+	// Partial 推送输出片段
+	Partial(content string)
+	// Progress 上报进度，total 未知时为 0
+	Progress(progress, total float64, message string)
+	// Log 向调用方推送日志消息，同时写入本次调用的日志
+	Log(level LogLevel, message string)
+}
+
+// EmitterTool 以 Emitter 推送事件的流式工具，同时实现 StreamTool 时优先使用 Stream
+type EmitterTool interface {
+	Tool
+	Stream(ctx context.Context, args json.RawMessage, emit Emitter) (json.RawMessage, error)
+}
+
+// CallbackEmitter 将 Emitter 的片段转换为旧式回调，进度与日志被忽略
+//
+// 用于同时实现 StreamTool 的工具以 Stream 实现 ExecuteStream。
+func CallbackEmitter(callback StreamCallback) Emitter {
+	return &eventEmitter{sink: func(event StreamEvent) {
+		if event.Type == EventPartial && callback != nil {
+			callback(event.Content, event.Index)
+		}
+	}}
+}
+
+// eventEmitter Emitter 的实现，串行化来自工具协程的事件
+type eventEmitter struct {
+	mu     sync.Mutex
+	sink   EventSink
+	next   int
+	logger *zerolog.Logger     // 为空时不写日志
+	mask   func(string) string // 推送前处理片段内容，为空时原样推送
+
+	// check 推送前检查片段，与上一个片段拼接后检查以发现跨片段的命中；返回错误时丢弃该片段
+	// 与之后的全部片段，并通过 abort 中止调用
+	check   func(string) error
+	abort   context.CancelFunc
+	blocked error
+	last    string
+}
+
+// Partial 实现 Emitter
+func (e *eventEmitter) Partial(content string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.partialLocked(content, e.next)
+}
+
+// partialAt 以工具指定的序号推送片段，供旧式 StreamTool 使用
+func (e *eventEmitter) partialAt(content string, index int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.partialLocked(content, index)
+}
+
+func (e *eventEmitter) partialLocked(content string, index int) {
+	if e.blocked != nil {
+		return
+	}
+	if e.check != nil {
+		if err := e.check(e.last + content); err != nil {
+			e.blocked = err
+			if e.abort != nil {
+				e.abort()
+			}
+			return
+		}
+		e.last = content
+	}
+	e.next = index + 1
+	if e.mask != nil {
+		content = e.mask(content)
+	}
+	e.sink(StreamEvent{Type: EventPartial, Index: index, Content: content})
+}
+
+// Progress 实现 Emitter
+func (e *eventEmitter) Progress(progress, total float64, message string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sink(StreamEvent{Type: EventProgress, Progress: progress, Total: total, Message: message})
+}
+
+// Log 实现 Emitter
+func (e *eventEmitter) Log(level LogLevel, message string) {
+	if e.logger != nil {
+		e.logger.WithLevel(zerologLevel(level)).Str("stream_log", string(level)).Msg(message)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sink(StreamEvent{Type: EventLog, Level: level, Message: message})
+}
+
+// blockedError 片段被拒绝时返回拒绝错误，同步调用没有 emitter，此时为 nil
+func (e *eventEmitter) blockedError() error {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.blocked
+}
+
+// final 推送调用结果，同步调用没有 emitter，此时不做任何事
+func (e *eventEmitter) final(result *ToolCallResult) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sink(StreamEvent{Type: EventFinal, Result: result})
+}
+
+// zerologLevel 日志消息级别对应的 zerolog 级别
+func zerologLevel(level LogLevel) zerolog.Level {
+	switch level {
+	case LogDebug:
+		return zerolog.DebugLevel
+	case LogWarning:
+		return zerolog.WarnLevel
+	case LogError:
+		return zerolog.ErrorLevel
+	default:
+		return zerolog.InfoLevel
+	}
+}
+
+// streamRunner 返回工具的流式执行函数，工具不支持流式时返回 nil
+//
+// 旧式 StreamTool 的回调序号原样保留。
+func streamRunner(tool Tool) func(ctx context.Context, args json.RawMessage, emit *eventEmitter) (json.RawMessage, error) {
+	if emitterTool, ok := tool.(EmitterTool); ok {
+		return func(ctx context.Context, args json.RawMessage, emit *eventEmitter) (json.RawMessage, error) {
+			return emitterTool.Stream(ctx, args, emit)
+		}
+	}
+	if streamTool, ok := tool.(StreamTool); ok {
+		return func(ctx context.Context, args json.RawMessage, emit *eventEmitter) (json.RawMessage, error) {
+			return streamTool.ExecuteStream(ctx, args, emit.partialAt)
+		}
+	}
+	return nil
+}
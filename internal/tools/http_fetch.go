@@ -0,0 +1,323 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/schema"
+)
+
+// http_fetch 默认限制
+const (
+	defaultFetchMaxResponseBytes = 1 << 20
+	defaultFetchMaxRedirects     = 5
+	fetchDialTimeout             = 10 * time.Second
+)
+
+// ErrFetchBlocked 目标地址或主机被 http_fetch 的访问策略拒绝
+var ErrFetchBlocked = werrors.New(werrors.KindUnauthorized, "fetch destination blocked")
+
+// blockedPrefixes 私有地址之外仍需拦截的特殊网段
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"), // 运营商级 NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF 协议分配
+	netip.MustParsePrefix("198.18.0.0/15"), // 基准测试
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64，可映射到内网 IPv4
+}
+
+// HTTPFetchTool HTTP 请求工具
+//
+// 仅允许 http/https，按配置的主机白名单与黑名单过滤每一跳请求（含重定向），
+// 默认拒绝连接回环、内网、链路本地等地址。地址检查在建立连接时针对解析结果进行，
+// 可防止 DNS 重绑定绕过；不使用环境变量中的代理。
+type HTTPFetchTool struct {
+	config config.HTTPFetchConfig
+	client *http.Client
+}
+
+// HTTPFetchArgs HTTP 请求参数
+type HTTPFetchArgs struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method"` // GET, POST
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// HTTPFetchResult HTTP 请求结果
+type HTTPFetchResult struct {
+	Status       int                 `json:"status"`
+	URL          string              `json:"url"` // 重定向后的最终地址
+	Headers      map[string][]string `json:"headers"`
+	Body         string              `json:"body"`
+	BodyEncoding string              `json:"body_encoding"` // text 或 base64
+	Truncated    bool                `json:"truncated,omitempty"`
+}
+
+// NewHTTPFetchTool 创建 HTTP 请求工具
+func NewHTTPFetchTool(cfg config.HTTPFetchConfig) *HTTPFetchTool {
+	if cfg.MaxResponseBytes <= 0 {
+		cfg.MaxResponseBytes = defaultFetchMaxResponseBytes
+	}
+	if cfg.MaxRedirects < 0 {
+		cfg.MaxRedirects = 0
+	} else if cfg.MaxRedirects == 0 {
+		cfg.MaxRedirects = defaultFetchMaxRedirects
+	}
+
+	ft := &HTTPFetchTool{config: cfg}
+	dialer := &net.Dialer{Timeout: fetchDialTimeout, Control: ft.checkDial}
+	ft.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   fetchDialTimeout,
+			ResponseHeaderTimeout: 30 * time.Second,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: ft.checkRedirect,
+	}
+	return ft
+}
+
+func (ft *HTTPFetchTool) Name() string {
+	return "http_fetch"
+}
+
+func (ft *HTTPFetchTool) Description() string {
+	return "Fetch a URL over HTTP(S) with GET or POST and return status, headers and body"
+}
+
+func (ft *HTTPFetchTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (ft *HTTPFetchTool) Tags() []string {
+	return []string{TagNetwork}
+}
+
+func (ft *HTTPFetchTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"url":     {Type: schema.TypeString, Description: "Absolute http or https URL", MinLength: schema.Int(1)},
+		"method":  {Type: schema.TypeString, Description: "HTTP method", Enum: []interface{}{http.MethodGet, http.MethodPost}, Default: http.MethodGet},
+		"headers": {Type: schema.TypeObject, Description: "Request headers"},
+		"body":    {Type: schema.TypeString, Description: "Request body for POST"},
+	}, "url").Closed()
+}
+
+func (ft *HTTPFetchTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var fetchArgs HTTPFetchArgs
+	if err := json.Unmarshal(args, &fetchArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+
+	method := strings.ToUpper(fetchArgs.Method)
+	switch method {
+	case "":
+		method = http.MethodGet
+	case http.MethodGet, http.MethodPost:
+	default:
+		return nil, fmt.Errorf("unsupported method: %s", fetchArgs.Method)
+	}
+
+	target, err := url.Parse(fetchArgs.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %v", err)
+	}
+	if err := ft.checkURL(target); err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if fetchArgs.Body != "" {
+		body = strings.NewReader(fetchArgs.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	for name, value := range fetchArgs.Headers {
+		if strings.EqualFold(name, "Host") {
+			continue // 禁止改写 Host，避免绕过主机名检查
+		}
+		req.Header.Set(name, value)
+	}
+
+	resp, data, truncated, err := ft.fetch(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := HTTPFetchResult{
+		Status:    resp.StatusCode,
+		URL:       resp.Request.URL.String(),
+		Headers:   resp.Header,
+		Truncated: truncated,
+	}
+	if isTextContent(resp.Header.Get("Content-Type")) && utf8.Valid(data) {
+		result.Body = string(data)
+		result.BodyEncoding = "text"
+	} else {
+		result.Body = base64.StdEncoding.EncodeToString(data)
+		result.BodyEncoding = "base64"
+	}
+
+	return json.Marshal(result)
+}
+
+// fetch 按访问策略发送请求并读取响应体，响应体超过 MaxResponseBytes 时截断
+func (ft *HTTPFetchTool) fetch(req *http.Request) (*http.Response, []byte, bool, error) {
+	if err := ft.checkURL(req.URL); err != nil {
+		return nil, nil, false, err
+	}
+	resp, err := ft.client.Do(req)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 多读一个字节用于判断是否截断
+	data, err := io.ReadAll(io.LimitReader(resp.Body, ft.config.MaxResponseBytes+1))
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to read response: %v", err)
+	}
+	if int64(len(data)) > ft.config.MaxResponseBytes {
+		return resp, data[:ft.config.MaxResponseBytes], true, nil
+	}
+	return resp, data, false, nil
+}
+
+// checkURL 校验协议与主机名访问策略
+func (ft *HTTPFetchTool) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: unsupported scheme %q", ErrFetchBlocked, u.Scheme)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrFetchBlocked)
+	}
+	if matchHost(ft.config.DenyHosts, host) {
+		return fmt.Errorf("%w: host %s is denied", ErrFetchBlocked, host)
+	}
+	if len(ft.config.AllowHosts) > 0 && !matchHost(ft.config.AllowHosts, host) {
+		return fmt.Errorf("%w: host %s is not allowed", ErrFetchBlocked, host)
+	}
+	return nil
+}
+
+// checkResolved 校验访问策略，并检查主机解析出的地址；用于无法在建立连接时检查地址的场景（如浏览器），
+// 解析结果与实际连接之间仍存在 DNS 重绑定的可能
+func (ft *HTTPFetchTool) checkResolved(ctx context.Context, u *url.URL) error {
+	if err := ft.checkURL(u); err != nil {
+		return err
+	}
+	if ft.config.AllowPrivate {
+		return nil
+	}
+	host := u.Hostname()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: cannot resolve %s", ErrFetchBlocked, host)
+	}
+	for _, addr := range addrs {
+		if isPrivateAddr(addr) {
+			return fmt.Errorf("%w: address %s is private", ErrFetchBlocked, addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// checkRedirect 限制重定向次数，并对每一跳重新校验访问策略
+func (ft *HTTPFetchTool) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > ft.config.MaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", ft.config.MaxRedirects)
+	}
+	return ft.checkURL(req.URL)
+}
+
+// checkDial 在建立连接前校验解析后的目标地址
+func (ft *HTTPFetchTool) checkDial(network, address string, _ syscall.RawConn) error {
+	if ft.config.AllowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: unresolved address %s", ErrFetchBlocked, host)
+	}
+	if isPrivateAddr(addr) {
+		return fmt.Errorf("%w: address %s is private", ErrFetchBlocked, addr)
+	}
+	return nil
+}
+
+// isPrivateAddr 判断地址是否为回环、内网、链路本地或其他非公网地址
+func isPrivateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHost 主机名匹配，"*.example.com" 匹配 example.com 的子域名
+func matchHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(pattern)), ".")
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// isTextContent 判断响应内容类型是否按文本返回
+func isTextContent(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		mediaType == "application/json",
+		mediaType == "application/xml",
+		mediaType == "application/javascript",
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,410 @@
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Weave-Toolkit/config"
+)
+
+// 外部请求客户端默认参数
+const (
+	defaultHTTPDialTimeout         = 10 * time.Second
+	defaultHTTPMaxIdleConns        = 100
+	defaultHTTPMaxIdleConnsPerHost = 10
+	defaultHTTPIdleConnTimeout     = 90 * time.Second
+	defaultHTTPRetryBackoff        = 200 * time.Millisecond
+	defaultHTTPMaxRetryBackoff     = 5 * time.Second
+)
+
+// defaultRetryStatuses 默认重试的状态码
+var defaultRetryStatuses = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// HTTPClients 工具访问外部服务共用的 HTTP 客户端
+//
+// 所有客户端共享同一个连接池，按配置使用代理、TLS 设置、每个主机的并发上限与重试策略。
+// 工具通过 HTTPClient 或 ToolContext.HTTP 获取，不应自行创建 http.Client。
+type HTTPClients struct {
+	base      *http.Transport
+	transport http.RoundTripper
+	timeout   time.Duration
+}
+
+// defaultHTTPClients 上下文中没有共享客户端时使用的默认客户端（不使用代理、不重试）
+var defaultHTTPClients = mustHTTPClients(config.HTTPClientConfig{})
+
+// NewHTTPClients 按配置创建共享客户端
+func NewHTTPClients(cfg config.HTTPClientConfig) (*HTTPClients, error) {
+	dialTimeout := seconds(cfg.DialTimeout, defaultHTTPDialTimeout)
+	tlsConfig, err := httpClientTLS(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := httpClientProxy(cfg.Proxy, cfg.NoProxy)
+	if err != nil {
+		return nil, err
+	}
+
+	maxIdle := cfg.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = defaultHTTPMaxIdleConns
+	}
+	maxIdlePerHost := cfg.MaxIdleConnsPerHost
+	if maxIdlePerHost <= 0 {
+		maxIdlePerHost = defaultHTTPMaxIdleConnsPerHost
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}
+	base := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   dialTimeout,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdle,
+		MaxIdleConnsPerHost:   maxIdlePerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       seconds(cfg.IdleConnTimeout, defaultHTTPIdleConnTimeout),
+		ExpectContinueTimeout: time.Second,
+	}
+
+	var transport http.RoundTripper = base
+	if limits := hostLimits(cfg.Hosts); len(limits) > 0 {
+		transport = &hostLimitTransport{next: transport, limits: limits, slots: make(map[string]chan struct{})}
+	}
+	if cfg.Retry.MaxAttempts > 1 {
+		transport = newRetryTransport(transport, cfg.Retry)
+	}
+
+	return &HTTPClients{
+		base:      base,
+		transport: transport,
+		timeout:   time.Duration(cfg.Timeout) * time.Second,
+	}, nil
+}
+
+// newHTTPClients 按工具配置创建共享客户端，配置无效时记录错误并使用默认客户端
+func (tm *ToolManager) newHTTPClients(cfg config.HTTPClientConfig) *HTTPClients {
+	clients, err := NewHTTPClients(cfg)
+	if err != nil {
+		tm.logger.Error().Err(err).Msg("Invalid http_client config, using defaults")
+		return mustHTTPClients(config.HTTPClientConfig{})
+	}
+	return clients
+}
+
+// mustHTTPClients 创建不依赖外部文件的客户端，配置无效时 panic
+func mustHTTPClients(cfg config.HTTPClientConfig) *HTTPClients {
+	clients, err := NewHTTPClients(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return clients
+}
+
+// Client 获取共享连接池的客户端；配置了全局超时时优先使用，否则使用 timeout，0 表示仅受上下文限制
+func (h *HTTPClients) Client(timeout time.Duration) *http.Client {
+	if h.timeout > 0 {
+		timeout = h.timeout
+	}
+	return &http.Client{Transport: h.transport, Timeout: timeout}
+}
+
+// CloseIdleConnections 关闭连接池中的空闲连接
+func (h *HTTPClients) CloseIdleConnections() {
+	h.base.CloseIdleConnections()
+}
+
+// httpClientsContextKey 共享客户端上下文键
+type httpClientsContextKey struct{}
+
+// WithHTTPClients 在上下文中设置工具访问外部服务使用的共享客户端
+func WithHTTPClients(ctx context.Context, clients *HTTPClients) context.Context {
+	return context.WithValue(ctx, httpClientsContextKey{}, clients)
+}
+
+// HTTPClientsFromContext 获取上下文中的共享客户端，未设置时返回默认客户端
+func HTTPClientsFromContext(ctx context.Context) *HTTPClients {
+	if clients, ok := ctx.Value(httpClientsContextKey{}).(*HTTPClients); ok && clients != nil {
+		return clients
+	}
+	return defaultHTTPClients
+}
+
+// HTTPClient 获取访问外部服务的客户端，timeout 为工具自身的默认超时
+func HTTPClient(ctx context.Context, timeout time.Duration) *http.Client {
+	return HTTPClientsFromContext(ctx).Client(timeout)
+}
+
+// httpClientTLS 构造 TLS 配置
+func httpClientTLS(cfg config.HTTPClientTLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: cfg.InsecureSkipVerify}
+
+	switch cfg.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		tlsConfig.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("http_client: unsupported tls min_version %q", cfg.MinVersion)
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("http_client: failed to read ca_file: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("http_client: no certificates found in ca_file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("http_client: failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// httpClientProxy 构造代理选择函数
+func httpClientProxy(proxy string, noProxy []string) (func(*http.Request) (*url.URL, error), error) {
+	switch proxy {
+	case "":
+		return nil, nil
+	case "env":
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("http_client: invalid proxy %q", proxy)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("http_client: unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if matchHost(noProxy, strings.ToLower(req.URL.Hostname())) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+// hostLimits 提取配置了并发上限的主机
+func hostLimits(hosts map[string]config.HTTPHostConfig) map[string]int {
+	limits := make(map[string]int)
+	for host, cfg := range hosts {
+		if cfg.MaxConns > 0 {
+			limits[strings.ToLower(host)] = cfg.MaxConns
+		}
+	}
+	return limits
+}
+
+// hostLimitTransport 限制单个主机同时进行的请求数，请求在响应体关闭后释放名额
+type hostLimitTransport struct {
+	next   http.RoundTripper
+	limits map[string]int // 主机名或 "*.example.com" -> 上限
+
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *hostLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	slots := t.slotsFor(strings.ToLower(req.URL.Hostname()))
+	if slots == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	select {
+	case slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	release := sync.OnceFunc(func() { <-slots })
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// slotsFor 获取主机的并发名额，未配置上限时返回 nil；通配符上限由匹配的所有子域名共享
+func (t *hostLimitTransport) slotsFor(host string) chan struct{} {
+	pattern := ""
+	if _, ok := t.limits[host]; ok {
+		pattern = host
+	} else {
+		for candidate := range t.limits {
+			if strings.HasPrefix(candidate, "*.") && matchHost([]string{candidate}, host) {
+				pattern = candidate
+				break
+			}
+		}
+	}
+	if pattern == "" {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	slots, ok := t.slots[pattern]
+	if !ok {
+		slots = make(chan struct{}, t.limits[pattern])
+		t.slots[pattern] = slots
+	}
+	return slots
+}
+
+// releaseBody 关闭响应体时释放主机名额
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close 实现 io.Closer
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// retryTransport 按指数退避重试失败的请求
+//
+// 幂等请求在连接错误与可重试状态码时重试；其他请求只在服务端以可重试状态码明确拒绝时重试，
+// 且请求体必须可重放。Retry-After 响应头优先于计算出的等待时间。
+type retryTransport struct {
+	next        http.RoundTripper
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	statuses    map[int]bool
+}
+
+// newRetryTransport 创建重试传输层
+func newRetryTransport(next http.RoundTripper, cfg config.HTTPRetryConfig) *retryTransport {
+	backoff := time.Duration(cfg.InitialBackoff) * time.Millisecond
+	if backoff <= 0 {
+		backoff = defaultHTTPRetryBackoff
+	}
+	maxBackoff := time.Duration(cfg.MaxBackoff) * time.Millisecond
+	if maxBackoff <= 0 {
+		maxBackoff = defaultHTTPMaxRetryBackoff
+	}
+	statuses := cfg.Statuses
+	if len(statuses) == 0 {
+		statuses = defaultRetryStatuses
+	}
+
+	t := &retryTransport{next: next, maxAttempts: cfg.MaxAttempts, backoff: backoff, maxBackoff: maxBackoff, statuses: make(map[int]bool)}
+	for _, status := range statuses {
+		t.statuses[status] = true
+	}
+	return t
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// 请求体无法重放
+		return t.next.RoundTrip(req)
+	}
+	idempotent := isIdempotent(req.Method)
+
+	for attempt := 1; ; attempt++ {
+		try := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			try = req.Clone(req.Context())
+			try.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(try)
+		retry := false
+		switch {
+		case err != nil:
+			retry = idempotent && req.Context().Err() == nil
+		case t.statuses[resp.StatusCode]:
+			retry = true
+		}
+		if !retry || attempt >= t.maxAttempts {
+			return resp, err
+		}
+
+		wait := t.wait(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// wait 第 attempt 次失败后的等待时间
+func (t *retryTransport) wait(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if after, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && after >= 0 {
+			return min(time.Duration(after)*time.Second, t.maxBackoff)
+		}
+	}
+
+	wait := t.backoff << (attempt - 1)
+	if wait <= 0 || wait > t.maxBackoff {
+		wait = t.maxBackoff
+	}
+	// 在 [wait/2, wait] 之间随机抖动，避免多个调用同时重试
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// isIdempotent 请求方法是否幂等
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// seconds 将配置的秒数转换为时间间隔，不大于 0 时使用默认值
+func seconds(value int, fallback time.Duration) time.Duration {
+	if value <= 0 {
+		return fallback
+	}
+	return time.Duration(value) * time.Second
+}
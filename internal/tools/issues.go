@@ -0,0 +1,603 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/schema"
+)
+
+// 问题跟踪系统类型
+const (
+	IssueTrackerGitHub = "github"
+	IssueTrackerJira   = "jira"
+)
+
+// issues 默认参数
+const (
+	defaultIssuesMaxResults = 50
+	defaultGitHubAPIURL     = "https://api.github.com"
+	defaultJiraIssueType    = "Task"
+	issuesRequestTimeout    = 30 * time.Second
+	issuesMaxResponseBytes  = 4 << 20
+	issuesErrorBodyBytes    = 512
+)
+
+// jiraTimeLayout Jira REST API 的时间格式
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+var (
+	// ErrIssuesReadOnly 跟踪系统未开启写操作
+	ErrIssuesReadOnly = werrors.New(werrors.KindUnauthorized, "issue tracker is read-only")
+
+	// jiraKeyPattern Jira 问题键，如 OPS-123
+	jiraKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*-[0-9]+$`)
+	// jqlOrderBy JQL 末尾的排序子句
+	jqlOrderBy = regexp.MustCompile(`(?i)(^|\s)order\s+by\s.*$`)
+)
+
+// IssuesTool 问题跟踪工具
+//
+// 通过 GitHub REST API 或 Jira REST API v2 搜索、读取问题与评论，开启 allow_write 的跟踪系统还可创建问题与评论。
+// 跟踪系统按别名配置，每个 GitHub 别名对应一个仓库，Jira 别名可限定项目；令牌只保存在配置中。
+type IssuesTool struct {
+	config config.IssuesConfig
+}
+
+// IssuesArgs 问题跟踪参数
+type IssuesArgs struct {
+	Op        string   `json:"op"` // search, get, create, comment
+	Tracker   string   `json:"tracker"`
+	Query     string   `json:"query"` // GitHub 搜索语法或 JQL
+	ID        string   `json:"id"`    // GitHub 问题编号或 Jira 问题键
+	Title     string   `json:"title"`
+	Body      string   `json:"body"`
+	Labels    []string `json:"labels"`
+	IssueType string   `json:"issue_type"` // Jira 问题类型，默认 Task
+	Limit     int      `json:"limit"`
+}
+
+// IssuesResult 问题跟踪结果
+type IssuesResult struct {
+	Tracker  string         `json:"tracker"`
+	Type     string         `json:"type"`
+	Total    int            `json:"total,omitempty"`
+	Issues   []Issue        `json:"issues,omitempty"`
+	Issue    *Issue         `json:"issue,omitempty"`
+	Comments []IssueComment `json:"comments,omitempty"`
+	Comment  *IssueComment  `json:"comment,omitempty"`
+}
+
+// Issue 问题
+type Issue struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	State    string    `json:"state,omitempty"`
+	Author   string    `json:"author,omitempty"`
+	Assignee string    `json:"assignee,omitempty"`
+	Labels   []string  `json:"labels,omitempty"`
+	URL      string    `json:"url"`
+	Body     string    `json:"body,omitempty"`
+	Created  time.Time `json:"created,omitzero"`
+	Updated  time.Time `json:"updated,omitzero"`
+}
+
+// IssueComment 评论
+type IssueComment struct {
+	ID      string    `json:"id"`
+	Author  string    `json:"author,omitempty"`
+	Body    string    `json:"body"`
+	URL     string    `json:"url,omitempty"`
+	Created time.Time `json:"created,omitzero"`
+}
+
+// NewIssuesTool 创建问题跟踪工具
+func NewIssuesTool(cfg config.IssuesConfig) *IssuesTool {
+	if cfg.MaxResults <= 0 {
+		cfg.MaxResults = defaultIssuesMaxResults
+	}
+	return &IssuesTool{config: cfg}
+}
+
+func (it *IssuesTool) Name() string {
+	return "issues"
+}
+
+func (it *IssuesTool) Description() string {
+	return "Search, read, create and comment on GitHub issues or Jira tickets in configured trackers"
+}
+
+func (it *IssuesTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (it *IssuesTool) Tags() []string {
+	return []string{TagNetwork, TagDestructive}
+}
+
+func (it *IssuesTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"op":         {Type: schema.TypeString, Description: "Operation; create and comment require allow_write on the tracker", Enum: []interface{}{"search", "get", "create", "comment"}},
+		"tracker":    {Type: schema.TypeString, Description: "Configured tracker alias, defaults to the configured default", MinLength: schema.Int(1)},
+		"query":      {Type: schema.TypeString, Description: "GitHub search qualifiers or JQL (search)"},
+		"id":         {Type: schema.TypeString, Description: "GitHub issue number or Jira issue key (get, comment)", MinLength: schema.Int(1)},
+		"title":      {Type: schema.TypeString, Description: "Issue title (create)", MinLength: schema.Int(1)},
+		"body":       {Type: schema.TypeString, Description: "Issue description (create) or comment text (comment)"},
+		"labels":     {Type: schema.TypeArray, Description: "Labels for the new issue (create)", Items: &schema.Schema{Type: schema.TypeString, MinLength: schema.Int(1)}},
+		"issue_type": {Type: schema.TypeString, Description: "Jira issue type (create)", Default: defaultJiraIssueType},
+		"limit":      {Type: schema.TypeInteger, Description: "Maximum number of issues or comments to return", Minimum: schema.Float(1)},
+	}, "op").Closed()
+}
+
+func (it *IssuesTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var issuesArgs IssuesArgs
+	if err := json.Unmarshal(args, &issuesArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	name, tracker, err := it.tracker(issuesArgs.Tracker)
+	if err != nil {
+		return nil, err
+	}
+	if issuesArgs.Limit <= 0 || issuesArgs.Limit > it.config.MaxResults {
+		issuesArgs.Limit = it.config.MaxResults
+	}
+
+	switch issuesArgs.Op {
+	case "search":
+	case "get", "comment":
+		if issuesArgs.ID == "" {
+			return nil, fmt.Errorf("id is required for %s", issuesArgs.Op)
+		}
+	case "create":
+		if strings.TrimSpace(issuesArgs.Title) == "" {
+			return nil, fmt.Errorf("title is required for create")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported op: %s", issuesArgs.Op)
+	}
+	if issuesArgs.Op == "comment" && strings.TrimSpace(issuesArgs.Body) == "" {
+		return nil, fmt.Errorf("body is required for comment")
+	}
+	if (issuesArgs.Op == "create" || issuesArgs.Op == "comment") && !tracker.AllowWrite {
+		return nil, fmt.Errorf("%w: %s is disabled for %s", ErrIssuesReadOnly, issuesArgs.Op, name)
+	}
+
+	result := IssuesResult{Tracker: name, Type: tracker.Type}
+	switch tracker.Type {
+	case IssueTrackerGitHub:
+		err = it.github(ctx, tracker, issuesArgs, &result)
+	case IssueTrackerJira:
+		err = it.jira(ctx, tracker, issuesArgs, &result)
+	default:
+		err = fmt.Errorf("unsupported tracker type %q", tracker.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// tracker 解析跟踪系统别名，未指定时使用默认或唯一配置的跟踪系统
+func (it *IssuesTool) tracker(name string) (string, config.IssueTrackerConfig, error) {
+	if name == "" {
+		switch {
+		case it.config.Default != "":
+			name = it.config.Default
+		case len(it.config.Trackers) == 1:
+			for only := range it.config.Trackers {
+				name = only
+			}
+		case len(it.config.Trackers) == 0:
+			return "", config.IssueTrackerConfig{}, fmt.Errorf("no issue tracker configured")
+		default:
+			available := make([]string, 0, len(it.config.Trackers))
+			for tracker := range it.config.Trackers {
+				available = append(available, tracker)
+			}
+			sort.Strings(available)
+			return "", config.IssueTrackerConfig{}, fmt.Errorf("tracker is required, available: %s", strings.Join(available, ", "))
+		}
+	}
+	tracker, ok := it.config.Trackers[name]
+	if !ok {
+		return "", config.IssueTrackerConfig{}, fmt.Errorf("unknown issue tracker: %s", name)
+	}
+	return name, tracker, nil
+}
+
+// issuesRequest 发送 JSON 请求并解码响应，非 2xx 状态码返回包含响应片段的错误
+func (it *IssuesTool) issuesRequest(ctx context.Context, tracker config.IssueTrackerConfig, method, endpoint string, body, out interface{}) (int, error) {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		payload = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, payload)
+	if err != nil {
+		return 0, fmt.Errorf("invalid tracker url: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	token := tracker.ResolveToken()
+	switch {
+	case tracker.Type == IssueTrackerGitHub:
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	case tracker.User != "":
+		req.SetBasicAuth(tracker.User, token)
+	case token != "":
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := HTTPClient(ctx, issuesRequestTimeout).Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%s request failed: %v", tracker.Type, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, issuesMaxResponseBytes))
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("read %s response: %v", tracker.Type, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet := data
+		if len(snippet) > issuesErrorBodyBytes {
+			snippet = snippet[:issuesErrorBodyBytes]
+		}
+		return resp.StatusCode, fmt.Errorf("%s returned status %d: %s", tracker.Type, resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("invalid %s response: %v", tracker.Type, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// githubIssue GitHub 问题的响应字段
+type githubIssue struct {
+	Number   int                     `json:"number"`
+	Title    string                  `json:"title"`
+	State    string                  `json:"state"`
+	Body     string                  `json:"body"`
+	HTMLURL  string                  `json:"html_url"`
+	User     struct{ Login string }  `json:"user"`
+	Assignee *struct{ Login string } `json:"assignee"`
+	Labels   []struct{ Name string } `json:"labels"`
+	Created  time.Time               `json:"created_at"`
+	Updated  time.Time               `json:"updated_at"`
+}
+
+func (g githubIssue) issue(withBody bool) Issue {
+	issue := Issue{
+		ID:      strconv.Itoa(g.Number),
+		Title:   g.Title,
+		State:   g.State,
+		Author:  g.User.Login,
+		URL:     g.HTMLURL,
+		Created: g.Created,
+		Updated: g.Updated,
+	}
+	if g.Assignee != nil {
+		issue.Assignee = g.Assignee.Login
+	}
+	for _, label := range g.Labels {
+		issue.Labels = append(issue.Labels, label.Name)
+	}
+	if withBody {
+		issue.Body = g.Body
+	}
+	return issue
+}
+
+// githubComment GitHub 评论的响应字段
+type githubComment struct {
+	ID      int64                  `json:"id"`
+	Body    string                 `json:"body"`
+	HTMLURL string                 `json:"html_url"`
+	User    struct{ Login string } `json:"user"`
+	Created time.Time              `json:"created_at"`
+}
+
+func (g githubComment) comment() IssueComment {
+	return IssueComment{ID: strconv.FormatInt(g.ID, 10), Author: g.User.Login, Body: g.Body, URL: g.HTMLURL, Created: g.Created}
+}
+
+// github 调用 GitHub REST API，问题限定在配置的仓库内
+func (it *IssuesTool) github(ctx context.Context, tracker config.IssueTrackerConfig, args IssuesArgs, result *IssuesResult) error {
+	if strings.Count(tracker.Repo, "/") != 1 {
+		return fmt.Errorf("github tracker requires repo as owner/name")
+	}
+	base := tracker.URL
+	if base == "" {
+		base = defaultGitHubAPIURL
+	}
+	base = strings.TrimRight(base, "/")
+	repoURL := base + "/repos/" + tracker.Repo
+
+	var number int
+	if args.ID != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(args.ID, "#"))
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid github issue number: %s", args.ID)
+		}
+		number = n
+	}
+
+	switch args.Op {
+	case "search":
+		query := strings.TrimSpace(args.Query + " repo:" + tracker.Repo + " is:issue")
+		params := url.Values{"q": {query}, "per_page": {strconv.Itoa(min(args.Limit, 100))}}
+		var response struct {
+			TotalCount int           `json:"total_count"`
+			Items      []githubIssue `json:"items"`
+		}
+		if _, err := it.issuesRequest(ctx, tracker, http.MethodGet, base+"/search/issues?"+params.Encode(), nil, &response); err != nil {
+			return err
+		}
+		result.Total = response.TotalCount
+		result.Issues = make([]Issue, 0, len(response.Items))
+		for _, item := range response.Items {
+			if len(result.Issues) >= args.Limit {
+				break
+			}
+			result.Issues = append(result.Issues, item.issue(false))
+		}
+	case "get":
+		var issue githubIssue
+		if _, err := it.issuesRequest(ctx, tracker, http.MethodGet, fmt.Sprintf("%s/issues/%d", repoURL, number), nil, &issue); err != nil {
+			return err
+		}
+		converted := issue.issue(true)
+		result.Issue = &converted
+		var comments []githubComment
+		params := url.Values{"per_page": {strconv.Itoa(min(args.Limit, 100))}}
+		if _, err := it.issuesRequest(ctx, tracker, http.MethodGet, fmt.Sprintf("%s/issues/%d/comments?%s", repoURL, number, params.Encode()), nil, &comments); err != nil {
+			return err
+		}
+		for _, c := range comments {
+			if len(result.Comments) >= args.Limit {
+				break
+			}
+			result.Comments = append(result.Comments, c.comment())
+		}
+	case "create":
+		body := map[string]interface{}{"title": args.Title, "body": args.Body}
+		if len(args.Labels) > 0 {
+			body["labels"] = args.Labels
+		}
+		var issue githubIssue
+		if _, err := it.issuesRequest(ctx, tracker, http.MethodPost, repoURL+"/issues", body, &issue); err != nil {
+			return err
+		}
+		converted := issue.issue(true)
+		result.Issue = &converted
+	case "comment":
+		var comment githubComment
+		if _, err := it.issuesRequest(ctx, tracker, http.MethodPost, fmt.Sprintf("%s/issues/%d/comments", repoURL, number), map[string]string{"body": args.Body}, &comment); err != nil {
+			return err
+		}
+		converted := comment.comment()
+		result.Comment = &converted
+	}
+	return nil
+}
+
+// jiraTime Jira 时间字段
+type jiraTime time.Time
+
+func (t *jiraTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil || s == "" {
+		return nil
+	}
+	parsed, err := time.Parse(jiraTimeLayout, s)
+	if err != nil {
+		parsed, err = time.Parse(time.RFC3339, s)
+	}
+	if err == nil {
+		*t = jiraTime(parsed)
+	}
+	return nil
+}
+
+// jiraUser Jira 用户字段
+type jiraUser struct {
+	DisplayName string `json:"displayName"`
+}
+
+func (u *jiraUser) name() string {
+	if u == nil {
+		return ""
+	}
+	return u.DisplayName
+}
+
+// jiraIssue Jira 问题的响应字段
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string                 `json:"summary"`
+		Description string                 `json:"description"`
+		Status      *struct{ Name string } `json:"status"`
+		Reporter    *jiraUser              `json:"reporter"`
+		Assignee    *jiraUser              `json:"assignee"`
+		Labels      []string               `json:"labels"`
+		Created     jiraTime               `json:"created"`
+		Updated     jiraTime               `json:"updated"`
+	} `json:"fields"`
+}
+
+func (j jiraIssue) issue(base string, withBody bool) Issue {
+	issue := Issue{
+		ID:       j.Key,
+		Title:    j.Fields.Summary,
+		Author:   j.Fields.Reporter.name(),
+		Assignee: j.Fields.Assignee.name(),
+		Labels:   j.Fields.Labels,
+		URL:      base + "/browse/" + j.Key,
+		Created:  time.Time(j.Fields.Created),
+		Updated:  time.Time(j.Fields.Updated),
+	}
+	if j.Fields.Status != nil {
+		issue.State = j.Fields.Status.Name
+	}
+	if withBody {
+		issue.Body = j.Fields.Description
+	}
+	return issue
+}
+
+// jiraComment Jira 评论的响应字段
+type jiraComment struct {
+	ID      string    `json:"id"`
+	Body    string    `json:"body"`
+	Author  *jiraUser `json:"author"`
+	Created jiraTime  `json:"created"`
+}
+
+func (j jiraComment) comment() IssueComment {
+	return IssueComment{ID: j.ID, Author: j.Author.name(), Body: j.Body, Created: time.Time(j.Created)}
+}
+
+// jiraFields 搜索与读取问题时请求的字段
+const jiraFields = "summary,status,reporter,assignee,labels,created,updated"
+
+// jira 调用 Jira REST API v2，配置了 project 时搜索与创建限定在该项目内
+func (it *IssuesTool) jira(ctx context.Context, tracker config.IssueTrackerConfig, args IssuesArgs, result *IssuesResult) error {
+	if tracker.URL == "" {
+		return fmt.Errorf("jira tracker requires url")
+	}
+	base := strings.TrimRight(tracker.URL, "/")
+	api := base + "/rest/api/2"
+	if args.ID != "" {
+		args.ID = strings.ToUpper(args.ID)
+		if !jiraKeyPattern.MatchString(args.ID) {
+			return fmt.Errorf("invalid jira issue key: %s", args.ID)
+		}
+		if tracker.Project != "" && !strings.HasPrefix(args.ID, strings.ToUpper(tracker.Project)+"-") {
+			return fmt.Errorf("issue %s is outside project %s", args.ID, tracker.Project)
+		}
+	}
+
+	switch args.Op {
+	case "search":
+		params := url.Values{
+			"jql":        {scopeJQL(args.Query, tracker.Project)},
+			"maxResults": {strconv.Itoa(args.Limit)},
+			"fields":     {jiraFields},
+		}
+		var response struct {
+			Total  int         `json:"total"`
+			Issues []jiraIssue `json:"issues"`
+		}
+		status, err := it.issuesRequest(ctx, tracker, http.MethodGet, api+"/search?"+params.Encode(), nil, &response)
+		// Jira Cloud 已用 /search/jql 替换 /search，后者不返回总数
+		if status == http.StatusGone || status == http.StatusNotFound {
+			status, err = it.issuesRequest(ctx, tracker, http.MethodGet, api+"/search/jql?"+params.Encode(), nil, &response)
+		}
+		if err != nil {
+			return err
+		}
+		result.Total = response.Total
+		result.Issues = make([]Issue, 0, len(response.Issues))
+		for _, issue := range response.Issues {
+			if len(result.Issues) >= args.Limit {
+				break
+			}
+			result.Issues = append(result.Issues, issue.issue(base, false))
+		}
+	case "get":
+		var issue jiraIssue
+		params := url.Values{"fields": {jiraFields + ",description"}}
+		if _, err := it.issuesRequest(ctx, tracker, http.MethodGet, api+"/issue/"+args.ID+"?"+params.Encode(), nil, &issue); err != nil {
+			return err
+		}
+		converted := issue.issue(base, true)
+		result.Issue = &converted
+		var response struct {
+			Comments []jiraComment `json:"comments"`
+		}
+		params = url.Values{"maxResults": {strconv.Itoa(args.Limit)}}
+		if _, err := it.issuesRequest(ctx, tracker, http.MethodGet, api+"/issue/"+args.ID+"/comment?"+params.Encode(), nil, &response); err != nil {
+			return err
+		}
+		for _, c := range response.Comments {
+			if len(result.Comments) >= args.Limit {
+				break
+			}
+			result.Comments = append(result.Comments, c.comment())
+		}
+	case "create":
+		if tracker.Project == "" {
+			return fmt.Errorf("jira tracker requires project for create")
+		}
+		issueType := args.IssueType
+		if issueType == "" {
+			issueType = defaultJiraIssueType
+		}
+		fields := map[string]interface{}{
+			"project":     map[string]string{"key": tracker.Project},
+			"summary":     args.Title,
+			"description": args.Body,
+			"issuetype":   map[string]string{"name": issueType},
+		}
+		if len(args.Labels) > 0 {
+			fields["labels"] = args.Labels
+		}
+		var created struct {
+			Key string `json:"key"`
+		}
+		if _, err := it.issuesRequest(ctx, tracker, http.MethodPost, api+"/issue", map[string]interface{}{"fields": fields}, &created); err != nil {
+			return err
+		}
+		result.Issue = &Issue{ID: created.Key, Title: args.Title, Labels: args.Labels, URL: base + "/browse/" + created.Key, Body: args.Body}
+	case "comment":
+		var comment jiraComment
+		if _, err := it.issuesRequest(ctx, tracker, http.MethodPost, api+"/issue/"+args.ID+"/comment", map[string]string{"body": args.Body}, &comment); err != nil {
+			return err
+		}
+		converted := comment.comment()
+		converted.URL = base + "/browse/" + args.ID + "?focusedCommentId=" + url.QueryEscape(comment.ID)
+		result.Comment = &converted
+	}
+	return nil
+}
+
+// scopeJQL 将查询限定在项目内，ORDER BY 子句保留在末尾
+func scopeJQL(query, project string) string {
+	query = strings.TrimSpace(query)
+	if project == "" {
+		return query
+	}
+	scope := fmt.Sprintf("project = %q", project)
+	orderBy := jqlOrderBy.FindString(query)
+	where := strings.TrimSpace(strings.TrimSuffix(query, orderBy))
+	if where != "" {
+		scope += " AND (" + where + ")"
+	}
+	if orderBy != "" {
+		scope += " " + strings.TrimSpace(orderBy)
+	}
+	return scope
+}
@@ -0,0 +1,328 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itchyny/gojq"
+
+	"Weave-Toolkit/internal/jsonpath"
+	"Weave-Toolkit/internal/schema"
+)
+
+// json_transform 默认限制
+const (
+	maxTransformResults    = 1000
+	defaultFlattenSep      = "."
+	defaultTransformIndent = 2
+)
+
+// JSONTransformTool JSON 转换工具
+//
+// 对输入文档执行 jq 表达式或 JSONPath 查询、格式化、扁平化，或计算两个文档的差异，
+// 作为流水线中各工具之间的数据衔接。jq 表达式无法访问环境变量与文件。
+type JSONTransformTool struct{}
+
+// JSONTransformArgs JSON 转换参数
+type JSONTransformArgs struct {
+	Op         string      `json:"op"` // jq, jsonpath, format, flatten, diff
+	Input      interface{} `json:"input"`
+	Other      interface{} `json:"other"`       // diff 的目标文档
+	Expression string      `json:"expression"`  // jq 表达式或 JSONPath
+	ParseInput bool        `json:"parse_input"` // input 与 other 为 JSON 文本
+	Separator  string      `json:"separator"`   // flatten 的键分隔符
+	Pretty     bool        `json:"pretty"`
+	Indent     *int        `json:"indent"`
+}
+
+// JSONTransformResult JSON 转换结果
+type JSONTransformResult struct {
+	Op     string      `json:"op"`
+	Result interface{} `json:"result"`
+	Count  int         `json:"count,omitempty"` // jq 与 jsonpath 的结果数量
+	Equal  *bool       `json:"equal,omitempty"` // diff 时两个文档是否相同
+	Text   string      `json:"text,omitempty"`  // format 或 pretty 时的文本形式
+}
+
+// JSONPatchOp RFC 6902 JSON Patch 操作，diff 的结果可直接用于 patch
+type JSONPatchOp struct {
+	Op    string      `json:"op"` // add, remove, replace
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON remove 操作不带 value，add 与 replace 即使值为 null 也保留 value
+func (op JSONPatchOp) MarshalJSON() ([]byte, error) {
+	if op.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{op.Op, op.Path})
+	}
+	type plain JSONPatchOp
+	return json.Marshal(plain(op))
+}
+
+func (jt *JSONTransformTool) Name() string {
+	return "json_transform"
+}
+
+func (jt *JSONTransformTool) Description() string {
+	return "Transform JSON with jq or JSONPath expressions, pretty-print, flatten, or diff two documents"
+}
+
+func (jt *JSONTransformTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (jt *JSONTransformTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"op":          {Type: schema.TypeString, Description: "Operation", Enum: []interface{}{"jq", "jsonpath", "format", "flatten", "diff"}},
+		"input":       {Description: "Input document"},
+		"other":       {Description: "Document to compare against for diff"},
+		"expression":  {Type: schema.TypeString, Description: "jq filter for jq, path such as $.items[*].name for jsonpath", MinLength: schema.Int(1)},
+		"parse_input": {Type: schema.TypeBoolean, Description: "Treat string input and other as JSON text"},
+		"separator":   {Type: schema.TypeString, Description: "Key separator for flatten", Default: defaultFlattenSep, MinLength: schema.Int(1)},
+		"pretty":      {Type: schema.TypeBoolean, Description: "Also return the result as indented text"},
+		"indent":      {Type: schema.TypeInteger, Description: "Indent width for format and pretty, 0 for compact", Minimum: schema.Float(0), Maximum: schema.Float(8)},
+	}, "op", "input").Closed()
+}
+
+func (jt *JSONTransformTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var transformArgs JSONTransformArgs
+	if err := json.Unmarshal(args, &transformArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+
+	input, err := transformInput(transformArgs.Input, transformArgs.ParseInput)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input: %v", err)
+	}
+
+	result := JSONTransformResult{Op: transformArgs.Op}
+	switch transformArgs.Op {
+	case "jq":
+		if transformArgs.Expression == "" {
+			return nil, fmt.Errorf("expression is required for jq")
+		}
+		values, err := runJQ(ctx, transformArgs.Expression, input)
+		if err != nil {
+			return nil, err
+		}
+		result.Result, result.Count = values, len(values)
+
+	case "jsonpath":
+		if transformArgs.Expression == "" {
+			return nil, fmt.Errorf("expression is required for jsonpath")
+		}
+		values, err := jsonpath.Query(transformArgs.Expression, input)
+		if err != nil {
+			return nil, err
+		}
+		result.Result, result.Count = values, len(values)
+
+	case "format":
+		result.Result = input
+		transformArgs.Pretty = true
+
+	case "flatten":
+		separator := transformArgs.Separator
+		if separator == "" {
+			separator = defaultFlattenSep
+		}
+		result.Result = flattenJSON(input, separator)
+
+	case "diff":
+		other, err := transformInput(transformArgs.Other, transformArgs.ParseInput)
+		if err != nil {
+			return nil, fmt.Errorf("invalid other: %v", err)
+		}
+		patch := diffJSON("", input, other, []JSONPatchOp{})
+		equal := len(patch) == 0
+		result.Result, result.Equal = patch, &equal
+
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", transformArgs.Op)
+	}
+
+	if transformArgs.Pretty {
+		indent := defaultTransformIndent
+		if transformArgs.Indent != nil {
+			indent = *transformArgs.Indent
+		}
+		if result.Text, err = formatJSON(result.Result, indent); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(result)
+}
+
+// transformInput 按需将字符串输入解析为 JSON 文档
+func transformInput(value interface{}, parse bool) (interface{}, error) {
+	if text, ok := value.(string); ok && parse {
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+			return nil, err
+		}
+		return parsed, nil
+	}
+	return value, nil
+}
+
+// runJQ 执行 jq 表达式，结果数量超过上限时返回错误
+func runJQ(ctx context.Context, expression string, input interface{}) ([]interface{}, error) {
+	query, err := gojq.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq expression: %v", err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq expression: %v", err)
+	}
+
+	values := []interface{}{}
+	iter := code.RunWithContext(ctx, input)
+	for {
+		value, ok := iter.Next()
+		if !ok {
+			return values, nil
+		}
+		if err, isErr := value.(error); isErr {
+			var halt *gojq.HaltError
+			if errors.As(err, &halt) && halt.Value() == nil {
+				return values, nil
+			}
+			return nil, fmt.Errorf("jq: %v", err)
+		}
+		if len(values) == maxTransformResults {
+			return nil, fmt.Errorf("jq produced more than %d results", maxTransformResults)
+		}
+		values = append(values, value)
+	}
+}
+
+// formatJSON 按缩进宽度格式化，0 表示紧凑输出；不转义 HTML 字符
+func formatJSON(value interface{}, indent int) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if indent > 0 {
+		encoder.SetIndent("", strings.Repeat(" ", indent))
+	}
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// flattenJSON 将嵌套对象与数组展开为单层对象，键为以分隔符连接的路径，数组下标作为键名；
+// 空对象与空数组保留为叶子值，标量输入原样返回
+func flattenJSON(value interface{}, separator string) interface{} {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		return value
+	}
+
+	flat := make(map[string]interface{})
+	var walk func(prefix string, v interface{})
+	walk = func(prefix string, v interface{}) {
+		join := func(key string) string {
+			if prefix == "" {
+				return key
+			}
+			return prefix + separator + key
+		}
+		switch node := v.(type) {
+		case map[string]interface{}:
+			if len(node) == 0 && prefix != "" {
+				flat[prefix] = node
+			}
+			for key, child := range node {
+				walk(join(key), child)
+			}
+		case []interface{}:
+			if len(node) == 0 && prefix != "" {
+				flat[prefix] = node
+			}
+			for i, child := range node {
+				walk(join(strconv.Itoa(i)), child)
+			}
+		default:
+			flat[prefix] = node
+		}
+	}
+	walk("", value)
+	return flat
+}
+
+// diffJSON 生成将 from 变为 to 的 JSON Patch，路径为 RFC 6901 JSON Pointer
+//
+// 对象按键比较；数组按下标逐个比较，多出的元素从末尾起依次删除，保证补丁按顺序应用有效。
+func diffJSON(path string, from, to interface{}, patch []JSONPatchOp) []JSONPatchOp {
+	switch a := from.(type) {
+	case map[string]interface{}:
+		b, ok := to.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(a)+len(b))
+		for key := range a {
+			keys = append(keys, key)
+		}
+		for key := range b {
+			if _, exists := a[key]; !exists {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := path + "/" + escapePointer(key)
+			before, inFrom := a[key]
+			after, inTo := b[key]
+			switch {
+			case !inTo:
+				patch = append(patch, JSONPatchOp{Op: "remove", Path: child})
+			case !inFrom:
+				patch = append(patch, JSONPatchOp{Op: "add", Path: child, Value: after})
+			default:
+				patch = diffJSON(child, before, after, patch)
+			}
+		}
+		return patch
+
+	case []interface{}:
+		b, ok := to.([]interface{})
+		if !ok {
+			break
+		}
+		common := min(len(a), len(b))
+		for i := 0; i < common; i++ {
+			patch = diffJSON(path+"/"+strconv.Itoa(i), a[i], b[i], patch)
+		}
+		for i := len(a) - 1; i >= common; i-- {
+			patch = append(patch, JSONPatchOp{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+		}
+		for i := common; i < len(b); i++ {
+			patch = append(patch, JSONPatchOp{Op: "add", Path: path + "/-", Value: b[i]})
+		}
+		return patch
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		patch = append(patch, JSONPatchOp{Op: "replace", Path: path, Value: to})
+	}
+	return patch
+}
+
+// escapePointer 按 RFC 6901 转义 JSON Pointer 中的 ~ 与 /
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
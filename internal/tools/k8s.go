@@ -0,0 +1,563 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/internal/schema"
+)
+
+// k8s 工具默认限制
+const (
+	defaultK8sMaxLogBytes = 256 << 10
+	defaultK8sTailLines   = 100
+	defaultK8sListLimit   = 100
+	maxK8sListLimit       = 500
+	k8sAllNamespaces      = "*"
+)
+
+// 支持的资源类型
+const (
+	K8sKindPod        = "pods"
+	K8sKindDeployment = "deployments"
+	K8sKindService    = "services"
+)
+
+var (
+	// ErrK8sReadOnly 只读模式下请求了写操作
+	ErrK8sReadOnly = errors.New("k8s tool is read-only")
+	// ErrNamespaceDenied 命名空间不在允许列表中
+	ErrNamespaceDenied = werrors.New(werrors.KindUnauthorized, "namespace not allowed")
+)
+
+// K8sTool Kubernetes 集群查询工具
+//
+// 列出 Pod、Deployment 与 Service，查看资源详情与相关事件，获取 Pod 日志（流式调用时
+// 可持续跟随）。只能访问配置允许的命名空间；默认只读，开启 allow_write 后才允许
+// 调整 Deployment 副本数与删除 Pod。集群连接在首次调用时建立。
+type K8sTool struct {
+	config config.K8sConfig
+
+	mu        sync.Mutex
+	client    kubernetes.Interface
+	defaultNS string
+}
+
+// K8sArgs 集群操作参数
+type K8sArgs struct {
+	Op            string `json:"op"`   // list, describe, logs, scale, delete
+	Kind          string `json:"kind"` // pods, deployments, services
+	Namespace     string `json:"namespace"`
+	Name          string `json:"name"`
+	LabelSelector string `json:"label_selector"`
+	Limit         int64  `json:"limit"`
+	Continue      string `json:"continue"`
+	Container     string `json:"container"`
+	TailLines     int64  `json:"tail_lines"`
+	SinceSeconds  int64  `json:"since_seconds"`
+	Previous      bool   `json:"previous"`
+	Follow        bool   `json:"follow"` // 仅流式调用支持
+	Replicas      *int32 `json:"replicas"`
+}
+
+// K8sResult 集群操作结果
+type K8sResult struct {
+	Op        string          `json:"op"`
+	Kind      string          `json:"kind,omitempty"`
+	Namespace string          `json:"namespace"`
+	Name      string          `json:"name,omitempty"`
+	Items     interface{}     `json:"items,omitempty"`
+	Continue  string          `json:"continue,omitempty"` // 下一页的令牌
+	Object    json.RawMessage `json:"object,omitempty"`
+	Events    []K8sEvent      `json:"events,omitempty"`
+	Logs      string          `json:"logs,omitempty"`
+	Lines     int             `json:"lines,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"`
+	Replicas  *int32          `json:"replicas,omitempty"`
+}
+
+// K8sPod Pod 摘要
+type K8sPod struct {
+	Name      string    `json:"name"`
+	Phase     string    `json:"phase"`
+	Ready     string    `json:"ready"` // 就绪容器数/容器总数
+	Restarts  int32     `json:"restarts"`
+	Node      string    `json:"node,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// K8sDeployment Deployment 摘要
+type K8sDeployment struct {
+	Name      string    `json:"name"`
+	Replicas  int32     `json:"replicas"`
+	Ready     int32     `json:"ready"`
+	Updated   int32     `json:"updated"`
+	Available int32     `json:"available"`
+	Images    []string  `json:"images"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// K8sService Service 摘要
+type K8sService struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	ClusterIP string            `json:"cluster_ip,omitempty"`
+	Ports     []string          `json:"ports"` // port/protocol->targetPort
+	Selector  map[string]string `json:"selector,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// K8sEvent 资源相关事件
+type K8sEvent struct {
+	Type     string    `json:"type"`
+	Reason   string    `json:"reason"`
+	Message  string    `json:"message"`
+	Count    int32     `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// NewK8sTool 创建 Kubernetes 工具
+func NewK8sTool(cfg config.K8sConfig) *K8sTool {
+	if cfg.MaxLogBytes <= 0 {
+		cfg.MaxLogBytes = defaultK8sMaxLogBytes
+	}
+	if cfg.TailLines <= 0 {
+		cfg.TailLines = defaultK8sTailLines
+	}
+	return &K8sTool{config: cfg}
+}
+
+// NewK8sToolWithClient 使用已有的客户端创建 Kubernetes 工具，defaultNamespace 为未指定命名空间时使用的命名空间
+func NewK8sToolWithClient(cfg config.K8sConfig, client kubernetes.Interface, defaultNamespace string) *K8sTool {
+	kt := NewK8sTool(cfg)
+	kt.client = client
+	kt.defaultNS = defaultNamespace
+	return kt
+}
+
+func (kt *K8sTool) Name() string {
+	return "k8s"
+}
+
+func (kt *K8sTool) Description() string {
+	return "Inspect Kubernetes pods, deployments and services: list, describe with events, and fetch pod logs"
+}
+
+func (kt *K8sTool) Category() ToolCategory {
+	return CategorySystem
+}
+
+func (kt *K8sTool) Tags() []string {
+	return []string{TagNetwork}
+}
+
+func (kt *K8sTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"op":             {Type: schema.TypeString, Description: "Operation; scale and delete require allow_write", Enum: []interface{}{"list", "describe", "logs", "scale", "delete"}},
+		"kind":           {Type: schema.TypeString, Description: "Resource kind for list and describe", Enum: []interface{}{K8sKindPod, K8sKindDeployment, K8sKindService}, Default: K8sKindPod},
+		"namespace":      {Type: schema.TypeString, Description: "Namespace, defaults to the configured default"},
+		"name":           {Type: schema.TypeString, Description: "Resource name, required except for list", MinLength: schema.Int(1)},
+		"label_selector": {Type: schema.TypeString, Description: "Label selector for list, e.g. app=web"},
+		"limit":          {Type: schema.TypeInteger, Description: "Maximum items per list page", Minimum: schema.Float(1), Maximum: schema.Float(maxK8sListLimit)},
+		"continue":       {Type: schema.TypeString, Description: "Continue token returned by the previous list"},
+		"container":      {Type: schema.TypeString, Description: "Container for logs, defaults to the only container"},
+		"tail_lines":     {Type: schema.TypeInteger, Description: "Number of log lines from the end", Minimum: schema.Float(1)},
+		"since_seconds":  {Type: schema.TypeInteger, Description: "Only logs newer than this many seconds", Minimum: schema.Float(1)},
+		"previous":       {Type: schema.TypeBoolean, Description: "Logs of the previous terminated container"},
+		"follow":         {Type: schema.TypeBoolean, Description: "Keep streaming new log lines, streaming calls only"},
+		"replicas":       {Type: schema.TypeInteger, Description: "Desired replicas for scale", Minimum: schema.Float(0)},
+	}, "op").Closed()
+}
+
+func (kt *K8sTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	return kt.execute(ctx, args, nil)
+}
+
+// ExecuteStream 流式调用，logs 按行推送，其余操作与 Execute 相同
+func (kt *K8sTool) ExecuteStream(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	return kt.execute(ctx, args, callback)
+}
+
+func (kt *K8sTool) execute(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	var k8sArgs K8sArgs
+	if err := json.Unmarshal(args, &k8sArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	if k8sArgs.Kind == "" {
+		k8sArgs.Kind = K8sKindPod
+	}
+
+	client, err := kt.clientset()
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := kt.namespace(k8sArgs.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	op := strings.ToLower(k8sArgs.Op)
+	switch op {
+	case "list":
+	case "describe", "logs", "scale", "delete":
+		if k8sArgs.Name == "" {
+			return nil, fmt.Errorf("name is required for %s", op)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", k8sArgs.Op)
+	}
+	if (op == "scale" || op == "delete") && !kt.config.AllowWrite {
+		return nil, fmt.Errorf("%w: %s is disabled", ErrK8sReadOnly, op)
+	}
+
+	result := &K8sResult{Op: op, Kind: k8sArgs.Kind, Namespace: namespace, Name: k8sArgs.Name}
+	switch op {
+	case "list":
+		err = kt.list(ctx, client, k8sArgs, result)
+	case "describe":
+		err = kt.describe(ctx, client, k8sArgs, result)
+	case "logs":
+		result.Kind = K8sKindPod
+		err = kt.logs(ctx, client, k8sArgs, result, callback)
+	case "scale":
+		err = kt.scale(ctx, client, k8sArgs, result)
+	case "delete":
+		if k8sArgs.Kind != K8sKindPod {
+			return nil, fmt.Errorf("delete only supports %s", K8sKindPod)
+		}
+		err = client.CoreV1().Pods(namespace).Delete(ctx, k8sArgs.Name, metav1.DeleteOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// clientset 获取客户端，首次调用时加载集群配置，失败时下次调用重试
+func (kt *K8sTool) clientset() (kubernetes.Interface, error) {
+	kt.mu.Lock()
+	defer kt.mu.Unlock()
+	if kt.client != nil {
+		return kt.client, nil
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kt.config.Kubeconfig != "" {
+		path, err := platform.NormalizePath(kt.config.Kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		rules.ExplicitPath = path
+	}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{CurrentContext: kt.config.Context})
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubernetes config: %v", err)
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubernetes config: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %v", err)
+	}
+
+	kt.client = client
+	kt.defaultNS = namespace
+	return client, nil
+}
+
+// namespace 解析并校验命名空间：未指定时使用允许列表中的第一个，或集群配置的默认命名空间
+func (kt *K8sTool) namespace(requested string) (string, error) {
+	defaultNS := kt.defaultNS
+	if defaultNS == "" {
+		defaultNS = metav1.NamespaceDefault
+	}
+	allowed := kt.config.Namespaces
+
+	namespace := requested
+	if namespace == "" {
+		namespace = defaultNS
+		if len(allowed) > 0 && allowed[0] != k8sAllNamespaces {
+			namespace = allowed[0]
+		}
+	}
+
+	if slices.Contains(allowed, k8sAllNamespaces) || slices.Contains(allowed, namespace) ||
+		len(allowed) == 0 && namespace == defaultNS {
+		return namespace, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrNamespaceDenied, namespace)
+}
+
+// list 分页列出资源摘要
+func (kt *K8sTool) list(ctx context.Context, client kubernetes.Interface, args K8sArgs, result *K8sResult) error {
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultK8sListLimit
+	} else if limit > maxK8sListLimit {
+		limit = maxK8sListLimit
+	}
+	opts := metav1.ListOptions{LabelSelector: args.LabelSelector, Limit: limit, Continue: args.Continue}
+
+	switch args.Kind {
+	case K8sKindPod:
+		list, err := client.CoreV1().Pods(result.Namespace).List(ctx, opts)
+		if err != nil {
+			return err
+		}
+		items := make([]K8sPod, len(list.Items))
+		for i := range list.Items {
+			items[i] = summarizePod(&list.Items[i])
+		}
+		result.Items, result.Continue = items, list.Continue
+	case K8sKindDeployment:
+		list, err := client.AppsV1().Deployments(result.Namespace).List(ctx, opts)
+		if err != nil {
+			return err
+		}
+		items := make([]K8sDeployment, len(list.Items))
+		for i := range list.Items {
+			items[i] = summarizeDeployment(&list.Items[i])
+		}
+		result.Items, result.Continue = items, list.Continue
+	case K8sKindService:
+		list, err := client.CoreV1().Services(result.Namespace).List(ctx, opts)
+		if err != nil {
+			return err
+		}
+		items := make([]K8sService, len(list.Items))
+		for i := range list.Items {
+			items[i] = summarizeService(&list.Items[i])
+		}
+		result.Items, result.Continue = items, list.Continue
+	default:
+		return fmt.Errorf("unsupported kind: %s", args.Kind)
+	}
+	return nil
+}
+
+// describe 返回完整资源对象（去除 managedFields）与相关事件
+func (kt *K8sTool) describe(ctx context.Context, client kubernetes.Interface, args K8sArgs, result *K8sResult) error {
+	var (
+		object interface{}
+		kind   string
+		err    error
+	)
+	switch args.Kind {
+	case K8sKindPod:
+		var pod *corev1.Pod
+		if pod, err = client.CoreV1().Pods(result.Namespace).Get(ctx, args.Name, metav1.GetOptions{}); err == nil {
+			pod.ManagedFields = nil
+			object, kind = pod, "Pod"
+		}
+	case K8sKindDeployment:
+		var deployment *appsv1.Deployment
+		if deployment, err = client.AppsV1().Deployments(result.Namespace).Get(ctx, args.Name, metav1.GetOptions{}); err == nil {
+			deployment.ManagedFields = nil
+			object, kind = deployment, "Deployment"
+		}
+	case K8sKindService:
+		var service *corev1.Service
+		if service, err = client.CoreV1().Services(result.Namespace).Get(ctx, args.Name, metav1.GetOptions{}); err == nil {
+			service.ManagedFields = nil
+			object, kind = service, "Service"
+		}
+	default:
+		return fmt.Errorf("unsupported kind: %s", args.Kind)
+	}
+	if err != nil {
+		return err
+	}
+
+	if result.Object, err = json.Marshal(object); err != nil {
+		return err
+	}
+
+	selector := fields.AndSelectors(
+		fields.OneTermEqualSelector("involvedObject.name", args.Name),
+		fields.OneTermEqualSelector("involvedObject.kind", kind),
+	)
+	events, err := client.CoreV1().Events(result.Namespace).List(ctx, metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		// 事件仅作补充信息，无权限读取时不影响结果
+		return nil
+	}
+	for _, event := range events.Items {
+		if event.InvolvedObject.Name != args.Name || event.InvolvedObject.Kind != kind {
+			continue
+		}
+		lastSeen := event.LastTimestamp.Time
+		if lastSeen.IsZero() {
+			lastSeen = event.EventTime.Time
+		}
+		result.Events = append(result.Events, K8sEvent{
+			Type:     event.Type,
+			Reason:   event.Reason,
+			Message:  event.Message,
+			Count:    event.Count,
+			LastSeen: lastSeen,
+		})
+	}
+	return nil
+}
+
+// logs 读取 Pod 日志，超过上限时截断；有回调时按行推送而不在结果中返回日志
+func (kt *K8sTool) logs(ctx context.Context, client kubernetes.Interface, args K8sArgs, result *K8sResult, callback func(content string, index int)) error {
+	if args.Follow && callback == nil {
+		return fmt.Errorf("follow requires a streaming call")
+	}
+
+	opts := &corev1.PodLogOptions{
+		Container: args.Container,
+		Follow:    args.Follow,
+		Previous:  args.Previous,
+	}
+	tail := args.TailLines
+	if tail <= 0 {
+		tail = kt.config.TailLines
+	}
+	opts.TailLines = &tail
+	if args.SinceSeconds > 0 {
+		opts.SinceSeconds = &args.SinceSeconds
+	}
+
+	stream, err := client.CoreV1().Pods(result.Namespace).GetLogs(args.Name, opts).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	var (
+		logs strings.Builder
+		size int64
+	)
+	reader := bufio.NewReader(stream)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if size+int64(len(line)) > kt.config.MaxLogBytes {
+				result.Truncated = true
+				break
+			}
+			size += int64(len(line))
+			if callback != nil {
+				callback(strings.TrimSuffix(line, "\n"), result.Lines)
+			} else {
+				logs.WriteString(line)
+			}
+			result.Lines++
+		}
+		if err != nil {
+			// 跟随日志时调用超时或被取消视为正常结束
+			if err == io.EOF || args.Follow && ctx.Err() != nil {
+				break
+			}
+			return err
+		}
+	}
+	result.Logs = logs.String()
+	return nil
+}
+
+// scale 调整 Deployment 副本数
+func (kt *K8sTool) scale(ctx context.Context, client kubernetes.Interface, args K8sArgs, result *K8sResult) error {
+	if args.Kind != K8sKindDeployment {
+		return fmt.Errorf("scale only supports %s", K8sKindDeployment)
+	}
+	if args.Replicas == nil || *args.Replicas < 0 {
+		return fmt.Errorf("replicas is required for scale")
+	}
+
+	deployments := client.AppsV1().Deployments(result.Namespace)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		deployment, err := deployments.Get(ctx, args.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		deployment.Spec.Replicas = args.Replicas
+		_, err = deployments.Update(ctx, deployment, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	result.Replicas = args.Replicas
+	return nil
+}
+
+func summarizePod(pod *corev1.Pod) K8sPod {
+	ready, restarts := 0, int32(0)
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			ready++
+		}
+		restarts += status.RestartCount
+	}
+	return K8sPod{
+		Name:      pod.Name,
+		Phase:     string(pod.Status.Phase),
+		Ready:     fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers)),
+		Restarts:  restarts,
+		Node:      pod.Spec.NodeName,
+		IP:        pod.Status.PodIP,
+		CreatedAt: pod.CreationTimestamp.Time,
+	}
+}
+
+func summarizeDeployment(deployment *appsv1.Deployment) K8sDeployment {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	images := make([]string, 0, len(deployment.Spec.Template.Spec.Containers))
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		images = append(images, container.Image)
+	}
+	return K8sDeployment{
+		Name:      deployment.Name,
+		Replicas:  replicas,
+		Ready:     deployment.Status.ReadyReplicas,
+		Updated:   deployment.Status.UpdatedReplicas,
+		Available: deployment.Status.AvailableReplicas,
+		Images:    images,
+		CreatedAt: deployment.CreationTimestamp.Time,
+	}
+}
+
+func summarizeService(service *corev1.Service) K8sService {
+	ports := make([]string, 0, len(service.Spec.Ports))
+	for _, port := range service.Spec.Ports {
+		ports = append(ports, fmt.Sprintf("%d/%s->%s", port.Port, port.Protocol, port.TargetPort.String()))
+	}
+	return K8sService{
+		Name:      service.Name,
+		Type:      string(service.Spec.Type),
+		ClusterIP: service.Spec.ClusterIP,
+		Ports:     ports,
+		Selector:  service.Spec.Selector,
+		CreatedAt: service.CreationTimestamp.Time,
+	}
+}
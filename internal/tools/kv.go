@@ -0,0 +1,499 @@
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/schema"
+)
+
+// kv 工具默认限制
+const (
+	KVMemoryInstance       = "memory" // 内置内存存储的实例名
+	defaultKVMaxValueBytes = 64 << 10
+	defaultKVMaxKeys       = 10000
+	defaultKVScanCount     = 100
+	maxKVScanCount         = 1000
+	maxKVKeyLength         = 512
+)
+
+// ErrKVFull 内存存储的键数量已达上限
+var ErrKVFull = errors.New("kv store is full")
+
+// kvStore 键值存储后端，键已包含前缀
+type kvStore interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	Del(ctx context.Context, key string) (int64, error)
+	// Scan 按 glob 模式遍历键，返回的游标为 0 表示遍历结束
+	Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error)
+	// TTL 返回剩余生存时间，未设置过期时间时为 -1
+	TTL(ctx context.Context, key string) (time.Duration, bool, error)
+}
+
+// KVTool 键值存储工具
+//
+// 让智能体在多次工具调用之间通过服务器保存少量状态。支持配置多个命名的 Redis 实例，
+// 并始终提供名为 memory 的进程内存储；未配置默认实例时使用内存存储，
+// 其数据在进程重启后丢失且不在多个实例间共享。
+type KVTool struct {
+	config config.KVConfig
+	stores map[string]kvStore
+}
+
+// KVArgs 键值操作参数
+type KVArgs struct {
+	Op         string `json:"op"` // get, set, del, scan, ttl
+	Instance   string `json:"instance"`
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+	TTLSeconds int64  `json:"ttl_seconds"` // set 时的过期时间，0 表示不过期
+	Match      string `json:"match"`       // scan 的 glob 模式
+	Cursor     string `json:"cursor"`      // scan 游标，首次为空或 "0"
+	Count      int64  `json:"count"`       // scan 每次返回数量的提示值
+}
+
+// KVResult 键值操作结果
+type KVResult struct {
+	Op       string   `json:"op"`
+	Instance string   `json:"instance"`
+	Key      string   `json:"key,omitempty"`
+	Found    bool     `json:"found"`
+	Value    *string  `json:"value,omitempty"`
+	Deleted  int64    `json:"deleted,omitempty"`
+	TTL      *int64   `json:"ttl_seconds,omitempty"` // -1 表示不过期
+	Keys     []string `json:"keys,omitempty"`
+	Cursor   string   `json:"cursor,omitempty"` // 下一次 scan 的游标，"0" 表示遍历结束
+}
+
+// NewKVTool 创建键值存储工具，Redis 连接在首次使用时建立
+func NewKVTool(cfg config.KVConfig) *KVTool {
+	if cfg.MaxValueBytes <= 0 {
+		cfg.MaxValueBytes = defaultKVMaxValueBytes
+	}
+	if cfg.MaxKeys <= 0 {
+		cfg.MaxKeys = defaultKVMaxKeys
+	}
+	if cfg.Default == "" {
+		cfg.Default = KVMemoryInstance
+	}
+
+	kt := &KVTool{config: cfg, stores: make(map[string]kvStore)}
+	for name, instance := range cfg.Instances {
+		kt.stores[name] = newRedisStore(instance)
+	}
+	if _, exists := kt.stores[KVMemoryInstance]; !exists {
+		kt.stores[KVMemoryInstance] = newMemoryStore(cfg.MaxKeys)
+	}
+	return kt
+}
+
+func (kt *KVTool) Name() string {
+	return "kv"
+}
+
+func (kt *KVTool) Description() string {
+	return "Persist small string values between tool calls with get, set, del, scan and ttl"
+}
+
+func (kt *KVTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (kt *KVTool) Tags() []string {
+	return []string{TagDestructive}
+}
+
+func (kt *KVTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"op":          {Type: schema.TypeString, Description: "Operation", Enum: []interface{}{"get", "set", "del", "scan", "ttl"}},
+		"instance":    {Type: schema.TypeString, Description: "Configured store instance, defaults to the server default"},
+		"key":         {Type: schema.TypeString, Description: "Key, required except for scan", MinLength: schema.Int(1), MaxLength: schema.Int(maxKVKeyLength)},
+		"value":       {Type: schema.TypeString, Description: "Value to store for set"},
+		"ttl_seconds": {Type: schema.TypeInteger, Description: "Expiry for set in seconds, 0 keeps the key until deleted", Minimum: schema.Float(0)},
+		"match":       {Type: schema.TypeString, Description: "Glob pattern for scan", Default: "*"},
+		"cursor":      {Type: schema.TypeString, Description: "Cursor returned by the previous scan", Default: "0"},
+		"count":       {Type: schema.TypeInteger, Description: "Hint for the number of keys per scan", Minimum: schema.Float(1), Maximum: schema.Float(maxKVScanCount)},
+	}, "op").Closed()
+}
+
+func (kt *KVTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var kvArgs KVArgs
+	if err := json.Unmarshal(args, &kvArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+
+	instance := kvArgs.Instance
+	if instance == "" {
+		instance = kt.config.Default
+	}
+	store, exists := kt.stores[instance]
+	if !exists {
+		return nil, fmt.Errorf("unknown kv instance: %s", instance)
+	}
+
+	op := strings.ToLower(kvArgs.Op)
+	if op != "scan" {
+		if kvArgs.Key == "" {
+			return nil, fmt.Errorf("key is required for %s", op)
+		}
+		if len(kvArgs.Key) > maxKVKeyLength {
+			return nil, fmt.Errorf("key exceeds %d bytes", maxKVKeyLength)
+		}
+	}
+
+	result := KVResult{Op: op, Instance: instance, Key: kvArgs.Key}
+	key := kt.config.KeyPrefix + kvArgs.Key
+
+	switch op {
+	case "get":
+		value, found, err := store.Get(ctx, key)
+		if err != nil {
+			return nil, kvError(instance, err)
+		}
+		result.Found = found
+		if found {
+			result.Value = &value
+		}
+
+	case "set":
+		if len(kvArgs.Value) > kt.config.MaxValueBytes {
+			return nil, fmt.Errorf("value exceeds %d bytes", kt.config.MaxValueBytes)
+		}
+		if kvArgs.TTLSeconds < 0 {
+			return nil, fmt.Errorf("ttl_seconds must not be negative")
+		}
+		if err := store.Set(ctx, key, kvArgs.Value, time.Duration(kvArgs.TTLSeconds)*time.Second); err != nil {
+			return nil, kvError(instance, err)
+		}
+		result.Found = true
+
+	case "del":
+		deleted, err := store.Del(ctx, key)
+		if err != nil {
+			return nil, kvError(instance, err)
+		}
+		result.Found = deleted > 0
+		result.Deleted = deleted
+
+	case "ttl":
+		ttl, found, err := store.TTL(ctx, key)
+		if err != nil {
+			return nil, kvError(instance, err)
+		}
+		result.Found = found
+		if found {
+			seconds := int64(-1)
+			if ttl >= 0 {
+				seconds = int64((ttl + time.Second - 1) / time.Second)
+			}
+			result.TTL = &seconds
+		}
+
+	case "scan":
+		cursor := uint64(0)
+		if kvArgs.Cursor != "" {
+			parsed, err := strconv.ParseUint(kvArgs.Cursor, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cursor: %s", kvArgs.Cursor)
+			}
+			cursor = parsed
+		}
+		count := kvArgs.Count
+		if count <= 0 {
+			count = defaultKVScanCount
+		} else if count > maxKVScanCount {
+			count = maxKVScanCount
+		}
+		match := kvArgs.Match
+		if match == "" {
+			match = "*"
+		}
+
+		keys, next, err := store.Scan(ctx, cursor, escapeGlob(kt.config.KeyPrefix)+match, count)
+		if err != nil {
+			return nil, kvError(instance, err)
+		}
+		result.Keys = make([]string, len(keys))
+		for i, k := range keys {
+			result.Keys[i] = strings.TrimPrefix(k, kt.config.KeyPrefix)
+		}
+		result.Found = len(keys) > 0
+		result.Cursor = strconv.FormatUint(next, 10)
+
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", kvArgs.Op)
+	}
+
+	return json.Marshal(result)
+}
+
+// kvError 为存储错误标注实例名
+func kvError(instance string, err error) error {
+	return fmt.Errorf("kv instance %s: %w", instance, err)
+}
+
+// escapeGlob 转义键前缀中的 glob 元字符
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// matchGlob 按 Redis 的 glob 规则匹配：* ? [abc] [a-z] [^a] 与反斜杠转义
+func matchGlob(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchGlob(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 {
+				// 未闭合的 [ 按字面量处理
+				if s[0] != '[' {
+					return false
+				}
+				pattern, s = pattern[1:], s[1:]
+				continue
+			}
+			class := pattern[1 : end+1]
+			negate := strings.HasPrefix(class, "^")
+			if negate {
+				class = class[1:]
+			}
+			matched := false
+			for i := 0; i < len(class); i++ {
+				if i+2 < len(class) && class[i+1] == '-' {
+					if class[i] <= s[0] && s[0] <= class[i+2] {
+						matched = true
+					}
+					i += 2
+				} else if class[i] == s[0] {
+					matched = true
+				}
+			}
+			if matched == negate {
+				return false
+			}
+			pattern, s = pattern[end+2:], s[1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		}
+	}
+	return len(s) == 0
+}
+
+// redisStore Redis 后端
+type redisStore struct {
+	client *redis.Client
+}
+
+func newRedisStore(cfg config.KVInstanceConfig) *redisStore {
+	options := &redis.Options{
+		Addr:     cfg.Addr,
+		Username: cfg.Username,
+		Password: cfg.ResolvePassword(),
+		DB:       cfg.DB,
+	}
+	if cfg.TLS {
+		options.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return &redisStore{client: redis.NewClient(options)}
+}
+
+func (r *redisStore) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := r.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	return value, err == nil, err
+}
+
+func (r *redisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (r *redisStore) Del(ctx context.Context, key string) (int64, error) {
+	return r.client.Del(ctx, key).Result()
+}
+
+func (r *redisStore) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	return r.client.Scan(ctx, cursor, match, count).Result()
+}
+
+func (r *redisStore) TTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	ttl, err := r.client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, false, err
+	}
+	// 键不存在时为 -2，未设置过期时间时为 -1（均不带单位）
+	switch ttl {
+	case -2:
+		return 0, false, nil
+	case -1:
+		return -1, true, nil
+	}
+	return ttl, true, nil
+}
+
+// memoryStore 进程内存储
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	maxKeys int
+}
+
+type memoryEntry struct {
+	value   string
+	expires time.Time // 零值表示不过期
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+func newMemoryStore(maxKeys int) *memoryStore {
+	return &memoryStore{entries: make(map[string]memoryEntry), maxKeys: maxKeys}
+}
+
+// lookup 查找未过期的条目，顺带清除已过期的条目，调用方需持有锁
+func (m *memoryStore) lookup(key string, now time.Time) (memoryEntry, bool) {
+	entry, exists := m.entries[key]
+	if exists && entry.expired(now) {
+		delete(m.entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, exists
+}
+
+func (m *memoryStore) Get(_ context.Context, key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, exists := m.lookup(key, time.Now())
+	return entry.value, exists, nil
+}
+
+func (m *memoryStore) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if _, exists := m.lookup(key, now); !exists && len(m.entries) >= m.maxKeys {
+		for k, entry := range m.entries {
+			if entry.expired(now) {
+				delete(m.entries, k)
+			}
+		}
+		if len(m.entries) >= m.maxKeys {
+			return fmt.Errorf("%w (%d keys)", ErrKVFull, m.maxKeys)
+		}
+	}
+
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expires = now.Add(ttl)
+	}
+	m.entries[key] = entry
+	return nil
+}
+
+func (m *memoryStore) Del(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.lookup(key, time.Now()); !exists {
+		return 0, nil
+	}
+	delete(m.entries, key)
+	return 1, nil
+}
+
+// Scan 游标为有序键列表中的偏移量，遍历期间增删的键可能被遗漏或重复返回，与 Redis 语义一致
+func (m *memoryStore) Scan(_ context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	m.mu.Lock()
+	now := time.Now()
+	all := make([]string, 0, len(m.entries))
+	for key, entry := range m.entries {
+		if !entry.expired(now) {
+			all = append(all, key)
+		}
+	}
+	m.mu.Unlock()
+	sort.Strings(all)
+
+	if cursor >= uint64(len(all)) {
+		return []string{}, 0, nil
+	}
+	end := cursor + uint64(count)
+	if end > uint64(len(all)) {
+		end = uint64(len(all))
+	}
+
+	keys := make([]string, 0, end-cursor)
+	for _, key := range all[cursor:end] {
+		if matchGlob(match, key) {
+			keys = append(keys, key)
+		}
+	}
+	if end == uint64(len(all)) {
+		end = 0
+	}
+	return keys, end, nil
+}
+
+func (m *memoryStore) TTL(_ context.Context, key string) (time.Duration, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	entry, exists := m.lookup(key, now)
+	if !exists {
+		return 0, false, nil
+	}
+	if entry.expires.IsZero() {
+		return -1, true, nil
+	}
+	return entry.expires.Sub(now), true, nil
+}
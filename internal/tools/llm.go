@@ -0,0 +1,236 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/llm"
+	"Weave-Toolkit/internal/schema"
+)
+
+// llm 工具默认限制
+const (
+	defaultLLMMaxTokens      = 4096
+	defaultLLMMaxPromptBytes = 256 << 10
+	llmRequestTimeout        = 5 * time.Minute // 与 llm 包默认客户端的超时一致
+)
+
+// ErrModelNotAllowed 请求的模型不在服务允许的模型列表中
+var ErrModelNotAllowed = werrors.New(werrors.KindInvalidParams, "model not allowed")
+
+// LLMTool 大模型补全工具
+//
+// 将提示或多轮对话转发给配置的大模型服务（OpenAI、Anthropic、Ollama 或 OpenAI 兼容接口），
+// 可选择服务、模型、温度与最大输出长度。流式调用时按服务返回的片段逐段推送输出。
+type LLMTool struct {
+	config config.LLMConfig
+}
+
+// LLMArgs 补全参数，prompt 作为最后一条 user 消息追加到 messages 之后
+type LLMArgs struct {
+	Prompt      string        `json:"prompt"`
+	System      string        `json:"system"`
+	Messages    []llm.Message `json:"messages"`
+	Provider    string        `json:"provider"`
+	Model       string        `json:"model"`
+	Temperature *float64      `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens"`
+}
+
+// LLMResult 补全结果
+type LLMResult struct {
+	Provider     string         `json:"provider"`
+	Model        string         `json:"model"`
+	Content      string         `json:"content"`
+	ToolCalls    []llm.ToolCall `json:"tool_calls,omitempty"` // 请求附带工具定义时模型请求的工具调用
+	FinishReason string         `json:"finish_reason,omitempty"`
+	Usage        llm.Usage      `json:"usage"`
+}
+
+// NewLLMTool 创建大模型补全工具
+func NewLLMTool(cfg config.LLMConfig) *LLMTool {
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = defaultLLMMaxTokens
+	}
+	if cfg.MaxPromptBytes <= 0 {
+		cfg.MaxPromptBytes = defaultLLMMaxPromptBytes
+	}
+	return &LLMTool{config: cfg}
+}
+
+func (lt *LLMTool) Name() string {
+	return "llm"
+}
+
+func (lt *LLMTool) Description() string {
+	return "Send a prompt or conversation to a configured LLM provider (OpenAI, Anthropic, Ollama or OpenAI-compatible) and return the completion"
+}
+
+func (lt *LLMTool) Category() ToolCategory {
+	return CategoryAI
+}
+
+func (lt *LLMTool) Tags() []string {
+	return []string{TagNetwork, TagLLM, TagSlow}
+}
+
+func (lt *LLMTool) InputSchema() *schema.Schema {
+	message := schema.Object(map[string]*schema.Schema{
+		"role":    {Type: schema.TypeString, Enum: []interface{}{"system", "user", "assistant"}},
+		"content": {Type: schema.TypeString},
+	}, "role", "content").Closed()
+
+	return schema.Object(map[string]*schema.Schema{
+		"prompt":      {Type: schema.TypeString, Description: "User prompt, appended after messages", MinLength: schema.Int(1)},
+		"system":      {Type: schema.TypeString, Description: "System prompt"},
+		"messages":    {Type: schema.TypeArray, Description: "Conversation history", Items: message},
+		"provider":    {Type: schema.TypeString, Description: "Configured provider name, defaults to the configured default"},
+		"model":       {Type: schema.TypeString, Description: "Model name, defaults to the provider's model", MinLength: schema.Int(1)},
+		"temperature": {Type: schema.TypeNumber, Description: "Sampling temperature", Minimum: schema.Float(0), Maximum: schema.Float(2)},
+		"max_tokens":  {Type: schema.TypeInteger, Description: "Maximum output tokens", Minimum: schema.Float(1)},
+	}).Closed()
+}
+
+func (lt *LLMTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	return lt.execute(ctx, args, nil)
+}
+
+// ExecuteStream 流式调用，按服务返回的片段推送输出
+func (lt *LLMTool) ExecuteStream(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	return lt.execute(ctx, args, callback)
+}
+
+func (lt *LLMTool) execute(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	var llmArgs LLMArgs
+	if err := json.Unmarshal(args, &llmArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+
+	req := llm.Request{System: llmArgs.System, Model: llmArgs.Model, Temperature: llmArgs.Temperature, MaxTokens: llmArgs.MaxTokens}
+	// system 消息合并到 system 提示中，Anthropic 不接受 system 角色的消息
+	var system []string
+	if req.System != "" {
+		system = append(system, req.System)
+	}
+	for _, message := range llmArgs.Messages {
+		if message.Role == "system" {
+			system = append(system, message.Content)
+			continue
+		}
+		req.Messages = append(req.Messages, message)
+	}
+	req.System = strings.Join(system, "\n\n")
+	if llmArgs.Prompt != "" {
+		req.Messages = append(req.Messages, llm.Message{Role: "user", Content: llmArgs.Prompt})
+	}
+	if len(req.Messages) == 0 {
+		return nil, fmt.Errorf("prompt or messages is required")
+	}
+
+	var onDelta func(string)
+	if callback != nil {
+		index := 0
+		onDelta = func(delta string) {
+			callback(delta, index)
+			index++
+		}
+	}
+	result, err := lt.Complete(ctx, llmArgs.Provider, req, onDelta)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// Complete 使用指定服务（为空时为默认服务）执行补全，onDelta 不为空时以流式方式请求；
+// 其他工具可借此复用 llm 工具的服务配置与限制
+func (lt *LLMTool) Complete(ctx context.Context, providerName string, req llm.Request, onDelta func(delta string)) (*LLMResult, error) {
+	size := len(req.System)
+	for _, message := range req.Messages {
+		size += len(message.Content)
+		for _, call := range message.ToolCalls {
+			size += len(call.Arguments)
+		}
+	}
+	if size > lt.config.MaxPromptBytes {
+		return nil, fmt.Errorf("prompt exceeds %d bytes", lt.config.MaxPromptBytes)
+	}
+	if req.MaxTokens > lt.config.MaxTokens {
+		return nil, fmt.Errorf("max_tokens exceeds limit %d", lt.config.MaxTokens)
+	}
+
+	name, providerCfg, err := selectProvider("llm", lt.config.Providers, lt.config.Default, providerName)
+	if err != nil {
+		return nil, err
+	}
+	if req.Model == "" {
+		req.Model = providerCfg.Model
+	}
+	if req.Model == "" {
+		return nil, fmt.Errorf("model is required, provider %s has no default model", name)
+	}
+	if len(providerCfg.Models) > 0 && req.Model != providerCfg.Model && !slices.Contains(providerCfg.Models, req.Model) {
+		return nil, fmt.Errorf("%w: %s on provider %s", ErrModelNotAllowed, req.Model, name)
+	}
+
+	provider, err := llm.NewProvider(llm.Options{Type: providerCfg.Type, URL: providerCfg.URL, APIKey: providerCfg.ResolveAPIKey(), Client: HTTPClient(ctx, llmRequestTimeout)})
+	if err != nil {
+		return nil, fmt.Errorf("provider %s: %v", name, err)
+	}
+	var response *llm.Response
+	err = callUpstream(ctx, "llm:"+name, func() (err error) {
+		if onDelta != nil {
+			response, err = provider.Stream(ctx, req, onDelta)
+		} else {
+			response, err = provider.Complete(ctx, req)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &LLMResult{
+		Provider:     name,
+		Model:        response.Model,
+		Content:      response.Content,
+		ToolCalls:    response.ToolCalls,
+		FinishReason: response.FinishReason,
+		Usage:        response.Usage,
+	}, nil
+}
+
+// selectProvider 按名称选择服务，未指定时使用默认服务或唯一配置的服务；kind 用于错误信息
+func selectProvider(kind string, providers map[string]config.LLMProviderConfig, defaultName, name string) (string, config.LLMProviderConfig, error) {
+	if name == "" {
+		name = defaultName
+	}
+	if name == "" {
+		switch len(providers) {
+		case 0:
+			return "", config.LLMProviderConfig{}, fmt.Errorf("no %s provider configured", kind)
+		case 1:
+			for providerName := range providers {
+				name = providerName
+			}
+		default:
+			names := make([]string, 0, len(providers))
+			for providerName := range providers {
+				names = append(names, providerName)
+			}
+			sort.Strings(names)
+			return "", config.LLMProviderConfig{}, fmt.Errorf("provider is required, available: %s", strings.Join(names, ", "))
+		}
+	}
+	cfg, ok := providers[name]
+	if !ok {
+		return "", config.LLMProviderConfig{}, fmt.Errorf("unknown %s provider: %s", kind, name)
+	}
+	return name, cfg, nil
+}
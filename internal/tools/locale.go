@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// DefaultLocale 未指定区域设置时使用的默认值
+var DefaultLocale = language.AmericanEnglish
+
+// numberFractionDigits 格式化小数时保留的最大小数位数
+const numberFractionDigits = 6
+
+// dateLayouts 日期与日期时间布局
+type dateLayouts struct {
+	date     string
+	dateTime string
+}
+
+// localeDateLayouts 支持的日期格式，未列出的语言按最接近的匹配，默认美式格式
+var localeDateLayouts = []struct {
+	tag     language.Tag
+	layouts dateLayouts
+}{
+	{language.AmericanEnglish, dateLayouts{"Jan 2, 2006", "Jan 2, 2006, 3:04 PM"}},
+	{language.BritishEnglish, dateLayouts{"2 Jan 2006", "2 Jan 2006, 15:04"}},
+	{language.German, dateLayouts{"02.01.2006", "02.01.2006, 15:04"}},
+	{language.French, dateLayouts{"02/01/2006", "02/01/2006 15:04"}},
+	{language.Spanish, dateLayouts{"2/1/2006", "2/1/2006, 15:04"}},
+	{language.Italian, dateLayouts{"02/01/2006", "02/01/2006, 15:04"}},
+	{language.BrazilianPortuguese, dateLayouts{"02/01/2006", "02/01/2006, 15:04"}},
+	{language.Russian, dateLayouts{"02.01.2006", "02.01.2006, 15:04"}},
+	{language.SimplifiedChinese, dateLayouts{"2006年1月2日", "2006年1月2日 15:04"}},
+	{language.TraditionalChinese, dateLayouts{"2006年1月2日", "2006年1月2日 15:04"}},
+	{language.Japanese, dateLayouts{"2006年1月2日", "2006/01/02 15:04"}},
+	{language.Korean, dateLayouts{"2006. 1. 2.", "2006. 1. 2. 15:04"}},
+}
+
+var dateMatcher = func() language.Matcher {
+	tags := make([]language.Tag, len(localeDateLayouts))
+	for i, entry := range localeDateLayouts {
+		tags[i] = entry.tag
+	}
+	return language.NewMatcher(tags)
+}()
+
+// localeContextKey 区域设置上下文键
+type localeContextKey struct{}
+
+// ParseLocale 解析 BCP 47 语言标签（如 de-DE），也接受 Accept-Language 格式取首选项
+func ParseLocale(locale string) (language.Tag, bool) {
+	if locale == "" {
+		return language.Und, false
+	}
+	if tag, err := language.Parse(locale); err == nil {
+		return tag, true
+	}
+	if tags, _, err := language.ParseAcceptLanguage(locale); err == nil && len(tags) > 0 {
+		return tags[0], true
+	}
+	return language.Und, false
+}
+
+// WithLocale 在上下文中记录客户端区域设置，无法解析时保持原上下文
+func WithLocale(ctx context.Context, locale string) context.Context {
+	tag, ok := ParseLocale(locale)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, localeContextKey{}, tag)
+}
+
+// LocaleFromContext 从上下文中获取区域设置，未设置时返回 DefaultLocale
+func LocaleFromContext(ctx context.Context) language.Tag {
+	if tag, ok := contextLocale(ctx); ok {
+		return tag
+	}
+	return DefaultLocale
+}
+
+// contextLocale 获取客户端显式指定的区域设置
+func contextLocale(ctx context.Context) (language.Tag, bool) {
+	tag, ok := ctx.Value(localeContextKey{}).(language.Tag)
+	return tag, ok
+}
+
+// Formatter 按区域设置格式化数字与日期
+type Formatter struct {
+	tag     language.Tag
+	printer *message.Printer
+	layouts dateLayouts
+}
+
+// NewFormatter 创建指定区域设置的格式化器
+func NewFormatter(tag language.Tag) *Formatter {
+	_, index, _ := dateMatcher.Match(tag)
+	return &Formatter{
+		tag:     tag,
+		printer: message.NewPrinter(tag),
+		layouts: localeDateLayouts[index].layouts,
+	}
+}
+
+// FormatterFromContext 按上下文中的区域设置创建格式化器
+func FormatterFromContext(ctx context.Context) *Formatter {
+	return NewFormatter(LocaleFromContext(ctx))
+}
+
+// Locale 格式化器使用的语言标签
+func (f *Formatter) Locale() string {
+	return f.tag.String()
+}
+
+// Number 格式化数字，使用本地的千分位与小数分隔符
+func (f *Formatter) Number(v float64) string {
+	return f.printer.Sprint(number.Decimal(v, number.MaxFractionDigits(numberFractionDigits)))
+}
+
+// Integer 格式化整数
+func (f *Formatter) Integer(v int) string {
+	return f.printer.Sprint(number.Decimal(v))
+}
+
+// Date 格式化日期
+func (f *Formatter) Date(t time.Time) string {
+	return t.Format(f.layouts.date)
+}
+
+// DateTime 格式化日期与时间
+func (f *Formatter) DateTime(t time.Time) string {
+	return t.Format(f.layouts.dateTime)
+}
@@ -1,444 +1,1139 @@
-package tools
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"sync"
-	"time"
-
-	"Weave-Toolkit/config"
-	"Weave-Toolkit/internal/logger"
-)
-
-// ToolCategory 工具分类
-type ToolCategory string
-
-const (
-	CategoryMath    ToolCategory = "math"    // 计算工具
-	CategoryAI      ToolCategory = "ai"      // AI工具
-	CategorySystem  ToolCategory = "system"  // 系统工具
-	CategoryUtility ToolCategory = "utility" // 实用工具
-)
-
-// ToolManager MCP 工具管理器
-type ToolManager struct {
-	categories map[ToolCategory]*CategoryManager
-	mu         sync.RWMutex
-	logger     *logger.Logger
-}
-
-// CategoryManager 分类管理器
-type CategoryManager struct {
-	Name    ToolCategory
-	tools   map[string]Tool
-	enabled bool
-	config  CategoryConfig
-}
-
-// CategoryConfig 分类配置
-type CategoryConfig struct {
-	Enabled   bool          `json:"enabled"`
-	MaxTools  int           `json:"max_tools"`
-	RateLimit int           `json:"rate_limit"`
-	Timeout   time.Duration `json:"timeout"`
-}
-
-// Tool 工具接口
-type Tool interface {
-	Name() string
-	Description() string
-	Category() ToolCategory
-	Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error)
-}
-
-// StreamTool 流式工具接口
-type StreamTool interface {
-	Tool
-	ExecuteStream(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error)
-}
-
-// StreamCallback 流式回调函数类型
-type StreamCallback func(content string, index int)
-
-// ToolInfo 工具信息结构
-type ToolInfo struct {
-	Name        string       `json:"name"`
-	Description string       `json:"description"`
-	Category    ToolCategory `json:"category"`
-	Enabled     bool         `json:"enabled"`
-}
-
-// ToolCallResult 工具调用结果
-type ToolCallResult struct {
-	Content []ToolCallContent `json:"content"`
-}
-
-// ToolCallContent 工具调用内容
-type ToolCallContent struct {
-	Type string      `json:"type"`
-	Text string      `json:"text"`
-	Data interface{} `json:"data,omitempty"`
-}
-
-// NewToolManager 创建新的工具管理器
-func NewToolManager(logger *logger.Logger, toolConfig *config.ToolManagerConfig) *ToolManager {
-	tm := &ToolManager{
-		categories: make(map[ToolCategory]*CategoryManager),
-		logger:     logger,
-	}
-
-	// 使用配置初始化分类
-	tm.initCategoriesFromConfig(toolConfig)
-
-	return tm
-}
-
-// initCategoriesFromConfig 从配置初始化分类
-func (tm *ToolManager) initCategoriesFromConfig(toolConfig *config.ToolManagerConfig) {
-	categoryMapping := map[string]ToolCategory{
-		"math":    CategoryMath,
-		"ai":      CategoryAI,
-		"system":  CategorySystem,
-		"utility": CategoryUtility,
-	}
-
-	for configCategory, configData := range toolConfig.Categories {
-		category, exists := categoryMapping[configCategory]
-		if !exists {
-			// 未知分类跳过
-			tm.logger.Warn().Str("category", configCategory).Msg("Unknown category in config, skipping")
-			continue
-		}
-
-		tm.categories[category] = &CategoryManager{
-			Name:    category,
-			tools:   make(map[string]Tool),
-			enabled: configData.Enabled,
-			config: CategoryConfig{
-				Enabled:   configData.Enabled,
-				MaxTools:  configData.MaxTools,
-				RateLimit: configData.RateLimit,
-				Timeout:   configData.Timeout,
-			},
-		}
-	}
-
-	// 确保所有预定义分类都有管理器
-	for _, category := range []ToolCategory{CategoryMath, CategoryAI, CategorySystem, CategoryUtility} {
-		if _, exists := tm.categories[category]; !exists {
-			tm.categories[category] = &CategoryManager{
-				Name:    category,
-				tools:   make(map[string]Tool),
-				enabled: false, // 默认禁用
-				config:  CategoryConfig{},
-			}
-		}
-	}
-}
-
-// RegisterTool 注册工具到指定分类
-func (tm *ToolManager) RegisterTool(tool Tool) error {
-	tm.mu.Lock()
-	defer tm.mu.Unlock()
-
-	category := tool.Category()
-	categoryMgr, exists := tm.categories[category]
-	if !exists {
-		return fmt.Errorf("category not found: %s", category)
-	}
-
-	if !categoryMgr.enabled {
-		return fmt.Errorf("category is disabled: %s", category)
-	}
-
-	if len(categoryMgr.tools) >= categoryMgr.config.MaxTools {
-		return fmt.Errorf("category %s reached maximum tools limit: %d", category, categoryMgr.config.MaxTools)
-	}
-
-	categoryMgr.tools[tool.Name()] = tool
-	tm.logger.Info().
-		Str("tool", tool.Name()).
-		Str("category", string(category)).
-		Msg("Tool registered")
-
-	return nil
-}
-
-// RegisterAllTools 注册所有可用工具
-func (tm *ToolManager) RegisterAllTools() {
-	tm.RegisterTool(&CalculatorTool{})
-	tm.RegisterTool(&StreamTextProcessor{})
-	// 添加更多工具
-}
-
-// EnableCategory 启用分类
-func (tm *ToolManager) EnableCategory(category ToolCategory) error {
-	tm.mu.Lock()
-	defer tm.mu.Unlock()
-
-	categoryMgr, exists := tm.categories[category]
-	if !exists {
-		return fmt.Errorf("category not found: %s", category)
-	}
-
-	categoryMgr.enabled = true
-	tm.logger.Info().Str("category", string(category)).Msg("Category enabled")
-	return nil
-}
-
-// DisableCategory 禁用分类
-func (tm *ToolManager) DisableCategory(category ToolCategory) error {
-	tm.mu.Lock()
-	defer tm.mu.Unlock()
-
-	categoryMgr, exists := tm.categories[category]
-	if !exists {
-		return fmt.Errorf("category not found: %s", category)
-	}
-
-	categoryMgr.enabled = false
-	tm.logger.Info().Str("category", string(category)).Msg("Category disabled")
-	return nil
-}
-
-// UpdateCategoryConfig 更新分类配置
-func (tm *ToolManager) UpdateCategoryConfig(category ToolCategory, config CategoryConfig) error {
-	tm.mu.Lock()
-	defer tm.mu.Unlock()
-
-	categoryMgr, exists := tm.categories[category]
-	if !exists {
-		return fmt.Errorf("category not found: %s", category)
-	}
-
-	categoryMgr.config = config
-	tm.logger.Info().
-		Str("category", string(category)).
-		Interface("config", config).
-		Msg("Category config updated")
-
-	return nil
-}
-
-// GetTools 获取所有工具信息
-func (tm *ToolManager) GetTools() []ToolInfo {
-	tm.mu.RLock()
-	defer tm.mu.RUnlock()
-
-	var tools []ToolInfo
-	for _, categoryMgr := range tm.categories {
-		if !categoryMgr.enabled {
-			continue
-		}
-
-		for _, tool := range categoryMgr.tools {
-			tools = append(tools, ToolInfo{
-				Name:        tool.Name(),
-				Description: tool.Description(),
-				Category:    tool.Category(),
-				Enabled:     true,
-			})
-		}
-	}
-
-	return tools
-}
-
-// GetToolsByCategory 按分类获取工具信息
-func (tm *ToolManager) GetToolsByCategory(category ToolCategory) []ToolInfo {
-	tm.mu.RLock()
-	defer tm.mu.RUnlock()
-
-	categoryMgr, exists := tm.categories[category]
-	if !exists || !categoryMgr.enabled {
-		return []ToolInfo{}
-	}
-
-	var tools []ToolInfo
-	for _, tool := range categoryMgr.tools {
-		tools = append(tools, ToolInfo{
-			Name:        tool.Name(),
-			Description: tool.Description(),
-			Category:    category,
-			Enabled:     true,
-		})
-	}
-
-	return tools
-}
-
-// GetCategories 获取所有分类信息
-func (tm *ToolManager) GetCategories() map[ToolCategory]CategoryConfig {
-	tm.mu.RLock()
-	defer tm.mu.RUnlock()
-
-	categories := make(map[ToolCategory]CategoryConfig)
-	for category, categoryMgr := range tm.categories {
-		categories[category] = categoryMgr.config
-	}
-
-	return categories
-}
-
-// CallTool 调用工具
-func (tm *ToolManager) CallTool(ctx context.Context, name string, args json.RawMessage) (*ToolCallResult, error) {
-	startTime := time.Now()
-
-	tm.mu.RLock()
-	defer tm.mu.RUnlock()
-
-	// 在所有分类中查找工具
-	var tool Tool
-	var category ToolCategory
-
-	for cat, categoryMgr := range tm.categories {
-		if !categoryMgr.enabled {
-			continue
-		}
-
-		if t, exists := categoryMgr.tools[name]; exists {
-			tool = t
-			category = cat
-			break
-		}
-	}
-
-	if tool == nil {
-		tm.logger.Error().Str("tool", name).Msg("Tool not found")
-		return nil, fmt.Errorf("tool not found: %s", name)
-	}
-
-	// 应用分类级别的超时设置
-	categoryMgr := tm.categories[category]
-	if categoryMgr.config.Timeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, categoryMgr.config.Timeout)
-		defer cancel()
-	}
-
-	// 记录工具调用开始
-	tm.logger.Info().
-		Str("tool", name).
-		Str("category", string(category)).
-		RawJSON("args", args).
-		Msg("Tool call started")
-
-	result, err := tool.Execute(ctx, args)
-	duration := time.Since(startTime)
-
-	// 记录工具调用结果
-	if err != nil {
-		tm.logger.Error().
-			Str("tool", name).
-			Str("category", string(category)).
-			Dur("duration", duration).
-			Err(err).
-			Msg("Tool call failed")
-	} else {
-		tm.logger.Info().
-			Str("tool", name).
-			Str("category", string(category)).
-			Dur("duration", duration).
-			Msg("Tool call completed successfully")
-	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	// MCP 兼容格式
-	return &ToolCallResult{
-		Content: []ToolCallContent{
-			{
-				Type: "text",
-				Text: string(result),
-			},
-		},
-	}, nil
-}
-
-// CallToolStream 流式调用工具
-func (tm *ToolManager) CallToolStream(ctx context.Context, name string, args json.RawMessage, callback StreamCallback) (*ToolCallResult, error) {
-	startTime := time.Now()
-
-	tm.mu.RLock()
-	defer tm.mu.RUnlock()
-
-	// 在所有分类中查找工具
-	var tool Tool
-	var category ToolCategory
-
-	for cat, categoryMgr := range tm.categories {
-		if !categoryMgr.enabled {
-			continue
-		}
-
-		if t, exists := categoryMgr.tools[name]; exists {
-			tool = t
-			category = cat
-			break
-		}
-	}
-
-	if tool == nil {
-		tm.logger.Error().Str("tool", name).Msg("Tool not found")
-		return nil, fmt.Errorf("tool not found: %s", name)
-	}
-
-	// 检查工具是否支持流式调用
-	streamTool, supportsStream := tool.(StreamTool)
-	if !supportsStream {
-		tm.logger.Warn().Str("tool", name).Msg("Tool does not support streaming, returning regular execution result")
-		// 若不支持流式，返回普通调用结果
-		return tm.CallTool(ctx, name, args)
-	}
-
-	// 应用分类级别的超时设置
-	categoryMgr := tm.categories[category]
-	if categoryMgr.config.Timeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, categoryMgr.config.Timeout)
-		defer cancel()
-	}
-
-	// 记录流式工具调用开始
-	tm.logger.Info().
-		Str("tool", name).
-		Str("category", string(category)).
-		RawJSON("args", args).
-		Msg("Stream tool call started")
-
-	result, err := streamTool.ExecuteStream(ctx, args, callback)
-	duration := time.Since(startTime)
-
-	// 记录流式工具调用结果
-	if err != nil {
-		tm.logger.Error().
-			Str("tool", name).
-			Str("category", string(category)).
-			Dur("duration", duration).
-			Err(err).
-			Msg("Stream tool call failed")
-	} else {
-		tm.logger.Info().
-			Str("tool", name).
-			Str("category", string(category)).
-			Dur("duration", duration).
-			Msg("Stream tool call completed successfully")
-	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	// MCP 兼容格式
-	return &ToolCallResult{
-		Content: []ToolCallContent{
-			{
-				Type: "text",
-				Text: string(result),
-			},
-		},
-	}, nil
-}
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/envelope"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/internal/schema"
+)
+
+// ToolCategory 工具分类
+type ToolCategory string
+
+const (
+	CategoryMath    ToolCategory = "math"    // 计算工具
+	CategoryAI      ToolCategory = "ai"      // AI工具
+	CategorySystem  ToolCategory = "system"  // 系统工具
+	CategoryUtility ToolCategory = "utility" // 实用工具
+)
+
+// ToolManager MCP 工具管理器
+type ToolManager struct {
+	categories map[ToolCategory]*CategoryManager
+	disabled   map[string]bool // 单独禁用的工具
+	aliases    *aliasTable
+	observers  []CallObserver
+	pool       *WorkerPool
+	toolConfig *config.ToolManagerConfig
+	workspaces *WorkspaceManager // 为空时不提供工作区
+	decrypter  ArgumentDecrypter // 为空时拒绝加密参数
+	limiters   tenantLimiters
+	shared     RateLimiter // 集群共享限流器，为空时使用本地限流器
+	breakers   *CircuitBreakers
+	stats      *CallStats
+	// 超时配置：toolTimeouts 为单个工具的覆盖值，defaultTimeout 为 global.default_timeout，
+	// fallbackTimeout 为服务器配置的 MCP_TOOL_TIMEOUT
+	toolTimeouts    map[string]time.Duration
+	defaultTimeout  time.Duration
+	fallbackTimeout time.Duration
+	streamChunkSize int // 非流式工具在流式调用中推送结果的片段大小，不大于 0 时不拆分
+	// 结果大小上限：resultLimits 为单个工具的覆盖值，defaultResultLimit 为 global.max_result_bytes
+	resultLimits       map[string]int
+	defaultResultLimit int
+	results            *ResultStore                // 被截断结果的完整内容
+	blobs              *BlobStore                  // 上传文件存储，为空时不接受上传
+	http               *HTTPClients                // 工具访问外部服务共用的客户端
+	catalog            []Tool                      // RegisterAllTools 注册的工具，为 nil 时使用内置工具
+	tags               map[string][]string         // tool_tags 配置为工具追加的标签
+	approvals          config.ApprovalConfig       // 需要人工审批的工具
+	redactor           *Redactor                   // 写入日志与调用记录前的脱敏规则
+	piiFilter          *PIIFilter                  // 返回给客户端前的个人信息检测，未启用时为空
+	policyEngine       *PolicyEngine               // 内容安全策略，未配置时为空
+	audit              *policyAudit                // 被内容安全策略拒绝的调用
+	opa                *OPAAuthorizer              // OPA 授权查询，未配置时为空
+	strictOutput       bool                        // 开发模式：结果不符合输出 Schema 时调用失败
+	providers          map[string]ResourceProvider // 按 scheme 注册的资源提供者
+	providerCatalog    []ResourceProvider          // RegisterAllTools 注册的资源提供者，为 nil 时使用内置提供者
+	mu                 sync.RWMutex
+	logger             *logger.Logger
+}
+
+// CategoryManager 分类管理器
+type CategoryManager struct {
+	Name    ToolCategory
+	tools   map[string]Tool
+	enabled bool
+	config  CategoryConfig
+}
+
+// CategoryConfig 分类配置
+type CategoryConfig struct {
+	Enabled   bool          `json:"enabled"`
+	MaxTools  int           `json:"max_tools"`
+	RateLimit int           `json:"rate_limit"`
+	Timeout   time.Duration `json:"timeout"`
+}
+
+// Tool 工具接口
+type Tool interface {
+	Name() string
+	Description() string
+	Category() ToolCategory
+	Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error)
+}
+
+// StreamTool 以回调推送片段的流式工具接口，回调序号由工具维护；新工具建议实现 EmitterTool
+type StreamTool interface {
+	Tool
+	ExecuteStream(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error)
+}
+
+// SchemaTool 声明参数 JSON Schema 的工具，调用前按 Schema 校验参数
+type SchemaTool interface {
+	Tool
+	InputSchema() *schema.Schema
+}
+
+// ContentTool 自定义调用结果内容的工具，例如返回图片；未实现的工具结果作为单个文本内容返回
+type ContentTool interface {
+	Tool
+	ResultContent(result json.RawMessage) ([]ToolCallContent, error)
+}
+
+// ResourceTool 向所有客户端公开资源的工具，区别于仅对发起调用的会话可见的 PublishResource
+type ResourceTool interface {
+	Tool
+	// Resources 列出工具当前公开的资源
+	Resources() []ToolResource
+	// ReadResource 读取资源，URI 不属于该工具时 ok 为 false
+	ReadResource(uri string) (content, mimeType string, ok bool, err error)
+}
+
+// ToolResource 工具公开的资源
+type ToolResource struct {
+	URI         string
+	Name        string
+	MimeType    string
+	Description string
+}
+
+// ResourceTemplateProvider 以参数化 URI 模板（如 logs://{date}、db://{table}/rows）公开资源的工具，
+// 适用于无法逐一列出的资源；模板语法见 uritemplate 包
+type ResourceTemplateProvider interface {
+	Tool
+	// ResourceTemplates 列出工具公开的资源模板
+	ResourceTemplates() []ResourceTemplate
+	// ReadTemplate 读取与模板 uriTemplate 匹配的资源，vars 为从 URI 中解析出的变量
+	ReadTemplate(ctx context.Context, uriTemplate string, vars map[string]string) (content, mimeType string, err error)
+}
+
+// ResourceTemplate 工具公开的资源模板
+type ResourceTemplate struct {
+	URITemplate string
+	Name        string
+	MimeType    string
+	Description string
+}
+
+// StreamCallback 流式回调函数类型
+type StreamCallback func(content string, index int)
+
+// ToolInfo 工具信息结构
+type ToolInfo struct {
+	Name         string         `json:"name"`
+	Description  string         `json:"description"`
+	Category     ToolCategory   `json:"category"`
+	Enabled      bool           `json:"enabled"`
+	InputSchema  *schema.Schema `json:"inputSchema,omitempty"`
+	OutputSchema *schema.Schema `json:"outputSchema,omitempty"` // 结果的 Schema，声明时结果同时以 structuredContent 返回
+	Tags         []string       `json:"tags,omitempty"`         // 能力标签，包含 tool_tags 配置追加的标签
+}
+
+// ErrInvalidArguments 工具参数不符合其声明的 Schema
+var ErrInvalidArguments = werrors.New(werrors.KindInvalidParams, "invalid arguments")
+
+// ErrToolNotFound 工具不存在、已禁用或对当前租户不可见
+var ErrToolNotFound = werrors.New(werrors.KindNotFound, "tool not found")
+
+// ToolCallResult 工具调用结果
+type ToolCallResult struct {
+	Content           []ToolCallContent `json:"content"`
+	StructuredContent json.RawMessage   `json:"structuredContent,omitempty"` // 声明了输出 Schema 的工具的结构化结果
+	IsError           bool              `json:"isError,omitempty"`           // 工具执行出错，内容为错误说明
+	Meta              *ResultMeta       `json:"_meta,omitempty"`             // 截断位置与读取其余内容的资源、检测到的个人信息与策略注释
+}
+
+// ErrToolPanic 工具执行过程中发生 panic
+var ErrToolPanic = errors.New("tool panicked")
+
+// ToolCallContent 工具调用内容
+type ToolCallContent struct {
+	Type     string      `json:"type"` // text, image
+	Text     string      `json:"text,omitempty"`
+	Data     interface{} `json:"data,omitempty"`     // image 内容为 base64 编码的数据
+	MimeType string      `json:"mimeType,omitempty"` // image 内容的 MIME 类型
+}
+
+// NewToolManager 创建新的工具管理器
+func NewToolManager(logger *logger.Logger, toolConfig *config.ToolManagerConfig) *ToolManager {
+	tm := &ToolManager{
+		categories:         make(map[ToolCategory]*CategoryManager),
+		disabled:           make(map[string]bool),
+		limiters:           tenantLimiters{limiters: make(map[string]*tenantLimiter)},
+		aliases:            newAliasTable(toolConfig.Aliases, toolConfig.ClientAliases, toolConfig.Namespaces),
+		pool:               NewWorkerPool(toolConfig.Global.MaxConcurrentCalls),
+		breakers:           NewCircuitBreakers(toolConfig.Global.CircuitBreaker),
+		stats:              NewCallStats(),
+		toolTimeouts:       toolTimeouts(toolConfig),
+		tags:               toolConfig.ToolTags,
+		approvals:          toolConfig.Approvals,
+		redactor:           NewRedactor(toolConfig.Redaction),
+		piiFilter:          NewPIIFilter(toolConfig.PII),
+		policyEngine:       NewPolicyEngine(toolConfig.Policies),
+		audit:              &policyAudit{},
+		opa:                NewOPAAuthorizer(toolConfig.OPA),
+		defaultTimeout:     time.Duration(toolConfig.Global.DefaultTimeout) * time.Second,
+		streamChunkSize:    DefaultStreamChunkSize,
+		resultLimits:       resultLimits(toolConfig),
+		defaultResultLimit: toolConfig.Global.MaxResultBytes,
+		results:            NewResultStore(time.Duration(toolConfig.Global.ResultRetention)*time.Second, toolConfig.Global.ResultStoreBytes),
+		toolConfig:         toolConfig,
+		logger:             logger,
+	}
+
+	// 熔断器状态经健康检查公开，只保存脱敏后的错误文本
+	tm.breakers.SetErrorText(tm.PublicErrorText)
+
+	// 使用配置初始化分类
+	tm.initCategoriesFromConfig(toolConfig)
+	tm.http = tm.newHTTPClients(toolConfig.HTTPClient)
+
+	workspaces, err := NewWorkspaceManager(toolConfig.Global.WorkspaceDir, toolConfig.Global.WorkspaceMaxBytes)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Tool workspaces disabled")
+	} else {
+		tm.workspaces = workspaces
+	}
+
+	if !toolConfig.Resources.Blobs.Disabled {
+		blobs, err := NewBlobStore(toolConfig.Resources.Blobs)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Blob uploads disabled")
+		} else {
+			tm.blobs = blobs
+		}
+	}
+
+	return tm
+}
+
+// initCategoriesFromConfig 从配置初始化分类
+func (tm *ToolManager) initCategoriesFromConfig(toolConfig *config.ToolManagerConfig) {
+	categoryMapping := map[string]ToolCategory{
+		"math":    CategoryMath,
+		"ai":      CategoryAI,
+		"system":  CategorySystem,
+		"utility": CategoryUtility,
+	}
+
+	for configCategory, configData := range toolConfig.Categories {
+		category, exists := categoryMapping[configCategory]
+		if !exists {
+			// 未知分类跳过
+			tm.logger.Warn().Str("category", configCategory).Msg("Unknown category in config, skipping")
+			continue
+		}
+
+		tm.categories[category] = &CategoryManager{
+			Name:    category,
+			tools:   make(map[string]Tool),
+			enabled: configData.Enabled,
+			config: CategoryConfig{
+				Enabled:   configData.Enabled,
+				MaxTools:  configData.MaxTools,
+				RateLimit: configData.RateLimit,
+				Timeout:   time.Duration(configData.Timeout) * time.Second,
+			},
+		}
+	}
+
+	// 确保所有预定义分类都有管理器
+	for _, category := range []ToolCategory{CategoryMath, CategoryAI, CategorySystem, CategoryUtility} {
+		if _, exists := tm.categories[category]; !exists {
+			tm.categories[category] = &CategoryManager{
+				Name:    category,
+				tools:   make(map[string]Tool),
+				enabled: false, // 默认禁用
+				config:  CategoryConfig{},
+			}
+		}
+	}
+}
+
+// RegisterTool 注册工具到指定分类
+func (tm *ToolManager) RegisterTool(tool Tool) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	category := tool.Category()
+	categoryMgr, exists := tm.categories[category]
+	if !exists {
+		return werrors.NotFound("category not found: %s", category)
+	}
+
+	if !categoryMgr.enabled {
+		return fmt.Errorf("category is disabled: %s", category)
+	}
+
+	if err := tm.checkToolNameLocked(tool.Name(), category); err != nil {
+		return err
+	}
+
+	if _, registered := categoryMgr.tools[tool.Name()]; !registered && len(categoryMgr.tools) >= categoryMgr.config.MaxTools {
+		return fmt.Errorf("category %s reached maximum tools limit: %d", category, categoryMgr.config.MaxTools)
+	}
+
+	categoryMgr.tools[tool.Name()] = tool
+	tm.logger.Info().
+		Str("tool", tool.Name()).
+		Str("category", string(category)).
+		Msg("Tool registered")
+
+	return nil
+}
+
+// BuiltinTools 按工具配置创建所有内置工具
+func BuiltinTools(toolConfig *config.ToolManagerConfig) []Tool {
+	fetch := NewHTTPFetchTool(toolConfig.HTTPFetch)
+	llmTool := NewLLMTool(toolConfig.LLM)
+	embeddings := NewEmbeddingsTool(toolConfig.Embeddings)
+	ragIngest, ragQuery := NewRAGTools(toolConfig.RAG, embeddings, llmTool)
+	return []Tool{
+		&CalculatorTool{},
+		NewStreamTextProcessor(toolConfig.StreamText),
+		fetch,
+		NewKVTool(toolConfig.KV),
+		NewK8sTool(toolConfig.K8s),
+		&JSONTransformTool{},
+		NewCryptoTool(toolConfig.Crypto),
+		NewChartTool(toolConfig.Chart),
+		NewTranslateTool(toolConfig.Translate),
+		llmTool,
+		embeddings,
+		NewVectorSearchTool(toolConfig.VectorSearch, embeddings),
+		ragIngest,
+		ragQuery,
+		NewSummarizeTool(toolConfig.Summarize, llmTool),
+		&ChunkTextTool{},
+		NewNotifyTool(toolConfig.Notify),
+		NewScrapeTool(toolConfig.Scrape, fetch),
+		NewBrowserTool(toolConfig.Browser, fetch),
+		NewSysinfoTool(toolConfig.Sysinfo),
+		NewArchiveTool(toolConfig.Archive),
+		NewValidateTool(toolConfig.Validate),
+		NewCodefmtTool(toolConfig.Codefmt),
+		NewIssuesTool(toolConfig.Issues),
+		NewQRTool(toolConfig.QR),
+		NewSecurityTool(toolConfig.Security),
+		// 添加更多工具
+	}
+}
+
+// UseTools 以指定工具取代内置工具，此后 RegisterAllTools 与重新加载配置只注册这些工具
+func (tm *ToolManager) UseTools(tools ...Tool) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.catalog = tools
+}
+
+// RegisterAllTools 注册所有可用工具
+func (tm *ToolManager) RegisterAllTools() {
+	tm.mu.RLock()
+	catalog := tm.catalog
+	tm.mu.RUnlock()
+	if catalog == nil {
+		catalog = BuiltinTools(tm.toolConfig)
+	}
+
+	for _, tool := range catalog {
+		if err := tm.RegisterTool(tool); err != nil && isToolNameConflict(err) {
+			tm.logger.Warn().Str("tool", tool.Name()).Err(err).Msg("Tool not registered")
+		}
+	}
+	tm.registerResourceProviders()
+
+	tm.mu.Lock()
+	tm.validateAliases()
+	tm.mu.Unlock()
+}
+
+// EnableCategory 启用分类
+func (tm *ToolManager) EnableCategory(category ToolCategory) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	categoryMgr, exists := tm.categories[category]
+	if !exists {
+		return werrors.NotFound("category not found: %s", category)
+	}
+
+	categoryMgr.enabled = true
+	tm.logger.Info().Str("category", string(category)).Msg("Category enabled")
+	return nil
+}
+
+// DisableCategory 禁用分类
+func (tm *ToolManager) DisableCategory(category ToolCategory) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	categoryMgr, exists := tm.categories[category]
+	if !exists {
+		return werrors.NotFound("category not found: %s", category)
+	}
+
+	categoryMgr.enabled = false
+	tm.logger.Info().Str("category", string(category)).Msg("Category disabled")
+	return nil
+}
+
+// EnableTool 启用单个工具
+func (tm *ToolManager) EnableTool(name string) error {
+	return tm.setToolEnabled(name, true)
+}
+
+// DisableTool 禁用单个工具，禁用后不出现在工具列表中且无法调用
+func (tm *ToolManager) DisableTool(name string) error {
+	return tm.setToolEnabled(name, false)
+}
+
+// setToolEnabled 设置单个工具的启用状态
+func (tm *ToolManager) setToolEnabled(name string, enabled bool) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if tm.findToolLocked(name) == nil {
+		return fmt.Errorf("%w: %s", ErrToolNotFound, name)
+	}
+
+	if enabled {
+		delete(tm.disabled, name)
+		tm.logger.Info().Str("tool", name).Msg("Tool enabled")
+	} else {
+		tm.disabled[name] = true
+		tm.logger.Info().Str("tool", name).Msg("Tool disabled")
+	}
+	return nil
+}
+
+// ReloadConfig 重新应用工具配置
+//
+// 更新分类启用状态与配置、别名、超时与结果大小上限，并为新启用的分类注册工具；
+// max_concurrent_calls 等全局执行参数需重启后生效。
+func (tm *ToolManager) ReloadConfig(toolConfig *config.ToolManagerConfig) {
+	tm.mu.Lock()
+	for name, configData := range toolConfig.Categories {
+		categoryMgr, exists := tm.categories[ToolCategory(name)]
+		if !exists {
+			tm.logger.Warn().Str("category", name).Msg("Unknown category in config, skipping")
+			continue
+		}
+
+		categoryMgr.enabled = configData.Enabled
+		categoryMgr.config = CategoryConfig{
+			Enabled:   configData.Enabled,
+			MaxTools:  configData.MaxTools,
+			RateLimit: configData.RateLimit,
+			Timeout:   time.Duration(configData.Timeout) * time.Second,
+		}
+	}
+	tm.aliases = newAliasTable(toolConfig.Aliases, toolConfig.ClientAliases, toolConfig.Namespaces)
+	tm.toolTimeouts = toolTimeouts(toolConfig)
+	tm.tags = toolConfig.ToolTags
+	tm.approvals = toolConfig.Approvals
+	tm.redactor = NewRedactor(toolConfig.Redaction)
+	tm.piiFilter = NewPIIFilter(toolConfig.PII)
+	tm.policyEngine = NewPolicyEngine(toolConfig.Policies)
+	tm.opa = NewOPAAuthorizer(toolConfig.OPA)
+	tm.defaultTimeout = time.Duration(toolConfig.Global.DefaultTimeout) * time.Second
+	tm.resultLimits = resultLimits(toolConfig)
+	tm.defaultResultLimit = toolConfig.Global.MaxResultBytes
+	previous := tm.http
+	tm.http = tm.newHTTPClients(toolConfig.HTTPClient)
+	tm.mu.Unlock()
+	// 进行中的请求不受影响，旧连接池的空闲连接随即关闭
+	previous.CloseIdleConnections()
+
+	tm.RegisterAllTools()
+	tm.logger.Info().Msg("Tool config reloaded")
+}
+
+// UpdateCategoryConfig 更新分类配置
+func (tm *ToolManager) UpdateCategoryConfig(category ToolCategory, config CategoryConfig) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	categoryMgr, exists := tm.categories[category]
+	if !exists {
+		return werrors.NotFound("category not found: %s", category)
+	}
+
+	categoryMgr.config = config
+	tm.logger.Info().
+		Str("category", string(category)).
+		Interface("config", config).
+		Msg("Category config updated")
+
+	return nil
+}
+
+// GetTools 获取所有已启用工具的信息，按名称排序
+func (tm *ToolManager) GetTools() []ToolInfo {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	var tools []ToolInfo
+	for _, categoryMgr := range tm.categories {
+		if !categoryMgr.enabled {
+			continue
+		}
+
+		for _, tool := range categoryMgr.tools {
+			if tm.disabled[tool.Name()] {
+				continue
+			}
+			tools = append(tools, tm.toolInfoLocked(tool, true))
+		}
+	}
+
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// NewToolInfo 构造工具信息
+func NewToolInfo(tool Tool, enabled bool) ToolInfo {
+	info := ToolInfo{
+		Name:        tool.Name(),
+		Description: tool.Description(),
+		Category:    tool.Category(),
+		Enabled:     enabled,
+	}
+	if schemaTool, ok := tool.(SchemaTool); ok {
+		info.InputSchema = schemaTool.InputSchema()
+	}
+	info.OutputSchema = outputSchema(tool)
+	info.Tags = toolTags(tool, nil)
+	return info
+}
+
+// toolInfoLocked 构造工具信息并合并配置追加的标签（调用方需持有锁）
+func (tm *ToolManager) toolInfoLocked(tool Tool, enabled bool) ToolInfo {
+	info := NewToolInfo(tool, enabled)
+	info.Tags = toolTags(tool, tm.tags[tool.Name()])
+	return info
+}
+
+// GetAllTools 获取所有已注册工具及其启用状态（含已禁用的工具和分类）
+func (tm *ToolManager) GetAllTools() []ToolInfo {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	tools := []ToolInfo{}
+	for _, categoryMgr := range tm.categories {
+		for _, tool := range categoryMgr.tools {
+			tools = append(tools, tm.toolInfoLocked(tool, categoryMgr.enabled && !tm.disabled[tool.Name()]))
+		}
+	}
+
+	return tools
+}
+
+// GetToolsByCategory 按分类获取工具信息
+func (tm *ToolManager) GetToolsByCategory(category ToolCategory) []ToolInfo {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	categoryMgr, exists := tm.categories[category]
+	if !exists || !categoryMgr.enabled {
+		return []ToolInfo{}
+	}
+
+	var tools []ToolInfo
+	for _, tool := range categoryMgr.tools {
+		if tm.disabled[tool.Name()] {
+			continue
+		}
+		tools = append(tools, tm.toolInfoLocked(tool, true))
+	}
+
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// GetCategories 获取所有分类信息
+func (tm *ToolManager) GetCategories() map[ToolCategory]CategoryConfig {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	categories := make(map[ToolCategory]CategoryConfig)
+	for category, categoryMgr := range tm.categories {
+		categories[category] = categoryMgr.config
+	}
+
+	return categories
+}
+
+// Resources 列出已启用工具公开的资源
+func (tm *ToolManager) Resources() []ToolResource {
+	var resources []ToolResource
+	for _, tool := range tm.resourceTools() {
+		resources = append(resources, tool.Resources()...)
+	}
+	return resources
+}
+
+// ReadResource 读取被截断结果的其余内容或已启用工具公开的资源，没有工具认领时 ok 为 false
+func (tm *ToolManager) ReadResource(uri string) (content, mimeType string, ok bool, err error) {
+	if strings.HasPrefix(uri, ResultURIPrefix) {
+		content, err = tm.results.Read(uri)
+		return content, "text/plain", true, err
+	}
+	for _, tool := range tm.resourceTools() {
+		if content, mimeType, ok, err = tool.ReadResource(uri); ok {
+			return content, mimeType, true, err
+		}
+	}
+	return "", "", false, nil
+}
+
+// resourceTools 收集已启用的 ResourceTool，按名称排序
+func (tm *ToolManager) resourceTools() []ResourceTool {
+	return enabledTools[ResourceTool](tm)
+}
+
+// enabledTools 在读锁内收集已启用且实现接口 T 的工具，按名称排序
+func enabledTools[T Tool](tm *ToolManager) []T {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	var tools []T
+	for _, categoryMgr := range tm.categories {
+		if !categoryMgr.enabled {
+			continue
+		}
+		for name, tool := range categoryMgr.tools {
+			if typed, ok := tool.(T); ok && !tm.disabled[name] {
+				tools = append(tools, typed)
+			}
+		}
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name() < tools[j].Name() })
+	return tools
+}
+
+// Close 释放已注册工具与资源提供者持有的资源（如浏览器进程、数据库连接），服务器关闭时调用
+func (tm *ToolManager) Close() error {
+	tm.mu.Lock()
+	var closers []io.Closer
+	for _, categoryMgr := range tm.categories {
+		for _, tool := range categoryMgr.tools {
+			if closer, ok := tool.(io.Closer); ok {
+				closers = append(closers, closer)
+			}
+		}
+	}
+	for _, provider := range tm.providers {
+		if closer, ok := provider.(io.Closer); ok {
+			closers = append(closers, closer)
+		}
+	}
+	tm.providers = nil
+	if tm.blobs != nil {
+		closers = append(closers, tm.blobs)
+	}
+	tm.mu.Unlock()
+
+	var errs []error
+	for _, closer := range closers {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// toolEntry 工具查找结果快照，执行阶段不再持有管理器锁
+type toolEntry struct {
+	tool        Tool
+	category    ToolCategory
+	config      CategoryConfig
+	timeout     time.Duration // 生效的超时时间，0 为不限制
+	resultLimit int           // 文本结果的字节数上限，0 为不限制
+	tags        []string
+	approval    bool // 调用需要人工审批
+	observers   []CallObserver
+}
+
+// lookupTool 在读锁内复制工具引用、分类配置与观察者列表
+func (tm *ToolManager) lookupTool(name string) (toolEntry, bool) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	entry := toolEntry{observers: tm.observers}
+
+	// 在所有分类中查找工具
+	for cat, categoryMgr := range tm.categories {
+		if !categoryMgr.enabled {
+			continue
+		}
+
+		if t, exists := categoryMgr.tools[name]; exists {
+			if tm.disabled[name] {
+				return entry, false
+			}
+			entry.tool = t
+			entry.category = cat
+			entry.config = categoryMgr.config
+			entry.timeout = tm.resolveTimeout(name, categoryMgr)
+			entry.resultLimit = tm.resolveResultLimit(name)
+			entry.tags = toolTags(t, tm.tags[name])
+			entry.approval = tm.requiresApprovalLocked(name, entry.tags)
+			return entry, true
+		}
+	}
+
+	return entry, false
+}
+
+// NewWorkspace 创建由调用方负责删除的工作区，未启用工作区时返回 nil
+//
+// 放入上下文（WithWorkspace）后 CallTool 直接使用，不会在调用结束时删除，
+// 异步任务借此在结果返回后保留工作区一段时间。
+func (tm *ToolManager) NewWorkspace() *Workspace {
+	if tm.workspaces == nil {
+		return nil
+	}
+	return tm.workspaces.New()
+}
+
+// Blobs 上传文件存储，关闭上传时返回 nil
+func (tm *ToolManager) Blobs() *BlobStore {
+	return tm.blobs
+}
+
+// SweepWorkspaces 删除遗留的过期工作区
+func (tm *ToolManager) SweepWorkspaces(olderThan time.Duration) (int, error) {
+	if tm.workspaces == nil {
+		return 0, nil
+	}
+	return tm.workspaces.Sweep(olderThan)
+}
+
+// attachWorkspace 上下文中没有工作区时创建一个，返回的函数在调用结束时删除它
+func (tm *ToolManager) attachWorkspace(ctx context.Context, name string) (context.Context, func()) {
+	if _, ok := WorkspaceFromContext(ctx); ok || tm.workspaces == nil {
+		return ctx, func() {}
+	}
+
+	ws := tm.workspaces.New()
+	return WithWorkspace(ctx, ws), func() {
+		if usage := ws.Usage(); usage > ws.MaxBytes() {
+			tm.logger.Warn().
+				Str("tool", name).
+				Int64("usage", usage).
+				Int64("max_bytes", ws.MaxBytes()).
+				Msg("Tool workspace exceeded size limit")
+		}
+		if err := ws.Remove(); err != nil {
+			tm.logger.Warn().Str("tool", name).Err(err).Msg("Failed to remove tool workspace")
+		}
+	}
+}
+
+// ArgumentDecrypter 解密客户端以 JWE 加密的工具参数
+type ArgumentDecrypter interface {
+	Decrypt(compact string) ([]byte, error)
+}
+
+// ErrEncryptionDisabled 收到加密参数但服务器未配置解密密钥
+var ErrEncryptionDisabled = werrors.New(werrors.KindInvalidParams, "encrypted arguments are not enabled")
+
+// errArgumentsWithheld 加密参数调用失败时日志与调用记录中使用的错误，避免工具在错误信息中回显参数
+var errArgumentsWithheld = errors.New("tool call failed (details withheld for encrypted arguments)")
+
+// SetArgumentDecrypter 设置加密参数的解密器
+func (tm *ToolManager) SetArgumentDecrypter(decrypter ArgumentDecrypter) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.decrypter = decrypter
+}
+
+// openArguments 解密信封加密的参数并标记调用记录，普通参数原样返回
+func (tm *ToolManager) openArguments(args json.RawMessage, record *CallRecord) (json.RawMessage, error) {
+	compact, ok := envelope.Unwrap(args)
+	if !ok {
+		return args, nil
+	}
+	record.Encrypted = true
+
+	tm.mu.RLock()
+	decrypter := tm.decrypter
+	tm.mu.RUnlock()
+	if decrypter == nil {
+		return nil, ErrEncryptionDisabled
+	}
+
+	plain, err := decrypter.Decrypt(compact)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(plain) {
+		return nil, fmt.Errorf("%w: decrypted arguments are not valid JSON", ErrInvalidArguments)
+	}
+	return plain, nil
+}
+
+// ValidateArguments 按工具声明的 Schema 校验参数，未声明 Schema 的工具不做校验
+func (tm *ToolManager) ValidateArguments(name string, args json.RawMessage) error {
+	entry, found := tm.lookupTool(tm.ResolveAlias("", name))
+	if !found {
+		return tm.toolNotFound(name)
+	}
+	return validateArguments(entry.tool, args)
+}
+
+// validateArguments 校验参数，失败时返回包装 ErrInvalidArguments 的错误
+func validateArguments(tool Tool, args json.RawMessage) error {
+	schemaTool, ok := tool.(SchemaTool)
+	if !ok {
+		return nil
+	}
+	if err := schemaTool.InputSchema().ValidateJSON(args); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	return nil
+}
+
+// CallTool 调用工具
+func (tm *ToolManager) CallTool(ctx context.Context, name string, args json.RawMessage) (*ToolCallResult, error) {
+	return tm.callTool(ctx, name, args, nil)
+}
+
+// CallToolStream 流式调用工具，以回调推送输出片段
+//
+// 保留给只处理片段的调用方，工具上报的进度交给上下文中的进度接收者，日志事件被忽略；
+// 需要进度与日志事件时使用 CallToolEvents。
+func (tm *ToolManager) CallToolStream(ctx context.Context, name string, args json.RawMessage, callback StreamCallback) (*ToolCallResult, error) {
+	progress := ProgressFromContext(ctx)
+	emit := &eventEmitter{sink: func(event StreamEvent) {
+		switch event.Type {
+		case EventPartial:
+			if callback != nil {
+				callback(event.Content, event.Index)
+			}
+		case EventProgress:
+			progress.Report(event.Progress, event.Total, event.Message)
+		}
+	}}
+	return tm.callTool(ctx, name, args, emit)
+}
+
+// CallToolEvents 流式调用工具，按发生顺序向 sink 推送片段、进度与日志事件，成功时以 final 事件结束
+//
+// 工具通过 ProgressFromContext 上报的进度同样作为 progress 事件推送；不支持流式的工具推送按片段切分的结果。
+// 调用失败时不推送 final 事件，错误由返回值给出。
+func (tm *ToolManager) CallToolEvents(ctx context.Context, name string, args json.RawMessage, sink EventSink) (*ToolCallResult, error) {
+	emit := &eventEmitter{sink: sink}
+	ctx = WithProgress(ctx, ProgressFunc(emit.Progress))
+	return tm.callTool(ctx, name, args, emit)
+}
+
+// callTool 调用工具的完整流程：租户、审批、解密、校验、授权与策略、熔断、执行槽位、工作区、超时、
+// 执行、结构化输出、结果策略、个人信息与截断
+//
+// emit 为空时同步执行；不为空时流式执行，事件经 emit 推送，成功时以 final 事件结束。
+// 不支持流式的工具按同步调用执行，再将结果按片段推送。
+func (tm *ToolManager) callTool(ctx context.Context, name string, args json.RawMessage, emit *eventEmitter) (*ToolCallResult, error) {
+	startTime := time.Now()
+	stream := emit != nil
+	label := "Tool call"
+	if stream {
+		label = "Stream tool call"
+	}
+
+	// 解析全局别名，日志中记录规范工具名
+	alias := ""
+	if canonical := tm.ResolveAlias("", name); canonical != name {
+		alias, name = name, canonical
+	}
+
+	record := CallRecord{
+		Tool:      name,
+		Alias:     alias,
+		Arguments: args,
+		Stream:    stream,
+		Status:    CallStatusSuccess,
+		StartedAt: startTime,
+	}
+
+	entry, found := tm.lookupTool(name)
+	if !found {
+		tm.logger.Error().Str("tool", name).Msg("Tool not found")
+		return nil, tm.failCall(ctx, entry.observers, record, tm.toolNotFound(name))
+	}
+	record.Category = entry.category
+
+	execute := func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+		return entry.tool.Execute(ctx, args)
+	}
+	if stream {
+		runStream := streamRunner(entry.tool)
+		if runStream == nil {
+			tm.logger.Warn().Str("tool", name).Msg("Tool does not support streaming, returning regular execution result")
+			// 若不支持流式，返回普通调用结果，较大的文本结果按片段推送
+			result, err := tm.callTool(ctx, name, args, nil)
+			if err == nil {
+				tm.streamResult(result, emit.partialAt)
+				emit.final(result)
+			}
+			return result, err
+		}
+		execute = func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+			return runStream(ctx, args, emit)
+		}
+	}
+
+	if err := tm.checkTenant(ctx, name, entry.category, entry.tags); err != nil {
+		tm.logger.Warn().Str("tool", name).Str("tenant", TenantFromContext(ctx).Name).Err(err).Msg("Tool call rejected for tenant")
+		return nil, tm.failCall(ctx, entry.observers, record, err)
+	}
+	if entry.approval && !approvedFromContext(ctx) {
+		tm.logger.Warn().Str("tool", name).Msg("Tool call rejected without approval")
+		return nil, tm.failCall(ctx, entry.observers, record, fmt.Errorf("%w: %s", ErrApprovalRequired, name))
+	}
+
+	// 加密参数仅在执行前于内存中解密，日志与调用记录中只保留密文
+	plainArgs, err := tm.openArguments(args, &record)
+	if err != nil {
+		tm.logger.Warn().Str("tool", name).Err(err).Msg("Tool arguments rejected")
+		return nil, tm.failCall(ctx, entry.observers, record, err)
+	}
+
+	if err := validateArguments(entry.tool, plainArgs); err != nil {
+		tm.logger.Warn().Str("tool", name).Err(record.loggedError(err)).Msg("Tool arguments rejected")
+		return nil, tm.failCall(ctx, entry.observers, record, err)
+	}
+
+	// OPA 授权与内容安全策略检查解密后的参数
+	if err := tm.authorize(ctx, name, entry, plainArgs); err != nil {
+		return nil, tm.failCall(ctx, entry.observers, record, err)
+	}
+	var annotations []PolicyFinding
+	if err := tm.enforcePolicy(ctx, config.PolicyTargetArguments, name, plainArgs, nil, &annotations); err != nil {
+		return nil, tm.failCall(ctx, entry.observers, record, err)
+	}
+
+	// 连续失败的工具在熔断期间直接拒绝
+	if err := tm.breakers.Allow(name); err != nil {
+		tm.logger.Warn().Str("tool", name).Err(err).Msg("Tool call short-circuited")
+		return nil, tm.failCall(ctx, entry.observers, record, err)
+	}
+
+	// 获取执行槽位，池满时等待直到上下文取消
+	if err := tm.pool.Acquire(ctx); err != nil {
+		tm.breakers.Abandon(name)
+		tm.logger.Warn().Str("tool", name).Err(err).Msg("No execution slot available")
+		return nil, tm.failCall(ctx, entry.observers, record, err)
+	}
+	defer tm.pool.Release()
+
+	// 为本次调用提供临时工作区，调用结束后删除
+	ctx, releaseWorkspace := tm.attachWorkspace(ctx, name)
+	defer releaseWorkspace()
+	ctx = withCallBreakers(ctx, tm.breakers, func(err error) string { return tm.PublicErrorText(name, record.loggedError(err)) })
+	ctx = tm.callContext(ctx, name)
+	if stream {
+		emit.logger = LoggerFromContext(ctx)
+		emit.mask = func(content string) string { return tm.pii().Mask(name, content) }
+	}
+
+	// 应用超时：单个工具、分类、全局默认依次覆盖，请求自带的截止时间更早时以其为准
+	ctx, cancel, budget := withTimeout(ctx, entry.timeout)
+	defer cancel()
+	if stream {
+		// 结果阶段的拒绝规则同样检查每个片段，命中时中止调用，不再推送后续片段
+		emit.check = tm.partialPolicy(ctx, name)
+		emit.abort = cancel
+	}
+
+	// 记录工具调用开始
+	tm.logger.Info().
+		Str("tool", name).
+		Str("alias", alias).
+		Str("category", string(entry.category)).
+		RawJSON("args", tm.redaction().Arguments(name, args)).
+		Msg(label + " started")
+
+	result, err := tm.runTool(ctx, name, entry.category, func(ctx context.Context) (json.RawMessage, error) {
+		return execute(ctx, plainArgs)
+	})
+	err = timeoutError(ctx, name, entry.timeout, budget, err)
+	if blocked := emit.blockedError(); blocked != nil {
+		// 与最终结果被拒绝时相同，工具本身视为执行成功
+		tm.breakers.recordCall(ctx, name, nil, "")
+		record.Duration = time.Since(startTime)
+		return nil, tm.failCall(ctx, entry.observers, record, blocked)
+	}
+	tm.breakers.recordCall(ctx, name, err, tm.PublicErrorText(name, record.loggedError(err)))
+	record.Duration = time.Since(startTime)
+	if errors.Is(err, ErrToolPanic) {
+		callResult := tm.panicResult(ctx, entry.observers, record, err)
+		emit.final(callResult)
+		return callResult, nil
+	}
+
+	// 记录工具调用结果
+	if err != nil {
+		tm.logger.Error().
+			Str("tool", name).
+			Str("category", string(entry.category)).
+			Dur("duration", record.Duration).
+			Err(record.loggedError(err)).
+			Msg(label + " failed")
+		return nil, tm.failCall(ctx, entry.observers, record, err)
+	}
+
+	tm.logger.Info().
+		Str("tool", name).
+		Str("category", string(entry.category)).
+		Dur("duration", record.Duration).
+		Msg(label + " completed successfully")
+
+	// MCP 兼容格式
+	callResult := &ToolCallResult{
+		Content: resultContent(entry.tool, result),
+	}
+	if err := tm.structureResult(name, entry.tool, result, callResult); err != nil {
+		tm.logger.Error().Str("tool", name).Err(err).Msg("Tool output rejected")
+		return nil, tm.failCall(ctx, entry.observers, record, err)
+	}
+	if err := tm.enforcePolicy(ctx, config.PolicyTargetResult, name, plainArgs, callResult, &annotations); err != nil {
+		return nil, tm.failCall(ctx, entry.observers, record, err)
+	}
+	annotatePolicy(callResult, annotations)
+
+	// 先检测个人信息，截断后保存的完整内容同样不含原文
+	tm.pii().Apply(name, callResult)
+	tm.truncateResult(name, callResult, entry.resultLimit)
+	tm.finishStructuredContent(name, callResult)
+	record.Result = callResult
+	tm.notifyObservers(ctx, entry.observers, record)
+	emit.final(callResult)
+
+	return callResult, nil
+}
+
+// resultContent 将工具结果转换为 MCP 内容，自定义内容失败时退回文本内容
+func resultContent(tool Tool, result json.RawMessage) []ToolCallContent {
+	if contentTool, ok := tool.(ContentTool); ok {
+		if content, err := contentTool.ResultContent(result); err == nil && len(content) > 0 {
+			return content
+		}
+	}
+	return []ToolCallContent{
+		{
+			Type: "text",
+			Text: string(result),
+		},
+	}
+}
+
+// runTool 执行工具并捕获 panic，记录调用栈后转换为 ErrToolPanic 错误
+//
+// 只能捕获工具在调用协程中的 panic，工具自行启动的协程需要自行恢复。
+//
+// 执行期间为协程设置 pprof 标签 tool 与 category，CPU profile 中的样本可按工具归因，
+// 工具在执行期间启动的协程继承这些标签。
+func (tm *ToolManager) runTool(ctx context.Context, name string, category ToolCategory, execute func(ctx context.Context) (json.RawMessage, error)) (result json.RawMessage, err error) {
+	defer tm.stats.begin(name, category)()
+	defer func() {
+		if r := recover(); r != nil {
+			tm.logger.Error().
+				Str("tool", name).
+				Interface("panic", r).
+				Bytes("stack", debug.Stack()).
+				Msg("Tool panicked")
+			result, err = nil, fmt.Errorf("%w: %v", ErrToolPanic, r)
+		}
+	}()
+	pprof.Do(ctx, pprof.Labels("tool", name, "category", string(category)), func(ctx context.Context) {
+		result, err = execute(ctx)
+	})
+	return result, err
+}
+
+// panicResult 将工具 panic 转换为 isError 的调用结果，panic 详情仅记录在日志中
+func (tm *ToolManager) panicResult(ctx context.Context, observers []CallObserver, record CallRecord, err error) *ToolCallResult {
+	callResult := &ToolCallResult{
+		Content: []ToolCallContent{
+			{
+				Type: "text",
+				Text: fmt.Sprintf("internal error: tool %s failed unexpectedly", record.Tool),
+			},
+		},
+		IsError: true,
+	}
+	record.Result = callResult
+	tm.failCall(ctx, observers, record, err)
+	return callResult
+}
+
+// PublicErrorText 可公开的错误文本：按工具的脱敏规则处理并掩码个人信息，
+// 熔断状态与异步任务保存的错误均经过此处理；加密参数的调用应先经 CallRecord.loggedError 去掉错误详情
+func (tm *ToolManager) PublicErrorText(tool string, err error) string {
+	if err == nil {
+		return ""
+	}
+	return tm.pii().Mask(tool, tm.redaction().Text(tool, err.Error()))
+}
+
+// failCall 记录失败的调用并原样返回错误
+func (tm *ToolManager) failCall(ctx context.Context, observers []CallObserver, record CallRecord, err error) error {
+	record.Status = CallStatusError
+	record.Error = record.loggedError(err).Error()
+	record.ErrorKind = werrors.KindOf(err)
+	if record.Duration == 0 {
+		record.Duration = time.Since(record.StartedAt)
+	}
+	tm.notifyObservers(ctx, observers, record)
+	return err
+}
+
+// CircuitBreakers 获取工具与上游服务的熔断器
+func (tm *ToolManager) CircuitBreakers() *CircuitBreakers {
+	return tm.breakers
+}
+
+// PoolStats 获取执行池统计信息
+func (tm *ToolManager) PoolStats() map[string]interface{} {
+	return tm.pool.Stats()
+}
+
+// PoolLoad 获取执行池当前负载
+func (tm *ToolManager) PoolLoad() (inUse, capacity int, waiting int64) {
+	return tm.pool.Load()
+}
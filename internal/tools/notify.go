@@ -0,0 +1,392 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/schema"
+)
+
+// 通知渠道类型
+const (
+	NotifyChannelSlack    = "slack"
+	NotifyChannelDiscord  = "discord"
+	NotifyChannelTelegram = "telegram"
+)
+
+// 通知级别
+const (
+	NotifyLevelInfo    = "info"
+	NotifyLevelSuccess = "success"
+	NotifyLevelWarning = "warning"
+	NotifyLevelError   = "error"
+)
+
+// notify 默认参数
+const (
+	defaultNotifyMaxMessageBytes = 4000
+	defaultTelegramURL           = "https://api.telegram.org"
+	notifyRequestTimeout         = 15 * time.Second
+	notifyErrorBodyBytes         = 512
+)
+
+// notifyLevels 各级别在 Slack 与 Discord 中的颜色及在 Telegram 中的前缀
+var notifyLevels = map[string]struct {
+	color int
+	emoji string
+}{
+	NotifyLevelInfo:    {0x2F80ED, "ℹ️"},
+	NotifyLevelSuccess: {0x27AE60, "✅"},
+	NotifyLevelWarning: {0xF2C94C, "⚠️"},
+	NotifyLevelError:   {0xEB5757, "🚨"},
+}
+
+// NotifyTool 消息通知工具
+//
+// 向配置的 Slack、Discord incoming webhook 或 Telegram 机器人发送通知，渠道按别名引用，
+// webhook 地址与 bot token 只保存在配置中。可一次发送到多个渠道，部分渠道失败时在结果中逐个报告。
+type NotifyTool struct {
+	config config.NotifyConfig
+}
+
+// NotifyArgs 通知参数，channel 与 channels 均为空时使用默认渠道
+type NotifyArgs struct {
+	Channel  string   `json:"channel"`
+	Channels []string `json:"channels"`
+	Message  string   `json:"message"`
+	Title    string   `json:"title"`
+	Level    string   `json:"level"` // info, success, warning, error
+	URL      string   `json:"url"`   // 标题链接
+}
+
+// NotifyResult 通知结果
+type NotifyResult struct {
+	Sent       int                  `json:"sent"`
+	Failed     int                  `json:"failed"`
+	Deliveries []NotifyDeliveryInfo `json:"deliveries"`
+}
+
+// NotifyDeliveryInfo 单个渠道的发送结果
+type NotifyDeliveryInfo struct {
+	Channel   string `json:"channel"`
+	Type      string `json:"type"`
+	OK        bool   `json:"ok"`
+	MessageID string `json:"message_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// notifyMessage 渲染前的消息
+type notifyMessage struct {
+	Title   string
+	Message string
+	Level   string
+	URL     string
+}
+
+// NewNotifyTool 创建消息通知工具
+func NewNotifyTool(cfg config.NotifyConfig) *NotifyTool {
+	if cfg.MaxMessageBytes <= 0 {
+		cfg.MaxMessageBytes = defaultNotifyMaxMessageBytes
+	}
+	return &NotifyTool{config: cfg}
+}
+
+func (nt *NotifyTool) Name() string {
+	return "notify"
+}
+
+func (nt *NotifyTool) Description() string {
+	return "Send an alert or message to configured Slack, Discord or Telegram channels by alias"
+}
+
+func (nt *NotifyTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (nt *NotifyTool) Tags() []string {
+	return []string{TagNetwork}
+}
+
+func (nt *NotifyTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"channel":  {Type: schema.TypeString, Description: "Configured channel alias, defaults to the configured default", MinLength: schema.Int(1)},
+		"channels": {Type: schema.TypeArray, Description: "Several channel aliases to send the same message to", Items: &schema.Schema{Type: schema.TypeString, MinLength: schema.Int(1)}, MinItems: schema.Int(1)},
+		"message":  {Type: schema.TypeString, Description: "Message body", MinLength: schema.Int(1)},
+		"title":    {Type: schema.TypeString, Description: "Optional title"},
+		"level":    {Type: schema.TypeString, Description: "Severity, shown as a color or emoji", Enum: []interface{}{NotifyLevelInfo, NotifyLevelSuccess, NotifyLevelWarning, NotifyLevelError}, Default: NotifyLevelInfo},
+		"url":      {Type: schema.TypeString, Description: "Link attached to the title"},
+	}, "message").Closed()
+}
+
+func (nt *NotifyTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var notifyArgs NotifyArgs
+	if err := json.Unmarshal(args, &notifyArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	if strings.TrimSpace(notifyArgs.Message) == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	if len(notifyArgs.Title)+len(notifyArgs.Message) > nt.config.MaxMessageBytes {
+		return nil, fmt.Errorf("message exceeds %d bytes", nt.config.MaxMessageBytes)
+	}
+	if notifyArgs.Level == "" {
+		notifyArgs.Level = NotifyLevelInfo
+	}
+	if _, ok := notifyLevels[notifyArgs.Level]; !ok {
+		return nil, fmt.Errorf("unsupported level: %s", notifyArgs.Level)
+	}
+	if notifyArgs.URL != "" {
+		if parsed, err := url.Parse(notifyArgs.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("url must be an absolute http or https URL")
+		}
+	}
+
+	channels, err := nt.channels(notifyArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := notifyMessage{Title: notifyArgs.Title, Message: notifyArgs.Message, Level: notifyArgs.Level, URL: notifyArgs.URL}
+	result := NotifyResult{Deliveries: make([]NotifyDeliveryInfo, 0, len(channels))}
+	var lastErr error
+	for _, name := range channels {
+		cfg := nt.config.Channels[name]
+		delivery := NotifyDeliveryInfo{Channel: name, Type: cfg.Type}
+		messageID, err := nt.send(ctx, cfg, msg)
+		if err != nil {
+			lastErr = fmt.Errorf("channel %s: %v", name, err)
+			delivery.Error = err.Error()
+			result.Failed++
+		} else {
+			delivery.OK = true
+			delivery.MessageID = messageID
+			result.Sent++
+		}
+		result.Deliveries = append(result.Deliveries, delivery)
+	}
+	// 全部失败时返回错误，部分失败时由调用方根据结果决定是否重试
+	if result.Sent == 0 {
+		return nil, lastErr
+	}
+	return json.Marshal(result)
+}
+
+// channels 解析目标渠道并去重，未指定时使用默认渠道或唯一配置的渠道
+func (nt *NotifyTool) channels(args NotifyArgs) ([]string, error) {
+	var names []string
+	if args.Channel != "" {
+		names = append(names, args.Channel)
+	}
+	names = append(names, args.Channels...)
+
+	if len(names) == 0 {
+		switch {
+		case nt.config.Default != "":
+			names = []string{nt.config.Default}
+		case len(nt.config.Channels) == 1:
+			for name := range nt.config.Channels {
+				names = []string{name}
+			}
+		case len(nt.config.Channels) == 0:
+			return nil, fmt.Errorf("no notification channel configured")
+		default:
+			available := make([]string, 0, len(nt.config.Channels))
+			for name := range nt.config.Channels {
+				available = append(available, name)
+			}
+			sort.Strings(available)
+			return nil, fmt.Errorf("channel is required, available: %s", strings.Join(available, ", "))
+		}
+	}
+
+	seen := make(map[string]bool, len(names))
+	unique := names[:0]
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		if _, ok := nt.config.Channels[name]; !ok {
+			return nil, fmt.Errorf("unknown notification channel: %s", name)
+		}
+		seen[name] = true
+		unique = append(unique, name)
+	}
+	return unique, nil
+}
+
+// send 按渠道类型发送消息，返回服务端的消息 ID（如有）
+func (nt *NotifyTool) send(ctx context.Context, cfg config.NotifyChannelConfig, msg notifyMessage) (string, error) {
+	switch cfg.Type {
+	case NotifyChannelSlack:
+		return nt.sendSlack(ctx, cfg, msg)
+	case NotifyChannelDiscord:
+		return nt.sendDiscord(ctx, cfg, msg)
+	case NotifyChannelTelegram:
+		return nt.sendTelegram(ctx, cfg, msg)
+	default:
+		return "", fmt.Errorf("unsupported channel type %q", cfg.Type)
+	}
+}
+
+// sendSlack 通过 incoming webhook 发送带颜色条的附件，响应体为 "ok"
+func (nt *NotifyTool) sendSlack(ctx context.Context, cfg config.NotifyChannelConfig, msg notifyMessage) (string, error) {
+	webhookURL := cfg.ResolveWebhookURL()
+	if webhookURL == "" {
+		return "", fmt.Errorf("webhook_url is required")
+	}
+
+	attachment := map[string]interface{}{
+		"color":    fmt.Sprintf("#%06X", notifyLevels[msg.Level].color),
+		"text":     msg.Message,
+		"fallback": msg.Message,
+	}
+	if msg.Title != "" {
+		attachment["title"] = msg.Title
+		attachment["fallback"] = msg.Title + ": " + msg.Message
+		if msg.URL != "" {
+			attachment["title_link"] = msg.URL
+		}
+	}
+	body := map[string]interface{}{"attachments": []interface{}{attachment}}
+	if cfg.Username != "" {
+		body["username"] = cfg.Username
+	}
+
+	_, err := postNotify(ctx, webhookURL, body)
+	return "", err
+}
+
+// sendDiscord 通过 webhook 发送 embed，wait=true 时 Discord 返回创建的消息
+func (nt *NotifyTool) sendDiscord(ctx context.Context, cfg config.NotifyChannelConfig, msg notifyMessage) (string, error) {
+	webhookURL := cfg.ResolveWebhookURL()
+	if webhookURL == "" {
+		return "", fmt.Errorf("webhook_url is required")
+	}
+	separator := "?"
+	if strings.Contains(webhookURL, "?") {
+		separator = "&"
+	}
+
+	embed := map[string]interface{}{
+		"description": msg.Message,
+		"color":       notifyLevels[msg.Level].color,
+	}
+	if msg.Title != "" {
+		embed["title"] = msg.Title
+		if msg.URL != "" {
+			embed["url"] = msg.URL
+		}
+	}
+	body := map[string]interface{}{"embeds": []interface{}{embed}}
+	if cfg.Username != "" {
+		body["username"] = cfg.Username
+	}
+
+	data, err := postNotify(ctx, webhookURL+separator+"wait=true", body)
+	if err != nil {
+		return "", err
+	}
+	var response struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(data, &response)
+	return response.ID, nil
+}
+
+// sendTelegram 通过 Bot API sendMessage 发送 HTML 格式的消息
+func (nt *NotifyTool) sendTelegram(ctx context.Context, cfg config.NotifyChannelConfig, msg notifyMessage) (string, error) {
+	token := cfg.ResolveToken()
+	if token == "" || cfg.ChatID == "" {
+		return "", fmt.Errorf("token and chat_id are required")
+	}
+	baseURL := cfg.URL
+	if baseURL == "" {
+		baseURL = defaultTelegramURL
+	}
+
+	var text strings.Builder
+	text.WriteString(notifyLevels[msg.Level].emoji + " ")
+	if msg.Title != "" {
+		title := "<b>" + html.EscapeString(msg.Title) + "</b>"
+		if msg.URL != "" {
+			title = `<a href="` + html.EscapeString(msg.URL) + `">` + title + "</a>"
+		}
+		text.WriteString(title + "\n")
+	}
+	text.WriteString(html.EscapeString(msg.Message))
+
+	body := map[string]interface{}{
+		"chat_id":                  cfg.ChatID,
+		"text":                     text.String(),
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}
+	data, err := postNotify(ctx, strings.TrimRight(baseURL, "/")+"/bot"+token+"/sendMessage", body)
+	if err != nil {
+		// 错误信息中不包含 token
+		return "", fmt.Errorf("%s", strings.ReplaceAll(err.Error(), token, "***"))
+	}
+	var response struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+		Result      struct {
+			MessageID int64 `json:"message_id"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", fmt.Errorf("invalid telegram response: %v", err)
+	}
+	if !response.OK {
+		return "", fmt.Errorf("telegram error: %s", response.Description)
+	}
+	return strconv.FormatInt(response.Result.MessageID, 10), nil
+}
+
+// postNotify 发送 JSON 请求并返回响应体，非 2xx 状态码返回包含响应片段的错误
+func postNotify(ctx context.Context, endpoint string, body interface{}) ([]byte, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification endpoint")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := HTTPClient(ctx, notifyRequestTimeout).Do(req)
+	if err != nil {
+		// 请求地址中包含凭据，只返回底层错误
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("notification request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, fmt.Errorf("read notification response: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet := data
+		if len(snippet) > notifyErrorBodyBytes {
+			snippet = snippet[:notifyErrorBodyBytes]
+		}
+		return nil, fmt.Errorf("notification endpoint returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return data, nil
+}
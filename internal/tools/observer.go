@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// 工具调用状态
+const (
+	CallStatusSuccess = "success"
+	CallStatusError   = "error"
+)
+
+// CallRecord 工具调用记录
+type CallRecord struct {
+	Tool      string          `json:"tool"`
+	Alias     string          `json:"alias,omitempty"`
+	Category  ToolCategory    `json:"category,omitempty"`
+	Client    string          `json:"client,omitempty"`
+	Tenant    string          `json:"tenant,omitempty"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Result    *ToolCallResult `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	ErrorKind werrors.Kind    `json:"error_kind,omitempty"` // 错误分类，统计时不依赖错误文本
+	Status    string          `json:"status"`
+	Stream    bool            `json:"stream"`
+	Encrypted bool            `json:"encrypted,omitempty"` // 参数经信封加密，Arguments 为密文
+	StartedAt time.Time       `json:"started_at"`
+	Duration  time.Duration   `json:"duration"`
+}
+
+// loggedError 写入日志与调用记录的错误，加密参数的调用不记录工具返回的错误详情
+func (r CallRecord) loggedError(err error) error {
+	if r.Encrypted {
+		return errArgumentsWithheld
+	}
+	return err
+}
+
+// CallObserver 工具调用观察者，在每次调用结束后被同步调用，实现方应避免阻塞
+type CallObserver func(ctx context.Context, record CallRecord)
+
+// clientContextKey 客户端名称上下文键
+type clientContextKey struct{}
+
+// WithClient 在上下文中记录发起调用的客户端名称
+func WithClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+// ClientFromContext 从上下文中获取客户端名称
+func ClientFromContext(ctx context.Context) string {
+	if client, ok := ctx.Value(clientContextKey{}).(string); ok {
+		return client
+	}
+	return ""
+}
+
+// AddCallObserver 注册工具调用观察者
+func (tm *ToolManager) AddCallObserver(observer CallObserver) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.observers = append(tm.observers, observer)
+}
+
+// redaction 当前的脱敏规则
+func (tm *ToolManager) redaction() *Redactor {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.redactor
+}
+
+// pii 当前的个人信息检测
+func (tm *ToolManager) pii() *PIIFilter {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.piiFilter
+}
+
+// notifyObservers 计入调用统计并通知观察者列表快照
+func (tm *ToolManager) notifyObservers(ctx context.Context, observers []CallObserver, record CallRecord) {
+	// 观察者收到的参数、结果与错误均已脱敏；调用统计经 /health/stats 公开，错误文本另外掩码个人信息
+	redactor := tm.redaction()
+	record.Error = redactor.Text(record.Tool, record.Error)
+	stats := record
+	stats.Error = tm.pii().Mask(record.Tool, record.Error)
+	tm.stats.record(stats)
+	if len(observers) == 0 {
+		return
+	}
+
+	record.Arguments = redactor.Arguments(record.Tool, record.Arguments)
+	record.Result = redactor.Result(record.Tool, record.Result)
+
+	if record.Client == "" {
+		record.Client = ClientFromContext(ctx)
+	}
+	if tenant := TenantFromContext(ctx); tenant != nil && record.Tenant == "" {
+		record.Tenant = tenant.Name
+	}
+
+	for _, observer := range observers {
+		observer(ctx, record)
+	}
+}
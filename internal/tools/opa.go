@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// ErrNotAuthorized OPA 授权策略拒绝了工具调用
+var ErrNotAuthorized = werrors.New(werrors.KindUnauthorized, "denied by authorization policy")
+
+// OPA 查询的默认参数
+const (
+	defaultOPATimeout   = 2 * time.Second
+	maxOPAResponseBytes = 64 << 10
+)
+
+// OPAAuthorizer 通过 OPA 的 Data API 查询工具调用的授权决定
+//
+// 决策规则以 Rego 编写并由 OPA 加载，修改规则无需重新编译或重启服务。
+type OPAAuthorizer struct {
+	endpoint string
+	client   *http.Client
+	failOpen bool
+}
+
+// OPAInput 查询授权决定时提交的 input
+type OPAInput struct {
+	Tool      string          `json:"tool"`
+	Category  ToolCategory    `json:"category,omitempty"`
+	Tags      []string        `json:"tags,omitempty"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Client    string          `json:"client,omitempty"`
+	Tenant    string          `json:"tenant,omitempty"`
+	Time      time.Time       `json:"time"` // UTC 时间，用于按时段限制
+}
+
+// opaDecision 决策结果，可以是布尔值或 {"allow": bool, "reason": string}
+type opaDecision struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason"`
+}
+
+// UnmarshalJSON 同时接受布尔值与对象
+func (d *opaDecision) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &d.Allow); err == nil {
+		return nil
+	}
+	type plain opaDecision
+	return json.Unmarshal(data, (*plain)(d))
+}
+
+// NewOPAAuthorizer 按配置创建授权查询，未配置 URL 时返回 nil
+func NewOPAAuthorizer(cfg config.OPAConfig) *OPAAuthorizer {
+	if cfg.URL == "" {
+		return nil
+	}
+	timeout := defaultOPATimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	return &OPAAuthorizer{
+		endpoint: strings.TrimRight(cfg.URL, "/") + "/v1/data/" + strings.Trim(cfg.Path, "/"),
+		client:   &http.Client{Timeout: timeout},
+		failOpen: cfg.FailOpen,
+	}
+}
+
+// Authorize 查询授权决定，拒绝时返回包装 ErrNotAuthorized 的错误
+//
+// 决策路径未定义（OPA 响应中没有 result）视为拒绝。OPA 不可用时按 fail_open 放行或拒绝，
+// 放行时 unavailable 返回查询错误供记录。
+func (a *OPAAuthorizer) Authorize(ctx context.Context, input OPAInput) (unavailable, err error) {
+	if a == nil {
+		return nil, nil
+	}
+	decision, err := a.query(ctx, input)
+	if err != nil {
+		if a.failOpen {
+			return err, nil
+		}
+		return err, fmt.Errorf("%w: %s: policy unavailable", ErrNotAuthorized, input.Tool)
+	}
+	if decision == nil || !decision.Allow {
+		if decision != nil && decision.Reason != "" {
+			return nil, fmt.Errorf("%w: %s: %s", ErrNotAuthorized, input.Tool, decision.Reason)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNotAuthorized, input.Tool)
+	}
+	return nil, nil
+}
+
+// query 请求 OPA，决策未定义时返回 nil
+func (a *OPAAuthorizer) query(ctx context.Context, input OPAInput) (*opaDecision, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("opa: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("opa returned status %d", resp.StatusCode)
+	}
+	var payload struct {
+		Result *opaDecision `json:"result"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOPAResponseBytes)).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("invalid opa response: %v", err)
+	}
+	return payload.Result, nil
+}
+
+// authorizer 当前的 OPA 授权查询
+func (tm *ToolManager) authorizer() *OPAAuthorizer {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.opa
+}
+
+// authorize 按 OPA 决定检查调用，args 为解密后的参数
+func (tm *ToolManager) authorize(ctx context.Context, name string, entry toolEntry, args json.RawMessage) error {
+	authorizer := tm.authorizer()
+	if authorizer == nil {
+		return nil
+	}
+	input := OPAInput{
+		Tool:      name,
+		Category:  entry.category,
+		Tags:      entry.tags,
+		Arguments: args,
+		Client:    ClientFromContext(ctx),
+		Time:      time.Now().UTC(),
+	}
+	if tenant := TenantFromContext(ctx); tenant != nil {
+		input.Tenant = tenant.Name
+	}
+	unavailable, err := authorizer.Authorize(ctx, input)
+	if unavailable != nil {
+		tm.logger.Warn().Str("tool", name).Err(unavailable).Msg("OPA authorization query failed")
+	}
+	if err != nil {
+		tm.logger.Warn().Str("tool", name).Str("client", input.Client).Str("tenant", input.Tenant).Err(err).Msg("Tool call denied by OPA policy")
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/schema"
+)
+
+// ErrInvalidOutput 工具结果不符合其声明的输出 Schema，仅在开发模式下返回
+var ErrInvalidOutput = werrors.New(werrors.KindInternal, "tool output does not match its output schema")
+
+// OutputSchemaTool 声明结果 JSON Schema 的工具，结果以 structuredContent 返回并按 Schema 校验
+//
+// 按 MCP 规范输出 Schema 的根类型应为 object，OutputSchema 返回 nil 时视为未声明。
+type OutputSchemaTool interface {
+	Tool
+	OutputSchema() *schema.Schema
+}
+
+// outputSchema 工具声明的输出 Schema，未声明时返回 nil
+func outputSchema(tool Tool) *schema.Schema {
+	if schemaTool, ok := tool.(OutputSchemaTool); ok {
+		return schemaTool.OutputSchema()
+	}
+	return nil
+}
+
+// SetStrictOutput 设置开发模式：结果不符合输出 Schema 时调用失败，否则只记录警告并省略 structuredContent
+func (tm *ToolManager) SetStrictOutput(strict bool) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.strictOutput = strict
+}
+
+// structureResult 为声明了输出 Schema 的工具校验结果并写入 structuredContent
+func (tm *ToolManager) structureResult(name string, tool Tool, result json.RawMessage, callResult *ToolCallResult) error {
+	outputSchema := outputSchema(tool)
+	if outputSchema == nil {
+		return nil
+	}
+	if err := outputSchema.ValidateJSON(result); err != nil {
+		tm.mu.RLock()
+		strict := tm.strictOutput
+		tm.mu.RUnlock()
+		if strict {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidOutput, name, err)
+		}
+		tm.logger.Warn().Str("tool", name).Err(err).Msg("Tool result does not match its output schema")
+		return nil
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, result); err != nil {
+		return nil
+	}
+	callResult.StructuredContent = compact.Bytes()
+	return nil
+}
+
+// finishStructuredContent 使 structuredContent 与处理后的文本内容一致：
+// 个人信息按同样的规则掩码，文本内容被截断时省略 structuredContent，客户端通过截断资源读取完整结果
+func (tm *ToolManager) finishStructuredContent(name string, callResult *ToolCallResult) {
+	if callResult.StructuredContent == nil {
+		return
+	}
+	if callResult.Meta != nil && len(callResult.Meta.Truncated) > 0 {
+		callResult.StructuredContent = nil
+		return
+	}
+	masked := tm.pii().Mask(name, string(callResult.StructuredContent))
+	if !json.Valid([]byte(masked)) {
+		callResult.StructuredContent = nil
+		return
+	}
+	callResult.StructuredContent = json.RawMessage(masked)
+}
@@ -0,0 +1,243 @@
+package tools
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"Weave-Toolkit/config"
+)
+
+// PII 检测器名称
+const (
+	PIIEmail      = "email"
+	PIIPhone      = "phone"
+	PIICreditCard = "credit_card"
+	PIINationalID = "national_id"
+)
+
+// PIIFinding 结果中检测到的一类个人信息，以 _meta.pii 返回
+type PIIFinding struct {
+	Type   string `json:"type"`
+	Count  int    `json:"count"`
+	Masked bool   `json:"masked"` // 为 false 时内容原样返回
+}
+
+// piiDetector 一类个人信息的匹配规则
+type piiDetector struct {
+	name    string
+	pattern *regexp.Regexp
+	valid   func(match string) bool // 为空时所有匹配都有效
+	mask    func(match string) string
+}
+
+// piiDetectors 按优先级排列的检测器，重叠的匹配只保留优先级高的
+var piiDetectors = []piiDetector{
+	{
+		name:    PIIEmail,
+		pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		mask:    maskEmail,
+	},
+	{
+		// 美国社会安全号与中国居民身份证号
+		name:    PIINationalID,
+		pattern: regexp.MustCompile(`\b(?:\d{3}-\d{2}-\d{4}|\d{17}[\dXx])\b`),
+		valid:   validNationalID,
+		mask:    maskDigits,
+	},
+	{
+		name:    PIICreditCard,
+		pattern: regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		valid:   luhnValid,
+		mask:    maskDigits,
+	},
+	{
+		// 带分隔符的北美号码、E.164 号码与中国大陆手机号
+		name:    PIIPhone,
+		pattern: regexp.MustCompile(`(?:\+\d{1,3}[ .\-]?)?(?:\(\d{3}\)[ .\-]?|\b\d{3}[ .\-])\d{3}[ .\-]\d{4}\b|\+\d{8,15}\b|\b1[3-9]\d{9}\b`),
+		mask:    maskDigits,
+	},
+}
+
+// PIIFilter 在结果返回给客户端前检测并处理个人信息
+type PIIFilter struct {
+	detectors []piiDetector
+	action    string
+	tools     map[string]string
+}
+
+// NewPIIFilter 按配置创建检测器，未启用时返回 nil
+func NewPIIFilter(cfg config.PIIConfig) *PIIFilter {
+	if !cfg.Enabled {
+		return nil
+	}
+	f := &PIIFilter{action: cfg.Action, tools: cfg.Tools}
+	if f.action == "" {
+		f.action = config.PIIActionMask
+	}
+	for _, detector := range piiDetectors {
+		if len(cfg.Detectors) == 0 || slices.Contains(cfg.Detectors, detector.name) {
+			f.detectors = append(f.detectors, detector)
+		}
+	}
+	return f
+}
+
+// actionFor 工具的处理方式
+func (f *PIIFilter) actionFor(tool string) string {
+	if action, ok := f.tools[tool]; ok {
+		return action
+	}
+	return f.action
+}
+
+// Apply 原地处理结果中的文本内容，检测到个人信息时在 _meta.pii 中列出
+func (f *PIIFilter) Apply(tool string, result *ToolCallResult) {
+	if f == nil || result == nil {
+		return
+	}
+	action := f.actionFor(tool)
+	if action == config.PIIActionOff {
+		return
+	}
+	counts := make(map[string]int)
+	for i := range result.Content {
+		content := &result.Content[i]
+		if content.Type != "text" || content.Text == "" {
+			continue
+		}
+		matches := f.scan(content.Text)
+		for _, m := range matches {
+			counts[m.detector.name]++
+		}
+		if action == config.PIIActionMask && len(matches) > 0 {
+			content.Text = maskMatches(content.Text, matches)
+		}
+	}
+	if len(counts) == 0 {
+		return
+	}
+	if result.Meta == nil {
+		result.Meta = &ResultMeta{}
+	}
+	for _, detector := range f.detectors {
+		if count := counts[detector.name]; count > 0 {
+			result.Meta.PII = append(result.Meta.PII, PIIFinding{Type: detector.name, Count: count, Masked: action == config.PIIActionMask})
+		}
+	}
+}
+
+// Mask 返回掩码后的文本，工具的处理方式不是 mask 时原样返回
+//
+// 用于流式片段：跨片段的个人信息无法识别，最终结果仍会完整检测。
+func (f *PIIFilter) Mask(tool, text string) string {
+	if f == nil || text == "" || f.actionFor(tool) != config.PIIActionMask {
+		return text
+	}
+	if matches := f.scan(text); len(matches) > 0 {
+		return maskMatches(text, matches)
+	}
+	return text
+}
+
+// piiMatch 文本中的一处匹配
+type piiMatch struct {
+	start, end int
+	detector   *piiDetector
+}
+
+// scan 按优先级查找匹配，返回按位置排序且互不重叠的结果
+func (f *PIIFilter) scan(text string) []piiMatch {
+	var matches []piiMatch
+	for i := range f.detectors {
+		detector := &f.detectors[i]
+	next:
+		for _, loc := range detector.pattern.FindAllStringIndex(text, -1) {
+			if detector.valid != nil && !detector.valid(text[loc[0]:loc[1]]) {
+				continue
+			}
+			for _, m := range matches {
+				if loc[0] < m.end && m.start < loc[1] {
+					continue next
+				}
+			}
+			matches = append(matches, piiMatch{start: loc[0], end: loc[1], detector: detector})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+	return matches
+}
+
+// maskMatches 替换文本中的匹配
+func maskMatches(text string, matches []piiMatch) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	last := 0
+	for _, m := range matches {
+		b.WriteString(text[last:m.start])
+		b.WriteString(m.detector.mask(text[m.start:m.end]))
+		last = m.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// maskEmail 保留用户名首字符与域名，如 a***@example.com
+func maskEmail(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	return email[:1] + "***" + email[at:]
+}
+
+// maskDigits 保留分隔符与最后 4 位，其余字符替换为 *
+func maskDigits(s string) string {
+	keep := 4
+	out := []byte(s)
+	for i := len(out) - 1; i >= 0; i-- {
+		c := out[i]
+		if (c < '0' || c > '9') && c != 'X' && c != 'x' {
+			continue
+		}
+		if keep > 0 {
+			keep--
+			continue
+		}
+		out[i] = '*'
+	}
+	return string(out)
+}
+
+// luhnValid 信用卡号的 Luhn 校验
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// validNationalID 排除不会分配的社会安全号，并校验身份证号的校验位
+func validNationalID(s string) bool {
+	if len(s) == 11 {
+		area, group, serial := s[:3], s[4:6], s[7:]
+		return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+	}
+	weights := []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i, w := range weights {
+		sum += int(s[i]-'0') * w
+	}
+	return strings.ToUpper(s[17:]) == string("10X98765432"[sum%11])
+}
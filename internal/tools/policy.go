@@ -0,0 +1,364 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// ErrPolicyViolation 工具参数或结果违反内容安全策略
+var ErrPolicyViolation = werrors.New(werrors.KindUnauthorized, "blocked by content policy")
+
+// 策略相关的默认参数
+const (
+	defaultPolicyTimeout   = 5 * time.Second
+	maxPolicyAuditEntries  = 1000
+	maxPolicyResponseBytes = 64 << 10
+)
+
+// endpointPolicyRule 策略服务未给出规则名称或不可用时使用的名称
+const endpointPolicyRule = "endpoint"
+
+// PolicyFinding 一次策略命中，注释以 _meta.policy 返回
+type PolicyFinding struct {
+	Rule   string `json:"rule"`
+	Stage  string `json:"stage"` // arguments 或 result
+	Reason string `json:"reason,omitempty"`
+}
+
+// PolicyEngine 按内置规则与外部策略服务检查工具参数和结果
+type PolicyEngine struct {
+	rules    []policyRule
+	endpoint string
+	client   *http.Client
+	failOpen bool
+}
+
+// policyRule 编译后的内置规则
+type policyRule struct {
+	name     string
+	tools    []string
+	target   string
+	patterns []*regexp.Regexp
+	keywords []string
+	action   string
+}
+
+// NewPolicyEngine 编译策略规则，没有规则且未配置策略服务时返回 nil
+func NewPolicyEngine(cfg config.PolicyConfig) *PolicyEngine {
+	if len(cfg.Rules) == 0 && cfg.Endpoint == "" {
+		return nil
+	}
+	timeout := defaultPolicyTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	p := &PolicyEngine{
+		endpoint: cfg.Endpoint,
+		client:   &http.Client{Timeout: timeout},
+		failOpen: cfg.FailOpen,
+	}
+	for _, rule := range cfg.Rules {
+		compiled := policyRule{name: rule.Name, tools: rule.Tools, target: rule.Target, action: rule.Action}
+		if compiled.action == "" {
+			compiled.action = config.PolicyActionBlock
+		}
+		for _, pattern := range rule.Patterns {
+			if re, err := regexp.Compile(pattern); err == nil {
+				compiled.patterns = append(compiled.patterns, re)
+			}
+		}
+		for _, keyword := range rule.Keywords {
+			compiled.keywords = append(compiled.keywords, strings.ToLower(keyword))
+		}
+		p.rules = append(p.rules, compiled)
+	}
+	return p
+}
+
+// checkPartial 按结果阶段的内置拒绝规则检查一段流式输出，返回第一个命中
+func (p *PolicyEngine) checkPartial(tool, text string) *PolicyFinding {
+	for i := range p.rules {
+		rule := &p.rules[i]
+		if rule.action != config.PolicyActionBlock || rule.target == config.PolicyTargetArguments ||
+			(len(rule.tools) > 0 && !slices.Contains(rule.tools, tool)) {
+			continue
+		}
+		if match, ok := rule.match([]string{text}); ok {
+			return &PolicyFinding{Rule: rule.name, Stage: config.PolicyTargetResult, Reason: fmt.Sprintf("matched %q", match)}
+		}
+	}
+	return nil
+}
+
+// Check 检查一个阶段，返回拒绝调用的命中与其余注释
+//
+// 内置规则先于策略服务检查，命中拒绝规则后不再请求策略服务。err 为策略服务的错误，
+// 未配置 fail_open 时同时返回拒绝。
+func (p *PolicyEngine) Check(ctx context.Context, stage, tool string, args json.RawMessage, result *ToolCallResult) (blocked *PolicyFinding, annotations []PolicyFinding, err error) {
+	if p == nil {
+		return nil, nil, nil
+	}
+	var texts []string
+	if stage == config.PolicyTargetArguments {
+		texts = argumentStrings(args)
+	} else if result != nil {
+		for _, content := range result.Content {
+			if content.Type == "text" && content.Text != "" {
+				texts = append(texts, content.Text)
+			}
+		}
+	}
+	for i := range p.rules {
+		rule := &p.rules[i]
+		if (rule.target != "" && rule.target != stage) || (len(rule.tools) > 0 && !slices.Contains(rule.tools, tool)) {
+			continue
+		}
+		match, ok := rule.match(texts)
+		if !ok {
+			continue
+		}
+		finding := PolicyFinding{Rule: rule.name, Stage: stage, Reason: fmt.Sprintf("matched %q", match)}
+		if rule.action == config.PolicyActionBlock {
+			return &finding, annotations, nil
+		}
+		annotations = append(annotations, finding)
+	}
+	if p.endpoint == "" {
+		return nil, annotations, nil
+	}
+
+	decision, err := p.ask(ctx, stage, tool, args, result)
+	if err != nil {
+		if p.failOpen {
+			return nil, annotations, err
+		}
+		return &PolicyFinding{Rule: endpointPolicyRule, Stage: stage, Reason: "policy endpoint unavailable"}, annotations, err
+	}
+	if decision.Rule == "" {
+		decision.Rule = endpointPolicyRule
+	}
+	finding := PolicyFinding{Rule: decision.Rule, Stage: stage, Reason: decision.Reason}
+	switch decision.Action {
+	case config.PolicyActionBlock:
+		return &finding, annotations, nil
+	case config.PolicyActionAnnotate:
+		annotations = append(annotations, finding)
+	}
+	return nil, annotations, nil
+}
+
+// match 返回文本中第一处匹配的正则或关键字
+func (r *policyRule) match(texts []string) (string, bool) {
+	for _, text := range texts {
+		for _, re := range r.patterns {
+			if match := re.FindString(text); match != "" {
+				return match, true
+			}
+		}
+		if len(r.keywords) == 0 {
+			continue
+		}
+		lower := strings.ToLower(text)
+		for _, keyword := range r.keywords {
+			if strings.Contains(lower, keyword) {
+				return keyword, true
+			}
+		}
+	}
+	return "", false
+}
+
+// argumentStrings 参数中所有的对象成员名与字符串值，不是合法 JSON 时返回原文
+func argumentStrings(args json.RawMessage) []string {
+	var doc interface{}
+	if err := json.Unmarshal(args, &doc); err != nil {
+		return []string{string(args)}
+	}
+	var texts []string
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case string:
+			texts = append(texts, v)
+		case map[string]interface{}:
+			for key, member := range v {
+				texts = append(texts, key)
+				walk(member)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(doc)
+	return texts
+}
+
+// policyRequest 发送给策略服务的请求
+type policyRequest struct {
+	Stage     string          `json:"stage"`
+	Tool      string          `json:"tool"`
+	Tenant    string          `json:"tenant,omitempty"`
+	Client    string          `json:"client,omitempty"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Result    *ToolCallResult `json:"result,omitempty"`
+}
+
+// policyDecision 策略服务的决定，action 为 allow、block 或 annotate
+type policyDecision struct {
+	Action string `json:"action"`
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+}
+
+// ask 请求策略服务，非 2xx 响应与无法识别的 action 视为错误
+func (p *PolicyEngine) ask(ctx context.Context, stage, tool string, args json.RawMessage, result *ToolCallResult) (policyDecision, error) {
+	request := policyRequest{Stage: stage, Tool: tool, Client: ClientFromContext(ctx), Arguments: args, Result: result}
+	if tenant := TenantFromContext(ctx); tenant != nil {
+		request.Tenant = tenant.Name
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return policyDecision{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return policyDecision{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return policyDecision{}, fmt.Errorf("policy endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return policyDecision{}, fmt.Errorf("policy endpoint returned status %d", resp.StatusCode)
+	}
+	var decision policyDecision
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxPolicyResponseBytes)).Decode(&decision); err != nil {
+		return policyDecision{}, fmt.Errorf("invalid policy endpoint response: %v", err)
+	}
+	switch decision.Action {
+	case "", "allow", config.PolicyActionBlock, config.PolicyActionAnnotate:
+		return decision, nil
+	default:
+		return policyDecision{}, fmt.Errorf("policy endpoint returned unknown action %q", decision.Action)
+	}
+}
+
+// PolicyAuditEntry 一次被策略拒绝的调用
+type PolicyAuditEntry struct {
+	ID     string    `json:"id"`
+	Time   time.Time `json:"time"`
+	Tool   string    `json:"tool"`
+	Tenant string    `json:"tenant,omitempty"`
+	Client string    `json:"client,omitempty"`
+	PolicyFinding
+}
+
+// policyAudit 最近被拒绝的调用，超过上限时丢弃最早的记录
+type policyAudit struct {
+	mu      sync.Mutex
+	entries []PolicyAuditEntry
+	seq     int64
+}
+
+// add 追加一条审计记录
+func (a *policyAudit) add(entry PolicyAuditEntry) PolicyAuditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.seq++
+	entry.ID = "pa_" + strconv.FormatInt(a.seq, 10)
+	entry.Time = time.Now().UTC()
+	if len(a.entries) >= maxPolicyAuditEntries {
+		a.entries = append(a.entries[:0], a.entries[1:]...)
+	}
+	a.entries = append(a.entries, entry)
+	return entry
+}
+
+// PolicyAudit 最近被内容安全策略拒绝的调用
+func (tm *ToolManager) PolicyAudit() []PolicyAuditEntry {
+	tm.audit.mu.Lock()
+	defer tm.audit.mu.Unlock()
+	return append([]PolicyAuditEntry(nil), tm.audit.entries...)
+}
+
+// policies 当前的内容安全策略
+func (tm *ToolManager) policies() *PolicyEngine {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.policyEngine
+}
+
+// enforcePolicy 检查一个阶段，注释追加到 annotations；违反策略时记录审计并返回错误
+func (tm *ToolManager) enforcePolicy(ctx context.Context, stage, name string, args json.RawMessage, result *ToolCallResult, annotations *[]PolicyFinding) error {
+	blocked, found, err := tm.policies().Check(ctx, stage, name, args, result)
+	if err != nil {
+		tm.logger.Warn().Str("tool", name).Str("stage", stage).Err(err).Msg("Policy endpoint check failed")
+	}
+	*annotations = append(*annotations, found...)
+	if blocked == nil {
+		return nil
+	}
+	return tm.blockCall(ctx, stage, name, blocked)
+}
+
+// partialPolicy 返回流式片段的检查：片段命中结果阶段的内置拒绝规则时记录审计并返回错误；
+// 策略服务与注释规则只检查最终结果
+func (tm *ToolManager) partialPolicy(ctx context.Context, name string) func(content string) error {
+	engine := tm.policies()
+	if engine == nil {
+		return nil
+	}
+	return func(content string) error {
+		if blocked := engine.checkPartial(name, content); blocked != nil {
+			return tm.blockCall(ctx, config.PolicyTargetResult, name, blocked)
+		}
+		return nil
+	}
+}
+
+// blockCall 记录被策略拒绝的调用并返回拒绝错误
+func (tm *ToolManager) blockCall(ctx context.Context, stage, name string, blocked *PolicyFinding) error {
+	// 原因可能包含命中的内容，只以脱敏后的形式写入审计记录与日志，不返回给调用方
+	entry := PolicyAuditEntry{Tool: name, Client: ClientFromContext(ctx), PolicyFinding: *blocked}
+	entry.Reason = tm.redaction().Text(name, blocked.Reason)
+	if tenant := TenantFromContext(ctx); tenant != nil {
+		entry.Tenant = tenant.Name
+	}
+	entry = tm.audit.add(entry)
+	tm.logger.Warn().
+		Str("tool", name).
+		Str("stage", stage).
+		Str("rule", blocked.Rule).
+		Str("reason", entry.Reason).
+		Str("audit_id", entry.ID).
+		Msg("Tool call blocked by policy")
+	return fmt.Errorf("%w: rule %s", ErrPolicyViolation, blocked.Rule)
+}
+
+// annotatePolicy 将策略注释写入结果的 _meta.policy
+func annotatePolicy(result *ToolCallResult, annotations []PolicyFinding) {
+	if len(annotations) == 0 {
+		return
+	}
+	if result.Meta == nil {
+		result.Meta = &ResultMeta{}
+	}
+	result.Meta.Policy = append(result.Meta.Policy, annotations...)
+}
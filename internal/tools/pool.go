@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// defaultPoolSize 未配置 max_concurrent_calls 时的默认并发上限
+const defaultPoolSize = 100
+
+// WorkerPool 有界执行池
+//
+// 以信号量限制同时执行的工具数量，工具在调用方协程中执行，
+// 避免每次调用额外的协程切换；池满时调用方等待直到获得槽位或上下文取消。
+type WorkerPool struct {
+	slots   chan struct{}
+	waiting int64
+}
+
+// NewWorkerPool 创建执行池
+func NewWorkerPool(size int) *WorkerPool {
+	if size <= 0 {
+		size = defaultPoolSize
+	}
+	return &WorkerPool{slots: make(chan struct{}, size)}
+}
+
+// Acquire 获取执行槽位
+func (p *WorkerPool) Acquire(ctx context.Context) error {
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	atomic.AddInt64(&p.waiting, 1)
+	defer atomic.AddInt64(&p.waiting, -1)
+
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("worker pool saturated (capacity %d): %v", cap(p.slots), ctx.Err())
+	}
+}
+
+// Release 释放执行槽位
+func (p *WorkerPool) Release() {
+	<-p.slots
+}
+
+// Load 当前占用的槽位数、容量与等待中的调用数
+func (p *WorkerPool) Load() (inUse, capacity int, waiting int64) {
+	return len(p.slots), cap(p.slots), atomic.LoadInt64(&p.waiting)
+}
+
+// Stats 获取执行池统计信息
+func (p *WorkerPool) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"capacity": cap(p.slots),
+		"in_use":   len(p.slots),
+		"waiting":  atomic.LoadInt64(&p.waiting),
+	}
+}
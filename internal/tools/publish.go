@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+)
+
+// ResourcePublisher 资源发布者，由会话实现，工具通过它向当前会话发布资源
+type ResourcePublisher interface {
+	// Publish 发布资源并返回资源 URI，同名资源会被替换
+	Publish(name, mimeType string, data []byte) (string, error)
+}
+
+// publisherContextKey 资源发布者上下文键
+type publisherContextKey struct{}
+
+// WithPublisher 在上下文中设置当前会话的资源发布者
+func WithPublisher(ctx context.Context, publisher ResourcePublisher) context.Context {
+	return context.WithValue(ctx, publisherContextKey{}, publisher)
+}
+
+// PublishResource 向发起调用的会话发布资源
+//
+// 资源仅对该会话可见，占用计入会话内存配额；调用不属于任何会话时返回错误。
+func PublishResource(ctx context.Context, name, mimeType string, data []byte) (string, error) {
+	publisher, ok := ctx.Value(publisherContextKey{}).(ResourcePublisher)
+	if !ok || publisher == nil {
+		return "", fmt.Errorf("no session available to publish resource %q", name)
+	}
+	return publisher.Publish(name, mimeType, data)
+}
+
+// ResourceReader 资源读取者，由会话实现，工具通过它读取会话中已发布的资源
+type ResourceReader interface {
+	// ReadResource 按 URI 读取资源内容与 MIME 类型
+	ReadResource(uri string) ([]byte, string, error)
+}
+
+// readerContextKey 资源读取者上下文键
+type readerContextKey struct{}
+
+// WithResourceReader 在上下文中设置当前会话的资源读取者
+func WithResourceReader(ctx context.Context, reader ResourceReader) context.Context {
+	return context.WithValue(ctx, readerContextKey{}, reader)
+}
+
+// ReadSessionResource 读取发起调用的会话中发布的资源，调用不属于任何会话时返回错误
+func ReadSessionResource(ctx context.Context, uri string) ([]byte, string, error) {
+	reader, ok := ctx.Value(readerContextKey{}).(ResourceReader)
+	if !ok || reader == nil {
+		return nil, "", fmt.Errorf("no session available to read resource %s", uri)
+	}
+	return reader.ReadResource(uri)
+}
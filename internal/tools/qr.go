@@ -0,0 +1,465 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"strings"
+	"unicode/utf8"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/schema"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/aztec"
+	"github.com/makiuchi-d/gozxing/datamatrix"
+	multiqr "github.com/makiuchi-d/gozxing/multi/qrcode"
+	"github.com/makiuchi-d/gozxing/oned"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// qr 默认限制
+const (
+	defaultQRMaxPayloadBytes = 2048
+	defaultQRMaxImageBytes   = 10 << 20
+	defaultQRMaxPixels       = 25000000
+	defaultQRSize            = 256
+	maxQRSize                = 4096
+)
+
+// 条码格式
+const (
+	QRFormatQRCode     = "qr_code"
+	QRFormatDataMatrix = "data_matrix"
+	QRFormatAztec      = "aztec"
+	QRFormatCode128    = "code_128"
+	QRFormatCode39     = "code_39"
+	QRFormatCode93     = "code_93"
+	QRFormatEAN13      = "ean_13"
+	QRFormatEAN8       = "ean_8"
+	QRFormatUPCA       = "upc_a"
+	QRFormatUPCE       = "upc_e"
+	QRFormatITF        = "itf"
+	QRFormatCodabar    = "codabar"
+)
+
+// qrFormats 格式名称与 gozxing 格式的对应关系
+var qrFormats = map[string]gozxing.BarcodeFormat{
+	QRFormatQRCode:     gozxing.BarcodeFormat_QR_CODE,
+	QRFormatDataMatrix: gozxing.BarcodeFormat_DATA_MATRIX,
+	QRFormatAztec:      gozxing.BarcodeFormat_AZTEC,
+	QRFormatCode128:    gozxing.BarcodeFormat_CODE_128,
+	QRFormatCode39:     gozxing.BarcodeFormat_CODE_39,
+	QRFormatCode93:     gozxing.BarcodeFormat_CODE_93,
+	QRFormatEAN13:      gozxing.BarcodeFormat_EAN_13,
+	QRFormatEAN8:       gozxing.BarcodeFormat_EAN_8,
+	QRFormatUPCA:       gozxing.BarcodeFormat_UPC_A,
+	QRFormatUPCE:       gozxing.BarcodeFormat_UPC_E,
+	QRFormatITF:        gozxing.BarcodeFormat_ITF,
+	QRFormatCodabar:    gozxing.BarcodeFormat_CODABAR,
+}
+
+// qrFormatOrder 解码时依次尝试的格式，二维码在前
+var qrFormatOrder = []string{
+	QRFormatQRCode, QRFormatDataMatrix, QRFormatAztec,
+	QRFormatCode128, QRFormatEAN13, QRFormatEAN8, QRFormatUPCA, QRFormatUPCE,
+	QRFormatCode39, QRFormatCode93, QRFormatITF, QRFormatCodabar,
+}
+
+// ErrQRNotFound 图片中没有可识别的条码
+var ErrQRNotFound = werrors.New(werrors.KindNotFound, "no barcode found in image")
+
+// QRTool 二维码与条码工具
+//
+// generate 将文本编码为 QR 码、Data Matrix 或一维条码，以 PNG 图片内容返回；decode 识别图片中的条码，
+// 图片中有多个 QR 码时全部返回。编码内容与图片的大小受配置限制，解码前先检查图片尺寸，避免解压炸弹。
+type QRTool struct {
+	config config.QRConfig
+}
+
+// QRArgs 条码参数
+type QRArgs struct {
+	Op              string `json:"op"`               // generate, decode
+	Data            string `json:"data"`             // generate：编码内容
+	Format          string `json:"format"`           // 条码格式，generate 默认 qr_code，decode 默认尝试全部格式
+	Size            int    `json:"size"`             // generate：图片宽度（像素）
+	Height          int    `json:"height"`           // generate：图片高度，二维码默认与宽度相同，一维条码默认为宽度的一半
+	ErrorCorrection string `json:"error_correction"` // generate：QR 码纠错级别 L、M、Q、H
+	Margin          *int   `json:"margin"`           // generate：静区宽度（模块数）
+	Image           string `json:"image"`            // decode：base64、data URL 或 blob:// URI 形式的 PNG、JPEG、GIF 图片
+	TryHarder       bool   `json:"try_harder"`       // decode：以更慢的速度提高识别率
+}
+
+// QRResult 条码结果
+type QRResult struct {
+	Op       string         `json:"op"`
+	Format   string         `json:"format,omitempty"`
+	MimeType string         `json:"mime_type,omitempty"`
+	Width    int            `json:"width,omitempty"`
+	Height   int            `json:"height,omitempty"`
+	Data     string         `json:"data,omitempty"`
+	Codes    []QRDecodeInfo `json:"codes,omitempty"`
+}
+
+// QRDecodeInfo 识别出的条码
+type QRDecodeInfo struct {
+	Format string   `json:"format"`
+	Text   string   `json:"text"`
+	Points [][2]int `json:"points,omitempty"` // 定位点坐标
+}
+
+// NewQRTool 创建条码工具
+func NewQRTool(cfg config.QRConfig) *QRTool {
+	if cfg.MaxPayloadBytes <= 0 {
+		cfg.MaxPayloadBytes = defaultQRMaxPayloadBytes
+	}
+	if cfg.MaxImageBytes <= 0 {
+		cfg.MaxImageBytes = defaultQRMaxImageBytes
+	}
+	if cfg.MaxPixels <= 0 {
+		cfg.MaxPixels = defaultQRMaxPixels
+	}
+	return &QRTool{config: cfg}
+}
+
+func (qt *QRTool) Name() string {
+	return "qr"
+}
+
+func (qt *QRTool) Description() string {
+	return "Generate QR codes and barcodes as PNG images, or decode them from an image"
+}
+
+func (qt *QRTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (qt *QRTool) InputSchema() *schema.Schema {
+	formats := make([]interface{}, len(qrFormatOrder))
+	for i, format := range qrFormatOrder {
+		formats[i] = format
+	}
+	return schema.Object(map[string]*schema.Schema{
+		"op":               {Type: schema.TypeString, Description: "Operation", Enum: []interface{}{"generate", "decode"}},
+		"data":             {Type: schema.TypeString, Description: "Text to encode (generate)", MinLength: schema.Int(1)},
+		"format":           {Type: schema.TypeString, Description: "Barcode format; generate defaults to qr_code, decode tries all formats", Enum: formats},
+		"size":             {Type: schema.TypeInteger, Description: "Image width in pixels (generate)", Minimum: schema.Float(16), Maximum: schema.Float(maxQRSize), Default: defaultQRSize},
+		"height":           {Type: schema.TypeInteger, Description: "Image height in pixels (generate)", Minimum: schema.Float(16), Maximum: schema.Float(maxQRSize)},
+		"error_correction": {Type: schema.TypeString, Description: "QR code error correction level (generate)", Enum: []interface{}{"L", "M", "Q", "H"}, Default: "M"},
+		"margin":           {Type: schema.TypeInteger, Description: "Quiet zone width in modules (generate)", Minimum: schema.Float(0), Maximum: schema.Float(32)},
+		"image":            {Type: schema.TypeString, Description: "PNG, JPEG or GIF image as base64, a data URL or an uploaded blob:// URI (decode)", MinLength: schema.Int(1)},
+		"try_harder":       {Type: schema.TypeBoolean, Description: "Spend more time looking for barcodes (decode)", Default: false},
+	}, "op").Closed()
+}
+
+func (qt *QRTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var qrArgs QRArgs
+	if err := json.Unmarshal(args, &qrArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	if qrArgs.Format != "" {
+		if _, ok := qrFormats[qrArgs.Format]; !ok {
+			return nil, fmt.Errorf("unsupported format: %s", qrArgs.Format)
+		}
+	}
+
+	var result *QRResult
+	var err error
+	switch qrArgs.Op {
+	case "generate":
+		result, err = qt.generate(qrArgs)
+	case "decode":
+		result, err = qt.decode(ctx, qrArgs)
+	default:
+		return nil, fmt.Errorf("unsupported op: %s", qrArgs.Op)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// generate 编码为 PNG 图片
+func (qt *QRTool) generate(args QRArgs) (*QRResult, error) {
+	if args.Data == "" {
+		return nil, fmt.Errorf("data is required for generate")
+	}
+	if len(args.Data) > qt.config.MaxPayloadBytes {
+		return nil, fmt.Errorf("data exceeds %d bytes", qt.config.MaxPayloadBytes)
+	}
+	if !utf8.ValidString(args.Data) {
+		return nil, fmt.Errorf("data must be valid UTF-8")
+	}
+	if args.Format == "" {
+		args.Format = QRFormatQRCode
+	}
+	if args.Size <= 0 {
+		args.Size = defaultQRSize
+	}
+	if args.Size > maxQRSize || args.Height > maxQRSize {
+		return nil, fmt.Errorf("size must not exceed %d pixels", maxQRSize)
+	}
+	twoDimensional := args.Format == QRFormatQRCode || args.Format == QRFormatDataMatrix || args.Format == QRFormatAztec
+	if args.Height <= 0 {
+		args.Height = args.Size
+		if !twoDimensional {
+			args.Height = args.Size / 2
+		}
+	}
+
+	hints := make(map[gozxing.EncodeHintType]interface{})
+	if args.Margin != nil {
+		hints[gozxing.EncodeHintType_MARGIN] = *args.Margin
+	}
+	var writer gozxing.Writer
+	switch args.Format {
+	case QRFormatQRCode:
+		level := args.ErrorCorrection
+		if level == "" {
+			level = "M"
+		}
+		hints[gozxing.EncodeHintType_ERROR_CORRECTION] = strings.ToUpper(level)
+		// 默认按 ISO-8859-1 编码，非 ASCII 内容以 UTF-8 编码并写入 ECI 标记
+		if !isASCII(args.Data) {
+			hints[gozxing.EncodeHintType_CHARACTER_SET] = "UTF-8"
+		}
+		writer = qrcode.NewQRCodeWriter()
+	case QRFormatDataMatrix:
+		// 由 encodeDataMatrix 编码
+	case QRFormatCode128:
+		writer = oned.NewCode128Writer()
+	case QRFormatCode39:
+		writer = oned.NewCode39Writer()
+	case QRFormatCode93:
+		writer = oned.NewCode93Writer()
+	case QRFormatEAN13:
+		writer = oned.NewEAN13Writer()
+	case QRFormatEAN8:
+		writer = oned.NewEAN8Writer()
+	case QRFormatUPCA:
+		writer = oned.NewUPCAWriter()
+	case QRFormatUPCE:
+		writer = oned.NewUPCEWriter()
+	case QRFormatITF:
+		writer = oned.NewITFWriter()
+	case QRFormatCodabar:
+		writer = oned.NewCodaBarWriter()
+	default:
+		return nil, fmt.Errorf("generating %s is not supported", args.Format)
+	}
+
+	var matrix *gozxing.BitMatrix
+	var err error
+	if args.Format == QRFormatDataMatrix {
+		matrix, err = encodeDataMatrix(args.Data, args.Size, args.Height, args.Margin)
+	} else {
+		matrix, err = writer.Encode(args.Data, qrFormats[args.Format], args.Size, args.Height, hints)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode data as %s: %v", args.Format, err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, matrix); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %v", err)
+	}
+	return &QRResult{
+		Op:       "generate",
+		Format:   args.Format,
+		MimeType: "image/png",
+		Width:    matrix.GetWidth(),
+		Height:   matrix.GetHeight(),
+		Data:     base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
+
+// encodeDataMatrix 编码 Data Matrix 并加上静区
+//
+// gozxing 的 Data Matrix 编码器不支持 margin，输出的图片没有静区，识别时无法定位；
+// 这里按模块大小编码后，在四周留出 margin 个模块（默认 1 个）再缩放到目标尺寸。
+func encodeDataMatrix(data string, width, height int, margin *int) (*gozxing.BitMatrix, error) {
+	symbol, err := datamatrix.NewDataMatrixWriter().Encode(data, gozxing.BarcodeFormat_DATA_MATRIX, 0, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	quiet := 1
+	if margin != nil {
+		quiet = *margin
+	}
+	modulesX := symbol.GetWidth() + 2*quiet
+	modulesY := symbol.GetHeight() + 2*quiet
+	scale := min(width/modulesX, height/modulesY)
+	if scale < 1 {
+		return nil, fmt.Errorf("size is too small for %dx%d modules", modulesX, modulesY)
+	}
+	matrix, err := gozxing.NewBitMatrix(width, height)
+	if err != nil {
+		return nil, err
+	}
+	left := (width - symbol.GetWidth()*scale) / 2
+	top := (height - symbol.GetHeight()*scale) / 2
+	for y := 0; y < symbol.GetHeight(); y++ {
+		for x := 0; x < symbol.GetWidth(); x++ {
+			if symbol.Get(x, y) {
+				if err := matrix.SetRegion(left+x*scale, top+y*scale, scale, scale); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return matrix, nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// imageData 解析 decode 的图片参数：上传的 blob:// 文件、base64 或 data URL
+func (qt *QRTool) imageData(ctx context.Context, image string) ([]byte, error) {
+	if IsBlobURI(image) {
+		data, _, err := ReadBlob(ctx, image, int64(qt.config.MaxImageBytes))
+		return data, err
+	}
+	encoded := image
+	if strings.HasPrefix(encoded, "data:") {
+		comma := strings.IndexByte(encoded, ',')
+		if comma < 0 || !strings.HasSuffix(encoded[:comma], ";base64") {
+			return nil, fmt.Errorf("image data URL must be base64 encoded")
+		}
+		encoded = encoded[comma+1:]
+	}
+	if base64.StdEncoding.DecodedLen(len(encoded)) > qt.config.MaxImageBytes {
+		return nil, fmt.Errorf("image exceeds %d bytes", qt.config.MaxImageBytes)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 image: %v", err)
+	}
+	return data, nil
+}
+
+// decode 识别图片中的条码
+func (qt *QRTool) decode(ctx context.Context, args QRArgs) (*QRResult, error) {
+	if args.Image == "" {
+		return nil, fmt.Errorf("image is required for decode")
+	}
+	data, err := qt.imageData(ctx, args.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported image: %v", err)
+	}
+	if cfg.Width*cfg.Height > qt.config.MaxPixels {
+		return nil, fmt.Errorf("image has more than %d pixels", qt.config.MaxPixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported image: %v", err)
+	}
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %v", err)
+	}
+
+	hints := make(map[gozxing.DecodeHintType]interface{})
+	if args.TryHarder {
+		hints[gozxing.DecodeHintType_TRY_HARDER] = true
+	}
+	formats := qrFormatOrder
+	if args.Format != "" {
+		formats = []string{args.Format}
+	}
+
+	result := &QRResult{Op: "decode"}
+	for _, format := range formats {
+		if format == QRFormatQRCode {
+			// 一张图片中可能有多个 QR 码
+			found, err := multiqr.NewQRCodeMultiReader().DecodeMultiple(bitmap, hints)
+			if err == nil {
+				for _, r := range found {
+					result.Codes = append(result.Codes, qrDecodeInfo(format, r))
+				}
+				return result, nil
+			}
+			continue
+		}
+		found, err := qrReader(format, hints).Decode(bitmap, hints)
+		if err == nil {
+			result.Codes = append(result.Codes, qrDecodeInfo(format, found))
+			return result, nil
+		}
+	}
+	return nil, ErrQRNotFound
+}
+
+// qrReader 创建指定格式的解码器
+func qrReader(format string, hints map[gozxing.DecodeHintType]interface{}) gozxing.Reader {
+	switch format {
+	case QRFormatDataMatrix:
+		return datamatrix.NewDataMatrixReader()
+	case QRFormatAztec:
+		return aztec.NewAztecReader()
+	case QRFormatCode128:
+		return oned.NewCode128Reader()
+	case QRFormatEAN13:
+		return oned.NewEAN13Reader()
+	case QRFormatEAN8:
+		return oned.NewEAN8Reader()
+	case QRFormatUPCA:
+		return oned.NewUPCAReader()
+	case QRFormatUPCE:
+		return oned.NewUPCEReader()
+	case QRFormatCode39:
+		return oned.NewCode39Reader()
+	case QRFormatCode93:
+		return oned.NewCode93Reader()
+	case QRFormatITF:
+		return oned.NewITFReader()
+	case QRFormatCodabar:
+		return oned.NewCodaBarReader()
+	}
+	return qrcode.NewQRCodeReader()
+}
+
+func qrDecodeInfo(format string, r *gozxing.Result) QRDecodeInfo {
+	info := QRDecodeInfo{Format: format, Text: r.GetText()}
+	for _, point := range r.GetResultPoints() {
+		info.Points = append(info.Points, [2]int{int(point.GetX()), int(point.GetY())})
+	}
+	return info
+}
+
+// ResultContent generate 的结果以图片内容返回，并附带不含图片数据的文本摘要；decode 的结果按文本返回
+func (qt *QRTool) ResultContent(result json.RawMessage) ([]ToolCallContent, error) {
+	var qrResult QRResult
+	if err := json.Unmarshal(result, &qrResult); err != nil {
+		return nil, err
+	}
+	if qrResult.Data == "" {
+		return nil, nil
+	}
+	image := qrResult.Data
+	qrResult.Data = ""
+	summary, err := json.Marshal(qrResult)
+	if err != nil {
+		return nil, err
+	}
+	return []ToolCallContent{
+		{Type: "image", Data: image, MimeType: qrResult.MimeType},
+		{Type: "text", Text: string(summary)},
+	}, nil
+}
@@ -0,0 +1,738 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/chunk"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/llm"
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/internal/schema"
+	"Weave-Toolkit/internal/vector"
+)
+
+// rag 工具默认参数
+const (
+	RAGURIPrefix               = "weave://rag/"
+	defaultRAGCorpus           = "default"
+	defaultRAGChunkSize        = 1000
+	defaultRAGChunkOverlap     = 100
+	defaultRAGTopK             = 4
+	defaultRAGMaxDocumentBytes = 1 << 20
+	defaultRAGMaxCorpora       = 16
+	defaultRAGMaxChunks        = 20000
+	maxRAGTopK                 = 50
+)
+
+// ragSystemPrompt 生成回答时的系统提示
+const ragSystemPrompt = "Answer the question using only the numbered context passages. Cite the passages you use as [n]. If the context does not contain the answer, say that you don't know."
+
+// RAGDocument 语料库中的文档
+type RAGDocument struct {
+	ID         string            `json:"id"`
+	Title      string            `json:"title,omitempty"`
+	Source     string            `json:"source,omitempty"` // 导入时的资源 URI
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Chunks     int               `json:"chunks"`
+	Chars      int               `json:"chars"`
+	IngestedAt time.Time         `json:"ingested_at"`
+	Text       string            `json:"text,omitempty"`
+}
+
+// ragStore rag_ingest 与 rag_query 共享的语料库：片段向量保存在向量索引中（每个语料库一个集合），
+// 文档原文与概要保存在 documents 中
+type ragStore struct {
+	config config.RAGConfig
+
+	mu        sync.Mutex
+	index     *vector.Index
+	documents map[string]map[string]*RAGDocument // 语料库 -> 文档 ID -> 文档
+	path      string                             // 文档快照文件，为空时不持久化
+}
+
+// load 首次使用时创建索引并加载快照，失败时下次调用重试；调用方需持有锁
+func (rs *ragStore) load() error {
+	if rs.index != nil {
+		return nil
+	}
+
+	var indexPath string
+	documents := make(map[string]map[string]*RAGDocument)
+	if rs.config.StoreDir != "" {
+		dir, err := platform.NormalizePath(rs.config.StoreDir)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create rag store directory: %v", err)
+		}
+		indexPath = filepath.Join(dir, "index.json")
+		rs.path = filepath.Join(dir, "documents.json")
+
+		data, err := os.ReadFile(rs.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read rag documents: %v", err)
+		}
+		if err == nil {
+			if err := json.Unmarshal(data, &documents); err != nil {
+				return fmt.Errorf("invalid rag documents file: %v", err)
+			}
+		}
+	}
+
+	index, err := vector.NewIndex(indexPath, vector.Limits{MaxCollections: rs.config.MaxCorpora, MaxDocuments: rs.config.MaxChunks})
+	if err != nil {
+		return err
+	}
+	rs.index = index
+	rs.documents = documents
+	return nil
+}
+
+// save 写入文档快照，调用方需持有锁
+func (rs *ragStore) save() error {
+	if rs.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(rs.documents)
+	if err != nil {
+		return err
+	}
+	tmp := rs.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to persist rag documents: %v", err)
+	}
+	if err := os.Rename(tmp, rs.path); err != nil {
+		return fmt.Errorf("failed to persist rag documents: %v", err)
+	}
+	return nil
+}
+
+// put 写入文档的片段向量并替换旧版本多出的片段
+func (rs *ragStore) put(corpus string, doc *RAGDocument, chunks []chunk.Chunk, vectors [][]float64) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if err := rs.load(); err != nil {
+		return err
+	}
+
+	docs := make([]vector.Document, len(chunks))
+	for i, c := range chunks {
+		metadata := map[string]string{"document_id": doc.ID, "chunk": strconv.Itoa(c.Index)}
+		for key, value := range doc.Metadata {
+			if _, reserved := metadata[key]; !reserved {
+				metadata[key] = value
+			}
+		}
+		embedding := make([]float32, len(vectors[i]))
+		for j, x := range vectors[i] {
+			embedding[j] = float32(x)
+		}
+		docs[i] = vector.Document{ID: ragChunkID(doc.ID, c.Index), Text: c.Text, Metadata: metadata, Vector: embedding}
+	}
+	if err := rs.index.Upsert(corpus, docs); err != nil {
+		return err
+	}
+
+	if previous, exists := rs.documents[corpus][doc.ID]; exists && previous.Chunks > len(chunks) {
+		stale := make([]string, 0, previous.Chunks-len(chunks))
+		for i := len(chunks); i < previous.Chunks; i++ {
+			stale = append(stale, ragChunkID(doc.ID, i))
+		}
+		if _, err := rs.index.Delete(corpus, stale); err != nil {
+			return err
+		}
+	}
+
+	if rs.documents[corpus] == nil {
+		rs.documents[corpus] = make(map[string]*RAGDocument)
+	}
+	rs.documents[corpus][doc.ID] = doc
+	return rs.save()
+}
+
+// remove 删除文档及其片段，返回实际删除的文档数
+func (rs *ragStore) remove(corpus string, ids []string) (int, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if err := rs.load(); err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, id := range ids {
+		doc, exists := rs.documents[corpus][id]
+		if !exists {
+			continue
+		}
+		chunkIDs := make([]string, doc.Chunks)
+		for i := range chunkIDs {
+			chunkIDs[i] = ragChunkID(id, i)
+		}
+		if _, err := rs.index.Delete(corpus, chunkIDs); err != nil && !errors.Is(err, vector.ErrCollectionNotFound) {
+			return removed, err
+		}
+		delete(rs.documents[corpus], id)
+		removed++
+	}
+	if len(rs.documents[corpus]) == 0 && rs.documents[corpus] != nil {
+		delete(rs.documents, corpus)
+		if err := rs.index.Drop(corpus); err != nil && !errors.Is(err, vector.ErrCollectionNotFound) {
+			return removed, err
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, rs.save()
+}
+
+// search 检索语料库，返回片段及其所属文档
+func (rs *ragStore) search(corpus string, q vector.Query) ([]vector.Match, map[string]*RAGDocument, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if err := rs.load(); err != nil {
+		return nil, nil, err
+	}
+	if _, exists := rs.documents[corpus]; !exists {
+		return nil, nil, fmt.Errorf("%w: %s", vector.ErrCollectionNotFound, corpus)
+	}
+
+	matches, err := rs.index.Search(corpus, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	docs := make(map[string]*RAGDocument)
+	for _, match := range matches {
+		id := match.Metadata["document_id"]
+		if doc, exists := rs.documents[corpus][id]; exists {
+			docs[id] = doc
+		}
+	}
+	return matches, docs, nil
+}
+
+// snapshot 返回语料库的文档列表（不含原文），按 ID 排序
+func (rs *ragStore) snapshot() (map[string][]RAGDocument, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if err := rs.load(); err != nil {
+		return nil, err
+	}
+
+	corpora := make(map[string][]RAGDocument, len(rs.documents))
+	for corpus, docs := range rs.documents {
+		list := make([]RAGDocument, 0, len(docs))
+		for _, doc := range docs {
+			summary := *doc
+			summary.Text = ""
+			list = append(list, summary)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+		corpora[corpus] = list
+	}
+	return corpora, nil
+}
+
+// document 获取文档原文
+func (rs *ragStore) document(corpus, id string) (*RAGDocument, bool, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if err := rs.load(); err != nil {
+		return nil, false, err
+	}
+	doc, exists := rs.documents[corpus][id]
+	return doc, exists, nil
+}
+
+func ragChunkID(documentID string, index int) string {
+	return documentID + "#" + strconv.Itoa(index)
+}
+
+// ragCorpusURI 语料库资源 URI，文档 ID 为空时为文档列表
+func ragCorpusURI(corpus, documentID string) string {
+	uri := RAGURIPrefix + url.PathEscape(corpus)
+	if documentID != "" {
+		uri += "/" + url.PathEscape(documentID)
+	}
+	return uri
+}
+
+// RAGIngestTool 语料导入工具
+//
+// 将直接上传的文本或会话中发布的资源切分为片段，通过 embeddings 工具向量化后写入语料库；
+// 同一 ID 的文档重新导入时替换旧版本。语料库以 weave://rag/ 资源向所有客户端公开。
+type RAGIngestTool struct {
+	store      *ragStore
+	embeddings *EmbeddingsTool
+}
+
+// RAGIngestDocument 待导入的文档，text 与 uri 二选一
+type RAGIngestDocument struct {
+	ID       string            `json:"id"`
+	Title    string            `json:"title"`
+	Text     string            `json:"text"`
+	URI      string            `json:"uri"` // 会话资源 URI（session://resources/...）
+	Metadata map[string]string `json:"metadata"`
+}
+
+// RAGIngestArgs 导入参数
+type RAGIngestArgs struct {
+	Corpus       string              `json:"corpus"`
+	Documents    []RAGIngestDocument `json:"documents"`
+	Remove       []string            `json:"remove"` // 要删除的文档 ID
+	ChunkSize    int                 `json:"chunk_size"`
+	ChunkOverlap *int                `json:"chunk_overlap"`
+}
+
+// RAGIngestResult 导入结果
+type RAGIngestResult struct {
+	Corpus    string             `json:"corpus"`
+	Documents []RAGIngestSummary `json:"documents,omitempty"`
+	Chunks    int                `json:"chunks"`
+	Removed   int                `json:"removed,omitempty"`
+	URI       string             `json:"uri"`
+}
+
+// RAGIngestSummary 单个文档的导入结果
+type RAGIngestSummary struct {
+	ID     string `json:"id"`
+	Chunks int    `json:"chunks"`
+	URI    string `json:"uri"`
+}
+
+// RAGQueryTool 语料检索问答工具
+//
+// 按问题检索语料库中最相关的片段，并将片段作为上下文交给 llm 工具生成带引用的回答；
+// answer 为 false 时只返回检索结果。流式调用时逐段推送回答。
+type RAGQueryTool struct {
+	store      *ragStore
+	embeddings *EmbeddingsTool
+	llm        *LLMTool
+}
+
+// RAGQueryArgs 问答参数
+type RAGQueryArgs struct {
+	Corpus   string            `json:"corpus"`
+	Question string            `json:"question"`
+	TopK     int               `json:"top_k"`
+	MinScore float64           `json:"min_score"`
+	Filter   map[string]string `json:"filter"`
+	Answer   *bool             `json:"answer"`
+	Provider string            `json:"provider"` // 生成回答的服务，覆盖配置
+	Model    string            `json:"model"`
+}
+
+// RAGSource 检索到的片段
+type RAGSource struct {
+	N          int               `json:"n"` // 回答中引用的编号
+	DocumentID string            `json:"document_id"`
+	Title      string            `json:"title,omitempty"`
+	Chunk      int               `json:"chunk"`
+	Score      float64           `json:"score"`
+	Text       string            `json:"text"`
+	URI        string            `json:"uri"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// RAGQueryResult 问答结果
+type RAGQueryResult struct {
+	Corpus  string      `json:"corpus"`
+	Answer  string      `json:"answer,omitempty"`
+	Model   string      `json:"model,omitempty"`
+	Usage   *llm.Usage  `json:"usage,omitempty"`
+	Sources []RAGSource `json:"sources"`
+}
+
+// NewRAGTools 创建共享同一语料库的导入与问答工具，语料库在首次使用时创建或加载
+func NewRAGTools(cfg config.RAGConfig, embeddings *EmbeddingsTool, llmTool *LLMTool) (*RAGIngestTool, *RAGQueryTool) {
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = defaultRAGChunkSize
+	}
+	if cfg.ChunkOverlap < 0 || cfg.ChunkOverlap >= cfg.ChunkSize {
+		cfg.ChunkOverlap = 0
+	} else if cfg.ChunkOverlap == 0 {
+		cfg.ChunkOverlap = min(defaultRAGChunkOverlap, cfg.ChunkSize/10)
+	}
+	if cfg.TopK <= 0 {
+		cfg.TopK = defaultRAGTopK
+	}
+	if cfg.MaxDocumentBytes <= 0 {
+		cfg.MaxDocumentBytes = defaultRAGMaxDocumentBytes
+	}
+	if cfg.MaxCorpora <= 0 {
+		cfg.MaxCorpora = defaultRAGMaxCorpora
+	}
+	if cfg.MaxChunks <= 0 {
+		cfg.MaxChunks = defaultRAGMaxChunks
+	}
+
+	store := &ragStore{config: cfg}
+	return &RAGIngestTool{store: store, embeddings: embeddings},
+		&RAGQueryTool{store: store, embeddings: embeddings, llm: llmTool}
+}
+
+func (rt *RAGIngestTool) Name() string {
+	return "rag_ingest"
+}
+
+func (rt *RAGIngestTool) Description() string {
+	return "Chunk, embed and store documents (inline text or session resources) in a retrieval corpus, or remove documents from it"
+}
+
+func (rt *RAGIngestTool) Category() ToolCategory {
+	return CategoryAI
+}
+
+func (rt *RAGIngestTool) Tags() []string {
+	return []string{TagNetwork, TagLLM, TagSlow}
+}
+
+func (rt *RAGIngestTool) InputSchema() *schema.Schema {
+	document := schema.Object(map[string]*schema.Schema{
+		"id":       {Type: schema.TypeString, Description: "Document ID, defaults to uri", MinLength: schema.Int(1), MaxLength: schema.Int(maxVectorIDLength)},
+		"title":    {Type: schema.TypeString, Description: "Document title"},
+		"text":     {Type: schema.TypeString, Description: "Document text", MinLength: schema.Int(1)},
+		"uri":      {Type: schema.TypeString, Description: "Session resource to ingest instead of text", MinLength: schema.Int(1)},
+		"metadata": {Type: schema.TypeObject, Description: "String metadata copied to every chunk, usable as a query filter"},
+	}).Closed()
+
+	return schema.Object(map[string]*schema.Schema{
+		"corpus":        {Type: schema.TypeString, Description: "Corpus name", Default: defaultRAGCorpus, MinLength: schema.Int(1)},
+		"documents":     {Type: schema.TypeArray, Description: "Documents to add or replace", Items: document, MinItems: schema.Int(1)},
+		"remove":        {Type: schema.TypeArray, Description: "Document IDs to remove", Items: &schema.Schema{Type: schema.TypeString}, MinItems: schema.Int(1)},
+		"chunk_size":    {Type: schema.TypeInteger, Description: "Maximum characters per chunk", Minimum: schema.Float(50)},
+		"chunk_overlap": {Type: schema.TypeInteger, Description: "Characters shared by adjacent chunks", Minimum: schema.Float(0)},
+	}).Closed()
+}
+
+func (rt *RAGIngestTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var ingestArgs RAGIngestArgs
+	if err := json.Unmarshal(args, &ingestArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	if len(ingestArgs.Documents) == 0 && len(ingestArgs.Remove) == 0 {
+		return nil, fmt.Errorf("documents or remove is required")
+	}
+	corpus := ingestArgs.Corpus
+	if corpus == "" {
+		corpus = defaultRAGCorpus
+	}
+
+	opts := chunk.Options{Size: rt.store.config.ChunkSize, Overlap: rt.store.config.ChunkOverlap}
+	if ingestArgs.ChunkSize > 0 {
+		opts.Size = ingestArgs.ChunkSize
+	}
+	if ingestArgs.ChunkOverlap != nil {
+		opts.Overlap = *ingestArgs.ChunkOverlap
+	}
+	if opts.Overlap >= opts.Size {
+		return nil, fmt.Errorf("chunk_overlap must be smaller than chunk_size")
+	}
+
+	result := RAGIngestResult{Corpus: corpus, URI: ragCorpusURI(corpus, "")}
+	if len(ingestArgs.Remove) > 0 {
+		removed, err := rt.store.remove(corpus, ingestArgs.Remove)
+		if err != nil {
+			return nil, err
+		}
+		result.Removed = removed
+	}
+
+	progress := ProgressFromContext(ctx)
+	for i, input := range ingestArgs.Documents {
+		doc, err := rt.resolve(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %v", i, err)
+		}
+		chunks := chunk.Split(doc.Text, opts)
+		if len(chunks) == 0 {
+			return nil, fmt.Errorf("document %s: no text to ingest", doc.ID)
+		}
+
+		texts := make([]string, len(chunks))
+		for j, c := range chunks {
+			texts[j] = c.Text
+		}
+		vectors, err := rt.embeddings.EmbedAll(ctx, rt.store.config.EmbeddingsProvider, rt.store.config.EmbeddingsModel, texts)
+		if err != nil {
+			return nil, fmt.Errorf("document %s: %w", doc.ID, err)
+		}
+
+		doc.Chunks = len(chunks)
+		if err := rt.store.put(corpus, doc, chunks, vectors); err != nil {
+			return nil, fmt.Errorf("document %s: %w", doc.ID, err)
+		}
+		result.Documents = append(result.Documents, RAGIngestSummary{ID: doc.ID, Chunks: doc.Chunks, URI: ragCorpusURI(corpus, doc.ID)})
+		result.Chunks += doc.Chunks
+		progress.Report(float64(i+1), float64(len(ingestArgs.Documents)), "ingested "+doc.ID)
+		LoggerFromContext(ctx).Debug().Str("corpus", corpus).Str("document", doc.ID).Int("chunks", doc.Chunks).Msg("Document ingested")
+	}
+	return json.Marshal(result)
+}
+
+// resolve 读取文档内容并校验大小与编码
+func (rt *RAGIngestTool) resolve(ctx context.Context, input RAGIngestDocument) (*RAGDocument, error) {
+	doc := &RAGDocument{ID: input.ID, Title: input.Title, Metadata: input.Metadata, Text: input.Text, IngestedAt: time.Now().UTC()}
+	switch {
+	case input.Text != "" && input.URI != "":
+		return nil, fmt.Errorf("text and uri are mutually exclusive")
+	case input.URI != "":
+		data, _, err := ReadSessionResource(ctx, input.URI)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(data) {
+			return nil, fmt.Errorf("resource %s is not UTF-8 text", input.URI)
+		}
+		doc.Text = string(data)
+		doc.Source = input.URI
+		if doc.ID == "" {
+			doc.ID = input.URI
+		}
+	case input.Text == "":
+		return nil, fmt.Errorf("text or uri is required")
+	}
+	if doc.ID == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	if len(doc.Text) > rt.store.config.MaxDocumentBytes {
+		return nil, fmt.Errorf("document exceeds %d bytes", rt.store.config.MaxDocumentBytes)
+	}
+	doc.Chars = utf8.RuneCountInString(doc.Text)
+	return doc, nil
+}
+
+// Resources 每个语料库公开一个文档列表资源与每个文档的原文资源
+func (rt *RAGIngestTool) Resources() []ToolResource {
+	corpora, err := rt.store.snapshot()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(corpora))
+	for corpus := range corpora {
+		names = append(names, corpus)
+	}
+	sort.Strings(names)
+
+	var resources []ToolResource
+	for _, corpus := range names {
+		resources = append(resources, ToolResource{
+			URI:         ragCorpusURI(corpus, ""),
+			Name:        "rag/" + corpus,
+			MimeType:    "application/json",
+			Description: fmt.Sprintf("Documents in the %s retrieval corpus", corpus),
+		})
+		for _, doc := range corpora[corpus] {
+			description := doc.Title
+			if description == "" {
+				description = fmt.Sprintf("Document %s in the %s retrieval corpus", doc.ID, corpus)
+			}
+			resources = append(resources, ToolResource{
+				URI:         ragCorpusURI(corpus, doc.ID),
+				Name:        "rag/" + corpus + "/" + doc.ID,
+				MimeType:    "text/plain",
+				Description: description,
+			})
+		}
+	}
+	return resources
+}
+
+// ReadResource 读取语料库文档列表（weave://rag/<corpus>）或文档原文（weave://rag/<corpus>/<id>）
+func (rt *RAGIngestTool) ReadResource(uri string) (string, string, bool, error) {
+	rest, ok := strings.CutPrefix(uri, RAGURIPrefix)
+	if !ok {
+		return "", "", false, nil
+	}
+	escapedCorpus, escapedID, hasID := strings.Cut(rest, "/")
+	corpus, err := url.PathUnescape(escapedCorpus)
+	if err != nil {
+		return "", "", true, fmt.Errorf("invalid resource uri: %s", uri)
+	}
+
+	if !hasID {
+		corpora, err := rt.store.snapshot()
+		if err != nil {
+			return "", "", true, err
+		}
+		docs, exists := corpora[corpus]
+		if !exists {
+			return "", "", true, werrors.NotFound("resource not found: %s", uri)
+		}
+		content, err := json.MarshalIndent(map[string]interface{}{"corpus": corpus, "documents": docs}, "", "  ")
+		return string(content), "application/json", true, err
+	}
+
+	id, err := url.PathUnescape(escapedID)
+	if err != nil {
+		return "", "", true, fmt.Errorf("invalid resource uri: %s", uri)
+	}
+	doc, exists, err := rt.store.document(corpus, id)
+	if err != nil {
+		return "", "", true, err
+	}
+	if !exists {
+		return "", "", true, werrors.NotFound("resource not found: %s", uri)
+	}
+	return doc.Text, "text/plain", true, nil
+}
+
+// ResourceTemplates 语料库文档列表与文档原文的资源模板，客户端可直接构造 URI 而无需先列出全部文档
+func (rt *RAGIngestTool) ResourceTemplates() []ResourceTemplate {
+	return []ResourceTemplate{
+		{URITemplate: RAGURIPrefix + "{corpus}", Name: "rag/corpus", MimeType: "application/json", Description: "Documents in a retrieval corpus"},
+		{URITemplate: RAGURIPrefix + "{corpus}/{id}", Name: "rag/document", MimeType: "text/plain", Description: "Original text of a document in a retrieval corpus"},
+	}
+}
+
+// ReadTemplate 按模板变量读取语料库资源
+func (rt *RAGIngestTool) ReadTemplate(ctx context.Context, uriTemplate string, vars map[string]string) (string, string, error) {
+	content, mimeType, _, err := rt.ReadResource(ragCorpusURI(vars["corpus"], vars["id"]))
+	return content, mimeType, err
+}
+
+func (qt *RAGQueryTool) Name() string {
+	return "rag_query"
+}
+
+func (qt *RAGQueryTool) Description() string {
+	return "Retrieve the passages of a corpus most relevant to a question and answer it from them with numbered citations"
+}
+
+func (qt *RAGQueryTool) Category() ToolCategory {
+	return CategoryAI
+}
+
+func (qt *RAGQueryTool) Tags() []string {
+	return []string{TagNetwork, TagLLM, TagSlow}
+}
+
+func (qt *RAGQueryTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"corpus":    {Type: schema.TypeString, Description: "Corpus name", Default: defaultRAGCorpus, MinLength: schema.Int(1)},
+		"question":  {Type: schema.TypeString, Description: "Question to answer", MinLength: schema.Int(1)},
+		"top_k":     {Type: schema.TypeInteger, Description: "Number of passages to retrieve", Minimum: schema.Float(1), Maximum: schema.Float(maxRAGTopK)},
+		"min_score": {Type: schema.TypeNumber, Description: "Minimum cosine similarity", Minimum: schema.Float(-1), Maximum: schema.Float(1)},
+		"filter":    {Type: schema.TypeObject, Description: "Document metadata values that passages must match"},
+		"answer":    {Type: schema.TypeBoolean, Description: "Generate an answer with the llm tool; false returns only the passages", Default: true},
+		"provider":  {Type: schema.TypeString, Description: "LLM provider used for the answer"},
+		"model":     {Type: schema.TypeString, Description: "LLM model used for the answer"},
+	}, "question").Closed()
+}
+
+func (qt *RAGQueryTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	return qt.execute(ctx, args, nil)
+}
+
+// ExecuteStream 流式调用，逐段推送生成的回答
+func (qt *RAGQueryTool) ExecuteStream(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	return qt.execute(ctx, args, callback)
+}
+
+func (qt *RAGQueryTool) execute(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	var queryArgs RAGQueryArgs
+	if err := json.Unmarshal(args, &queryArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	if strings.TrimSpace(queryArgs.Question) == "" {
+		return nil, fmt.Errorf("question is required")
+	}
+	corpus := queryArgs.Corpus
+	if corpus == "" {
+		corpus = defaultRAGCorpus
+	}
+	topK := queryArgs.TopK
+	if topK <= 0 {
+		topK = qt.store.config.TopK
+	}
+	if topK > maxRAGTopK {
+		return nil, fmt.Errorf("top_k exceeds limit %d", maxRAGTopK)
+	}
+
+	vectors, err := qt.embeddings.EmbedAll(ctx, qt.store.config.EmbeddingsProvider, qt.store.config.EmbeddingsModel, []string{queryArgs.Question})
+	if err != nil {
+		return nil, err
+	}
+	query := make([]float32, len(vectors[0]))
+	for i, x := range vectors[0] {
+		query[i] = float32(x)
+	}
+	matches, docs, err := qt.store.search(corpus, vector.Query{Vector: query, TopK: topK, MinScore: queryArgs.MinScore, Filter: queryArgs.Filter})
+	if err != nil {
+		return nil, err
+	}
+
+	result := RAGQueryResult{Corpus: corpus, Sources: make([]RAGSource, len(matches))}
+	for i, match := range matches {
+		documentID := match.Metadata["document_id"]
+		chunkIndex, _ := strconv.Atoi(match.Metadata["chunk"])
+		source := RAGSource{N: i + 1, DocumentID: documentID, Chunk: chunkIndex, Score: match.Score, Text: match.Text, URI: ragCorpusURI(corpus, documentID)}
+		if doc, exists := docs[documentID]; exists {
+			source.Title = doc.Title
+			source.Metadata = doc.Metadata
+		}
+		result.Sources[i] = source
+	}
+
+	if (queryArgs.Answer != nil && !*queryArgs.Answer) || len(result.Sources) == 0 {
+		return json.Marshal(result)
+	}
+
+	var onDelta func(string)
+	if callback != nil {
+		index := 0
+		onDelta = func(delta string) {
+			callback(delta, index)
+			index++
+		}
+	}
+	provider, model := qt.store.config.LLMProvider, qt.store.config.LLMModel
+	if queryArgs.Provider != "" {
+		provider = queryArgs.Provider
+	}
+	if queryArgs.Model != "" {
+		model = queryArgs.Model
+	}
+	req := llm.Request{
+		Model:    model,
+		System:   ragSystemPrompt,
+		Messages: []llm.Message{{Role: "user", Content: ragPrompt(queryArgs.Question, result.Sources)}},
+	}
+	completion, err := qt.llm.Complete(ctx, provider, req, onDelta)
+	if err != nil {
+		return nil, err
+	}
+	result.Answer = completion.Content
+	result.Model = completion.Model
+	result.Usage = &completion.Usage
+	return json.Marshal(result)
+}
+
+// ragPrompt 将编号的片段与问题组合为用户提示
+func ragPrompt(question string, sources []RAGSource) string {
+	var b strings.Builder
+	b.WriteString("Context:\n")
+	for _, source := range sources {
+		fmt.Fprintf(&b, "\n[%d]", source.N)
+		if source.Title != "" {
+			fmt.Fprintf(&b, " %s", source.Title)
+		}
+		fmt.Fprintf(&b, "\n%s\n", source.Text)
+	}
+	fmt.Fprintf(&b, "\nQuestion: %s", question)
+	return b.String()
+}
@@ -0,0 +1,204 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/jsonpath"
+)
+
+// DefaultRedactedKeys 未配置 redaction.keys 时按名称脱敏的对象成员
+var DefaultRedactedKeys = []string{
+	"password", "passwd", "secret", "token", "api_key", "apikey",
+	"access_token", "refresh_token", "client_secret", "private_key", "authorization",
+}
+
+// defaultRedactionReplacement 默认替换文本
+const defaultRedactionReplacement = "[REDACTED]"
+
+// Redactor 在参数与结果写入日志和调用记录前脱敏
+type Redactor struct {
+	keys        map[string]bool
+	replacement string
+	common      redactionRule
+	tools       map[string]redactionRule
+}
+
+// redactionRule 编译后的脱敏规则
+type redactionRule struct {
+	arguments []*jsonpath.Path
+	result    []*jsonpath.Path
+	patterns  []*regexp.Regexp
+}
+
+// NewRedactor 编译脱敏规则，无效的 JSONPath 与正则表达式被忽略（加载配置时已校验）
+func NewRedactor(cfg config.RedactionConfig) *Redactor {
+	keys := cfg.Keys
+	if len(keys) == 0 {
+		keys = DefaultRedactedKeys
+	}
+	r := &Redactor{
+		keys:        make(map[string]bool, len(keys)),
+		replacement: cfg.Replacement,
+		common:      compileRedactionRule(cfg.RedactionRule),
+		tools:       make(map[string]redactionRule, len(cfg.Tools)),
+	}
+	if r.replacement == "" {
+		r.replacement = defaultRedactionReplacement
+	}
+	for _, key := range keys {
+		r.keys[strings.ToLower(key)] = true
+	}
+	for tool, rule := range cfg.Tools {
+		r.tools[tool] = compileRedactionRule(rule)
+	}
+	return r
+}
+
+// compileRedactionRule 编译一组脱敏规则
+func compileRedactionRule(rule config.RedactionRule) redactionRule {
+	var compiled redactionRule
+	for _, expr := range rule.Arguments {
+		if path, err := jsonpath.Compile(expr); err == nil {
+			compiled.arguments = append(compiled.arguments, path)
+		}
+	}
+	for _, expr := range rule.Result {
+		if path, err := jsonpath.Compile(expr); err == nil {
+			compiled.result = append(compiled.result, path)
+		}
+	}
+	for _, pattern := range rule.Patterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			compiled.patterns = append(compiled.patterns, re)
+		}
+	}
+	return compiled
+}
+
+// Arguments 返回脱敏后的参数，未改动时返回原参数
+func (r *Redactor) Arguments(tool string, args json.RawMessage) json.RawMessage {
+	if r == nil || len(args) == 0 {
+		return args
+	}
+	rule := r.tools[tool]
+	return r.redactJSON(args, r.common.arguments, rule.arguments, rule.patterns)
+}
+
+// Result 返回脱敏后的结果副本，未改动时返回原结果
+//
+// 可解析为 JSON 的文本内容按字段名与 JSONPath 脱敏，其余文本只应用正则规则。
+func (r *Redactor) Result(tool string, result *ToolCallResult) *ToolCallResult {
+	if r == nil || result == nil {
+		return result
+	}
+	rule := r.tools[tool]
+	var redacted *ToolCallResult
+	for i, content := range result.Content {
+		if content.Type != "text" || content.Text == "" {
+			continue
+		}
+		text := string(r.redactJSON(json.RawMessage(content.Text), r.common.result, rule.result, rule.patterns))
+		if text == content.Text {
+			continue
+		}
+		if redacted == nil {
+			copied := *result
+			copied.Content = append([]ToolCallContent(nil), result.Content...)
+			redacted = &copied
+		}
+		redacted.Content[i].Text = text
+	}
+	if redacted == nil {
+		return result
+	}
+	return redacted
+}
+
+// Text 对错误信息等纯文本应用正则规则
+func (r *Redactor) Text(tool, text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+	return r.redactString(text, r.tools[tool].patterns)
+}
+
+// redactJSON 对 JSON 文档按字段名、JSONPath 与正则规则脱敏，不是 JSON 对象或数组时只应用正则规则
+func (r *Redactor) redactJSON(data json.RawMessage, common, paths []*jsonpath.Path, patterns []*regexp.Regexp) json.RawMessage {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return json.RawMessage(r.redactString(string(data), patterns))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return json.RawMessage(r.redactString(string(data), patterns))
+	}
+
+	changed := r.redactValue(doc, patterns)
+	for _, path := range append(append([]*jsonpath.Path{}, common...), paths...) {
+		if path.Replace(doc, func(interface{}) interface{} { return r.replacement }) > 0 {
+			changed = true
+		}
+	}
+	if !changed {
+		return data
+	}
+	redacted, err := json.Marshal(doc)
+	if err != nil {
+		return data
+	}
+	return redacted
+}
+
+// redactValue 原地替换敏感字段的值与字符串中匹配正则的部分，返回是否有改动
+func (r *Redactor) redactValue(value interface{}, patterns []*regexp.Regexp) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, member := range v {
+			if r.keys[strings.ToLower(key)] {
+				if member != r.replacement {
+					v[key] = r.replacement
+					changed = true
+				}
+				continue
+			}
+			if s, ok := member.(string); ok {
+				if redacted := r.redactString(s, patterns); redacted != s {
+					v[key] = redacted
+					changed = true
+				}
+				continue
+			}
+			changed = r.redactValue(member, patterns) || changed
+		}
+	case []interface{}:
+		for i, item := range v {
+			if s, ok := item.(string); ok {
+				if redacted := r.redactString(s, patterns); redacted != s {
+					v[i] = redacted
+					changed = true
+				}
+				continue
+			}
+			changed = r.redactValue(item, patterns) || changed
+		}
+	}
+	return changed
+}
+
+// redactString 替换字符串中匹配共用规则与工具规则的部分
+func (r *Redactor) redactString(s string, patterns []*regexp.Regexp) string {
+	for _, re := range r.common.patterns {
+		s = re.ReplaceAllLiteralString(s, r.replacement)
+	}
+	for _, re := range patterns {
+		s = re.ReplaceAllLiteralString(s, r.replacement)
+	}
+	return s
+}
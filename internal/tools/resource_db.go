@@ -0,0 +1,304 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // Postgres 驱动
+	_ "modernc.org/sqlite"             // SQLite 驱动（纯 Go，无需 CGO）
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// db:// 资源默认限制
+const (
+	defaultResourceDBMaxRows = 1000
+	resourceDBQueryTimeout   = 30 * time.Second
+)
+
+// DBResourceProvider 以 db://<数据源>/<表或查询> 读取配置的数据库
+//
+// 只能整表读取 tables 中列出的表或执行 queries 中预定义的查询，查询参数按 params 的顺序
+// 取自 URI 的查询参数并以占位符传入，客户端无法提交任意 SQL。结果默认为 JSON
+// （columns 与 rows），?format=csv 返回带表头的 CSV；?limit 限制行数，不超过 max_rows。
+type DBResourceProvider struct {
+	sources map[string]config.ResourceDBConfig
+
+	mu  sync.Mutex
+	dbs map[string]*sql.DB // 首次读取时打开的连接池
+}
+
+// DBResourceResult JSON 格式的查询结果
+type DBResourceResult struct {
+	Columns   []string                 `json:"columns"`
+	Rows      []map[string]interface{} `json:"rows"`
+	Truncated bool                     `json:"truncated,omitempty"` // 结果超过行数上限被截断
+}
+
+// NewDBResourceProvider 创建数据库资源提供者
+func NewDBResourceProvider(sources map[string]config.ResourceDBConfig) *DBResourceProvider {
+	normalized := make(map[string]config.ResourceDBConfig, len(sources))
+	for name, source := range sources {
+		if source.MaxRows <= 0 {
+			source.MaxRows = defaultResourceDBMaxRows
+		}
+		normalized[name] = source
+	}
+	return &DBResourceProvider{sources: normalized, dbs: make(map[string]*sql.DB)}
+}
+
+func (dp *DBResourceProvider) Scheme() string {
+	return "db"
+}
+
+// Resources 列出各数据源可读取的表与预定义查询
+func (dp *DBResourceProvider) Resources() []ToolResource {
+	names := make([]string, 0, len(dp.sources))
+	for name := range dp.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var resources []ToolResource
+	for _, name := range names {
+		source := dp.sources[name]
+		for _, table := range source.Tables {
+			resources = append(resources, ToolResource{
+				URI:         "db://" + name + "/" + url.PathEscape(table),
+				Name:        "db/" + name + "/" + table,
+				MimeType:    "application/json",
+				Description: fmt.Sprintf("Rows of table %s in %s", table, name),
+			})
+		}
+		queries := make([]string, 0, len(source.Queries))
+		for query := range source.Queries {
+			queries = append(queries, query)
+		}
+		sort.Strings(queries)
+		for _, query := range queries {
+			description := source.Queries[query].Description
+			if description == "" {
+				description = fmt.Sprintf("Result of query %s in %s", query, name)
+			}
+			if params := source.Queries[query].Params; len(params) > 0 {
+				description += " (parameters: " + strings.Join(params, ", ") + ")"
+			}
+			resources = append(resources, ToolResource{
+				URI:         "db://" + name + "/" + url.PathEscape(query),
+				Name:        "db/" + name + "/" + query,
+				MimeType:    "application/json",
+				Description: description,
+			})
+		}
+	}
+	return resources
+}
+
+func (dp *DBResourceProvider) ResourceTemplates() []ResourceTemplate {
+	return []ResourceTemplate{{
+		URITemplate: "db://{source}/{name}{?format,limit}",
+		Name:        "db",
+		MimeType:    "application/json",
+		Description: "Rows of an allowed table or predefined query; query parameters are passed as URI query parameters",
+	}}
+}
+
+// Read 读取整表或执行预定义查询
+func (dp *DBResourceProvider) Read(ctx context.Context, uri *url.URL) ([]byte, string, error) {
+	sourceName := uri.Host
+	source, ok := dp.sources[sourceName]
+	if !ok {
+		return nil, "", resourceNotFound(uri)
+	}
+	name, err := url.PathUnescape(strings.TrimPrefix(uri.EscapedPath(), "/"))
+	if err != nil || name == "" {
+		return nil, "", resourceNotFound(uri)
+	}
+
+	params := uri.Query()
+	format := params.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		return nil, "", werrors.InvalidParams("unsupported format: %s", format)
+	}
+	limit := source.MaxRows
+	if value := params.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, "", werrors.InvalidParams("invalid limit: %s", value)
+		}
+		limit = min(n, source.MaxRows)
+	}
+
+	var query string
+	var args []interface{}
+	if predefined, ok := source.Queries[name]; ok {
+		query = predefined.SQL
+		for _, param := range predefined.Params {
+			if !params.Has(param) {
+				return nil, "", werrors.InvalidParams("missing query parameter: %s", param)
+			}
+			args = append(args, params.Get(param))
+		}
+	} else if slices.Contains(source.Tables, name) {
+		query = "SELECT * FROM " + quoteIdentifier(name)
+	} else {
+		return nil, "", resourceNotFound(uri)
+	}
+
+	db, err := dp.open(sourceName, source)
+	if err != nil {
+		return nil, "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, resourceDBQueryTimeout)
+	defer cancel()
+	result, err := queryRows(ctx, db, query, args, limit)
+	if err != nil {
+		return nil, "", werrors.UpstreamFailure("query %s on %s failed: %v", name, sourceName, err)
+	}
+
+	if format == "csv" {
+		data, err := result.csv()
+		return data, "text/csv", err
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	return data, "application/json", err
+}
+
+// open 获取数据源的连接池，首次使用时打开
+func (dp *DBResourceProvider) open(name string, source config.ResourceDBConfig) (*sql.DB, error) {
+	dp.mu.Lock()
+	defer dp.mu.Unlock()
+	if db, ok := dp.dbs[name]; ok {
+		return db, nil
+	}
+
+	var driver string
+	switch source.Driver {
+	case "sqlite", "":
+		driver = "sqlite"
+	case "postgres":
+		driver = "pgx"
+	default:
+		return nil, fmt.Errorf("unsupported db driver for %s: %s", name, source.Driver)
+	}
+	db, err := sql.Open(driver, source.ResolveDSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open db %s: %v", name, err)
+	}
+	db.SetMaxOpenConns(4)
+	dp.dbs[name] = db
+	return db, nil
+}
+
+// Close 关闭已打开的连接池
+func (dp *DBResourceProvider) Close() error {
+	dp.mu.Lock()
+	defer dp.mu.Unlock()
+	var errs []error
+	for name, db := range dp.dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close db %s: %v", name, err))
+		}
+		delete(dp.dbs, name)
+	}
+	return errors.Join(errs...)
+}
+
+// queryRows 执行查询并读取至多 limit 行
+func queryRows(ctx context.Context, db *sql.DB, query string, args []interface{}, limit int) (*DBResourceResult, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &DBResourceResult{Columns: columns, Rows: []map[string]interface{}{}}
+	for rows.Next() {
+		if len(result.Rows) == limit {
+			result.Truncated = true
+			break
+		}
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = dbValue(values[i])
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, rows.Err()
+}
+
+// dbValue 将驱动返回的值转换为可编码为 JSON 的值
+func dbValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
+
+// csv 按列顺序输出带表头的 CSV，NULL 输出为空
+func (r *DBResourceResult) csv() ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(r.Columns); err != nil {
+		return nil, err
+	}
+	record := make([]string, len(r.Columns))
+	for _, row := range r.Rows {
+		for i, column := range r.Columns {
+			switch value := row[column].(type) {
+			case nil:
+				record[i] = ""
+			case string:
+				record[i] = value
+			default:
+				record[i] = fmt.Sprint(value)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// quoteIdentifier 以双引号引用表名，schema.table 分别引用
+func quoteIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// EnvResourceProvider 以 env://<变量名> 公开白名单中的环境变量，供客户端读取部署相关的配置值
+type EnvResourceProvider struct {
+	names []string
+}
+
+// NewEnvResourceProvider 创建环境变量资源提供者，names 为允许读取的变量名
+func NewEnvResourceProvider(names []string) *EnvResourceProvider {
+	allowed := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(allowed, name) {
+			allowed = append(allowed, name)
+		}
+	}
+	slices.Sort(allowed)
+	return &EnvResourceProvider{names: allowed}
+}
+
+func (ep *EnvResourceProvider) Scheme() string {
+	return "env"
+}
+
+// Resources 列出白名单中已设置的环境变量
+func (ep *EnvResourceProvider) Resources() []ToolResource {
+	var resources []ToolResource
+	for _, name := range ep.names {
+		if _, ok := os.LookupEnv(name); ok {
+			resources = append(resources, ToolResource{
+				URI:         "env://" + name,
+				Name:        "env/" + name,
+				MimeType:    "text/plain",
+				Description: "Value of the " + name + " environment variable",
+			})
+		}
+	}
+	return resources
+}
+
+func (ep *EnvResourceProvider) ResourceTemplates() []ResourceTemplate {
+	return []ResourceTemplate{{URITemplate: "env://{name}", Name: "env", MimeType: "text/plain", Description: "Value of an allowed environment variable"}}
+}
+
+// Read 读取环境变量，未列入白名单与未设置的变量均按不存在处理
+func (ep *EnvResourceProvider) Read(ctx context.Context, uri *url.URL) ([]byte, string, error) {
+	name := uri.Host
+	if name == "" {
+		name = strings.TrimPrefix(uri.Path, "/")
+	}
+	if !slices.Contains(ep.names, name) {
+		return nil, "", resourceNotFound(uri)
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, "", resourceNotFound(uri)
+	}
+	return []byte(value), "text/plain", nil
+}
@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// https:// 资源默认限制
+const (
+	defaultResourceHTTPMaxBytes = 4 << 20
+	defaultResourceHTTPTimeout  = 30
+	resourceHTTPMaxRedirects    = 5
+)
+
+// HTTPResourceProvider 由服务器代为读取 https:// 资源
+//
+// 只能访问 allow_hosts 中的主机（含重定向后的每一跳），请求经 http_client 配置的共享连接池、
+// 代理与重试策略发出，不转发客户端的任何请求头。
+type HTTPResourceProvider struct {
+	config config.ResourceHTTPConfig
+}
+
+// NewHTTPResourceProvider 创建 https:// 资源提供者
+func NewHTTPResourceProvider(cfg config.ResourceHTTPConfig) *HTTPResourceProvider {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = defaultResourceHTTPMaxBytes
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultResourceHTTPTimeout
+	}
+	return &HTTPResourceProvider{config: cfg}
+}
+
+func (hp *HTTPResourceProvider) Scheme() string {
+	return "https"
+}
+
+// Resources 远程资源无法枚举
+func (hp *HTTPResourceProvider) Resources() []ToolResource {
+	return nil
+}
+
+func (hp *HTTPResourceProvider) ResourceTemplates() []ResourceTemplate {
+	return []ResourceTemplate{{
+		URITemplate: "https://{host}{+path}",
+		Name:        "https",
+		Description: "Document fetched by the server from an allowed host: " + strings.Join(hp.config.AllowHosts, ", "),
+	}}
+}
+
+// Read 请求资源，主机不在白名单中时拒绝
+func (hp *HTTPResourceProvider) Read(ctx context.Context, uri *url.URL) ([]byte, string, error) {
+	if uri.User != nil {
+		return nil, "", werrors.InvalidParams("credentials in resource uri are not allowed")
+	}
+	if err := hp.checkURL(uri); err != nil {
+		return nil, "", err
+	}
+
+	client := *HTTPClient(ctx, time.Duration(hp.config.Timeout)*time.Second)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= resourceHTTPMaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", resourceHTTPMaxRedirects)
+		}
+		return hp.checkURL(req.URL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri.String(), nil)
+	if err != nil {
+		return nil, "", werrors.InvalidParams("invalid resource uri: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		var blocked *werrors.Error
+		if errors.As(err, &blocked) {
+			return nil, "", blocked
+		}
+		return nil, "", werrors.UpstreamFailure("failed to fetch %s: %v", uri.Redacted(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, "", werrors.UpstreamFailure("%s returned %s", uri.Redacted(), resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, hp.config.MaxBytes+1))
+	if err != nil {
+		return nil, "", werrors.UpstreamFailure("failed to read %s: %v", uri.Redacted(), err)
+	}
+	if int64(len(data)) > hp.config.MaxBytes {
+		return nil, "", werrors.InvalidParams("resource exceeds %d bytes", hp.config.MaxBytes)
+	}
+
+	mimeType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		mimeType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+	return data, mimeType, nil
+}
+
+// checkURL 只允许 https 与白名单中的主机
+func (hp *HTTPResourceProvider) checkURL(u *url.URL) error {
+	host := strings.ToLower(u.Hostname())
+	if u.Scheme != "https" || !matchHost(hp.config.AllowHosts, host) {
+		return werrors.Unauthorized("host not allowed: %s", host)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// reservedSchemes 服务器自身处理的资源 URI scheme，资源提供者不能注册
+var reservedSchemes = []string{"file", "session", "weave", "result", "history", "blob"}
+
+// ResourceProvider 按 URI scheme 提供资源的扩展（如 https://、db://、env://）
+//
+// 与工具一样，内置提供者由 BuiltinResourceProviders 按配置创建，嵌入应用可用
+// UseResourceProviders 替换；实现 io.Closer 的提供者在被替换或服务器关闭时关闭。
+type ResourceProvider interface {
+	// Scheme 处理的 URI scheme，小写
+	Scheme() string
+	// Resources 列出可枚举的资源，无法枚举时返回空
+	Resources() []ToolResource
+	// ResourceTemplates 列出资源模板
+	ResourceTemplates() []ResourceTemplate
+	// Read 读取资源，文本类型以外的内容以 blob 返回
+	Read(ctx context.Context, uri *url.URL) (content []byte, mimeType string, err error)
+}
+
+// BuiltinResourceProviders 按工具配置创建已配置的内置资源提供者
+func BuiltinResourceProviders(toolConfig *config.ToolManagerConfig) []ResourceProvider {
+	resources := toolConfig.Resources
+	var providers []ResourceProvider
+	if len(resources.HTTP.AllowHosts) > 0 {
+		providers = append(providers, NewHTTPResourceProvider(resources.HTTP))
+	}
+	if len(resources.DB) > 0 {
+		providers = append(providers, NewDBResourceProvider(resources.DB))
+	}
+	if len(resources.Env) > 0 {
+		providers = append(providers, NewEnvResourceProvider(resources.Env))
+	}
+	return providers
+}
+
+// UseResourceProviders 指定 RegisterAllTools 注册的资源提供者，替代内置提供者；
+// 不带参数调用时不提供任何提供者
+func (tm *ToolManager) UseResourceProviders(providers ...ResourceProvider) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.providerCatalog = append([]ResourceProvider{}, providers...)
+}
+
+// registerResourceProviders 注册资源提供者，替换并关闭此前注册的提供者
+func (tm *ToolManager) registerResourceProviders() {
+	tm.mu.RLock()
+	catalog := tm.providerCatalog
+	tm.mu.RUnlock()
+	if catalog == nil {
+		catalog = BuiltinResourceProviders(tm.toolConfig)
+	}
+
+	providers := make(map[string]ResourceProvider, len(catalog))
+	for _, provider := range catalog {
+		scheme := strings.ToLower(provider.Scheme())
+		if slices.Contains(reservedSchemes, scheme) {
+			tm.logger.Warn().Str("scheme", scheme).Msg("Resource provider uses a reserved scheme, skipping")
+			continue
+		}
+		providers[scheme] = provider
+		tm.logger.Info().Str("scheme", scheme).Msg("Resource provider registered")
+	}
+
+	tm.mu.Lock()
+	previous := tm.providers
+	tm.providers = providers
+	tm.mu.Unlock()
+
+	for scheme, provider := range previous {
+		if providers[scheme] == provider {
+			continue
+		}
+		if closer, ok := provider.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				tm.logger.Warn().Err(err).Str("scheme", scheme).Msg("Failed to close resource provider")
+			}
+		}
+	}
+}
+
+// ResourceProviders 获取已注册的资源提供者，按 scheme 排序
+func (tm *ToolManager) ResourceProviders() []ResourceProvider {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	providers := make([]ResourceProvider, 0, len(tm.providers))
+	for _, provider := range tm.providers {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Scheme() < providers[j].Scheme() })
+	return providers
+}
+
+// ReadProviderResource 由对应 scheme 的资源提供者读取资源，没有提供者时 ok 为 false
+func (tm *ToolManager) ReadProviderResource(ctx context.Context, uri string) (content []byte, mimeType string, ok bool, err error) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme == "" {
+		return nil, "", false, nil
+	}
+
+	tm.mu.RLock()
+	provider, exists := tm.providers[strings.ToLower(parsed.Scheme)]
+	clients := tm.http
+	tm.mu.RUnlock()
+	if !exists {
+		return nil, "", false, nil
+	}
+
+	if _, set := ctx.Value(httpClientsContextKey{}).(*HTTPClients); !set {
+		ctx = WithHTTPClients(ctx, clients)
+	}
+	content, mimeType, err = provider.Read(ctx, parsed)
+	if err != nil {
+		return nil, "", true, err
+	}
+	return content, mimeType, true, nil
+}
+
+// resourceNotFound 资源不存在或不在白名单中，两者不加区分以免泄露配置
+func resourceNotFound(uri *url.URL) error {
+	return werrors.NotFound("resource not found: %s", uri.Redacted())
+}
@@ -0,0 +1,56 @@
+package tools
+
+import "Weave-Toolkit/internal/jsonstream"
+
+// MarshalJSONStream 将调用结果直接写入流式编码器，文本内容边转义边输出，
+// 输出与 json.Marshal 相同
+func (r *ToolCallResult) MarshalJSONStream(s *jsonstream.Stream) {
+	if r == nil {
+		s.Raw("null")
+		return
+	}
+	s.Raw(`{"content":`)
+	if r.Content == nil {
+		s.Raw("null")
+	} else {
+		s.Raw("[")
+		for i := range r.Content {
+			if i > 0 {
+				s.Raw(",")
+			}
+			r.Content[i].MarshalJSONStream(s)
+		}
+		s.Raw("]")
+	}
+	if len(r.StructuredContent) > 0 {
+		s.Raw(`,"structuredContent":`)
+		s.Value(r.StructuredContent)
+	}
+	if r.IsError {
+		s.Raw(`,"isError":true`)
+	}
+	if r.Meta != nil {
+		s.Raw(`,"_meta":`)
+		s.Value(r.Meta)
+	}
+	s.Raw("}")
+}
+
+// MarshalJSONStream 将单个内容写入流式编码器，省略规则与结构体标签一致
+func (c *ToolCallContent) MarshalJSONStream(s *jsonstream.Stream) {
+	s.Raw(`{"type":`)
+	s.String(c.Type)
+	if c.Text != "" {
+		s.Raw(`,"text":`)
+		s.String(c.Text)
+	}
+	if c.Data != nil {
+		s.Raw(`,"data":`)
+		s.Value(c.Data)
+	}
+	if c.MimeType != "" {
+		s.Raw(`,"mimeType":`)
+		s.String(c.MimeType)
+	}
+	s.Raw("}")
+}
@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// ResultURIPrefix 被截断结果的完整内容资源 URI 前缀，?offset= 指定继续读取的位置
+const ResultURIPrefix = "result://"
+
+// 截断结果保存的默认参数
+const (
+	DefaultResultRetention  = 10 * time.Minute
+	DefaultResultStoreBytes = 256 << 20
+)
+
+// ResultMeta 调用结果的附加信息，以 _meta 返回
+type ResultMeta struct {
+	Truncated []TruncatedContent `json:"truncated,omitempty"`
+	PII       []PIIFinding       `json:"pii,omitempty"`    // 检测到的个人信息
+	Policy    []PolicyFinding    `json:"policy,omitempty"` // 命中的 annotate 策略
+}
+
+// TruncatedContent 被截断的文本内容及读取其余部分的资源
+type TruncatedContent struct {
+	Index    int    `json:"index"`    // 在 content 中的位置
+	URI      string `json:"uri"`      // 从截断处继续读取的资源，未能保存完整内容时为空
+	Size     int    `json:"size"`     // 完整内容的字节数
+	Returned int    `json:"returned"` // 已返回的字节数
+}
+
+// storedResult 保存的完整内容，window 为每次读取返回的字节数上限
+type storedResult struct {
+	id      string
+	text    string
+	window  int
+	expires time.Time
+}
+
+// ResultStore 被截断结果的完整内容，超过保留时长后过期，总大小超过上限时淘汰最早保存的内容
+type ResultStore struct {
+	retention time.Duration
+	maxBytes  int64
+	mu        sync.Mutex
+	results   map[string]*storedResult
+	order     []*storedResult // 按保存时间排序
+	size      int64
+	now       func() time.Time
+}
+
+// NewResultStore 创建结果存储，参数不大于 0 时使用默认值
+func NewResultStore(retention time.Duration, maxBytes int64) *ResultStore {
+	if retention <= 0 {
+		retention = DefaultResultRetention
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultResultStoreBytes
+	}
+	return &ResultStore{
+		retention: retention,
+		maxBytes:  maxBytes,
+		results:   make(map[string]*storedResult),
+		now:       time.Now,
+	}
+}
+
+// SetClock 替换时钟，供测试推进时间
+func (rs *ResultStore) SetClock(now func() time.Time) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.now = now
+}
+
+// Save 保存完整内容并返回资源 URI，内容超过存储上限时返回错误
+func (rs *ResultStore) Save(text string, window int) (string, error) {
+	if int64(len(text)) > rs.maxBytes {
+		return "", fmt.Errorf("result of %d bytes exceeds the result store limit of %d bytes", len(text), rs.maxBytes)
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	result := &storedResult{id: hex.EncodeToString(buf), text: text, window: window}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	now := rs.now()
+	result.expires = now.Add(rs.retention)
+	for len(rs.order) > 0 && (!rs.order[0].expires.After(now) || rs.size+int64(len(text)) > rs.maxBytes) {
+		rs.removeOldestLocked()
+	}
+	rs.results[result.id] = result
+	rs.order = append(rs.order, result)
+	rs.size += int64(len(text))
+	return ResultURIPrefix + result.id, nil
+}
+
+// removeOldestLocked 删除最早保存的内容（调用方需持有锁）
+func (rs *ResultStore) removeOldestLocked() {
+	oldest := rs.order[0]
+	rs.order[0] = nil
+	rs.order = rs.order[1:]
+	delete(rs.results, oldest.id)
+	rs.size -= int64(len(oldest.text))
+}
+
+// Read 读取 result://{id}?offset=N，返回从 offset 开始不超过一个窗口的内容；
+// 之后还有内容时在末尾附加截断标记，指明继续读取的 URI
+func (rs *ResultStore) Read(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme+"://" != ResultURIPrefix || parsed.Host == "" {
+		return "", fmt.Errorf("invalid result uri: %s", uri)
+	}
+	offset := 0
+	if value := parsed.Query().Get("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			return "", fmt.Errorf("invalid offset: %s", value)
+		}
+	}
+
+	rs.mu.Lock()
+	result, exists := rs.results[parsed.Host]
+	if exists && !result.expires.After(rs.now()) {
+		exists = false
+	}
+	rs.mu.Unlock()
+	if !exists {
+		return "", werrors.NotFound("result not found or expired: %s", uri)
+	}
+
+	if offset > len(result.text) {
+		return "", fmt.Errorf("offset %d exceeds result size %d", offset, len(result.text))
+	}
+	for offset < len(result.text) && !utf8.RuneStart(result.text[offset]) {
+		offset--
+	}
+	rest := result.text[offset:]
+	if len(rest) <= result.window {
+		return rest, nil
+	}
+	cut := chunkCut(rest, result.window)
+	return rest[:cut] + truncationMarker(offset+cut, len(result.text), ResultURIPrefix+result.id), nil
+}
+
+// Stats 获取存储的内容数与字节数
+func (rs *ResultStore) Stats() map[string]interface{} {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return map[string]interface{}{
+		"results":   len(rs.results),
+		"bytes":     rs.size,
+		"max_bytes": rs.maxBytes,
+	}
+}
+
+// truncationMarker 截断标记，uri 为空时表示其余内容不可读取
+func truncationMarker(returned, size int, uri string) string {
+	if uri == "" {
+		return fmt.Sprintf("\n\n[truncated: %d of %d bytes shown]", returned, size)
+	}
+	return fmt.Sprintf("\n\n[truncated: %d of %d bytes shown, read resource %s?offset=%d for more]", returned, size, uri, returned)
+}
+
+// resultLimits 单个工具的结果大小上限
+func resultLimits(toolConfig *config.ToolManagerConfig) map[string]int {
+	limits := make(map[string]int, len(toolConfig.ToolResultLimits))
+	for name, limit := range toolConfig.ToolResultLimits {
+		if limit > 0 {
+			limits[name] = limit
+		}
+	}
+	return limits
+}
+
+// resolveResultLimit 在读锁内确定工具的结果大小上限，0 为不限制
+func (tm *ToolManager) resolveResultLimit(name string) int {
+	if limit, exists := tm.resultLimits[name]; exists {
+		return limit
+	}
+	return tm.defaultResultLimit
+}
+
+// ResultStats 获取截断结果存储的统计信息
+func (tm *ToolManager) ResultStats() map[string]interface{} {
+	return tm.results.Stats()
+}
+
+// truncateResult 将超过上限的文本内容截断，完整内容保存在结果存储中供 result:// 资源继续读取
+func (tm *ToolManager) truncateResult(name string, result *ToolCallResult, limit int) {
+	if limit <= 0 {
+		return
+	}
+	for i := range result.Content {
+		content := &result.Content[i]
+		if content.Type != "text" || len(content.Text) <= limit {
+			continue
+		}
+
+		full := content.Text
+		cut := chunkCut(full, limit)
+		uri, err := tm.results.Save(full, limit)
+		if err != nil {
+			tm.logger.Warn().Str("tool", name).Err(err).Msg("Truncated tool result not kept")
+		}
+		content.Text = full[:cut] + truncationMarker(cut, len(full), uri)
+		if result.Meta == nil {
+			result.Meta = &ResultMeta{}
+		}
+		result.Meta.Truncated = append(result.Meta.Truncated, TruncatedContent{Index: i, URI: uri, Size: len(full), Returned: cut})
+	}
+}
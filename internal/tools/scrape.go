@@ -0,0 +1,468 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/temoto/robotstxt"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/schema"
+)
+
+// scrape 默认限制
+const (
+	defaultScrapeUserAgent  = "Weave-Toolkit/1.0"
+	defaultScrapeMaxPages   = 10
+	defaultScrapeMaxRecords = 1000
+	robotsCacheTTL          = 10 * time.Minute
+	maxScrapeCrawlDelay     = 10 * time.Second
+)
+
+// ErrRobotsDisallowed 目标页面被站点的 robots.txt 禁止抓取
+var ErrRobotsDisallowed = werrors.New(werrors.KindUnauthorized, "disallowed by robots.txt")
+
+// inlineElements 提取文本时不视为分隔的行内元素，其余元素前后补空格，避免相邻段落的文字粘连
+var inlineElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "cite": true, "code": true,
+	"data": true, "dfn": true, "em": true, "i": true, "kbd": true, "mark": true, "q": true,
+	"s": true, "samp": true, "small": true, "span": true, "strong": true, "sub": true,
+	"sup": true, "time": true, "u": true, "var": true,
+}
+
+// ScrapeTool 网页抓取工具
+//
+// 通过 http_fetch 的请求层获取页面，沿用其主机白名单、内网地址拦截与响应大小限制。
+// 按 CSS 选择器或 XPath 选出记录并提取字段，可沿下一页链接翻页；默认遵守 robots.txt
+// 的抓取规则与 Crawl-delay，robots.txt 按站点缓存。
+type ScrapeTool struct {
+	config config.ScrapeConfig
+	fetch  *HTTPFetchTool
+
+	mu     sync.Mutex
+	robots map[string]robotsEntry // 按 scheme://host 缓存
+}
+
+type robotsEntry struct {
+	data    *robotstxt.RobotsData
+	expires time.Time
+}
+
+// ScrapeArgs 抓取参数，selector 与 xpath、next 与 next_xpath 分别二选一
+type ScrapeArgs struct {
+	URL        string                 `json:"url"`
+	Selector   string                 `json:"selector"` // 记录的 CSS 选择器，为空时整个页面为一条记录
+	XPath      string                 `json:"xpath"`
+	Fields     map[string]ScrapeField `json:"fields"`     // 为空时每条记录只包含 text
+	Next       string                 `json:"next"`       // 下一页链接的 CSS 选择器
+	NextXPath  string                 `json:"next_xpath"` // 下一页链接的 XPath
+	MaxPages   int                    `json:"max_pages"`
+	MaxRecords int                    `json:"max_records"`
+}
+
+// ScrapeField 字段提取规则，选择器相对记录元素，均为空时取记录元素本身
+type ScrapeField struct {
+	Selector string `json:"selector"`
+	XPath    string `json:"xpath"`
+	Attr     string `json:"attr"` // 取属性值，为空时取文本；href 与 src 转换为绝对地址
+	All      bool   `json:"all"`  // 返回所有匹配的值，否则只返回第一个
+}
+
+// ScrapeResult 抓取结果
+type ScrapeResult struct {
+	Records   []map[string]interface{} `json:"records"`
+	Pages     []ScrapePage             `json:"pages"`
+	Next      string                   `json:"next,omitempty"`      // 达到页数上限时尚未抓取的下一页
+	Truncated bool                     `json:"truncated,omitempty"` // 记录数达到上限
+}
+
+// ScrapePage 已抓取的页面
+type ScrapePage struct {
+	URL       string `json:"url"`
+	Status    int    `json:"status"`
+	Records   int    `json:"records"`
+	Truncated bool   `json:"truncated,omitempty"` // 页面超过 http_fetch 的响应大小上限被截断
+}
+
+// NewScrapeTool 创建网页抓取工具
+func NewScrapeTool(cfg config.ScrapeConfig, fetch *HTTPFetchTool) *ScrapeTool {
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaultScrapeUserAgent
+	}
+	if cfg.MaxPages <= 0 {
+		cfg.MaxPages = defaultScrapeMaxPages
+	}
+	if cfg.MaxRecords <= 0 {
+		cfg.MaxRecords = defaultScrapeMaxRecords
+	}
+	return &ScrapeTool{config: cfg, fetch: fetch, robots: make(map[string]robotsEntry)}
+}
+
+func (st *ScrapeTool) Name() string {
+	return "scrape"
+}
+
+func (st *ScrapeTool) Description() string {
+	return "Fetch web pages and extract structured records with CSS selectors or XPath, following next-page links and respecting robots.txt"
+}
+
+func (st *ScrapeTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (st *ScrapeTool) Tags() []string {
+	return []string{TagNetwork, TagSlow}
+}
+
+func (st *ScrapeTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"url":         {Type: schema.TypeString, Description: "Absolute http or https URL of the first page", MinLength: schema.Int(1)},
+		"selector":    {Type: schema.TypeString, Description: "CSS selector matching one element per record; the whole page is one record when omitted"},
+		"xpath":       {Type: schema.TypeString, Description: "XPath expression matching one element per record, instead of selector"},
+		"fields":      {Type: schema.TypeObject, Description: "Fields to extract from each record: name -> {selector or xpath relative to the record, attr to read an attribute instead of text, all to return every match}; defaults to the record text"},
+		"next":        {Type: schema.TypeString, Description: "CSS selector of the next-page link to follow"},
+		"next_xpath":  {Type: schema.TypeString, Description: "XPath of the next-page link, instead of next"},
+		"max_pages":   {Type: schema.TypeInteger, Description: "Maximum number of pages to fetch", Minimum: schema.Float(1)},
+		"max_records": {Type: schema.TypeInteger, Description: "Maximum number of records to return", Minimum: schema.Float(1)},
+	}, "url").Closed()
+}
+
+// scrapeField 编译后的字段规则
+type scrapeField struct {
+	name  string
+	query *nodeQuery
+	attr  string
+	all   bool
+}
+
+func (st *ScrapeTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var scrapeArgs ScrapeArgs
+	if err := json.Unmarshal(args, &scrapeArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+
+	target, err := url.Parse(scrapeArgs.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %v", err)
+	}
+	if err := st.fetch.checkURL(target); err != nil {
+		return nil, err
+	}
+
+	records, err := compileNodeQuery("selector", scrapeArgs.Selector, "xpath", scrapeArgs.XPath)
+	if err != nil {
+		return nil, err
+	}
+	next, err := compileNodeQuery("next", scrapeArgs.Next, "next_xpath", scrapeArgs.NextXPath)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := compileScrapeFields(scrapeArgs.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPages := st.config.MaxPages
+	if scrapeArgs.MaxPages > 0 && scrapeArgs.MaxPages < maxPages {
+		maxPages = scrapeArgs.MaxPages
+	}
+	maxRecords := st.config.MaxRecords
+	if scrapeArgs.MaxRecords > 0 && scrapeArgs.MaxRecords < maxRecords {
+		maxRecords = scrapeArgs.MaxRecords
+	}
+
+	result := ScrapeResult{Records: []map[string]interface{}{}, Pages: []ScrapePage{}}
+	visited := make(map[string]bool)
+	for {
+		if len(result.Pages) >= maxPages {
+			result.Next = target.String()
+			break
+		}
+		visited[target.String()] = true
+
+		delay, err := st.checkRobots(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Pages) > 0 && delay > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(min(delay, maxScrapeCrawlDelay)):
+			}
+		}
+
+		doc, page, err := st.fetchPage(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		base, _ := url.Parse(page.URL)
+
+		nodes := []*html.Node{doc}
+		if records != nil {
+			nodes = records.all(doc)
+		}
+		for _, node := range nodes {
+			if len(result.Records) >= maxRecords {
+				result.Truncated = true
+				break
+			}
+			result.Records = append(result.Records, extractRecord(node, fields, base))
+			page.Records++
+		}
+		result.Pages = append(result.Pages, page)
+		if result.Truncated || next == nil {
+			break
+		}
+
+		link := next.first(doc)
+		if link == nil {
+			break
+		}
+		nextURL := resolveLink(base, htmlquery.SelectAttr(link, "href"))
+		if nextURL == nil || visited[nextURL.String()] {
+			break
+		}
+		target = nextURL
+	}
+
+	return json.Marshal(result)
+}
+
+// fetchPage 获取并解析 HTML 页面，非 2xx 状态与非 HTML 内容返回错误
+func (st *ScrapeTool) fetchPage(ctx context.Context, target *url.URL) (*html.Node, ScrapePage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, ScrapePage{}, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("User-Agent", st.config.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.5")
+
+	resp, data, truncated, err := st.fetch.fetch(req)
+	if err != nil {
+		return nil, ScrapePage{}, err
+	}
+	page := ScrapePage{URL: resp.Request.URL.String(), Status: resp.StatusCode, Truncated: truncated}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, page, fmt.Errorf("page %s returned status %d", page.URL, resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+			return nil, page, fmt.Errorf("page %s is not HTML: %s", page.URL, contentType)
+		}
+	}
+	reader, err := charset.NewReader(bytes.NewReader(data), contentType)
+	if err != nil {
+		return nil, page, fmt.Errorf("failed to decode page %s: %v", page.URL, err)
+	}
+	doc, err := html.Parse(reader)
+	if err != nil {
+		return nil, page, fmt.Errorf("failed to parse page %s: %v", page.URL, err)
+	}
+	return doc, page, nil
+}
+
+// checkRobots 检查 robots.txt 是否允许抓取，返回站点要求的抓取间隔
+func (st *ScrapeTool) checkRobots(ctx context.Context, target *url.URL) (time.Duration, error) {
+	if st.config.IgnoreRobots {
+		return 0, nil
+	}
+	origin := target.Scheme + "://" + target.Host
+
+	st.mu.Lock()
+	entry, ok := st.robots[origin]
+	st.mu.Unlock()
+	if !ok || time.Now().After(entry.expires) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("User-Agent", st.config.UserAgent)
+		resp, data, _, err := st.fetch.fetch(req)
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch robots.txt: %w", err)
+		}
+		// 4xx 视为不限制，5xx 视为全部禁止
+		robots, err := robotstxt.FromStatusAndBytes(resp.StatusCode, data)
+		if err != nil {
+			return 0, fmt.Errorf("invalid robots.txt on %s: %v", origin, err)
+		}
+		entry = robotsEntry{data: robots, expires: time.Now().Add(robotsCacheTTL)}
+		st.mu.Lock()
+		st.robots[origin] = entry
+		st.mu.Unlock()
+	}
+
+	group := entry.data.FindGroup(st.config.UserAgent)
+	if !group.Test(target.RequestURI()) {
+		return 0, fmt.Errorf("%w: %s", ErrRobotsDisallowed, target)
+	}
+	return group.CrawlDelay, nil
+}
+
+// compileScrapeFields 编译字段规则，按名称排序
+func compileScrapeFields(fields map[string]ScrapeField) ([]scrapeField, error) {
+	if len(fields) == 0 {
+		return []scrapeField{{name: "text"}}, nil
+	}
+	compiled := make([]scrapeField, 0, len(fields))
+	for name, field := range fields {
+		query, err := compileNodeQuery("fields."+name+".selector", field.Selector, "fields."+name+".xpath", field.XPath)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, scrapeField{name: name, query: query, attr: field.Attr, all: field.All})
+	}
+	sort.Slice(compiled, func(i, j int) bool { return compiled[i].name < compiled[j].name })
+	return compiled, nil
+}
+
+// extractRecord 从记录元素提取字段，未匹配的字段为 null，all 字段为数组
+func extractRecord(node *html.Node, fields []scrapeField, base *url.URL) map[string]interface{} {
+	record := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		matches := []*html.Node{node}
+		if field.query != nil {
+			matches = field.query.all(node)
+		}
+
+		values := make([]string, 0, len(matches))
+		for _, match := range matches {
+			if value, ok := fieldValue(match, field.attr, base); ok {
+				values = append(values, value)
+			}
+		}
+		switch {
+		case field.all:
+			record[field.name] = values
+		case len(values) > 0:
+			record[field.name] = values[0]
+		default:
+			record[field.name] = nil
+		}
+	}
+	return record
+}
+
+// fieldValue 取元素的文本或属性值
+func fieldValue(node *html.Node, attr string, base *url.URL) (string, bool) {
+	if attr == "" {
+		return nodeText(node), true
+	}
+	if !htmlquery.ExistsAttr(node, attr) {
+		return "", false
+	}
+	value := strings.TrimSpace(htmlquery.SelectAttr(node, attr))
+	if attr == "href" || attr == "src" {
+		if link := resolveLink(base, value); link != nil {
+			return link.String(), true
+		}
+	}
+	return value, true
+}
+
+// nodeText 提取可读文本，忽略脚本与样式，合并连续空白
+func nodeText(node *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.CommentNode:
+			return
+		case html.ElementNode:
+			switch n.Data {
+			case "script", "style", "noscript", "template":
+				return
+			}
+			if !inlineElements[n.Data] {
+				b.WriteByte(' ')
+				defer b.WriteByte(' ')
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(node)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// resolveLink 将链接解析为绝对的 http(s) 地址，无效链接返回 nil
+func resolveLink(base *url.URL, href string) *url.URL {
+	href = strings.TrimSpace(href)
+	if href == "" || base == nil {
+		return nil
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return nil
+	}
+	link := base.ResolveReference(ref)
+	if link.Scheme != "http" && link.Scheme != "https" {
+		return nil
+	}
+	link.Fragment = ""
+	return link
+}
+
+// nodeQuery 编译后的 CSS 选择器或 XPath 表达式
+type nodeQuery struct {
+	css   cascadia.SelectorGroup
+	xpath *xpath.Expr
+}
+
+// compileNodeQuery 编译 CSS 选择器或 XPath，两者均为空时返回 nil
+func compileNodeQuery(cssName, css, xpathName, expr string) (*nodeQuery, error) {
+	switch {
+	case css != "" && expr != "":
+		return nil, fmt.Errorf("%s and %s are mutually exclusive", cssName, xpathName)
+	case css != "":
+		group, err := cascadia.ParseGroup(css)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", cssName, err)
+		}
+		return &nodeQuery{css: group}, nil
+	case expr != "":
+		compiled, err := xpath.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", xpathName, err)
+		}
+		return &nodeQuery{xpath: compiled}, nil
+	}
+	return nil, nil
+}
+
+func (q *nodeQuery) all(node *html.Node) []*html.Node {
+	if q.xpath != nil {
+		return htmlquery.QuerySelectorAll(node, q.xpath)
+	}
+	return cascadia.QueryAll(node, q.css)
+}
+
+func (q *nodeQuery) first(node *html.Node) *html.Node {
+	if q.xpath != nil {
+		return htmlquery.QuerySelector(node, q.xpath)
+	}
+	return cascadia.Query(node, q.css)
+}
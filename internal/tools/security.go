@@ -0,0 +1,915 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/schema"
+)
+
+// security 工具默认限制
+const (
+	defaultSecurityMaxTextBytes = 1 << 20
+	defaultSecurityMaxFindings  = 100
+	defaultMinSecretEntropy     = 3.0
+)
+
+// strengthLabels 强度评分对应的名称
+var strengthLabels = []string{"very_weak", "weak", "fair", "strong", "very_strong"}
+
+// strengthThresholds 评分 1-4 所需的熵（比特）
+var strengthThresholds = []float64{28, 36, 60, 80}
+
+// crackScenarios 估算破解时间的攻击场景（每秒猜测次数）
+var crackScenarios = map[string]float64{
+	"online_throttled": 100.0 / 3600, // 有限流的在线攻击
+	"online":           10,           // 无限流的在线攻击
+	"offline_slow":     1e4,          // 离线攻击 bcrypt、scrypt 等慢哈希
+	"offline_fast":     1e10,         // 离线攻击 MD5、SHA-1 等快哈希
+}
+
+// commonPasswords 常见密码与密码中的常见单词，按出现频率排序
+var commonPasswords = []string{
+	"123456", "password", "12345678", "qwerty", "123456789", "12345", "1234", "111111", "1234567", "dragon",
+	"123123", "baseball", "abc123", "football", "monkey", "letmein", "696969", "shadow", "master", "666666",
+	"qwertyuiop", "123321", "mustang", "1234567890", "michael", "654321", "superman", "1qaz2wsx", "7777777", "121212",
+	"000000", "qazwsx", "123qwe", "killer", "trustno1", "jordan", "jennifer", "zxcvbnm", "asdfgh", "hunter",
+	"buster", "soccer", "harley", "batman", "andrew", "tigger", "sunshine", "iloveyou", "2000", "charlie",
+	"robert", "thomas", "hockey", "ranger", "daniel", "starwars", "klaster", "112233", "george", "computer",
+	"michelle", "jessica", "pepper", "1111", "zxcvbn", "555555", "11111111", "131313", "freedom", "777777",
+	"pass", "maggie", "159753", "aaaaaa", "ginger", "princess", "joshua", "cheese", "amanda", "summer",
+	"love", "ashley", "nicole", "chelsea", "biteme", "matthew", "access", "yankees", "987654321", "dallas",
+	"austin", "thunder", "taylor", "matrix", "admin", "welcome", "login", "passw0rd", "secret", "changeme",
+	"default", "root", "guest", "test", "qwerty123", "password1", "administrator", "letmein1", "winter", "spring",
+	"autumn", "monday", "company", "office", "hello", "flower", "dragon1", "google", "apple", "orange",
+}
+
+// commonPasswordRank 常见密码在列表中的位置
+var commonPasswordRank = func() map[string]int {
+	ranks := make(map[string]int, len(commonPasswords))
+	for i, word := range commonPasswords {
+		if _, exists := ranks[word]; !exists {
+			ranks[word] = i + 1
+		}
+	}
+	return ranks
+}()
+
+// leetSubstitutions 常见的字符替换，如 p@ssw0rd
+var leetSubstitutions = map[rune]rune{
+	'4': 'a', '@': 'a', '3': 'e', '1': 'i', '!': 'i', '0': 'o', '5': 's', '$': 's', '7': 't', '+': 't',
+}
+
+// keyboardRows 键盘上相邻的按键
+var keyboardRows = []string{"qwertyuiop", "asdfghjkl", "zxcvbnm", "1234567890", "!@#$%^&*()", "qazwsxedc"}
+
+// yearPattern 年份
+var yearPattern = regexp.MustCompile(`(?:19|20)\d\d`)
+
+// defaultPasswordPolicy 未配置策略时使用的内置策略
+var defaultPasswordPolicy = config.PasswordPolicyConfig{
+	MinLength:          12,
+	MaxLength:          maxPasswordLength,
+	MinClasses:         3,
+	MinScore:           3,
+	MaxRepeat:          3,
+	DisallowCommon:     true,
+	DisallowUserInputs: true,
+}
+
+// secretRule 密钥检测规则，正则有捕获分组时以第一个分组作为密钥
+type secretRule struct {
+	name        string
+	description string
+	pattern     *regexp.Regexp
+	generic     bool // 通用赋值规则，需检查熵以排除占位符
+}
+
+// builtinSecretRules 内置检测规则，具体的规则在前，通用规则在最后
+var builtinSecretRules = []secretRule{
+	{name: "private_key", description: "Private key", pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{name: "aws_access_key_id", description: "AWS access key ID", pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`)},
+	{name: "aws_secret_access_key", description: "AWS secret access key", pattern: regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`)},
+	{name: "github_token", description: "GitHub token", pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{82})\b`)},
+	{name: "gitlab_token", description: "GitLab personal access token", pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{name: "slack_token", description: "Slack token", pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{name: "slack_webhook", description: "Slack incoming webhook URL", pattern: regexp.MustCompile(`https://hooks\.slack\.com/services/T[A-Za-z0-9_]+/B[A-Za-z0-9_]+/[A-Za-z0-9_]+`)},
+	{name: "stripe_key", description: "Stripe live secret or restricted key", pattern: regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{24,}`)},
+	{name: "google_api_key", description: "Google API key", pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{name: "anthropic_api_key", description: "Anthropic API key", pattern: regexp.MustCompile(`\bsk-ant-[a-z]+\d{2}-[A-Za-z0-9_-]{80,}`)},
+	{name: "openai_api_key", description: "OpenAI API key", pattern: regexp.MustCompile(`\bsk-(?:(?:proj|svcacct|admin)-[A-Za-z0-9_-]{40,}|[A-Za-z0-9]{20}T3BlbkFJ[A-Za-z0-9]{20})`)},
+	{name: "jwt", description: "JSON Web Token", pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{name: "url_credentials", description: "Password embedded in a URL", pattern: regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]+:([^/\s:@]+)@`)},
+	{name: "generic_secret", description: "Secret assigned to a password, key or token field", generic: true, pattern: regexp.MustCompile(
+		"(?i)(?:password|passwd|pwd|secret|api[_-]?key|access[_-]?key|access[_-]?token|auth[_-]?token|private[_-]?key)[\"']?\\s*[:=]\\s*[\"']?([^\\s\"'`,;]{8,})")},
+}
+
+// SecurityTool 密码强度、密码策略与密钥泄露检测工具
+//
+// strength 估算密码的熵与破解时间，识别常见密码、键盘序列、重复与个人信息等可预测模式；
+// policy 按配置的策略检查密码；scan 在文本中查找疑似泄露的 API 密钥、令牌与私钥，结果中的密钥均已脱敏。
+type SecurityTool struct {
+	config  config.SecurityConfig
+	rules   []secretRule
+	ruleErr error
+}
+
+// SecurityArgs 安全检查参数
+type SecurityArgs struct {
+	Op         string   `json:"op"`          // strength, policy, scan
+	Password   string   `json:"password"`    // strength 与 policy：待检查的密码
+	UserInputs []string `json:"user_inputs"` // strength 与 policy：用户名、邮箱等个人信息，密码中包含时降低评分
+	Policy     string   `json:"policy"`      // policy：策略名称
+	Text       string   `json:"text"`        // scan：待扫描的文本
+	Rules      []string `json:"rules"`       // scan：只使用这些检测规则
+}
+
+// SecurityResult 安全检查结果
+type SecurityResult struct {
+	Op         string            `json:"op"`
+	Strength   *PasswordStrength `json:"strength,omitempty"`
+	Policy     string            `json:"policy,omitempty"`
+	Passed     *bool             `json:"passed,omitempty"`
+	Violations []PolicyViolation `json:"violations,omitempty"`
+	Findings   []SecretFinding   `json:"findings,omitempty"`
+	Truncated  bool              `json:"truncated,omitempty"`
+}
+
+// PasswordStrength 密码强度
+type PasswordStrength struct {
+	Score       int               `json:"score"` // 0-4
+	Label       string            `json:"label"`
+	EntropyBits float64           `json:"entropy_bits"`
+	Length      int               `json:"length"`
+	Classes     []string          `json:"classes"`
+	Common      bool              `json:"common"`      // 是常见密码或其简单变形
+	CrackTimes  map[string]string `json:"crack_times"` // 各攻击场景下的估算破解时间
+	Warnings    []string          `json:"warnings,omitempty"`
+	Suggestions []string          `json:"suggestions,omitempty"`
+}
+
+// PolicyViolation 违反的策略规则
+type PolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// SecretFinding 检测到的疑似密钥
+type SecretFinding struct {
+	Rule        string  `json:"rule"`
+	Description string  `json:"description"`
+	Line        int     `json:"line"`
+	Column      int     `json:"column"`
+	Redacted    string  `json:"redacted"` // 脱敏后的密钥
+	Entropy     float64 `json:"entropy"`  // 香农熵（比特/字符）
+}
+
+// NewSecurityTool 创建安全检查工具
+func NewSecurityTool(cfg config.SecurityConfig) *SecurityTool {
+	if cfg.MinSecretEntropy <= 0 {
+		cfg.MinSecretEntropy = defaultMinSecretEntropy
+	}
+	if cfg.MaxTextBytes <= 0 {
+		cfg.MaxTextBytes = defaultSecurityMaxTextBytes
+	}
+	if cfg.MaxFindings <= 0 {
+		cfg.MaxFindings = defaultSecurityMaxFindings
+	}
+
+	disabled := make(map[string]bool, len(cfg.DisabledRules))
+	for _, name := range cfg.DisabledRules {
+		disabled[name] = true
+	}
+	tool := &SecurityTool{config: cfg}
+	var generic []secretRule
+	for _, rule := range builtinSecretRules {
+		if disabled[rule.name] {
+			continue
+		}
+		if rule.generic {
+			generic = append(generic, rule)
+			continue
+		}
+		tool.rules = append(tool.rules, rule)
+	}
+	// 自定义规则排在通用规则之前
+	names := make([]string, 0, len(cfg.Rules))
+	for name := range cfg.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pattern, err := regexp.Compile(cfg.Rules[name])
+		if err != nil {
+			tool.ruleErr = fmt.Errorf("invalid rule %s: %v", name, err)
+			break
+		}
+		tool.rules = append(tool.rules, secretRule{name: name, description: "Custom rule " + name, pattern: pattern})
+	}
+	tool.rules = append(tool.rules, generic...)
+	return tool
+}
+
+func (st *SecurityTool) Name() string {
+	return "security"
+}
+
+func (st *SecurityTool) Description() string {
+	return "Estimate password strength, check passwords against configured policies, and detect leaked API keys, tokens and private keys in text"
+}
+
+func (st *SecurityTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (st *SecurityTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"op":          {Type: schema.TypeString, Description: "Operation", Enum: []interface{}{"strength", "policy", "scan"}},
+		"password":    {Type: schema.TypeString, Description: "Password to evaluate (strength, policy)", MaxLength: schema.Int(maxPasswordLength)},
+		"user_inputs": {Type: schema.TypeArray, Description: "Personal information such as user names or email addresses that should not appear in the password", Items: &schema.Schema{Type: schema.TypeString}},
+		"policy":      {Type: schema.TypeString, Description: "Name of a configured password policy (policy)"},
+		"text":        {Type: schema.TypeString, Description: "Text to scan for leaked secrets (scan)"},
+		"rules":       {Type: schema.TypeArray, Description: "Only use these detection rules (scan)", Items: &schema.Schema{Type: schema.TypeString}},
+	}, "op").Closed()
+}
+
+func (st *SecurityTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var securityArgs SecurityArgs
+	if err := json.Unmarshal(args, &securityArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+
+	result := SecurityResult{Op: securityArgs.Op}
+	var err error
+	switch securityArgs.Op {
+	case "strength":
+		if securityArgs.Password == "" {
+			return nil, fmt.Errorf("password is required for strength")
+		}
+		if utf8.RuneCountInString(securityArgs.Password) > maxPasswordLength {
+			return nil, fmt.Errorf("password must not exceed %d characters", maxPasswordLength)
+		}
+		result.Strength = evaluateStrength(securityArgs.Password, securityArgs.UserInputs)
+	case "policy":
+		err = st.checkPolicy(securityArgs, &result)
+	case "scan":
+		err = st.scan(securityArgs, &result)
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", securityArgs.Op)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// passwordCounts 各类字符的数量
+type passwordCounts struct {
+	lower, upper, digits, symbols, other int
+}
+
+func countPasswordClasses(runes []rune) passwordCounts {
+	var counts passwordCounts
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			counts.lower++
+		case r >= 'A' && r <= 'Z':
+			counts.upper++
+		case r >= '0' && r <= '9':
+			counts.digits++
+		case r < utf8.RuneSelf && unicode.IsPrint(r):
+			counts.symbols++
+		default:
+			counts.other++
+		}
+	}
+	return counts
+}
+
+// pool 字符空间大小
+func (c passwordCounts) pool() int {
+	pool := 0
+	if c.lower > 0 {
+		pool += 26
+	}
+	if c.upper > 0 {
+		pool += 26
+	}
+	if c.digits > 0 {
+		pool += 10
+	}
+	if c.symbols > 0 {
+		pool += 33
+	}
+	if c.other > 0 {
+		pool += 100
+	}
+	return pool
+}
+
+func (c passwordCounts) classes() []string {
+	classes := []string{}
+	for _, class := range []struct {
+		name  string
+		count int
+	}{{"lowercase", c.lower}, {"uppercase", c.upper}, {"digits", c.digits}, {"symbols", c.symbols}, {"other", c.other}} {
+		if class.count > 0 {
+			classes = append(classes, class.name)
+		}
+	}
+	return classes
+}
+
+// strengthAnalyzer 记录被可预测模式覆盖的字符
+//
+// 被模式覆盖的字符不再按字符空间计算熵，而由模式本身贡献少量熵，例如常见密码按其排名计算。
+type strengthAnalyzer struct {
+	lowered    []rune
+	normalized []rune // 还原常见替换后的小写字符
+	weak       []bool
+	bits       float64
+	warnings   []string
+}
+
+// mark 标记 [start, end) 为可预测模式；有新标记的字符时计入模式的熵并记录警告
+func (sa *strengthAnalyzer) mark(start, end int, bits float64, warning string) {
+	fresh := false
+	for i := start; i < end; i++ {
+		if !sa.weak[i] {
+			sa.weak[i] = true
+			fresh = true
+		}
+	}
+	if !fresh {
+		return
+	}
+	sa.bits += bits
+	for _, existing := range sa.warnings {
+		if existing == warning {
+			return
+		}
+	}
+	sa.warnings = append(sa.warnings, warning)
+}
+
+func indexRunes(haystack, needle []rune, from int) int {
+	for i := from; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j, r := range needle {
+			if haystack[i+j] != r {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// evaluateStrength 估算密码强度
+func evaluateStrength(password string, userInputs []string) *PasswordStrength {
+	runes := []rune(password)
+	counts := countPasswordClasses(runes)
+	bitsPerChar := 0.0
+	if pool := counts.pool(); pool > 0 {
+		bitsPerChar = math.Log2(float64(pool))
+	}
+
+	sa := &strengthAnalyzer{
+		lowered:    make([]rune, len(runes)),
+		normalized: make([]rune, len(runes)),
+		weak:       make([]bool, len(runes)),
+	}
+	for i, r := range runes {
+		sa.lowered[i] = unicode.ToLower(r)
+		sa.normalized[i] = sa.lowered[i]
+		if sub, ok := leetSubstitutions[sa.lowered[i]]; ok {
+			sa.normalized[i] = sub
+		}
+	}
+	common := isCommonPassword(runes, sa)
+
+	// 常见密码与单词，长的优先
+	words := make([]string, 0, len(commonPasswords))
+	for _, word := range commonPasswords {
+		if utf8.RuneCountInString(word) >= 4 {
+			words = append(words, word)
+		}
+	}
+	sort.SliceStable(words, func(i, j int) bool { return len(words[i]) > len(words[j]) })
+	for _, word := range words {
+		needle := []rune(word)
+		for pass, source := range [][]rune{sa.lowered, sa.normalized} {
+			for i := indexRunes(source, needle, 0); i >= 0; i = indexRunes(source, needle, i+1) {
+				// 大写与字符替换各增加 1 比特
+				bits := math.Log2(float64(commonPasswordRank[word] + 1))
+				for j := i; j < i+len(needle); j++ {
+					if unicode.IsUpper(runes[j]) {
+						bits++
+						break
+					}
+				}
+				if pass == 1 {
+					bits++
+				}
+				sa.mark(i, i+len(needle), bits, "contains a commonly used password or word")
+			}
+		}
+	}
+
+	// 个人信息
+	for _, input := range userInputs {
+		for _, candidate := range personalCandidates(input) {
+			needle := []rune(candidate)
+			for _, source := range [][]rune{sa.lowered, sa.normalized} {
+				for i := indexRunes(source, needle, 0); i >= 0; i = indexRunes(source, needle, i+1) {
+					sa.mark(i, i+len(needle), 1+math.Log2(float64(len(userInputs))), "avoid personal information such as names or email addresses")
+				}
+			}
+		}
+	}
+
+	findKeyboardPatterns(sa)
+	findSequences(sa)
+	findRepeats(sa, bitsPerChar)
+
+	for _, loc := range yearPattern.FindAllStringIndex(password, -1) {
+		start := utf8.RuneCountInString(password[:loc[0]])
+		sa.mark(start, start+4, math.Log2(120), "dates and years are easy to guess")
+	}
+
+	entropy := sa.bits
+	for _, weak := range sa.weak {
+		if !weak {
+			entropy += bitsPerChar
+		}
+	}
+	if common {
+		entropy = math.Min(entropy, sa.bits)
+	}
+
+	score := 0
+	for score < len(strengthThresholds) && entropy >= strengthThresholds[score] {
+		score++
+	}
+	strength := &PasswordStrength{
+		Score:       score,
+		Label:       strengthLabels[score],
+		EntropyBits: math.Round(entropy*10) / 10,
+		Length:      len(runes),
+		Classes:     counts.classes(),
+		Common:      common,
+		CrackTimes:  make(map[string]string, len(crackScenarios)),
+		Warnings:    sa.warnings,
+	}
+	if common {
+		strength.Warnings = append([]string{"this is a commonly used password"}, strength.Warnings...)
+	}
+	guesses := math.Pow(2, math.Max(entropy-1, 0))
+	for scenario, rate := range crackScenarios {
+		strength.CrackTimes[scenario] = formatCrackTime(guesses / rate)
+	}
+
+	if len(runes) < 12 {
+		strength.Suggestions = append(strength.Suggestions, "use at least 12 characters")
+	}
+	if len(strength.Classes) < 3 {
+		strength.Suggestions = append(strength.Suggestions, "mix uppercase letters, digits and symbols")
+	}
+	if len(strength.Warnings) > 0 {
+		strength.Suggestions = append(strength.Suggestions, "avoid common words, keyboard patterns, repeats and personal information")
+	}
+	if score < 3 {
+		strength.Suggestions = append(strength.Suggestions, "consider a passphrase of several random words")
+	}
+	return strength
+}
+
+// personalCandidates 个人信息中需要在密码里查找的内容，邮箱同时查找 @ 前的部分，忽略少于 3 个字符的内容
+func personalCandidates(input string) []string {
+	input = strings.ToLower(strings.TrimSpace(input))
+	var candidates []string
+	for _, candidate := range []string{input, input[:max(strings.IndexByte(input, '@'), 0)]} {
+		if utf8.RuneCountInString(candidate) >= 3 {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// isCommonPassword 去掉首尾的数字与符号、还原常见替换后是否为常见密码
+func isCommonPassword(runes []rune, sa *strengthAnalyzer) bool {
+	if _, ok := commonPasswordRank[string(sa.lowered)]; ok {
+		return true
+	}
+	affix := func(r rune) bool { return !unicode.IsLetter(r) }
+	start, end := 0, len(runes)
+	for start < end && affix(runes[start]) {
+		start++
+	}
+	for end > start && affix(runes[end-1]) {
+		end--
+	}
+	if end-start < 4 {
+		return false
+	}
+	_, ok := commonPasswordRank[string(sa.normalized[start:end])]
+	return ok
+}
+
+// findKeyboardPatterns 查找键盘上相邻按键组成的序列，至少 4 个字符
+func findKeyboardPatterns(sa *strengthAnalyzer) {
+	n := len(sa.lowered)
+	for i := 0; i < n; i++ {
+		for length := n - i; length >= 4; length-- {
+			fragment := string(sa.lowered[i : i+length])
+			found := false
+			for _, row := range keyboardRows {
+				if strings.Contains(row, fragment) || strings.Contains(reverseString(row), fragment) {
+					found = true
+					break
+				}
+			}
+			if found {
+				sa.mark(i, i+length, math.Log2(float64(len(keyboardRows)*20))+math.Log2(float64(length)), "keyboard patterns like qwerty are easy to guess")
+				i += length - 1
+				break
+			}
+		}
+	}
+}
+
+// findSequences 查找字母或数字的递增、递减序列，至少 3 个字符
+func findSequences(sa *strengthAnalyzer) {
+	n := len(sa.lowered)
+	for i := 0; i+2 < n; {
+		r := sa.lowered[i]
+		digit := r >= '0' && r <= '9'
+		if !digit && !(r >= 'a' && r <= 'z') {
+			i++
+			continue
+		}
+		delta := sa.lowered[i+1] - r
+		if delta != 1 && delta != -1 {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < n && sa.lowered[j]-sa.lowered[j-1] == delta && (sa.lowered[j] >= '0' && sa.lowered[j] <= '9') == digit {
+			j++
+		}
+		if j-i >= 3 {
+			space := 26.0
+			if digit {
+				space = 10
+			}
+			sa.mark(i, j, math.Log2(space)+1+math.Log2(float64(j-i)), "sequences like abc or 6543 are easy to guess")
+			i = j
+			continue
+		}
+		i++
+	}
+}
+
+// findRepeats 查找重复的字符（aaa）与重复的片段（abcabc），重复部分只计入重复次数的熵
+func findRepeats(sa *strengthAnalyzer, bitsPerChar float64) {
+	n := len(sa.lowered)
+	for i := 0; i < n; {
+		j := i + 1
+		for j < n && sa.lowered[j] == sa.lowered[i] {
+			j++
+		}
+		if j-i >= 3 {
+			sa.mark(i, j, bitsPerChar+math.Log2(float64(j-i)), "repeated characters like aaa are easy to guess")
+		}
+		i = j
+	}
+
+	for i := 0; i < n; i++ {
+		for size := (n - i) / 2; size >= 2; size-- {
+			reps := 1
+			for i+(reps+1)*size <= n && string(sa.lowered[i:i+size]) == string(sa.lowered[i+reps*size:i+(reps+1)*size]) {
+				reps++
+			}
+			if reps >= 2 && reps*size >= 6 {
+				sa.mark(i+size, i+reps*size, math.Log2(float64(reps)), "repeated words like abcabc are only slightly harder to guess than abc")
+				i += reps*size - 1
+				break
+			}
+		}
+	}
+}
+
+func reverseString(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// formatCrackTime 以易读的形式表示破解时间
+func formatCrackTime(seconds float64) string {
+	units := []struct {
+		name    string
+		seconds float64
+	}{
+		{"year", 365 * 24 * 3600},
+		{"month", 30 * 24 * 3600},
+		{"day", 24 * 3600},
+		{"hour", 3600},
+		{"minute", 60},
+		{"second", 1},
+	}
+	if seconds < 1 {
+		return "less than a second"
+	}
+	if seconds >= 100*units[0].seconds {
+		return "centuries"
+	}
+	for _, unit := range units {
+		if seconds >= unit.seconds {
+			count := int(seconds / unit.seconds)
+			if count == 1 {
+				return "1 " + unit.name
+			}
+			return fmt.Sprintf("%d %ss", count, unit.name)
+		}
+	}
+	return "less than a second"
+}
+
+// resolvePolicy 选择密码策略：参数、default_policy、唯一配置的策略，未配置任何策略时使用内置策略
+func (st *SecurityTool) resolvePolicy(name string) (string, config.PasswordPolicyConfig, error) {
+	if name == "" {
+		name = st.config.DefaultPolicy
+	}
+	if name == "" {
+		switch len(st.config.Policies) {
+		case 0:
+			return "default", defaultPasswordPolicy, nil
+		case 1:
+			for only := range st.config.Policies {
+				name = only
+			}
+		default:
+			available := make([]string, 0, len(st.config.Policies))
+			for policy := range st.config.Policies {
+				available = append(available, policy)
+			}
+			sort.Strings(available)
+			return "", config.PasswordPolicyConfig{}, fmt.Errorf("policy is required, available: %s", strings.Join(available, ", "))
+		}
+	}
+	policy, exists := st.config.Policies[name]
+	if !exists {
+		if name == "default" && len(st.config.Policies) == 0 {
+			return name, defaultPasswordPolicy, nil
+		}
+		return "", config.PasswordPolicyConfig{}, fmt.Errorf("unknown policy: %s", name)
+	}
+	return name, policy, nil
+}
+
+// checkPolicy 按策略检查密码，返回全部违反的规则
+func (st *SecurityTool) checkPolicy(args SecurityArgs, result *SecurityResult) error {
+	if args.Password == "" {
+		return fmt.Errorf("password is required for policy")
+	}
+	if utf8.RuneCountInString(args.Password) > maxPasswordLength {
+		return fmt.Errorf("password must not exceed %d characters", maxPasswordLength)
+	}
+	name, policy, err := st.resolvePolicy(args.Policy)
+	if err != nil {
+		return err
+	}
+	var pattern *regexp.Regexp
+	if policy.Pattern != "" {
+		if pattern, err = regexp.Compile(policy.Pattern); err != nil {
+			return fmt.Errorf("policy %s has an invalid pattern: %v", name, err)
+		}
+	}
+
+	runes := []rune(args.Password)
+	counts := countPasswordClasses(runes)
+	lowered := strings.ToLower(args.Password)
+	strength := evaluateStrength(args.Password, args.UserInputs)
+	violations := []PolicyViolation{}
+	violate := func(rule, format string, a ...interface{}) {
+		violations = append(violations, PolicyViolation{Rule: rule, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if policy.MinLength > 0 && len(runes) < policy.MinLength {
+		violate("min_length", "must be at least %d characters", policy.MinLength)
+	}
+	if policy.MaxLength > 0 && len(runes) > policy.MaxLength {
+		violate("max_length", "must be at most %d characters", policy.MaxLength)
+	}
+	for _, class := range []struct {
+		rule, name string
+		min, count int
+	}{
+		{"min_lowercase", "lowercase letters", policy.MinLowercase, counts.lower},
+		{"min_uppercase", "uppercase letters", policy.MinUppercase, counts.upper},
+		{"min_digits", "digits", policy.MinDigits, counts.digits},
+		{"min_symbols", "symbols", policy.MinSymbols, counts.symbols},
+	} {
+		if class.count < class.min {
+			violate(class.rule, "must contain at least %d %s", class.min, class.name)
+		}
+	}
+	if policy.MinClasses > 0 {
+		classes := 0
+		for _, count := range []int{counts.lower, counts.upper, counts.digits, counts.symbols + counts.other} {
+			if count > 0 {
+				classes++
+			}
+		}
+		if classes < policy.MinClasses {
+			violate("min_classes", "must mix at least %d of lowercase letters, uppercase letters, digits and symbols", policy.MinClasses)
+		}
+	}
+	if policy.MinScore > 0 && strength.Score < policy.MinScore {
+		violate("min_score", "strength score %d is below the required %d", strength.Score, policy.MinScore)
+	}
+	if policy.MaxRepeat > 0 {
+		run := 1
+		for i := 1; i < len(runes); i++ {
+			if runes[i] == runes[i-1] {
+				run++
+				if run > policy.MaxRepeat {
+					violate("max_repeat", "must not repeat a character more than %d times in a row", policy.MaxRepeat)
+					break
+				}
+			} else {
+				run = 1
+			}
+		}
+	}
+	if policy.DisallowCommon && strength.Common {
+		violate("disallow_common", "must not be a commonly used password")
+	}
+	if policy.DisallowUserInputs {
+		contains := false
+		for _, input := range args.UserInputs {
+			for _, candidate := range personalCandidates(input) {
+				contains = contains || strings.Contains(lowered, candidate)
+			}
+		}
+		if contains {
+			violate("disallow_user_inputs", "must not contain personal information")
+		}
+	}
+	for _, forbidden := range policy.Forbidden {
+		if forbidden != "" && strings.Contains(lowered, strings.ToLower(forbidden)) {
+			violate("forbidden", "must not contain %q", forbidden)
+		}
+	}
+	if pattern != nil && !pattern.MatchString(args.Password) {
+		violate("pattern", "must match %s", policy.Pattern)
+	}
+
+	passed := len(violations) == 0
+	result.Policy = name
+	result.Passed = &passed
+	result.Violations = violations
+	result.Strength = strength
+	return nil
+}
+
+// scan 查找疑似泄露的密钥，与已发现的密钥重叠的匹配会被忽略，因此具体规则优先于通用规则
+func (st *SecurityTool) scan(args SecurityArgs, result *SecurityResult) error {
+	if st.ruleErr != nil {
+		return st.ruleErr
+	}
+	if args.Text == "" {
+		return fmt.Errorf("text is required for scan")
+	}
+	if len(args.Text) > st.config.MaxTextBytes {
+		return fmt.Errorf("text exceeds %d bytes", st.config.MaxTextBytes)
+	}
+
+	rules := st.rules
+	if len(args.Rules) > 0 {
+		known := make(map[string]secretRule, len(st.rules))
+		for _, rule := range st.rules {
+			known[rule.name] = rule
+		}
+		wanted := make(map[string]bool, len(args.Rules))
+		for _, name := range args.Rules {
+			if _, exists := known[name]; !exists {
+				return fmt.Errorf("unknown rule: %s", name)
+			}
+			wanted[name] = true
+		}
+		rules = nil
+		for _, rule := range st.rules {
+			if wanted[rule.name] {
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	type span struct{ start, end int }
+	var covered []span
+	overlaps := func(start, end int) bool {
+		for _, s := range covered {
+			if start < s.end && s.start < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	findings := []SecretFinding{}
+scan:
+	for _, rule := range rules {
+		for _, loc := range rule.pattern.FindAllStringSubmatchIndex(args.Text, -1) {
+			start, end := loc[0], loc[1]
+			if len(loc) >= 4 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+			if start == end || overlaps(start, end) {
+				continue
+			}
+			secret := args.Text[start:end]
+			entropy := shannonEntropy(secret)
+			if rule.generic && (entropy < st.config.MinSecretEntropy || isPlaceholder(secret)) {
+				continue
+			}
+			if len(findings) >= st.config.MaxFindings {
+				result.Truncated = true
+				break scan
+			}
+			covered = append(covered, span{start, end})
+			line := strings.Count(args.Text[:start], "\n") + 1
+			lineStart := strings.LastIndexByte(args.Text[:start], '\n') + 1
+			findings = append(findings, SecretFinding{
+				Rule:        rule.name,
+				Description: rule.description,
+				Line:        line,
+				Column:      utf8.RuneCountInString(args.Text[lineStart:start]) + 1,
+				Redacted:    redactSecret(secret),
+				Entropy:     math.Round(entropy*100) / 100,
+			})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+		return findings[i].Column < findings[j].Column
+	})
+	result.Findings = findings
+	return nil
+}
+
+// shannonEntropy 每个字符的香农熵
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// isPlaceholder 模板变量与示例值不视为密钥
+func isPlaceholder(value string) bool {
+	if strings.Contains(value, "${") || strings.Contains(value, "{{") || strings.HasPrefix(value, "<") || strings.HasPrefix(value, "%") || strings.HasPrefix(value, "$") {
+		return true
+	}
+	lowered := strings.ToLower(value)
+	for _, marker := range []string{"example", "placeholder", "redacted", "changeme", "your_", "your-", "xxxx", "****"} {
+		if strings.Contains(lowered, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactSecret 只保留密钥的前 4 个字符
+func redactSecret(secret string) string {
+	runes := []rune(secret)
+	if len(runes) < 12 {
+		return "****"
+	}
+	return string(runes[:4]) + "****"
+}
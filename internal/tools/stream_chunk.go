@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultStreamChunkSize 不支持流式输出的工具在流式调用中推送结果的默认片段大小（字节）
+const DefaultStreamChunkSize = 16 * 1024
+
+// SetStreamChunkSize 设置不支持流式输出的工具在流式调用中推送结果的片段大小（字节），
+// 0 为使用默认值，负数为不拆分、只返回结果
+func (tm *ToolManager) SetStreamChunkSize(size int) {
+	if size == 0 {
+		size = DefaultStreamChunkSize
+	}
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.streamChunkSize = size
+}
+
+// streamResult 将非流式工具的文本结果按片段依次推送给流式回调，使客户端可以逐步渲染；
+// 结果不超过一个片段或执行出错时不推送
+func (tm *ToolManager) streamResult(result *ToolCallResult, callback StreamCallback) {
+	tm.mu.RLock()
+	size := tm.streamChunkSize
+	tm.mu.RUnlock()
+	if size <= 0 || callback == nil || result == nil || result.IsError {
+		return
+	}
+
+	total := 0
+	for _, content := range result.Content {
+		if content.Type == "text" {
+			total += len(content.Text)
+		}
+	}
+	if total <= size {
+		return
+	}
+
+	index := 0
+	for _, content := range result.Content {
+		if content.Type != "text" {
+			continue
+		}
+		for text := content.Text; text != ""; index++ {
+			cut := chunkCut(text, size)
+			callback(text[:cut], index)
+			text = text[cut:]
+		}
+	}
+}
+
+// chunkCut 返回不超过 size 字节的片段长度：片段后半部分有换行时在最后一个换行之后切分，
+// 否则在不拆分 UTF-8 字符的位置切分
+func chunkCut(text string, size int) int {
+	if len(text) <= size {
+		return len(text)
+	}
+	if newline := strings.LastIndexByte(text[:size], '\n'); newline >= size/2 {
+		return newline + 1
+	}
+	cut := size
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		// 片段小于单个字符时整体输出该字符
+		_, width := utf8.DecodeRuneInString(text)
+		return width
+	}
+	return cut
+}
@@ -1,182 +1,370 @@
-package tools
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"strings"
-	"time"
-)
-
-// StreamTextProcessor 流式文本处理工具
-type StreamTextProcessor struct{}
-
-// StreamTextArgs 流式文本处理参数
-type StreamTextArgs struct {
-	Text      string `json:"text"`
-	Operation string `json:"operation"` // split, reverse, count, analyze
-}
-
-// StreamTextResult 流式文本处理结果
-type StreamTextResult struct {
-	OriginalText string      `json:"original_text"`
-	Result       interface{} `json:"result"`
-	Operation    string      `json:"operation"`
-}
-
-// parseArguments 统一参数解析函数
-func parseArguments(args json.RawMessage) (StreamTextArgs, error) {
-	var textArgs StreamTextArgs
-
-	// 首先尝试解析为 map
-	var rawArgs map[string]interface{}
-	if err := json.Unmarshal(args, &rawArgs); err != nil {
-		return textArgs, fmt.Errorf("invalid JSON format: %v", err)
-	}
-
-	// 提取参数
-	if textVal, ok := rawArgs["text"].(string); ok {
-		textArgs.Text = textVal
-	}
-
-	if opVal, ok := rawArgs["operation"].(string); ok {
-		textArgs.Operation = opVal
-	} else {
-		// 如果没有提供 operation 参数，使用默认值
-		textArgs.Operation = "analyze"
-	}
-
-	// 验证参数
-	if textArgs.Text == "" {
-		return textArgs, fmt.Errorf("text parameter is required")
-	}
-
-	return textArgs, nil
-}
-
-func (stp *StreamTextProcessor) Name() string {
-	return "stream_text_processor"
-}
-
-func (stp *StreamTextProcessor) Description() string {
-	return "Process text with streaming output (split, reverse, count, analyze)"
-}
-
-func (stp *StreamTextProcessor) Category() ToolCategory {
-	return CategoryUtility
-}
-
-func (stp *StreamTextProcessor) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
-	// 使用统一的参数解析函数
-	textArgs, err := parseArguments(args)
-	if err != nil {
-		return nil, err
-	}
-
-	var result interface{}
-	switch textArgs.Operation {
-	case "split":
-		result = strings.Fields(textArgs.Text)
-	case "reverse":
-		runes := []rune(textArgs.Text)
-		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
-			runes[i], runes[j] = runes[j], runes[i]
-		}
-		result = string(runes)
-	case "count":
-		result = map[string]int{
-			"characters": len(textArgs.Text),
-			"words":      len(strings.Fields(textArgs.Text)),
-			"lines":      len(strings.Split(textArgs.Text, "\n")),
-		}
-	case "analyze":
-		result = map[string]interface{}{
-			"length":        len(textArgs.Text),
-			"word_count":    len(strings.Fields(textArgs.Text)),
-			"line_count":    len(strings.Split(textArgs.Text, "\n")),
-			"has_uppercase": strings.ToLower(textArgs.Text) != textArgs.Text,
-			"has_lowercase": strings.ToUpper(textArgs.Text) != textArgs.Text,
-		}
-	default:
-		return nil, fmt.Errorf("unsupported operation: %s", textArgs.Operation)
-	}
-
-	return json.Marshal(StreamTextResult{
-		OriginalText: textArgs.Text,
-		Result:       result,
-		Operation:    textArgs.Operation,
-	})
-}
-
-// ExecuteStream 流式执行文本处理
-func (stp *StreamTextProcessor) ExecuteStream(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
-	// 使用统一的参数解析函数
-	textArgs, err := parseArguments(args)
-	if err != nil {
-		return nil, err
-	}
-
-	// 流式处理流程
-	callback("开始文本处理...", 0)
-	time.Sleep(100 * time.Millisecond)
-
-	callback(fmt.Sprintf("输入文本长度: %d 字符", len(textArgs.Text)), 1)
-	time.Sleep(100 * time.Millisecond)
-
-	callback(fmt.Sprintf("处理操作: %s", textArgs.Operation), 2)
-	time.Sleep(100 * time.Millisecond)
-
-	// 使用普通调用获取结果，然后流式展示处理过程
-	result, err := stp.Execute(ctx, args)
-	if err != nil {
-		callback(fmt.Sprintf("处理失败: %s", err.Error()), 3)
-		return nil, err
-	}
-
-	// 解析结果用于流式展示
-	var streamResult StreamTextResult
-	if err := json.Unmarshal(result, &streamResult); err != nil {
-		callback("结果解析失败", 3)
-		return nil, err
-	}
-
-	// 根据操作类型展示不同的处理过程
-	switch textArgs.Operation {
-	case "split":
-		callback("正在分割文本...", 3)
-		if words, ok := streamResult.Result.([]interface{}); ok {
-			for i, word := range words {
-				callback(fmt.Sprintf("单词 %d: %s", i+1, word), 4+i)
-				time.Sleep(30 * time.Millisecond)
-			}
-		}
-		callback("文本分割完成", 4+len(streamResult.Result.([]interface{})))
-
-	case "reverse":
-		callback("正在反转文本...", 3)
-		time.Sleep(200 * time.Millisecond)
-		callback("文本反转完成", 4)
-		callback(fmt.Sprintf("结果: %s", streamResult.Result), 5)
-
-	case "count":
-		callback("正在统计文本信息...", 3)
-		time.Sleep(200 * time.Millisecond)
-		if counts, ok := streamResult.Result.(map[string]interface{}); ok {
-			callback(fmt.Sprintf("统计完成: %v 字符, %v 单词, %v 行",
-				counts["characters"], counts["words"], counts["lines"]), 4)
-		}
-
-	case "analyze":
-		callback("正在分析文本特征...", 3)
-		time.Sleep(200 * time.Millisecond)
-		callback("文本分析完成", 4)
-
-	default:
-		callback(fmt.Sprintf("错误：不支持的操作类型 %s", textArgs.Operation), 3)
-		return nil, fmt.Errorf("unsupported operation: %s", textArgs.Operation)
-	}
-
-	callback("处理完成！", 100)
-
-	return result, nil
-}
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/chunk"
+	"Weave-Toolkit/internal/schema"
+)
+
+// StreamTextProcessor 流式文本处理工具
+//
+// 流式调用依次推送处理过程，相邻消息之间按配置的间隔等待（默认不等待），等待期间上下文取消时立即返回。
+type StreamTextProcessor struct {
+	pacing time.Duration
+}
+
+// NewStreamTextProcessor 创建流式文本处理工具
+func NewStreamTextProcessor(cfg config.StreamTextConfig) *StreamTextProcessor {
+	return &StreamTextProcessor{pacing: time.Duration(max(cfg.PacingMS, 0)) * time.Millisecond}
+}
+
+// StreamTextArgs 流式文本处理参数
+type StreamTextArgs struct {
+	Text      string `json:"text"`
+	Operation string `json:"operation"`  // split, reverse, count, analyze 以及 textOperations 中的转换操作
+	Model     string `json:"model"`      // 估算 token 数所用的模型或 tiktoken 编码
+	MaxTokens int    `json:"max_tokens"` // truncate 保留的最大 token 数
+	Top       int    `json:"top"`        // analyze 返回的高频词个数
+}
+
+// textOperations 大小写转换、按行去重排序、提取与规范化等转换操作，由 transformText 执行
+var textOperations = []string{
+	"upper", "lower", "title", "snake", "kebab", "camel",
+	"dedupe", "sort", "extract_urls", "extract_emails", "normalize_whitespace", "truncate",
+}
+
+// 未指定模型时按其编码估算 token 数
+const defaultTokenModel = "gpt-4o"
+
+// StreamTextResult 流式文本处理结果
+type StreamTextResult struct {
+	OriginalText string      `json:"original_text"`
+	Result       interface{} `json:"result"`
+	Operation    string      `json:"operation"`
+}
+
+// parseArguments 统一参数解析函数
+func parseArguments(args json.RawMessage) (StreamTextArgs, error) {
+	var textArgs StreamTextArgs
+
+	// 首先尝试解析为 map
+	var rawArgs map[string]interface{}
+	if err := json.Unmarshal(args, &rawArgs); err != nil {
+		return textArgs, fmt.Errorf("invalid JSON format: %v", err)
+	}
+
+	// 提取参数
+	if textVal, ok := rawArgs["text"].(string); ok {
+		textArgs.Text = textVal
+	}
+
+	if opVal, ok := rawArgs["operation"].(string); ok {
+		textArgs.Operation = opVal
+	} else {
+		// 如果没有提供 operation 参数，使用默认值
+		textArgs.Operation = "analyze"
+	}
+
+	textArgs.Model = defaultTokenModel
+	if modelVal, ok := rawArgs["model"].(string); ok && modelVal != "" {
+		textArgs.Model = modelVal
+	}
+	if maxTokens, ok := rawArgs["max_tokens"].(float64); ok {
+		textArgs.MaxTokens = int(maxTokens)
+	}
+	textArgs.Top = defaultTopWords
+	if top, ok := rawArgs["top"].(float64); ok {
+		textArgs.Top = min(max(int(top), 0), maxTopWords)
+	}
+
+	// 验证参数
+	if textArgs.Text == "" {
+		return textArgs, fmt.Errorf("text parameter is required")
+	}
+
+	return textArgs, nil
+}
+
+func (stp *StreamTextProcessor) Name() string {
+	return "stream_text_processor"
+}
+
+func (stp *StreamTextProcessor) Description() string {
+	return "Process text with streaming output: split, reverse, count and analyze; case conversion (upper, lower, title, snake, kebab, camel); line dedupe and sort; URL and email extraction; whitespace normalization; truncation to a token budget. analyze detects the language and reports Flesch readability for English and the most frequent words; count reports bytes, characters, grapheme clusters, words, lines, sentences and estimated LLM tokens for context budgeting"
+}
+
+func (stp *StreamTextProcessor) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (stp *StreamTextProcessor) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"text": {Type: schema.TypeString, Description: "Text to process", MinLength: schema.Int(1)},
+		"operation": {
+			Type:        schema.TypeString,
+			Description: "Processing operation",
+			Enum:        textOperationEnum(),
+			Default:     "analyze",
+		},
+		"model": {
+			Type:        schema.TypeString,
+			Description: "Model or tiktoken encoding (o200k_base, cl100k_base, p50k_base) used to estimate token counts",
+			Default:     defaultTokenModel,
+		},
+		"max_tokens": {Type: schema.TypeInteger, Description: "Token budget for truncate, estimated with model", Minimum: schema.Float(1)},
+		"top":        {Type: schema.TypeInteger, Description: "Number of most frequent words returned by analyze, excluding stopwords", Default: defaultTopWords, Minimum: schema.Float(0), Maximum: schema.Float(maxTopWords)},
+	}, "text")
+}
+
+func (stp *StreamTextProcessor) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	// 使用统一的参数解析函数
+	textArgs, err := parseArguments(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	switch textArgs.Operation {
+	case "split":
+		result = strings.Fields(textArgs.Text)
+	case "reverse":
+		runes := []rune(textArgs.Text)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		result = string(runes)
+	case "count":
+		// characters 按 Unicode 码点计数，graphemes 为用户感知的字符数，tokens 为按模型编码估算的 token 数
+		result = map[string]interface{}{
+			"bytes":      len(textArgs.Text),
+			"characters": utf8.RuneCountInString(textArgs.Text),
+			"graphemes":  countGraphemes(textArgs.Text),
+			"words":      len(strings.Fields(textArgs.Text)),
+			"lines":      len(strings.Split(textArgs.Text, "\n")),
+			"sentences":  chunk.CountSentences(textArgs.Text),
+			"tokens":     chunk.EstimateModelTokens(textArgs.Text, textArgs.Model),
+			"encoding":   chunk.EncodingForModel(textArgs.Model),
+		}
+	case "analyze":
+		words := analysisWords(textArgs.Text)
+		language := detectLanguage(textArgs.Text, words)
+		analysis := map[string]interface{}{
+			"length":         utf8.RuneCountInString(textArgs.Text),
+			"word_count":     len(strings.Fields(textArgs.Text)),
+			"line_count":     len(strings.Split(textArgs.Text, "\n")),
+			"sentence_count": chunk.CountSentences(textArgs.Text),
+			"has_uppercase":  strings.ToLower(textArgs.Text) != textArgs.Text,
+			"has_lowercase":  strings.ToUpper(textArgs.Text) != textArgs.Text,
+			"language":       language,
+			"top_words":      topWords(words, language.Code, textArgs.Top),
+		}
+		// Flesch 公式基于英文音节，其他语言不返回可读性
+		if language.Code == "en" {
+			if readability := fleschReadability(textArgs.Text, words); readability != nil {
+				analysis["readability"] = readability
+			}
+		}
+		result = analysis
+	default:
+		transformed, ok, err := transformText(ctx, textArgs)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("unsupported operation: %s", textArgs.Operation)
+		}
+		result = transformed
+	}
+
+	return json.Marshal(StreamTextResult{
+		OriginalText: textArgs.Text,
+		Result:       result,
+		Operation:    textArgs.Operation,
+	})
+}
+
+// ExecuteStream 以回调流式执行文本处理，供仍使用 StreamTool 接口的调用方
+func (stp *StreamTextProcessor) ExecuteStream(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	return stp.Stream(ctx, args, CallbackEmitter(callback))
+}
+
+// Stream 流式执行文本处理，依次推送处理过程并上报进度
+func (stp *StreamTextProcessor) Stream(ctx context.Context, args json.RawMessage, emit Emitter) (json.RawMessage, error) {
+	// 使用统一的参数解析函数
+	textArgs, err := parseArguments(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// 进度中的数字按客户端区域设置格式化
+	formatter := FormatterFromContext(ctx)
+
+	// 处理分为准备、执行、展示三个阶段
+	const steps = 3
+
+	// 流式处理流程
+	emit.Progress(0, steps, "开始文本处理")
+	emit.Partial("开始文本处理...")
+	if err := stp.pace(ctx); err != nil {
+		return nil, err
+	}
+
+	emit.Partial(fmt.Sprintf("输入文本长度: %s 字符", formatter.Integer(utf8.RuneCountInString(textArgs.Text))))
+	if err := stp.pace(ctx); err != nil {
+		return nil, err
+	}
+
+	emit.Partial(fmt.Sprintf("处理操作: %s", textArgs.Operation))
+	if err := stp.pace(ctx); err != nil {
+		return nil, err
+	}
+	emit.Progress(1, steps, "参数解析完成")
+
+	// 使用普通调用获取结果，然后流式展示处理过程
+	result, err := stp.Execute(ctx, args)
+	if err != nil {
+		emit.Partial(fmt.Sprintf("处理失败: %s", err.Error()))
+		return nil, err
+	}
+	emit.Progress(2, steps, "处理完成，正在展示结果")
+
+	// 解析结果用于流式展示
+	var streamResult StreamTextResult
+	if err := json.Unmarshal(result, &streamResult); err != nil {
+		emit.Log(LogError, fmt.Sprintf("结果解析失败: %v", err))
+		emit.Partial("结果解析失败")
+		return nil, err
+	}
+
+	// 根据操作类型展示不同的处理过程
+	switch textArgs.Operation {
+	case "split":
+		emit.Partial("正在分割文本...")
+		if words, ok := streamResult.Result.([]interface{}); ok {
+			for i, word := range words {
+				emit.Partial(fmt.Sprintf("单词 %d: %s", i+1, word))
+				if err := stp.pace(ctx); err != nil {
+					return nil, err
+				}
+			}
+		}
+		emit.Partial("文本分割完成")
+
+	case "reverse":
+		emit.Partial("正在反转文本...")
+		if err := stp.pace(ctx); err != nil {
+			return nil, err
+		}
+		emit.Partial("文本反转完成")
+		emit.Partial(fmt.Sprintf("结果: %s", streamResult.Result))
+
+	case "count":
+		emit.Partial("正在统计文本信息...")
+		if err := stp.pace(ctx); err != nil {
+			return nil, err
+		}
+		if counts, ok := streamResult.Result.(map[string]interface{}); ok {
+			count := func(key string) string {
+				v, _ := counts[key].(float64)
+				return formatter.Number(v)
+			}
+			emit.Partial(fmt.Sprintf("统计完成: %s 字符, %s 单词, %s 行, %s 句, 约 %s token",
+				count("characters"), count("words"), count("lines"), count("sentences"), count("tokens")))
+		}
+
+	case "analyze":
+		emit.Partial("正在分析文本特征...")
+		if err := stp.pace(ctx); err != nil {
+			return nil, err
+		}
+		if analysis, ok := streamResult.Result.(map[string]interface{}); ok {
+			if language, ok := analysis["language"].(map[string]interface{}); ok {
+				emit.Partial(fmt.Sprintf("检测到语言: %v (%v)", language["name"], language["code"]))
+			}
+		}
+		emit.Partial("文本分析完成")
+
+	default:
+		if slices.Contains(textOperations, textArgs.Operation) {
+			emit.Partial("文本转换完成")
+			break
+		}
+		emit.Partial(fmt.Sprintf("错误：不支持的操作类型 %s", textArgs.Operation))
+		return nil, fmt.Errorf("unsupported operation: %s", textArgs.Operation)
+	}
+
+	emit.Partial("处理完成！")
+	emit.Progress(steps, steps, "")
+
+	return result, nil
+}
+
+// pace 在相邻的流式消息之间等待配置的间隔；上下文已取消时立即返回其错误，使取消的流不再继续推送
+func (stp *StreamTextProcessor) pace(ctx context.Context) error {
+	if stp.pacing <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(stp.pacing)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// textOperationEnum 返回 operation 的取值：基础操作与转换操作
+func textOperationEnum() []interface{} {
+	enum := []interface{}{"split", "reverse", "count", "analyze"}
+	for _, op := range textOperations {
+		enum = append(enum, op)
+	}
+	return enum
+}
+
+// countGraphemes 统计用户感知的字符（扩展字素簇）数：组合符号、变体选择符、肤色修饰符、标签字符与韩文中声、终声
+// 并入前一个字符，零宽连接符（ZWJ）连接的 emoji 序列、成对的区域指示符（国旗）与 CRLF 各计为一个
+func countGraphemes(text string) int {
+	count, regional := 0, 0
+	prev := rune(-1)
+	for _, r := range text {
+		switch {
+		case prev < 0:
+			count++
+		case isGraphemeExtend(r), prev == '\u200d', prev == '\r' && r == '\n':
+		case isRegionalIndicator(r) && regional%2 == 1:
+		default:
+			count++
+		}
+		if isRegionalIndicator(r) {
+			regional++
+		} else {
+			regional = 0
+		}
+		prev = r
+	}
+	return count
+}
+
+func isGraphemeExtend(r rune) bool {
+	return unicode.Is(unicode.M, r) ||
+		r == '\u200d' ||
+		r >= 0xFE00 && r <= 0xFE0F || // 变体选择符
+		r >= 0x1F3FB && r <= 0x1F3FF || // emoji 肤色修饰符
+		r >= 0xE0020 && r <= 0xE007F || // 标签字符
+		r >= 0x1160 && r <= 0x11FF // 韩文字母中声与终声
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
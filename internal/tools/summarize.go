@@ -0,0 +1,313 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/chunk"
+	"Weave-Toolkit/internal/llm"
+	"Weave-Toolkit/internal/schema"
+)
+
+// summarize 默认参数
+const (
+	defaultSummarizeChunkTokens   = 3000
+	defaultSummarizeMaxTokens     = 1024
+	defaultSummarizeMaxInputBytes = 4 << 20
+	defaultSummarizeConcurrency   = 4
+	maxSummarizePasses            = 4
+)
+
+// 摘要风格
+const (
+	SummaryStyleParagraph = "paragraph"
+	SummaryStyleBullets   = "bullets"
+	SummaryStyleTLDR      = "tldr"
+)
+
+const summarizeSystemPrompt = "You summarize text accurately. Preserve key facts, names, numbers and conclusions, and never add information that is not in the text."
+
+var summaryStyles = map[string]string{
+	SummaryStyleParagraph: "as a concise paragraph",
+	SummaryStyleBullets:   "as a bulleted list of the key points",
+	SummaryStyleTLDR:      "in one or two sentences",
+}
+
+// SummarizeTool 文本摘要工具
+//
+// 通过 llm 工具生成摘要。输入超过单次请求上限时按 map-reduce 处理：先按段落切分并发摘要各段，
+// 再将分段摘要合并为最终摘要，必要时逐轮合并。流式调用时推送最终摘要的输出片段。
+type SummarizeTool struct {
+	config config.SummarizeConfig
+	llm    *LLMTool
+}
+
+// SummarizeArgs 摘要参数，text 与 uri 二选一
+type SummarizeArgs struct {
+	Text         string `json:"text"`
+	URI          string `json:"uri"`   // 会话资源 URI
+	Style        string `json:"style"` // paragraph, bullets, tldr
+	MaxWords     int    `json:"max_words"`
+	Language     string `json:"language"` // 摘要语言，默认与原文相同
+	Instructions string `json:"instructions"`
+	Provider     string `json:"provider"`
+	Model        string `json:"model"`
+}
+
+// SummarizeResult 摘要结果
+type SummarizeResult struct {
+	Summary string    `json:"summary"`
+	Model   string    `json:"model"`
+	Chunks  int       `json:"chunks"` // 原文切分的片段数，1 表示一次完成
+	Passes  int       `json:"passes"` // 请求轮数
+	Calls   int       `json:"calls"`  // 请求总数
+	Usage   llm.Usage `json:"usage"`
+}
+
+// NewSummarizeTool 创建文本摘要工具
+func NewSummarizeTool(cfg config.SummarizeConfig, llmTool *LLMTool) *SummarizeTool {
+	if cfg.ChunkTokens <= 0 {
+		cfg.ChunkTokens = defaultSummarizeChunkTokens
+	}
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = defaultSummarizeMaxTokens
+	}
+	if cfg.MaxInputBytes <= 0 {
+		cfg.MaxInputBytes = defaultSummarizeMaxInputBytes
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultSummarizeConcurrency
+	}
+	return &SummarizeTool{config: cfg, llm: llmTool}
+}
+
+func (st *SummarizeTool) Name() string {
+	return "summarize"
+}
+
+func (st *SummarizeTool) Description() string {
+	return "Summarize text of any length with an LLM, splitting long inputs into chunks and combining their summaries (map-reduce)"
+}
+
+func (st *SummarizeTool) Category() ToolCategory {
+	return CategoryAI
+}
+
+func (st *SummarizeTool) Tags() []string {
+	return []string{TagNetwork, TagLLM, TagSlow}
+}
+
+func (st *SummarizeTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"text":         {Type: schema.TypeString, Description: "Text to summarize", MinLength: schema.Int(1)},
+		"uri":          {Type: schema.TypeString, Description: "Session resource to summarize instead of text", MinLength: schema.Int(1)},
+		"style":        {Type: schema.TypeString, Description: "Summary style", Enum: []interface{}{SummaryStyleParagraph, SummaryStyleBullets, SummaryStyleTLDR}, Default: SummaryStyleParagraph},
+		"max_words":    {Type: schema.TypeInteger, Description: "Approximate maximum length of the summary in words", Minimum: schema.Float(1)},
+		"language":     {Type: schema.TypeString, Description: "Language of the summary, defaults to the language of the text"},
+		"instructions": {Type: schema.TypeString, Description: "Additional instructions, such as what to focus on"},
+		"provider":     {Type: schema.TypeString, Description: "LLM provider, defaults to the configured provider"},
+		"model":        {Type: schema.TypeString, Description: "LLM model, defaults to the configured model"},
+	}).Closed()
+}
+
+func (st *SummarizeTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	return st.execute(ctx, args, nil)
+}
+
+// ExecuteStream 流式调用，推送最终摘要的输出片段
+func (st *SummarizeTool) ExecuteStream(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	return st.execute(ctx, args, callback)
+}
+
+// summarizeRun 一次摘要调用的参数与累计用量
+type summarizeRun struct {
+	tool     *SummarizeTool
+	args     SummarizeArgs
+	provider string
+	model    string
+
+	mu     sync.Mutex
+	result SummarizeResult
+}
+
+func (st *SummarizeTool) execute(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	var sumArgs SummarizeArgs
+	if err := json.Unmarshal(args, &sumArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	if sumArgs.Style == "" {
+		sumArgs.Style = SummaryStyleParagraph
+	}
+	if _, ok := summaryStyles[sumArgs.Style]; !ok {
+		return nil, fmt.Errorf("unsupported style: %s", sumArgs.Style)
+	}
+
+	text := sumArgs.Text
+	switch {
+	case text != "" && sumArgs.URI != "":
+		return nil, fmt.Errorf("text and uri are mutually exclusive")
+	case sumArgs.URI != "":
+		data, _, err := ReadSessionResource(ctx, sumArgs.URI)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(data) {
+			return nil, fmt.Errorf("resource %s is not UTF-8 text", sumArgs.URI)
+		}
+		text = string(data)
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("text or uri is required")
+	}
+	if len(text) > st.config.MaxInputBytes {
+		return nil, fmt.Errorf("text exceeds %d bytes", st.config.MaxInputBytes)
+	}
+
+	run := &summarizeRun{tool: st, args: sumArgs, provider: st.config.Provider, model: st.config.Model}
+	if sumArgs.Provider != "" {
+		run.provider = sumArgs.Provider
+	}
+	if sumArgs.Model != "" {
+		run.model = sumArgs.Model
+	}
+
+	// map：原文超出上限时分段摘要
+	parts := []string{text}
+	run.result.Chunks = 1
+	if chunk.EstimateTokens(text) > st.config.ChunkTokens {
+		chunks := chunk.Split(text, chunk.Options{Size: st.config.ChunkTokens, Unit: chunk.UnitTokens})
+		run.result.Chunks = len(chunks)
+		parts = make([]string, len(chunks))
+		for i, c := range chunks {
+			parts[i] = c.Text
+		}
+		summaries, err := run.mapParts(ctx, parts, false)
+		if err != nil {
+			return nil, err
+		}
+		parts = summaries
+	}
+
+	// reduce：分段摘要仍超出上限时分组合并，直到可以一次完成
+	for len(parts) > 1 && chunk.EstimateTokens(strings.Join(parts, "\n\n")) > st.config.ChunkTokens {
+		if run.result.Passes >= maxSummarizePasses {
+			return nil, fmt.Errorf("text is too long to summarize in %d passes", maxSummarizePasses)
+		}
+		groups := chunk.Split(strings.Join(parts, "\n\n"), chunk.Options{Size: st.config.ChunkTokens, Unit: chunk.UnitTokens})
+		texts := make([]string, len(groups))
+		for i, group := range groups {
+			texts[i] = group.Text
+		}
+		summaries, err := run.mapParts(ctx, texts, true)
+		if err != nil {
+			return nil, err
+		}
+		parts = summaries
+	}
+
+	var onDelta func(string)
+	if callback != nil {
+		index := 0
+		onDelta = func(delta string) {
+			callback(delta, index)
+			index++
+		}
+	}
+	summary, err := run.complete(ctx, run.finalPrompt(parts), onDelta)
+	if err != nil {
+		return nil, err
+	}
+	run.result.Summary = strings.TrimSpace(summary)
+	run.result.Passes++
+	return json.Marshal(run.result)
+}
+
+// mapParts 并发摘要各部分，结果与输入顺序一致；combined 表示输入本身是分段摘要
+func (r *summarizeRun) mapParts(ctx context.Context, parts []string, combined bool) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	summaries := make([]string, len(parts))
+	errs := make(chan error, len(parts))
+	sem := make(chan struct{}, r.tool.config.Concurrency)
+	var wg sync.WaitGroup
+	for i, part := range parts {
+		wg.Add(1)
+		go func(i int, part string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			prompt := "Summarize this excerpt from a longer document. Keep every key point so the excerpts can be combined later.\n\nExcerpt:\n" + part
+			if combined {
+				prompt = "The following are summaries of consecutive parts of one document. Merge them into one shorter summary that keeps every key point.\n\nSummaries:\n" + part
+			}
+			summary, err := r.complete(ctx, prompt, nil)
+			if err != nil {
+				errs <- err
+				cancel()
+				return
+			}
+			summaries[i] = strings.TrimSpace(summary)
+		}(i, part)
+	}
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+
+	r.result.Passes++
+	return summaries, nil
+}
+
+// finalPrompt 生成最终摘要的提示，多个部分时为合并分段摘要
+func (r *summarizeRun) finalPrompt(parts []string) string {
+	var b strings.Builder
+	if len(parts) == 1 && r.result.Chunks == 1 {
+		fmt.Fprintf(&b, "Summarize the following text %s.", summaryStyles[r.args.Style])
+	} else {
+		fmt.Fprintf(&b, "The following are summaries of consecutive parts of one document. Combine them into a single summary of the whole document %s.", summaryStyles[r.args.Style])
+	}
+	if r.args.MaxWords > 0 {
+		fmt.Fprintf(&b, " Use at most %d words.", r.args.MaxWords)
+	}
+	if r.args.Language != "" {
+		fmt.Fprintf(&b, " Write the summary in %s.", r.args.Language)
+	} else {
+		b.WriteString(" Write the summary in the same language as the text.")
+	}
+	if r.args.Instructions != "" {
+		fmt.Fprintf(&b, "\n\nAdditional instructions: %s", r.args.Instructions)
+	}
+	b.WriteString("\n\nText:\n")
+	b.WriteString(strings.Join(parts, "\n\n"))
+	return b.String()
+}
+
+// complete 发送一次请求并累计用量
+func (r *summarizeRun) complete(ctx context.Context, prompt string, onDelta func(string)) (string, error) {
+	req := llm.Request{
+		Model:     r.model,
+		System:    summarizeSystemPrompt,
+		Messages:  []llm.Message{{Role: "user", Content: prompt}},
+		MaxTokens: r.tool.config.MaxTokens,
+	}
+	completion, err := r.tool.llm.Complete(ctx, r.provider, req, onDelta)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result.Calls++
+	r.result.Model = completion.Model
+	r.result.Usage.InputTokens += completion.Usage.InputTokens
+	r.result.Usage.OutputTokens += completion.Usage.OutputTokens
+	return completion.Content, nil
+}
@@ -0,0 +1,411 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/shirou/gopsutil/v4/mem"
+	"github.com/shirou/gopsutil/v4/process"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/schema"
+)
+
+// sysinfo 默认限制
+const (
+	defaultSysinfoProcessLimit = 20
+	defaultSysinfoMaxProcesses = 200
+	sysinfoSampleInterval      = 500 * time.Millisecond
+)
+
+// 报告的信息类别
+const (
+	SysinfoHost      = "host"
+	SysinfoCPU       = "cpu"
+	SysinfoMemory    = "memory"
+	SysinfoDisk      = "disk"
+	SysinfoLoad      = "load"
+	SysinfoProcesses = "processes"
+)
+
+// 进程排序方式
+const (
+	SysinfoSortCPU    = "cpu"
+	SysinfoSortMemory = "memory"
+)
+
+// defaultSysinfoSections 未指定 sections 时报告的类别，进程列表需显式请求
+var defaultSysinfoSections = []string{SysinfoHost, SysinfoCPU, SysinfoMemory, SysinfoDisk, SysinfoLoad}
+
+// SysinfoTool 主机监控工具
+//
+// 通过 gopsutil 报告运行服务器的主机的 CPU、内存、磁盘使用率、负载与运行时间，以及按名称或用户
+// 过滤、按 CPU 或内存排序的进程列表。CPU 使用率为采样间隔内的平均值；进程命令行可能包含凭据，
+// 默认不返回。
+type SysinfoTool struct {
+	config config.SysinfoConfig
+}
+
+// SysinfoArgs 监控参数
+type SysinfoArgs struct {
+	Sections []string `json:"sections"` // host, cpu, memory, disk, load, processes
+	Name     string   `json:"name"`     // 进程名包含的子串，不区分大小写
+	User     string   `json:"user"`     // 进程所属用户
+	SortBy   string   `json:"sort_by"`  // cpu, memory
+	Limit    int      `json:"limit"`
+}
+
+// SysinfoResult 监控结果，未请求的类别省略
+type SysinfoResult struct {
+	Host      *SysinfoHostInfo   `json:"host,omitempty"`
+	CPU       *SysinfoCPUInfo    `json:"cpu,omitempty"`
+	Memory    *SysinfoMemoryInfo `json:"memory,omitempty"`
+	Disks     []SysinfoDiskInfo  `json:"disks,omitempty"`
+	Load      *load.AvgStat      `json:"load,omitempty"`
+	Processes []SysinfoProcess   `json:"processes,omitempty"`
+	Matched   int                `json:"matched,omitempty"`  // 符合过滤条件的进程数
+	Warnings  []string           `json:"warnings,omitempty"` // 当前平台不支持或读取失败的类别
+}
+
+// SysinfoHostInfo 主机信息
+type SysinfoHostInfo struct {
+	Hostname        string `json:"hostname"`
+	OS              string `json:"os"`
+	Platform        string `json:"platform"`
+	PlatformVersion string `json:"platform_version"`
+	KernelVersion   string `json:"kernel_version"`
+	Arch            string `json:"arch"`
+	Uptime          uint64 `json:"uptime"` // 秒
+	BootTime        string `json:"boot_time"`
+	Procs           uint64 `json:"procs"`
+}
+
+// SysinfoCPUInfo CPU 信息
+type SysinfoCPUInfo struct {
+	Model   string  `json:"model"`
+	Cores   int     `json:"cores"`
+	Threads int     `json:"threads"`
+	Percent float64 `json:"percent"`
+}
+
+// SysinfoMemoryInfo 内存信息，单位为字节
+type SysinfoMemoryInfo struct {
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Available   uint64  `json:"available"`
+	UsedPercent float64 `json:"used_percent"`
+	SwapTotal   uint64  `json:"swap_total"`
+	SwapUsed    uint64  `json:"swap_used"`
+}
+
+// SysinfoDiskInfo 磁盘分区使用情况，单位为字节
+type SysinfoDiskInfo struct {
+	Path        string  `json:"path"`
+	Device      string  `json:"device,omitempty"`
+	FSType      string  `json:"fstype"`
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Free        uint64  `json:"free"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// SysinfoProcess 进程信息
+type SysinfoProcess struct {
+	PID           int32   `json:"pid"`
+	Name          string  `json:"name"`
+	User          string  `json:"user,omitempty"`
+	Status        string  `json:"status,omitempty"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryRSS     uint64  `json:"memory_rss"`
+	MemoryPercent float32 `json:"memory_percent"`
+	Cmdline       string  `json:"cmdline,omitempty"`
+}
+
+// NewSysinfoTool 创建主机监控工具
+func NewSysinfoTool(cfg config.SysinfoConfig) *SysinfoTool {
+	if cfg.MaxProcesses <= 0 {
+		cfg.MaxProcesses = defaultSysinfoMaxProcesses
+	}
+	return &SysinfoTool{config: cfg}
+}
+
+func (st *SysinfoTool) Name() string {
+	return "sysinfo"
+}
+
+func (st *SysinfoTool) Description() string {
+	return "Report CPU, memory and disk usage, load average, uptime and a filtered process list of the host running the server"
+}
+
+func (st *SysinfoTool) Category() ToolCategory {
+	return CategorySystem
+}
+
+func (st *SysinfoTool) InputSchema() *schema.Schema {
+	sections := []interface{}{SysinfoHost, SysinfoCPU, SysinfoMemory, SysinfoDisk, SysinfoLoad, SysinfoProcesses}
+	return schema.Object(map[string]*schema.Schema{
+		"sections": {Type: schema.TypeArray, Description: "Sections to report; all except processes by default", Items: &schema.Schema{Type: schema.TypeString, Enum: sections}, MinItems: schema.Int(1)},
+		"name":     {Type: schema.TypeString, Description: "Only processes whose name contains this text, case-insensitive"},
+		"user":     {Type: schema.TypeString, Description: "Only processes owned by this user"},
+		"sort_by":  {Type: schema.TypeString, Description: "Process sort order", Enum: []interface{}{SysinfoSortCPU, SysinfoSortMemory}, Default: SysinfoSortCPU},
+		"limit":    {Type: schema.TypeInteger, Description: "Maximum number of processes to return", Default: defaultSysinfoProcessLimit, Minimum: schema.Float(1)},
+	}).Closed()
+}
+
+func (st *SysinfoTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var sysArgs SysinfoArgs
+	if err := json.Unmarshal(args, &sysArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	sections := sysArgs.Sections
+	if len(sections) == 0 {
+		sections = defaultSysinfoSections
+	}
+	for _, section := range sections {
+		switch section {
+		case SysinfoHost, SysinfoCPU, SysinfoMemory, SysinfoDisk, SysinfoLoad, SysinfoProcesses:
+		default:
+			return nil, fmt.Errorf("unsupported section: %s", section)
+		}
+	}
+	switch sysArgs.SortBy {
+	case "", SysinfoSortCPU, SysinfoSortMemory:
+	default:
+		return nil, fmt.Errorf("unsupported sort_by: %s", sysArgs.SortBy)
+	}
+	limit := sysArgs.Limit
+	if limit <= 0 {
+		limit = defaultSysinfoProcessLimit
+	}
+	limit = min(limit, st.config.MaxProcesses)
+
+	var result SysinfoResult
+	warn := func(section string, err error) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", section, err))
+	}
+	wants := func(section string) bool { return slices.Contains(sections, section) }
+
+	// 进程 CPU 使用率与主机 CPU 使用率在同一采样间隔内计算
+	var procs *processSample
+	if wants(SysinfoProcesses) {
+		var err error
+		if procs, err = sampleProcesses(ctx); err != nil {
+			warn(SysinfoProcesses, err)
+		}
+	}
+	if wants(SysinfoCPU) {
+		info, err := cpuInfo(ctx)
+		if err != nil {
+			warn(SysinfoCPU, err)
+		} else {
+			result.CPU = info
+		}
+	} else if procs != nil {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(sysinfoSampleInterval):
+		}
+	}
+	if procs != nil {
+		result.Processes, result.Matched = st.processes(ctx, procs, sysArgs, limit)
+	}
+
+	if wants(SysinfoHost) {
+		info, err := host.InfoWithContext(ctx)
+		if err != nil {
+			warn(SysinfoHost, err)
+		} else {
+			result.Host = &SysinfoHostInfo{
+				Hostname:        info.Hostname,
+				OS:              info.OS,
+				Platform:        info.Platform,
+				PlatformVersion: info.PlatformVersion,
+				KernelVersion:   info.KernelVersion,
+				Arch:            runtime.GOARCH,
+				Uptime:          info.Uptime,
+				BootTime:        time.Unix(int64(info.BootTime), 0).UTC().Format(time.RFC3339),
+				Procs:           info.Procs,
+			}
+		}
+	}
+	if wants(SysinfoMemory) {
+		vm, err := mem.VirtualMemoryWithContext(ctx)
+		if err != nil {
+			warn(SysinfoMemory, err)
+		} else {
+			result.Memory = &SysinfoMemoryInfo{Total: vm.Total, Used: vm.Used, Available: vm.Available, UsedPercent: vm.UsedPercent}
+			if swap, err := mem.SwapMemoryWithContext(ctx); err == nil {
+				result.Memory.SwapTotal = swap.Total
+				result.Memory.SwapUsed = swap.Used
+			}
+		}
+	}
+	if wants(SysinfoDisk) {
+		disks, err := st.disks(ctx)
+		if err != nil {
+			warn(SysinfoDisk, err)
+		}
+		result.Disks = disks
+	}
+	if wants(SysinfoLoad) {
+		avg, err := load.AvgWithContext(ctx)
+		if err != nil {
+			warn(SysinfoLoad, err)
+		} else {
+			result.Load = avg
+		}
+	}
+
+	return json.Marshal(result)
+}
+
+// cpuInfo 读取 CPU 型号与核数，并在采样间隔内计算总体使用率
+func cpuInfo(ctx context.Context) (*SysinfoCPUInfo, error) {
+	percents, err := cpu.PercentWithContext(ctx, sysinfoSampleInterval, false)
+	if err != nil {
+		return nil, err
+	}
+	info := &SysinfoCPUInfo{}
+	if len(percents) > 0 {
+		info.Percent = percents[0]
+	}
+	if stats, err := cpu.InfoWithContext(ctx); err == nil && len(stats) > 0 {
+		info.Model = stats[0].ModelName
+	}
+	info.Cores, _ = cpu.CountsWithContext(ctx, false)
+	info.Threads, _ = cpu.CountsWithContext(ctx, true)
+	return info, nil
+}
+
+// disks 报告配置的挂载点，未配置时报告所有物理分区
+func (st *SysinfoTool) disks(ctx context.Context) ([]SysinfoDiskInfo, error) {
+	type mount struct{ path, device string }
+	var mounts []mount
+	if len(st.config.DiskPaths) > 0 {
+		for _, path := range st.config.DiskPaths {
+			mounts = append(mounts, mount{path: path})
+		}
+	} else {
+		partitions, err := disk.PartitionsWithContext(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		for _, partition := range partitions {
+			if seen[partition.Mountpoint] {
+				continue
+			}
+			seen[partition.Mountpoint] = true
+			mounts = append(mounts, mount{path: partition.Mountpoint, device: partition.Device})
+		}
+	}
+
+	var disks []SysinfoDiskInfo
+	var errs []string
+	for _, m := range mounts {
+		usage, err := disk.UsageWithContext(ctx, m.path)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", m.path, err))
+			continue
+		}
+		disks = append(disks, SysinfoDiskInfo{
+			Path:        m.path,
+			Device:      m.device,
+			FSType:      usage.Fstype,
+			Total:       usage.Total,
+			Used:        usage.Used,
+			Free:        usage.Free,
+			UsedPercent: usage.UsedPercent,
+		})
+	}
+	if len(errs) > 0 {
+		return disks, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return disks, nil
+}
+
+// processSample 采样开始时各进程的累计 CPU 时间
+type processSample struct {
+	start time.Time
+	procs []*process.Process
+	times map[int32]float64
+}
+
+func sampleProcesses(ctx context.Context) (*processSample, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sample := &processSample{start: time.Now(), procs: procs, times: make(map[int32]float64, len(procs))}
+	for _, p := range procs {
+		if times, err := p.TimesWithContext(ctx); err == nil {
+			sample.times[p.Pid] = times.User + times.System
+		}
+	}
+	return sample, nil
+}
+
+// processes 按采样间隔内的 CPU 时间计算使用率，过滤、排序后返回前 limit 个进程及匹配总数；
+// 采样期间退出或无权读取的进程被跳过
+func (st *SysinfoTool) processes(ctx context.Context, sample *processSample, args SysinfoArgs, limit int) ([]SysinfoProcess, int) {
+	elapsed := time.Since(sample.start).Seconds()
+	name := strings.ToLower(args.Name)
+
+	var matched []SysinfoProcess
+	for _, p := range sample.procs {
+		procName, err := p.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		if name != "" && !strings.Contains(strings.ToLower(procName), name) {
+			continue
+		}
+		user, _ := p.UsernameWithContext(ctx)
+		if args.User != "" && user != args.User {
+			continue
+		}
+
+		info := SysinfoProcess{PID: p.Pid, Name: procName, User: user}
+		if before, ok := sample.times[p.Pid]; ok && elapsed > 0 {
+			if times, err := p.TimesWithContext(ctx); err == nil {
+				info.CPUPercent = max(0, (times.User+times.System-before)/elapsed*100)
+			}
+		}
+		if memInfo, err := p.MemoryInfoWithContext(ctx); err == nil {
+			info.MemoryRSS = memInfo.RSS
+		}
+		info.MemoryPercent, _ = p.MemoryPercentWithContext(ctx)
+		if status, err := p.StatusWithContext(ctx); err == nil && len(status) > 0 {
+			info.Status = status[0]
+		}
+		if st.config.ExposeCmdline {
+			info.Cmdline, _ = p.CmdlineWithContext(ctx)
+		}
+		matched = append(matched, info)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if args.SortBy == SysinfoSortMemory {
+			return matched[i].MemoryRSS > matched[j].MemoryRSS
+		}
+		if matched[i].CPUPercent != matched[j].CPUPercent {
+			return matched[i].CPUPercent > matched[j].CPUPercent
+		}
+		return matched[i].MemoryRSS > matched[j].MemoryRSS
+	})
+	if len(matched) > limit {
+		return matched[:limit], len(matched)
+	}
+	return matched, len(matched)
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"sort"
+)
+
+// 内置工具使用的能力标签，自定义工具与 tool_tags 配置可以使用任意标签
+const (
+	TagNetwork     = "network"     // 向外部服务发起请求
+	TagDestructive = "destructive" // 可能修改或删除数据
+	TagSlow        = "slow"        // 通常需要数秒以上
+	TagFilesystem  = "filesystem"  // 读写服务器上的文件
+	TagLLM         = "llm"         // 调用大模型服务，按用量计费
+	TagExec        = "exec"        // 运行外部程序
+)
+
+// TaggedTool 声明能力标签的工具，标签用于 tools/list 过滤与租户的标签策略
+type TaggedTool interface {
+	Tool
+	Tags() []string
+}
+
+// ToolFilter 按标签筛选工具
+type ToolFilter struct {
+	Tags        []string `json:"tags"`        // 工具需要包含全部标签
+	ExcludeTags []string `json:"excludeTags"` // 工具不能包含其中任何标签
+}
+
+// Matches 判断标签是否满足筛选条件
+func (f ToolFilter) Matches(tags []string) bool {
+	for _, tag := range f.Tags {
+		if !hasTag(tags, tag) {
+			return false
+		}
+	}
+	for _, tag := range f.ExcludeTags {
+		if hasTag(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter 筛选满足条件的工具
+func (f ToolFilter) Filter(toolInfos []ToolInfo) []ToolInfo {
+	if len(f.Tags) == 0 && len(f.ExcludeTags) == 0 {
+		return toolInfos
+	}
+	matched := make([]ToolInfo, 0, len(toolInfos))
+	for _, info := range toolInfos {
+		if f.Matches(info.Tags) {
+			matched = append(matched, info)
+		}
+	}
+	return matched
+}
+
+// toolTags 合并工具声明的标签与配置中追加的标签，去重并排序
+func toolTags(tool Tool, extra []string) []string {
+	var tags []string
+	if tagged, ok := tool.(TaggedTool); ok {
+		tags = append(tags, tagged.Tags()...)
+	}
+	for _, tag := range extra {
+		if !hasTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// hasTag 判断标签列表是否包含指定标签
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
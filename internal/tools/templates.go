@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"sync"
+
+	"Weave-Toolkit/internal/uritemplate"
+)
+
+// parsedTemplates 已解析的资源模板，按原始模板缓存
+var parsedTemplates sync.Map
+
+// parseTemplate 解析并缓存资源模板
+func parseTemplate(raw string) (*uritemplate.Template, error) {
+	if cached, ok := parsedTemplates.Load(raw); ok {
+		return cached.(*uritemplate.Template), nil
+	}
+	template, err := uritemplate.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	parsedTemplates.Store(raw, template)
+	return template, nil
+}
+
+// ResourceTemplates 列出已启用工具公开的资源模板，忽略无法解析的模板
+func (tm *ToolManager) ResourceTemplates() []ResourceTemplate {
+	var templates []ResourceTemplate
+	for _, provider := range enabledTools[ResourceTemplateProvider](tm) {
+		for _, template := range provider.ResourceTemplates() {
+			if _, err := parseTemplate(template.URITemplate); err != nil {
+				tm.logger.Warn().Err(err).Str("tool", provider.Name()).Msg("Invalid resource template")
+				continue
+			}
+			templates = append(templates, template)
+		}
+	}
+	return templates
+}
+
+// ReadTemplateResource 读取与已启用工具的资源模板匹配的 URI，没有模板匹配时 ok 为 false；
+// 工具按名称顺序、同一工具的模板按声明顺序匹配，使用第一个匹配的模板
+func (tm *ToolManager) ReadTemplateResource(ctx context.Context, uri string) (content, mimeType string, ok bool, err error) {
+	for _, provider := range enabledTools[ResourceTemplateProvider](tm) {
+		for _, template := range provider.ResourceTemplates() {
+			parsed, err := parseTemplate(template.URITemplate)
+			if err != nil {
+				continue
+			}
+			vars, matched := parsed.Match(uri)
+			if !matched {
+				continue
+			}
+			content, mimeType, err = provider.ReadTemplate(ctx, template.URITemplate, vars)
+			if mimeType == "" {
+				mimeType = template.MimeType
+			}
+			return content, mimeType, true, err
+		}
+	}
+	return "", "", false, nil
+}
@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// ErrRateLimited 租户的工具调用超过每分钟限额
+var ErrRateLimited = werrors.New(werrors.KindRateLimited, "rate limit exceeded")
+
+// Tenant 发起调用的租户
+type Tenant struct {
+	Name   string
+	Config config.TenantConfig
+}
+
+// tenantContextKey 租户上下文键
+type tenantContextKey struct{}
+
+// WithTenant 在上下文中记录发起调用的租户
+func WithTenant(ctx context.Context, tenant *Tenant) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext 从上下文中获取租户，未关联租户时返回 nil
+func TenantFromContext(ctx context.Context) *Tenant {
+	tenant, _ := ctx.Value(tenantContextKey{}).(*Tenant)
+	return tenant
+}
+
+// Allows 租户是否可使用该工具，nil 表示不限制
+func (t *Tenant) Allows(name string, category ToolCategory, tags []string) bool {
+	if t == nil {
+		return true
+	}
+	for _, disabled := range t.Config.DisabledTools {
+		if disabled == name {
+			return false
+		}
+	}
+	for _, denied := range t.Config.DenyTags {
+		if hasTag(tags, denied) {
+			return false
+		}
+	}
+	if len(t.Config.Categories) == 0 {
+		return true
+	}
+	for _, allowed := range t.Config.Categories {
+		if allowed == string(category) {
+			return true
+		}
+	}
+	return false
+}
+
+// TenantTools 获取租户可使用的启用工具，tenant 为 nil 时与 GetTools 相同
+func (tm *ToolManager) TenantTools(tenant *Tenant) []ToolInfo {
+	toolInfos := tm.GetTools()
+	if tenant == nil {
+		return toolInfos
+	}
+
+	allowed := toolInfos[:0]
+	for _, info := range toolInfos {
+		if tenant.Allows(info.Name, info.Category, info.Tags) {
+			allowed = append(allowed, info)
+		}
+	}
+	return allowed
+}
+
+// tenantLimiters 按租户名称保存的调用限流器
+type tenantLimiters struct {
+	mu       sync.Mutex
+	limiters map[string]*tenantLimiter
+}
+
+// tenantLimiter 租户的令牌桶，限额随配置重载变化时重建
+type tenantLimiter struct {
+	perMinute int
+	limiter   *rate.Limiter
+}
+
+// allow 消耗一次调用额度
+func (tl *tenantLimiters) allow(tenant string, perMinute int) bool {
+	tl.mu.Lock()
+	limiter, exists := tl.limiters[tenant]
+	if !exists || limiter.perMinute != perMinute {
+		limiter = &tenantLimiter{
+			perMinute: perMinute,
+			limiter:   rate.NewLimiter(rate.Limit(float64(perMinute)/60), perMinute),
+		}
+		tl.limiters[tenant] = limiter
+	}
+	tl.mu.Unlock()
+
+	return limiter.limiter.Allow()
+}
+
+// RateLimiter 跨副本共享的限流器，key 为限流对象，perMinute 为每分钟限额
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, perMinute int) (bool, error)
+}
+
+// SetRateLimiter 设置集群共享的租户限流器，为 nil 时恢复为本地限流
+func (tm *ToolManager) SetRateLimiter(limiter RateLimiter) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.shared = limiter
+}
+
+// allowTenant 消耗租户的一次调用额度
+//
+// 共享限流器不可用时退回本地限流并记录警告，避免后端故障导致所有租户调用失败。
+func (tm *ToolManager) allowTenant(ctx context.Context, tenant string, perMinute int) bool {
+	tm.mu.RLock()
+	shared := tm.shared
+	tm.mu.RUnlock()
+
+	if shared != nil {
+		allowed, err := shared.Allow(ctx, "tenant:"+tenant, perMinute)
+		if err == nil {
+			return allowed
+		}
+		tm.logger.Warn().Err(err).Str("tenant", tenant).Msg("Shared rate limiter unavailable, falling back to local limiter")
+	}
+	return tm.limiters.allow(tenant, perMinute)
+}
+
+// checkTenant 检查上下文中的租户能否调用该工具；不在租户目录中的工具按不存在处理
+func (tm *ToolManager) checkTenant(ctx context.Context, name string, category ToolCategory, tags []string) error {
+	tenant := TenantFromContext(ctx)
+	if tenant == nil {
+		return nil
+	}
+	if !tenant.Allows(name, category, tags) {
+		return fmt.Errorf("%w: %s", ErrToolNotFound, name)
+	}
+	if limit := tenant.Config.RateLimit; limit > 0 && !tm.allowTenant(ctx, tenant.Name, limit) {
+		return fmt.Errorf("%w: tenant %s allows %d calls per minute", ErrRateLimited, tenant.Name, limit)
+	}
+	return nil
+}
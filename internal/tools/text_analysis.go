@@ -0,0 +1,297 @@
+package tools
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"Weave-Toolkit/internal/chunk"
+)
+
+// analyze 返回的词频条目数
+const (
+	defaultTopWords = 10
+	maxTopWords     = 100
+)
+
+// TextLanguage 检测到的语言，Code 为 ISO 639-1 代码，无法判断时为 und
+type TextLanguage struct {
+	Code       string  `json:"code"`
+	Name       string  `json:"name"`
+	Script     string  `json:"script,omitempty"` // 主要文字，没有字母时为空
+	Confidence float64 `json:"confidence"`       // 0-1
+}
+
+// TextReadability 英文文本的 Flesch 可读性指标
+type TextReadability struct {
+	FleschReadingEase  float64 `json:"flesch_reading_ease"`  // 0-100，越高越易读
+	FleschKincaidGrade float64 `json:"flesch_kincaid_grade"` // 对应的美国年级
+	Level              string  `json:"level"`                // very_easy 到 very_difficult
+	WordsPerSentence   float64 `json:"words_per_sentence"`
+	SyllablesPerWord   float64 `json:"syllables_per_word"`
+}
+
+// WordFrequency 词频条目
+type WordFrequency struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// 各语言的高频虚词，用于识别拉丁字母语言并在词频中排除
+var languageStopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "a", "an", "in", "is", "it", "that", "for", "was", "on", "are", "with", "as", "this", "be", "at", "by", "not", "have", "from", "or", "but", "you", "they", "we", "he", "she", "his", "her", "were", "which", "will", "would", "there", "their", "what", "if", "has", "been", "i"},
+	"es": {"el", "la", "de", "que", "y", "en", "los", "las", "del", "se", "por", "un", "una", "con", "no", "es", "para", "su", "al", "lo", "como", "más", "pero", "sus", "le", "ya", "o", "fue", "este", "ha", "muy", "también", "está", "son"},
+	"fr": {"le", "la", "les", "de", "des", "et", "en", "un", "une", "du", "est", "que", "qui", "dans", "pour", "pas", "sur", "au", "avec", "il", "elle", "ce", "ne", "se", "plus", "par", "sont", "mais", "nous", "vous", "ont", "été", "aux", "cette"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "zu", "den", "von", "mit", "sich", "des", "auf", "für", "im", "dem", "auch", "es", "an", "als", "ich", "sie", "er", "wir", "wird", "sind", "oder", "aber", "noch", "nach", "bei", "einer", "über"},
+	"it": {"il", "lo", "la", "i", "gli", "le", "di", "che", "e", "è", "un", "una", "per", "non", "in", "con", "del", "della", "si", "sono", "da", "al", "ma", "come", "anche", "più", "questo", "nel", "alla", "essere", "ha", "ci"},
+	"pt": {"o", "a", "os", "as", "de", "que", "e", "do", "da", "em", "um", "uma", "para", "é", "com", "não", "por", "se", "mais", "dos", "das", "no", "na", "como", "mas", "foi", "ao", "ele", "ela", "seu", "sua", "são", "também", "muito", "está"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "die", "in", "te", "niet", "op", "voor", "met", "zijn", "er", "aan", "ook", "als", "bij", "maar", "om", "dan", "wat", "nog", "wordt", "door", "naar", "ik", "je", "we", "hij", "zij"},
+	"zh": {"的", "了", "是", "在", "和", "也", "就", "都", "而", "及", "与", "这", "那", "有", "我", "你", "他", "她", "它", "们", "不", "一", "个"},
+}
+
+var languageNames = map[string]string{
+	"en": "English", "es": "Spanish", "fr": "French", "de": "German", "it": "Italian", "pt": "Portuguese", "nl": "Dutch",
+	"zh": "Chinese", "ja": "Japanese", "ko": "Korean", "ru": "Russian", "uk": "Ukrainian", "ar": "Arabic", "he": "Hebrew",
+	"el": "Greek", "th": "Thai", "hi": "Hindi", "und": "Undetermined",
+}
+
+// 文字与其唯一对应的语言；Latin、Han 与 Cyrillic 需要进一步判断
+var textScripts = []struct {
+	name     string
+	table    *unicode.RangeTable
+	language string
+}{
+	{"Latin", unicode.Latin, ""},
+	{"Han", unicode.Han, "zh"},
+	{"Kana", unicode.Hiragana, "ja"},
+	{"Kana", unicode.Katakana, "ja"},
+	{"Hangul", unicode.Hangul, "ko"},
+	{"Cyrillic", unicode.Cyrillic, "ru"},
+	{"Arabic", unicode.Arabic, "ar"},
+	{"Hebrew", unicode.Hebrew, "he"},
+	{"Greek", unicode.Greek, "el"},
+	{"Thai", unicode.Thai, "th"},
+	{"Devanagari", unicode.Devanagari, "hi"},
+}
+
+// detectLanguage 按文字判断语言：假名出现时为日语，汉字为中文，西里尔字母中含乌克兰语特有字母时为乌克兰语；
+// 拉丁字母文本按各语言虚词的命中数判断。置信度为主要文字所占比例乘以最佳语言相对次佳语言的优势
+func detectLanguage(text string, words []string) TextLanguage {
+	counts := make(map[string]int)
+	letters := 0
+	ukrainian := false
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range textScripts {
+			if unicode.Is(s.table, r) {
+				counts[s.name]++
+				break
+			}
+		}
+		ukrainian = ukrainian || strings.ContainsRune("іїєґІЇЄҐ", r)
+	}
+	if letters == 0 {
+		return TextLanguage{Code: "und", Name: languageNames["und"]}
+	}
+
+	script := ""
+	for _, s := range textScripts {
+		if counts[s.name] > counts[script] {
+			script = s.name
+		}
+	}
+	// 日文混用汉字与假名，假名占一成以上即按日文处理
+	if (script == "Han" || script == "Kana") && counts["Kana"]*10 >= counts["Han"]+counts["Kana"] {
+		script = "Kana"
+	}
+	share := float64(counts[script]) / float64(letters)
+	if script == "Han" || script == "Kana" {
+		share = float64(counts["Han"]+counts["Kana"]) / float64(letters)
+	}
+
+	language := TextLanguage{Code: "und", Script: script}
+	switch script {
+	case "Latin":
+		best, second := latinLanguage(words)
+		if best.hits > 0 {
+			language.Code = best.code
+			language.Confidence = share * float64(best.hits) / float64(best.hits+second.hits)
+		}
+	default:
+		for _, s := range textScripts {
+			if s.name == script {
+				language.Code = s.language
+				break
+			}
+		}
+		if language.Code == "ru" && ukrainian {
+			language.Code = "uk"
+		}
+		language.Confidence = share
+	}
+	language.Name = languageNames[language.Code]
+	language.Confidence = math.Round(language.Confidence*100) / 100
+	return language
+}
+
+type languageHits struct {
+	code string
+	hits int
+}
+
+// latinLanguage 返回虚词命中数最多与次多的拉丁字母语言
+func latinLanguage(words []string) (best, second languageHits) {
+	for _, code := range []string{"en", "es", "fr", "de", "it", "pt", "nl"} {
+		hits := 0
+		for _, word := range words {
+			if slices.Contains(languageStopwords[code], word) {
+				hits++
+			}
+		}
+		switch {
+		case hits > best.hits:
+			best, second = languageHits{code, hits}, best
+		case hits > second.hits:
+			second = languageHits{code, hits}
+		}
+	}
+	return best, second
+}
+
+// analysisWords 将文本切分为小写的词：字母、数字与词内撇号组成一个词，汉字各自成为一个词，
+// 连续的平假名或片假名各组成一个词
+func analysisWords(text string) []string {
+	var words []string
+	var word []rune
+	wordKana := kanaNone
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.Trim(string(word), "'’"))
+			word = word[:0]
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		kana := kanaClass(r)
+		if kana != wordKana {
+			flush()
+			wordKana = kana
+		}
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			words = append(words, string(r))
+		case unicode.IsLetter(r), unicode.IsMark(r), unicode.IsDigit(r), (r == '\'' || r == '’') && len(word) > 0:
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+const (
+	kanaNone = iota
+	kanaHiragana
+	kanaKatakana
+)
+
+func kanaClass(r rune) int {
+	switch {
+	case unicode.Is(unicode.Hiragana, r):
+		return kanaHiragana
+	case unicode.Is(unicode.Katakana, r), r == 'ー':
+		return kanaKatakana
+	}
+	return kanaNone
+}
+
+// topWords 返回排除虚词与纯数字后出现次数最多的 n 个词，次数相同时按词排序；
+// 日文中只含平假名的词多为助词与词尾，同样排除
+func topWords(words []string, language string, n int) []WordFrequency {
+	counts := make(map[string]int)
+	for _, word := range words {
+		if word == "" || slices.Contains(languageStopwords[language], word) || strings.IndexFunc(word, unicode.IsLetter) < 0 ||
+			language == "ja" && kanaClass([]rune(word)[0]) == kanaHiragana {
+			continue
+		}
+		counts[word]++
+	}
+	frequencies := make([]WordFrequency, 0, len(counts))
+	for word, count := range counts {
+		frequencies = append(frequencies, WordFrequency{Word: word, Count: count})
+	}
+	slices.SortFunc(frequencies, func(a, b WordFrequency) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Word, b.Word)
+	})
+	return frequencies[:min(n, len(frequencies))]
+}
+
+// fleschReadability 计算英文文本的 Flesch 易读度与 Flesch-Kincaid 年级，没有词时返回 nil
+func fleschReadability(text string, words []string) *TextReadability {
+	wordCount, syllables := 0, 0
+	for _, word := range words {
+		if strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		wordCount++
+		syllables += englishSyllables(word)
+	}
+	sentences := max(chunk.CountSentences(text), 1)
+	if wordCount == 0 {
+		return nil
+	}
+	wordsPerSentence := float64(wordCount) / float64(sentences)
+	syllablesPerWord := float64(syllables) / float64(wordCount)
+	ease := 206.835 - 1.015*wordsPerSentence - 84.6*syllablesPerWord
+	round := func(v float64) float64 { return math.Round(v*100) / 100 }
+	return &TextReadability{
+		FleschReadingEase:  round(ease),
+		FleschKincaidGrade: round(0.39*wordsPerSentence + 11.8*syllablesPerWord - 15.59),
+		Level:              readabilityLevel(ease),
+		WordsPerSentence:   round(wordsPerSentence),
+		SyllablesPerWord:   round(syllablesPerWord),
+	}
+}
+
+// englishSyllables 按元音组估算英文单词的音节数：词尾不发音的 e 不计，至少为 1
+func englishSyllables(word string) int {
+	count, vowel := 0, false
+	for _, r := range word {
+		isVowel := strings.ContainsRune("aeiouy", r)
+		if isVowel && !vowel {
+			count++
+		}
+		vowel = isVowel
+	}
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && count > 1 {
+		count--
+	}
+	return max(count, 1)
+}
+
+func readabilityLevel(ease float64) string {
+	switch {
+	case ease >= 90:
+		return "very_easy"
+	case ease >= 80:
+		return "easy"
+	case ease >= 70:
+		return "fairly_easy"
+	case ease >= 60:
+		return "standard"
+	case ease >= 50:
+		return "fairly_difficult"
+	case ease >= 30:
+		return "difficult"
+	default:
+		return "very_difficult"
+	}
+}
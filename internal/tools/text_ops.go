@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"Weave-Toolkit/internal/chunk"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/collate"
+)
+
+var (
+	textURLPattern   = regexp.MustCompile(`(?i)\b(?:https?|ftp)://[^\s<>"'` + "`" + `]+`)
+	textEmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+// 标识符风格转换的分隔符，空字符串表示 camelCase
+var identifierSeparators = map[string]string{"snake": "_", "kebab": "-", "camel": ""}
+
+// transformText 执行文本转换类操作，第二个返回值表示 operation 是否属于此类
+func transformText(ctx context.Context, textArgs StreamTextArgs) (interface{}, bool, error) {
+	text := textArgs.Text
+	switch textArgs.Operation {
+	case "upper":
+		return cases.Upper(LocaleFromContext(ctx)).String(text), true, nil
+	case "lower":
+		return cases.Lower(LocaleFromContext(ctx)).String(text), true, nil
+	case "title":
+		return cases.Title(LocaleFromContext(ctx)).String(text), true, nil
+	case "snake", "kebab", "camel":
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = identifierCase(line, identifierSeparators[textArgs.Operation])
+		}
+		return strings.Join(lines, "\n"), true, nil
+	case "dedupe":
+		lines := textLines(text)
+		seen := make(map[string]bool, len(lines))
+		unique := lines[:0]
+		for _, line := range lines {
+			if !seen[line] {
+				seen[line] = true
+				unique = append(unique, line)
+			}
+		}
+		return strings.Join(unique, "\n"), true, nil
+	case "sort":
+		lines := textLines(text)
+		// 客户端指定区域设置时按其排序规则排序，否则按码点排序
+		if tag, ok := contextLocale(ctx); ok {
+			collate.New(tag).SortStrings(lines)
+		} else {
+			slices.Sort(lines)
+		}
+		return strings.Join(lines, "\n"), true, nil
+	case "extract_urls":
+		return extractMatches(text, textURLPattern, trimURL), true, nil
+	case "extract_emails":
+		return extractMatches(text, textEmailPattern, nil), true, nil
+	case "normalize_whitespace":
+		return normalizeWhitespace(text), true, nil
+	case "truncate":
+		if textArgs.MaxTokens <= 0 {
+			return nil, true, fmt.Errorf("max_tokens must be a positive integer for truncate")
+		}
+		truncated, ok := chunk.TruncateTokens(text, textArgs.Model, textArgs.MaxTokens)
+		return map[string]interface{}{
+			"text":      truncated,
+			"tokens":    chunk.EstimateModelTokens(truncated, textArgs.Model),
+			"truncated": ok,
+			"encoding":  chunk.EncodingForModel(textArgs.Model),
+		}, true, nil
+	}
+	return nil, false, nil
+}
+
+// textLines 按行切分文本：CRLF 视同 LF，末尾的换行不产生空行
+func textLines(text string) []string {
+	return strings.Split(strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), "\n")
+}
+
+// identifierCase 将一行文本转换为 snake_case、kebab-case 或 camelCase，
+// 在非字母数字字符、小写到大写以及连续大写后接小写（HTTPServer）处分词
+func identifierCase(line, separator string) string {
+	var words []string
+	runes := []rune(line)
+	start := -1
+	for i, r := range runes {
+		alnum := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if !alnum {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if separator == "" && i > 0 {
+			first := []rune(word)
+			first[0] = unicode.ToUpper(first[0])
+			word = string(first)
+		}
+		words[i] = word
+	}
+	return strings.Join(words, separator)
+}
+
+// extractMatches 按出现顺序返回去重后的匹配
+func extractMatches(text string, pattern *regexp.Regexp, clean func(string) string) []string {
+	matches := []string{}
+	seen := make(map[string]bool)
+	for _, match := range pattern.FindAllString(text, -1) {
+		if clean != nil {
+			match = clean(match)
+		}
+		if key := strings.ToLower(match); !seen[key] {
+			seen[key] = true
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// trimURL 去掉 URL 末尾的句读，以及没有对应左括号的右括号
+func trimURL(url string) string {
+	for url != "" {
+		last := url[len(url)-1]
+		switch {
+		case strings.IndexByte(".,;:!?", last) >= 0:
+		case last == ')' && strings.Count(url, "(") < strings.Count(url, ")"):
+		case last == ']' && strings.Count(url, "[") < strings.Count(url, "]"):
+		default:
+			return url
+		}
+		url = url[:len(url)-1]
+	}
+	return url
+}
+
+// normalizeWhitespace 将行内连续空白合并为一个空格并去掉行首尾空白，连续空行合并为一行，去掉首尾空行
+func normalizeWhitespace(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	normalized := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			if !blank && len(normalized) > 0 {
+				normalized = append(normalized, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		normalized = append(normalized, line)
+	}
+	return strings.TrimRight(strings.Join(normalized, "\n"), "\n")
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+// ErrToolTimeout 工具执行超过生效的超时时间
+var ErrToolTimeout = werrors.New(werrors.KindTimeout, "tool timed out")
+
+// TimeoutError 工具超时的结构化错误，同时匹配 ErrToolTimeout 与 context.DeadlineExceeded
+type TimeoutError struct {
+	Tool      string
+	Timeout   time.Duration // 本次调用生效的超时时间
+	Requested bool          // 生效的是请求携带的截止时间（X-MCP-Timeout、_meta.timeout 或 gRPC 截止时间），而非工具的超时配置
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s: %s did not finish within %s", ErrToolTimeout, e.Tool, e.Timeout.Round(time.Millisecond))
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrToolTimeout || target == context.DeadlineExceeded
+}
+
+// toolTimeouts 单个工具的超时配置
+func toolTimeouts(toolConfig *config.ToolManagerConfig) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(toolConfig.ToolTimeouts))
+	for name, seconds := range toolConfig.ToolTimeouts {
+		if seconds > 0 {
+			timeouts[name] = time.Duration(seconds) * time.Second
+		}
+	}
+	return timeouts
+}
+
+// SetDefaultTimeout 设置未配置 global.default_timeout 时使用的工具超时，0 为不限制
+func (tm *ToolManager) SetDefaultTimeout(timeout time.Duration) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.fallbackTimeout = timeout
+}
+
+// ToolTimeout 获取工具生效的超时时间，按单个工具、分类、全局默认的顺序取第一个配置值，0 为不限制
+func (tm *ToolManager) ToolTimeout(name string) time.Duration {
+	entry, found := tm.lookupTool(tm.ResolveAlias("", name))
+	if !found {
+		return 0
+	}
+	return entry.timeout
+}
+
+// resolveTimeout 在读锁内按单个工具、分类、全局默认的顺序确定超时时间
+func (tm *ToolManager) resolveTimeout(name string, category *CategoryManager) time.Duration {
+	if timeout, exists := tm.toolTimeouts[name]; exists {
+		return timeout
+	}
+	if category.config.Timeout > 0 {
+		return category.config.Timeout
+	}
+	if tm.defaultTimeout > 0 {
+		return tm.defaultTimeout
+	}
+	return tm.fallbackTimeout
+}
+
+// withTimeout 为调用应用超时，请求自带的截止时间更早时以其为准；返回本次调用的时间预算，0 为不限制
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc, time.Duration) {
+	budget := timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); budget <= 0 || remaining < budget {
+			budget = remaining
+		}
+	}
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, budget
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, budget
+}
+
+// timeoutError 调用因截止时间结束时将工具返回的错误转换为 TimeoutError，timeout 为工具配置的超时
+func timeoutError(ctx context.Context, name string, timeout, budget time.Duration, err error) error {
+	if err == nil || errors.Is(err, ErrToolPanic) || errors.Is(err, ErrToolTimeout) || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return &TimeoutError{Tool: name, Timeout: budget, Requested: timeout <= 0 || budget < timeout}
+}
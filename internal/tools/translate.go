@@ -0,0 +1,544 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/llm"
+	"Weave-Toolkit/internal/schema"
+)
+
+// translate 默认限制
+const (
+	defaultTranslateMaxTextBytes = 128 << 10
+	defaultTranslateChunkBytes   = 4000
+	translateErrorBodyBytes      = 512
+	translateRequestTimeout      = 60 * time.Second
+)
+
+// 翻译服务类型
+const (
+	TranslateProviderDeepL          = "deepl"
+	TranslateProviderGoogle         = "google"
+	TranslateProviderLibreTranslate = "libretranslate"
+	TranslateProviderLLM            = "llm"
+)
+
+// 官方服务地址
+const (
+	deeplProURL  = "https://api.deepl.com"
+	deeplFreeURL = "https://api-free.deepl.com"
+	googleURL    = "https://translation.googleapis.com"
+)
+
+// translator 翻译服务适配器；source 为空表示自动检测，返回的 detected 为检测到的源语言（未知时为空）
+type translator interface {
+	translate(ctx context.Context, text, source, target string) (translated, detected string, err error)
+	detect(ctx context.Context, text string) (language string, confidence *float64, err error)
+}
+
+// TranslateTool 翻译工具
+//
+// 通过配置的翻译服务（DeepL、Google Cloud Translation、LibreTranslate 或 OpenAI 兼容接口的本地大模型）
+// 翻译文本或检测语言。长文本按行切分为片段逐段翻译，流式调用时每完成一段即推送该段译文。
+// API 密钥在配置中定义，调用参数只通过名称选择服务。
+type TranslateTool struct {
+	config config.TranslateConfig
+}
+
+// TranslateArgs 翻译参数
+type TranslateArgs struct {
+	Op       string `json:"op"` // translate, detect
+	Text     string `json:"text"`
+	Target   string `json:"target"` // 目标语言，如 de、zh、en-US
+	Source   string `json:"source"` // 源语言，默认自动检测
+	Provider string `json:"provider"`
+}
+
+// TranslateResult 翻译结果
+type TranslateResult struct {
+	Op         string   `json:"op"`
+	Provider   string   `json:"provider"`
+	Text       string   `json:"text,omitempty"`       // 译文
+	Source     string   `json:"source,omitempty"`     // 源语言，自动检测时为检测结果
+	Target     string   `json:"target,omitempty"`     // 目标语言
+	Chunks     int      `json:"chunks,omitempty"`     // 翻译的片段数
+	Language   string   `json:"language,omitempty"`   // detect 检测到的语言
+	Confidence *float64 `json:"confidence,omitempty"` // detect 的置信度（0-1），服务不提供时为空
+}
+
+// NewTranslateTool 创建翻译工具
+func NewTranslateTool(cfg config.TranslateConfig) *TranslateTool {
+	if cfg.MaxTextBytes <= 0 {
+		cfg.MaxTextBytes = defaultTranslateMaxTextBytes
+	}
+	if cfg.ChunkBytes <= 0 {
+		cfg.ChunkBytes = defaultTranslateChunkBytes
+	}
+	return &TranslateTool{config: cfg}
+}
+
+func (tt *TranslateTool) Name() string {
+	return "translate"
+}
+
+func (tt *TranslateTool) Description() string {
+	return "Translate text or detect its language using the configured DeepL, Google, LibreTranslate or local LLM provider"
+}
+
+func (tt *TranslateTool) Category() ToolCategory {
+	return CategoryAI
+}
+
+func (tt *TranslateTool) Tags() []string {
+	return []string{TagNetwork, TagSlow}
+}
+
+func (tt *TranslateTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"op":       {Type: schema.TypeString, Description: "Operation", Enum: []interface{}{"translate", "detect"}, Default: "translate"},
+		"text":     {Type: schema.TypeString, Description: "Text to translate or detect", MinLength: schema.Int(1)},
+		"target":   {Type: schema.TypeString, Description: "Target language code such as de, zh or en-US; required for translate", MinLength: schema.Int(2)},
+		"source":   {Type: schema.TypeString, Description: "Source language code, detected automatically when omitted", MinLength: schema.Int(2)},
+		"provider": {Type: schema.TypeString, Description: "Configured provider name, defaults to the configured default"},
+	}, "text").Closed()
+}
+
+func (tt *TranslateTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	return tt.execute(ctx, args, nil)
+}
+
+// ExecuteStream 流式调用，translate 每完成一个片段推送该片段的译文
+func (tt *TranslateTool) ExecuteStream(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	return tt.execute(ctx, args, callback)
+}
+
+func (tt *TranslateTool) execute(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	var translateArgs TranslateArgs
+	if err := json.Unmarshal(args, &translateArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	op := translateArgs.Op
+	if op == "" {
+		op = "translate"
+	}
+	if op != "translate" && op != "detect" {
+		return nil, fmt.Errorf("unsupported operation: %s", translateArgs.Op)
+	}
+	if strings.TrimSpace(translateArgs.Text) == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+	if len(translateArgs.Text) > tt.config.MaxTextBytes {
+		return nil, fmt.Errorf("text exceeds %d bytes", tt.config.MaxTextBytes)
+	}
+	if op == "translate" && translateArgs.Target == "" {
+		return nil, fmt.Errorf("target is required for translate")
+	}
+
+	name, provider, err := tt.provider(ctx, translateArgs.Provider)
+	if err != nil {
+		return nil, err
+	}
+	result := TranslateResult{Op: op, Provider: name}
+
+	chunks := splitTranslateChunks(translateArgs.Text, tt.config.ChunkBytes)
+	if op == "detect" {
+		// 语言检测只需要开头的一段文本
+		var language string
+		var confidence *float64
+		err := callUpstream(ctx, "translate:"+name, func() (err error) {
+			language, confidence, err = provider.detect(ctx, strings.TrimSpace(chunks[0]))
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Language, result.Confidence = strings.ToLower(language), confidence
+		return json.Marshal(result)
+	}
+
+	result.Source, result.Target = translateArgs.Source, translateArgs.Target
+	var translated strings.Builder
+	for i, chunk := range chunks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		body := strings.TrimSpace(chunk)
+		output := chunk
+		if body != "" {
+			var text, detected string
+			err := callUpstream(ctx, "translate:"+name, func() (err error) {
+				text, detected, err = provider.translate(ctx, body, translateArgs.Source, translateArgs.Target)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+			if result.Source == "" && detected != "" {
+				result.Source = strings.ToLower(detected)
+			}
+			// 保留片段首尾的空白与换行，拼接后保持原文的段落结构
+			start := strings.Index(chunk, body)
+			output = chunk[:start] + text + chunk[start+len(body):]
+		}
+		translated.WriteString(output)
+		if callback != nil {
+			callback(output, i)
+		}
+	}
+	result.Text = translated.String()
+	result.Chunks = len(chunks)
+	return json.Marshal(result)
+}
+
+// provider 按名称选择翻译服务，未指定时使用默认服务
+func (tt *TranslateTool) provider(ctx context.Context, name string) (string, translator, error) {
+	if name == "" {
+		name = tt.config.Default
+	}
+	if name == "" {
+		if len(tt.config.Providers) != 1 {
+			names := make([]string, 0, len(tt.config.Providers))
+			for providerName := range tt.config.Providers {
+				names = append(names, providerName)
+			}
+			sort.Strings(names)
+			if len(names) == 0 {
+				return "", nil, fmt.Errorf("no translation provider configured")
+			}
+			return "", nil, fmt.Errorf("provider is required, available: %s", strings.Join(names, ", "))
+		}
+		for providerName := range tt.config.Providers {
+			name = providerName
+		}
+	}
+
+	cfg, ok := tt.config.Providers[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown translation provider: %s", name)
+	}
+	apiKey := cfg.ResolveAPIKey()
+	client := HTTPClient(ctx, translateRequestTimeout)
+	switch cfg.Type {
+	case TranslateProviderDeepL:
+		if apiKey == "" {
+			return "", nil, fmt.Errorf("provider %s: api key is required", name)
+		}
+		baseURL := cfg.URL
+		if baseURL == "" {
+			// DeepL 免费版密钥以 :fx 结尾，使用单独的接口地址
+			baseURL = deeplProURL
+			if strings.HasSuffix(apiKey, ":fx") {
+				baseURL = deeplFreeURL
+			}
+		}
+		return name, &deeplTranslator{client: client, baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey}, nil
+	case TranslateProviderGoogle:
+		if apiKey == "" {
+			return "", nil, fmt.Errorf("provider %s: api key is required", name)
+		}
+		baseURL := cfg.URL
+		if baseURL == "" {
+			baseURL = googleURL
+		}
+		return name, &googleTranslator{client: client, baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey}, nil
+	case TranslateProviderLibreTranslate:
+		if cfg.URL == "" {
+			return "", nil, fmt.Errorf("provider %s: url is required", name)
+		}
+		return name, &libreTranslator{client: client, baseURL: strings.TrimRight(cfg.URL, "/"), apiKey: apiKey}, nil
+	case TranslateProviderLLM:
+		if cfg.URL == "" || cfg.Model == "" {
+			return "", nil, fmt.Errorf("provider %s: url and model are required", name)
+		}
+		provider, err := llm.NewProvider(llm.Options{Type: llm.ProviderOpenAICompatible, URL: cfg.URL, APIKey: apiKey, Client: client})
+		if err != nil {
+			return "", nil, fmt.Errorf("provider %s: %v", name, err)
+		}
+		return name, &llmTranslator{provider: provider, model: cfg.Model}, nil
+	default:
+		return "", nil, fmt.Errorf("provider %s: unsupported type %q", name, cfg.Type)
+	}
+}
+
+// splitTranslateChunks 按行将文本切分为不超过 limit 字节的片段，片段依次拼接等于原文；
+// 超长的单行在空白处切分，没有空白时按字符边界切分
+func splitTranslateChunks(text string, limit int) []string {
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if current.Len()+len(line) <= limit {
+			current.WriteString(line)
+			continue
+		}
+		flush()
+		for len(line) > limit {
+			cut := strings.LastIndexFunc(line[:limit], unicode.IsSpace)
+			if cut <= 0 {
+				cut = limit
+				for cut > 0 && !utf8.RuneStart(line[cut]) {
+					cut--
+				}
+			} else {
+				cut++
+			}
+			chunks = append(chunks, line[:cut])
+			line = line[cut:]
+		}
+		current.WriteString(line)
+	}
+	flush()
+	if len(chunks) == 0 {
+		chunks = []string{text}
+	}
+	return chunks
+}
+
+// postTranslateJSON 发送 JSON 请求并解码 JSON 响应，非 2xx 状态码返回包含响应片段的错误
+func postTranslateJSON(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("translation request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, translateErrorBodyBytes))
+		return fmt.Errorf("translation provider returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid translation response: %v", err)
+	}
+	return nil
+}
+
+// deeplTranslator DeepL API v2
+type deeplTranslator struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+func (d *deeplTranslator) translate(ctx context.Context, text, source, target string) (string, string, error) {
+	request := map[string]interface{}{
+		"text":        []string{text},
+		"target_lang": strings.ToUpper(target),
+	}
+	if source != "" {
+		// 源语言不区分地区变体
+		if i := strings.IndexByte(source, '-'); i > 0 {
+			source = source[:i]
+		}
+		request["source_lang"] = strings.ToUpper(source)
+	}
+	var response struct {
+		Translations []struct {
+			DetectedSourceLanguage string `json:"detected_source_language"`
+			Text                   string `json:"text"`
+		} `json:"translations"`
+	}
+	headers := map[string]string{"Authorization": "DeepL-Auth-Key " + d.apiKey}
+	if err := postTranslateJSON(ctx, d.client, d.baseURL+"/v2/translate", headers, request, &response); err != nil {
+		return "", "", err
+	}
+	if len(response.Translations) == 0 {
+		return "", "", fmt.Errorf("invalid translation response: no translations")
+	}
+	return response.Translations[0].Text, response.Translations[0].DetectedSourceLanguage, nil
+}
+
+// detect DeepL 没有单独的检测接口，通过翻译为英文获取检测到的源语言，会计入翻译字符数
+func (d *deeplTranslator) detect(ctx context.Context, text string) (string, *float64, error) {
+	_, detected, err := d.translate(ctx, text, "", "EN-US")
+	if err != nil {
+		return "", nil, err
+	}
+	return detected, nil, nil
+}
+
+// googleTranslator Google Cloud Translation API v2
+type googleTranslator struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+func (g *googleTranslator) endpoint(path string) string {
+	return g.baseURL + path + "?key=" + url.QueryEscape(g.apiKey)
+}
+
+func (g *googleTranslator) translate(ctx context.Context, text, source, target string) (string, string, error) {
+	request := map[string]interface{}{
+		"q":      []string{text},
+		"target": target,
+		"format": "text",
+	}
+	if source != "" {
+		request["source"] = source
+	}
+	var response struct {
+		Data struct {
+			Translations []struct {
+				TranslatedText         string `json:"translatedText"`
+				DetectedSourceLanguage string `json:"detectedSourceLanguage"`
+			} `json:"translations"`
+		} `json:"data"`
+	}
+	if err := postTranslateJSON(ctx, g.client, g.endpoint("/language/translate/v2"), nil, request, &response); err != nil {
+		return "", "", err
+	}
+	if len(response.Data.Translations) == 0 {
+		return "", "", fmt.Errorf("invalid translation response: no translations")
+	}
+	return response.Data.Translations[0].TranslatedText, response.Data.Translations[0].DetectedSourceLanguage, nil
+}
+
+func (g *googleTranslator) detect(ctx context.Context, text string) (string, *float64, error) {
+	var response struct {
+		Data struct {
+			Detections [][]struct {
+				Language   string  `json:"language"`
+				Confidence float64 `json:"confidence"`
+			} `json:"detections"`
+		} `json:"data"`
+	}
+	request := map[string]interface{}{"q": []string{text}}
+	if err := postTranslateJSON(ctx, g.client, g.endpoint("/language/translate/v2/detect"), nil, request, &response); err != nil {
+		return "", nil, err
+	}
+	if len(response.Data.Detections) == 0 || len(response.Data.Detections[0]) == 0 {
+		return "", nil, fmt.Errorf("invalid detection response: no detections")
+	}
+	detection := response.Data.Detections[0][0]
+	return detection.Language, &detection.Confidence, nil
+}
+
+// libreTranslator LibreTranslate API，置信度由 0-100 换算为 0-1
+type libreTranslator struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+func (l *libreTranslator) translate(ctx context.Context, text, source, target string) (string, string, error) {
+	if source == "" {
+		source = "auto"
+	}
+	request := map[string]interface{}{
+		"q":      text,
+		"source": strings.ToLower(source),
+		"target": strings.ToLower(target),
+		"format": "text",
+	}
+	if l.apiKey != "" {
+		request["api_key"] = l.apiKey
+	}
+	var response struct {
+		TranslatedText   string `json:"translatedText"`
+		DetectedLanguage *struct {
+			Language string `json:"language"`
+		} `json:"detectedLanguage"`
+	}
+	if err := postTranslateJSON(ctx, l.client, l.baseURL+"/translate", nil, request, &response); err != nil {
+		return "", "", err
+	}
+	detected := ""
+	if response.DetectedLanguage != nil {
+		detected = response.DetectedLanguage.Language
+	}
+	return response.TranslatedText, detected, nil
+}
+
+func (l *libreTranslator) detect(ctx context.Context, text string) (string, *float64, error) {
+	request := map[string]interface{}{"q": text}
+	if l.apiKey != "" {
+		request["api_key"] = l.apiKey
+	}
+	var response []struct {
+		Language   string  `json:"language"`
+		Confidence float64 `json:"confidence"`
+	}
+	if err := postTranslateJSON(ctx, l.client, l.baseURL+"/detect", nil, request, &response); err != nil {
+		return "", nil, err
+	}
+	if len(response) == 0 {
+		return "", nil, fmt.Errorf("invalid detection response: no detections")
+	}
+	confidence := response[0].Confidence / 100
+	return response[0].Language, &confidence, nil
+}
+
+// llmTranslator OpenAI 兼容的 chat completions 接口，适用于 Ollama、vLLM 等本地部署的大模型
+type llmTranslator struct {
+	provider llm.Provider
+	model    string
+}
+
+func (m *llmTranslator) complete(ctx context.Context, system, text string) (string, error) {
+	temperature := 0.0
+	response, err := m.provider.Complete(ctx, llm.Request{
+		Model:       m.model,
+		System:      system,
+		Messages:    []llm.Message{{Role: "user", Content: text}},
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(response.Content), nil
+}
+
+func (m *llmTranslator) translate(ctx context.Context, text, source, target string) (string, string, error) {
+	from := "the source language"
+	if source != "" {
+		from = source
+	}
+	system := fmt.Sprintf("You are a translation engine. Translate the user's text from %s to the language with code %s. "+
+		"Reply with the translation only, without explanations, and keep the original formatting.", from, target)
+	translated, err := m.complete(ctx, system, text)
+	return translated, "", err
+}
+
+func (m *llmTranslator) detect(ctx context.Context, text string) (string, *float64, error) {
+	system := "Identify the language of the user's text. Reply with its ISO 639-1 code only, for example en."
+	language, err := m.complete(ctx, system, text)
+	if err != nil {
+		return "", nil, err
+	}
+	fields := strings.Fields(language)
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("invalid detection response: empty reply")
+	}
+	return strings.Trim(fields[0], ".\"'`"), nil, nil
+}
@@ -0,0 +1,675 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/internal/schema"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// validate 默认限制
+const (
+	defaultValidateMaxDocumentBytes = 1 << 20
+	defaultValidateMaxIssues        = 100
+	// validateMaxNodes 限制 YAML 别名展开后的节点总数，防止“十亿笑声”式的展开
+	validateMaxNodes = 1000000
+	// validateInlineSchemaURL 内联 Schema 的资源地址，其中的相对引用不会被加载
+	validateInlineSchemaURL = "mem:///schema.json"
+)
+
+// 文档格式
+const (
+	ValidateFormatJSON = "json"
+	ValidateFormatYAML = "yaml"
+)
+
+// 问题类型
+const (
+	ValidateIssueSyntax       = "syntax"
+	ValidateIssueDuplicateKey = "duplicate_key"
+	ValidateIssueKey          = "key"
+	ValidateIssueSchema       = "schema"
+)
+
+// ValidateTool 结构化数据校验工具
+//
+// 检查 JSON 或 YAML 文档的语法与重复键，并可按 JSON Schema（draft 4 至 2020-12）校验，
+// 返回带 JSON Pointer 路径与行列号的问题列表，供生成配置文件的智能体修正输出。
+// 内联 Schema 不能引用外部资源；配置中的 Schema 文件可以引用同目录下的其他文件。
+type ValidateTool struct {
+	config config.ValidateConfig
+}
+
+// ValidateArgs 校验参数
+type ValidateArgs struct {
+	Document     string          `json:"document"`
+	Format       string          `json:"format"`        // json、yaml，默认按内容判断
+	Schema       json.RawMessage `json:"schema"`        // 内联 Schema，可为对象或 JSON/YAML 文本
+	SchemaName   string          `json:"schema_name"`   // 配置中的 Schema 名称
+	AssertFormat bool            `json:"assert_format"` // 将 format 关键字作为断言而非注解
+}
+
+// ValidateResult 校验结果
+type ValidateResult struct {
+	Valid     bool            `json:"valid"`
+	Format    string          `json:"format"`
+	Documents int             `json:"documents"`
+	Issues    []ValidateIssue `json:"issues,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+// ValidateIssue 校验发现的问题
+type ValidateIssue struct {
+	Type     string `json:"type"`               // syntax、duplicate_key、key、schema
+	Document int    `json:"document,omitempty"` // YAML 多文档时的文档序号，从 1 开始
+	Path     string `json:"path"`               // 实例的 JSON Pointer，根为空字符串
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Keyword  string `json:"keyword,omitempty"` // 未通过的 Schema 关键字位置
+	Message  string `json:"message"`
+}
+
+// NewValidateTool 创建校验工具
+func NewValidateTool(cfg config.ValidateConfig) *ValidateTool {
+	if cfg.MaxDocumentBytes <= 0 {
+		cfg.MaxDocumentBytes = defaultValidateMaxDocumentBytes
+	}
+	if cfg.MaxIssues <= 0 {
+		cfg.MaxIssues = defaultValidateMaxIssues
+	}
+	return &ValidateTool{config: cfg}
+}
+
+func (vt *ValidateTool) Name() string {
+	return "validate"
+}
+
+func (vt *ValidateTool) Description() string {
+	return "Lint JSON or YAML documents and validate them against a JSON Schema, reporting violations with paths and line numbers"
+}
+
+func (vt *ValidateTool) Category() ToolCategory {
+	return CategoryUtility
+}
+
+func (vt *ValidateTool) InputSchema() *schema.Schema {
+	return schema.Object(map[string]*schema.Schema{
+		"document":      {Type: schema.TypeString, Description: "JSON or YAML text to check; YAML may contain several documents"},
+		"format":        {Type: schema.TypeString, Description: "Document format, detected from the content by default", Enum: []interface{}{ValidateFormatJSON, ValidateFormatYAML}},
+		"schema":        {Description: "Inline JSON Schema as an object, boolean, or JSON/YAML text"},
+		"schema_name":   {Type: schema.TypeString, Description: "Name of a schema defined in the server configuration", MinLength: schema.Int(1)},
+		"assert_format": {Type: schema.TypeBoolean, Description: "Treat the format keyword as an assertion", Default: false},
+	}, "document").Closed()
+}
+
+func (vt *ValidateTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var validateArgs ValidateArgs
+	if err := json.Unmarshal(args, &validateArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	if len(validateArgs.Document) > vt.config.MaxDocumentBytes {
+		return nil, fmt.Errorf("document exceeds %d bytes", vt.config.MaxDocumentBytes)
+	}
+	hasSchema := len(validateArgs.Schema) > 0 && string(validateArgs.Schema) != "null"
+	if hasSchema && validateArgs.SchemaName != "" {
+		return nil, fmt.Errorf("schema and schema_name are mutually exclusive")
+	}
+
+	var compiled *jsonschema.Schema
+	var err error
+	switch {
+	case validateArgs.SchemaName != "":
+		compiled, err = vt.namedSchema(validateArgs.SchemaName, validateArgs.AssertFormat)
+	case hasSchema:
+		compiled, err = inlineSchema(validateArgs.Schema, validateArgs.AssertFormat)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := documentFormat(validateArgs.Document, validateArgs.Format)
+	if err != nil {
+		return nil, err
+	}
+	docs, issues := parseDocuments(validateArgs.Document, format)
+
+	result := ValidateResult{Format: format, Documents: len(docs)}
+	add := func(issue ValidateIssue) bool {
+		if len(result.Issues) >= vt.config.MaxIssues {
+			result.Truncated = true
+			return false
+		}
+		result.Issues = append(result.Issues, issue)
+		return true
+	}
+	for _, issue := range issues {
+		add(issue)
+	}
+	for i, doc := range docs {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if len(docs) > 1 {
+			for j := range doc.issues {
+				doc.issues[j].Document = i + 1
+			}
+		}
+		for _, issue := range doc.issues {
+			add(issue)
+		}
+		if compiled == nil {
+			continue
+		}
+		for _, issue := range schemaIssues(compiled, doc) {
+			if len(docs) > 1 {
+				issue.Document = i + 1
+			}
+			if !add(issue) {
+				break
+			}
+		}
+	}
+	result.Valid = len(result.Issues) == 0
+	return json.Marshal(result)
+}
+
+// namedSchema 编译配置中的 Schema 文件，文件中的相对引用从同目录加载
+func (vt *ValidateTool) namedSchema(name string, assertFormat bool) (*jsonschema.Schema, error) {
+	path, ok := vt.config.Schemas[name]
+	if !ok {
+		available := make([]string, 0, len(vt.config.Schemas))
+		for schemaName := range vt.config.Schemas {
+			available = append(available, schemaName)
+		}
+		sort.Strings(available)
+		return nil, fmt.Errorf("unknown schema: %s, available: %s", name, strings.Join(available, ", "))
+	}
+	path, err := platform.NormalizePath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema %s: %v", name, err)
+	}
+	format := ValidateFormatJSON
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		format = ValidateFormatYAML
+	}
+	doc, err := parseSchemaDocument(string(data), format)
+	if err != nil {
+		return nil, fmt.Errorf("schema %s: %v", name, err)
+	}
+
+	location := filepath.ToSlash(path)
+	if !strings.HasPrefix(location, "/") {
+		location = "/" + location
+	}
+	schemaURL := (&url.URL{Scheme: "file", Path: location}).String()
+
+	compiler := jsonschema.NewCompiler()
+	if assertFormat {
+		compiler.AssertFormat()
+	}
+	if err := compiler.AddResource(schemaURL, doc); err != nil {
+		return nil, fmt.Errorf("schema %s: %v", name, err)
+	}
+	compiled, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %v", name, err)
+	}
+	return compiled, nil
+}
+
+// noRefLoader 拒绝加载外部引用
+type noRefLoader struct{}
+
+func (noRefLoader) Load(url string) (any, error) {
+	return nil, errors.New("external references are not allowed in inline schemas")
+}
+
+// inlineSchema 编译参数中的 Schema，字符串按 JSON 或 YAML 文本解析
+func inlineSchema(raw json.RawMessage, assertFormat bool) (*jsonschema.Schema, error) {
+	var doc any
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		format, err := documentFormat(text, "")
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = parseSchemaDocument(text, format); err != nil {
+			return nil, fmt.Errorf("schema: %v", err)
+		}
+	} else if doc, err = jsonschema.UnmarshalJSON(bytes.NewReader(raw)); err != nil {
+		return nil, fmt.Errorf("schema: %v", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.UseLoader(noRefLoader{})
+	if assertFormat {
+		compiler.AssertFormat()
+	}
+	if err := compiler.AddResource(validateInlineSchemaURL, doc); err != nil {
+		return nil, fmt.Errorf("schema: %v", err)
+	}
+	compiled, err := compiler.Compile(validateInlineSchemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+	return compiled, nil
+}
+
+// parseSchemaDocument 解析单个 Schema 文档，存在语法错误或重复键时返回错误
+func parseSchemaDocument(text, format string) (any, error) {
+	docs, issues := parseDocuments(text, format)
+	for _, doc := range docs {
+		issues = append(issues, doc.issues...)
+	}
+	if len(issues) > 0 {
+		issue := issues[0]
+		if issue.Line > 0 {
+			return nil, fmt.Errorf("line %d: %s", issue.Line, issue.Message)
+		}
+		return nil, errors.New(issue.Message)
+	}
+	if len(docs) != 1 {
+		return nil, fmt.Errorf("expected one document, got %d", len(docs))
+	}
+	return docs[0].value, nil
+}
+
+// schemaIssues 按 Schema 校验文档，返回叶子错误
+func schemaIssues(compiled *jsonschema.Schema, doc parsedDocument) []ValidateIssue {
+	err := compiled.Validate(doc.value)
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		if err != nil {
+			return []ValidateIssue{{Type: ValidateIssueSchema, Message: err.Error()}}
+		}
+		return nil
+	}
+	var issues []ValidateIssue
+	var collect func(unit jsonschema.OutputUnit)
+	collect = func(unit jsonschema.OutputUnit) {
+		if len(unit.Errors) > 0 {
+			for _, child := range unit.Errors {
+				collect(child)
+			}
+			return
+		}
+		if unit.Error == nil {
+			return
+		}
+		pos := doc.positions[unit.InstanceLocation]
+		issues = append(issues, ValidateIssue{
+			Type:    ValidateIssueSchema,
+			Path:    unit.InstanceLocation,
+			Line:    pos.line,
+			Column:  pos.column,
+			Keyword: unit.KeywordLocation,
+			Message: unit.Error.String(),
+		})
+	}
+	collect(*verr.DetailedOutput())
+	return issues
+}
+
+// documentFormat 确定文档格式，未指定时以 { 或 [ 开头的内容按 JSON 处理
+func documentFormat(text, format string) (string, error) {
+	switch format {
+	case ValidateFormatJSON, ValidateFormatYAML:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
+	trimmed := strings.TrimLeft(text, " \t\r\n\ufeff")
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return ValidateFormatJSON, nil
+	}
+	return ValidateFormatYAML, nil
+}
+
+// textPosition 行列号，均从 1 开始
+type textPosition struct {
+	line   int
+	column int
+}
+
+// parsedDocument 解析后的文档，positions 以 JSON Pointer 记录各值的位置
+type parsedDocument struct {
+	value     any
+	positions map[string]textPosition
+	issues    []ValidateIssue
+}
+
+// parseDocuments 解析文档，语法错误作为问题返回
+func parseDocuments(text, format string) ([]parsedDocument, []ValidateIssue) {
+	if format == ValidateFormatJSON {
+		doc, issue := parseJSONDocument(text)
+		if issue != nil {
+			return nil, []ValidateIssue{*issue}
+		}
+		return []parsedDocument{doc}, nil
+	}
+	return parseYAMLDocuments(text)
+}
+
+// jsonWalker 逐个读取 JSON 记号以记录位置并检测重复键
+type jsonWalker struct {
+	text string
+	dec  *json.Decoder
+	doc  *parsedDocument
+}
+
+func parseJSONDocument(text string) (parsedDocument, *ValidateIssue) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	doc := parsedDocument{positions: make(map[string]textPosition)}
+	w := &jsonWalker{text: text, dec: dec, doc: &doc}
+
+	value, err := w.value("")
+	if err == nil {
+		end := w.skipSpace(dec.InputOffset())
+		if _, extra := dec.Token(); extra == nil {
+			return doc, w.syntaxIssue(end, "unexpected data after top-level value")
+		} else if extra != io.EOF {
+			err = extra
+		}
+	}
+	if err != nil {
+		offset := int64(len(text))
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			offset = syntaxErr.Offset
+		}
+		message := err.Error()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			message = "unexpected end of JSON input"
+		}
+		return doc, w.syntaxIssue(offset, message)
+	}
+	doc.value = value
+	return doc, nil
+}
+
+func (w *jsonWalker) syntaxIssue(offset int64, message string) *ValidateIssue {
+	pos := offsetPosition(w.text, offset)
+	return &ValidateIssue{Type: ValidateIssueSyntax, Line: pos.line, Column: pos.column, Message: message}
+}
+
+// skipSpace 跳过空白与分隔符，返回下一个记号的起始偏移
+func (w *jsonWalker) skipSpace(offset int64) int64 {
+	for int(offset) < len(w.text) && strings.IndexByte(" \t\r\n:,", w.text[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+func (w *jsonWalker) mark(ptr string, offset int64) textPosition {
+	pos := offsetPosition(w.text, w.skipSpace(offset))
+	if _, ok := w.doc.positions[ptr]; !ok {
+		w.doc.positions[ptr] = pos
+	}
+	return pos
+}
+
+func (w *jsonWalker) value(ptr string) (any, error) {
+	w.mark(ptr, w.dec.InputOffset())
+	tok, err := w.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	if delim == '{' {
+		obj := make(map[string]any)
+		for w.dec.More() {
+			keyOffset := w.dec.InputOffset()
+			keyTok, err := w.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyTok.(string)
+			child := ptr + "/" + escapePointer(key)
+			if _, dup := obj[key]; dup {
+				pos := w.mark(child, keyOffset)
+				w.doc.issues = append(w.doc.issues, ValidateIssue{
+					Type:    ValidateIssueDuplicateKey,
+					Path:    child,
+					Line:    pos.line,
+					Column:  pos.column,
+					Message: fmt.Sprintf("duplicate key %q", key),
+				})
+			}
+			value, err := w.value(child)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = value
+		}
+		_, err := w.dec.Token()
+		return obj, err
+	}
+
+	arr := make([]any, 0)
+	for i := 0; w.dec.More(); i++ {
+		value, err := w.value(ptr + "/" + strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, value)
+	}
+	_, err = w.dec.Token()
+	return arr, err
+}
+
+// offsetPosition 字节偏移对应的行列号，列按字符计
+func offsetPosition(text string, offset int64) textPosition {
+	if int(offset) > len(text) {
+		offset = int64(len(text))
+	}
+	before := text[:offset]
+	line := strings.Count(before, "\n") + 1
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return textPosition{line: line, column: utf8.RuneCountInString(before[lineStart:]) + 1}
+}
+
+// yamlLinePattern 提取 yaml.v3 错误信息中的行号
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// yamlConverter 将 YAML 节点转换为 JSON 值
+type yamlConverter struct {
+	doc   *parsedDocument
+	nodes int
+}
+
+func parseYAMLDocuments(text string) ([]parsedDocument, []ValidateIssue) {
+	dec := yaml.NewDecoder(strings.NewReader(text))
+	var docs []parsedDocument
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			issue := ValidateIssue{Type: ValidateIssueSyntax, Document: len(docs) + 1, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+			if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+				issue.Line, _ = strconv.Atoi(match[1])
+			}
+			return docs, []ValidateIssue{issue}
+		}
+
+		doc := parsedDocument{positions: make(map[string]textPosition)}
+		conv := &yamlConverter{doc: &doc}
+		value, err := conv.value(&node, "")
+		if err != nil {
+			return docs, []ValidateIssue{{Type: ValidateIssueSyntax, Document: len(docs) + 1, Message: err.Error()}}
+		}
+		doc.value = value
+		docs = append(docs, doc)
+	}
+	if len(docs) == 0 {
+		// 空文本视为一个 null 文档
+		docs = append(docs, parsedDocument{positions: map[string]textPosition{}})
+	}
+	return docs, nil
+}
+
+func (c *yamlConverter) value(node *yaml.Node, ptr string) (any, error) {
+	c.nodes++
+	if c.nodes > validateMaxNodes {
+		return nil, fmt.Errorf("document expands to more than %d nodes", validateMaxNodes)
+	}
+	if node.Line > 0 {
+		if _, ok := c.doc.positions[ptr]; !ok {
+			c.doc.positions[ptr] = textPosition{line: node.Line, column: node.Column}
+		}
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return c.value(node.Content[0], ptr)
+	case yaml.AliasNode:
+		return c.value(node.Alias, ptr)
+	case yaml.SequenceNode:
+		arr := make([]any, 0, len(node.Content))
+		for i, item := range node.Content {
+			value, err := c.value(item, ptr+"/"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		return arr, nil
+	case yaml.MappingNode:
+		return c.mapping(node, ptr)
+	case yaml.ScalarNode:
+		return scalarValue(node), nil
+	}
+	return nil, nil
+}
+
+// mapping 转换映射节点，<< 合并的键不覆盖显式定义的键
+func (c *yamlConverter) mapping(node *yaml.Node, ptr string) (any, error) {
+	obj := make(map[string]any)
+	var merges []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, val := node.Content[i], node.Content[i+1]
+		if key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge" {
+			merges = append(merges, val)
+			continue
+		}
+		if key.Kind != yaml.ScalarNode {
+			c.doc.issues = append(c.doc.issues, ValidateIssue{
+				Type:    ValidateIssueKey,
+				Path:    ptr,
+				Line:    key.Line,
+				Column:  key.Column,
+				Message: "complex mapping keys cannot be represented in JSON",
+			})
+			continue
+		}
+		child := ptr + "/" + escapePointer(key.Value)
+		if _, dup := obj[key.Value]; dup {
+			c.doc.issues = append(c.doc.issues, ValidateIssue{
+				Type:    ValidateIssueDuplicateKey,
+				Path:    child,
+				Line:    key.Line,
+				Column:  key.Column,
+				Message: fmt.Sprintf("duplicate key %q", key.Value),
+			})
+		}
+		value, err := c.value(val, child)
+		if err != nil {
+			return nil, err
+		}
+		obj[key.Value] = value
+	}
+
+	for _, merge := range merges {
+		sources := []*yaml.Node{merge}
+		if merge.Kind == yaml.SequenceNode {
+			sources = merge.Content
+		}
+		for _, source := range sources {
+			value, err := c.value(source, ptr)
+			if err != nil {
+				return nil, err
+			}
+			merged, ok := value.(map[string]any)
+			if !ok {
+				c.doc.issues = append(c.doc.issues, ValidateIssue{
+					Type:    ValidateIssueKey,
+					Path:    ptr,
+					Line:    source.Line,
+					Column:  source.Column,
+					Message: "merge key requires a mapping or a sequence of mappings",
+				})
+				continue
+			}
+			for k, v := range merged {
+				if _, ok := obj[k]; !ok {
+					obj[k] = v
+				}
+			}
+		}
+	}
+	return obj, nil
+}
+
+// scalarValue 按标签转换标量，无法解析的数值与其他标签（如时间戳）保留原文
+func scalarValue(node *yaml.Node) any {
+	switch node.ShortTag() {
+	case "!!null":
+		return nil
+	case "!!bool":
+		var b bool
+		if node.Decode(&b) == nil {
+			return b
+		}
+	case "!!int":
+		var i int64
+		if node.Decode(&i) == nil {
+			return i
+		}
+		var u uint64
+		if node.Decode(&u) == nil {
+			return u
+		}
+		var f float64
+		if node.Decode(&f) == nil {
+			return f
+		}
+	case "!!float":
+		var f float64
+		if node.Decode(&f) == nil {
+			return f
+		}
+	}
+	return node.Value
+}
@@ -0,0 +1,266 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/internal/schema"
+	"Weave-Toolkit/internal/vector"
+)
+
+// vector_search 工具默认限制
+const (
+	defaultVectorMaxCollections = 64
+	defaultVectorMaxDocuments   = 10000
+	defaultVectorMaxTopK        = 100
+	defaultVectorTopK           = 5
+	maxVectorIDLength           = 256
+)
+
+// VectorSearchTool 向量检索工具
+//
+// 在进程内索引中按集合保存文档，upsert 时未提供向量的文档通过 embeddings 工具自动向量化，
+// query 可传入查询文本或向量，按余弦相似度返回最相近的文档。配置 persist_path 后
+// 索引在修改后写入快照文件并在重启时加载。同一集合应始终使用同一个向量模型。
+type VectorSearchTool struct {
+	config     config.VectorSearchConfig
+	embeddings *EmbeddingsTool
+
+	mu    sync.Mutex
+	index *vector.Index
+}
+
+// VectorDocument 待写入的文档，vector 为空时使用 text 计算向量
+type VectorDocument struct {
+	ID       string            `json:"id"`
+	Text     string            `json:"text"`
+	Metadata map[string]string `json:"metadata"`
+	Vector   []float32         `json:"vector"`
+}
+
+// VectorSearchArgs 向量检索参数
+type VectorSearchArgs struct {
+	Op         string            `json:"op"` // upsert, query, delete, list, drop
+	Collection string            `json:"collection"`
+	Documents  []VectorDocument  `json:"documents"`
+	Text       string            `json:"text"`
+	Vector     []float32         `json:"vector"`
+	TopK       int               `json:"top_k"`
+	MinScore   float64           `json:"min_score"`
+	Filter     map[string]string `json:"filter"`
+	IDs        []string          `json:"ids"`
+	Provider   string            `json:"provider"` // 向量化服务，仅在需要计算向量时使用
+	Model      string            `json:"model"`
+}
+
+// VectorSearchResult 向量检索结果
+type VectorSearchResult struct {
+	Op          string                  `json:"op"`
+	Collection  string                  `json:"collection,omitempty"`
+	Upserted    int                     `json:"upserted,omitempty"`
+	Deleted     int                     `json:"deleted,omitempty"`
+	Matches     []vector.Match          `json:"matches,omitempty"`
+	Collections []vector.CollectionInfo `json:"collections,omitempty"`
+}
+
+// NewVectorSearchTool 创建向量检索工具，索引在首次使用时创建或加载
+func NewVectorSearchTool(cfg config.VectorSearchConfig, embeddings *EmbeddingsTool) *VectorSearchTool {
+	if cfg.MaxCollections <= 0 {
+		cfg.MaxCollections = defaultVectorMaxCollections
+	}
+	if cfg.MaxDocuments <= 0 {
+		cfg.MaxDocuments = defaultVectorMaxDocuments
+	}
+	if cfg.MaxTopK <= 0 {
+		cfg.MaxTopK = defaultVectorMaxTopK
+	}
+	return &VectorSearchTool{config: cfg, embeddings: embeddings}
+}
+
+func (vt *VectorSearchTool) Name() string {
+	return "vector_search"
+}
+
+func (vt *VectorSearchTool) Description() string {
+	return "Store documents in an in-process vector index and run semantic similarity search over them by text or vector"
+}
+
+func (vt *VectorSearchTool) Category() ToolCategory {
+	return CategoryAI
+}
+
+func (vt *VectorSearchTool) Tags() []string {
+	return []string{TagNetwork, TagLLM}
+}
+
+func (vt *VectorSearchTool) InputSchema() *schema.Schema {
+	number := &schema.Schema{Type: schema.TypeNumber}
+	metadata := &schema.Schema{Type: schema.TypeObject, Description: "String metadata returned with matches and usable in filter"}
+	document := schema.Object(map[string]*schema.Schema{
+		"id":       {Type: schema.TypeString, MinLength: schema.Int(1), MaxLength: schema.Int(maxVectorIDLength)},
+		"text":     {Type: schema.TypeString, Description: "Document text, embedded when vector is omitted"},
+		"metadata": metadata,
+		"vector":   {Type: schema.TypeArray, Description: "Precomputed embedding", Items: number, MinItems: schema.Int(1)},
+	}, "id").Closed()
+
+	return schema.Object(map[string]*schema.Schema{
+		"op":         {Type: schema.TypeString, Description: "Operation", Enum: []interface{}{"upsert", "query", "delete", "list", "drop"}},
+		"collection": {Type: schema.TypeString, Description: "Collection name, required except for list", MinLength: schema.Int(1)},
+		"documents":  {Type: schema.TypeArray, Description: "Documents to upsert", Items: document, MinItems: schema.Int(1)},
+		"text":       {Type: schema.TypeString, Description: "Query text for query", MinLength: schema.Int(1)},
+		"vector":     {Type: schema.TypeArray, Description: "Query embedding for query, used instead of text", Items: number, MinItems: schema.Int(1)},
+		"top_k":      {Type: schema.TypeInteger, Description: "Number of matches to return", Minimum: schema.Float(1), Maximum: schema.Float(float64(vt.config.MaxTopK))},
+		"min_score":  {Type: schema.TypeNumber, Description: "Minimum cosine similarity", Minimum: schema.Float(-1), Maximum: schema.Float(1)},
+		"filter":     {Type: schema.TypeObject, Description: "Metadata values that matches must equal"},
+		"ids":        {Type: schema.TypeArray, Description: "Document IDs to delete", Items: &schema.Schema{Type: schema.TypeString}, MinItems: schema.Int(1)},
+		"provider":   {Type: schema.TypeString, Description: "Embeddings provider used for text"},
+		"model":      {Type: schema.TypeString, Description: "Embedding model used for text"},
+	}, "op").Closed()
+}
+
+func (vt *VectorSearchTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var searchArgs VectorSearchArgs
+	if err := json.Unmarshal(args, &searchArgs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	if searchArgs.Op != "list" && searchArgs.Collection == "" {
+		return nil, fmt.Errorf("collection is required for %s", searchArgs.Op)
+	}
+
+	index, err := vt.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	result := VectorSearchResult{Op: searchArgs.Op, Collection: searchArgs.Collection}
+	switch searchArgs.Op {
+	case "upsert":
+		result.Upserted, err = vt.upsert(ctx, index, searchArgs)
+	case "query":
+		result.Matches, err = vt.query(ctx, index, searchArgs)
+	case "delete":
+		if len(searchArgs.IDs) == 0 {
+			return nil, fmt.Errorf("ids is required for delete")
+		}
+		result.Deleted, err = index.Delete(searchArgs.Collection, searchArgs.IDs)
+	case "list":
+		result.Collections = index.Collections()
+	case "drop":
+		err = index.Drop(searchArgs.Collection)
+	default:
+		return nil, fmt.Errorf("unsupported op: %s", searchArgs.Op)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+func (vt *VectorSearchTool) upsert(ctx context.Context, index *vector.Index, args VectorSearchArgs) (int, error) {
+	if len(args.Documents) == 0 {
+		return 0, fmt.Errorf("documents is required for upsert")
+	}
+
+	docs := make([]vector.Document, len(args.Documents))
+	var texts []string
+	var pending []int
+	for i, doc := range args.Documents {
+		if doc.ID == "" {
+			return 0, fmt.Errorf("document %d: id is required", i)
+		}
+		docs[i] = vector.Document{ID: doc.ID, Text: doc.Text, Metadata: doc.Metadata, Vector: doc.Vector}
+		if len(doc.Vector) == 0 {
+			if doc.Text == "" {
+				return 0, fmt.Errorf("document %s: text or vector is required", doc.ID)
+			}
+			texts = append(texts, doc.Text)
+			pending = append(pending, i)
+		}
+	}
+
+	if len(texts) > 0 {
+		vectors, err := vt.embed(ctx, args, texts)
+		if err != nil {
+			return 0, err
+		}
+		for j, i := range pending {
+			docs[i].Vector = vectors[j]
+		}
+	}
+
+	if err := index.Upsert(args.Collection, docs); err != nil {
+		return 0, err
+	}
+	return len(docs), nil
+}
+
+func (vt *VectorSearchTool) query(ctx context.Context, index *vector.Index, args VectorSearchArgs) ([]vector.Match, error) {
+	if args.TopK > vt.config.MaxTopK {
+		return nil, fmt.Errorf("top_k exceeds limit %d", vt.config.MaxTopK)
+	}
+	if args.TopK <= 0 {
+		args.TopK = defaultVectorTopK
+	}
+
+	query := args.Vector
+	if len(query) == 0 {
+		if args.Text == "" {
+			return nil, fmt.Errorf("text or vector is required for query")
+		}
+		vectors, err := vt.embed(ctx, args, []string{args.Text})
+		if err != nil {
+			return nil, err
+		}
+		query = vectors[0]
+	}
+
+	return index.Search(args.Collection, vector.Query{Vector: query, TopK: args.TopK, MinScore: args.MinScore, Filter: args.Filter})
+}
+
+// embed 通过 embeddings 工具计算向量并转换为索引使用的 float32
+func (vt *VectorSearchTool) embed(ctx context.Context, args VectorSearchArgs, texts []string) ([][]float32, error) {
+	if vt.embeddings == nil {
+		return nil, fmt.Errorf("embeddings are not available, provide vectors explicitly")
+	}
+	result, err := vt.embeddings.Embed(ctx, args.Provider, args.Model, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(result.Embeddings))
+	for i, embedding := range result.Embeddings {
+		vectors[i] = make([]float32, len(embedding))
+		for j, x := range embedding {
+			vectors[i][j] = float32(x)
+		}
+	}
+	return vectors, nil
+}
+
+// loadIndex 获取索引，首次调用时加载快照，失败时下次调用重试
+func (vt *VectorSearchTool) loadIndex() (*vector.Index, error) {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	if vt.index != nil {
+		return vt.index, nil
+	}
+
+	path := vt.config.PersistPath
+	if path != "" {
+		normalized, err := platform.NormalizePath(path)
+		if err != nil {
+			return nil, err
+		}
+		path = normalized
+	}
+	index, err := vector.NewIndex(path, vector.Limits{MaxCollections: vt.config.MaxCollections, MaxDocuments: vt.config.MaxDocuments})
+	if err != nil {
+		return nil, err
+	}
+	vt.index = index
+	return index, nil
+}
@@ -0,0 +1,241 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"Weave-Toolkit/internal/platform"
+)
+
+// 工作区默认配置
+const (
+	defaultWorkspaceMaxBytes = 100 << 20
+	workspaceDirName         = "weave-workspaces"
+	workspacePattern         = "call-*"
+)
+
+// ErrWorkspaceFull 写入会超过工作区大小上限
+var ErrWorkspaceFull = errors.New("workspace size limit exceeded")
+
+// workspaceContextKey 工作区上下文键
+type workspaceContextKey struct{}
+
+// WithWorkspace 在上下文中记录工具调用的工作区
+func WithWorkspace(ctx context.Context, ws *Workspace) context.Context {
+	return context.WithValue(ctx, workspaceContextKey{}, ws)
+}
+
+// WorkspaceFromContext 获取当前工具调用的工作区
+func WorkspaceFromContext(ctx context.Context) (*Workspace, bool) {
+	ws, ok := ctx.Value(workspaceContextKey{}).(*Workspace)
+	return ws, ok && ws != nil
+}
+
+// WorkspaceManager 工作区根目录管理
+type WorkspaceManager struct {
+	root     string
+	maxBytes int64
+}
+
+// NewWorkspaceManager 创建工作区管理器，root 为空时使用系统临时目录下的 weave-workspaces
+func NewWorkspaceManager(root string, maxBytes int64) (*WorkspaceManager, error) {
+	if root == "" {
+		root = filepath.Join(os.TempDir(), workspaceDirName)
+	}
+	root, err := platform.NormalizePath(root)
+	if err != nil {
+		return nil, err
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultWorkspaceMaxBytes
+	}
+	return &WorkspaceManager{root: root, maxBytes: maxBytes}, nil
+}
+
+// Root 工作区根目录
+func (m *WorkspaceManager) Root() string {
+	return m.root
+}
+
+// New 创建工作区，目录在首次使用时才创建
+func (m *WorkspaceManager) New() *Workspace {
+	return &Workspace{root: m.root, maxBytes: m.maxBytes}
+}
+
+// Sweep 删除修改时间早于 olderThan 的遗留工作区（如进程异常退出时未清理的目录）
+func (m *WorkspaceManager) Sweep(olderThan time.Duration) (int, error) {
+	entries, err := os.ReadDir(m.root)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	removed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), strings.TrimSuffix(workspacePattern, "*")) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(m.root, entry.Name())); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// Workspace 单次工具调用的临时目录
+//
+// 同一调用内的各步骤可通过它共享中间文件，调用结束后整体删除（异步任务在保留时长后删除）。
+// 通过 WriteFile 与 Create 写入的数据计入大小上限，超出时返回 ErrWorkspaceFull；
+// 工具直接写入 Dir 下的文件不受限制，但会在调用结束时计入 Usage。
+type Workspace struct {
+	root     string
+	maxBytes int64
+	used     int64
+
+	once sync.Once
+	dir  string
+	err  error
+
+	mu      sync.Mutex
+	removed bool
+}
+
+// Dir 工作区目录，首次调用时创建
+func (w *Workspace) Dir() (string, error) {
+	w.once.Do(func() {
+		if err := os.MkdirAll(w.root, 0700); err != nil {
+			w.err = fmt.Errorf("failed to create workspace root: %v", err)
+			return
+		}
+		w.dir, w.err = os.MkdirTemp(w.root, workspacePattern)
+	})
+	return w.dir, w.err
+}
+
+// Path 工作区内文件的绝对路径，name 不能跳出工作区，所需的父目录会自动创建
+func (w *Workspace) Path(name string) (string, error) {
+	dir, err := w.Dir()
+	if err != nil {
+		return "", err
+	}
+	path, err := platform.Confine(dir, name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// MaxBytes 工作区大小上限
+func (w *Workspace) MaxBytes() int64 {
+	return w.maxBytes
+}
+
+// WriteFile 写入文件，超过大小上限时返回 ErrWorkspaceFull
+func (w *Workspace) WriteFile(name string, data []byte) error {
+	if err := w.reserve(int64(len(data))); err != nil {
+		return err
+	}
+	path, err := w.Path(name)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Create 创建文件，写入超过大小上限时返回 ErrWorkspaceFull
+func (w *Workspace) Create(name string) (io.WriteCloser, error) {
+	path, err := w.Path(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &cappedFile{file: file, ws: w}, nil
+}
+
+// Usage 工作区内文件的实际总大小
+func (w *Workspace) Usage() int64 {
+	if w.dir == "" {
+		return 0
+	}
+	var total int64
+	filepath.WalkDir(w.dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// Remove 删除工作区目录，可重复调用
+func (w *Workspace) Remove() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.removed || w.dir == "" {
+		w.removed = true
+		return nil
+	}
+	w.removed = true
+	return os.RemoveAll(w.dir)
+}
+
+// RemoveAfter 在指定时长后删除工作区，用于结果可能引用工作区文件的异步任务
+func (w *Workspace) RemoveAfter(ttl time.Duration) {
+	if ttl <= 0 {
+		w.Remove()
+		return
+	}
+	time.AfterFunc(ttl, func() { w.Remove() })
+}
+
+// reserve 预占写入字节数
+func (w *Workspace) reserve(n int64) error {
+	if atomic.AddInt64(&w.used, n) > w.maxBytes {
+		atomic.AddInt64(&w.used, -n)
+		return fmt.Errorf("%w (%d bytes)", ErrWorkspaceFull, w.maxBytes)
+	}
+	return nil
+}
+
+// cappedFile 写入计入工作区大小上限的文件
+//
+// 不嵌入 *os.File，避免 io.Copy 通过 ReadFrom 绕过计数。
+type cappedFile struct {
+	file *os.File
+	ws   *Workspace
+}
+
+func (f *cappedFile) Write(p []byte) (int, error) {
+	if err := f.ws.reserve(int64(len(p))); err != nil {
+		return 0, err
+	}
+	return f.file.Write(p)
+}
+
+func (f *cappedFile) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,204 @@
+// Package uritemplate 资源 URI 模板
+//
+// 支持 RFC 6570 中资源模板常用的表达式：简单变量 {var}（不含 / ? # 的一段，按百分号编码）、
+// 保留字符变量 {+var}（可跨越 /，如文件路径）与结尾的查询变量 {?a,b}（可省略、顺序任意）。
+// 模板既可按变量展开为 URI，也可反向匹配 URI 并解析出变量值。
+package uritemplate
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// 表达式类型
+const (
+	kindSimple   = ""  // {var}
+	kindReserved = "+" // {+var}
+	kindQuery    = "?" // {?a,b}
+)
+
+// part 模板片段，字面量片段的 names 为空
+type part struct {
+	literal string
+	kind    string
+	names   []string
+}
+
+// Template 解析后的 URI 模板
+type Template struct {
+	raw     string
+	parts   []part
+	pattern *regexp.Regexp
+	query   []string // {?...} 中的变量
+}
+
+// Parse 解析 URI 模板
+func Parse(raw string) (*Template, error) {
+	t := &Template{raw: raw}
+	seen := make(map[string]bool)
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	rest := raw
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			t.addLiteral(rest, &expr)
+			break
+		}
+		if open > 0 {
+			t.addLiteral(rest[:open], &expr)
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated expression in template %s", raw)
+		}
+		body := rest[open+1 : open+end]
+		rest = rest[open+end+1:]
+		if t.query != nil {
+			return nil, fmt.Errorf("query expression must be last in template %s", raw)
+		}
+
+		kind := kindSimple
+		if body != "" && (body[0] == '+' || body[0] == '?') {
+			kind, body = body[:1], body[1:]
+		}
+		names := strings.Split(body, ",")
+		for _, name := range names {
+			if !validName(name) {
+				return nil, fmt.Errorf("invalid variable %q in template %s", name, raw)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("duplicate variable %s in template %s", name, raw)
+			}
+			seen[name] = true
+		}
+
+		switch kind {
+		case kindQuery:
+			t.query = names
+			expr.WriteString(`(?:\?([^#]*))?`)
+		default:
+			if len(names) != 1 {
+				return nil, fmt.Errorf("expression {%s} must name a single variable in template %s", body, raw)
+			}
+			if kind == kindReserved {
+				expr.WriteString(`([^?#]+)`)
+			} else {
+				expr.WriteString(`([^/?#]+)`)
+			}
+		}
+		t.parts = append(t.parts, part{kind: kind, names: names})
+	}
+	expr.WriteString("$")
+
+	pattern, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %v", raw, err)
+	}
+	t.pattern = pattern
+	return t, nil
+}
+
+// addLiteral 添加字面量片段
+func (t *Template) addLiteral(literal string, expr *strings.Builder) {
+	t.parts = append(t.parts, part{literal: literal})
+	expr.WriteString(regexp.QuoteMeta(literal))
+}
+
+// validName 变量名只能由字母、数字、下划线与点组成
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// String 返回原始模板
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Vars 按出现顺序返回模板中的变量
+func (t *Template) Vars() []string {
+	var names []string
+	for _, p := range t.parts {
+		names = append(names, p.names...)
+	}
+	return names
+}
+
+// Match 按模板匹配 URI，返回解码后的变量值；查询变量未出现时不包含在结果中
+func (t *Template) Match(uri string) (map[string]string, bool) {
+	groups := t.pattern.FindStringSubmatch(uri)
+	if groups == nil {
+		return nil, false
+	}
+
+	vars := make(map[string]string)
+	group := 1
+	for _, p := range t.parts {
+		if p.names == nil {
+			continue
+		}
+		value := groups[group]
+		group++
+		if p.kind == kindQuery {
+			query, err := url.ParseQuery(value)
+			if err != nil {
+				return nil, false
+			}
+			for _, name := range p.names {
+				if query.Has(name) {
+					vars[name] = query.Get(name)
+				}
+			}
+			continue
+		}
+		decoded, err := url.PathUnescape(value)
+		if err != nil {
+			return nil, false
+		}
+		vars[p.names[0]] = decoded
+	}
+	return vars, true
+}
+
+// Expand 按变量展开模板；简单变量与查询变量按百分号编码，缺少的查询变量省略
+func (t *Template) Expand(vars map[string]string) (string, error) {
+	var b strings.Builder
+	for _, p := range t.parts {
+		switch {
+		case p.names == nil:
+			b.WriteString(p.literal)
+		case p.kind == kindQuery:
+			sep := "?"
+			for _, name := range p.names {
+				value, ok := vars[name]
+				if !ok {
+					continue
+				}
+				b.WriteString(sep + url.QueryEscape(name) + "=" + url.QueryEscape(value))
+				sep = "&"
+			}
+		default:
+			value, ok := vars[p.names[0]]
+			if !ok || value == "" {
+				return "", fmt.Errorf("missing variable %s for template %s", p.names[0], t.raw)
+			}
+			if p.kind == kindReserved {
+				b.WriteString((&url.URL{Path: value}).EscapedPath())
+			} else {
+				b.WriteString(url.PathEscape(value))
+			}
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,282 @@
+// Package vector 进程内向量索引
+//
+// 按集合保存文档及其向量，使用余弦相似度做暴力检索，适合中小规模的语义搜索。
+// 可选地以 JSON 快照持久化到文件，每次写操作后整体重写快照。
+package vector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	werrors "Weave-Toolkit/internal/errors"
+)
+
+var (
+	// ErrCollectionNotFound 集合不存在
+	ErrCollectionNotFound = werrors.New(werrors.KindNotFound, "collection not found")
+	// ErrDimensionMismatch 向量维度与集合不一致
+	ErrDimensionMismatch = werrors.New(werrors.KindInvalidParams, "vector dimension mismatch")
+	// ErrLimitExceeded 集合或文档数量超出上限
+	ErrLimitExceeded = errors.New("index limit exceeded")
+)
+
+// Document 索引中的文档
+type Document struct {
+	ID       string            `json:"id"`
+	Text     string            `json:"text,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Vector   []float32         `json:"vector"`
+}
+
+// Match 检索结果
+type Match struct {
+	ID       string            `json:"id"`
+	Score    float64           `json:"score"`
+	Text     string            `json:"text,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Query 检索条件
+type Query struct {
+	Vector   []float32
+	TopK     int
+	MinScore float64
+	Filter   map[string]string // 元数据需全部相等
+}
+
+// CollectionInfo 集合概要
+type CollectionInfo struct {
+	Name      string `json:"name"`
+	Dimension int    `json:"dimension"`
+	Documents int    `json:"documents"`
+}
+
+// Limits 索引上限，0 表示不限制
+type Limits struct {
+	MaxCollections int
+	MaxDocuments   int // 每个集合的文档数上限
+}
+
+type collection struct {
+	Dimension int                  `json:"dimension"`
+	Documents map[string]*Document `json:"documents"`
+}
+
+// Index 线程安全的向量索引
+type Index struct {
+	mu          sync.RWMutex
+	collections map[string]*collection
+	limits      Limits
+	path        string
+}
+
+// NewIndex 创建索引，path 不为空时从快照加载并在每次修改后写回
+func NewIndex(path string, limits Limits) (*Index, error) {
+	idx := &Index{collections: make(map[string]*collection), limits: limits, path: path}
+	if path == "" {
+		return idx, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vector index: %v", err)
+	}
+	if err := json.Unmarshal(data, &idx.collections); err != nil {
+		return nil, fmt.Errorf("invalid vector index file: %v", err)
+	}
+	for name, c := range idx.collections {
+		if c == nil || c.Documents == nil {
+			idx.collections[name] = &collection{Dimension: dimensionOf(c), Documents: make(map[string]*Document)}
+		}
+	}
+	return idx, nil
+}
+
+func dimensionOf(c *collection) int {
+	if c == nil {
+		return 0
+	}
+	return c.Dimension
+}
+
+// Upsert 写入或覆盖文档，集合不存在时按首个向量的维度创建
+func (idx *Index) Upsert(name string, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	c, ok := idx.collections[name]
+	if !ok {
+		if idx.limits.MaxCollections > 0 && len(idx.collections) >= idx.limits.MaxCollections {
+			return fmt.Errorf("%w: at most %d collections", ErrLimitExceeded, idx.limits.MaxCollections)
+		}
+		c = &collection{Dimension: len(docs[0].Vector), Documents: make(map[string]*Document)}
+	}
+
+	added := make(map[string]bool)
+	for _, doc := range docs {
+		if len(doc.Vector) == 0 || len(doc.Vector) != c.Dimension {
+			return fmt.Errorf("%w: document %s has %d dimensions, collection %s has %d", ErrDimensionMismatch, doc.ID, len(doc.Vector), name, c.Dimension)
+		}
+		if _, exists := c.Documents[doc.ID]; !exists {
+			added[doc.ID] = true
+		}
+	}
+	if idx.limits.MaxDocuments > 0 && len(c.Documents)+len(added) > idx.limits.MaxDocuments {
+		return fmt.Errorf("%w: at most %d documents per collection", ErrLimitExceeded, idx.limits.MaxDocuments)
+	}
+
+	for _, doc := range docs {
+		doc := doc
+		doc.Vector = normalize(doc.Vector)
+		c.Documents[doc.ID] = &doc
+	}
+	idx.collections[name] = c
+	return idx.save()
+}
+
+// Search 按余弦相似度返回得分最高的文档
+func (idx *Index) Search(name string, q Query) ([]Match, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	c, ok := idx.collections[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCollectionNotFound, name)
+	}
+	if len(q.Vector) != c.Dimension {
+		return nil, fmt.Errorf("%w: query has %d dimensions, collection %s has %d", ErrDimensionMismatch, len(q.Vector), name, c.Dimension)
+	}
+
+	query := normalize(q.Vector)
+	matches := make([]Match, 0)
+	for _, doc := range c.Documents {
+		if !matchFilter(doc.Metadata, q.Filter) {
+			continue
+		}
+		score := dot(query, doc.Vector)
+		if score < q.MinScore {
+			continue
+		}
+		matches = append(matches, Match{ID: doc.ID, Score: score, Text: doc.Text, Metadata: doc.Metadata})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if q.TopK > 0 && len(matches) > q.TopK {
+		matches = matches[:q.TopK]
+	}
+	return matches, nil
+}
+
+// Delete 删除文档，返回实际删除的数量
+func (idx *Index) Delete(name string, ids []string) (int, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	c, ok := idx.collections[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrCollectionNotFound, name)
+	}
+	deleted := 0
+	for _, id := range ids {
+		if _, exists := c.Documents[id]; exists {
+			delete(c.Documents, id)
+			deleted++
+		}
+	}
+	if deleted == 0 {
+		return 0, nil
+	}
+	return deleted, idx.save()
+}
+
+// Drop 删除整个集合
+func (idx *Index) Drop(name string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if _, ok := idx.collections[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrCollectionNotFound, name)
+	}
+	delete(idx.collections, name)
+	return idx.save()
+}
+
+// Collections 按名称排序列出集合
+func (idx *Index) Collections() []CollectionInfo {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	infos := make([]CollectionInfo, 0, len(idx.collections))
+	for name, c := range idx.collections {
+		infos = append(infos, CollectionInfo{Name: name, Dimension: c.Dimension, Documents: len(c.Documents)})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// save 将快照写入临时文件后原子替换，调用方需持有写锁
+func (idx *Index) save() error {
+	if idx.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(idx.collections)
+	if err != nil {
+		return err
+	}
+	tmp := idx.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to persist vector index: %v", err)
+	}
+	if err := os.Rename(tmp, idx.path); err != nil {
+		return fmt.Errorf("failed to persist vector index: %v", err)
+	}
+	return nil
+}
+
+// normalize 返回单位向量，存储时归一化后余弦相似度即为点积
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	out := make([]float32, len(v))
+	if sum == 0 {
+		return out
+	}
+	norm := math.Sqrt(sum)
+	for i, x := range v {
+		out[i] = float32(float64(x) / norm)
+	}
+	return out
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func matchFilter(metadata, filter map[string]string) bool {
+	for key, value := range filter {
+		if metadata[key] != value {
+			return false
+		}
+	}
+	return true
+}
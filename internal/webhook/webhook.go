@@ -0,0 +1,223 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 支持的 Webhook 来源
+const (
+	ProviderGitHub  = "github"
+	ProviderStripe  = "stripe"
+	ProviderGeneric = "generic"
+)
+
+// DefaultSignatureHeader generic 来源默认签名头
+const DefaultSignatureHeader = "X-Signature"
+
+// StripeTolerance Stripe 签名时间戳允许的最大偏差
+var StripeTolerance = 5 * time.Minute
+
+// Verify 校验 Webhook 请求的 HMAC 签名
+func Verify(provider, secret, sigHeader string, header http.Header, body []byte) error {
+	if secret == "" {
+		return fmt.Errorf("webhook secret not configured")
+	}
+
+	switch provider {
+	case ProviderGitHub:
+		sig := header.Get("X-Hub-Signature-256")
+		if sig == "" {
+			return fmt.Errorf("missing X-Hub-Signature-256 header")
+		}
+		return compareHex(strings.TrimPrefix(sig, "sha256="), sign(secret, body))
+	case ProviderStripe:
+		return verifyStripe(secret, header.Get("Stripe-Signature"), body)
+	case ProviderGeneric, "":
+		if sigHeader == "" {
+			sigHeader = DefaultSignatureHeader
+		}
+		sig := header.Get(sigHeader)
+		if sig == "" {
+			return fmt.Errorf("missing %s header", sigHeader)
+		}
+		return compareHex(strings.TrimPrefix(sig, "sha256="), sign(secret, body))
+	default:
+		return fmt.Errorf("unsupported webhook provider: %s", provider)
+	}
+}
+
+// verifyStripe 校验 Stripe-Signature 头（t=timestamp,v1=signature）
+func verifyStripe(secret, sigHeader string, body []byte) error {
+	if sigHeader == "" {
+		return fmt.Errorf("missing Stripe-Signature header")
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(sigHeader, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			timestamp = kv[1]
+		case "v1":
+			signatures = append(signatures, kv[1])
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return fmt.Errorf("malformed Stripe-Signature header")
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid Stripe-Signature timestamp: %v", err)
+	}
+	if age := time.Since(time.Unix(ts, 0)); age > StripeTolerance || age < -StripeTolerance {
+		return fmt.Errorf("stripe signature timestamp outside tolerance")
+	}
+
+	payload := append([]byte(timestamp+"."), body...)
+	expected := sign(secret, payload)
+	for _, sig := range signatures {
+		if compareHex(sig, expected) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature mismatch")
+}
+
+// sign 计算 HMAC-SHA256
+func sign(secret string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// compareHex 常量时间比较十六进制签名
+func compareHex(sig string, expected []byte) error {
+	decoded, err := hex.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding")
+	}
+	if !hmac.Equal(decoded, expected) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// Sign 生成 generic/github 格式的签名（sha256=hex），便于客户端和测试使用
+func Sign(secret string, body []byte) string {
+	return "sha256=" + hex.EncodeToString(sign(secret, body))
+}
+
+// EventType 获取 Webhook 事件类型
+func EventType(provider string, header http.Header, payload map[string]interface{}) string {
+	switch provider {
+	case ProviderGitHub:
+		return header.Get("X-GitHub-Event")
+	case ProviderStripe:
+		if t, ok := payload["type"].(string); ok {
+			return t
+		}
+		return ""
+	default:
+		if t := header.Get("X-Event-Type"); t != "" {
+			return t
+		}
+		if t, ok := payload["event"].(string); ok {
+			return t
+		}
+		return ""
+	}
+}
+
+// RenderArguments 根据模板渲染工具参数
+//
+// 以 "$." 开头的字符串按路径取值并保留原始类型（如 "$.payload.amount"），
+// 其余字符串按 text/template 渲染（如 "PR: {{.payload.pull_request.title}}"）。
+func RenderArguments(tmpl map[string]interface{}, data map[string]interface{}) (map[string]interface{}, error) {
+	rendered, err := renderValue(tmpl, data)
+	if err != nil {
+		return nil, err
+	}
+	if rendered == nil {
+		return map[string]interface{}{}, nil
+	}
+	return rendered.(map[string]interface{}), nil
+}
+
+// renderValue 递归渲染参数值
+func renderValue(v interface{}, data map[string]interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			r, err := renderValue(item, data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", k, err)
+			}
+			out[k] = r
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			r, err := renderValue(item, data)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %v", i, err)
+			}
+			out[i] = r
+		}
+		return out, nil
+	case string:
+		if strings.HasPrefix(val, "$.") {
+			return Lookup(data, strings.TrimPrefix(val, "$.")), nil
+		}
+		if !strings.Contains(val, "{{") {
+			return val, nil
+		}
+		t, err := template.New("arg").Option("missingkey=zero").Parse(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("template execution failed: %v", err)
+		}
+		return buf.String(), nil
+	default:
+		return val, nil
+	}
+}
+
+// Lookup 按点分路径取值，支持数组下标（如 commits.0.message）
+func Lookup(data interface{}, path string) interface{} {
+	current := data
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current = node[key]
+		case []interface{}:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil
+			}
+			current = node[idx]
+		default:
+			return nil
+		}
+	}
+	return current
+}
@@ -0,0 +1,297 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+)
+
+// 支持的响应压缩编码
+const (
+	EncodingGzip = "gzip"
+	EncodingZstd = "zstd"
+)
+
+// DefaultCompressionMinSize 默认的最小压缩字节数，小于该值的响应原样返回
+const DefaultCompressionMinSize = 1024
+
+var gzipPool = sync.Pool{
+	New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	},
+}
+
+var zstdPool = sync.Pool{
+	New: func() interface{} {
+		w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1), zstd.WithEncoderLevel(zstd.SpeedDefault))
+		return w
+	},
+}
+
+// compressor 压缩写入器
+type compressor interface {
+	io.Writer
+	Flush() error
+	Close() error
+	Reset(w io.Writer)
+}
+
+// CompressionMiddleware 响应压缩中间件
+//
+// 根据 Accept-Encoding 在 encodings（按服务端偏好排序）中协商编码。
+// 响应先缓冲至 minSize 字节再决定是否压缩，小响应不受影响；
+// SSE 响应直接压缩，每次 Flush 同时刷新压缩器，事件不会滞留在压缩缓冲区中。
+func CompressionMiddleware(encodings []string, minSize int) gin.HandlerFunc {
+	if minSize <= 0 {
+		minSize = DefaultCompressionMinSize
+	}
+
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), encodings)
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		cw := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			minSize:        minSize,
+		}
+		c.Writer = cw
+		c.Header("Vary", "Accept-Encoding")
+
+		defer func() {
+			cw.finish()
+			c.Writer = cw.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// compressWriter 延迟决定是否压缩的响应写入器
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+
+	buf      bytes.Buffer
+	decided  bool
+	writer   compressor // 为空表示不压缩
+	finished bool
+}
+
+// Write 写入响应体
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buf.Write(data)
+		if w.buf.Len() < w.minSize && !w.isEventStream() {
+			return len(data), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+
+	if w.writer != nil {
+		return w.writer.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写入字符串响应体
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 刷新缓冲区，流式响应在首次刷新时即确定编码
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(); err != nil {
+			return
+		}
+	}
+	if w.writer != nil {
+		w.writer.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide 根据已缓冲的内容决定是否压缩，并写出缓冲数据
+func (w *compressWriter) decide() error {
+	w.decided = true
+
+	if w.shouldCompress() {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		w.writer = acquireCompressor(w.encoding, w.ResponseWriter)
+	}
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	data := w.buf.Bytes()
+	defer w.buf.Reset()
+
+	if w.writer != nil {
+		_, err := w.writer.Write(data)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(data)
+	return err
+}
+
+// shouldCompress 判断当前响应是否需要压缩
+func (w *compressWriter) shouldCompress() bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+
+	if w.isEventStream() {
+		return true
+	}
+	if w.buf.Len() < w.minSize {
+		return false
+	}
+	return isCompressible(header.Get("Content-Type"))
+}
+
+// isEventStream 是否为 SSE 响应
+func (w *compressWriter) isEventStream() bool {
+	return strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream")
+}
+
+// finish 写出剩余数据并关闭压缩器
+func (w *compressWriter) finish() {
+	if w.finished {
+		return
+	}
+	w.finished = true
+
+	if !w.decided {
+		if w.buf.Len() == 0 {
+			// 无响应体时不设置 Content-Encoding
+			w.decided = true
+		} else if err := w.decide(); err != nil {
+			return
+		}
+	}
+
+	if w.writer != nil {
+		w.writer.Close()
+		releaseCompressor(w.encoding, w.writer)
+		w.writer = nil
+	}
+}
+
+// acquireCompressor 从对象池获取压缩器
+func acquireCompressor(encoding string, dst io.Writer) compressor {
+	var cw compressor
+	switch encoding {
+	case EncodingZstd:
+		cw = zstdPool.Get().(*zstd.Encoder)
+	default:
+		cw = gzipPool.Get().(*gzip.Writer)
+	}
+	cw.Reset(dst)
+	return cw
+}
+
+// releaseCompressor 归还压缩器
+func releaseCompressor(encoding string, cw compressor) {
+	cw.Reset(io.Discard)
+	switch encoding {
+	case EncodingZstd:
+		zstdPool.Put(cw)
+	default:
+		gzipPool.Put(cw)
+	}
+}
+
+// isCompressible 判断内容类型是否值得压缩
+func isCompressible(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range []string{"text/", "application/json", "application/javascript", "application/xml", "image/svg+xml"} {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return strings.Contains(contentType, "+json") || strings.Contains(contentType, "+xml")
+}
+
+// negotiateEncoding 根据 Accept-Encoding 选择编码
+//
+// 选择客户端权重最高的编码，权重相同时按服务端偏好顺序；q=0 表示不接受。
+func negotiateEncoding(acceptEncoding string, supported []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	weights := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, q := parseEncodingWeight(part)
+		if name == "" {
+			continue
+		}
+		if name == "*" {
+			wildcard = q
+			continue
+		}
+		weights[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range supported {
+		if encoding != EncodingGzip && encoding != EncodingZstd {
+			continue
+		}
+		q, ok := weights[encoding]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// parseEncodingWeight 解析单个编码及其权重
+func parseEncodingWeight(part string) (string, float64) {
+	fields := strings.Split(part, ";")
+	name := strings.ToLower(strings.TrimSpace(fields[0]))
+	q := 1.0
+	for _, param := range fields[1:] {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "q=") {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+		if err != nil {
+			return "", 0
+		}
+		q = parsed
+	}
+	return name, q
+}
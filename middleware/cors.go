@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORS 允许的请求头与暴露的响应头
+const (
+	corsAllowMethods  = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowHeaders  = "Content-Type, Authorization, X-API-Key, Cache-Control, Last-Event-ID, Mcp-Session-Id, X-MCP-Client-Name, X-Request-ID"
+	corsExposeHeaders = "Mcp-Session-Id, X-Request-ID"
+	corsMaxAge        = "600"
+)
+
+// CORSMiddleware 跨域中间件
+//
+// origins 为逗号分隔的允许来源，空或 "*" 表示允许任意来源。
+// 预检请求（OPTIONS）在此直接返回 204，不进入后续的鉴权中间件。
+func CORSMiddleware(origins string) gin.HandlerFunc {
+	allowAll := strings.TrimSpace(origins) == ""
+	allowed := make(map[string]bool)
+	for _, origin := range strings.Split(origins, ",") {
+		switch origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin {
+		case "":
+		case "*":
+			allowAll = true
+		default:
+			allowed[origin] = true
+		}
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+		switch {
+		case allowAll:
+			header.Set("Access-Control-Allow-Origin", "*")
+		case allowed[origin]:
+			header.Set("Access-Control-Allow-Origin", origin)
+		default:
+			// 未允许的来源不返回 CORS 头，由浏览器拦截
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+		header.Set("Access-Control-Expose-Headers", corsExposeHeaders)
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", corsAllowMethods)
+			header.Set("Access-Control-Allow-Headers", corsAllowHeaders)
+			header.Set("Access-Control-Max-Age", corsMaxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
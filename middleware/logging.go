@@ -1,35 +1,136 @@
-package middleware
-
-import (
-	"time"
-
-	"github.com/gin-gonic/gin"
-	"Weave-Toolkit/internal/logger"
-)
-
-// LoggingMiddleware 日志中间件
-func LoggingMiddleware(log *logger.Logger) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		start := time.Now()
-		path := c.Request.URL.Path
-
-		// 处理请求
-		c.Next()
-
-		// 记录日志
-		latency := time.Since(start)
-		status := c.Writer.Status()
-		clientIP := c.ClientIP()
-		method := c.Request.Method
-		userAgent := c.Request.UserAgent()
-
-		log.Info().
-			Str("method", method).
-			Str("path", path).
-			Int("status", status).
-			Str("client_ip", clientIP).
-			Str("user_agent", userAgent).
-			Dur("latency", latency).
-			Msg("HTTP request")
-	}
-}
\ No newline at end of file
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"Weave-Toolkit/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// 访问日志格式
+const (
+	AccessLogJSON   = "json"   // 结构化字段
+	AccessLogCommon = "common" // Common Log Format，末尾附加 MCP 方法、工具名与请求ID
+	AccessLogOff    = "off"
+)
+
+// 处理器写入 gin.Context 的 MCP 请求信息，供访问日志读取
+const (
+	ContextKeyMCPMethod = "mcp_method"
+	ContextKeyMCPTool   = "mcp_tool"
+	ContextKeyMCPClient = "mcp_client"
+)
+
+// commonLogTime Common Log Format 的时间格式
+const commonLogTime = "02/Jan/2006:15:04:05 -0700"
+
+// SetMCPRequestInfo 记录解码后的 JSON-RPC 方法、工具名与客户端，写入访问日志
+func SetMCPRequestInfo(c *gin.Context, method, tool, client string) {
+	c.Set(ContextKeyMCPMethod, method)
+	if tool != "" {
+		c.Set(ContextKeyMCPTool, tool)
+	}
+	if client != "" {
+		c.Set(ContextKeyMCPClient, client)
+	}
+}
+
+// LoggingMiddleware 日志中间件，使用 JSON 格式的访问日志
+func LoggingMiddleware(log *logger.Logger) gin.HandlerFunc {
+	return AccessLogMiddleware(log, AccessLogJSON)
+}
+
+// AccessLogMiddleware 访问日志中间件
+//
+// 除路径与状态码外，记录处理器通过 SetMCPRequestInfo 写入的 MCP 方法、工具名和客户端，
+// 以及请求 ID、请求体与响应体字节数（响应字节数为压缩后实际写出的大小）。
+func AccessLogMiddleware(log *logger.Logger, format string) gin.HandlerFunc {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == AccessLogOff {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+
+		body := &countingBody{ReadCloser: c.Request.Body}
+		if c.Request.Body != nil {
+			c.Request.Body = body
+		}
+
+		// 处理请求
+		c.Next()
+
+		// 记录日志
+		latency := time.Since(start)
+		status := c.Writer.Status()
+		clientIP := c.ClientIP()
+		method := c.Request.Method
+		userAgent := c.Request.UserAgent()
+		bytesIn := atomic.LoadInt64(&body.n)
+		bytesOut := c.Writer.Size()
+		if bytesOut < 0 {
+			bytesOut = 0
+		}
+
+		client := c.GetString(ContextKeyMCPClient)
+		if client == "" {
+			client = c.GetHeader("X-MCP-Client-Name")
+		}
+
+		if format == AccessLogCommon {
+			log.Info().Msg(fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %d "%s" "%s" %s %s`,
+				clientIP, dashIfEmpty(client), start.Format(commonLogTime),
+				method, c.Request.RequestURI, c.Request.Proto, status, bytesOut,
+				dashIfEmpty(c.GetString(ContextKeyMCPMethod)), dashIfEmpty(c.GetString(ContextKeyMCPTool)),
+				dashIfEmpty(c.GetString("request_id")), latency))
+			return
+		}
+
+		event := log.Info().
+			Str("method", method).
+			Str("path", path).
+			Int("status", status).
+			Str("client_ip", clientIP).
+			Str("user_agent", userAgent).
+			Str("request_id", c.GetString("request_id")).
+			Int64("bytes_in", bytesIn).
+			Int("bytes_out", bytesOut).
+			Dur("latency", latency)
+		if rpcMethod := c.GetString(ContextKeyMCPMethod); rpcMethod != "" {
+			event = event.Str("rpc_method", rpcMethod)
+		}
+		if tool := c.GetString(ContextKeyMCPTool); tool != "" {
+			event = event.Str("tool", tool)
+		}
+		if client != "" {
+			event = event.Str("client", client)
+		}
+		event.Msg("HTTP request")
+	}
+}
+
+// countingBody 统计已读取的请求体字节数
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	atomic.AddInt64(&b.n, int64(n))
+	return n, err
+}
+
+// dashIfEmpty Common Log Format 中空字段以 - 表示
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -1,24 +1,40 @@
-package middleware
-
-import (
-	"Weave-Toolkit/internal/logger"
-	"net/http"
-
-	"github.com/gin-gonic/gin"
-)
-
-// RecoveryMiddleware 崩溃恢复中间件
-func RecoveryMiddleware(log *logger.Logger) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		defer func() {
-			if err := recover(); err != nil {
-				c.AbortWithStatus(http.StatusInternalServerError)
-				log.Error().
-					Interface("panic", err).
-					Str("path", c.Request.URL.Path).
-					Msg("Recovered from panic")
-			}
-		}()
-		c.Next()
-	}
-}
+package middleware
+
+import (
+	"Weave-Toolkit/internal/logger"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecoveryMiddleware 崩溃恢复中间件
+//
+// 处理器 panic 时记录调用栈并返回 500，响应已开始写出（如 SSE）时仅中止请求。
+func RecoveryMiddleware(log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
+				log.Error().
+					Interface("panic", err).
+					Str("path", c.Request.URL.Path).
+					Str("request_id", c.GetString("request_id")).
+					Bytes("stack", debug.Stack()).
+					Msg("Recovered from panic")
+
+				if c.Writer.Written() {
+					c.Abort()
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "internal server error",
+				})
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,200 @@
+package weave
+
+import (
+	"context"
+	"fmt"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/tools"
+)
+
+// DefaultAddress 未指定 HTTP 传输时的监听地址
+const DefaultAddress = ":8080"
+
+// Transport 服务器对外提供服务的方式，可同时使用多种
+type Transport func(cfg *Config)
+
+// HTTP 在 address 上提供 /mcp 等 HTTP 端点，支持 unix: 地址
+func HTTP(address string) Transport {
+	return func(cfg *Config) {
+		cfg.ServerAddress = address
+	}
+}
+
+// HTTPS 在 address 上以 TLS 提供 HTTP 端点
+func HTTPS(address, certFile, keyFile string) Transport {
+	return func(cfg *Config) {
+		cfg.ServerAddress = address
+		cfg.TLSCertFile = certFile
+		cfg.TLSKeyFile = keyFile
+	}
+}
+
+// GRPC 在独立端口提供 weave.v1.ToolService
+func GRPC(address string) Transport {
+	return func(cfg *Config) {
+		cfg.GRPCAddress = address
+	}
+}
+
+// Admin 在独立端口提供管理接口，apiKey 为空时不鉴权
+func Admin(address, apiKey string) Transport {
+	return func(cfg *Config) {
+		cfg.AdminAddress = address
+		cfg.AdminAPIKey = apiKey
+	}
+}
+
+// Builder 组装嵌入式 MCP 服务器
+type Builder struct {
+	cfg       *Config
+	tools     []Tool
+	builtins  bool
+	logger    Logger
+	observers []CallObserver
+	providers []ResourceProvider
+	err       error
+}
+
+// New 以默认配置创建 Builder：全部分类启用，不注册内置工具，日志写入 ./log
+func New() *Builder {
+	return &Builder{cfg: DefaultConfig()}
+}
+
+// FromEnv 以与独立服务相同的方式从环境变量、.env 与 tool-config.json 加载配置
+//
+// 加载失败时错误在 Build 或 Run 时返回。
+func FromEnv() *Builder {
+	cfg, err := config.Load()
+	if err != nil {
+		return &Builder{cfg: DefaultConfig(), err: err}
+	}
+	return &Builder{cfg: cfg}
+}
+
+// DefaultConfig 嵌入模式的默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		LogLevel:   "info",
+		LogDir:     "./log",
+		ToolConfig: *mcp.DefaultToolConfig(),
+	}
+}
+
+// WithConfig 调整配置，按调用顺序应用
+func (b *Builder) WithConfig(configure func(cfg *Config)) *Builder {
+	configure(b.cfg)
+	return b
+}
+
+// WithTool 注册工具，与内置工具重名时覆盖内置工具
+func (b *Builder) WithTool(toolList ...Tool) *Builder {
+	b.tools = append(b.tools, toolList...)
+	return b
+}
+
+// WithBuiltinTools 同时注册所有内置工具，内置工具读取 ToolConfig 中的配置
+func (b *Builder) WithBuiltinTools() *Builder {
+	b.builtins = true
+	return b
+}
+
+// WithResourceProvider 注册资源提供者，与按 resources 配置创建的内置提供者合并，同一 scheme 时覆盖内置提供者
+func (b *Builder) WithResourceProvider(providers ...ResourceProvider) *Builder {
+	b.providers = append(b.providers, providers...)
+	return b
+}
+
+// WithTransport 设置对外提供服务的方式，未设置 HTTP 时监听 DefaultAddress
+func (b *Builder) WithTransport(transports ...Transport) *Builder {
+	for _, transport := range transports {
+		transport(b.cfg)
+	}
+	return b
+}
+
+// WithLogger 将日志转发到嵌入应用的日志库，如 slog.Default()；未设置时按配置的 LogDir 与 LogLevel
+// 写入日志文件与标准输出
+func (b *Builder) WithLogger(log Logger) *Builder {
+	b.logger = log
+	return b
+}
+
+// WithObserver 注册工具调用观察者，每次调用结束后收到调用记录
+func (b *Builder) WithObserver(observers ...CallObserver) *Builder {
+	b.observers = append(b.observers, observers...)
+	return b
+}
+
+// Build 创建服务器但不启动，可通过 Handler 挂载到已有的 HTTP 服务
+//
+// 未通过 WithLogger 提供日志器时创建的日志器随进程存在；需要在退出时关闭日志的应用应使用 Run。
+func (b *Builder) Build() (*Server, error) {
+	srv, _, err := b.build()
+	return srv, err
+}
+
+// Run 创建并启动服务器，ctx 取消后排空进行中的请求并关闭
+func (b *Builder) Run(ctx context.Context) error {
+	srv, closeLogger, err := b.build()
+	if err != nil {
+		return err
+	}
+	defer closeLogger()
+	return srv.Start(ctx)
+}
+
+// build 创建服务器，返回关闭由 Builder 创建的日志器的函数
+func (b *Builder) build() (*Server, func(), error) {
+	if b.err != nil {
+		return nil, nil, b.err
+	}
+	if b.cfg.ServerAddress == "" {
+		b.cfg.ServerAddress = DefaultAddress
+	}
+
+	opts := []mcp.ServerOption{mcp.WithConfig(b.cfg), mcp.WithTools(b.catalog()...)}
+	if b.providers != nil {
+		opts = append(opts, mcp.WithResourceProviders(append(tools.BuiltinResourceProviders(&b.cfg.ToolConfig), b.providers...)...))
+	}
+	closeLogger := func() {}
+	if b.logger != nil {
+		opts = append(opts, mcp.WithLogger(b.logger))
+	} else {
+		created, err := logger.New(b.cfg.LogDir, b.cfg.LogLevel, b.cfg.LogBackend)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize logger: %v", err)
+		}
+		opts = append(opts, mcp.WithLogManager(created))
+		closeLogger = func() { created.Close() }
+	}
+
+	srv, err := mcp.New(opts...)
+	if err != nil {
+		closeLogger()
+		return nil, nil, err
+	}
+	for _, observer := range b.observers {
+		srv.ToolManager().AddCallObserver(observer)
+	}
+	return srv, closeLogger, nil
+}
+
+// catalog 按注册顺序合并内置工具与自定义工具，同名时自定义工具优先
+func (b *Builder) catalog() []Tool {
+	var toolList []Tool
+	if b.builtins {
+		custom := make(map[string]bool, len(b.tools))
+		for _, tool := range b.tools {
+			custom[tool.Name()] = true
+		}
+		for _, tool := range tools.BuiltinTools(&b.cfg.ToolConfig) {
+			if !custom[tool.Name()] {
+				toolList = append(toolList, tool)
+			}
+		}
+	}
+	return append(toolList, b.tools...)
+}
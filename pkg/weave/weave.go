@@ -0,0 +1,143 @@
+// Package weave 以库的形式在其他 Go 服务中嵌入 MCP 服务器
+//
+// 工具接口、调用结果与参数 Schema 以类型别名导出，嵌入应用实现 Tool 接口后通过
+// Builder 注册，与内置工具共用同一套校验、超时、熔断与调用记录：
+//
+//	err := weave.New().
+//		WithTool(myTool).
+//		WithTransport(weave.HTTP(":8080")).
+//		Run(ctx)
+package weave
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/schema"
+	"Weave-Toolkit/internal/tools"
+)
+
+// 工具接口与调用结果
+type (
+	Tool                     = tools.Tool
+	StreamTool               = tools.StreamTool
+	EmitterTool              = tools.EmitterTool
+	SchemaTool               = tools.SchemaTool
+	OutputSchemaTool         = tools.OutputSchemaTool
+	ContentTool              = tools.ContentTool
+	ResourceTool             = tools.ResourceTool
+	ToolResource             = tools.ToolResource
+	ResourceTemplateProvider = tools.ResourceTemplateProvider
+	ResourceTemplate         = tools.ResourceTemplate
+	ResourceProvider         = tools.ResourceProvider
+	ToolCategory             = tools.ToolCategory
+	ToolInfo                 = tools.ToolInfo
+	ToolCallResult           = tools.ToolCallResult
+	ToolCallContent          = tools.ToolCallContent
+	StreamCallback           = tools.StreamCallback
+	CallRecord               = tools.CallRecord
+	CallObserver             = tools.CallObserver
+	ToolManager              = tools.ToolManager
+)
+
+// 工具分类
+const (
+	CategoryMath    = tools.CategoryMath
+	CategoryAI      = tools.CategoryAI
+	CategorySystem  = tools.CategorySystem
+	CategoryUtility = tools.CategoryUtility
+)
+
+// Schema 工具参数的 JSON Schema
+type Schema = schema.Schema
+
+// JSON Schema 类型
+const (
+	TypeObject  = schema.TypeObject
+	TypeString  = schema.TypeString
+	TypeNumber  = schema.TypeNumber
+	TypeInteger = schema.TypeInteger
+	TypeBoolean = schema.TypeBoolean
+	TypeArray   = schema.TypeArray
+)
+
+// Object 创建对象类型的 Schema
+func Object(properties map[string]*Schema, required ...string) *Schema {
+	return schema.Object(properties, required...)
+}
+
+// 服务器、配置与日志
+type (
+	Server     = mcp.Server
+	Config     = config.Config
+	ToolConfig = config.ToolManagerConfig
+	Logger     = mcp.Logger
+)
+
+// 调用工具时可能返回的错误，可用 errors.Is 判断
+var (
+	ErrToolNotFound     = tools.ErrToolNotFound
+	ErrInvalidArguments = tools.ErrInvalidArguments
+	ErrInvalidOutput    = tools.ErrInvalidOutput
+	ErrToolTimeout      = tools.ErrToolTimeout
+	ErrToolPanic        = tools.ErrToolPanic
+)
+
+// 工具调用的请求上下文
+type (
+	ToolContext      = tools.ToolContext
+	ProgressReporter = tools.ProgressReporter
+	ProgressFunc     = tools.ProgressFunc
+	Sampler          = tools.Sampler
+	HTTPClients      = tools.HTTPClients
+)
+
+// 流式调用事件
+type (
+	Emitter         = tools.Emitter
+	StreamEvent     = tools.StreamEvent
+	StreamEventType = tools.StreamEventType
+	EventSink       = tools.EventSink
+	LogLevel        = tools.LogLevel
+)
+
+// 流式调用事件类型与日志消息级别
+const (
+	EventPartial  = tools.EventPartial
+	EventProgress = tools.EventProgress
+	EventLog      = tools.EventLog
+	EventFinal    = tools.EventFinal
+
+	LogDebug   = tools.LogDebug
+	LogInfo    = tools.LogInfo
+	LogWarning = tools.LogWarning
+	LogError   = tools.LogError
+)
+
+// CallbackEmitter 将 Emitter 的片段转换为旧式回调，用于以 Stream 实现 ExecuteStream
+func CallbackEmitter(callback StreamCallback) Emitter {
+	return tools.CallbackEmitter(callback)
+}
+
+// ToolContextFrom 获取调用的客户端、会话、租户、请求ID、日志器、进度接收者、补全接口与共享 HTTP 客户端
+func ToolContextFrom(ctx context.Context) ToolContext {
+	return tools.ToolContextFrom(ctx)
+}
+
+// HTTPClient 获取访问外部服务的客户端，复用按 http_client 配置的连接池、代理与重试策略
+func HTTPClient(ctx context.Context, timeout time.Duration) *http.Client {
+	return tools.HTTPClient(ctx, timeout)
+}
+
+// ProgressFromContext 获取进度接收者，调用方不接收进度时上报被忽略
+func ProgressFromContext(ctx context.Context) ProgressReporter {
+	return tools.ProgressFromContext(ctx)
+}
+
+// BuiltinTools 按工具配置创建所有内置工具
+func BuiltinTools(toolConfig *ToolConfig) []Tool {
+	return tools.BuiltinTools(toolConfig)
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestToolConfig() *config.ToolManagerConfig {
+	return &config.ToolManagerConfig{
+		Categories: map[string]config.CategoryConfig{
+			"math":    {Enabled: true, MaxTools: 10},
+			"ai":      {Enabled: true, MaxTools: 10},
+			"utility": {Enabled: true, MaxTools: 30},
+			"system":  {Enabled: true, MaxTools: 20},
+		},
+	}
+}
+
+func TestToolAliases(t *testing.T) {
+	cfg := newTestToolConfig()
+	cfg.Aliases = map[string]string{
+		"calc":                  "calculator",
+		"stream_text_processor": "calculator", // 与已注册工具冲突，应被忽略
+	}
+	cfg.ClientAliases = map[string]map[string]string{
+		"short-names-client": {"text": "stream_text_processor"},
+	}
+
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	tm.RegisterAllTools()
+
+	t.Run("全局别名解析", func(t *testing.T) {
+		assert.Equal(t, "calculator", tm.ResolveAlias("", "calc"))
+		assert.Equal(t, "calculator", tm.ResolveAlias("any-client", "calc"))
+	})
+
+	t.Run("工具名优先于别名", func(t *testing.T) {
+		assert.Equal(t, "stream_text_processor", tm.ResolveAlias("", "stream_text_processor"))
+	})
+
+	t.Run("客户端别名", func(t *testing.T) {
+		assert.Equal(t, "stream_text_processor", tm.ResolveAlias("short-names-client", "text"))
+		assert.Equal(t, "text", tm.ResolveAlias("other-client", "text"))
+		assert.Equal(t, "text", tm.ExposedName("short-names-client", "stream_text_processor"))
+		assert.Equal(t, "stream_text_processor", tm.ExposedName("other-client", "stream_text_processor"))
+	})
+
+	t.Run("通过别名调用工具", func(t *testing.T) {
+		args, _ := json.Marshal(tools.CalculatorArgs{Operation: "add", A: 1, B: 2})
+		result, err := tm.CallTool(context.Background(), "calc", args)
+		require.NoError(t, err)
+		require.Len(t, result.Content, 1)
+		assert.JSONEq(t, `{"result":3}`, result.Content[0].Text)
+	})
+}
+
+func TestToolNamespaces(t *testing.T) {
+	cfg := newTestToolConfig()
+	cfg.Aliases = map[string]string{"upper": "util.text.upper"}
+	cfg.Namespaces = map[string]config.NamespaceConfig{
+		"util.text":   {Alias: "text", ShortNames: true},
+		"gw.github":   {ShortNames: true},
+		"gw.internal": {ShortNames: true},
+	}
+
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	reverse := testkit.NewMockTool("util.text.reverse").Returns("reversed")
+	tm.UseTools(
+		reverse,
+		testkit.NewMockTool("util.text.upper"),
+		testkit.NewMockTool("gw.github.search"),
+		testkit.NewMockTool("gw.internal.search"),
+		testkit.NewMockTool("gw.github.calculator"),
+		&tools.CalculatorTool{},
+	)
+	tm.RegisterAllTools()
+
+	t.Run("命名空间别名与短名称", func(t *testing.T) {
+		assert.Equal(t, "util.text.reverse", tm.ResolveAlias("", "reverse"))
+		assert.Equal(t, "util.text.reverse", tm.ResolveAlias("", "text.reverse"))
+		assert.Equal(t, "util.text.upper", tm.ResolveAlias("", "upper"))
+
+		result, err := tm.CallTool(context.Background(), "text.reverse", json.RawMessage(`{}`))
+		require.NoError(t, err)
+		assert.Equal(t, 1, reverse.CallCount())
+		assert.Contains(t, result.Content[0].Text, "reversed")
+	})
+
+	t.Run("已注册工具优先于短名称", func(t *testing.T) {
+		assert.Equal(t, "calculator", tm.ResolveAlias("", "calculator"))
+	})
+
+	t.Run("有歧义的短名称", func(t *testing.T) {
+		assert.Equal(t, "search", tm.ResolveAlias("", "search"))
+		_, err := tm.CallTool(context.Background(), "search", json.RawMessage(`{}`))
+		require.Error(t, err)
+		assert.True(t, werrors.Is(err, werrors.KindNotFound))
+		assert.Contains(t, err.Error(), "search is ambiguous, use one of: gw.github.search, gw.internal.search")
+	})
+
+	t.Run("注册时检查名称冲突", func(t *testing.T) {
+		for _, name := range []string{"util.text", "util.text.reverse.v2", "bad name", "util..text"} {
+			err := tm.RegisterTool(testkit.NewMockTool(name))
+			assert.True(t, errors.Is(err, tools.ErrToolNameConflict), name)
+		}
+		err := tm.RegisterTool(testkit.NewMockTool("calculator").WithCategory(tools.CategoryUtility))
+		assert.ErrorContains(t, err, "already registered in category math")
+
+		// 同一分类中重新注册是允许的
+		require.NoError(t, tm.RegisterTool(testkit.NewMockTool("util.text.reverse")))
+	})
+}
@@ -0,0 +1,135 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/jobs"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovalGate(t *testing.T) {
+	cfg := newTestToolConfig()
+	cfg.Approvals = config.ApprovalConfig{Tools: []string{"calculator"}, Tags: []string{"network"}}
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	tm.RegisterAllTools()
+
+	ctx := context.Background()
+	assert.True(t, tm.RequiresApproval(ctx, "calculator"))
+	assert.True(t, tm.RequiresApproval(ctx, "http_fetch"))
+	assert.False(t, tm.RequiresApproval(ctx, "crypto"))
+	assert.False(t, tm.RequiresApproval(ctx, "missing"))
+
+	// 未经批准的调用被拒绝，批准后正常执行
+	args := json.RawMessage(`{"operation":"add","a":1,"b":2}`)
+	_, err := tm.CallTool(ctx, "calculator", args)
+	assert.True(t, errors.Is(err, tools.ErrApprovalRequired))
+	_, err = tm.CallTool(tools.WithApproved(ctx), "calculator", args)
+	assert.NoError(t, err)
+}
+
+func TestApprovalQueue(t *testing.T) {
+	url := newTestServer(t, func(cfg *config.Config) {
+		cfg.APIKey = "secret"
+		cfg.ToolConfig.Approvals = config.ApprovalConfig{Tools: []string{"calculator"}, Timeout: 1}
+	})
+	baseURL := strings.TrimSuffix(url, "/mcp")
+	var sessionID string
+	send := func(method, path, body, accept string) *http.Response {
+		req, err := http.NewRequest(method, baseURL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "secret")
+		if sessionID != "" {
+			req.Header.Set(mcp.SessionIDHeader, sessionID)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	admin := func(path string) *http.Response {
+		return send(http.MethodPost, "/admin"+path, `{"by":"alice"}`, "")
+	}
+	getJob := func(id string) jobs.Job {
+		reply := decodeReply(t, send(http.MethodPost, "/mcp", `{"jsonrpc":"2.0","id":9,"method":"jobs/get","params":{"jobId":"`+id+`"}}`, ""))
+		require.Nil(t, reply.Error)
+		var job jobs.Job
+		require.NoError(t, json.Unmarshal(reply.Result, &job))
+		return job
+	}
+	call := toolCall(1, "calculator", `{"operation":"add","a":1,"b":2}`)
+
+	// 不属于会话的非流式调用无法在之后查询任务，直接拒绝
+	reply := decodeReply(t, send(http.MethodPost, "/mcp", call, ""))
+	require.NotNil(t, reply.Error)
+	assert.Contains(t, reply.Error.Message, "require a session")
+
+	// 非流式调用返回等待审批的任务
+	sessionID = send(http.MethodPost, "/mcp", `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"approvals-test"}}}`, "").Header.Get(mcp.SessionIDHeader)
+	require.NotEmpty(t, sessionID)
+	reply = decodeReply(t, send(http.MethodPost, "/mcp", call, ""))
+	require.Nil(t, reply.Error)
+	var parked struct {
+		JobID  string      `json:"jobId"`
+		Status jobs.Status `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(reply.Result, &parked))
+	assert.Equal(t, jobs.StatusAwaitingApproval, parked.Status)
+
+	var listed struct {
+		Approvals []jobs.Job `json:"approvals"`
+	}
+	require.NoError(t, json.NewDecoder(send(http.MethodGet, "/admin/approvals", "", "").Body).Decode(&listed))
+	require.Len(t, listed.Approvals, 1)
+	assert.Equal(t, parked.JobID, listed.Approvals[0].ID)
+
+	require.Equal(t, http.StatusOK, admin("/approvals/"+parked.JobID+"/approve").StatusCode)
+	assert.Equal(t, http.StatusConflict, admin("/approvals/"+parked.JobID+"/deny").StatusCode)
+	assert.Equal(t, http.StatusNotFound, admin("/approvals/job_missing/approve").StatusCode)
+	require.Eventually(t, func() bool { return getJob(parked.JobID).Status == jobs.StatusCompleted }, 2*time.Second, 10*time.Millisecond)
+	job := getJob(parked.JobID)
+	assert.Equal(t, jobs.DecisionApproved, job.Approval.Decision)
+	assert.Equal(t, "alice", job.Approval.DecidedBy)
+
+	// 流式调用等待期间收到进度，批准后收到结果
+	stream := send(http.MethodPost, "/mcp", call, "text/event-stream")
+	reader := bufio.NewReader(stream.Body)
+	assert.Equal(t, "tool/call", readEvent(t, reader).Event)
+	assert.Equal(t, "progress", readEvent(t, reader).Event)
+	status := readEvent(t, reader)
+	require.Equal(t, "job/status", status.Event)
+	var waiting jobs.Job
+	require.NoError(t, json.Unmarshal(status.Data, &waiting))
+	require.Equal(t, http.StatusOK, admin("/approvals/"+waiting.ID+"/approve").StatusCode)
+	for {
+		event := readEvent(t, reader)
+		require.NotEqual(t, "error", event.Event, string(event.Data))
+		if event.Event == "done" {
+			assert.Contains(t, string(event.Data), `\"result\":3`)
+			break
+		}
+	}
+
+	// 超时未处理的调用自动拒绝
+	reply = decodeReply(t, send(http.MethodPost, "/mcp", call, ""))
+	require.NoError(t, json.Unmarshal(reply.Result, &parked))
+	require.Eventually(t, func() bool { return getJob(parked.JobID).Status == jobs.StatusDenied }, 3*time.Second, 50*time.Millisecond)
+	job = getJob(parked.JobID)
+	assert.Equal(t, jobs.DecisionExpired, job.Approval.Decision)
+	assert.Equal(t, "denied: approval timed out", job.Error)
+}
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runArchive(t *testing.T, tool *tools.ArchiveTool, args string) tools.ArchiveResult {
+	t.Helper()
+	raw, err := tool.Execute(context.Background(), json.RawMessage(args))
+	require.NoError(t, err)
+	var result tools.ArchiveResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	for _, name := range []string{"out/docs.zip", "out/docs.tar.gz", "out/docs.tar"} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFile(t, filepath.Join(dir, "docs", "a.txt"), "alpha")
+			writeTestFile(t, filepath.Join(dir, "docs", "sub", "b.md"), "bravo")
+			tool := tools.NewArchiveTool(config.ArchiveConfig{Roots: map[string]string{"data": dir}})
+
+			created := runArchive(t, tool, `{"op":"create","archive":"`+name+`","sources":["docs"]}`)
+			assert.Equal(t, 2, created.Files)
+			assert.Equal(t, int64(10), created.Bytes)
+			assert.Positive(t, created.Size)
+
+			_, err := tool.Execute(context.Background(), json.RawMessage(`{"op":"create","archive":"`+name+`","sources":["docs"]}`))
+			assert.ErrorContains(t, err, "already exists")
+
+			listed := runArchive(t, tool, `{"op":"list","archive":"`+name+`"}`)
+			var names []string
+			for _, entry := range listed.Entries {
+				names = append(names, entry.Name)
+			}
+			assert.Equal(t, []string{"docs/", "docs/a.txt", "docs/sub/", "docs/sub/b.md"}, names)
+			assert.Equal(t, 2, listed.Files)
+
+			extracted := runArchive(t, tool, `{"op":"extract","archive":"`+name+`","dest":"restore","entries":["docs/sub"]}`)
+			assert.Equal(t, 1, extracted.Files)
+			data, err := os.ReadFile(filepath.Join(dir, "restore", "docs", "sub", "b.md"))
+			require.NoError(t, err)
+			assert.Equal(t, "bravo", string(data))
+			assert.NoFileExists(t, filepath.Join(dir, "restore", "docs", "a.txt"))
+
+			// 已存在的文件需要 overwrite
+			_, err = tool.Execute(context.Background(), json.RawMessage(`{"op":"extract","archive":"`+name+`","dest":"restore"}`))
+			assert.ErrorContains(t, err, "already exists")
+			runArchive(t, tool, `{"op":"extract","archive":"`+name+`","dest":"restore","overwrite":true}`)
+			assert.FileExists(t, filepath.Join(dir, "restore", "docs", "a.txt"))
+		})
+	}
+}
+
+func TestArchiveZipSlip(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, name := range []string{"ok.txt", "../evil.txt"} {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		w.Write([]byte("payload"))
+	}
+	require.NoError(t, writer.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "slip.zip"), buf.Bytes(), 0644))
+
+	tool := tools.NewArchiveTool(config.ArchiveConfig{Roots: map[string]string{"data": dir}})
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"op":"extract","archive":"slip.zip","dest":"out"}`))
+	assert.True(t, errors.Is(err, platform.ErrOutsideRoot))
+	assert.NoFileExists(t, filepath.Join(dir, "evil.txt"))
+	// 失败时删除本次创建的文件与目录
+	assert.NoDirExists(t, filepath.Join(dir, "out"))
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"op":"list","archive":"../slip.zip"}`))
+	assert.True(t, errors.Is(err, platform.ErrOutsideRoot))
+}
+
+func TestArchiveQuota(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "big.txt"), string(bytes.Repeat([]byte("x"), 4096)))
+
+	tool := tools.NewArchiveTool(config.ArchiveConfig{Roots: map[string]string{"data": dir}})
+	runArchive(t, tool, `{"op":"create","archive":"big.zip","sources":["big.txt"]}`)
+
+	// 压缩后很小的归档按实际解压的字节数计算配额
+	small := tools.NewArchiveTool(config.ArchiveConfig{Roots: map[string]string{"data": dir}, MaxExtractBytes: 1024})
+	_, err := small.Execute(context.Background(), json.RawMessage(`{"op":"extract","archive":"big.zip","dest":"out"}`))
+	assert.True(t, errors.Is(err, tools.ErrArchiveQuota))
+	assert.NoFileExists(t, filepath.Join(dir, "out", "big.txt"))
+
+	_, err = small.Execute(context.Background(), json.RawMessage(`{"op":"create","archive":"again.zip","sources":["big.txt"]}`))
+	assert.True(t, errors.Is(err, tools.ErrArchiveQuota))
+	assert.NoFileExists(t, filepath.Join(dir, "again.zip"))
+}
+
+func TestArchiveRoots(t *testing.T) {
+	tool := tools.NewArchiveTool(config.ArchiveConfig{Roots: map[string]string{"a": t.TempDir(), "b": t.TempDir()}})
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"op":"list","archive":"x.zip"}`))
+	assert.ErrorContains(t, err, "root is required, available: a, b")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"op":"list","root":"c","archive":"x.zip"}`))
+	assert.ErrorContains(t, err, "unknown archive root")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"op":"list","root":"a","archive":"x.rar"}`))
+	assert.ErrorContains(t, err, "cannot infer archive format")
+}
@@ -0,0 +1,234 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := tools.NewBlobStore(config.BlobConfig{Dir: dir, TTL: 60, MaxBytes: 16, MaxTotalBytes: 24})
+	require.NoError(t, err)
+	defer store.Close()
+
+	info, err := store.Create("", "hello.txt", "text/plain", strings.NewReader("hello"), true)
+	require.NoError(t, err)
+	assert.True(t, info.Complete)
+	assert.Equal(t, tools.BlobURIPrefix+info.ID, info.URI)
+	assert.Equal(t, int64(5), info.Size)
+	data, _, err := store.ReadAll("", info.URI)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	// 只对上传者可见
+	_, _, err = store.ReadAll("team-a", info.URI)
+	assert.True(t, werrors.Is(err, werrors.KindNotFound))
+
+	// 分块上传
+	chunked, err := store.Create("team-a", "parts.bin", "", strings.NewReader("ab"), false)
+	require.NoError(t, err)
+	assert.Equal(t, "application/octet-stream", chunked.MimeType)
+	_, _, err = store.Open("team-a", chunked.URI)
+	assert.ErrorContains(t, err, "not complete")
+	_, err = store.Append("team-a", chunked.ID, 1, strings.NewReader("cd"), false)
+	assert.ErrorContains(t, err, "offset 1 does not match uploaded size 2")
+	_, err = store.Append("team-a", chunked.ID, 2, strings.NewReader("cd"), false)
+	require.NoError(t, err)
+	chunked, err = store.Append("team-a", chunked.ID, 4, strings.NewReader("ef"), true)
+	require.NoError(t, err)
+	sum := sha256.Sum256([]byte("abcdef"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), chunked.SHA256)
+	data, _, err = store.ReadAll("team-a", chunked.URI)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdef", string(data))
+	_, err = store.Append("team-a", chunked.ID, 6, strings.NewReader("g"), true)
+	assert.ErrorContains(t, err, "already complete")
+
+	// 超过单个文件与总大小上限的上传被丢弃
+	_, err = store.Create("", "big", "", strings.NewReader(strings.Repeat("x", 17)), true)
+	assert.True(t, errors.Is(err, tools.ErrBlobTooLarge))
+	_, err = store.Create("", "fill", "", strings.NewReader(strings.Repeat("x", 14)), true)
+	assert.True(t, errors.Is(err, tools.ErrBlobStoreFull))
+	assert.Equal(t, int64(11), store.Stats()["bytes"])
+	assert.Len(t, store.List(""), 1)
+
+	require.NoError(t, store.Delete("", info.ID))
+	assert.True(t, werrors.Is(store.Delete("", info.ID), werrors.KindNotFound))
+
+	// 过期后删除
+	store.SetClock(func() time.Time { return time.Now().Add(2 * time.Minute) })
+	_, _, err = store.Open("team-a", chunked.URI)
+	assert.True(t, werrors.Is(err, werrors.KindNotFound))
+	assert.Equal(t, 0, store.Stats()["blobs"])
+
+	require.NoError(t, store.Close())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestBlobToolArguments(t *testing.T) {
+	store, err := tools.NewBlobStore(config.BlobConfig{Dir: t.TempDir()})
+	require.NoError(t, err)
+	defer store.Close()
+	ctx := tools.WithBlobStore(context.Background(), store)
+
+	// qr 工具直接读取上传的图片
+	qr := tools.NewQRTool(config.QRConfig{})
+	out, err := qr.Execute(ctx, json.RawMessage(`{"op":"generate","data":"blob payload"}`))
+	require.NoError(t, err)
+	var generated tools.QRResult
+	require.NoError(t, json.Unmarshal(out, &generated))
+	image, err := base64.StdEncoding.DecodeString(generated.Data)
+	require.NoError(t, err)
+	info, err := store.Create("", "code.png", "image/png", bytes.NewReader(image), true)
+	require.NoError(t, err)
+	out, err = qr.Execute(ctx, json.RawMessage(`{"op":"decode","image":"`+info.URI+`"}`))
+	require.NoError(t, err)
+	var decoded tools.QRResult
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	require.Len(t, decoded.Codes, 1)
+	assert.Equal(t, "blob payload", decoded.Codes[0].Text)
+
+	// 其他租户的调用无法读取
+	other := tools.WithTenant(ctx, &tools.Tenant{Name: "team-b"})
+	_, err = qr.Execute(other, json.RawMessage(`{"op":"decode","image":"`+info.URI+`"}`))
+	assert.True(t, werrors.Is(err, werrors.KindNotFound))
+
+	// archive 工具列出并解压上传的归档，不能向 blob 写入
+	root := t.TempDir()
+	archive := tools.NewArchiveTool(config.ArchiveConfig{Roots: map[string]string{"data": root}})
+	upload, err := store.Create("", "bundle.zip", "application/zip", bytes.NewReader(testZip(t, map[string]string{"docs/readme.md": "# hi"})), true)
+	require.NoError(t, err)
+	out, err = archive.Execute(ctx, json.RawMessage(`{"op":"list","archive":"`+upload.URI+`"}`))
+	require.NoError(t, err)
+	var listed tools.ArchiveResult
+	require.NoError(t, json.Unmarshal(out, &listed))
+	assert.Equal(t, tools.ArchiveFormatZip, listed.Format)
+	require.Len(t, listed.Entries, 1)
+	assert.Equal(t, "docs/readme.md", listed.Entries[0].Name)
+
+	_, err = archive.Execute(ctx, json.RawMessage(`{"op":"extract","archive":"`+upload.URI+`","dest":"out"}`))
+	require.NoError(t, err)
+	content, err := os.ReadFile(root + "/out/docs/readme.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# hi", string(content))
+
+	_, err = archive.Execute(ctx, json.RawMessage(`{"op":"create","archive":"`+upload.URI+`","sources":["out"]}`))
+	assert.ErrorContains(t, err, "can only be listed or extracted")
+}
+
+func TestBlobUploadEndpoint(t *testing.T) {
+	url := newTestServer(t, func(cfg *config.Config) {
+		cfg.ToolConfig.Resources.Blobs = config.BlobConfig{Dir: t.TempDir(), MaxBytes: 1 << 20}
+	})
+	baseURL := strings.TrimSuffix(url, "/mcp")
+	send := func(method, path, contentType string, body []byte) *http.Response {
+		req, err := http.NewRequest(method, url+path, bytes.NewReader(body))
+		require.NoError(t, err)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	decodeBlob := func(resp *http.Response) tools.BlobInfo {
+		var info tools.BlobInfo
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+		return info
+	}
+
+	// 请求体为文件内容
+	resp := send(http.MethodPost, "/blobs?name=notes.txt", "text/plain; charset=utf-8", []byte("meeting notes"))
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	notes := decodeBlob(resp)
+	assert.Equal(t, "text/plain", notes.MimeType)
+	assert.Equal(t, "notes.txt", notes.Name)
+
+	reply := decodeReply(t, postMCP(t, baseURL, `{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"`+notes.URI+`"}}`, false))
+	require.Nil(t, reply.Error)
+	assert.Contains(t, string(reply.Result), `"text":"meeting notes"`)
+	reply = decodeReply(t, postMCP(t, baseURL, `{"jsonrpc":"2.0","id":2,"method":"resources/list"}`, false))
+	assert.Contains(t, string(reply.Result), notes.URI)
+
+	// multipart 上传后作为工具参数使用
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	require.NoError(t, writer.WriteField("comment", "ignored"))
+	part, err := writer.CreateFormFile("file", "bundle.zip")
+	require.NoError(t, err)
+	part.Write(testZip(t, map[string]string{"a.txt": "alpha", "b.txt": "beta"}))
+	require.NoError(t, writer.Close())
+	resp = send(http.MethodPost, "/blobs", writer.FormDataContentType(), form.Bytes())
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	bundle := decodeBlob(resp)
+	assert.Equal(t, "bundle.zip", bundle.Name)
+
+	reply = decodeReply(t, postMCP(t, baseURL, toolCall(3, "archive", `{"op":"list","archive":"`+bundle.URI+`"}`), false))
+	require.Nil(t, reply.Error)
+	assert.Contains(t, string(reply.Result), `a.txt`)
+	assert.Contains(t, string(reply.Result), `b.txt`)
+
+	// 分块上传
+	resp = send(http.MethodPost, "/blobs?chunked=true&name=log.txt", "text/plain", []byte("first,"))
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	log := decodeBlob(resp)
+	assert.False(t, log.Complete)
+	resp = send(http.MethodPatch, "/blobs/"+log.ID+"?offset=3", "", []byte("second"))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp = send(http.MethodPatch, "/blobs/"+log.ID+"?offset=6&final=true", "", []byte("second"))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	log = decodeBlob(resp)
+	assert.True(t, log.Complete)
+	assert.Equal(t, int64(12), log.Size)
+
+	// 超过大小上限
+	resp = send(http.MethodPost, "/blobs", "application/octet-stream", make([]byte, 1<<20+1))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	resp = send(http.MethodGet, "/blobs", "", nil)
+	var listed struct {
+		Blobs []tools.BlobInfo `json:"blobs"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&listed))
+	assert.Len(t, listed.Blobs, 3)
+
+	resp = send(http.MethodDelete, "/blobs/"+notes.ID, "", nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	reply = decodeReply(t, postMCP(t, baseURL, `{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"`+notes.URI+`"}}`, false))
+	require.NotNil(t, reply.Error)
+}
+
+// testZip 创建包含指定文件的 zip 归档
+func testZip(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		w.Write([]byte(content))
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,198 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/envelope"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyTool 按开关决定成功或失败的测试工具
+type flakyTool struct {
+	failing atomic.Bool
+	calls   atomic.Int32
+}
+
+func (ft *flakyTool) Name() string                 { return "flaky" }
+func (ft *flakyTool) Description() string          { return "fails while the switch is on" }
+func (ft *flakyTool) Category() tools.ToolCategory { return tools.CategoryUtility }
+
+func (ft *flakyTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	ft.calls.Add(1)
+	if ft.failing.Load() {
+		return nil, errors.New("upstream unavailable")
+	}
+	return json.RawMessage(`{"ok":true}`), nil
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	cfg := newTestToolConfig()
+	cfg.Global.CircuitBreaker = config.CircuitBreakerConfig{FailureThreshold: 2, Cooldown: 30}
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	tool := &flakyTool{}
+	require.NoError(t, tm.RegisterTool(tool))
+
+	now := time.Now()
+	tm.CircuitBreakers().SetClock(func() time.Time { return now })
+
+	// 连续失败达到阈值后熔断，熔断期间不再执行工具
+	tool.failing.Store(true)
+	for i := 0; i < 2; i++ {
+		_, err := tm.CallTool(context.Background(), "flaky", json.RawMessage(`{}`))
+		require.ErrorContains(t, err, "upstream unavailable")
+	}
+	_, err := tm.CallTool(context.Background(), "flaky", json.RawMessage(`{}`))
+	var circuitErr *tools.CircuitOpenError
+	require.True(t, errors.As(err, &circuitErr))
+	assert.True(t, errors.Is(err, tools.ErrCircuitOpen))
+	assert.Equal(t, "flaky", circuitErr.Name)
+	assert.Equal(t, 30*time.Second, circuitErr.RetryAfter)
+	assert.Equal(t, int32(2), tool.calls.Load())
+
+	stats := tm.CircuitBreakers().Stats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, tools.CircuitOpen, stats[0].State)
+	assert.Equal(t, int64(1), stats[0].Trips)
+
+	// 熔断时长过后放行一次试探调用，失败则重新熔断
+	now = now.Add(31 * time.Second)
+	_, err = tm.CallTool(context.Background(), "flaky", json.RawMessage(`{}`))
+	require.ErrorContains(t, err, "upstream unavailable")
+	_, err = tm.CallTool(context.Background(), "flaky", json.RawMessage(`{}`))
+	assert.True(t, errors.Is(err, tools.ErrCircuitOpen))
+	assert.Equal(t, int64(2), tm.CircuitBreakers().Stats()[0].Trips)
+
+	// 试探调用成功后恢复
+	now = now.Add(31 * time.Second)
+	tool.failing.Store(false)
+	_, err = tm.CallTool(context.Background(), "flaky", json.RawMessage(`{}`))
+	require.NoError(t, err)
+	stats = tm.CircuitBreakers().Stats()
+	assert.Equal(t, tools.CircuitClosed, stats[0].State)
+	assert.Zero(t, stats[0].Failures)
+	assert.Equal(t, int32(4), tool.calls.Load())
+}
+
+func TestCircuitBreakerUpstream(t *testing.T) {
+	var failing atomic.Int32
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failing.Add(1)
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(down.Close)
+	healthy := newFakeLLMServer(t)
+
+	cfg := newTestToolConfig()
+	cfg.Global.CircuitBreaker = config.CircuitBreakerConfig{FailureThreshold: 2, Cooldown: 30}
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	require.NoError(t, tm.RegisterTool(tools.NewLLMTool(config.LLMConfig{
+		Default: "openai",
+		Providers: map[string]config.LLMProviderConfig{
+			"openai": {Type: "openai", URL: down.URL + "/v1", APIKey: "openai-key", Model: "gpt-test"},
+			"ollama": {Type: "ollama", URL: healthy.URL, Model: "llama-test"},
+		},
+	})))
+
+	// 上游服务按名称单独熔断，熔断期间不再请求该服务
+	for i := 0; i < 3; i++ {
+		_, err := tm.CallTool(context.Background(), "llm", json.RawMessage(`{"prompt":"Hi"}`))
+		require.Error(t, err)
+	}
+	_, err := tm.CallTool(context.Background(), "llm", json.RawMessage(`{"prompt":"Hi"}`))
+	var circuitErr *tools.CircuitOpenError
+	require.True(t, errors.As(err, &circuitErr))
+	assert.Equal(t, "llm:openai", circuitErr.Name)
+	assert.Equal(t, int32(2), failing.Load())
+
+	// 其他服务与工具本身不受影响
+	_, err = tm.CallTool(context.Background(), "llm", json.RawMessage(`{"provider":"ollama","prompt":"Hi"}`))
+	require.NoError(t, err)
+	stats := tm.CircuitBreakers().Stats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, "llm:openai", stats[0].Name)
+	assert.Equal(t, tools.CircuitOpen, stats[0].State)
+}
+
+func TestCircuitBreakerHalfOpenTrial(t *testing.T) {
+	breakers := tools.NewCircuitBreakers(config.CircuitBreakerConfig{FailureThreshold: 1, Cooldown: 10})
+	now := time.Now()
+	breakers.SetClock(func() time.Time { return now })
+
+	// 调用方取消不计为失败
+	require.NoError(t, breakers.Allow("llm:openai"))
+	breakers.Record("llm:openai", context.Canceled)
+	assert.Empty(t, breakers.Stats())
+
+	require.NoError(t, breakers.Allow("llm:openai"))
+	breakers.Record("llm:openai", errors.New("503 Service Unavailable"))
+	assert.True(t, errors.Is(breakers.Allow("llm:openai"), tools.ErrCircuitOpen))
+	assert.NoError(t, breakers.Allow("llm:anthropic"))
+
+	// 半开状态同时只放行一次试探，放弃的试探不影响下一次
+	now = now.Add(11 * time.Second)
+	require.NoError(t, breakers.Allow("llm:openai"))
+	assert.True(t, errors.Is(breakers.Allow("llm:openai"), tools.ErrCircuitOpen))
+	breakers.Abandon("llm:openai")
+	require.NoError(t, breakers.Allow("llm:openai"))
+	breakers.Record("llm:openai", nil)
+	assert.NoError(t, breakers.Allow("llm:openai"))
+
+	// 阈值为 0 时不熔断
+	disabled := tools.NewCircuitBreakers(config.CircuitBreakerConfig{})
+	for i := 0; i < 10; i++ {
+		disabled.Record("flaky", errors.New("boom"))
+	}
+	assert.NoError(t, disabled.Allow("flaky"))
+}
+
+func TestCircuitBreakerErrorTextSanitized(t *testing.T) {
+	key, err := envelope.Generate()
+	require.NoError(t, err)
+
+	cfg := newTestToolConfig()
+	cfg.Global.CircuitBreaker = config.CircuitBreakerConfig{FailureThreshold: 5, Cooldown: 30}
+	cfg.Redaction.Patterns = []string{`tok_[a-z0-9]+`}
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	tm.SetArgumentDecrypter(key)
+	// 工具在错误中回显参数
+	echo := testkit.NewMockTool("echo").Handle(func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+		return nil, fmt.Errorf("bad input %s", args)
+	})
+	require.NoError(t, tm.RegisterTool(echo))
+
+	lastError := func() string {
+		for _, stats := range tm.CircuitBreakers().Stats() {
+			if stats.Name == "echo" {
+				return stats.LastError
+			}
+		}
+		return ""
+	}
+
+	// 明文参数的错误按脱敏规则处理
+	_, err = tm.CallTool(context.Background(), "echo", json.RawMessage(`{"token":"tok_abc123"}`))
+	require.ErrorContains(t, err, "tok_abc123")
+	assert.NotContains(t, lastError(), "tok_abc123")
+	assert.Contains(t, lastError(), "[REDACTED]")
+
+	// 加密参数的调用不保存错误详情
+	compact, err := envelope.Encrypt(key.PublicJWK(), []byte(`{"password":"hunter2"}`))
+	require.NoError(t, err)
+	_, err = tm.CallTool(context.Background(), "echo", envelope.Wrap(compact))
+	require.ErrorContains(t, err, "hunter2")
+	assert.NotContains(t, lastError(), "hunter2")
+	assert.Contains(t, lastError(), "details withheld")
+}
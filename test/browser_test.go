@@ -0,0 +1,97 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findChrome 查找本机的 Chrome，未安装时跳过需要真实浏览器的测试
+func findChrome(t *testing.T) string {
+	t.Helper()
+	for _, name := range []string{"google-chrome", "google-chrome-stable", "chromium", "chromium-browser", "headless-shell"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	t.Skip("chrome is not installed")
+	return ""
+}
+
+func TestBrowserValidation(t *testing.T) {
+	tool := tools.NewBrowserTool(config.BrowserConfig{ExecPath: "/nonexistent/chrome"}, tools.NewHTTPFetchTool(config.HTTPFetchConfig{}))
+	defer tool.Close()
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"http://127.0.0.1:8080/"}`))
+	assert.True(t, errors.Is(err, tools.ErrFetchBlocked))
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"url":"file:///etc/passwd"}`))
+	assert.True(t, errors.Is(err, tools.ErrFetchBlocked))
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"url":"http://127.0.0.1/","extract":"pdf"}`))
+	assert.ErrorContains(t, err, "unsupported extract")
+
+	// 浏览器无法启动时返回错误，下次调用重试
+	private := tools.NewBrowserTool(config.BrowserConfig{ExecPath: "/nonexistent/chrome"}, tools.NewHTTPFetchTool(config.HTTPFetchConfig{AllowPrivate: true}))
+	defer private.Close()
+	for i := 0; i < 2; i++ {
+		_, err = private.Execute(context.Background(), json.RawMessage(`{"url":"http://127.0.0.1:8080/"}`))
+		assert.ErrorContains(t, err, "failed to start browser")
+	}
+}
+
+func TestBrowserImageContent(t *testing.T) {
+	tool := tools.NewBrowserTool(config.BrowserConfig{}, tools.NewHTTPFetchTool(config.HTTPFetchConfig{}))
+
+	content, err := tool.ResultContent(json.RawMessage(`{"url":"https://example.com/","title":"Example","screenshot":"iVBORw0KGgo=","mime_type":"image/png"}`))
+	require.NoError(t, err)
+	require.Len(t, content, 2)
+	assert.Equal(t, "image", content[0].Type)
+	assert.Equal(t, "iVBORw0KGgo=", content[0].Data)
+	assert.Equal(t, "image/png", content[0].MimeType)
+	assert.NotContains(t, content[1].Text, "iVBORw0KGgo=")
+	assert.Contains(t, content[1].Text, `"title":"Example"`)
+
+	// 没有截图时按文本返回
+	content, err = tool.ResultContent(json.RawMessage(`{"url":"https://example.com/","title":"Example"}`))
+	require.NoError(t, err)
+	assert.Empty(t, content)
+}
+
+func TestBrowserRender(t *testing.T) {
+	chrome := findChrome(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>App</title></head><body><div id="app"></div>
+<script>setTimeout(() => { document.getElementById("app").innerHTML = '<p class="item">one</p><p class="item">two</p>'; }, 100);</script></body></html>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tool := tools.NewBrowserTool(config.BrowserConfig{ExecPath: chrome, NoSandbox: true}, tools.NewHTTPFetchTool(config.HTTPFetchConfig{AllowPrivate: true}))
+	defer tool.Close()
+
+	raw, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"`+server.URL+`/","wait_for":".item","selector":".item","screenshot":true}`))
+	require.NoError(t, err)
+	var result tools.BrowserResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	assert.Equal(t, "App", result.Title)
+	assert.Equal(t, []string{"one", "two"}, result.Content)
+	image, err := base64.StdEncoding.DecodeString(result.Screenshot)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(image, []byte("\x89PNG")))
+}
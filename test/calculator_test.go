@@ -1,145 +1,303 @@
-package test
-
-import (
-	"context"
-	"encoding/json"
-	"testing"
-
-	"Weave-Toolkit/internal/tools"
-
-	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/require"
-)
-
-func TestCalculatorTool(t *testing.T) {
-	calculator := &tools.CalculatorTool{}
-
-	tests := []struct {
-		name     string
-		args     tools.CalculatorArgs
-		expected float64
-		hasError bool
-	}{
-		{
-			name: "加法运算",
-			args: tools.CalculatorArgs{
-				Operation: "add",
-				A:         10,
-				B:         20,
-			},
-			expected: 30,
-		},
-		{
-			name: "减法运算",
-			args: tools.CalculatorArgs{
-				Operation: "subtract",
-				A:         50,
-				B:         30,
-			},
-			expected: 20,
-		},
-		{
-			name: "乘法运算",
-			args: tools.CalculatorArgs{
-				Operation: "multiply",
-				A:         5,
-				B:         6,
-			},
-			expected: 30,
-		},
-		{
-			name: "除法运算",
-			args: tools.CalculatorArgs{
-				Operation: "divide",
-				A:         100,
-				B:         4,
-			},
-			expected: 25,
-		},
-		{
-			name: "除零错误",
-			args: tools.CalculatorArgs{
-				Operation: "divide",
-				A:         10,
-				B:         0,
-			},
-			hasError: true,
-		},
-		{
-			name: "无效操作",
-			args: tools.CalculatorArgs{
-				Operation: "invalid",
-				A:         10,
-				B:         20,
-			},
-			hasError: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			argsJSON, err := json.Marshal(tt.args)
-			require.NoError(t, err)
-
-			result, err := calculator.Execute(context.Background(), argsJSON)
-
-			if tt.hasError {
-				assert.Error(t, err)
-				return
-			}
-
-			require.NoError(t, err)
-
-			var calcResult tools.CalculatorResult
-			err = json.Unmarshal(result, &calcResult)
-			require.NoError(t, err)
-
-			assert.Equal(t, tt.expected, calcResult.Result)
-		})
-	}
-}
-
-func TestCalculatorToolWithOperands(t *testing.T) {
-	calculator := &tools.CalculatorTool{}
-
-	tests := []struct {
-		name      string
-		operands  []float64
-		operation string
-		expected  float64
-	}{
-		{
-			name:      "使用操作数数组进行加法",
-			operands:  []float64{15, 25},
-			operation: "add",
-			expected:  40,
-		},
-		{
-			name:      "使用操作数数组进行乘法",
-			operands:  []float64{3, 7},
-			operation: "multiply",
-			expected:  21,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			args := tools.CalculatorArgs{
-				Operation: tt.operation,
-				Operands:  tt.operands,
-			}
-
-			argsJSON, err := json.Marshal(args)
-			require.NoError(t, err)
-
-			result, err := calculator.Execute(context.Background(), argsJSON)
-			require.NoError(t, err)
-
-			var calcResult tools.CalculatorResult
-			err = json.Unmarshal(result, &calcResult)
-			require.NoError(t, err)
-
-			assert.Equal(t, tt.expected, calcResult.Result)
-		})
-	}
-}
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"Weave-Toolkit/internal/expr"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculatorTool(t *testing.T) {
+	calculator := &tools.CalculatorTool{}
+
+	tests := []struct {
+		name     string
+		args     tools.CalculatorArgs
+		expected float64
+		hasError bool
+	}{
+		{
+			name: "加法运算",
+			args: tools.CalculatorArgs{
+				Operation: "add",
+				A:         10,
+				B:         20,
+			},
+			expected: 30,
+		},
+		{
+			name: "减法运算",
+			args: tools.CalculatorArgs{
+				Operation: "subtract",
+				A:         50,
+				B:         30,
+			},
+			expected: 20,
+		},
+		{
+			name: "乘法运算",
+			args: tools.CalculatorArgs{
+				Operation: "multiply",
+				A:         5,
+				B:         6,
+			},
+			expected: 30,
+		},
+		{
+			name: "除法运算",
+			args: tools.CalculatorArgs{
+				Operation: "divide",
+				A:         100,
+				B:         4,
+			},
+			expected: 25,
+		},
+		{
+			name: "除零错误",
+			args: tools.CalculatorArgs{
+				Operation: "divide",
+				A:         10,
+				B:         0,
+			},
+			hasError: true,
+		},
+		{
+			name: "无效操作",
+			args: tools.CalculatorArgs{
+				Operation: "invalid",
+				A:         10,
+				B:         20,
+			},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argsJSON, err := json.Marshal(tt.args)
+			require.NoError(t, err)
+
+			result, err := calculator.Execute(context.Background(), argsJSON)
+
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var calcResult tools.CalculatorResult
+			err = json.Unmarshal(result, &calcResult)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, calcResult.Result)
+		})
+	}
+}
+
+func TestCalculatorToolWithOperands(t *testing.T) {
+	calculator := &tools.CalculatorTool{}
+
+	tests := []struct {
+		name      string
+		operands  []float64
+		operation string
+		expected  float64
+	}{
+		{
+			name:      "使用操作数数组进行加法",
+			operands:  []float64{15, 25},
+			operation: "add",
+			expected:  40,
+		},
+		{
+			name:      "使用操作数数组进行乘法",
+			operands:  []float64{3, 7},
+			operation: "multiply",
+			expected:  21,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tools.CalculatorArgs{
+				Operation: tt.operation,
+				Operands:  tt.operands,
+			}
+
+			argsJSON, err := json.Marshal(args)
+			require.NoError(t, err)
+
+			result, err := calculator.Execute(context.Background(), argsJSON)
+			require.NoError(t, err)
+
+			var calcResult tools.CalculatorResult
+			err = json.Unmarshal(result, &calcResult)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, calcResult.Result)
+		})
+	}
+}
+
+func calculate(t *testing.T, args map[string]interface{}) (tools.CalculatorResult, error) {
+	t.Helper()
+	data, err := json.Marshal(args)
+	require.NoError(t, err)
+
+	var result tools.CalculatorResult
+	raw, err := (&tools.CalculatorTool{}).Execute(context.Background(), data)
+	if err != nil {
+		return result, err
+	}
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result, nil
+}
+
+func TestCalculatorNaryOperands(t *testing.T) {
+	result, err := calculate(t, map[string]interface{}{"operation": "subtract", "operands": []float64{100, 30, 20}})
+	require.NoError(t, err)
+	assert.Equal(t, float64(50), result.Result)
+
+	result, err = calculate(t, map[string]interface{}{"operation": "multiply", "operands": []float64{2, 3, 4, 5}})
+	require.NoError(t, err)
+	assert.Equal(t, float64(120), result.Result)
+
+	_, err = calculate(t, map[string]interface{}{"operation": "divide", "operands": []float64{8, 2, 0}})
+	assert.ErrorContains(t, err, "division by zero")
+}
+
+func TestCalculatorExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"-2^2", -4},
+		{"2^3^2", 512},
+		{"2 ** 10", 1024},
+		{"10 % 4 + 3!", 8},
+		{"sin(pi / 2) + ln(e)", 2},
+		{"pow(2, 0.5) ^ 2", 2.0000000000000004},
+		{"log(8, 2) + log10(1000)", 6},
+		{"max(1, 7, 3) - min(4, 2, 9)", 5},
+		{"avg(1, 2, 3, 4) * sum(1, 1)", 5},
+		{"round(3.14159, 2)", 3.14},
+		{"x * 2 + y", 11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			result, err := calculate(t, map[string]interface{}{
+				"operation":  "evaluate",
+				"expression": tt.expression,
+				"variables":  map[string]float64{"x": 4, "y": 3},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Result)
+		})
+	}
+
+	result, err := calculate(t, map[string]interface{}{"operation": "evaluate", "expression": "sin(30) + atan(1)", "angle": "deg"})
+	require.NoError(t, err)
+	assert.InDelta(t, 45.5, result.Result, 1e-12)
+
+	for _, expression := range []string{"1 +", "(1 + 2", "foo(1)", "sin()", "1 $ 2", "z + 1", "1 / 0", "171!", "ln(0)"} {
+		_, err := calculate(t, map[string]interface{}{"operation": "evaluate", "expression": expression})
+		assert.Error(t, err, expression)
+	}
+	_, err = calculate(t, map[string]interface{}{"operation": "evaluate", "expression": "pi", "variables": map[string]float64{"pi": 3}})
+	assert.Error(t, err)
+}
+
+func TestCalculatorDecimal(t *testing.T) {
+	result, err := calculate(t, map[string]interface{}{"operation": "add", "a": 0.1, "b": 0.2, "decimal": true})
+	require.NoError(t, err)
+	assert.Equal(t, "0.3", result.Value)
+	assert.Equal(t, 0.3, result.Result)
+
+	result, err = calculate(t, map[string]interface{}{"operation": "evaluate", "expression": "1 / 3", "decimal": true, "precision": 5})
+	require.NoError(t, err)
+	assert.Equal(t, "0.33333", result.Value)
+
+	result, err = calculate(t, map[string]interface{}{"operation": "evaluate", "expression": "25! + 0.5", "decimal": true})
+	require.NoError(t, err)
+	assert.Equal(t, "15511210043330985984000000.5", result.Value)
+
+	result, err = calculate(t, map[string]interface{}{"operation": "evaluate", "expression": "sqrt(2)", "decimal": true, "precision": 30})
+	require.NoError(t, err)
+	assert.Equal(t, "1.41421356237309504880168872421", result.Value)
+
+	result, err = calculate(t, map[string]interface{}{"operation": "add", "operands": []json.Number{"12345678901234567890.12", "0.01"}, "decimal": true})
+	require.NoError(t, err)
+	assert.Equal(t, "12345678901234567890.13", result.Value)
+
+	result, err = calculate(t, map[string]interface{}{
+		"operation":  "evaluate",
+		"expression": "price * qty - 0.01",
+		"variables":  map[string]json.Number{"price": "19999999999999999.99", "qty": "3"},
+		"decimal":    true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "59999999999999999.96", result.Value)
+
+	result, err = calculate(t, map[string]interface{}{"operation": "sqrt", "a": json.Number("1.21"), "decimal": true})
+	require.NoError(t, err)
+	assert.Equal(t, "1.1", result.Value)
+
+	_, err = calculate(t, map[string]interface{}{"operation": "evaluate", "expression": "sin(1)", "decimal": true})
+	assert.ErrorIs(t, err, expr.ErrUnsupportedDecimal)
+	_, err = calculate(t, map[string]interface{}{"operation": "evaluate", "expression": "10 ^ 100000", "decimal": true})
+	assert.Error(t, err)
+}
+
+func TestCalculatorReductions(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		expected float64
+	}{
+		{"sum", map[string]interface{}{"operation": "sum", "operands": []float64{1, 2, 3, 4, 5}}, 15},
+		{"单个操作数求和", map[string]interface{}{"operation": "sum", "operands": []float64{7}}, 7},
+		{"min", map[string]interface{}{"operation": "min", "operands": []float64{4, -2, 9}}, -2},
+		{"max", map[string]interface{}{"operation": "max", "a": 3, "b": 8}, 8},
+		{"mean", map[string]interface{}{"operation": "mean", "operands": []float64{1, 2, 3, 4}}, 2.5},
+		{"mod", map[string]interface{}{"operation": "mod", "operands": []float64{100, 7, 3}}, 2},
+		{"pow", map[string]interface{}{"operation": "pow", "a": 2, "b": 10}, 1024},
+		{"sqrt", map[string]interface{}{"operation": "sqrt", "a": 81}, 9},
+		{"abs", map[string]interface{}{"operation": "abs", "operands": []float64{-3.5}}, 3.5},
+		{"round", map[string]interface{}{"operation": "round", "a": 2.71828, "digits": 3}, 2.718},
+		{"round 默认取整", map[string]interface{}{"operation": "round", "a": -2.5}, -3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculate(t, tt.args)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Result)
+		})
+	}
+
+	result, err := calculate(t, map[string]interface{}{"operation": "mean", "operands": []float64{0.1, 0.2}, "decimal": true})
+	require.NoError(t, err)
+	assert.Equal(t, "0.15", result.Value)
+
+	_, err = calculate(t, map[string]interface{}{"operation": "add", "operands": []float64{1}})
+	assert.ErrorContains(t, err, "at least 2 operands")
+	_, err = calculate(t, map[string]interface{}{"operation": "pow", "operands": []float64{2, 3, 4}})
+	assert.ErrorContains(t, err, "exactly 2 operands")
+	_, err = calculate(t, map[string]interface{}{"operation": "sqrt", "operands": []float64{4, 9}})
+	assert.ErrorContains(t, err, "exactly 1 operand")
+	_, err = calculate(t, map[string]interface{}{"operation": "mod", "a": 5, "b": 0})
+	assert.ErrorContains(t, err, "division by zero")
+	_, err = calculate(t, map[string]interface{}{"operation": "sqrt", "a": -1})
+	assert.ErrorContains(t, err, "not a number")
+	_, err = calculate(t, map[string]interface{}{"operation": "multiply", "operands": []float64{1e300, 1e300}})
+	assert.ErrorContains(t, err, "exceeds the float64 range")
+	_, err = calculate(t, map[string]interface{}{"operation": "round", "a": 1, "digits": 16})
+	assert.ErrorContains(t, err, "digits")
+}
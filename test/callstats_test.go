@@ -0,0 +1,133 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toolCallStats 解析 CallStats 的 JSON 输出
+func toolCallStats(t *testing.T, stats interface{}) (byTool, byCategory map[string]tools.ToolCallStats) {
+	t.Helper()
+	data, err := json.Marshal(stats)
+	require.NoError(t, err)
+	var decoded struct {
+		Tools      []tools.ToolCallStats `json:"tools"`
+		Categories []tools.ToolCallStats `json:"categories"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	byTool = make(map[string]tools.ToolCallStats)
+	for _, item := range decoded.Tools {
+		byTool[item.Name] = item
+	}
+	byCategory = make(map[string]tools.ToolCallStats)
+	for _, item := range decoded.Categories {
+		byCategory[item.Name] = item
+	}
+	return byTool, byCategory
+}
+
+func TestCallStats(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	ok := testkit.NewMockTool("ok").Returns(map[string]bool{"ok": true})
+	failing := testkit.NewMockTool("failing").Fails(errors.New("boom"))
+	blocking := testkit.NewMockTool("blocking").Handle(func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+		close(started)
+		<-release
+		return json.RawMessage(`{}`), nil
+	})
+	tm := testkit.NewToolManager(t, ok, failing, blocking)
+
+	for i := 0; i < 3; i++ {
+		_, err := tm.CallTool(context.Background(), "ok", json.RawMessage(`{}`))
+		require.NoError(t, err)
+	}
+	_, err := tm.CallTool(context.Background(), "failing", json.RawMessage(`{}`))
+	require.Error(t, err)
+	_, err = tm.CallTool(context.Background(), "missing", json.RawMessage(`{}`))
+	require.Error(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = tm.CallTool(context.Background(), "blocking", json.RawMessage(`{}`))
+	}()
+	<-started
+
+	byTool, byCategory := toolCallStats(t, tm.CallStats())
+	assert.NotContains(t, byTool, "missing", "unknown tools are not tracked")
+
+	assert.Equal(t, int64(3), byTool["ok"].Calls)
+	assert.Zero(t, byTool["ok"].Errors)
+	assert.Equal(t, tools.CategoryUtility, byTool["ok"].Category)
+	assert.GreaterOrEqual(t, byTool["ok"].P99Ms, byTool["ok"].P50Ms)
+
+	assert.Equal(t, int64(1), byTool["failing"].Errors)
+	assert.Equal(t, "boom", byTool["failing"].LastError)
+	require.NotNil(t, byTool["failing"].LastErrorAt)
+	assert.WithinDuration(t, time.Now(), *byTool["failing"].LastErrorAt, time.Minute)
+
+	assert.Equal(t, int64(1), byTool["blocking"].Running)
+	assert.Zero(t, byTool["blocking"].Calls)
+
+	utility := byCategory[string(tools.CategoryUtility)]
+	assert.Equal(t, int64(4), utility.Calls)
+	assert.Equal(t, int64(1), utility.Errors)
+	assert.Equal(t, int64(1), utility.Running)
+
+	close(release)
+	<-done
+	byTool, _ = toolCallStats(t, tm.CallStats())
+	assert.Zero(t, byTool["blocking"].Running)
+	assert.Equal(t, int64(1), byTool["blocking"].Calls)
+}
+
+func TestCallStatsErrorRedacted(t *testing.T) {
+	cfg := newTestToolConfig()
+	cfg.Redaction.Patterns = []string{`tok_[a-z0-9]+`}
+	cfg.PII = config.PIIConfig{Enabled: true}
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	failing := testkit.NewMockTool("failing").Fails(errors.New("token tok_abc123 rejected for carol@example.com"))
+	require.NoError(t, tm.RegisterTool(failing))
+
+	_, err := tm.CallTool(context.Background(), "failing", json.RawMessage(`{}`))
+	require.Error(t, err)
+
+	// /health/stats 公开的最近错误已按脱敏规则处理并掩码个人信息
+	byTool, _ := toolCallStats(t, tm.CallStats())
+	lastError := byTool["failing"].LastError
+	assert.NotContains(t, lastError, "tok_abc123")
+	assert.NotContains(t, lastError, "carol@")
+	assert.Contains(t, lastError, "[REDACTED]")
+	assert.Contains(t, lastError, "c***@example.com")
+}
+
+func TestServerStatsToolCalls(t *testing.T) {
+	srv, url := newHandlerServer(t, nil, testkit.NewMockTool("ok").Returns("done"))
+	_, err := srv.CallTool("ok", map[string]interface{}{})
+	require.NoError(t, err)
+
+	resp, err := http.Get(url + "/health/stats")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		ToolCalls json.RawMessage `json:"tool_calls"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	byTool, _ := toolCallStats(t, body.ToolCalls)
+	assert.Equal(t, int64(1), byTool["ok"].Calls)
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCapabilities(t *testing.T) {
+	capabilities := tools.ClientCapabilities{
+		"sampling":     map[string]interface{}{},
+		"roots":        map[string]interface{}{"listChanged": false},
+		"experimental": map[string]interface{}{"streaming": true},
+		"elicitation":  nil,
+	}
+	assert.True(t, capabilities.Supports(tools.CapabilitySampling))
+	assert.True(t, capabilities.Supports(tools.CapabilityRoots))
+	assert.False(t, capabilities.Supports("roots.listChanged"))
+	assert.True(t, capabilities.Supports("experimental.streaming"))
+	assert.False(t, capabilities.Supports(tools.CapabilityElicitation))
+	assert.False(t, capabilities.Supports("sampling.tools"))
+	assert.False(t, tools.ClientSupports(context.Background(), tools.CapabilitySampling))
+
+	type seen struct {
+		Client   string `json:"client"`
+		Sampling bool   `json:"sampling"`
+	}
+	probe := testkit.NewMockTool("probe").Handle(func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+		return json.Marshal(seen{Client: tools.ClientFromContext(ctx), Sampling: tools.ClientSupports(ctx, tools.CapabilitySampling)})
+	})
+	srv := testkit.NewServer(t, nil, probe)
+	call := func() seen {
+		result, err := srv.CallTool("probe", map[string]string{})
+		require.NoError(t, err)
+		var s seen
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &s))
+		return s
+	}
+
+	// 不属于会话的调用没有客户端能力
+	assert.Equal(t, seen{Client: "unknown"}, call())
+
+	// 之后的请求不再携带 clientInfo，沿用 initialize 时声明的客户端与能力
+	resp, err := srv.Call(mcp.MethodInitialize, map[string]interface{}{
+		"protocolVersion": mcp.ProtocolVersion,
+		"capabilities":    map[string]interface{}{"sampling": map[string]interface{}{}},
+		"clientInfo":      map[string]interface{}{"name": "desktop", "version": "1.2.0"},
+	})
+	require.NoError(t, err)
+	sessionID := resp.Header.Get(mcp.SessionIDHeader)
+	require.NotEmpty(t, sessionID)
+	srv.SetHeader(mcp.SessionIDHeader, sessionID)
+	assert.Equal(t, seen{Client: "desktop", Sampling: true}, call())
+
+	// 未声明 sampling 的会话
+	_, err = srv.Initialize("cli")
+	require.NoError(t, err)
+	assert.Equal(t, seen{Client: "cli"}, call())
+}
@@ -0,0 +1,110 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pngMagic = []byte("\x89PNG\r\n\x1a\n")
+
+func renderChart(t *testing.T, tool *tools.ChartTool, args map[string]interface{}) (tools.ChartResult, []byte, error) {
+	t.Helper()
+	data, err := json.Marshal(args)
+	require.NoError(t, err)
+
+	var result tools.ChartResult
+	raw, err := tool.Execute(context.Background(), data)
+	if err != nil {
+		return result, nil, err
+	}
+	require.NoError(t, json.Unmarshal(raw, &result))
+	image, err := base64.StdEncoding.DecodeString(result.Data)
+	require.NoError(t, err)
+	return result, image, nil
+}
+
+func TestChartRender(t *testing.T) {
+	tool := tools.NewChartTool(config.ChartConfig{})
+
+	t.Run("line", func(t *testing.T) {
+		result, image, err := renderChart(t, tool, map[string]interface{}{
+			"type":   "line",
+			"title":  "Latency",
+			"series": []map[string]interface{}{{"name": "p50", "values": []float64{10, 12, 9}}, {"name": "p99", "values": []float64{40, 55, 38}}},
+			"labels": []string{"mon", "tue", "wed"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", result.MimeType)
+		assert.Equal(t, 6, result.Points)
+		assert.True(t, bytes.HasPrefix(image, pngMagic))
+	})
+
+	t.Run("bar svg", func(t *testing.T) {
+		result, image, err := renderChart(t, tool, map[string]interface{}{
+			"type":   "bar",
+			"series": []map[string]interface{}{{"values": []float64{3, 5, 2}}},
+			"labels": []string{"a", "b", "c"},
+			"format": "svg",
+			"width":  400,
+			"height": 300,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "image/svg+xml", result.MimeType)
+		assert.True(t, strings.HasPrefix(string(image), "<svg"))
+	})
+
+	t.Run("pie", func(t *testing.T) {
+		_, image, err := renderChart(t, tool, map[string]interface{}{
+			"type":   "pie",
+			"series": []map[string]interface{}{{"values": []float64{1, 2, 3}}},
+		})
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(image, pngMagic))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		series := []map[string]interface{}{{"values": []float64{1, 2}}}
+		_, _, err := renderChart(t, tool, map[string]interface{}{"type": "pie", "series": []map[string]interface{}{{"values": []float64{1, -1}}}})
+		assert.Error(t, err)
+		_, _, err = renderChart(t, tool, map[string]interface{}{"type": "bar", "series": series, "labels": []string{"a"}})
+		assert.Error(t, err)
+		_, _, err = renderChart(t, tool, map[string]interface{}{"type": "line", "series": []map[string]interface{}{{"values": []float64{1, 2}, "x": []float64{1}}}})
+		assert.Error(t, err)
+		_, _, err = renderChart(t, tool, map[string]interface{}{"type": "line", "series": series, "width": 5000})
+		assert.Error(t, err)
+
+		limited := tools.NewChartTool(config.ChartConfig{MaxPoints: 1})
+		_, _, err = renderChart(t, limited, map[string]interface{}{"type": "line", "series": series})
+		assert.ErrorContains(t, err, "too many data points")
+	})
+}
+
+func TestChartImageContent(t *testing.T) {
+	tm := tools.NewToolManager(newTestLogger(t), newTestToolConfig())
+	tm.RegisterAllTools()
+
+	result, err := tm.CallTool(context.Background(), "chart", json.RawMessage(`{"type":"bar","series":[{"values":[1,2,3]}]}`))
+	require.NoError(t, err)
+	require.Len(t, result.Content, 2)
+
+	assert.Equal(t, "image", result.Content[0].Type)
+	assert.Equal(t, "image/png", result.Content[0].MimeType)
+	image, err := base64.StdEncoding.DecodeString(result.Content[0].Data.(string))
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(image, pngMagic))
+
+	// 文本摘要不重复携带图片数据
+	assert.Equal(t, "text", result.Content[1].Type)
+	assert.NotContains(t, result.Content[1].Text, `"data"`)
+	assert.Contains(t, result.Content[1].Text, `"points":3`)
+}
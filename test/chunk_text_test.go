@@ -0,0 +1,95 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/internal/chunk"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const chunkSample = "Hello world. This is a test of the chunker.\n\nSecond paragraph here with more words and more words. Another sentence!\nNew line here."
+
+func chunkText(t *testing.T, args map[string]interface{}) tools.ChunkTextResult {
+	t.Helper()
+	raw, err := (&tools.ChunkTextTool{}).Execute(context.Background(), json.RawMessage(mustJSON(t, args)))
+	require.NoError(t, err)
+	var result tools.ChunkTextResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result
+}
+
+func chunkTexts(chunks []chunk.Chunk) []string {
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.Text
+	}
+	return texts
+}
+
+func TestChunkTextStrategies(t *testing.T) {
+	result := chunkText(t, map[string]interface{}{"text": chunkSample, "size": 40})
+	assert.Equal(t, chunk.StrategyParagraph, result.Strategy)
+	assert.Equal(t, []string{
+		"Hello world.",
+		"This is a test of the chunker.",
+		"Second paragraph here with more words",
+		"and more words.",
+		"Another sentence!\nNew line here.",
+	}, chunkTexts(result.Chunks))
+	for _, c := range result.Chunks {
+		assert.LessOrEqual(t, c.Length, 40)
+		assert.Equal(t, c.Text, string([]rune(chunkSample)[c.Start:c.End]))
+	}
+
+	// 整段不超过上限时保持完整
+	result = chunkText(t, map[string]interface{}{"text": chunkSample, "size": 80, "strategy": "paragraph"})
+	assert.Equal(t, "Hello world. This is a test of the chunker.", result.Chunks[0].Text)
+
+	result = chunkText(t, map[string]interface{}{"text": chunkSample, "size": 40, "strategy": "fixed", "overlap": 10})
+	assert.Equal(t, "Hello world. This is a test of the chunk", result.Chunks[0].Text)
+	assert.Equal(t, 31, result.Chunks[1].Start)
+
+	result = chunkText(t, map[string]interface{}{"text": chunkSample, "size": 10, "unit": "tokens", "strategy": "sentence", "overlap": 3})
+	assert.Equal(t, chunk.UnitTokens, result.Unit)
+	for _, c := range result.Chunks {
+		assert.LessOrEqual(t, c.Length, 10)
+	}
+	assert.Equal(t, "Hello world.", result.Chunks[0].Text)
+
+	result = chunkText(t, map[string]interface{}{"text": "这是第一句。这是第二句，比较长一点。第三句。", "size": 10})
+	assert.Equal(t, "这是第一句。", result.Chunks[0].Text)
+}
+
+func TestChunkTextLimits(t *testing.T) {
+	result := chunkText(t, map[string]interface{}{"text": strings.Repeat("word ", 2000), "size": 5, "strategy": "fixed"})
+	assert.True(t, result.Truncated)
+	assert.Equal(t, 2000, result.Count)
+	assert.Len(t, result.Chunks, 1000)
+
+	_, err := (&tools.ChunkTextTool{}).Execute(context.Background(), json.RawMessage(`{"text":"abc","size":5,"overlap":5}`))
+	assert.ErrorContains(t, err, "overlap")
+
+	assert.Equal(t, 10, chunk.EstimateTokens("Hello, world! 你好世界"))
+}
+
+func TestEstimateModelTokens(t *testing.T) {
+	// 与 tiktoken cl100k_base 的实际结果一致：Hello|,| world|! 与 9 个词加句号
+	assert.Equal(t, 4, chunk.EstimateModelTokens("Hello, world!", "gpt-4"))
+	assert.Equal(t, 10, chunk.EstimateModelTokens("The quick brown fox jumps over the lazy dog.", "gpt-4"))
+	// 数字每 3 位一组
+	assert.Equal(t, 3, chunk.EstimateModelTokens("1234567", "gpt-4o"))
+	// 词表越大，CJK 文本的 token 数越少
+	text := "上下文预算需要准确的计数"
+	assert.Less(t, chunk.EstimateModelTokens(text, "gpt-4o"), chunk.EstimateModelTokens(text, "gpt-4"))
+	assert.Less(t, chunk.EstimateModelTokens(text, "gpt-4"), chunk.EstimateModelTokens(text, "text-davinci-003"))
+	assert.Equal(t, 0, chunk.EstimateModelTokens("", "gpt-4o"))
+
+	assert.Equal(t, 3, chunk.CountSentences("First one. Second one!\n第三句。"))
+	assert.Equal(t, 0, chunk.CountSentences("  \n "))
+}
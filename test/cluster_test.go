@@ -0,0 +1,209 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/cluster"
+	"Weave-Toolkit/internal/jobs"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closedRedisURL 占用一个端口后立即关闭，确保连接被拒绝
+func closedRedisURL(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+	return "redis://" + addr + "/0"
+}
+
+func TestClusterRedisUnavailable(t *testing.T) {
+	url := closedRedisURL(t)
+
+	_, err := cluster.Connect(context.Background(), url, "")
+	assert.ErrorContains(t, err, "cluster redis unavailable")
+
+	_, err = cluster.Connect(context.Background(), "http://localhost", "")
+	assert.ErrorContains(t, err, "invalid cluster redis url")
+
+	// 集群模式下共享存储不可用时拒绝启动，而不是降级为单机模式
+	cfg := &config.Config{
+		LogLevel:        "error",
+		JobStoreDir:     t.TempDir(),
+		ToolConfig:      *newTestToolConfig(),
+		ClusterRedisURL: url,
+	}
+	_, err = mcp.NewServer(cfg, newTestLogger(t))
+	assert.ErrorContains(t, err, "failed to join cluster")
+}
+
+// fakeLimiter 记录调用的共享限流器
+type fakeLimiter struct {
+	mu      sync.Mutex
+	keys    []string
+	allowed bool
+	err     error
+}
+
+func (f *fakeLimiter) Allow(_ context.Context, key string, _ int) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys = append(f.keys, key)
+	return f.allowed, f.err
+}
+
+func TestSharedRateLimiter(t *testing.T) {
+	tm := tools.NewToolManager(newTestLogger(t), newTestToolConfig())
+	tm.RegisterAllTools()
+	tenant := &tools.Tenant{Name: "team-a", Config: config.TenantConfig{RateLimit: 100}}
+	ctx := tools.WithTenant(context.Background(), tenant)
+	args, _ := json.Marshal(tools.CalculatorArgs{Operation: "add", A: 1, B: 2})
+
+	limiter := &fakeLimiter{}
+	tm.SetRateLimiter(limiter)
+	_, err := tm.CallTool(ctx, "calculator", args)
+	assert.True(t, errors.Is(err, tools.ErrRateLimited))
+	assert.Equal(t, []string{"tenant:team-a"}, limiter.keys)
+
+	// 共享限流器不可用时退回本地限流
+	limiter.err = errors.New("connection refused")
+	_, err = tm.CallTool(ctx, "calculator", args)
+	assert.NoError(t, err)
+}
+
+// memoryJobStore 模拟其他副本写入的共享任务存储
+type memoryJobStore struct {
+	mu       sync.Mutex
+	jobs     map[string]jobs.Job
+	cancels  chan string
+	requests []string
+}
+
+func newMemoryJobStore() *memoryJobStore {
+	return &memoryJobStore{jobs: make(map[string]jobs.Job), cancels: make(chan string, 4)}
+}
+
+func (s *memoryJobStore) Save(_ context.Context, job jobs.Job, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+func (s *memoryJobStore) Load(_ context.Context, id string) (*jobs.Job, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, found := s.jobs[id]
+	return &job, found, nil
+}
+
+func (s *memoryJobStore) List(context.Context) ([]jobs.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]jobs.Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		list = append(list, job)
+	}
+	return list, nil
+}
+
+func (s *memoryJobStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+func (s *memoryJobStore) RequestCancel(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, id)
+	return nil
+}
+
+func (s *memoryJobStore) Cancellations(ctx context.Context) <-chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case id := <-s.cancels:
+				select {
+				case ch <- id:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch
+}
+
+func TestJobManagerSharedStore(t *testing.T) {
+	store := newMemoryJobStore()
+	runner := func(ctx context.Context, job jobs.Job) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	mgr := jobs.NewManager(jobs.Config{Workers: 1, Shared: store, Instance: "replica-a"}, runner, newTestLogger(t))
+	require.NoError(t, mgr.Start())
+	defer mgr.Shutdown(context.Background())
+
+	// 本副本提交的任务写入共享存储并记录执行副本
+	local, err := mgr.Submit("slow", nil, "client")
+	require.NoError(t, err)
+	assert.Equal(t, "replica-a", local.Instance)
+	saved, found, _ := store.Load(context.Background(), local.ID)
+	require.True(t, found)
+	assert.Equal(t, "replica-a", saved.Instance)
+
+	// 其他副本的任务可以查询、列出与请求取消
+	remote := jobs.Job{ID: "job_remote", Tool: "slow", Instance: "replica-b", Status: jobs.StatusRunning, CreatedAt: time.Now()}
+	store.Save(context.Background(), remote, 0)
+	got, err := mgr.Get("job_remote")
+	require.NoError(t, err)
+	assert.Equal(t, "replica-b", got.Instance)
+	assert.Len(t, mgr.List(), 2)
+
+	_, err = mgr.Cancel("job_remote")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"job_remote"}, store.requests)
+
+	// 订阅其他副本的任务时轮询共享存储，任务结束后通道关闭
+	updates, unsubscribe, err := mgr.Subscribe("job_remote")
+	require.NoError(t, err)
+	defer unsubscribe()
+	<-updates
+	remote.Status = jobs.StatusCancelled
+	store.Save(context.Background(), remote, 0)
+	timeout := time.After(5 * time.Second)
+	for closed := false; !closed; {
+		select {
+		case _, ok := <-updates:
+			closed = !ok
+		case <-timeout:
+			t.Fatal("subscription to remote job did not finish")
+		}
+	}
+
+	// 其他副本转发的取消请求作用于本副本执行的任务
+	store.cancels <- local.ID
+	job := waitJob(t, mgr, local.ID)
+	assert.Equal(t, jobs.StatusCancelled, job.Status)
+
+	_, err = mgr.Get("job_missing")
+	assert.ErrorContains(t, err, "job not found")
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runCodefmt(t *testing.T, tool *tools.CodefmtTool, args map[string]interface{}) tools.CodefmtResult {
+	t.Helper()
+	raw, err := json.Marshal(args)
+	require.NoError(t, err)
+	out, err := tool.Execute(context.Background(), raw)
+	require.NoError(t, err)
+	var result tools.CodefmtResult
+	require.NoError(t, json.Unmarshal(out, &result))
+	return result
+}
+
+func TestCodefmtGo(t *testing.T) {
+	tool := tools.NewCodefmtTool(config.CodefmtConfig{})
+
+	result := runCodefmt(t, tool, map[string]interface{}{"language": "go", "code": "package main\nfunc main(){\nx:=1\n_ = x}\n"})
+	assert.True(t, result.Changed)
+	assert.Equal(t, "package main\n\nfunc main() {\n\tx := 1\n\t_ = x\n}\n", result.Formatted)
+	assert.Empty(t, result.Diagnostics)
+
+	// 语句片段同样可以格式化
+	result = runCodefmt(t, tool, map[string]interface{}{"language": "go", "code": "if x{y()}"})
+	assert.Equal(t, "if x {\n\ty()\n}", result.Formatted)
+
+	result = runCodefmt(t, tool, map[string]interface{}{"language": "go", "code": "package main\n\nfunc main() {\n\tx := \n}\n"})
+	assert.False(t, result.Changed)
+	require.NotEmpty(t, result.Diagnostics)
+	assert.Equal(t, "gofmt", result.Diagnostics[0].Source)
+	assert.Equal(t, 5, result.Diagnostics[0].Line)
+	assert.Equal(t, tools.CodefmtSeverityError, result.Diagnostics[0].Severity)
+}
+
+func TestCodefmtExternalFormatter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the formatter")
+	}
+	dir := t.TempDir()
+	// 以脚本模拟 black：压缩多余空格，遇到 "def f(:" 时按 black 的格式报错
+	script := filepath.Join(dir, "black")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+input=$(cat)
+case "$input" in
+  *"def f(:"*) echo "error: cannot format -: Cannot parse: 1:6: def f(:" >&2; exit 123;;
+esac
+printf '%s\n' "$input" | sed 's/  */ /g'
+`), 0755))
+
+	tool := tools.NewCodefmtTool(config.CodefmtConfig{Black: script})
+	result := runCodefmt(t, tool, map[string]interface{}{"language": "python", "code": "x  =  1\n"})
+	assert.True(t, result.Changed)
+	assert.Equal(t, "x = 1\n", result.Formatted)
+
+	result = runCodefmt(t, tool, map[string]interface{}{"language": "python", "code": "def f(:\n"})
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, "black", result.Diagnostics[0].Source)
+	assert.Equal(t, 1, result.Diagnostics[0].Line)
+	assert.Equal(t, 6, result.Diagnostics[0].Column)
+	assert.Equal(t, "def f(:\n", result.Formatted)
+
+	missing := tools.NewCodefmtTool(config.CodefmtConfig{Prettier: filepath.Join(dir, "missing")})
+	_, err := missing.Execute(context.Background(), json.RawMessage(`{"language":"typescript","code":"let x=1"}`))
+	assert.ErrorContains(t, err, "prettier is not available")
+}
+
+func TestCodefmtLinters(t *testing.T) {
+	if _, err := exec.LookPath("grep"); err != nil {
+		t.Skip("grep is not installed")
+	}
+	tool := tools.NewCodefmtTool(config.CodefmtConfig{Linters: map[string]config.CodefmtLinterConfig{
+		"todo":  {Command: []string{"grep", "-Hn", "TODO", "{file}"}, Languages: []string{"go"}},
+		"stdin": {Command: []string{"grep", "-n", "panic"}, Languages: []string{"go"}, Pattern: `^(?P<line>\d+):(?P<message>.*)$`},
+		"other": {Command: []string{"grep", "-Hn", "x", "{file}"}, Languages: []string{"python"}},
+	}})
+
+	result := runCodefmt(t, tool, map[string]interface{}{
+		"language": "go",
+		"code":     "package main\nfunc main(){\n// TODO: remove\npanic(1)\n}\n",
+		"lint":     true,
+	})
+	require.Len(t, result.Diagnostics, 2)
+	// 按 linter 名称排序，诊断行号对应格式化后的代码
+	assert.Equal(t, "stdin", result.Diagnostics[0].Source)
+	assert.Equal(t, 5, result.Diagnostics[0].Line)
+	assert.Equal(t, "panic(1)", result.Diagnostics[0].Message)
+	assert.Equal(t, "todo", result.Diagnostics[1].Source)
+	assert.Equal(t, 4, result.Diagnostics[1].Line)
+	assert.Equal(t, "// TODO: remove", result.Diagnostics[1].Message)
+	assert.Equal(t, tools.CodefmtSeverityWarning, result.Diagnostics[1].Severity)
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"language":"css","code":"a{}","format":false,"lint":true}`))
+	assert.ErrorContains(t, err, "no linter configured for css")
+}
@@ -0,0 +1,120 @@
+package test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompressionRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.CompressionMiddleware([]string{middleware.EncodingZstd, middleware.EncodingGzip}, 64))
+
+	r.GET("/large", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": strings.Repeat("weave ", 100)})
+	})
+	r.GET("/small", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	r.GET("/stream", func(c *gin.Context) {
+		c.Writer.Header().Set("Content-Type", "text/event-stream")
+		c.Writer.WriteString("event: content\ndata: first\n\n")
+		c.Writer.Flush()
+		c.Writer.WriteString("event: done\ndata: last\n\n")
+		c.Writer.Flush()
+	})
+	return r
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	r := newCompressionRouter()
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("gzip 压缩大响应", func(t *testing.T) {
+		w := get("/large", "gzip")
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+		reader, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "weave weave")
+	})
+
+	t.Run("优先 zstd", func(t *testing.T) {
+		w := get("/large", "gzip, zstd")
+		assert.Equal(t, "zstd", w.Header().Get("Content-Encoding"))
+
+		decoder, err := zstd.NewReader(w.Body)
+		require.NoError(t, err)
+		defer decoder.Close()
+		body, err := io.ReadAll(decoder)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "weave weave")
+	})
+
+	t.Run("q=0 表示不接受", func(t *testing.T) {
+		w := get("/large", "zstd;q=0, gzip")
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("小响应不压缩", func(t *testing.T) {
+		w := get("/small", "gzip")
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.JSONEq(t, `{"ok":true}`, w.Body.String())
+	})
+
+	t.Run("未声明 Accept-Encoding", func(t *testing.T) {
+		w := get("/large", "")
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Contains(t, w.Body.String(), "weave weave")
+	})
+}
+
+func TestCompressionStreamsSSE(t *testing.T) {
+	srv := httptest.NewServer(newCompressionRouter())
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+
+	// 每次 Flush 后事件即可解压读取，无需等待响应结束
+	reader, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	lines := bufio.NewReader(reader)
+
+	line, err := lines.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: content\n", line)
+
+	rest, err := io.ReadAll(lines)
+	require.NoError(t, err)
+	assert.Contains(t, string(rest), "event: done")
+}
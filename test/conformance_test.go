@@ -0,0 +1,330 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+// conformanceTranscript 一段客户端与服务器的对话记录
+//
+// 记录按参考客户端（TypeScript SDK / MCP Inspector、Python SDK）实际发送的消息序列整理，
+// 依次发送每个请求并与期望的响应比较。期望值中的对象必须与响应的键完全一致，数组长度必须相同；
+// "<any>" 匹配任意非 null 值，"${name}" 引用此前通过 capture 保存的值，在请求与请求头中同样会被替换。
+type conformanceTranscript struct {
+	Description  string                `json:"description"`
+	ListPageSize int                   `json:"list_page_size"`
+	Exchanges    []conformanceExchange `json:"exchanges"`
+}
+
+// conformanceExchange 一次请求与期望的响应
+type conformanceExchange struct {
+	Name     string             `json:"name"`
+	Headers  map[string]string  `json:"headers"`
+	Request  json.RawMessage    `json:"request"`  // JSON 请求体
+	Raw      string             `json:"raw"`      // 原样发送的请求体，用于非法 JSON
+	Status   int                `json:"status"`   // 期望的 HTTP 状态码，默认 200
+	Response json.RawMessage    `json:"response"` // 期望的 JSON 响应，为空时要求响应体为空
+	Events   []conformanceEvent `json:"events"`   // 期望的 SSE 事件，设置时以 text/event-stream 请求
+	// Capture 保存响应中的值供后续请求引用："header:<名称>" 为响应头，其余为响应体中以点分隔的路径
+	Capture map[string]string `json:"capture"`
+}
+
+// conformanceEvent 期望的 SSE 事件
+type conformanceEvent struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+func TestMCPConformance(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "conformance", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		var transcript conformanceTranscript
+		require.NoError(t, json.Unmarshal(data, &transcript), file)
+
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".json"), func(t *testing.T) {
+			url := newTestServer(t, func(cfg *config.Config) {
+				cfg.ListPageSize = transcript.ListPageSize
+			})
+			vars := map[string]interface{}{}
+			for i, exchange := range transcript.Exchanges {
+				name := exchange.Name
+				if name == "" {
+					name = strconv.Itoa(i)
+				}
+				if !runExchange(t, url, exchange, vars) {
+					t.Fatalf("%s: exchange %q failed, later exchanges depend on it", transcript.Description, name)
+				}
+			}
+		})
+	}
+}
+
+// runExchange 发送一个请求并校验响应，返回是否通过
+func runExchange(t *testing.T, url string, exchange conformanceExchange, vars map[string]interface{}) bool {
+	t.Helper()
+	body := []byte(exchange.Raw)
+	if len(exchange.Request) > 0 {
+		var request interface{}
+		require.NoError(t, json.Unmarshal(exchange.Request, &request))
+		var err error
+		body, err = json.Marshal(substitute(request, vars))
+		require.NoError(t, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if exchange.Events != nil {
+		req.Header.Set("Accept", "application/json, text/event-stream")
+	}
+	for key, value := range exchange.Headers {
+		req.Header.Set(key, fmt.Sprint(substitute(value, vars)))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	status := exchange.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	ok := true
+	fail := func(format string, args ...interface{}) {
+		t.Errorf("%s: "+format, append([]interface{}{exchange.Name}, args...)...)
+		ok = false
+	}
+	if resp.StatusCode != status {
+		fail("status %d, expected %d (body %s)", resp.StatusCode, status, respBody)
+	}
+
+	var actual interface{}
+	switch {
+	case exchange.Events != nil:
+		if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+			fail("content type %q, expected text/event-stream", resp.Header.Get("Content-Type"))
+		}
+		events, err := parseEvents(respBody)
+		if err != nil {
+			fail("%v", err)
+			break
+		}
+		if len(events) != len(exchange.Events) {
+			fail("%d events, expected %d: %s", len(events), len(exchange.Events), respBody)
+			break
+		}
+		for i, event := range events {
+			if event.Event != exchange.Events[i].Event {
+				fail("event %d is %q, expected %q", i, event.Event, exchange.Events[i].Event)
+			}
+			if err := matchJSON(decodeJSON(t, exchange.Events[i].Data), event.Data, vars, fmt.Sprintf("events[%d]", i)); err != nil {
+				fail("%v", err)
+			}
+		}
+	case len(exchange.Response) == 0:
+		if len(bytes.TrimSpace(respBody)) != 0 {
+			fail("expected empty body, got %s", respBody)
+		}
+	default:
+		if err := json.Unmarshal(respBody, &actual); err != nil {
+			fail("invalid JSON response %s: %v", respBody, err)
+			break
+		}
+		if err := matchJSON(decodeJSON(t, exchange.Response), actual, vars, "response"); err != nil {
+			fail("%v\nactual: %s", err, respBody)
+		}
+	}
+
+	for name, path := range exchange.Capture {
+		var value interface{}
+		if header, isHeader := strings.CutPrefix(path, "header:"); isHeader {
+			value = resp.Header.Get(header)
+		} else {
+			value = lookupPath(actual, path)
+		}
+		if value == nil || value == "" {
+			fail("capture %s: %s is missing", name, path)
+		}
+		vars[name] = value
+	}
+	return ok
+}
+
+// parsedEvent 解析出的 SSE 事件
+type parsedEvent struct {
+	ID    int
+	Event string
+	Data  interface{}
+}
+
+// parseEvents 解析 SSE 响应，事件 ID 必须递增
+func parseEvents(body []byte) ([]parsedEvent, error) {
+	var events []parsedEvent
+	var current parsedEvent
+	lastID := 0
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if current.Event != "" {
+				events = append(events, current)
+			}
+			current = parsedEvent{}
+		case strings.HasPrefix(line, "id: "):
+			id, err := strconv.Atoi(strings.TrimPrefix(line, "id: "))
+			if err != nil || id <= lastID {
+				return nil, fmt.Errorf("event id %q is not increasing", line)
+			}
+			current.ID, lastID = id, id
+		case strings.HasPrefix(line, "event: "):
+			current.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &current.Data); err != nil {
+				return nil, fmt.Errorf("invalid event data %q: %v", line, err)
+			}
+		}
+	}
+	return events, scanner.Err()
+}
+
+// decodeJSON 解码期望值
+func decodeJSON(t *testing.T, data json.RawMessage) interface{} {
+	var value interface{}
+	require.NoError(t, json.Unmarshal(data, &value))
+	return value
+}
+
+// substitute 替换值中的 "${name}" 引用
+func substitute(value interface{}, vars map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if name, ok := variableName(v); ok {
+			if captured, exists := vars[name]; exists {
+				return captured
+			}
+		}
+		return v
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = substitute(item, vars)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = substitute(item, vars)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// variableName 解析 "${name}" 形式的引用
+func variableName(value string) (string, bool) {
+	if strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}") {
+		return value[2 : len(value)-1], true
+	}
+	return "", false
+}
+
+// matchJSON 按对话记录的规则比较期望值与实际值
+func matchJSON(expected, actual interface{}, vars map[string]interface{}, path string) error {
+	if s, ok := expected.(string); ok {
+		if s == "<any>" {
+			if actual == nil {
+				return fmt.Errorf("%s: expected a value, got null", path)
+			}
+			return nil
+		}
+		if name, ok := variableName(s); ok {
+			expected = vars[name]
+		}
+	}
+
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %v", path, actual)
+		}
+		if keys, actualKeys := sortedKeys(e), sortedKeys(a); !reflect.DeepEqual(keys, actualKeys) {
+			return fmt.Errorf("%s: keys %v, expected %v", path, actualKeys, keys)
+		}
+		for _, key := range sortedKeys(e) {
+			if err := matchJSON(e[key], a[key], vars, path+"."+key); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(e) {
+			return fmt.Errorf("%s: expected an array of %d items, got %v", path, len(e), actual)
+		}
+		for i := range e {
+			if err := matchJSON(e[i], a[i], vars, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("%s: got %v, expected %v", path, actual, expected)
+		}
+		return nil
+	}
+}
+
+// sortedKeys 对象的键，按字母排序
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lookupPath 按点分隔的路径取值，数字段为数组下标
+func lookupPath(value interface{}, path string) interface{} {
+	for _, part := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[part]
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil
+			}
+			value = v[index]
+		default:
+			return nil
+		}
+	}
+	return value
+}
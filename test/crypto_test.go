@@ -0,0 +1,126 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cryptoCall(t *testing.T, tool *tools.CryptoTool, args map[string]interface{}) (tools.CryptoResult, error) {
+	t.Helper()
+	data, err := json.Marshal(args)
+	require.NoError(t, err)
+
+	var result tools.CryptoResult
+	raw, err := tool.Execute(context.Background(), data)
+	if err != nil {
+		return result, err
+	}
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result, nil
+}
+
+func TestCryptoHMAC(t *testing.T) {
+	t.Setenv("TEST_HMAC_SECRET", "key")
+	tool := tools.NewCryptoTool(config.CryptoConfig{Keys: map[string]config.CryptoKeyConfig{
+		"github": {SecretEnv: "TEST_HMAC_SECRET"},
+		"hexkey": {Secret: "6b6579", Encoding: "hex"},
+	}})
+	message := "The quick brown fox jumps over the lazy dog"
+	const want = "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+
+	result, err := cryptoCall(t, tool, map[string]interface{}{"op": "hmac", "key": "key", "message": message})
+	require.NoError(t, err)
+	assert.Equal(t, want, result.Value)
+	assert.Equal(t, "sha256", result.Algorithm)
+
+	for _, name := range []string{"github", "hexkey"} {
+		result, err = cryptoCall(t, tool, map[string]interface{}{"op": "hmac", "key_name": name, "message": message})
+		require.NoError(t, err)
+		assert.Equal(t, want, result.Value, name)
+	}
+
+	result, err = cryptoCall(t, tool, map[string]interface{}{"op": "hmac", "key": "key", "message": message, "algorithm": "sha1", "encoding": "base64"})
+	require.NoError(t, err)
+	assert.Equal(t, "3nybhbi3iqa8ino29wqQcBydtNk=", result.Value)
+
+	// GitHub 风格的 sha256= 前缀
+	result, err = cryptoCall(t, tool, map[string]interface{}{"op": "verify", "key_name": "github", "message": message, "signature": "sha256=" + want})
+	require.NoError(t, err)
+	assert.True(t, *result.Valid)
+	result, err = cryptoCall(t, tool, map[string]interface{}{"op": "verify", "key_name": "github", "message": message + ".", "signature": want})
+	require.NoError(t, err)
+	assert.False(t, *result.Valid)
+
+	_, err = cryptoCall(t, tool, map[string]interface{}{"op": "hmac", "key_name": "missing", "message": message})
+	assert.ErrorContains(t, err, "unknown key")
+	_, err = cryptoCall(t, tool, map[string]interface{}{"op": "hmac", "message": message})
+	assert.Error(t, err)
+}
+
+func TestCryptoRandom(t *testing.T) {
+	tool := tools.NewCryptoTool(config.CryptoConfig{})
+
+	result, err := cryptoCall(t, tool, map[string]interface{}{"op": "random"})
+	require.NoError(t, err)
+	assert.Len(t, result.Value, 64)
+	assert.Equal(t, float64(256), result.EntropyBits)
+
+	other, err := cryptoCall(t, tool, map[string]interface{}{"op": "random"})
+	require.NoError(t, err)
+	assert.NotEqual(t, result.Value, other.Value)
+
+	result, err = cryptoCall(t, tool, map[string]interface{}{"op": "random", "length": 16, "encoding": "base64url"})
+	require.NoError(t, err)
+	assert.Len(t, result.Value, 22)
+
+	for i := 0; i < 20; i++ {
+		result, err = cryptoCall(t, tool, map[string]interface{}{
+			"op": "random", "kind": "password", "length": 12,
+			"symbols": false, "min_digits": 3, "min_uppercase": 2, "exclude_ambiguous": true,
+		})
+		require.NoError(t, err)
+		password := result.Value
+		require.Len(t, password, 12)
+		digits, upper := 0, 0
+		for _, r := range password {
+			assert.True(t, unicode.IsLetter(r) || unicode.IsDigit(r), "unexpected %q", r)
+			assert.False(t, strings.ContainsRune("Il1O0o", r), "ambiguous %q", r)
+			if unicode.IsDigit(r) {
+				digits++
+			}
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+		assert.GreaterOrEqual(t, digits, 3)
+		assert.GreaterOrEqual(t, upper, 2)
+	}
+
+	_, err = cryptoCall(t, tool, map[string]interface{}{"op": "random", "kind": "password", "length": 8, "min_digits": 9})
+	assert.Error(t, err)
+	_, err = cryptoCall(t, tool, map[string]interface{}{"op": "random", "kind": "password", "symbols": false, "min_symbols": 1})
+	assert.Error(t, err)
+	_, err = cryptoCall(t, tool, map[string]interface{}{"op": "random", "kind": "password", "length": 4})
+	assert.Error(t, err)
+}
+
+func TestCryptoCompare(t *testing.T) {
+	tool := tools.NewCryptoTool(config.CryptoConfig{})
+
+	result, err := cryptoCall(t, tool, map[string]interface{}{"op": "compare", "a": "secret", "b": "secret"})
+	require.NoError(t, err)
+	assert.True(t, *result.Valid)
+
+	result, err = cryptoCall(t, tool, map[string]interface{}{"op": "compare", "a": "secret", "b": "secret2"})
+	require.NoError(t, err)
+	assert.False(t, *result.Valid)
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"Weave-Toolkit/internal/envelope"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelopeEncryption(t *testing.T) {
+	key, err := envelope.Generate()
+	require.NoError(t, err)
+
+	compact, err := envelope.Encrypt(key.PublicJWK(), []byte(`{"secret":"s3cr3t"}`))
+	require.NoError(t, err)
+	assert.NotContains(t, compact, "s3cr3t")
+
+	plain, err := key.Decrypt(compact)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"secret":"s3cr3t"}`, string(plain))
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		parts := strings.Split(compact, ".")
+		parts[3] = strings.Repeat("A", len(parts[3]))
+		_, err := key.Decrypt(strings.Join(parts, "."))
+		assert.ErrorIs(t, err, envelope.ErrDecrypt)
+	})
+
+	t.Run("other recipient", func(t *testing.T) {
+		other, err := envelope.Generate()
+		require.NoError(t, err)
+		_, err = other.Decrypt(compact)
+		assert.ErrorIs(t, err, envelope.ErrDecrypt)
+	})
+
+	t.Run("wrap and unwrap", func(t *testing.T) {
+		got, ok := envelope.Unwrap(envelope.Wrap(compact))
+		require.True(t, ok)
+		assert.Equal(t, compact, got)
+
+		_, ok = envelope.Unwrap(json.RawMessage(`{"$jwe":"x","extra":1}`))
+		assert.False(t, ok)
+	})
+}
+
+func TestEnvelopeKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "encryption.pem")
+
+	created, err := envelope.LoadOrCreate(path)
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	loaded, err := envelope.LoadOrCreate(path)
+	require.NoError(t, err)
+	assert.Equal(t, created.KeyID(), loaded.KeyID())
+	assert.Equal(t, created.PublicJWK(), loaded.PublicJWK())
+}
+
+func TestEncryptedToolArguments(t *testing.T) {
+	key, err := envelope.Generate()
+	require.NoError(t, err)
+
+	tm := tools.NewToolManager(newTestLogger(t), newTestToolConfig())
+	tm.RegisterAllTools()
+
+	var mu sync.Mutex
+	var records []tools.CallRecord
+	tm.AddCallObserver(func(ctx context.Context, record tools.CallRecord) {
+		mu.Lock()
+		records = append(records, record)
+		mu.Unlock()
+	})
+
+	encrypt := func(args string) json.RawMessage {
+		compact, err := envelope.Encrypt(key.PublicJWK(), []byte(args))
+		require.NoError(t, err)
+		return envelope.Wrap(compact)
+	}
+
+	t.Run("disabled without key", func(t *testing.T) {
+		_, err := tm.CallTool(context.Background(), "calculator", encrypt(`{"operation":"add","a":1,"b":2}`))
+		assert.ErrorIs(t, err, tools.ErrEncryptionDisabled)
+	})
+
+	tm.SetArgumentDecrypter(key)
+
+	t.Run("decrypted before execution", func(t *testing.T) {
+		result, err := tm.CallTool(context.Background(), "calculator", encrypt(`{"operation":"add","a":1234,"b":4321}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"result":5555}`, result.Content[0].Text)
+
+		mu.Lock()
+		record := records[len(records)-1]
+		mu.Unlock()
+		assert.True(t, record.Encrypted)
+		assert.NotContains(t, string(record.Arguments), "1234")
+	})
+
+	t.Run("schema validated after decryption", func(t *testing.T) {
+		_, err := tm.CallTool(context.Background(), "calculator", encrypt(`{"operation":"cube"}`))
+		assert.ErrorIs(t, err, tools.ErrInvalidArguments)
+	})
+
+	t.Run("errors withheld from records", func(t *testing.T) {
+		_, err := tm.CallTool(context.Background(), "calculator", encrypt(`{"operation":"divide","a":7,"b":0}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "division by zero") // 调用方仍可得到错误详情
+
+		mu.Lock()
+		record := records[len(records)-1]
+		mu.Unlock()
+		assert.Equal(t, tools.CallStatusError, record.Status)
+		assert.NotContains(t, record.Error, "division")
+	})
+}
@@ -0,0 +1,101 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"Weave-Toolkit/internal/envelope"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestErrorKinds(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		kind werrors.Kind
+	}{
+		{"工具不存在", fmt.Errorf("%w: calc", tools.ErrToolNotFound), werrors.KindNotFound},
+		{"参数错误", fmt.Errorf("%w: missing a", tools.ErrInvalidArguments), werrors.KindInvalidParams},
+		{"解密失败", envelope.ErrDecrypt, werrors.KindInvalidParams},
+		{"租户限额", fmt.Errorf("%w: tenant a", tools.ErrRateLimited), werrors.KindRateLimited},
+		{"熔断", &tools.CircuitOpenError{Name: "calc"}, werrors.KindUnavailable},
+		{"工具超时", &tools.TimeoutError{Tool: "calc"}, werrors.KindTimeout},
+		{"截止时间", fmt.Errorf("fetch: %w", context.DeadlineExceeded), werrors.KindTimeout},
+		{"地址被拒绝", fmt.Errorf("%w: 10.0.0.1", tools.ErrFetchBlocked), werrors.KindUnauthorized},
+		{"上游失败", werrors.UpstreamFailure("provider returned %d", 500), werrors.KindUpstreamFailure},
+		{"最外层分类优先", werrors.Wrap(werrors.KindInvalidParams, tools.ErrToolNotFound), werrors.KindInvalidParams},
+		{"未分类", errors.New("boom"), werrors.KindInternal},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.kind, werrors.KindOf(tc.err))
+			assert.True(t, werrors.Is(tc.err, tc.kind))
+		})
+	}
+
+	// 分类不改变错误文本与哨兵错误的匹配
+	err := werrors.NotFound("%w: %s", tools.ErrToolNotFound, "calc")
+	assert.EqualError(t, err, "tool not found: calc")
+	assert.ErrorIs(t, err, tools.ErrToolNotFound)
+	assert.Nil(t, werrors.Wrap(werrors.KindTimeout, nil))
+	assert.False(t, werrors.Is(nil, werrors.KindInternal))
+}
+
+func TestErrorKindMapping(t *testing.T) {
+	cases := []struct {
+		kind   werrors.Kind
+		rpc    int
+		status int
+		grpc   codes.Code
+	}{
+		{werrors.KindNotFound, -32602, http.StatusNotFound, codes.NotFound},
+		{werrors.KindInvalidParams, -32602, http.StatusBadRequest, codes.InvalidArgument},
+		{werrors.KindTimeout, -32003, http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{werrors.KindRateLimited, -32004, http.StatusTooManyRequests, codes.ResourceExhausted},
+		{werrors.KindUnauthorized, -32005, http.StatusForbidden, codes.PermissionDenied},
+		{werrors.KindUnavailable, -32002, http.StatusServiceUnavailable, codes.Unavailable},
+		{werrors.KindUpstreamFailure, -32006, http.StatusBadGateway, codes.Unavailable},
+		{werrors.KindInternal, -32603, http.StatusInternalServerError, codes.Unknown},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.kind), func(t *testing.T) {
+			assert.Equal(t, tc.rpc, tc.kind.RPCCode())
+			assert.Equal(t, tc.status, tc.kind.HTTPStatus())
+			assert.Equal(t, tc.grpc, tc.kind.GRPCCode())
+		})
+	}
+}
+
+func TestErrorKindRPCResponse(t *testing.T) {
+	limited := testkit.NewMockTool("quota").Fails(werrors.RateLimited("quota exhausted for %s", "team-a"))
+	upstream := testkit.NewMockTool("upstream").Fails(werrors.UpstreamFailure("provider returned %d", 502))
+	srv := testkit.NewServer(t, nil, limited, upstream)
+
+	var records []tools.CallRecord
+	srv.ToolManager().AddCallObserver(func(_ context.Context, record tools.CallRecord) {
+		records = append(records, record)
+	})
+
+	_, err := srv.CallTool("quota", map[string]interface{}{})
+	var rpcErr *testkit.RPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, -32004, rpcErr.Code)
+	assert.Equal(t, "quota exhausted for team-a", rpcErr.Message)
+
+	_, err = srv.CallTool("upstream", map[string]interface{}{})
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, -32006, rpcErr.Code)
+
+	require.Len(t, records, 2)
+	assert.Equal(t, werrors.KindRateLimited, records[0].ErrorKind)
+	assert.Equal(t, werrors.KindUpstreamFailure, records[1].ErrorKind)
+}
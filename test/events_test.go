@@ -0,0 +1,137 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBuffer(t *testing.T) {
+	t.Run("按游标读取事件", func(t *testing.T) {
+		buf := mcp.NewEventBuffer(10, time.Minute)
+		id := buf.Open("team-a/session_1")
+
+		for i := 0; i < 3; i++ {
+			_, err := buf.Append(id, mcp.StreamEventContent, map[string]int{"index": i})
+			require.NoError(t, err)
+		}
+
+		events, done, truncated, err := buf.Since(id, 1)
+		require.NoError(t, err)
+		assert.False(t, done)
+		assert.False(t, truncated)
+		require.Len(t, events, 2)
+		assert.Equal(t, int64(2), events[0].ID)
+		assert.JSONEq(t, `{"index":1}`, string(events[0].Data))
+	})
+
+	t.Run("超出容量时淘汰最早事件", func(t *testing.T) {
+		buf := mcp.NewEventBuffer(2, time.Minute)
+		id := buf.Open("team-a/session_1")
+
+		for i := 0; i < 5; i++ {
+			_, err := buf.Append(id, mcp.StreamEventContent, i)
+			require.NoError(t, err)
+		}
+
+		events, _, truncated, err := buf.Since(id, 0)
+		require.NoError(t, err)
+		assert.True(t, truncated)
+		require.Len(t, events, 2)
+		assert.Equal(t, int64(4), events[0].ID)
+	})
+
+	t.Run("长轮询等待新事件", func(t *testing.T) {
+		buf := mcp.NewEventBuffer(10, time.Minute)
+		id := buf.Open("team-a/session_1")
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			buf.Append(id, mcp.StreamEventDone, "ok")
+			buf.Close(id)
+		}()
+
+		events, _, _, err := buf.Wait(context.Background(), id, "team-a/session_1", 0, 5*time.Second)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, mcp.StreamEventDone, events[0].Event)
+
+		events, done, _, err := buf.Wait(context.Background(), id, "team-a/session_1", 1, 5*time.Second)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+		assert.True(t, done)
+	})
+
+	t.Run("长轮询超时返回空结果", func(t *testing.T) {
+		buf := mcp.NewEventBuffer(10, time.Minute)
+		id := buf.Open("team-a/session_1")
+
+		events, done, _, err := buf.Wait(context.Background(), id, "team-a/session_1", 0, 20*time.Millisecond)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+		assert.False(t, done)
+	})
+
+	t.Run("其他持有者无法拉取事件", func(t *testing.T) {
+		buf := mcp.NewEventBuffer(10, time.Minute)
+		id := buf.Open("team-a/session_1")
+		assert.Regexp(t, `^stream_[0-9a-f]{32}$`, id)
+		_, err := buf.Append(id, mcp.StreamEventContent, "secret")
+		require.NoError(t, err)
+
+		for _, owner := range []string{"team-b/session_1", "team-a/session_2", "team-a/", ""} {
+			_, _, _, err = buf.Wait(context.Background(), id, owner, 0, 10*time.Millisecond)
+			assert.ErrorContains(t, err, "stream not found", owner)
+		}
+	})
+
+	t.Run("未知事件流", func(t *testing.T) {
+		buf := mcp.NewEventBuffer(10, time.Minute)
+		_, err := buf.Append("missing", mcp.StreamEventContent, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestLongPollStreamOwner(t *testing.T) {
+	srv := testkit.NewServer(t, nil, testkit.NewMockTool("echo").Returns("ok"))
+	resp, err := srv.Initialize("poll-client")
+	require.NoError(t, err)
+	sessionID := resp.Header.Get(mcp.SessionIDHeader)
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{}}}`
+	req := httptest.NewRequest(http.MethodPost, "/mcp/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(mcp.SessionIDHeader, sessionID)
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	streamID := rec.Header().Get(mcp.StreamIDHeader)
+	require.NotEmpty(t, streamID)
+
+	poll := func(session string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/mcp/events?stream="+streamID+"&wait=2s", nil)
+		if session != "" {
+			req.Header.Set(mcp.SessionIDHeader, session)
+		}
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 只知道流ID而不属于同一会话的请求拉取不到事件
+	assert.Equal(t, http.StatusNotFound, poll("").Code)
+	assert.Equal(t, http.StatusNotFound, poll("session_other").Code)
+
+	rec = poll(sessionID)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"streamId":"`+streamID+`"`)
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"Weave-Toolkit/internal/schema"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixturesDir make fixtures 生成的示例参数目录
+var fixturesDir = filepath.Join("testdata", "fixtures")
+
+// TestToolArgumentFixtures 对每个内置工具执行表驱动的参数校验测试
+func TestToolArgumentFixtures(t *testing.T) {
+	cfg := newTestToolConfig()
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	tm.RegisterAllTools()
+
+	for _, tool := range tools.BuiltinTools(cfg) {
+		schemaTool, ok := tool.(tools.SchemaTool)
+		if !ok {
+			continue
+		}
+
+		t.Run(tool.Name(), func(t *testing.T) {
+			generated, err := schema.GenerateFixtures(tool.Name(), schemaTool.InputSchema())
+			require.NoError(t, err)
+			want, err := generated.Marshal()
+			require.NoError(t, err)
+
+			data, err := os.ReadFile(filepath.Join(fixturesDir, schema.FixtureFile(tool.Name())))
+			require.NoError(t, err, "fixtures missing, run make fixtures")
+			require.JSONEq(t, string(want), string(data), "fixtures are stale, run make fixtures")
+
+			var fixtures schema.Fixtures
+			require.NoError(t, json.Unmarshal(data, &fixtures))
+			require.NotEmpty(t, fixtures.Valid)
+			require.NotEmpty(t, fixtures.Invalid)
+
+			for _, fixture := range fixtures.Valid {
+				t.Run("valid/"+fixture.Name, func(t *testing.T) {
+					assert.NoError(t, tm.ValidateArguments(tool.Name(), fixture.Arguments))
+				})
+			}
+			for _, fixture := range fixtures.Invalid {
+				t.Run("invalid/"+fixture.Name, func(t *testing.T) {
+					assert.ErrorIs(t, tm.ValidateArguments(tool.Name(), fixture.Arguments), tools.ErrInvalidArguments)
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+)
+
+// fuzzCallTimeout 单次模糊测试调用的时限，避免复杂表达式拖慢测试
+const fuzzCallTimeout = time.Second
+
+// FuzzStreamTextArguments 文本处理工具的参数解析与执行不因任意输入 panic
+func FuzzStreamTextArguments(f *testing.F) {
+	for _, seed := range []string{
+		`{"text":"hello world","operation":"split"}`,
+		`{"text":"héllo","operation":"reverse"}`,
+		`{"text":"a b c"}`,
+		`{"text":123,"operation":["count"]}`,
+		`{"text":"","operation":"analyze"}`,
+		`null`,
+		`[]`,
+		`{"text":"\u0000\ud800"}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	tool := &tools.StreamTextProcessor{}
+	f.Fuzz(func(t *testing.T, args []byte) {
+		ctx, cancel := context.WithTimeout(context.Background(), fuzzCallTimeout)
+		defer cancel()
+
+		result, err := tool.Execute(ctx, args)
+		if err == nil && !json.Valid(result) {
+			t.Fatalf("Execute returned invalid JSON for %q: %s", args, result)
+		}
+		result, err = tool.ExecuteStream(ctx, args, func(content string, index int) {})
+		if err == nil && !json.Valid(result) {
+			t.Fatalf("ExecuteStream returned invalid JSON for %q: %s", args, result)
+		}
+	})
+}
+
+// FuzzCalculatorExecute 计算器对任意参数返回结果或错误而不 panic
+func FuzzCalculatorExecute(f *testing.F) {
+	for _, seed := range []string{
+		`{"operation":"add","a":1,"b":2}`,
+		`{"operation":"divide","a":1,"b":0}`,
+		`{"operation":"multiply","operands":[2,3,7]}`,
+		`{"operation":"evaluate","expression":"sin(pi/2) + x^2","variables":{"x":3}}`,
+		`{"operation":"evaluate","expression":"1/3","decimal":true,"precision":50}`,
+		`{"operation":"evaluate","expression":"((((1))))","angle":"deg"}`,
+		`{"operation":"evaluate","expression":"2^2^2^2^2"}`,
+		`{"operation":"subtract","a":1e308,"b":-1e308}`,
+		`{"operation":"evaluate","expression":"10!","decimal":true,"precision":-1}`,
+		`{}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	tool := &tools.CalculatorTool{}
+	f.Fuzz(func(t *testing.T, args []byte) {
+		ctx, cancel := context.WithTimeout(context.Background(), fuzzCallTimeout)
+		defer cancel()
+
+		result, err := tool.Execute(ctx, args)
+		if err == nil && !json.Valid(result) {
+			t.Fatalf("Execute returned invalid JSON for %q: %s", args, result)
+		}
+	})
+}
+
+// FuzzJSONRPCRequest /mcp 对任意请求体返回结构化的 JSON-RPC 响应，不出现内部错误
+func FuzzJSONRPCRequest(f *testing.F) {
+	for _, seed := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"fuzz","version":"1"}}}`,
+		`{"jsonrpc":"2.0","id":"a","method":"tools/list","params":{"cursor":"eyJvIjoxfQ"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"calculator","arguments":{"operation":"add","a":1,"b":2}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"stream_text_processor","arguments":{"text":"hi"},"async":true}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"result://missing"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"prompts/get","params":{"name":"x","arguments":[]}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"jobs/get","params":{"jobId":7}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":null,"method":"ping"}`,
+		`[{"jsonrpc":"2.0","id":1,"method":"ping"}]`,
+		`{"jsonrpc":"2.0","id":1e400,"method":"ping"}`,
+		`{`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	srv := testkit.NewServer(f, nil, &tools.CalculatorTool{}, &tools.StreamTextProcessor{})
+	handler := srv.Handler()
+	f.Fuzz(func(t *testing.T, body []byte) {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code == http.StatusInternalServerError {
+			t.Fatalf("internal error for %q: %s", body, rec.Body.String())
+		}
+		if rec.Code == http.StatusAccepted && rec.Body.Len() == 0 {
+			return
+		}
+
+		var reply map[string]json.RawMessage
+		if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+			t.Fatalf("response for %q is not a JSON object: %s", body, rec.Body.String())
+		}
+		if string(reply["jsonrpc"]) != `"2.0"` {
+			t.Fatalf("response for %q is not JSON-RPC 2.0: %s", body, rec.Body.String())
+		}
+		_, hasResult := reply["result"]
+		_, hasError := reply["error"]
+		if hasResult == hasError {
+			t.Fatalf("response for %q must have exactly one of result and error: %s", body, rec.Body.String())
+		}
+	})
+}
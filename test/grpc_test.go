@@ -0,0 +1,132 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/pb/weavev1"
+	"Weave-Toolkit/testkit"
+)
+
+// newGRPCClient 在内存监听上提供服务器的 gRPC 服务并返回客户端
+func newGRPCClient(t *testing.T, srv *testkit.Server) weavev1.ToolServiceClient {
+	grpcSrv := srv.GRPCServer()
+	require.NotNil(t, grpcSrv)
+	listener := bufconn.Listen(1 << 20)
+	go grpcSrv.Serve(listener)
+	t.Cleanup(grpcSrv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return weavev1.NewToolServiceClient(conn)
+}
+
+func TestGRPCToolService(t *testing.T) {
+	echo := testkit.NewMockTool("echo").Handle(func(_ context.Context, args json.RawMessage) (json.RawMessage, error) {
+		return args, nil
+	})
+	streamer := testkit.NewMockTool("streamer").Streams(time.Millisecond, "first ", "second").Returns("first second")
+	slow := testkit.NewMockTool("slow").Delays(time.Second).Returns("late")
+	srv := testkit.NewServer(t, func(cfg *config.Config) {
+		cfg.APIKey = "secret"
+		cfg.GRPCAddress = "127.0.0.1:0"
+		cfg.ToolTimeout = 100 * time.Millisecond
+		cfg.ToolConfig.Policies = config.PolicyConfig{Rules: []config.PolicyRule{
+			{Name: "no-drop", Target: "arguments", Keywords: []string{"DROP TABLE"}},
+		}}
+	}, echo, streamer, slow)
+	client := newGRPCClient(t, srv)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret")
+	arguments := func(values map[string]interface{}) *structpb.Struct {
+		s, err := structpb.NewStruct(values)
+		require.NoError(t, err)
+		return s
+	}
+
+	// 未携带或携带错误的 API Key 时拒绝
+	_, err := client.ListTools(context.Background(), &weavev1.ListToolsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	wrong := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong")
+	_, err = client.CallTool(wrong, &weavev1.CallToolRequest{Name: "echo"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	stream, err := client.CallToolStream(context.Background(), &weavev1.CallToolRequest{Name: "streamer"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Zero(t, streamer.CallCount())
+
+	// 列出工具，按名称排序
+	list, err := client.ListTools(ctx, &weavev1.ListToolsRequest{})
+	require.NoError(t, err)
+	var names []string
+	for _, tool := range list.GetTools() {
+		names = append(names, tool.GetName())
+	}
+	assert.Equal(t, []string{"echo", "slow", "streamer"}, names)
+
+	// 单次调用
+	resp, err := client.CallTool(ctx, &weavev1.CallToolRequest{Name: "echo", Arguments: arguments(map[string]interface{}{"text": "hello"})})
+	require.NoError(t, err)
+	assert.False(t, resp.GetIsError())
+	require.Len(t, resp.GetContent(), 1)
+	assert.JSONEq(t, `{"text":"hello"}`, resp.GetContent()[0].GetText())
+
+	// 流式调用先推送片段，最后推送结果消息
+	stream, err = client.CallToolStream(ctx, &weavev1.CallToolRequest{Name: "streamer", Arguments: arguments(nil)})
+	require.NoError(t, err)
+	var chunks []string
+	var result *weavev1.CallToolResponse
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		require.Nil(t, result, "no messages after the result")
+		if chunk := msg.GetChunk(); chunk != nil {
+			assert.Equal(t, int32(len(chunks)), chunk.GetIndex())
+			chunks = append(chunks, chunk.GetContent())
+		} else {
+			result = msg.GetResult()
+		}
+	}
+	assert.Equal(t, []string{"first ", "second"}, chunks)
+	require.NotNil(t, result)
+	require.NotEmpty(t, result.GetContent())
+	assert.Contains(t, result.GetContent()[0].GetText(), "first second")
+
+	// 错误按分类映射为状态码
+	_, err = client.CallTool(ctx, &weavev1.CallToolRequest{Name: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.CallTool(ctx, &weavev1.CallToolRequest{Name: "echo", Arguments: arguments(map[string]interface{}{"query": "drop table users"})})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "rule no-drop")
+	_, err = client.CallTool(ctx, &weavev1.CallToolRequest{Name: "slow", Arguments: arguments(nil)})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	stream, err = client.CallToolStream(ctx, &weavev1.CallToolRequest{Name: "slow", Arguments: arguments(nil)})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
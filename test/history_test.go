@@ -0,0 +1,85 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/internal/history"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryRecordsToolCalls(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "history.db")
+	store, err := history.Open(history.DriverSQLite, dsn)
+	require.NoError(t, err)
+
+	recorder := history.NewRecorder(store, newTestLogger(t))
+
+	tm := tools.NewToolManager(newTestLogger(t), newTestToolConfig())
+	tm.RegisterAllTools()
+	tm.AddCallObserver(recorder.Observe)
+
+	ctx := tools.WithClient(context.Background(), "history-client")
+	args, _ := json.Marshal(tools.CalculatorArgs{Operation: "add", A: 2, B: 3})
+	_, err = tm.CallTool(ctx, "calculator", args)
+	require.NoError(t, err)
+
+	args, _ = json.Marshal(tools.CalculatorArgs{Operation: "divide", A: 1, B: 0})
+	_, err = tm.CallTool(ctx, "calculator", args)
+	require.Error(t, err)
+
+	_, err = tm.CallTool(context.Background(), "missing_tool", json.RawMessage(`{}`))
+	require.Error(t, err)
+
+	// 关闭时写完缓冲区
+	require.NoError(t, recorder.Close())
+
+	store, err = history.Open(history.DriverSQLite, dsn)
+	require.NoError(t, err)
+	defer store.Close()
+
+	t.Run("查询全部记录", func(t *testing.T) {
+		entries, err := store.Query(context.Background(), history.Filter{})
+		require.NoError(t, err)
+		assert.Len(t, entries, 3)
+	})
+
+	t.Run("按工具和客户端过滤", func(t *testing.T) {
+		entries, err := store.Query(context.Background(), history.Filter{
+			Tool:   "calculator",
+			Client: "history-client",
+		})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "math", entries[0].Category)
+	})
+
+	t.Run("按状态过滤", func(t *testing.T) {
+		entries, err := store.Query(context.Background(), history.Filter{Status: tools.CallStatusSuccess})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.JSONEq(t, `{"operation":"add","a":2,"b":3,"operands":null}`, string(entries[0].Arguments))
+		assert.Contains(t, string(entries[0].Result), `{\"result\":5}`)
+	})
+
+	t.Run("按时间范围过滤", func(t *testing.T) {
+		entries, err := store.Query(context.Background(), history.Filter{
+			Since: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+
+		entries, err = store.Query(context.Background(), history.Filter{
+			Since: time.Now().Add(-time.Hour),
+			Limit: 1,
+		})
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fetch(t *testing.T, tool *tools.HTTPFetchTool, args map[string]interface{}) (tools.HTTPFetchResult, error) {
+	t.Helper()
+	data, err := json.Marshal(args)
+	require.NoError(t, err)
+
+	var result tools.HTTPFetchResult
+	raw, err := tool.Execute(context.Background(), data)
+	if err != nil {
+		return result, err
+	}
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result, nil
+}
+
+func TestHTTPFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/text", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Test", "yes")
+		io.WriteString(w, "hello")
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"method": r.Method,
+			"token":  r.Header.Get("X-Token"),
+			"body":   string(body),
+		})
+	})
+	mux.HandleFunc("/binary", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte{0x00, 0xff, 0x10})
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, strings.Repeat("x", 100))
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/to-localhost", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.Replace("http://"+r.Host+"/text", "127.0.0.1", "localhost", 1), http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	t.Run("private addresses blocked by default", func(t *testing.T) {
+		tool := tools.NewHTTPFetchTool(config.HTTPFetchConfig{})
+		_, err := fetch(t, tool, map[string]interface{}{"url": srv.URL + "/text"})
+		assert.ErrorIs(t, err, tools.ErrFetchBlocked)
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		tool := tools.NewHTTPFetchTool(config.HTTPFetchConfig{AllowPrivate: true})
+		_, err := fetch(t, tool, map[string]interface{}{"url": "file:///etc/passwd"})
+		assert.ErrorIs(t, err, tools.ErrFetchBlocked)
+	})
+
+	tool := tools.NewHTTPFetchTool(config.HTTPFetchConfig{
+		AllowPrivate:     true,
+		DenyHosts:        []string{"localhost"},
+		MaxResponseBytes: 10,
+		MaxRedirects:     2,
+	})
+
+	t.Run("get text", func(t *testing.T) {
+		result, err := fetch(t, tool, map[string]interface{}{"url": srv.URL + "/text"})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, result.Status)
+		assert.Equal(t, "hello", result.Body)
+		assert.Equal(t, "text", result.BodyEncoding)
+		assert.Equal(t, []string{"yes"}, result.Headers["X-Test"])
+		assert.False(t, result.Truncated)
+	})
+
+	t.Run("post with headers and body", func(t *testing.T) {
+		big := tools.NewHTTPFetchTool(config.HTTPFetchConfig{AllowPrivate: true})
+		result, err := fetch(t, big, map[string]interface{}{
+			"url":     srv.URL + "/echo",
+			"method":  "POST",
+			"headers": map[string]string{"X-Token": "abc"},
+			"body":    "payload",
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"method":"POST","token":"abc","body":"payload"}`, result.Body)
+	})
+
+	t.Run("binary body as base64", func(t *testing.T) {
+		result, err := fetch(t, tool, map[string]interface{}{"url": srv.URL + "/binary"})
+		require.NoError(t, err)
+		assert.Equal(t, "base64", result.BodyEncoding)
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0x00, 0xff, 0x10}), result.Body)
+	})
+
+	t.Run("response truncated at cap", func(t *testing.T) {
+		result, err := fetch(t, tool, map[string]interface{}{"url": srv.URL + "/large"})
+		require.NoError(t, err)
+		assert.True(t, result.Truncated)
+		assert.Equal(t, strings.Repeat("x", 10), result.Body)
+	})
+
+	t.Run("redirect limit", func(t *testing.T) {
+		_, err := fetch(t, tool, map[string]interface{}{"url": srv.URL + "/loop"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "stopped after 2 redirects")
+	})
+
+	t.Run("redirect to denied host", func(t *testing.T) {
+		_, err := fetch(t, tool, map[string]interface{}{"url": srv.URL + "/to-localhost"})
+		assert.ErrorIs(t, err, tools.ErrFetchBlocked)
+	})
+
+	t.Run("allow list", func(t *testing.T) {
+		restricted := tools.NewHTTPFetchTool(config.HTTPFetchConfig{AllowPrivate: true, AllowHosts: []string{"*.example.com"}})
+		_, err := fetch(t, restricted, map[string]interface{}{"url": srv.URL + "/text"})
+		assert.ErrorIs(t, err, tools.ErrFetchBlocked)
+	})
+}
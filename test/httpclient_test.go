@@ -0,0 +1,161 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPClientRetry(t *testing.T) {
+	var hits atomic.Int32
+	var bodies []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		if hits.Add(1)%3 != 0 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	clients, err := tools.NewHTTPClients(config.HTTPClientConfig{
+		Retry: config.HTTPRetryConfig{MaxAttempts: 3, InitialBackoff: 1, MaxBackoff: 10},
+	})
+	require.NoError(t, err)
+	client := clients.Client(5 * time.Second)
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), hits.Load())
+
+	// 可重放请求体的 POST 在服务端明确拒绝时重试，每次发送完整的请求体
+	resp, err = client.Post(server.URL, "application/json", strings.NewReader(`{"a":1}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{`{"a":1}`, `{"a":1}`, `{"a":1}`}, bodies[3:])
+
+	// 超过最大尝试次数后返回最后一次响应
+	hits.Store(0)
+	clients, err = tools.NewHTTPClients(config.HTTPClientConfig{
+		Retry: config.HTTPRetryConfig{MaxAttempts: 2, InitialBackoff: 1, MaxBackoff: 10},
+	})
+	require.NoError(t, err)
+	resp, err = clients.Client(0).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestHTTPClientHostLimit(t *testing.T) {
+	var current, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := current.Add(1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		current.Add(-1)
+	}))
+	defer server.Close()
+
+	clients, err := tools.NewHTTPClients(config.HTTPClientConfig{
+		Hosts: map[string]config.HTTPHostConfig{"127.0.0.1": {MaxConns: 1}},
+	})
+	require.NoError(t, err)
+	client := clients.Client(5 * time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL)
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), peak.Load())
+}
+
+func TestHTTPClientProxy(t *testing.T) {
+	var proxied atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(r.URL.String())
+		w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+
+	clients, err := tools.NewHTTPClients(config.HTTPClientConfig{Proxy: proxy.URL, NoProxy: []string{"*.internal.test"}})
+	require.NoError(t, err)
+
+	resp, err := clients.Client(5 * time.Second).Get("http://api.example.test/v1")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "via proxy", string(body))
+	assert.Equal(t, "http://api.example.test/v1", proxied.Load())
+
+	// 无效配置在创建时报错
+	_, err = tools.NewHTTPClients(config.HTTPClientConfig{Proxy: "ftp://proxy:21"})
+	assert.ErrorContains(t, err, "unsupported proxy scheme")
+	_, err = tools.NewHTTPClients(config.HTTPClientConfig{TLS: config.HTTPClientTLSConfig{MinVersion: "1.0"}})
+	assert.ErrorContains(t, err, "unsupported tls min_version")
+	_, err = tools.NewHTTPClients(config.HTTPClientConfig{TLS: config.HTTPClientTLSConfig{CAFile: "missing.pem"}})
+	assert.ErrorContains(t, err, "ca_file")
+}
+
+func TestToolContextHTTPClient(t *testing.T) {
+	toolConfig := newTestToolConfig()
+	toolConfig.HTTPClient = config.HTTPClientConfig{Timeout: 7}
+	tm := tools.NewToolManager(newTestLogger(t), toolConfig)
+
+	var seen []*tools.HTTPClients
+	probe := testkit.NewMockTool("probe").Handle(func(ctx context.Context, _ json.RawMessage) (json.RawMessage, error) {
+		seen = append(seen, tools.ToolContextFrom(ctx).HTTP)
+		return json.RawMessage(`"ok"`), nil
+	})
+	tm.UseTools(probe)
+	tm.RegisterAllTools()
+
+	for i := 0; i < 2; i++ {
+		_, err := tm.CallTool(context.Background(), "probe", json.RawMessage(`{}`))
+		require.NoError(t, err)
+	}
+
+	// 同一配置下所有调用共用一个连接池，配置的超时优先于工具默认值
+	require.Len(t, seen, 2)
+	assert.Same(t, seen[0], seen[1])
+	assert.Equal(t, 7*time.Second, seen[0].Client(time.Minute).Timeout)
+
+	// 没有工具管理器时使用默认客户端
+	assert.Equal(t, time.Minute, tools.HTTPClient(context.Background(), time.Minute).Timeout)
+}
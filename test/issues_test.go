@@ -0,0 +1,141 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runIssues(t *testing.T, tool *tools.IssuesTool, args string) tools.IssuesResult {
+	t.Helper()
+	raw, err := tool.Execute(context.Background(), json.RawMessage(args))
+	require.NoError(t, err)
+	var result tools.IssuesResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result
+}
+
+func TestIssuesGitHub(t *testing.T) {
+	var created map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /search/issues", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer gh-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "label:bug repo:acme/app is:issue", r.URL.Query().Get("q"))
+		w.Write([]byte(`{"total_count": 7, "items": [
+			{"number": 12, "title": "Crash on start", "state": "open", "body": "trace", "html_url": "https://github.com/acme/app/issues/12",
+			 "user": {"login": "alice"}, "assignee": {"login": "bob"}, "labels": [{"name": "bug"}], "created_at": "2024-05-01T10:00:00Z"},
+			{"number": 13, "title": "Slow", "state": "open", "user": {"login": "carol"}}
+		]}`))
+	})
+	mux.HandleFunc("GET /repos/acme/app/issues/12", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number": 12, "title": "Crash on start", "state": "open", "body": "trace", "user": {"login": "alice"}}`))
+	})
+	mux.HandleFunc("GET /repos/acme/app/issues/12/comments", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 501, "body": "Confirmed", "user": {"login": "bob"}, "created_at": "2024-05-02T10:00:00Z"}]`))
+	})
+	mux.HandleFunc("POST /repos/acme/app/issues", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number": 14, "title": "New", "state": "open", "html_url": "https://github.com/acme/app/issues/14", "user": {"login": "bot"}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tracker := config.IssueTrackerConfig{Type: "github", URL: server.URL, Repo: "acme/app", Token: "gh-token"}
+	tool := tools.NewIssuesTool(config.IssuesConfig{Trackers: map[string]config.IssueTrackerConfig{"app": tracker}})
+
+	result := runIssues(t, tool, `{"op":"search","query":"label:bug","limit":1}`)
+	assert.Equal(t, 7, result.Total)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, "12", result.Issues[0].ID)
+	assert.Equal(t, "bob", result.Issues[0].Assignee)
+	assert.Equal(t, []string{"bug"}, result.Issues[0].Labels)
+	assert.Empty(t, result.Issues[0].Body)
+
+	result = runIssues(t, tool, `{"op":"get","id":"#12"}`)
+	require.NotNil(t, result.Issue)
+	assert.Equal(t, "trace", result.Issue.Body)
+	require.Len(t, result.Comments, 1)
+	assert.Equal(t, "Confirmed", result.Comments[0].Body)
+	assert.Equal(t, "501", result.Comments[0].ID)
+
+	// 写操作默认关闭
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"op":"create","title":"New"}`))
+	assert.True(t, errors.Is(err, tools.ErrIssuesReadOnly))
+	assert.Nil(t, created)
+
+	tracker.AllowWrite = true
+	writable := tools.NewIssuesTool(config.IssuesConfig{Trackers: map[string]config.IssueTrackerConfig{"app": tracker}})
+	result = runIssues(t, writable, `{"op":"create","title":"New","body":"details","labels":["triage"]}`)
+	assert.Equal(t, "14", result.Issue.ID)
+	assert.Equal(t, "details", created["body"])
+	assert.Equal(t, []interface{}{"triage"}, created["labels"])
+
+	_, err = writable.Execute(context.Background(), json.RawMessage(`{"op":"get","id":"abc"}`))
+	assert.ErrorContains(t, err, "invalid github issue number")
+	_, err = writable.Execute(context.Background(), json.RawMessage(`{"op":"get","id":"99"}`))
+	assert.ErrorContains(t, err, "github returned status 404")
+}
+
+func TestIssuesJira(t *testing.T) {
+	var comment map[string]string
+	mux := http.NewServeMux()
+	// 模拟 Jira Cloud：旧的 /search 已移除
+	mux.HandleFunc("GET /rest/api/2/search", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	mux.HandleFunc("GET /rest/api/2/search/jql", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "ops@example.com", user)
+		assert.Equal(t, "jira-token", pass)
+		assert.Equal(t, `project = "OPS" AND (status = Open) ORDER BY created DESC`, r.URL.Query().Get("jql"))
+		w.Write([]byte(`{"issues": [{"key": "OPS-7", "fields": {"summary": "Disk full", "status": {"name": "Open"},
+			"reporter": {"displayName": "Dana"}, "labels": ["infra"], "created": "2024-05-01T10:00:00.000+0200"}}]}`))
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/OPS-7/comment", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&comment))
+		w.Write([]byte(`{"id": "900", "body": "On it", "author": {"displayName": "Bot"}}`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusNotFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tool := tools.NewIssuesTool(config.IssuesConfig{Trackers: map[string]config.IssueTrackerConfig{
+		"ops": {Type: "jira", URL: server.URL, Project: "OPS", User: "ops@example.com", Token: "jira-token", AllowWrite: true},
+		"gh":  {Type: "github", Repo: "acme/app"},
+	}})
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"op":"search"}`))
+	assert.ErrorContains(t, err, "tracker is required, available: gh, ops")
+
+	result := runIssues(t, tool, `{"op":"search","tracker":"ops","query":"status = Open ORDER BY created DESC"}`)
+	require.Len(t, result.Issues, 1)
+	issue := result.Issues[0]
+	assert.Equal(t, "OPS-7", issue.ID)
+	assert.Equal(t, "Open", issue.State)
+	assert.Equal(t, "Dana", issue.Author)
+	assert.Equal(t, server.URL+"/browse/OPS-7", issue.URL)
+	assert.Equal(t, 8, issue.Created.UTC().Hour())
+
+	result = runIssues(t, tool, `{"op":"comment","tracker":"ops","id":"ops-7","body":"On it"}`)
+	assert.Equal(t, "On it", comment["body"])
+	assert.Equal(t, "900", result.Comment.ID)
+
+	// 问题键限定在配置的项目内
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"op":"get","tracker":"ops","id":"HR-1"}`))
+	assert.ErrorContains(t, err, "outside project OPS")
+}
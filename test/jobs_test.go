@@ -0,0 +1,242 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/jobs"
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(tb testing.TB) *logger.Logger {
+	log, err := logger.NewLogger(tb.TempDir(), "error")
+	require.NoError(tb, err)
+	tb.Cleanup(func() { log.Close() })
+	return log
+}
+
+func waitJob(t *testing.T, mgr *jobs.Manager, id string) *jobs.Job {
+	updates, unsubscribe, err := mgr.Subscribe(id)
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-updates:
+			if !ok {
+				job, err := mgr.Get(id)
+				require.NoError(t, err)
+				return job
+			}
+		case <-timeout:
+			t.Fatalf("job %s did not finish in time", id)
+		}
+	}
+}
+
+func TestJobManagerLifecycle(t *testing.T) {
+	runner := func(ctx context.Context, job jobs.Job) (interface{}, error) {
+		switch job.Tool {
+		case "echo":
+			return string(job.Arguments), nil
+		case "slow":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		default:
+			return nil, fmt.Errorf("tool not found: %s", job.Tool)
+		}
+	}
+
+	mgr := jobs.NewManager(jobs.Config{Workers: 2}, runner, newTestLogger(t))
+	require.NoError(t, mgr.Start())
+	defer mgr.Shutdown(context.Background())
+
+	t.Run("任务成功完成", func(t *testing.T) {
+		job, err := mgr.Submit("echo", json.RawMessage(`{"x":1}`), "test")
+		require.NoError(t, err)
+		assert.Equal(t, jobs.StatusPending, job.Status)
+
+		final := waitJob(t, mgr, job.ID)
+		assert.Equal(t, jobs.StatusCompleted, final.Status)
+		assert.Equal(t, `{"x":1}`, final.Result)
+		assert.NotNil(t, final.FinishedAt)
+	})
+
+	t.Run("任务执行失败", func(t *testing.T) {
+		job, err := mgr.Submit("missing", nil, "test")
+		require.NoError(t, err)
+
+		final := waitJob(t, mgr, job.ID)
+		assert.Equal(t, jobs.StatusFailed, final.Status)
+		assert.Contains(t, final.Error, "tool not found")
+	})
+
+	t.Run("取消运行中的任务", func(t *testing.T) {
+		job, err := mgr.Submit("slow", nil, "test")
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			j, _ := mgr.Get(job.ID)
+			return j.Status == jobs.StatusRunning
+		}, 5*time.Second, 10*time.Millisecond)
+
+		_, err = mgr.Cancel(job.ID)
+		require.NoError(t, err)
+
+		final := waitJob(t, mgr, job.ID)
+		assert.Equal(t, jobs.StatusCancelled, final.Status)
+	})
+
+	t.Run("查询不存在的任务", func(t *testing.T) {
+		_, err := mgr.Get("job_missing")
+		assert.Error(t, err)
+	})
+}
+
+func TestJobManagerPersistence(t *testing.T) {
+	dir := t.TempDir()
+	runner := func(ctx context.Context, job jobs.Job) (interface{}, error) {
+		return map[string]interface{}{"ok": true}, nil
+	}
+
+	mgr := jobs.NewManager(jobs.Config{StoreDir: dir}, runner, newTestLogger(t))
+	require.NoError(t, mgr.Start())
+
+	job, err := mgr.Submit("echo", nil, "test")
+	require.NoError(t, err)
+	waitJob(t, mgr, job.ID)
+	require.NoError(t, mgr.Shutdown(context.Background()))
+
+	// 重启后仍可查询任务结果
+	reloaded := jobs.NewManager(jobs.Config{StoreDir: dir}, runner, newTestLogger(t))
+	require.NoError(t, reloaded.Start())
+	defer reloaded.Shutdown(context.Background())
+
+	final, err := reloaded.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusCompleted, final.Status)
+	assert.Equal(t, map[string]interface{}{"ok": true}, final.Result)
+}
+
+func TestJobVisibility(t *testing.T) {
+	srv := testkit.NewServer(t, nil, testkit.NewMockTool("echo").Returns("ok"))
+	submit := func() string {
+		resp, err := srv.Call(mcp.MethodToolsCall, map[string]interface{}{"name": "echo", "arguments": map[string]string{}, "async": true})
+		require.NoError(t, err)
+		require.Nil(t, resp.Error)
+		var submitted struct {
+			JobID string `json:"jobId"`
+		}
+		require.NoError(t, json.Unmarshal(resp.Result, &submitted))
+		return submitted.JobID
+	}
+	listed := func() []string {
+		resp, err := srv.Call(mcp.MethodJobsList, nil)
+		require.NoError(t, err)
+		require.Nil(t, resp.Error)
+		var list struct {
+			Jobs []jobs.Job `json:"jobs"`
+		}
+		require.NoError(t, json.Unmarshal(resp.Result, &list))
+		ids := []string{}
+		for _, job := range list.Jobs {
+			ids = append(ids, job.ID)
+		}
+		return ids
+	}
+	get := func(id string) *testkit.RPCError {
+		resp, err := srv.Call(mcp.MethodJobsGet, map[string]string{"jobId": id})
+		require.NoError(t, err)
+		return resp.Error
+	}
+
+	// 会话中提交的任务只对同一会话可见，同名客户端的其他会话也看不到
+	_, err := srv.Initialize("client-a")
+	require.NoError(t, err)
+	inSession := submit()
+	assert.Regexp(t, `^job_[0-9a-f]{32}$`, inSession)
+	assert.Nil(t, get(inSession))
+	assert.Equal(t, []string{inSession}, listed())
+
+	_, err = srv.Initialize("client-a")
+	require.NoError(t, err)
+	assert.NotNil(t, get(inSession))
+	assert.Empty(t, listed())
+
+	// 不属于会话的请求无法确定任务归属：不能提交异步任务，也看不到任何任务，
+	// 即使声明与提交者相同的客户端名称
+	send := func(client, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(mcp.ClientNameHeader, client)
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+	for _, client := range []string{"client-b", "client-c"} {
+		rec := send(client, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{},"async":true}}`)
+		var reply rpcReply
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reply))
+		require.NotNil(t, reply.Error, client)
+		assert.Contains(t, reply.Error.Message, "require a session")
+
+		getBody := `{"jsonrpc":"2.0","id":2,"method":"jobs/get","params":{"jobId":"` + inSession + `"}}`
+		assert.Contains(t, send(client, getBody).Body.String(), "job not found")
+		list := send(client, `{"jsonrpc":"2.0","id":3,"method":"jobs/list"}`).Body.String()
+		assert.NotContains(t, list, inSession)
+	}
+	assert.Contains(t, send("client-a", `{"jsonrpc":"2.0","id":4,"method":"jobs/list"}`).Body.String(), `"jobs":[]`)
+}
+
+func TestJobErrorRedacted(t *testing.T) {
+	failing := testkit.NewMockTool("failing").Fails(errors.New("token tok_abc123 rejected for carol@example.com"))
+	srv := testkit.NewServer(t, func(cfg *config.Config) {
+		cfg.ToolConfig.Redaction.Patterns = []string{`tok_[a-z0-9]+`}
+		cfg.ToolConfig.PII = config.PIIConfig{Enabled: true}
+	}, failing)
+	_, err := srv.Initialize("client-a")
+	require.NoError(t, err)
+
+	resp, err := srv.Call(mcp.MethodToolsCall, map[string]interface{}{"name": "failing", "arguments": map[string]string{}, "async": true})
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	var submitted struct {
+		JobID string `json:"jobId"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Result, &submitted))
+
+	var job jobs.Job
+	require.Eventually(t, func() bool {
+		resp, err := srv.Call(mcp.MethodJobsGet, map[string]string{"jobId": submitted.JobID})
+		require.NoError(t, err)
+		require.Nil(t, resp.Error)
+		require.NoError(t, json.Unmarshal(resp.Result, &job))
+		return job.Status == jobs.StatusFailed
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// jobs/get 与 jobs/list 返回的错误已按脱敏规则处理并掩码个人信息
+	assert.NotContains(t, job.Error, "tok_abc123")
+	assert.NotContains(t, job.Error, "carol@")
+	assert.Contains(t, job.Error, "[REDACTED]")
+	assert.Contains(t, job.Error, "c***@example.com")
+
+	resp, err = srv.Call(mcp.MethodJobsList, nil)
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	assert.NotContains(t, string(resp.Result), "tok_abc123")
+	assert.NotContains(t, string(resp.Result), "carol@")
+}
@@ -0,0 +1,169 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"Weave-Toolkit/internal/jsonpath"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const storeDocument = `{
+	"store": {
+		"book": [
+			{"title": "Sayings", "author": "Rees", "price": 8.95, "category": "reference"},
+			{"title": "Sword", "author": "Waugh", "price": 12.99, "category": "fiction"},
+			{"title": "Moby Dick", "author": "Melville", "price": 8.99, "category": "fiction", "isbn": "0-553"},
+			{"title": "Rings", "author": "Tolkien", "price": 22.99, "category": "fiction", "isbn": "0-395"}
+		],
+		"bicycle": {"color": "red", "price": 19.95}
+	}
+}`
+
+func transform(t *testing.T, args map[string]interface{}) (tools.JSONTransformResult, error) {
+	t.Helper()
+	data, err := json.Marshal(args)
+	require.NoError(t, err)
+
+	var result tools.JSONTransformResult
+	raw, err := (&tools.JSONTransformTool{}).Execute(context.Background(), data)
+	if err != nil {
+		return result, err
+	}
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result, nil
+}
+
+func TestJSONPath(t *testing.T) {
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(storeDocument), &doc))
+
+	tests := []struct {
+		expr string
+		want []interface{}
+	}{
+		{"$.store.book[0].title", []interface{}{"Sayings"}},
+		{"$['store']['bicycle']['color']", []interface{}{"red"}},
+		{"$.store.book[-1].author", []interface{}{"Tolkien"}},
+		{"$.store.book[*].author", []interface{}{"Rees", "Waugh", "Melville", "Tolkien"}},
+		{"$..isbn", []interface{}{"0-553", "0-395"}},
+		{"$.store.book[0,2].title", []interface{}{"Sayings", "Moby Dick"}},
+		{"$.store.book[1:3].title", []interface{}{"Sword", "Moby Dick"}},
+		{"$.store.book[::-2].title", []interface{}{"Rings", "Sword"}},
+		{"$.store.book[?(@.isbn)].title", []interface{}{"Moby Dick", "Rings"}},
+		{"$.store.book[?(@.price < 10)].title", []interface{}{"Sayings", "Moby Dick"}},
+		{"$.store.book[?(@.category == 'fiction' && @.price > 20)].title", []interface{}{"Rings"}},
+		{"$.store.book[?(!(@.category == 'fiction'))].title", []interface{}{"Sayings"}},
+		{"$.store.book[?(@.price > $.store.bicycle.price)].title", []interface{}{"Rings"}},
+		{"$.store.missing", []interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := jsonpath.Query(tt.expr, doc)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, expr := range []string{"store.book", "$.store[", "$.book[?(@.a ==)]", "$[1:2:0]"} {
+		_, err := jsonpath.Compile(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestJSONTransform(t *testing.T) {
+	t.Run("jq", func(t *testing.T) {
+		result, err := transform(t, map[string]interface{}{
+			"op":          "jq",
+			"input":       storeDocument,
+			"parse_input": true,
+			"expression":  `.store.book[] | select(.price > 10) | .title`,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Count)
+		assert.Equal(t, []interface{}{"Sword", "Rings"}, result.Result)
+
+		// 不暴露服务器环境变量
+		result, err = transform(t, map[string]interface{}{"op": "jq", "input": nil, "expression": "$ENV | length"})
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{float64(0)}, result.Result)
+
+		_, err = transform(t, map[string]interface{}{"op": "jq", "input": 1, "expression": ".["})
+		assert.Error(t, err)
+		_, err = transform(t, map[string]interface{}{"op": "jq", "input": 1, "expression": "range(100000)"})
+		assert.ErrorContains(t, err, "more than")
+	})
+
+	t.Run("jsonpath", func(t *testing.T) {
+		result, err := transform(t, map[string]interface{}{
+			"op":          "jsonpath",
+			"input":       storeDocument,
+			"parse_input": true,
+			"expression":  "$..book[?(@.isbn)].price",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{8.99, 22.99}, result.Result)
+	})
+
+	t.Run("format", func(t *testing.T) {
+		input := map[string]interface{}{"b": []interface{}{1, 2}, "a": "<x>"}
+		result, err := transform(t, map[string]interface{}{"op": "format", "input": input})
+		require.NoError(t, err)
+		assert.Equal(t, "{\n  \"a\": \"<x>\",\n  \"b\": [\n    1,\n    2\n  ]\n}", result.Text)
+
+		result, err = transform(t, map[string]interface{}{"op": "format", "input": input, "indent": 0})
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":"<x>","b":[1,2]}`, result.Text)
+	})
+
+	t.Run("flatten", func(t *testing.T) {
+		result, err := transform(t, map[string]interface{}{
+			"op":        "flatten",
+			"input":     map[string]interface{}{"a": map[string]interface{}{"b": 1, "c": []interface{}{"x", "y"}}, "empty": map[string]interface{}{}},
+			"separator": "/",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"a/b":   float64(1),
+			"a/c/0": "x",
+			"a/c/1": "y",
+			"empty": map[string]interface{}{},
+		}, result.Result)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		result, err := transform(t, map[string]interface{}{
+			"op":    "diff",
+			"input": map[string]interface{}{"name": "web", "replicas": 2, "tags": []interface{}{"a", "b", "c"}, "old": true, "a/b": 1},
+			"other": map[string]interface{}{"name": "web", "replicas": 3, "tags": []interface{}{"a"}, "owner": nil, "a/b": 1},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, result.Equal)
+		assert.False(t, *result.Equal)
+
+		patch, err := json.Marshal(result.Result)
+		require.NoError(t, err)
+		assert.JSONEq(t, `[
+			{"op": "remove", "path": "/old"},
+			{"op": "add", "path": "/owner", "value": null},
+			{"op": "replace", "path": "/replicas", "value": 3},
+			{"op": "remove", "path": "/tags/2"},
+			{"op": "remove", "path": "/tags/1"}
+		]`, string(patch))
+
+		result, err = transform(t, map[string]interface{}{"op": "diff", "input": []interface{}{1}, "other": []interface{}{1}})
+		require.NoError(t, err)
+		assert.True(t, *result.Equal)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := transform(t, map[string]interface{}{"op": "format", "input": "{", "parse_input": true})
+		assert.Error(t, err)
+		_, err = transform(t, map[string]interface{}{"op": "jsonpath", "input": 1})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/internal/jsonstream"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// escapeSample 覆盖需要转义的各类字符
+const escapeSample = "引号\" 反斜杠\\ 控制\b\f\n\r\t\x01\x1f HTML <a href=\"x\">&</a> 分隔符   无效\xff\xfe 表情😀"
+
+func TestJSONStreamMatchesMarshal(t *testing.T) {
+	values := []interface{}{
+		nil,
+		escapeSample,
+		&tools.ToolCallResult{
+			Content: []tools.ToolCallContent{
+				{Type: "text", Text: escapeSample},
+				{Type: "image", Data: "aGVsbG8=", MimeType: "image/png"},
+				{Type: "text"},
+			},
+			IsError: true,
+			Meta: &tools.ResultMeta{Truncated: []tools.TruncatedContent{
+				{Index: 0, URI: "result://abc", Size: 100, Returned: 10},
+			}},
+		},
+		&tools.ToolCallResult{
+			Content:           []tools.ToolCallContent{{Type: "text", Text: `{"html":"<b>"}`}},
+			StructuredContent: json.RawMessage(`{"html":"<b>"}`),
+		},
+		&tools.ToolCallResult{},
+		(*tools.ToolCallResult)(nil),
+		map[string]interface{}{"b": []int{1, 2}, "a": "<x>"},
+	}
+	for _, value := range values {
+		expected, err := json.Marshal(value)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, jsonstream.Encode(&buf, value))
+		assert.Equal(t, string(expected), buf.String())
+	}
+
+	// 编码失败时返回错误
+	assert.Error(t, jsonstream.Encode(io.Discard, map[string]interface{}{"c": make(chan int)}))
+}
+
+// largeToolCallResult 约 1 MiB 文本的调用结果
+func largeToolCallResult() *tools.ToolCallResult {
+	return &tools.ToolCallResult{Content: []tools.ToolCallContent{
+		{Type: "text", Text: strings.Repeat("第 N 行日志输出 <tag> \"quoted\"\n", 32*1024)},
+	}}
+}
+
+// BenchmarkEncodeLargeResultMarshal 现有方式：json.Marshal 完整编码后写出
+func BenchmarkEncodeLargeResultMarshal(b *testing.B) {
+	result := largeToolCallResult()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			data, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "result": result, "id": 1})
+			if err != nil {
+				b.Fatal(err)
+			}
+			io.Discard.Write(data)
+		}
+	})
+}
+
+// BenchmarkEncodeLargeResultStream 流式编码，写缓冲从池中复用
+func BenchmarkEncodeLargeResultStream(b *testing.B) {
+	result := largeToolCallResult()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := jsonstream.Encode(io.Discard, result); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,201 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newFakeCluster() *fake.Clientset {
+	replicas := int32(2)
+	return fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "apps", Labels: map[string]string{"app": "web"}},
+			Spec:       corev1.PodSpec{NodeName: "node-a", Containers: []corev1.Container{{Name: "web"}, {Name: "sidecar"}}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "web", Ready: true, RestartCount: 2},
+					{Name: "sidecar", Ready: false, RestartCount: 1},
+				},
+			},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Namespace: "apps", Labels: map[string]string{"app": "worker"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "etcd-0", Namespace: "kube-system"}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "nginx:1.27"}}}},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+			Spec: corev1.ServiceSpec{
+				Type:      corev1.ServiceTypeClusterIP,
+				ClusterIP: "10.0.0.10",
+				Ports:     []corev1.ServicePort{{Port: 80, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt32(8080)}},
+			},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web-1.1", Namespace: "apps"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-1", Namespace: "apps"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			Count:          3,
+		},
+	)
+}
+
+func k8sCall(t *testing.T, tool *tools.K8sTool, args map[string]interface{}) (tools.K8sResult, json.RawMessage, error) {
+	t.Helper()
+	data, err := json.Marshal(args)
+	require.NoError(t, err)
+
+	var result tools.K8sResult
+	raw, err := tool.Execute(context.Background(), data)
+	if err != nil {
+		return result, nil, err
+	}
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result, raw, nil
+}
+
+func TestK8sList(t *testing.T) {
+	tool := tools.NewK8sToolWithClient(config.K8sConfig{Namespaces: []string{"apps"}}, newFakeCluster(), "default")
+
+	_, raw, err := k8sCall(t, tool, map[string]interface{}{"op": "list", "label_selector": "app=web"})
+	require.NoError(t, err)
+	var pods struct {
+		Namespace string         `json:"namespace"`
+		Items     []tools.K8sPod `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &pods))
+	assert.Equal(t, "apps", pods.Namespace)
+	require.Len(t, pods.Items, 1)
+	assert.Equal(t, "web-1", pods.Items[0].Name)
+	assert.Equal(t, "1/2", pods.Items[0].Ready)
+	assert.Equal(t, int32(3), pods.Items[0].Restarts)
+
+	_, raw, err = k8sCall(t, tool, map[string]interface{}{"op": "list", "kind": "deployments"})
+	require.NoError(t, err)
+	var deployments struct {
+		Items []tools.K8sDeployment `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &deployments))
+	require.Len(t, deployments.Items, 1)
+	assert.Equal(t, int32(2), deployments.Items[0].Replicas)
+	assert.Equal(t, []string{"nginx:1.27"}, deployments.Items[0].Images)
+
+	_, raw, err = k8sCall(t, tool, map[string]interface{}{"op": "list", "kind": "services"})
+	require.NoError(t, err)
+	var services struct {
+		Items []tools.K8sService `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &services))
+	require.Len(t, services.Items, 1)
+	assert.Equal(t, []string{"80/TCP->8080"}, services.Items[0].Ports)
+}
+
+func TestK8sNamespaces(t *testing.T) {
+	restricted := tools.NewK8sToolWithClient(config.K8sConfig{Namespaces: []string{"apps"}}, newFakeCluster(), "default")
+	_, _, err := k8sCall(t, restricted, map[string]interface{}{"op": "list", "namespace": "kube-system"})
+	assert.True(t, errors.Is(err, tools.ErrNamespaceDenied))
+
+	// 未配置允许列表时只能访问默认命名空间
+	defaults := tools.NewK8sToolWithClient(config.K8sConfig{}, newFakeCluster(), "apps")
+	result, _, err := k8sCall(t, defaults, map[string]interface{}{"op": "list"})
+	require.NoError(t, err)
+	assert.Equal(t, "apps", result.Namespace)
+	_, _, err = k8sCall(t, defaults, map[string]interface{}{"op": "list", "namespace": "kube-system"})
+	assert.True(t, errors.Is(err, tools.ErrNamespaceDenied))
+
+	all := tools.NewK8sToolWithClient(config.K8sConfig{Namespaces: []string{"*"}}, newFakeCluster(), "apps")
+	_, _, err = k8sCall(t, all, map[string]interface{}{"op": "list", "namespace": "kube-system"})
+	assert.NoError(t, err)
+}
+
+func TestK8sDescribe(t *testing.T) {
+	tool := tools.NewK8sToolWithClient(config.K8sConfig{Namespaces: []string{"apps"}}, newFakeCluster(), "default")
+
+	result, _, err := k8sCall(t, tool, map[string]interface{}{"op": "describe", "kind": "pods", "name": "web-1"})
+	require.NoError(t, err)
+	var pod corev1.Pod
+	require.NoError(t, json.Unmarshal(result.Object, &pod))
+	assert.Equal(t, "node-a", pod.Spec.NodeName)
+	require.Len(t, result.Events, 1)
+	assert.Equal(t, "BackOff", result.Events[0].Reason)
+
+	_, _, err = k8sCall(t, tool, map[string]interface{}{"op": "describe", "kind": "pods", "name": "missing"})
+	assert.Error(t, err)
+	_, _, err = k8sCall(t, tool, map[string]interface{}{"op": "describe", "kind": "pods"})
+	assert.Error(t, err)
+}
+
+func TestK8sLogs(t *testing.T) {
+	tool := tools.NewK8sToolWithClient(config.K8sConfig{Namespaces: []string{"apps"}}, newFakeCluster(), "default")
+
+	result, _, err := k8sCall(t, tool, map[string]interface{}{"op": "logs", "name": "web-1", "tail_lines": 10})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Logs)
+	assert.Equal(t, 1, result.Lines)
+
+	// 流式调用按行推送
+	var lines []string
+	raw, err := tool.ExecuteStream(context.Background(), json.RawMessage(`{"op":"logs","name":"web-1"}`), func(content string, index int) {
+		lines = append(lines, content)
+	})
+	require.NoError(t, err)
+	var streamed tools.K8sResult
+	require.NoError(t, json.Unmarshal(raw, &streamed))
+	assert.Len(t, lines, 1)
+	assert.Equal(t, 1, streamed.Lines)
+	assert.Empty(t, streamed.Logs)
+
+	// 非流式调用不支持跟随
+	_, _, err = k8sCall(t, tool, map[string]interface{}{"op": "logs", "name": "web-1", "follow": true})
+	assert.Error(t, err)
+
+	capped := tools.NewK8sToolWithClient(config.K8sConfig{Namespaces: []string{"apps"}, MaxLogBytes: 2}, newFakeCluster(), "default")
+	result, _, err = k8sCall(t, capped, map[string]interface{}{"op": "logs", "name": "web-1"})
+	require.NoError(t, err)
+	assert.True(t, result.Truncated)
+}
+
+func TestK8sWriteOperations(t *testing.T) {
+	readOnly := tools.NewK8sToolWithClient(config.K8sConfig{Namespaces: []string{"apps"}}, newFakeCluster(), "default")
+	_, _, err := k8sCall(t, readOnly, map[string]interface{}{"op": "scale", "kind": "deployments", "name": "web", "replicas": 3})
+	assert.True(t, errors.Is(err, tools.ErrK8sReadOnly))
+	_, _, err = k8sCall(t, readOnly, map[string]interface{}{"op": "delete", "name": "web-1"})
+	assert.True(t, errors.Is(err, tools.ErrK8sReadOnly))
+
+	cluster := newFakeCluster()
+	writable := tools.NewK8sToolWithClient(config.K8sConfig{Namespaces: []string{"apps"}, AllowWrite: true}, cluster, "default")
+
+	result, _, err := k8sCall(t, writable, map[string]interface{}{"op": "scale", "kind": "deployments", "name": "web", "replicas": 3})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), *result.Replicas)
+	deployment, err := cluster.AppsV1().Deployments("apps").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+
+	_, _, err = k8sCall(t, writable, map[string]interface{}{"op": "delete", "kind": "services", "name": "web"})
+	assert.Error(t, err)
+	_, _, err = k8sCall(t, writable, map[string]interface{}{"op": "delete", "name": "web-1"})
+	require.NoError(t, err)
+	_, err = cluster.CoreV1().Pods("apps").Get(context.Background(), "web-1", metav1.GetOptions{})
+	assert.Error(t, err)
+}
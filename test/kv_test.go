@@ -0,0 +1,152 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func kv(t *testing.T, tool *tools.KVTool, args map[string]interface{}) (tools.KVResult, error) {
+	t.Helper()
+	data, err := json.Marshal(args)
+	require.NoError(t, err)
+
+	var result tools.KVResult
+	raw, err := tool.Execute(context.Background(), data)
+	if err != nil {
+		return result, err
+	}
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result, nil
+}
+
+func TestKVMemoryStore(t *testing.T) {
+	tool := tools.NewKVTool(config.KVConfig{KeyPrefix: "agent:*:"})
+
+	result, err := kv(t, tool, map[string]interface{}{"op": "get", "key": "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, tools.KVMemoryInstance, result.Instance)
+	assert.False(t, result.Found)
+	assert.Nil(t, result.Value)
+
+	_, err = kv(t, tool, map[string]interface{}{"op": "set", "key": "plan", "value": "step 1"})
+	require.NoError(t, err)
+	result, err = kv(t, tool, map[string]interface{}{"op": "get", "key": "plan"})
+	require.NoError(t, err)
+	require.True(t, result.Found)
+	assert.Equal(t, "step 1", *result.Value)
+
+	// 未设置过期时间
+	result, err = kv(t, tool, map[string]interface{}{"op": "ttl", "key": "plan"})
+	require.NoError(t, err)
+	require.True(t, result.Found)
+	assert.Equal(t, int64(-1), *result.TTL)
+
+	_, err = kv(t, tool, map[string]interface{}{"op": "set", "key": "lease", "value": "x", "ttl_seconds": 60})
+	require.NoError(t, err)
+	result, err = kv(t, tool, map[string]interface{}{"op": "ttl", "key": "lease"})
+	require.NoError(t, err)
+	require.True(t, result.Found)
+	assert.InDelta(t, 60, *result.TTL, 1)
+
+	result, err = kv(t, tool, map[string]interface{}{"op": "del", "key": "lease"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Deleted)
+	result, err = kv(t, tool, map[string]interface{}{"op": "del", "key": "lease"})
+	require.NoError(t, err)
+	assert.False(t, result.Found)
+
+	result, err = kv(t, tool, map[string]interface{}{"op": "ttl", "key": "lease"})
+	require.NoError(t, err)
+	assert.False(t, result.Found)
+	assert.Nil(t, result.TTL)
+}
+
+func TestKVScan(t *testing.T) {
+	tool := tools.NewKVTool(config.KVConfig{KeyPrefix: "agent:*:"})
+	for i := 0; i < 5; i++ {
+		_, err := kv(t, tool, map[string]interface{}{"op": "set", "key": fmt.Sprintf("task:%d", i), "value": "v"})
+		require.NoError(t, err)
+	}
+	_, err := kv(t, tool, map[string]interface{}{"op": "set", "key": "note", "value": "v"})
+	require.NoError(t, err)
+
+	// 按页遍历直到游标归零，前缀中的 * 按字面量处理且不出现在返回的键中
+	var keys []string
+	cursor := "0"
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10)
+		result, err := kv(t, tool, map[string]interface{}{"op": "scan", "match": "task:*", "cursor": cursor, "count": 2})
+		require.NoError(t, err)
+		keys = append(keys, result.Keys...)
+		cursor = result.Cursor
+		if cursor == "0" {
+			break
+		}
+	}
+	assert.ElementsMatch(t, []string{"task:0", "task:1", "task:2", "task:3", "task:4"}, keys)
+
+	result, err := kv(t, tool, map[string]interface{}{"op": "scan", "match": "task:[13]"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task:1", "task:3"}, result.Keys)
+	assert.Equal(t, "0", result.Cursor)
+
+	_, err = kv(t, tool, map[string]interface{}{"op": "scan", "cursor": "abc"})
+	assert.Error(t, err)
+}
+
+func TestKVLimits(t *testing.T) {
+	tool := tools.NewKVTool(config.KVConfig{MaxKeys: 2, MaxValueBytes: 4})
+
+	_, err := kv(t, tool, map[string]interface{}{"op": "set", "key": "a", "value": "12345"})
+	assert.Error(t, err)
+
+	for _, key := range []string{"a", "b"} {
+		_, err := kv(t, tool, map[string]interface{}{"op": "set", "key": key, "value": "1"})
+		require.NoError(t, err)
+	}
+	_, err = kv(t, tool, map[string]interface{}{"op": "set", "key": "c", "value": "1"})
+	assert.True(t, errors.Is(err, tools.ErrKVFull))
+
+	// 覆盖已有的键不受数量上限限制
+	_, err = kv(t, tool, map[string]interface{}{"op": "set", "key": "a", "value": "2"})
+	assert.NoError(t, err)
+
+	_, err = kv(t, tool, map[string]interface{}{"op": "get"})
+	assert.Error(t, err)
+	_, err = kv(t, tool, map[string]interface{}{"op": "get", "key": "a", "instance": "cache"})
+	assert.ErrorContains(t, err, "unknown kv instance")
+}
+
+func TestKVRedisUnavailable(t *testing.T) {
+	// 占用一个端口后立即关闭，确保连接被拒绝
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	tool := tools.NewKVTool(config.KVConfig{
+		Instances: map[string]config.KVInstanceConfig{"cache": {Addr: addr}},
+		Default:   "cache",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = tool.Execute(ctx, json.RawMessage(`{"op":"get","key":"a"}`))
+	assert.ErrorContains(t, err, "kv instance cache")
+
+	// 内存存储始终可用
+	result, err := kv(t, tool, map[string]interface{}{"op": "set", "key": "a", "value": "1", "instance": tools.KVMemoryInstance})
+	require.NoError(t, err)
+	assert.True(t, result.Found)
+}
@@ -0,0 +1,346 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/internal/cluster"
+	"Weave-Toolkit/internal/jobs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis 只实现领导者选举所需命令的内存 Redis：GET、SET（NX/PX/EX）、DEL、PEXPIRE，
+// 以及按脚本内容识别的续约与释放脚本
+type fakeRedis struct {
+	addr string
+
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+}
+
+// newFakeRedis 在随机端口启动 fakeRedis，测试结束时关闭
+func newFakeRedis(t *testing.T) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f := &fakeRedis{addr: listener.Addr().String(), values: make(map[string]string), expires: make(map[string]time.Time)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+// URL 连接地址
+func (f *fakeRedis) URL() string {
+	return "redis://" + f.addr + "/0"
+}
+
+// Value 读取未过期的值
+func (f *fakeRedis) Value(key string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.getLocked(key)
+}
+
+// Set 直接写入值，模拟其他副本持有租约
+func (f *fakeRedis) Set(key, value string, ttl time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.values[key] = value
+	f.expires[key] = time.Now().Add(ttl)
+}
+
+func (f *fakeRedis) getLocked(key string) (string, bool) {
+	if expires, ok := f.expires[key]; ok && !time.Now().Before(expires) {
+		delete(f.values, key)
+		delete(f.expires, key)
+	}
+	value, ok := f.values[key]
+	return value, ok
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, f.exec(args)); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand 读取一条 RESP 数组形式的命令
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		if value, ok := f.getLocked(args[1]); ok {
+			return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+		}
+		return "$-1\r\n"
+	case "SET":
+		key, value := args[1], args[2]
+		var ttl time.Duration
+		nx := false
+		for i := 3; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "NX":
+				nx = true
+			case "PX", "EX":
+				n, _ := strconv.Atoi(args[i+1])
+				ttl = time.Duration(n) * time.Millisecond
+				if strings.EqualFold(args[i], "EX") {
+					ttl = time.Duration(n) * time.Second
+				}
+				i++
+			}
+		}
+		if _, exists := f.getLocked(key); exists && nx {
+			return "$-1\r\n"
+		}
+		f.values[key] = value
+		delete(f.expires, key)
+		if ttl > 0 {
+			f.expires[key] = time.Now().Add(ttl)
+		}
+		return "+OK\r\n"
+	case "DEL":
+		return fmt.Sprintf(":%d\r\n", f.delLocked(args[1:]...))
+	case "EVALSHA":
+		return "-NOSCRIPT No matching script\r\n"
+	case "EVAL":
+		// 续约与释放脚本：租约仍由 ARGV[1] 持有时延长过期时间或删除
+		script, key, owner := args[1], args[3], args[4]
+		if value, ok := f.getLocked(key); !ok || value != owner {
+			return ":0\r\n"
+		}
+		if strings.Contains(script, "PEXPIRE") {
+			ms, _ := strconv.Atoi(args[5])
+			f.expires[key] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			return ":1\r\n"
+		}
+		return fmt.Sprintf(":%d\r\n", f.delLocked(key))
+	default:
+		// HELLO、CLIENT SETINFO 等返回错误，客户端随即按 RESP2 继续
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
+
+func (f *fakeRedis) delLocked(keys ...string) int {
+	deleted := 0
+	for _, key := range keys {
+		if _, ok := f.getLocked(key); ok {
+			delete(f.values, key)
+			delete(f.expires, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+func TestClusterLeaderElection(t *testing.T) {
+	fake := newFakeRedis(t)
+	connect := func() *cluster.Client {
+		client, err := cluster.Connect(context.Background(), fake.URL(), "")
+		require.NoError(t, err)
+		t.Cleanup(func() { client.Close() })
+		return client
+	}
+	const ttl = 150 * time.Millisecond
+	key := cluster.DefaultPrefix + "leader:scheduler"
+
+	// campaign 启动一个副本的竞选，返回其选举、任期计数与停止函数
+	type candidate struct {
+		leader *cluster.Leader
+		terms  atomic.Int32
+		active atomic.Bool
+		stop   func()
+	}
+	campaign := func(client *cluster.Client) *candidate {
+		c := &candidate{leader: client.NewLeader("scheduler", ttl)}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.leader.Run(ctx, func(ctx context.Context) {
+				c.terms.Add(1)
+				c.active.Store(true)
+				<-ctx.Done()
+				c.active.Store(false)
+			})
+		}()
+		c.stop = func() {
+			cancel()
+			<-done
+		}
+		t.Cleanup(c.stop)
+		return c
+	}
+
+	a, b := connect(), connect()
+
+	// 获取租约：第一个副本当选，键记录其标识
+	first := campaign(a)
+	require.Eventually(t, first.leader.IsLeader, time.Second, 5*time.Millisecond)
+	require.Eventually(t, first.active.Load, time.Second, 5*time.Millisecond)
+	owner, held := fake.Value(key)
+	require.True(t, held)
+	assert.Equal(t, a.Instance(), owner)
+
+	// 续约：超过数个租约时长后仍在同一任期内，其他副本无法当选
+	second := campaign(b)
+	time.Sleep(3 * ttl)
+	assert.True(t, first.leader.IsLeader())
+	assert.Equal(t, int32(1), first.terms.Load())
+	assert.False(t, second.leader.IsLeader())
+	assert.Zero(t, second.terms.Load())
+	owner, _ = fake.Value(key)
+	assert.Equal(t, a.Instance(), owner)
+
+	// 释放：当选副本退出时删除租约，另一副本随即接任
+	first.stop()
+	assert.False(t, first.leader.IsLeader())
+	assert.False(t, first.active.Load())
+	require.Eventually(t, second.leader.IsLeader, time.Second, 5*time.Millisecond)
+	owner, _ = fake.Value(key)
+	assert.Equal(t, b.Instance(), owner)
+
+	// 失去租约：租约被其他副本持有后续约失败，任期的上下文被取消
+	fake.Set(key, "other-instance", time.Minute)
+	require.Eventually(t, func() bool { return !second.leader.IsLeader() }, time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool { return !second.active.Load() }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), second.terms.Load())
+
+	// 退出时不会删除其他副本持有的租约
+	second.stop()
+	owner, held = fake.Value(key)
+	assert.True(t, held)
+	assert.Equal(t, "other-instance", owner)
+}
+
+func TestClusterLeaderReapsSharedJobs(t *testing.T) {
+	fake := newFakeRedis(t)
+	store := newMemoryJobStore()
+	const ttl = 150 * time.Millisecond
+
+	// start 启动一个副本：各自的任务管理器共用共享存储，只在当选期间清理共享存储
+	type replica struct {
+		leader *cluster.Leader
+		sweeps atomic.Int32
+		stop   func()
+	}
+	start := func(name string) *replica {
+		client, err := cluster.Connect(context.Background(), fake.URL(), "")
+		require.NoError(t, err)
+		t.Cleanup(func() { client.Close() })
+		mgr := jobs.NewManager(jobs.Config{Shared: store, Instance: name, Retention: time.Minute}, nil, newTestLogger(t))
+
+		r := &replica{leader: client.NewLeader("maintenance", ttl)}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			r.leader.Run(ctx, cluster.Every(10*time.Millisecond, func(ctx context.Context) {
+				r.sweeps.Add(1)
+				mgr.ReapShared(ctx)
+			}))
+		}()
+		r.stop = func() {
+			cancel()
+			<-done
+		}
+		t.Cleanup(r.stop)
+		return r
+	}
+
+	a := start("replica-a")
+	require.Eventually(t, a.leader.IsLeader, time.Second, 5*time.Millisecond)
+	b := start("replica-b")
+
+	// 只清理超过保留时长的已完成任务
+	finished := time.Now().Add(-time.Hour)
+	recent := time.Now()
+	ctx := context.Background()
+	store.Save(ctx, jobs.Job{ID: "job_expired", Status: jobs.StatusCompleted, FinishedAt: &finished}, 0)
+	store.Save(ctx, jobs.Job{ID: "job_recent", Status: jobs.StatusFailed, FinishedAt: &recent}, 0)
+	store.Save(ctx, jobs.Job{ID: "job_running", Status: jobs.StatusRunning}, 0)
+	require.Eventually(t, func() bool {
+		_, found, _ := store.Load(ctx, "job_expired")
+		return !found
+	}, time.Second, 5*time.Millisecond)
+	_, found, _ := store.Load(ctx, "job_recent")
+	assert.True(t, found)
+	_, found, _ = store.Load(ctx, "job_running")
+	assert.True(t, found)
+
+	// 同一时刻只有当选副本执行清理
+	time.Sleep(2 * ttl)
+	assert.Positive(t, a.sweeps.Load())
+	assert.Zero(t, b.sweeps.Load())
+
+	// 当选副本退出后清理停止，由另一副本接任
+	a.stop()
+	stopped := a.sweeps.Load()
+	require.Eventually(t, func() bool { return b.sweeps.Load() > 0 }, time.Second, 5*time.Millisecond)
+	store.Save(ctx, jobs.Job{ID: "job_expired_later", Status: jobs.StatusCancelled, FinishedAt: &finished}, 0)
+	require.Eventually(t, func() bool {
+		_, found, _ := store.Load(ctx, "job_expired_later")
+		return !found
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, stopped, a.sweeps.Load())
+}
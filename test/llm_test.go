@@ -0,0 +1,245 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/llm"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeLLMServer 模拟 OpenAI、Anthropic 与 Ollama 的补全接口，回复固定为 "Hello there"
+func newFakeLLMServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer openai-key", r.Header.Get("Authorization"))
+		var req struct {
+			Model    string `json:"model"`
+			Stream   bool   `json:"stream"`
+			Messages []struct {
+				Role string `json:"role"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "system", req.Messages[0].Role)
+		if !req.Stream {
+			fmt.Fprintf(w, `{"model":%q,"choices":[{"message":{"content":"Hello there"},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":2}}`, req.Model)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: {\"model\":%q,\"choices\":[{\"delta\":{\"content\":\"Hello\"}}]}\n\n", req.Model)
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\" there\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":2}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+	mux.HandleFunc("/v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "anthropic-key", r.Header.Get("x-api-key"))
+		assert.NotEmpty(t, r.Header.Get("anthropic-version"))
+		var req struct {
+			System    string `json:"system"`
+			MaxTokens int    `json:"max_tokens"`
+			Stream    bool   `json:"stream"`
+			Messages  []struct {
+				Role string `json:"role"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "Be brief.", req.System)
+		assert.Positive(t, req.MaxTokens)
+		for _, message := range req.Messages {
+			assert.NotEqual(t, "system", message.Role)
+		}
+		if !req.Stream {
+			w.Write([]byte(`{"model":"claude-test","content":[{"type":"text","text":"Hello there"}],"stop_reason":"end_turn","usage":{"input_tokens":5,"output_tokens":2}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{
+			`{"type":"message_start","message":{"model":"claude-test","usage":{"input_tokens":5}}}`,
+			`{"type":"content_block_delta","delta":{"type":"text_delta","text":"Hello"}}`,
+			`{"type":"ping"}`,
+			`{"type":"content_block_delta","delta":{"type":"text_delta","text":" there"}}`,
+			`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":2}}`,
+			`{"type":"message_stop"}`,
+		} {
+			fmt.Fprintf(w, "event: x\ndata: %s\n\n", event)
+		}
+	})
+	mux.HandleFunc("/api/chat", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model  string `json:"model"`
+			Stream bool   `json:"stream"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if !req.Stream {
+			fmt.Fprintf(w, `{"model":%q,"message":{"content":"Hello there"},"done":true,"done_reason":"stop","prompt_eval_count":5,"eval_count":2}`, req.Model)
+			return
+		}
+		fmt.Fprintf(w, "{\"model\":%q,\"message\":{\"content\":\"Hello\"},\"done\":false}\n", req.Model)
+		fmt.Fprintf(w, "{\"model\":%q,\"message\":{\"content\":\" there\"},\"done\":false}\n", req.Model)
+		fmt.Fprintf(w, "{\"model\":%q,\"message\":{\"content\":\"\"},\"done\":true,\"done_reason\":\"stop\",\"prompt_eval_count\":5,\"eval_count\":2}\n", req.Model)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestLLMTool(serverURL string) *tools.LLMTool {
+	return tools.NewLLMTool(config.LLMConfig{
+		Default: "openai",
+		Providers: map[string]config.LLMProviderConfig{
+			"openai":    {Type: "openai", URL: serverURL + "/v1", APIKey: "openai-key", Model: "gpt-test", Models: []string{"gpt-other"}},
+			"anthropic": {Type: "anthropic", URL: serverURL, APIKey: "anthropic-key", Model: "claude-test"},
+			"ollama":    {Type: "ollama", URL: serverURL, Model: "llama-test"},
+		},
+	})
+}
+
+func TestLLMProviders(t *testing.T) {
+	server := newFakeLLMServer(t)
+	tool := newTestLLMTool(server.URL)
+
+	for _, provider := range []string{"openai", "anthropic", "ollama"} {
+		args := json.RawMessage(`{"provider":"` + provider + `","system":"Be brief.","messages":[{"role":"user","content":"Hi"},{"role":"assistant","content":"Hi!"}],"prompt":"Greet me","temperature":0.2}`)
+
+		t.Run(provider, func(t *testing.T) {
+			raw, err := tool.Execute(context.Background(), args)
+			require.NoError(t, err)
+			var result tools.LLMResult
+			require.NoError(t, json.Unmarshal(raw, &result))
+			assert.Equal(t, provider, result.Provider)
+			assert.Equal(t, "Hello there", result.Content)
+			assert.Equal(t, 5, result.Usage.InputTokens)
+			assert.Equal(t, 2, result.Usage.OutputTokens)
+			assert.NotEmpty(t, result.FinishReason)
+		})
+
+		t.Run(provider+" stream", func(t *testing.T) {
+			var deltas []string
+			raw, err := tool.ExecuteStream(context.Background(), args, func(content string, index int) {
+				assert.Equal(t, len(deltas), index)
+				deltas = append(deltas, content)
+			})
+			require.NoError(t, err)
+			var result tools.LLMResult
+			require.NoError(t, json.Unmarshal(raw, &result))
+			assert.Equal(t, []string{"Hello", " there"}, deltas)
+			assert.Equal(t, "Hello there", result.Content)
+			assert.Equal(t, 2, result.Usage.OutputTokens)
+		})
+	}
+}
+
+func TestLLMValidation(t *testing.T) {
+	server := newFakeLLMServer(t)
+	tool := newTestLLMTool(server.URL)
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"system":"Be brief."}`))
+	assert.ErrorContains(t, err, "prompt or messages")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"prompt":"Hi","model":"gpt-unknown"}`))
+	assert.True(t, errors.Is(err, tools.ErrModelNotAllowed))
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"prompt":"Hi","provider":"missing"}`))
+	assert.Error(t, err)
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"prompt":"Hi","max_tokens":100000}`))
+	assert.ErrorContains(t, err, "max_tokens")
+
+	unconfigured := tools.NewLLMTool(config.LLMConfig{})
+	_, err = unconfigured.Execute(context.Background(), json.RawMessage(`{"prompt":"Hi"}`))
+	assert.ErrorContains(t, err, "no llm provider")
+}
+
+// newFakeToolCallServer 模拟三种服务返回工具调用，记录收到的请求体
+func newFakeToolCallServer(t *testing.T, bodies map[string]map[string]interface{}) *httptest.Server {
+	t.Helper()
+	record := func(name string, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies[name] = body
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		record("openai", r)
+		w.Write([]byte(`{"model":"gpt-test","choices":[{"message":{"content":null,"tool_calls":[{"id":"call_2","type":"function","function":{"name":"calculator","arguments":"{\"operation\":\"add\",\"a\":1,\"b\":2}"}}]},"finish_reason":"tool_calls"}]}`))
+	})
+	mux.HandleFunc("/v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		record("anthropic", r)
+		w.Write([]byte(`{"model":"claude-test","content":[{"type":"text","text":"Let me add."},{"type":"tool_use","id":"toolu_2","name":"calculator","input":{"operation":"add","a":1,"b":2}}],"stop_reason":"tool_use"}`))
+	})
+	mux.HandleFunc("/api/chat", func(w http.ResponseWriter, r *http.Request) {
+		record("ollama", r)
+		w.Write([]byte(`{"model":"llama-test","message":{"content":"","tool_calls":[{"function":{"name":"calculator","arguments":{"operation":"add","a":1,"b":2}}}]},"done":true,"done_reason":"stop"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLLMToolCalls(t *testing.T) {
+	bodies := map[string]map[string]interface{}{}
+	server := newFakeToolCallServer(t, bodies)
+	tool := newTestLLMTool(server.URL)
+
+	req := llm.Request{
+		Messages: []llm.Message{
+			{Role: "user", Content: "What is 1+2, then 3+4?"},
+			{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "call_1", Name: "calculator", Arguments: json.RawMessage(`{"operation":"add","a":3,"b":4}`)}}},
+			{Role: "tool", ToolCallID: "call_1", Content: `{"result":7}`},
+		},
+		Tools: []llm.ToolDefinition{{
+			Name:        "calculator",
+			Description: "Basic arithmetic",
+			Parameters:  map[string]interface{}{"type": "object"},
+		}},
+	}
+
+	for _, provider := range []string{"openai", "anthropic", "ollama"} {
+		t.Run(provider, func(t *testing.T) {
+			result, err := tool.Complete(context.Background(), provider, req, nil)
+			require.NoError(t, err)
+			require.Len(t, result.ToolCalls, 1)
+			call := result.ToolCalls[0]
+			assert.NotEmpty(t, call.ID)
+			assert.Equal(t, "calculator", call.Name)
+			assert.JSONEq(t, `{"operation":"add","a":1,"b":2}`, string(call.Arguments))
+		})
+	}
+
+	// OpenAI：工具调用参数为 JSON 字符串，tool 消息携带 tool_call_id
+	openai := bodies["openai"]
+	assert.Equal(t, "calculator", openai["tools"].([]interface{})[0].(map[string]interface{})["function"].(map[string]interface{})["name"])
+	messages := openai["messages"].([]interface{})
+	toolCall := messages[1].(map[string]interface{})["tool_calls"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, `{"operation":"add","a":3,"b":4}`, toolCall["function"].(map[string]interface{})["arguments"])
+	assert.Equal(t, "call_1", messages[2].(map[string]interface{})["tool_call_id"])
+
+	// Anthropic：工具调用为 tool_use 块，结果为 user 消息中的 tool_result 块
+	anthropic := bodies["anthropic"]
+	assert.Contains(t, anthropic["tools"].([]interface{})[0], "input_schema")
+	messages = anthropic["messages"].([]interface{})
+	require.Len(t, messages, 3)
+	toolUse := messages[1].(map[string]interface{})["content"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "tool_use", toolUse["type"])
+	assert.Equal(t, map[string]interface{}{"operation": "add", "a": 3.0, "b": 4.0}, toolUse["input"])
+	toolResult := messages[2].(map[string]interface{})
+	assert.Equal(t, "user", toolResult["role"])
+	assert.Equal(t, "call_1", toolResult["content"].([]interface{})[0].(map[string]interface{})["tool_use_id"])
+
+	// Ollama：工具调用参数为 JSON 对象
+	messages = bodies["ollama"]["messages"].([]interface{})
+	toolCall = messages[1].(map[string]interface{})["tool_calls"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"operation": "add", "a": 3.0, "b": 4.0}, toolCall["function"].(map[string]interface{})["arguments"])
+	assert.Equal(t, "tool", messages[2].(map[string]interface{})["role"])
+}
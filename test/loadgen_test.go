@@ -0,0 +1,112 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/loadgen"
+	"Weave-Toolkit/internal/mcp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// calculatorArgs 压测使用的计算器参数
+var calculatorArgs = json.RawMessage(`{"operation":"add","a":1,"b":2}`)
+
+// newTestServer 在进程内启动 MCP 服务器，configure 可调整默认配置，返回 /mcp 端点地址
+func newTestServer(tb testing.TB, configure func(cfg *config.Config)) string {
+	cfg := &config.Config{
+		LogLevel:       "error",
+		MaxConnections: 1024,
+		ToolTimeout:    30 * time.Second,
+		JobStoreDir:    tb.TempDir(),
+		ToolConfig:     *newTestToolConfig(),
+	}
+	if configure != nil {
+		configure(cfg)
+	}
+	srv, err := mcp.NewServer(cfg, newTestLogger(tb))
+	require.NoError(tb, err)
+	httpSrv := httptest.NewServer(srv.Handler())
+	tb.Cleanup(httpSrv.Close)
+	return httpSrv.URL + "/mcp"
+}
+
+func TestLoadgen(t *testing.T) {
+	url := newTestServer(t, nil)
+
+	for _, stream := range []bool{false, true} {
+		report, err := loadgen.Run(context.Background(), loadgen.Options{
+			URL:         url,
+			Tool:        "calculator",
+			Arguments:   calculatorArgs,
+			Stream:      stream,
+			Concurrency: 4,
+			Requests:    40,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 40, report.Requests)
+		assert.Zero(t, report.Errors, report.FirstError)
+		assert.LessOrEqual(t, report.Percentile(0.5), report.Percentile(0.99))
+		assert.LessOrEqual(t, report.Percentile(0.99), report.Percentile(1))
+		if stream {
+			assert.Greater(t, report.FirstEventPercentile(0.5), time.Duration(0))
+		}
+	}
+
+	// 调用失败计入错误数
+	report, err := loadgen.Run(context.Background(), loadgen.Options{URL: url, Tool: "missing", Requests: 3})
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Errors)
+	assert.NotEmpty(t, report.FirstError)
+
+	// 按时长运行时在到期后结束
+	report, err = loadgen.Run(context.Background(), loadgen.Options{
+		URL: url, Tool: "calculator", Arguments: calculatorArgs, Concurrency: 2, Duration: 100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.Greater(t, report.Requests, 0)
+	assert.Less(t, report.Elapsed, time.Second)
+}
+
+// benchmarkServer 以 GOMAXPROCS 的 4 倍并发发送 b.N 个请求，报告吞吐量与延迟分位数；
+// -benchmem 的分配数同时包含客户端与服务器
+func benchmarkServer(b *testing.B, stream bool) {
+	url := newTestServer(b, nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	report, err := loadgen.Run(context.Background(), loadgen.Options{
+		URL:         url,
+		Tool:        "calculator",
+		Arguments:   calculatorArgs,
+		Stream:      stream,
+		Concurrency: runtime.GOMAXPROCS(0) * 4,
+		Requests:    b.N,
+	})
+	b.StopTimer()
+	require.NoError(b, err)
+	if report.Errors > 0 {
+		b.Fatalf("%d requests failed: %s", report.Errors, report.FirstError)
+	}
+
+	b.ReportMetric(report.Throughput(), "req/s")
+	b.ReportMetric(float64(report.Percentile(0.5).Microseconds()), "p50-us")
+	b.ReportMetric(float64(report.Percentile(0.99).Microseconds()), "p99-us")
+}
+
+// BenchmarkServerToolsCall 并发 tools/call 请求
+func BenchmarkServerToolsCall(b *testing.B) {
+	benchmarkServer(b, false)
+}
+
+// BenchmarkServerToolsCallStream 并发 SSE 流式 tools/call 请求
+func BenchmarkServerToolsCallStream(b *testing.B) {
+	benchmarkServer(b, true)
+}
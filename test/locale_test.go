@@ -0,0 +1,59 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocaleFormatter(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		locale   string
+		number   string
+		date     string
+		dateTime string
+	}{
+		{"", "1,234,567.5", "Mar 5, 2024", "Mar 5, 2024, 2:30 PM"},
+		{"de-DE", "1.234.567,5", "05.03.2024", "05.03.2024, 14:30"},
+		{"fr-FR", "1 234 567,5", "05/03/2024", "05/03/2024 14:30"},
+		{"zh-CN", "1,234,567.5", "2024年3月5日", "2024年3月5日 14:30"},
+		{"en-GB", "1,234,567.5", "5 Mar 2024", "5 Mar 2024, 14:30"},
+		{"de-AT;q=0.9, en;q=0.5", "1 234 567,5", "05.03.2024", "05.03.2024, 14:30"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.locale, func(t *testing.T) {
+			formatter := tools.FormatterFromContext(tools.WithLocale(context.Background(), tc.locale))
+			assert.Equal(t, tc.number, formatter.Number(1234567.5))
+			assert.Equal(t, tc.date, formatter.Date(date))
+			assert.Equal(t, tc.dateTime, formatter.DateTime(date))
+		})
+	}
+
+	t.Run("无法解析的区域设置使用默认值", func(t *testing.T) {
+		ctx := tools.WithLocale(context.Background(), "not a locale!")
+		assert.Equal(t, tools.DefaultLocale, tools.LocaleFromContext(ctx))
+	})
+}
+
+func TestCalculatorLocale(t *testing.T) {
+	args, _ := json.Marshal(tools.CalculatorArgs{Operation: "multiply", A: 1234.5, B: 1000})
+	ctx := tools.WithLocale(context.Background(), "de-DE")
+
+	raw, err := (&tools.CalculatorTool{}).Execute(ctx, args)
+	require.NoError(t, err)
+
+	var result tools.CalculatorResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	assert.Equal(t, 1234500.0, result.Result)
+	assert.Equal(t, "1.234.500", result.Formatted)
+	assert.Equal(t, "de-DE", result.Locale)
+}
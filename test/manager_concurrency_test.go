@@ -0,0 +1,173 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingTool 阻塞直到 release 关闭或上下文取消的测试工具
+type blockingTool struct {
+	name    string
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func newBlockingTool(name string) *blockingTool {
+	return &blockingTool{
+		name:    name,
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+}
+
+func (bt *blockingTool) Name() string                 { return bt.name }
+func (bt *blockingTool) Description() string          { return "blocks until released" }
+func (bt *blockingTool) Category() tools.ToolCategory { return tools.CategoryUtility }
+
+func (bt *blockingTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	bt.once.Do(func() { close(bt.started) })
+	select {
+	case <-bt.release:
+		return json.RawMessage(`{"ok":true}`), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// sleepTool 固定耗时的测试工具
+type sleepTool struct {
+	delay time.Duration
+}
+
+func (st *sleepTool) Name() string                 { return "sleep" }
+func (st *sleepTool) Description() string          { return "sleeps for a fixed delay" }
+func (st *sleepTool) Category() tools.ToolCategory { return tools.CategoryUtility }
+
+func (st *sleepTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	select {
+	case <-time.After(st.delay):
+		return json.RawMessage(`{}`), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestConfigChangeNotBlockedBySlowTool(t *testing.T) {
+	tm := tools.NewToolManager(newTestLogger(t), newTestToolConfig())
+	tool := newBlockingTool("blocking")
+	require.NoError(t, tm.RegisterTool(tool))
+
+	go tm.CallTool(context.Background(), "blocking", json.RawMessage(`{}`))
+	<-tool.started
+	defer close(tool.release)
+
+	// 工具执行期间修改配置不应被阻塞
+	done := make(chan struct{})
+	go func() {
+		tm.DisableCategory(tools.CategoryMath)
+		tm.EnableCategory(tools.CategoryMath)
+		tm.RegisterTool(&tools.CalculatorTool{})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("config change blocked by running tool")
+	}
+}
+
+func TestWorkerPoolBoundsConcurrency(t *testing.T) {
+	cfg := newTestToolConfig()
+	cfg.Global.MaxConcurrentCalls = 1
+
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	tool := newBlockingTool("blocking")
+	require.NoError(t, tm.RegisterTool(tool))
+	tm.RegisterAllTools()
+
+	go tm.CallTool(context.Background(), "blocking", json.RawMessage(`{}`))
+	<-tool.started
+
+	stats := tm.PoolStats()
+	assert.Equal(t, 1, stats["in_use"])
+
+	// 池已满时调用在上下文超时后失败
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	args, _ := json.Marshal(tools.CalculatorArgs{Operation: "add", A: 1, B: 1})
+	_, err := tm.CallTool(ctx, "calculator", args)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "worker pool saturated")
+
+	// 槽位释放后可继续调用
+	close(tool.release)
+	require.Eventually(t, func() bool {
+		return tm.PoolStats()["in_use"] == 0
+	}, time.Second, 5*time.Millisecond)
+
+	_, err = tm.CallTool(context.Background(), "calculator", args)
+	assert.NoError(t, err)
+}
+
+// BenchmarkCallToolParallel 并发调用吞吐
+func BenchmarkCallToolParallel(b *testing.B) {
+	tm := tools.NewToolManager(newTestLogger(b), newTestToolConfig())
+	tm.RegisterAllTools()
+	args, _ := json.Marshal(tools.CalculatorArgs{Operation: "add", A: 1, B: 2})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := tm.CallTool(context.Background(), "calculator", args); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkConfigChangeUnderLoad 慢工具持续执行时修改分类配置的延迟
+//
+// 执行期间持有读锁时每次配置变更需等待正在执行的慢调用结束（约为工具耗时），
+// 执行前释放锁后配置变更耗时与负载无关。
+func BenchmarkConfigChangeUnderLoad(b *testing.B) {
+	tm := tools.NewToolManager(newTestLogger(b), newTestToolConfig())
+	require.NoError(b, tm.RegisterTool(&sleepTool{delay: 5 * time.Millisecond}))
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					tm.CallTool(context.Background(), "sleep", json.RawMessage(`{}`))
+				}
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tm.UpdateCategoryConfig(tools.CategoryMath, tools.CategoryConfig{Enabled: true, MaxTools: 10})
+	}
+	b.StopTimer()
+
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/manifest"
+	"Weave-Toolkit/internal/schema"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestOpenAPI(t *testing.T) {
+	tm := tools.NewToolManager(newTestLogger(t), newTestToolConfig())
+	tm.RegisterAllTools()
+	require.NoError(t, tm.DisableTool("calculator"))
+	enabled := tm.GetTools()
+
+	doc := manifest.OpenAPI(enabled, manifest.Info{Title: "Weave-Toolkit", Version: "1.0.0", ServerURL: "https://tools.example.com"})
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "3.1.0", decoded["openapi"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"url": "https://tools.example.com"}}, decoded["servers"])
+
+	// 只导出启用的工具，参数 Schema 缺少说明时使用工具说明
+	schemas := decoded["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	assert.Len(t, schemas, len(enabled))
+	assert.NotContains(t, schemas, "calculator")
+	crypto := schemas["crypto"].(map[string]interface{})
+	assert.Equal(t, "object", crypto["type"])
+	assert.Equal(t, false, crypto["additionalProperties"])
+	assert.Contains(t, crypto["description"], "HMAC")
+	assert.Empty(t, decoded["paths"])
+	assert.NotContains(t, decoded, "security")
+
+	// 启用 REST 桥接时每个工具对应一个 POST 端点，请求体引用其参数 Schema
+	doc = manifest.OpenAPI(enabled, manifest.Info{Title: "Weave-Toolkit", Version: "1.0.0", PathPrefix: "/api/tools", BearerAuth: true})
+	require.Len(t, doc.Paths, len(enabled))
+	data, err = json.Marshal(doc.Paths["/api/tools/crypto"])
+	require.NoError(t, err)
+	var operation struct {
+		Post struct {
+			OperationID string   `json:"operationId"`
+			Tags        []string `json:"tags"`
+			RequestBody struct {
+				Content map[string]struct {
+					Schema map[string]string `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]interface{} `json:"responses"`
+		} `json:"post"`
+	}
+	require.NoError(t, json.Unmarshal(data, &operation))
+	assert.Equal(t, "crypto", operation.Post.OperationID)
+	assert.Equal(t, []string{"utility"}, operation.Post.Tags)
+	assert.Equal(t, "#/components/schemas/crypto", operation.Post.RequestBody.Content["application/json"].Schema["$ref"])
+	assert.Contains(t, operation.Post.Responses, "422")
+	assert.Equal(t, []map[string][]string{{"bearerAuth": {}}}, doc.Security)
+}
+
+func TestManifestOpenAI(t *testing.T) {
+	toolInfos := []tools.ToolInfo{
+		tools.NewToolInfo(tools.NewCryptoTool(config.CryptoConfig{}), true),
+		{Name: "bare", Description: "Tool without a schema", Category: tools.CategoryUtility, Enabled: true},
+	}
+
+	functions := manifest.OpenAI(toolInfos)
+	require.Len(t, functions, 2)
+	assert.Equal(t, "bare", functions[0].Function.Name)
+	assert.Equal(t, "function", functions[0].Type)
+
+	data, err := json.Marshal(functions[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"function","function":{"name":"bare","description":"Tool without a schema","parameters":{"type":"object"}}}`, string(data))
+
+	crypto := functions[1].Function
+	assert.Equal(t, "crypto", crypto.Name)
+	assert.Equal(t, []string{"op"}, crypto.Parameters.Required)
+	assert.NoError(t, crypto.Parameters.ValidateJSON(json.RawMessage(`{"op":"random"}`)))
+}
+
+func TestManifestAgents(t *testing.T) {
+	toolInfos := []tools.ToolInfo{
+		tools.NewToolInfo(tools.NewLLMTool(config.LLMConfig{}), true),
+		{Name: "bare", Description: "Tool without a schema", Category: tools.CategoryUtility, Enabled: true},
+	}
+
+	agentTools := manifest.Agents(toolInfos)
+	require.Len(t, agentTools, 2)
+
+	// 未声明参数的工具无法使用严格模式，parameters 为原始 Schema
+	bare := agentTools[0]
+	assert.Equal(t, "bare", bare.Name)
+	assert.Equal(t, "function", bare.Type)
+	assert.False(t, bare.Strict)
+	assert.Equal(t, bare.InputSchema, bare.Parameters)
+
+	llmTool := agentTools[1]
+	require.True(t, llmTool.Strict)
+	data, err := json.Marshal(llmTool.Parameters)
+	require.NoError(t, err)
+	var parameters struct {
+		Required             []string                          `json:"required"`
+		AdditionalProperties bool                              `json:"additionalProperties"`
+		Properties           map[string]map[string]interface{} `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(data, &parameters))
+	assert.False(t, parameters.AdditionalProperties)
+	assert.Len(t, parameters.Required, len(parameters.Properties))
+	assert.Equal(t, []interface{}{"string", "null"}, parameters.Properties["prompt"]["type"])
+	assert.NotContains(t, parameters.Properties["prompt"], "minLength")
+	messageItems := parameters.Properties["messages"]["items"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"content", "role"}, messageItems["required"])
+	assert.Equal(t, "object", llmTool.InputSchema.Type)
+
+	// 按严格模式填充的 null 可选参数仍能通过工具参数校验
+	assert.NoError(t, llmTool.InputSchema.ValidateJSON(json.RawMessage(`{"prompt":"Hi","system":null,"messages":null,"provider":null,"model":null,"temperature":null,"max_tokens":null}`)))
+
+	freeForm := schema.Object(map[string]*schema.Schema{"data": {Description: "Any JSON value"}}, "data").Closed()
+	_, ok := manifest.StrictSchema(freeForm)
+	assert.False(t, ok)
+
+	optionalEnum := schema.Object(map[string]*schema.Schema{"mode": {Type: schema.TypeString, Enum: []interface{}{"a", "b"}}}).Closed()
+	strict, ok := manifest.StrictSchema(optionalEnum)
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{"a", "b", nil}, strict["properties"].(map[string]interface{})["mode"].(map[string]interface{})["enum"])
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/internal/metering"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeteringRollups(t *testing.T) {
+	dir := t.TempDir()
+	meter := metering.New(metering.Config{Dir: dir, FlushInterval: time.Hour}, newTestLogger(t))
+	require.NoError(t, meter.Start())
+
+	day1 := time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC)
+	day2 := day1.Add(time.Hour)
+	ctx := metering.WithAPIKey(context.Background(), "secret-key")
+	result := &tools.ToolCallResult{Content: []tools.ToolCallContent{{Type: "text", Text: "ok"}}}
+	resultSize, _ := json.Marshal(result)
+
+	meter.Observe(ctx, tools.CallRecord{Tool: "calculator", Tenant: "team-a", Arguments: json.RawMessage(`{"a":1}`), Result: result, Status: tools.CallStatusSuccess, StartedAt: day1, Duration: 1500 * time.Millisecond})
+	meter.Observe(ctx, tools.CallRecord{Tool: "calculator", Tenant: "team-a", Status: tools.CallStatusError, StartedAt: day1, Duration: 500 * time.Millisecond})
+	meter.Observe(context.Background(), tools.CallRecord{Tool: "crypto", Status: tools.CallStatusSuccess, StartedAt: day2})
+	require.NoError(t, meter.Close())
+
+	// 重新加载持久化的每日汇总后继续累计
+	meter = metering.New(metering.Config{Dir: dir}, newTestLogger(t))
+	require.NoError(t, meter.Start())
+	defer meter.Close()
+
+	rollups := meter.Query(metering.Filter{})
+	require.Len(t, rollups, 2)
+	teamA := rollups[0]
+	assert.Equal(t, "2026-03-01", teamA.Date)
+	assert.Equal(t, "team-a", teamA.Tenant)
+	assert.Equal(t, metering.Fingerprint("secret-key"), teamA.APIKey)
+	assert.NotContains(t, teamA.APIKey, "secret")
+	assert.Equal(t, int64(2), teamA.Calls)
+	assert.Equal(t, int64(1), teamA.Errors)
+	assert.InDelta(t, 2.0, teamA.ExecutionSeconds, 0.001)
+	assert.Equal(t, int64(7), teamA.BytesIn)
+	assert.Equal(t, int64(len(resultSize)), teamA.BytesOut)
+	assert.Equal(t, "2026-03-02", rollups[1].Date)
+	assert.Empty(t, rollups[1].APIKey)
+
+	assert.Len(t, meter.Query(metering.Filter{From: "2026-03-02", To: "2026-03-31"}), 1)
+	assert.Len(t, meter.Query(metering.Filter{Tenant: "team-a"}), 1)
+	assert.Equal(t, int64(3), metering.Total(rollups).Calls)
+
+	var buf bytes.Buffer
+	require.NoError(t, metering.WriteCSV(&buf, rollups))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{"date", "tenant", "api_key", "calls", "errors", "execution_seconds", "bytes_in", "bytes_out"}, records[0])
+	assert.Equal(t, []string{"2026-03-01", "team-a", teamA.APIKey, "2", "1", "2.000", "7"}, records[1][:7])
+}
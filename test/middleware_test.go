@@ -0,0 +1,130 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMiddlewareRouter(t *testing.T, origins string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	log := newTestLogger(t)
+
+	r := gin.New()
+	r.Use(
+		middleware.LoggingMiddleware(log),
+		middleware.RecoveryMiddleware(log),
+		middleware.RequestIDMiddleware(),
+		middleware.CORSMiddleware(origins),
+	)
+	group := r.Group("/mcp", middleware.APIKeyMiddleware("secret"))
+	group.POST("", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	return r
+}
+
+func TestMiddlewareChain(t *testing.T) {
+	serve := func(r *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("panic 返回 500", func(t *testing.T) {
+		w := serve(newMiddlewareRouter(t, ""), httptest.NewRequest(http.MethodGet, "/panic", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.JSONEq(t, `{"error":"internal server error"}`, w.Body.String())
+		assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("预检请求不经过鉴权", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/mcp", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+		w := serve(newMiddlewareRouter(t, "https://app.example.com"), req)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Mcp-Session-Id")
+	})
+
+	t.Run("未允许的来源", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/mcp", nil)
+		req.Header.Set("Origin", "https://evil.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+		w := serve(newMiddlewareRouter(t, "https://app.example.com"), req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("鉴权", func(t *testing.T) {
+		r := newMiddlewareRouter(t, "*")
+
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		w := serve(r, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+
+		req = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.Header.Set("X-API-Key", "secret")
+		assert.Equal(t, http.StatusOK, serve(r, req).Code)
+	})
+}
+
+func TestAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	log, err := logger.NewLogger(dir, "info")
+	require.NoError(t, err)
+	defer log.Close()
+	defer log.SetLevel("error")
+
+	r := gin.New()
+	r.Use(middleware.RequestIDMiddleware(), middleware.AccessLogMiddleware(log, middleware.AccessLogJSON))
+	r.POST("/mcp", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		middleware.SetMCPRequestInfo(c, "tools/call", "calculator", "test-client")
+		c.String(http.StatusOK, "%d", len(body))
+	})
+
+	payload := `{"jsonrpc":"2.0","method":"tools/call"}`
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(payload)))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+
+	var entry map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if entry["message"] == "HTTP request" {
+			break
+		}
+	}
+	assert.Equal(t, "tools/call", entry["rpc_method"])
+	assert.Equal(t, "calculator", entry["tool"])
+	assert.Equal(t, "test-client", entry["client"])
+	assert.Equal(t, float64(len(payload)), entry["bytes_in"])
+	assert.Equal(t, float64(2), entry["bytes_out"])
+	assert.NotEmpty(t, entry["request_id"])
+}
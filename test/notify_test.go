@@ -0,0 +1,123 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeNotifyServer 模拟 Slack、Discord webhook 与 Telegram Bot API，记录收到的请求体
+func newFakeNotifyServer(t *testing.T) (*httptest.Server, map[string]map[string]interface{}) {
+	t.Helper()
+	var mu sync.Mutex
+	received := make(map[string]map[string]interface{})
+	record := func(name string, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		received[name] = body
+		mu.Unlock()
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slack/hook", func(w http.ResponseWriter, r *http.Request) {
+		record("slack", r)
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/discord/hook", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("wait"))
+		record("discord", r)
+		w.Write([]byte(`{"id":"1234"}`))
+	})
+	mux.HandleFunc("/bot123:secret/sendMessage", func(w http.ResponseWriter, r *http.Request) {
+		record("telegram", r)
+		w.Write([]byte(`{"ok":true,"result":{"message_id":42}}`))
+	})
+	mux.HandleFunc("/bot999:revoked/sendMessage", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"ok":false,"description":"Unauthorized"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func newTestNotifyTool(serverURL string) *tools.NotifyTool {
+	return tools.NewNotifyTool(config.NotifyConfig{
+		Default: "ops",
+		Channels: map[string]config.NotifyChannelConfig{
+			"ops":     {Type: "slack", WebhookURL: serverURL + "/slack/hook", Username: "weave"},
+			"dev":     {Type: "discord", WebhookURL: serverURL + "/discord/hook"},
+			"oncall":  {Type: "telegram", URL: serverURL, Token: "123:secret", ChatID: "-100"},
+			"revoked": {Type: "telegram", URL: serverURL, Token: "999:revoked", ChatID: "-100"},
+		},
+	})
+}
+
+func TestNotifyChannels(t *testing.T) {
+	server, received := newFakeNotifyServer(t)
+	tool := newTestNotifyTool(server.URL)
+
+	raw, err := tool.Execute(context.Background(), json.RawMessage(`{"channels":["ops","dev","oncall","ops"],"title":"Deploy <done>","message":"Build 42 & tests passed","level":"success","url":"https://ci.example.com/42"}`))
+	require.NoError(t, err)
+	var result tools.NotifyResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	assert.Equal(t, 3, result.Sent)
+	assert.Equal(t, 0, result.Failed)
+	require.Len(t, result.Deliveries, 3)
+	assert.Equal(t, "1234", result.Deliveries[1].MessageID)
+	assert.Equal(t, "42", result.Deliveries[2].MessageID)
+
+	slack := received["slack"]
+	assert.Equal(t, "weave", slack["username"])
+	attachment := slack["attachments"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "#27AE60", attachment["color"])
+	assert.Equal(t, "Deploy <done>", attachment["title"])
+	assert.Equal(t, "https://ci.example.com/42", attachment["title_link"])
+
+	embed := received["discord"]["embeds"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, float64(0x27AE60), embed["color"])
+	assert.Equal(t, "Build 42 & tests passed", embed["description"])
+
+	telegram := received["telegram"]
+	assert.Equal(t, "-100", telegram["chat_id"])
+	assert.Equal(t, "HTML", telegram["parse_mode"])
+	assert.Equal(t, "✅ <a href=\"https://ci.example.com/42\"><b>Deploy &lt;done&gt;</b></a>\nBuild 42 &amp; tests passed", telegram["text"])
+}
+
+func TestNotifyFailures(t *testing.T) {
+	server, _ := newFakeNotifyServer(t)
+	tool := newTestNotifyTool(server.URL)
+
+	// 部分失败时返回结果，失败原因中不包含 token
+	raw, err := tool.Execute(context.Background(), json.RawMessage(`{"channels":["oncall","revoked"],"message":"disk full","level":"error"}`))
+	require.NoError(t, err)
+	var result tools.NotifyResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	assert.Equal(t, 1, result.Sent)
+	assert.Equal(t, 1, result.Failed)
+	assert.Contains(t, result.Deliveries[1].Error, "401")
+	assert.NotContains(t, result.Deliveries[1].Error, "999:revoked")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"channel":"revoked","message":"disk full"}`))
+	assert.ErrorContains(t, err, "channel revoked")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"channel":"missing","message":"hi"}`))
+	assert.ErrorContains(t, err, "unknown notification channel")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"message":"hi","url":"javascript:alert(1)"}`))
+	assert.ErrorContains(t, err, "url")
+
+	unconfigured := tools.NewNotifyTool(config.NotifyConfig{})
+	_, err = unconfigured.Execute(context.Background(), json.RawMessage(`{"message":"hi"}`))
+	assert.ErrorContains(t, err, "no notification channel")
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOPAAuthorization(t *testing.T) {
+	var inputs []tools.OPAInput
+	opa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/data/weave/authz" {
+			w.Write([]byte(`{}`))
+			return
+		}
+		var body struct {
+			Input tools.OPAInput `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		inputs = append(inputs, body.Input)
+		var args struct {
+			Operation string `json:"operation"`
+		}
+		json.Unmarshal(body.Input.Arguments, &args)
+		switch {
+		case body.Input.Client != "ci":
+			w.Write([]byte(`{"result":false}`))
+		case args.Operation == "divide":
+			w.Write([]byte(`{"result":{"allow":false,"reason":"division is not allowed for ci"}}`))
+		default:
+			w.Write([]byte(`{"result":{"allow":true}}`))
+		}
+	}))
+	defer opa.Close()
+
+	cfg := newTestToolConfig()
+	cfg.OPA = config.OPAConfig{URL: opa.URL, Path: "/weave/authz"}
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	tm.RegisterAllTools()
+	ci := tools.WithClient(context.Background(), "ci")
+
+	_, err := tm.CallTool(ci, "calculator", json.RawMessage(`{"operation":"add","a":1,"b":2}`))
+	require.NoError(t, err)
+	require.Len(t, inputs, 1)
+	assert.Equal(t, "calculator", inputs[0].Tool)
+	assert.Equal(t, "ci", inputs[0].Client)
+	assert.JSONEq(t, `{"operation":"add","a":1,"b":2}`, string(inputs[0].Arguments))
+	assert.False(t, inputs[0].Time.IsZero())
+
+	// 按参数拒绝时错误中包含原因
+	_, err = tm.CallTool(ci, "calculator", json.RawMessage(`{"operation":"divide","a":1,"b":2}`))
+	assert.True(t, errors.Is(err, tools.ErrNotAuthorized))
+	assert.Contains(t, err.Error(), "division is not allowed for ci")
+
+	// 其他客户端被拒绝，流式调用同样检查
+	_, err = tm.CallTool(context.Background(), "calculator", json.RawMessage(`{"operation":"add","a":1,"b":2}`))
+	assert.True(t, errors.Is(err, tools.ErrNotAuthorized))
+	_, err = tm.CallToolStream(tools.WithClient(context.Background(), "web"), "stream_text_processor", json.RawMessage(`{"text":"hi","operation":"reverse"}`), nil)
+	assert.True(t, errors.Is(err, tools.ErrNotAuthorized))
+
+	// 决策未定义时拒绝
+	undefined := tools.NewOPAAuthorizer(config.OPAConfig{URL: opa.URL, Path: "weave/missing"})
+	unavailable, err := undefined.Authorize(ci, tools.OPAInput{Tool: "calculator"})
+	assert.NoError(t, unavailable)
+	assert.True(t, errors.Is(err, tools.ErrNotAuthorized))
+
+	// OPA 不可用时默认拒绝，fail_open 时放行
+	opa.Close()
+	unavailable, err = tools.NewOPAAuthorizer(cfg.OPA).Authorize(ci, tools.OPAInput{Tool: "calculator"})
+	assert.Error(t, unavailable)
+	assert.True(t, errors.Is(err, tools.ErrNotAuthorized))
+	unavailable, err = tools.NewOPAAuthorizer(config.OPAConfig{URL: opa.URL, Path: "weave/authz", FailOpen: true}).Authorize(ci, tools.OPAInput{Tool: "calculator"})
+	assert.Error(t, unavailable)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,209 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logEntry recordingLogger 记录的一条日志
+type logEntry struct {
+	level   string
+	message string
+	fields  map[string]interface{}
+}
+
+// recordingLogger 记录收到的日志，实现 mcp.Logger
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) Debug(msg string, args ...any) { l.record("debug", msg, args) }
+func (l *recordingLogger) Info(msg string, args ...any)  { l.record("info", msg, args) }
+func (l *recordingLogger) Warn(msg string, args ...any)  { l.record("warn", msg, args) }
+func (l *recordingLogger) Error(msg string, args ...any) { l.record("error", msg, args) }
+
+func (l *recordingLogger) record(level, msg string, args []any) {
+	fields := make(map[string]interface{})
+	for i := 0; i+1 < len(args); i += 2 {
+		fields[fmt.Sprint(args[i])] = args[i+1]
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level: level, message: msg, fields: fields})
+}
+
+// find 查找第一条指定消息的日志
+func (l *recordingLogger) find(message string) (logEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, entry := range l.entries {
+		if entry.message == message {
+			return entry, true
+		}
+	}
+	return logEntry{}, false
+}
+
+// withDebugLevel 临时将全局日志级别设为 debug，测试结束后恢复
+func withDebugLevel(t *testing.T, log *logger.Logger) {
+	previous := log.GetLevel()
+	require.NoError(t, log.SetLevel("debug"))
+	t.Cleanup(func() { log.SetLevel(previous) })
+}
+
+func TestLoggerBackend(t *testing.T) {
+	backend := &recordingLogger{}
+	log := logger.NewWithBackend(backend)
+	withDebugLevel(t, log)
+
+	log.Info().Str("tool", "calc").Int("attempt", 2).Float64("ratio", 0.5).Msg("Tool registered")
+	log.Warn().Err(errors.New("boom")).Msg("Something failed")
+	log.Debug().RawJSON("args", []byte(`{"a":1}`)).Msg("Details")
+
+	entry, ok := backend.find("Tool registered")
+	require.True(t, ok)
+	assert.Equal(t, "info", entry.level)
+	assert.Equal(t, map[string]interface{}{"tool": "calc", "attempt": int64(2), "ratio": 0.5}, entry.fields)
+
+	entry, ok = backend.find("Something failed")
+	require.True(t, ok)
+	assert.Equal(t, "warn", entry.level)
+	assert.Equal(t, "boom", entry.fields["error"])
+
+	entry, ok = backend.find("Details")
+	require.True(t, ok)
+	assert.Equal(t, "debug", entry.level)
+	assert.Equal(t, map[string]interface{}{"a": int64(1)}, entry.fields["args"])
+}
+
+func TestLoggerSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: logger.LevelTrace})
+	log := logger.NewWithHandler(handler)
+	previous := log.GetLevel()
+	require.NoError(t, log.SetLevel("trace"))
+	t.Cleanup(func() { log.SetLevel(previous) })
+
+	log.Trace().Str("tool", "calc").RawJSON("args", []byte(`{"b":{"c":true},"a":1}`)).Msg("Tool args")
+	log.Error().Int("attempt", 3).Err(errors.New("boom")).Msg("Tool failed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var trace map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &trace))
+	assert.Equal(t, "DEBUG-4", trace["level"])
+	assert.Equal(t, "Tool args", trace["msg"])
+	assert.Equal(t, "calc", trace["tool"])
+	assert.Equal(t, map[string]interface{}{"a": float64(1), "b": map[string]interface{}{"c": true}}, trace["args"])
+
+	var failure map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &failure))
+	assert.Equal(t, "ERROR", failure["level"])
+	assert.Equal(t, float64(3), failure["attempt"])
+	assert.Equal(t, "boom", failure["error"])
+
+	// handler 未启用的级别不输出
+	buf.Reset()
+	quiet := logger.NewWithHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	quiet.Info().Msg("dropped")
+	assert.Empty(t, buf.String())
+}
+
+func TestLoggerBackendSelection(t *testing.T) {
+	dir := t.TempDir()
+	log, err := logger.New(dir, "error", logger.BackendSlog)
+	require.NoError(t, err)
+	log.Error().Str("tool", "calc").Msg("Written by slog")
+	require.NoError(t, log.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "mcp-*.log"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(bytes.TrimSpace(data), &entry))
+	assert.Equal(t, "Written by slog", entry["msg"])
+	assert.Equal(t, "calc", entry["tool"])
+
+	log, err = logger.New(t.TempDir(), "error", "")
+	require.NoError(t, err)
+	require.NoError(t, log.Close())
+
+	_, err = logger.New(t.TempDir(), "error", "logrus")
+	assert.EqualError(t, err, `unknown log backend: "logrus"`)
+}
+
+func TestServerOptions(t *testing.T) {
+	t.Run("最小配置与自定义日志", func(t *testing.T) {
+		backend := &recordingLogger{}
+		withDebugLevel(t, logger.NewWithBackend(backend))
+		srv, err := mcp.New(
+			mcp.WithServerConfig(mcp.ServerConfig{MaxConnections: 4, JobStoreDir: t.TempDir()}),
+			mcp.WithLogger(backend),
+			mcp.WithTools(testkit.NewMockTool("lookup")),
+		)
+		require.NoError(t, err)
+
+		tools := srv.ToolManager().GetTools()
+		require.Len(t, tools, 1)
+		assert.Equal(t, "lookup", tools[0].Name)
+
+		entry, ok := backend.find("Tool registered")
+		require.True(t, ok, "server logs are routed to the supplied logger")
+		assert.Equal(t, "lookup", entry.fields["tool"])
+	})
+
+	t.Run("配置来源", func(t *testing.T) {
+		loads := 0
+		source := mcp.ConfigSourceFunc(func() (*config.Config, error) {
+			loads++
+			return mcp.ServerConfig{JobStoreDir: t.TempDir()}.Config(), nil
+		})
+		_, err := mcp.New(mcp.WithConfigSource(source), mcp.WithLogger(&recordingLogger{}), mcp.WithTools())
+		require.NoError(t, err)
+		assert.Equal(t, 1, loads)
+
+		failing := mcp.ConfigSourceFunc(func() (*config.Config, error) {
+			return nil, errors.New("vault unavailable")
+		})
+		_, err = mcp.New(mcp.WithConfigSource(failing))
+		assert.EqualError(t, err, "vault unavailable")
+	})
+
+	t.Run("完整配置优先于配置来源", func(t *testing.T) {
+		source := mcp.ConfigSourceFunc(func() (*config.Config, error) {
+			t.Fatal("config source must not be used when a config is supplied")
+			return nil, nil
+		})
+		srv, err := mcp.New(
+			mcp.WithConfigSource(source),
+			mcp.WithConfig(testkit.Config(t)),
+			mcp.WithLogManager(testkit.Logger(t)),
+			mcp.WithTools(testkit.NewMockTool("echo").Returns("ok")),
+		)
+		require.NoError(t, err)
+		result, err := srv.ToolManager().CallTool(context.Background(), "echo", []byte(`{}`))
+		require.NoError(t, err)
+		assert.Equal(t, `"ok"`, result.Content[0].Text)
+	})
+}
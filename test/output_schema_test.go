@@ -0,0 +1,94 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/schema"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputSchema(t *testing.T) {
+	weather := schema.Object(map[string]*schema.Schema{
+		"city":        {Type: schema.TypeString},
+		"temperature": {Type: schema.TypeNumber},
+	}, "city", "temperature")
+	newTool := func() *testkit.MockTool {
+		return testkit.NewMockTool("weather").WithOutputSchema(weather).Returns(map[string]interface{}{"city": "Berlin", "temperature": 21.5})
+	}
+
+	t.Run("tools/list 公开 outputSchema，结果以 structuredContent 返回", func(t *testing.T) {
+		srv := testkit.NewServer(t, nil, newTool(), testkit.NewMockTool("plain").Returns("ok"))
+		resp, err := srv.Call(mcp.MethodToolsList, nil)
+		require.NoError(t, err)
+		var list struct {
+			Tools []map[string]json.RawMessage `json:"tools"`
+		}
+		require.NoError(t, json.Unmarshal(resp.Result, &list))
+		require.Len(t, list.Tools, 2)
+		for _, tool := range list.Tools {
+			if string(tool["name"]) == `"weather"` {
+				assert.JSONEq(t, `{"type":"object","properties":{"city":{"type":"string"},"temperature":{"type":"number"}},"required":["city","temperature"]}`, string(tool["outputSchema"]))
+			} else {
+				assert.NotContains(t, tool, "outputSchema")
+			}
+		}
+
+		result, err := srv.CallTool("weather", map[string]string{})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"city":"Berlin","temperature":21.5}`, string(result.StructuredContent))
+		assert.JSONEq(t, result.Content[0].Text, string(result.StructuredContent))
+
+		result, err = srv.CallTool("plain", map[string]string{})
+		require.NoError(t, err)
+		assert.Nil(t, result.StructuredContent)
+	})
+
+	t.Run("不符合 Schema 的结果", func(t *testing.T) {
+		tool := newTool()
+		tool.Returns(map[string]interface{}{"city": "Berlin", "temperature": "warm"})
+		tm := testkit.NewToolManager(t, tool)
+
+		// 默认只记录警告并省略 structuredContent
+		result, err := tm.CallTool(context.Background(), "weather", json.RawMessage(`{}`))
+		require.NoError(t, err)
+		assert.Nil(t, result.StructuredContent)
+
+		// 开发模式下调用失败，JSON-RPC 返回内部错误
+		tm.SetStrictOutput(true)
+		_, err = tm.CallTool(context.Background(), "weather", json.RawMessage(`{}`))
+		assert.True(t, errors.Is(err, tools.ErrInvalidOutput))
+		assert.Contains(t, err.Error(), "temperature")
+		_, err = tm.CallToolStream(context.Background(), "weather", json.RawMessage(`{}`), nil)
+		assert.True(t, errors.Is(err, tools.ErrInvalidOutput))
+
+		srv := testkit.NewServer(t, func(cfg *config.Config) { cfg.DevMode = true }, tool)
+		_, err = srv.CallTool("weather", map[string]string{})
+		var rpcErr *testkit.RPCError
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, mcp.ErrorCodeInternalError, rpcErr.Code)
+	})
+
+	t.Run("个人信息掩码同样作用于 structuredContent", func(t *testing.T) {
+		contact := testkit.NewMockTool("contact").
+			WithOutputSchema(schema.Object(map[string]*schema.Schema{"email": {Type: schema.TypeString}}, "email")).
+			Returns(map[string]string{"email": "jane@example.com"})
+		cfg := testkit.ToolConfig()
+		cfg.PII = config.PIIConfig{Enabled: true, Detectors: []string{"email"}}
+		tm := tools.NewToolManager(newTestLogger(t), cfg)
+		require.NoError(t, tm.RegisterTool(contact))
+
+		result, err := tm.CallTool(context.Background(), "contact", json.RawMessage(`{}`))
+		require.NoError(t, err)
+		assert.NotContains(t, string(result.StructuredContent), "jane@example.com")
+		assert.Equal(t, result.Content[0].Text, string(result.StructuredContent))
+	})
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/internal/history"
+	"Weave-Toolkit/internal/pagination"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pageItem struct {
+	id string
+	at time.Time
+}
+
+func pageItemKey(item pageItem) pagination.Cursor {
+	return pagination.Cursor{Time: item.at, ID: item.id}
+}
+
+func TestPaginationParams(t *testing.T) {
+	t.Run("默认与上限", func(t *testing.T) {
+		params, err := pagination.ParseParams(url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, pagination.DefaultLimit, params.Limit)
+
+		params, err = pagination.ParseParams(url.Values{"limit": {"100000"}})
+		require.NoError(t, err)
+		assert.Equal(t, pagination.MaxLimit, params.Limit)
+	})
+
+	t.Run("游标往返", func(t *testing.T) {
+		cursor := pagination.Cursor{Time: time.Unix(1700000000, 123), ID: "job_1"}
+		params, err := pagination.ParseParams(url.Values{"cursor": {cursor.Encode()}})
+		require.NoError(t, err)
+		require.NotNil(t, params.Cursor)
+		assert.True(t, cursor.Time.Equal(params.Cursor.Time))
+		assert.Equal(t, "job_1", params.Cursor.ID)
+	})
+
+	t.Run("非法参数", func(t *testing.T) {
+		for _, query := range []url.Values{
+			{"cursor": {"not-a-cursor"}},
+			{"limit": {"abc"}},
+			{"since": {"yesterday"}},
+		} {
+			_, err := pagination.ParseParams(query)
+			assert.Error(t, err, query.Encode())
+		}
+	})
+}
+
+func TestPaginate(t *testing.T) {
+	base := time.Now()
+	var items []pageItem
+	for i := 0; i < 5; i++ {
+		items = append(items, pageItem{id: fmt.Sprintf("item_%d", i), at: base.Add(time.Duration(i) * time.Second)})
+	}
+	// 相同时间的记录按 ID 排序
+	items = append(items, pageItem{id: "item_5", at: items[4].at})
+
+	var seen []string
+	params := pagination.Params{Limit: 2}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10)
+
+		page := pagination.Paginate(items, params, pageItemKey)
+		for _, item := range page.Items {
+			seen = append(seen, item.id)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+
+		cursor, err := pagination.Decode(page.NextCursor)
+		require.NoError(t, err)
+		params.Cursor = cursor
+	}
+
+	assert.Equal(t, []string{"item_5", "item_4", "item_3", "item_2", "item_1", "item_0"}, seen)
+
+	t.Run("时间范围", func(t *testing.T) {
+		page := pagination.Paginate(items, pagination.Params{
+			Since: items[1].at,
+			Until: items[3].at,
+		}, pageItemKey)
+		require.Len(t, page.Items, 2)
+		assert.Equal(t, "item_2", page.Items[0].id)
+		assert.Empty(t, page.NextCursor)
+	})
+}
+
+func TestHistoryQueryPage(t *testing.T) {
+	store, err := history.Open(history.DriverSQLite, filepath.Join(t.TempDir(), "history.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	startedAt := time.Now().Truncate(time.Millisecond)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, store.Insert(context.Background(), &history.Entry{
+			Tool:      "calculator",
+			Status:    "success",
+			StartedAt: startedAt, // 相同时间依靠 ID 保证顺序稳定
+		}))
+	}
+
+	var ids []int64
+	filter := history.Filter{Limit: 2}
+	for {
+		page, err := store.QueryPage(context.Background(), filter)
+		require.NoError(t, err)
+		for _, entry := range page.Items {
+			ids = append(ids, entry.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor, err = pagination.Decode(page.NextCursor)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []int64{5, 4, 3, 2, 1}, ids)
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPIIFilter(t *testing.T) {
+	assert.Nil(t, tools.NewPIIFilter(config.PIIConfig{}))
+
+	filter := tools.NewPIIFilter(config.PIIConfig{Enabled: true, Tools: map[string]string{"audit": "flag", "trusted": "off"}})
+	text := "mail alice.smith@example.com, card 4111 1111 1111 1111, ssn 123-45-6789, " +
+		"id 11010519491231002X, tel (555) 123-4567, mobile 13812345678, order 1234567890123"
+	result := &tools.ToolCallResult{Content: []tools.ToolCallContent{{Type: "text", Text: text}}}
+	filter.Apply("scrape", result)
+	assert.Equal(t, "mail a***@example.com, card **** **** **** 1111, ssn ***-**-6789, "+
+		"id **************002X, tel (***) ***-4567, mobile *******5678, order 1234567890123", result.Content[0].Text)
+	require.NotNil(t, result.Meta)
+	assert.Equal(t, []tools.PIIFinding{
+		{Type: tools.PIIEmail, Count: 1, Masked: true},
+		{Type: tools.PIINationalID, Count: 2, Masked: true},
+		{Type: tools.PIICreditCard, Count: 1, Masked: true},
+		{Type: tools.PIIPhone, Count: 2, Masked: true},
+	}, result.Meta.PII)
+
+	// flag 原样返回并列出类型，off 不检测
+	flagged := &tools.ToolCallResult{Content: []tools.ToolCallContent{{Type: "text", Text: "bob@example.org"}}}
+	filter.Apply("audit", flagged)
+	assert.Equal(t, "bob@example.org", flagged.Content[0].Text)
+	assert.Equal(t, []tools.PIIFinding{{Type: tools.PIIEmail, Count: 1}}, flagged.Meta.PII)
+	exempt := &tools.ToolCallResult{Content: []tools.ToolCallContent{{Type: "text", Text: "bob@example.org"}}}
+	filter.Apply("trusted", exempt)
+	assert.Nil(t, exempt.Meta)
+
+	// 只启用部分检测器
+	emailOnly := tools.NewPIIFilter(config.PIIConfig{Enabled: true, Detectors: []string{"email"}})
+	assert.Equal(t, "x***@y.io 123-45-6789", emailOnly.Mask("scrape", "x@y.io 123-45-6789"))
+}
+
+func TestPIIInToolResults(t *testing.T) {
+	cfg := newTestToolConfig()
+	cfg.PII = config.PIIConfig{Enabled: true}
+	cfg.Global.MaxResultBytes = 64
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	lookup := testkit.NewMockTool("lookup").Returns(map[string]string{"email": "carol@example.com", "notes": strings.Repeat("x", 80)})
+	stream := testkit.NewMockTool("stream").Streams(time.Millisecond, "call 555-123-4567", " now").Returns("done")
+	require.NoError(t, tm.RegisterTool(lookup))
+	require.NoError(t, tm.RegisterTool(stream))
+	var records []tools.CallRecord
+	tm.AddCallObserver(func(_ context.Context, record tools.CallRecord) {
+		records = append(records, record)
+	})
+
+	// 返回给客户端、调用记录与截断后保存的完整内容都已掩码
+	result, err := tm.CallTool(context.Background(), "lookup", json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].Text, "c***@example.com")
+	assert.NotContains(t, result.Content[0].Text, "carol@")
+	require.NotNil(t, result.Meta)
+	assert.Equal(t, []tools.PIIFinding{{Type: tools.PIIEmail, Count: 1, Masked: true}}, result.Meta.PII)
+	require.Len(t, result.Meta.Truncated, 1)
+	stored, _, ok, err := tm.ReadResource(result.Meta.Truncated[0].URI + "?offset=0")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Contains(t, stored, "c***@example.com")
+	require.Len(t, records, 1)
+	assert.NotContains(t, records[0].Result.Content[0].Text, "carol@")
+
+	// 流式片段推送前掩码
+	var partials []string
+	_, err = tm.CallToolStream(context.Background(), "stream", json.RawMessage(`{}`), func(content string, index int) {
+		partials = append(partials, content)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"call ***-***-4567", " now"}, partials)
+}
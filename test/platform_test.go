@@ -0,0 +1,91 @@
+package test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"Weave-Toolkit/internal/platform"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePath(t *testing.T) {
+	t.Run("正斜杠转换为本地绝对路径", func(t *testing.T) {
+		path, err := platform.NormalizePath("data/jobs/../history.db")
+		require.NoError(t, err)
+
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(wd, "data", "history.db"), path)
+	})
+
+	t.Run("展开用户主目录", func(t *testing.T) {
+		home, err := os.UserHomeDir()
+		require.NoError(t, err)
+
+		path, err := platform.NormalizePath("~/weave/log")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(home, "weave", "log"), path)
+	})
+
+	t.Run("空路径", func(t *testing.T) {
+		_, err := platform.NormalizePath("")
+		assert.Error(t, err)
+	})
+}
+
+func TestConfine(t *testing.T) {
+	root := t.TempDir()
+
+	path, err := platform.Confine(root, "jobs/job_1.json")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "jobs", "job_1.json"), path)
+	assert.True(t, platform.IsWithin(root, path))
+
+	escapes := []string{"../outside.json", "jobs/../../outside.json", "/etc/passwd", ""}
+	if runtime.GOOS == "windows" {
+		escapes = append(escapes, `C:\Windows\win.ini`, `\\server\share\file`, "NUL", `jobs\..\..\outside.json`)
+	}
+	for _, name := range escapes {
+		_, err := platform.Confine(root, name)
+		assert.True(t, errors.Is(err, platform.ErrOutsideRoot), name)
+	}
+
+	assert.False(t, platform.IsWithin(root, filepath.Dir(root)))
+	if runtime.GOOS == "windows" {
+		// Windows 路径比较不区分大小写
+		assert.True(t, platform.IsWithin(root, filepath.Join(root, "JOBS")))
+	}
+}
+
+func TestListenUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "mcp.sock")
+
+	listener, err := platform.Listen(platform.UnixSocketPrefix + socket)
+	if !platform.SupportsUnixSockets() {
+		assert.True(t, errors.Is(err, platform.ErrUnixSocketUnsupported))
+		return
+	}
+	require.NoError(t, err)
+
+	// 模拟异常退出：关闭监听但保留 socket 文件
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+	_, err = os.Lstat(socket)
+	require.NoError(t, err)
+
+	// 遗留的 socket 文件在下次监听时被替换，普通文件不会被删除
+	listener, err = platform.Listen(platform.UnixSocketPrefix + socket)
+	require.NoError(t, err)
+	listener.Close()
+
+	regular := filepath.Join(t.TempDir(), "not-a-socket")
+	require.NoError(t, os.WriteFile(regular, []byte("data"), 0644))
+	_, err = platform.Listen(platform.UnixSocketPrefix + regular)
+	assert.Error(t, err)
+}
@@ -0,0 +1,184 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyRules(t *testing.T) {
+	cfg := newTestToolConfig()
+	cfg.Policies = config.PolicyConfig{Rules: []config.PolicyRule{
+		{Name: "no-sql-drop", Target: "arguments", Keywords: []string{"DROP TABLE"}},
+		{Name: "internal-hosts", Tools: []string{"fetch"}, Target: "result", Patterns: []string{`10\.\d+\.\d+\.\d+`}},
+		{Name: "profanity", Keywords: []string{"darn"}, Action: "annotate"},
+		{Name: "secrets", Target: "result", Patterns: []string{`sk-[A-Za-z0-9]+`}},
+	}}
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	echo := testkit.NewMockTool("echo").Handle(func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+		return args, nil
+	})
+	fetch := testkit.NewMockTool("fetch").Returns("upstream at 10.0.3.7")
+	leaky := testkit.NewMockTool("leaky").Returns("key is sk-SECRET123")
+	require.NoError(t, tm.RegisterTool(echo))
+	require.NoError(t, tm.RegisterTool(fetch))
+	require.NoError(t, tm.RegisterTool(leaky))
+	var records []tools.CallRecord
+	tm.AddCallObserver(func(_ context.Context, record tools.CallRecord) {
+		records = append(records, record)
+	})
+
+	// 参数命中拒绝规则时工具不执行
+	ctx := tools.WithClient(context.Background(), "cli")
+	_, err := tm.CallTool(ctx, "echo", json.RawMessage(`{"query":"drop table users"}`))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, tools.ErrPolicyViolation))
+	assert.Contains(t, err.Error(), "rule no-sql-drop")
+	assert.Equal(t, 0, echo.CallCount())
+
+	// 结果规则只作用于指定工具
+	_, err = tm.CallTool(ctx, "fetch", json.RawMessage(`{}`))
+	assert.True(t, errors.Is(err, tools.ErrPolicyViolation))
+	assert.Equal(t, 1, fetch.CallCount())
+	result, err := tm.CallTool(ctx, "echo", json.RawMessage(`{"text":"10.0.3.7"}`))
+	require.NoError(t, err)
+	assert.Nil(t, result.Meta)
+
+	// annotate 规则放行并在 _meta.policy 中列出
+	result, err = tm.CallTool(ctx, "echo", json.RawMessage(`{"text":"oh darn"}`))
+	require.NoError(t, err)
+	require.NotNil(t, result.Meta)
+	assert.Equal(t, []tools.PolicyFinding{
+		{Rule: "profanity", Stage: "arguments", Reason: `matched "darn"`},
+		{Rule: "profanity", Stage: "result", Reason: `matched "darn"`},
+	}, result.Meta.Policy)
+
+	// 返回给调用方的错误只含规则名，命中的内容只出现在审计记录中
+	_, err = tm.CallTool(ctx, "leaky", json.RawMessage(`{}`))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, tools.ErrPolicyViolation))
+	assert.Contains(t, err.Error(), "rule secrets")
+	assert.NotContains(t, err.Error(), "sk-SECRET123")
+
+	// 每次拒绝都有审计记录，调用记录为失败
+	audit := tm.PolicyAudit()
+	require.Len(t, audit, 3)
+	assert.Equal(t, "secrets", audit[2].Rule)
+	assert.Contains(t, audit[2].Reason, "sk-SECRET123")
+	assert.Equal(t, "echo", audit[0].Tool)
+	assert.Equal(t, "cli", audit[0].Client)
+	assert.Equal(t, "arguments", audit[0].Stage)
+	assert.Equal(t, "fetch", audit[1].Tool)
+	assert.Equal(t, "internal-hosts", audit[1].Rule)
+	assert.NotEqual(t, audit[0].ID, audit[1].ID)
+	require.Len(t, records, 5)
+	assert.Equal(t, tools.CallStatusError, records[0].Status)
+	assert.Equal(t, tools.CallStatusError, records[1].Status)
+}
+
+func TestPolicyStreamedPartials(t *testing.T) {
+	cfg := newTestToolConfig()
+	cfg.Policies = config.PolicyConfig{Rules: []config.PolicyRule{
+		{Name: "secrets", Target: "result", Patterns: []string{`sk-[A-Za-z0-9]+`}},
+	}}
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	// 密钥跨两个片段，拼接上一个片段后才能命中
+	streamer := testkit.NewMockTool("streamer").Streams(time.Millisecond, "key is sk-", "STREAMSECRET", " done").Returns("done")
+	require.NoError(t, tm.RegisterTool(streamer))
+
+	var partials []string
+	_, err := tm.CallToolStream(context.Background(), "streamer", json.RawMessage(`{}`), func(content string, _ int) {
+		partials = append(partials, content)
+	})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, tools.ErrPolicyViolation))
+	assert.Contains(t, err.Error(), "rule secrets")
+	assert.NotContains(t, err.Error(), "STREAMSECRET")
+
+	// 命中的片段与之后的片段都不推送
+	assert.Equal(t, []string{"key is sk-"}, partials)
+	audit := tm.PolicyAudit()
+	require.Len(t, audit, 1)
+	assert.Equal(t, "secrets", audit[0].Rule)
+	assert.Equal(t, "result", audit[0].Stage)
+	assert.Equal(t, "streamer", audit[0].Tool)
+}
+
+func TestPolicyEndpoint(t *testing.T) {
+	var stages []string
+	policy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stage     string          `json:"stage"`
+			Tool      string          `json:"tool"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		stages = append(stages, req.Stage)
+		switch {
+		case strings.Contains(string(req.Arguments), "secret-project"):
+			w.Write([]byte(`{"action":"block","rule":"confidential","reason":"mentions a restricted project"}`))
+		case req.Stage == "result":
+			w.Write([]byte(`{"action":"annotate","reason":"reviewed"}`))
+		default:
+			w.Write([]byte(`{"action":"allow"}`))
+		}
+	}))
+	defer policy.Close()
+
+	url := newTestServer(t, func(cfg *config.Config) {
+		cfg.APIKey = "secret"
+		cfg.ToolConfig.Policies = config.PolicyConfig{Endpoint: policy.URL}
+	})
+	baseURL := strings.TrimSuffix(url, "/mcp")
+	send := func(method, path, body string) *http.Response {
+		req, err := http.NewRequest(method, baseURL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "secret")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	reply := decodeReply(t, send(http.MethodPost, "/mcp", toolCall(1, "stream_text_processor", `{"text":"hello","operation":"reverse"}`)))
+	require.Nil(t, reply.Error)
+	assert.Contains(t, string(reply.Result), `"policy":[{"rule":"endpoint","stage":"result","reason":"reviewed"}]`)
+	assert.Equal(t, []string{"arguments", "result"}, stages)
+
+	reply = decodeReply(t, send(http.MethodPost, "/mcp", toolCall(2, "stream_text_processor", `{"text":"secret-project plans","operation":"reverse"}`)))
+	require.NotNil(t, reply.Error)
+	assert.Contains(t, reply.Error.Message, "rule confidential")
+	assert.NotContains(t, reply.Error.Message, "restricted project")
+
+	var audit struct {
+		Entries []tools.PolicyAuditEntry `json:"entries"`
+	}
+	require.NoError(t, json.NewDecoder(send(http.MethodGet, "/admin/policy/audit?tool=stream_text_processor", "").Body).Decode(&audit))
+	require.Len(t, audit.Entries, 1)
+	assert.Equal(t, "confidential", audit.Entries[0].Rule)
+
+	// 策略服务不可用时默认拒绝，fail_open 时放行
+	policy.Close()
+	engine := tools.NewPolicyEngine(config.PolicyConfig{Endpoint: policy.URL})
+	blocked, _, err := engine.Check(context.Background(), "arguments", "echo", json.RawMessage(`{}`), nil)
+	assert.Error(t, err)
+	require.NotNil(t, blocked)
+	assert.Equal(t, "endpoint", blocked.Rule)
+	engine = tools.NewPolicyEngine(config.PolicyConfig{Endpoint: policy.URL, FailOpen: true})
+	blocked, _, err = engine.Check(context.Background(), "arguments", "echo", json.RawMessage(`{}`), nil)
+	assert.Error(t, err)
+	assert.Nil(t, blocked)
+}
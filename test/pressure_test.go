@@ -0,0 +1,43 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPressureReport(t *testing.T) {
+	report := mcp.NewPressureReport(0.8)
+	report.Add("concurrency", 3, 10)
+	report.Add("memory", 100, 0) // 未配置上限，不参与评估
+	assert.Equal(t, mcp.PressureOK, report.Status)
+	assert.InDelta(t, 0.3, report.Pressure, 1e-9)
+	assert.NotContains(t, report.Factors, "memory")
+
+	// 任一指标达到阈值即判定为 degraded
+	report.Add("queue", 9, 10)
+	assert.Equal(t, mcp.PressureDegraded, report.Status)
+	assert.InDelta(t, 0.9, report.Pressure, 1e-9)
+
+	report.Drain()
+	assert.Equal(t, mcp.PressureDraining, report.Status)
+}
+
+func TestWorkerPoolLoad(t *testing.T) {
+	pool := tools.NewWorkerPool(2)
+	require.NoError(t, pool.Acquire(context.Background()))
+
+	inUse, capacity, waiting := pool.Load()
+	assert.Equal(t, 1, inUse)
+	assert.Equal(t, 2, capacity)
+	assert.Zero(t, waiting)
+
+	pool.Release()
+	inUse, _, _ = pool.Load()
+	assert.Zero(t, inUse)
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"sync"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/profiling"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfilingShipper(t *testing.T) {
+	type upload struct {
+		name, format, auth string
+		profile            []byte
+	}
+	var mu sync.Mutex
+	var uploads []upload
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/ingest", r.URL.Path)
+		file, _, err := r.FormFile("profile")
+		if !assert.NoError(t, err) {
+			return
+		}
+		data, _ := io.ReadAll(file)
+
+		mu.Lock()
+		uploads = append(uploads, upload{
+			name:    r.URL.Query().Get("name"),
+			format:  r.URL.Query().Get("format"),
+			auth:    r.Header.Get("Authorization"),
+			profile: data,
+		})
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	shipper := profiling.NewShipper(profiling.Config{
+		ServerURL: srv.URL + "/",
+		AppName:   "weave-test",
+		Tags:      profiling.ParseTags("region=eu, env=test"),
+		Interval:  50 * time.Millisecond,
+		AuthToken: "token",
+	}, newTestLogger(t))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer cancel()
+	require.NoError(t, shipper.Run(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.GreaterOrEqual(t, len(uploads), 2)
+
+	names := make(map[string]bool)
+	for _, u := range uploads {
+		names[u.name] = true
+		assert.Equal(t, "pprof", u.format)
+		assert.Equal(t, "Bearer token", u.auth)
+		require.Greater(t, len(u.profile), 2)
+		assert.Equal(t, []byte{0x1f, 0x8b}, u.profile[:2], "pprof data should be gzip compressed")
+	}
+	assert.True(t, names["weave-test.cpu{env=test,region=eu}"])
+	assert.True(t, names["weave-test.inuse_space{env=test,region=eu}"])
+}
+
+// labelTool 记录执行时 pprof 标签的测试工具
+type labelTool struct{}
+
+func (lt *labelTool) Name() string                 { return "label_reader" }
+func (lt *labelTool) Description() string          { return "reports its pprof labels" }
+func (lt *labelTool) Category() tools.ToolCategory { return tools.CategoryUtility }
+
+func (lt *labelTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	tool, _ := pprof.Label(ctx, "tool")
+	category, _ := pprof.Label(ctx, "category")
+	return json.Marshal(map[string]string{"tool": tool, "category": category})
+}
+
+func TestToolProfilingLabels(t *testing.T) {
+	tm := tools.NewToolManager(newTestLogger(t), newTestToolConfig())
+	require.NoError(t, tm.RegisterTool(&labelTool{}))
+
+	result, err := tm.CallTool(context.Background(), "label_reader", json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"tool":"label_reader","category":"utility"}`, result.Content[0].Text)
+}
+
+func TestDebugRuntimeEndpoint(t *testing.T) {
+	get := func(t *testing.T, url string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		req.Header.Set("X-API-Key", "admin-secret")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	_, url := newHandlerServer(t, func(cfg *config.Config) { cfg.APIKey = "admin-secret" })
+
+	unauthorized, err := http.Get(url + "/admin/debug/runtime")
+	require.NoError(t, err)
+	unauthorized.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, unauthorized.StatusCode)
+
+	resp := get(t, url+"/admin/debug/runtime")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var info struct {
+		Goroutines    int                    `json:"goroutines"`
+		UptimeSeconds int64                  `json:"uptime_seconds"`
+		Heap          map[string]uint64      `json:"heap"`
+		GC            map[string]interface{} `json:"gc"`
+		Build         map[string]interface{} `json:"build"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+	assert.Positive(t, info.Goroutines)
+	assert.Positive(t, info.Heap["alloc"])
+	assert.Contains(t, info.GC, "num_gc")
+	assert.NotEmpty(t, info.Build["go_version"])
+
+	assert.Equal(t, http.StatusOK, get(t, url+"/admin/debug/pprof/goroutine?debug=1").StatusCode)
+
+	// MCP_DISABLE_DEBUG 关闭所有调试端点
+	_, url = newHandlerServer(t, func(cfg *config.Config) {
+		cfg.APIKey = "admin-secret"
+		cfg.NoDebug = true
+	})
+	assert.Equal(t, http.StatusNotFound, get(t, url+"/admin/debug/runtime").StatusCode)
+	assert.Equal(t, http.StatusNotFound, get(t, url+"/admin/debug/pprof/heap").StatusCode)
+}
@@ -0,0 +1,257 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/mcp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	writePrompt("review.md", "Review {{file}} with a focus on {{focus}}.")
+	writePrompt("summary.json", `{"description":"Summarize a text","arguments":[{"name":"text","required":true},{"name":"tone"}],"template":"Summarize {{text}} {{tone}}"}`)
+	writePrompt("broken.json", `{"template":`)
+	writePrompt("notes.log", "ignored")
+
+	url := newTestServer(t, func(cfg *config.Config) {
+		cfg.ToolConfig.Prompts = config.PromptsConfig{Dir: dir, PollInterval: 20}
+	})
+	baseURL := strings.TrimSuffix(url, "/mcp")
+
+	resp := postMCP(t, baseURL, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"prompt-test"}}}`, false)
+	sessionID := resp.Header.Get(mcp.SessionIDHeader)
+	require.NotEmpty(t, sessionID)
+	var initialized struct {
+		Capabilities struct {
+			Prompts struct {
+				ListChanged bool `json:"listChanged"`
+			} `json:"prompts"`
+		} `json:"capabilities"`
+	}
+	require.NoError(t, json.Unmarshal(decodeReply(t, resp).Result, &initialized))
+	assert.True(t, initialized.Capabilities.Prompts.ListChanged)
+
+	listPrompts := func() []string {
+		var result struct {
+			Prompts []mcp.PromptInfo `json:"prompts"`
+		}
+		require.NoError(t, json.Unmarshal(decodeReply(t, postMCP(t, baseURL, `{"jsonrpc":"2.0","id":2,"method":"prompts/list"}`, false)).Result, &result))
+		names := []string{}
+		for _, prompt := range result.Prompts {
+			names = append(names, prompt.Name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"review", "summary"}, listPrompts())
+
+	// 模板文件中的参数均为必填
+	reply := decodeReply(t, postMCP(t, baseURL, `{"jsonrpc":"2.0","id":3,"method":"prompts/get","params":{"name":"review","arguments":{"file":"main.go","focus":"errors"}}}`, false))
+	require.Nil(t, reply.Error)
+	assert.Contains(t, string(reply.Result), "Review main.go with a focus on errors.")
+	reply = decodeReply(t, postMCP(t, baseURL, `{"jsonrpc":"2.0","id":4,"method":"prompts/get","params":{"name":"review","arguments":{"file":"main.go"}}}`, false))
+	require.NotNil(t, reply.Error)
+	assert.Contains(t, reply.Error.Message, "focus")
+
+	// 订阅通知流后新增提示词
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(mcp.SessionIDHeader, sessionID)
+	stream, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+
+	writePrompt("translate.txt", "Translate {{text}} into {{language}}.")
+	events := make(chan string, 1)
+	go func() {
+		reader := bufio.NewReader(stream.Body)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(line, "data: ") {
+				events <- strings.TrimSpace(strings.TrimPrefix(line, "data: "))
+				return
+			}
+		}
+	}()
+	select {
+	case data := <-events:
+		assert.JSONEq(t, `{"jsonrpc":"2.0","method":"notifications/prompts/list_changed"}`, data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no prompts/list_changed notification")
+	}
+	assert.Equal(t, []string{"review", "summary", "translate"}, listPrompts())
+}
+
+func TestNotificationStreamRequiresSession(t *testing.T) {
+	url := newTestServer(t, nil)
+	open := func(sessionID string) int {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/event-stream")
+		if sessionID != "" {
+			req.Header.Set(mcp.SessionIDHeader, sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusBadRequest, open(""))
+	assert.Equal(t, http.StatusNotFound, open("unknown"))
+
+	// 未配置提示词目录时不声明列表变化通知
+	baseURL := strings.TrimSuffix(url, "/mcp")
+	reply := decodeReply(t, postMCP(t, baseURL, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`, false))
+	assert.Contains(t, string(reply.Result), `"prompts":{"listChanged":false}`)
+	reply = decodeReply(t, postMCP(t, baseURL, `{"jsonrpc":"2.0","id":2,"method":"prompts/get","params":{"name":"example_prompt"}}`, false))
+	require.NotNil(t, reply.Error)
+}
+
+func TestPromptComposition(t *testing.T) {
+	docs := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(docs, "readme.md"), []byte("# Weave\nA toolkit."), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(docs, "large.txt"), []byte(strings.Repeat("x", 100)), 0o644))
+	docsURI := "file://" + filepath.ToSlash(docs)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"base.json":   `{"arguments":[{"name":"doc","required":true}],"messages":[{"text":"Context follows."},{"resource":"` + docsURI + `/{{doc}}"}]}`,
+		"review.json": `{"description":"Review a document","arguments":[{"name":"doc","required":true}],"template":"Review {{doc}}.","messages":[{"prompt":"base"},{"role":"assistant","tool":{"name":"calculator","arguments":{"operation":"add","a":1,"b":2}}}]}`,
+		"loop_a.json": `{"messages":[{"prompt":"loop_b"}]}`,
+		"loop_b.json": `{"messages":[{"prompt":"loop_a"}]}`,
+		"large.json":  `{"messages":[{"resource":"` + docsURI + `/large.txt"}]}`,
+		"mixed.json":  `{"messages":[{"text":"a","prompt":"base"}]}`,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	url := newTestServer(t, func(cfg *config.Config) {
+		cfg.ToolConfig.Resources = config.ResourcesConfig{Roots: map[string]string{"docs": docs}}
+		cfg.ToolConfig.Prompts = config.PromptsConfig{Dir: dir, MaxEmbedBytes: 64}
+	})
+	baseURL := strings.TrimSuffix(url, "/mcp")
+	getPrompt := func(name, arguments string) rpcReply {
+		return decodeReply(t, postMCP(t, baseURL, `{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"`+name+`","arguments":`+arguments+`}}`, false))
+	}
+
+	reply := getPrompt("review", `{"doc":"readme.md"}`)
+	require.Nil(t, reply.Error)
+	var result struct {
+		Description string `json:"description"`
+		Messages    []struct {
+			Role    string `json:"role"`
+			Content struct {
+				Type     string `json:"type"`
+				Text     string `json:"text"`
+				Resource struct {
+					URI  string `json:"uri"`
+					Text string `json:"text"`
+				} `json:"resource"`
+			} `json:"content"`
+		} `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal(reply.Result, &result))
+	assert.Equal(t, "Review a document", result.Description)
+	require.Len(t, result.Messages, 4)
+	assert.Equal(t, "Review readme.md.", result.Messages[0].Content.Text)
+	assert.Equal(t, "Context follows.", result.Messages[1].Content.Text)
+	assert.Equal(t, "resource", result.Messages[2].Content.Type)
+	assert.Equal(t, docsURI+"/readme.md", result.Messages[2].Content.Resource.URI)
+	assert.Equal(t, "# Weave\nA toolkit.", result.Messages[2].Content.Resource.Text)
+	assert.Equal(t, "assistant", result.Messages[3].Role)
+	assert.Contains(t, result.Messages[3].Content.Text, "3")
+
+	// 被引用的提示词同样校验必填参数
+	reply = getPrompt("review", `{}`)
+	require.NotNil(t, reply.Error)
+	assert.Contains(t, reply.Error.Message, "doc")
+
+	reply = getPrompt("loop_a", `{}`)
+	require.NotNil(t, reply.Error)
+	assert.Contains(t, reply.Error.Message, "loop_a -> loop_b -> loop_a")
+
+	reply = getPrompt("large", `{}`)
+	require.NotNil(t, reply.Error)
+	assert.Contains(t, reply.Error.Message, "exceeds 64 bytes")
+
+	reply = getPrompt("base", `{"doc":"../outside.md"}`)
+	require.NotNil(t, reply.Error)
+
+	// 定义不合法的文件不会加载
+	reply = getPrompt("mixed", `{}`)
+	require.NotNil(t, reply.Error)
+	assert.Contains(t, reply.Error.Message, "prompt not found")
+}
+
+func TestPromptArguments(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "letter.json"), []byte(`{
+		"arguments": [
+			{"name": "to", "required": true},
+			{"name": "topic", "required": true},
+			{"name": "tone", "required": true, "default": "formal", "enum": ["formal", "casual"]},
+			{"name": "signature"}
+		],
+		"template": "Write a {{tone}} letter to {{to}} about {{topic}}.{{signature}}"
+	}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad_default.json"), []byte(`{"arguments":[{"name":"tone","default":"rude","enum":["formal"]}],"template":"{{tone}}"}`), 0o644))
+
+	url := newTestServer(t, func(cfg *config.Config) {
+		cfg.ToolConfig.Prompts = config.PromptsConfig{Dir: dir}
+	})
+	baseURL := strings.TrimSuffix(url, "/mcp")
+	getPrompt := func(arguments string) rpcReply {
+		return decodeReply(t, postMCP(t, baseURL, `{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"letter","arguments":`+arguments+`}}`, false))
+	}
+
+	// 有默认值的参数在列表中不是必填
+	reply := decodeReply(t, postMCP(t, baseURL, `{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`, false))
+	var listed struct {
+		Prompts []mcp.PromptInfo `json:"prompts"`
+	}
+	require.NoError(t, json.Unmarshal(reply.Result, &listed))
+	require.Len(t, listed.Prompts, 1, "prompt with an invalid default is skipped")
+	tone := listed.Prompts[0].Arguments[2]
+	assert.False(t, tone.Required)
+	assert.Equal(t, "formal", tone.Default)
+	assert.Equal(t, []string{"formal", "casual"}, tone.Enum)
+
+	reply = getPrompt(`{"to":"Ana","topic":"the release"}`)
+	require.Nil(t, reply.Error)
+	assert.Contains(t, string(reply.Result), "Write a formal letter to Ana about the release.\"")
+
+	reply = getPrompt(`{"to":"Ana","topic":"the release","tone":"casual","signature":" -- Bo"}`)
+	require.Nil(t, reply.Error)
+	assert.Contains(t, string(reply.Result), "Write a casual letter to Ana about the release. -- Bo")
+
+	reply = getPrompt(`{}`)
+	require.NotNil(t, reply.Error)
+	assert.Equal(t, -32602, reply.Error.Code)
+	assert.Contains(t, reply.Error.Message, "missing required arguments: to, topic")
+
+	reply = getPrompt(`{"to":"Ana","topic":"x","tone":"angry"}`)
+	require.NotNil(t, reply.Error)
+	assert.Equal(t, -32602, reply.Error.Code)
+	assert.Contains(t, reply.Error.Message, "argument tone must be one of: formal, casual")
+}
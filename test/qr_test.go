@@ -0,0 +1,107 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runQR(t *testing.T, tool *tools.QRTool, args map[string]interface{}) tools.QRResult {
+	t.Helper()
+	raw, err := json.Marshal(args)
+	require.NoError(t, err)
+	out, err := tool.Execute(context.Background(), raw)
+	require.NoError(t, err)
+	var result tools.QRResult
+	require.NoError(t, json.Unmarshal(out, &result))
+	return result
+}
+
+func TestQRRoundTrip(t *testing.T) {
+	tool := tools.NewQRTool(config.QRConfig{})
+
+	for _, tc := range []struct {
+		format string
+		data   string
+	}{
+		{"qr_code", "https://example.com/invite?code=42"},
+		{"qr_code", "会议室 B-302"},
+		{"data_matrix", "SN-0042-XYZ"},
+		{"code_128", "PKG-12345"},
+		{"ean_13", "4006381333931"},
+	} {
+		generated := runQR(t, tool, map[string]interface{}{"op": "generate", "format": tc.format, "data": tc.data})
+		assert.Equal(t, "image/png", generated.MimeType)
+		assert.Equal(t, 256, generated.Width)
+
+		decoded := runQR(t, tool, map[string]interface{}{"op": "decode", "image": "data:image/png;base64," + generated.Data, "try_harder": true})
+		require.Len(t, decoded.Codes, 1, tc.format)
+		assert.Equal(t, tc.format, decoded.Codes[0].Format)
+		assert.Equal(t, tc.data, decoded.Codes[0].Text)
+	}
+}
+
+func TestQRDecodeMultiple(t *testing.T) {
+	tool := tools.NewQRTool(config.QRConfig{})
+
+	// 两个 QR 码并排放在同一张图片中
+	canvas := image.NewGray(image.Rect(0, 0, 512, 256))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for i, data := range []string{"left", "right"} {
+		generated := runQR(t, tool, map[string]interface{}{"op": "generate", "data": data})
+		raw, err := base64.StdEncoding.DecodeString(generated.Data)
+		require.NoError(t, err)
+		img, err := png.Decode(bytes.NewReader(raw))
+		require.NoError(t, err)
+		draw.Draw(canvas, image.Rect(i*256, 0, i*256+256, 256), img, image.Point{}, draw.Src)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, canvas))
+
+	decoded := runQR(t, tool, map[string]interface{}{"op": "decode", "image": base64.StdEncoding.EncodeToString(buf.Bytes()), "format": "qr_code"})
+	var texts []string
+	for _, code := range decoded.Codes {
+		texts = append(texts, code.Text)
+	}
+	assert.ElementsMatch(t, []string{"left", "right"}, texts)
+}
+
+func TestQRLimits(t *testing.T) {
+	tool := tools.NewQRTool(config.QRConfig{MaxPayloadBytes: 16, MaxPixels: 100 * 100})
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"op":"generate","data":"`+strings.Repeat("x", 17)+`"}`))
+	assert.ErrorContains(t, err, "data exceeds 16 bytes")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"op":"generate","format":"ean_13","data":"not-digits"}`))
+	assert.ErrorContains(t, err, "cannot encode data as ean_13")
+
+	// 像素数在解码前根据图片头检查
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 200, 200))))
+	image64 := base64.StdEncoding.EncodeToString(buf.Bytes())
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"op":"decode","image":"`+image64+`"}`))
+	assert.ErrorContains(t, err, "more than 10000 pixels")
+
+	buf.Reset()
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 50, 50))))
+	image64 = base64.StdEncoding.EncodeToString(buf.Bytes())
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"op":"decode","image":"`+image64+`"}`))
+	assert.True(t, errors.Is(err, tools.ErrQRNotFound))
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"op":"decode","image":"bm90IGFuIGltYWdl"}`))
+	assert.ErrorContains(t, err, "unsupported image")
+}
@@ -0,0 +1,173 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/internal/vector"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResourceReader 模拟会话中已发布的资源
+type fakeResourceReader map[string]string
+
+func (r fakeResourceReader) ReadResource(uri string) ([]byte, string, error) {
+	text, ok := r[uri]
+	if !ok {
+		return nil, "", fmt.Errorf("resource not found: %s", uri)
+	}
+	return []byte(text), "text/plain", nil
+}
+
+func newTestRAGTools(t *testing.T, cfg config.RAGConfig) (*tools.RAGIngestTool, *tools.RAGQueryTool) {
+	t.Helper()
+	embeddingsServer := newFakeEmbeddingsServer(t)
+	llmServer := newFakeLLMServer(t)
+	return tools.NewRAGTools(cfg, newTestEmbeddingsTool(embeddingsServer.URL), newTestLLMTool(llmServer.URL))
+}
+
+func ingestRAG(t *testing.T, tool *tools.RAGIngestTool, ctx context.Context, args string) tools.RAGIngestResult {
+	t.Helper()
+	raw, err := tool.Execute(ctx, json.RawMessage(args))
+	require.NoError(t, err)
+	var result tools.RAGIngestResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result
+}
+
+func TestRAGIngestAndQuery(t *testing.T) {
+	ingest, query := newTestRAGTools(t, config.RAGConfig{ChunkSize: 60, ChunkOverlap: -1})
+	ctx := tools.WithResourceReader(context.Background(), fakeResourceReader{
+		"session://resources/cars.txt": "A car has four wheels. The car engine burns fuel.",
+	})
+
+	long := strings.Repeat("The cat naps in the sun. ", 6)
+	result := ingestRAG(t, ingest, ctx, `{"corpus":"kb","documents":[
+		{"id":"cats","title":"Cats","text":"`+long+`","metadata":{"topic":"pets"}},
+		{"id":"dogs","text":"The dog fetches the ball.","metadata":{"topic":"pets"}},
+		{"uri":"session://resources/cars.txt"}
+	]}`)
+	assert.Equal(t, "weave://rag/kb", result.URI)
+	require.Len(t, result.Documents, 3)
+	assert.Greater(t, result.Documents[0].Chunks, 1)
+	assert.Equal(t, "session://resources/cars.txt", result.Documents[2].ID)
+
+	t.Run("retrieval only", func(t *testing.T) {
+		raw, err := query.Execute(context.Background(), json.RawMessage(`{"corpus":"kb","question":"where is the dog?","top_k":1,"answer":false}`))
+		require.NoError(t, err)
+		var res tools.RAGQueryResult
+		require.NoError(t, json.Unmarshal(raw, &res))
+		assert.Empty(t, res.Answer)
+		require.Len(t, res.Sources, 1)
+		assert.Equal(t, "dogs", res.Sources[0].DocumentID)
+		assert.Equal(t, 1, res.Sources[0].N)
+		assert.Equal(t, "weave://rag/kb/dogs", res.Sources[0].URI)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		raw, err := query.Execute(context.Background(), json.RawMessage(`{"corpus":"kb","question":"car","filter":{"topic":"pets"},"answer":false}`))
+		require.NoError(t, err)
+		var res tools.RAGQueryResult
+		require.NoError(t, json.Unmarshal(raw, &res))
+		require.NotEmpty(t, res.Sources)
+		for _, source := range res.Sources {
+			assert.NotEqual(t, "session://resources/cars.txt", source.DocumentID)
+		}
+	})
+
+	t.Run("answer stream", func(t *testing.T) {
+		var deltas []string
+		raw, err := query.ExecuteStream(context.Background(), json.RawMessage(`{"corpus":"kb","question":"what does the cat do?"}`), func(content string, index int) {
+			deltas = append(deltas, content)
+		})
+		require.NoError(t, err)
+		var res tools.RAGQueryResult
+		require.NoError(t, json.Unmarshal(raw, &res))
+		assert.Equal(t, "Hello there", res.Answer)
+		assert.Equal(t, []string{"Hello", " there"}, deltas)
+		require.NotNil(t, res.Usage)
+		assert.Equal(t, "Cats", res.Sources[0].Title)
+	})
+
+	t.Run("replace and remove", func(t *testing.T) {
+		result := ingestRAG(t, ingest, ctx, `{"corpus":"kb","documents":[{"id":"cats","text":"A short cat note."}],"remove":["dogs","missing"]}`)
+		assert.Equal(t, 1, result.Removed)
+		assert.Equal(t, 1, result.Chunks)
+
+		raw, err := query.Execute(context.Background(), json.RawMessage(`{"corpus":"kb","question":"cat","top_k":10,"answer":false}`))
+		require.NoError(t, err)
+		var res tools.RAGQueryResult
+		require.NoError(t, json.Unmarshal(raw, &res))
+		ids := map[string]int{}
+		for _, source := range res.Sources {
+			ids[source.DocumentID]++
+		}
+		assert.Equal(t, map[string]int{"cats": 1, "session://resources/cars.txt": 1}, ids)
+	})
+
+	_, err := query.Execute(context.Background(), json.RawMessage(`{"corpus":"missing","question":"cat"}`))
+	assert.True(t, errors.Is(err, vector.ErrCollectionNotFound))
+
+	_, err = ingest.Execute(context.Background(), json.RawMessage(`{"documents":[{"uri":"session://resources/cars.txt"}]}`))
+	assert.ErrorContains(t, err, "no session available")
+
+	_, err = ingest.Execute(context.Background(), json.RawMessage(`{"documents":[{"text":"no id"}]}`))
+	assert.ErrorContains(t, err, "id is required")
+}
+
+func TestRAGResources(t *testing.T) {
+	embeddingsServer := newFakeEmbeddingsServer(t)
+	cfg := newTestToolConfig()
+	cfg.Embeddings = config.EmbeddingsConfig{
+		Providers: map[string]config.LLMProviderConfig{
+			"local": {Type: "ollama", URL: embeddingsServer.URL, Model: "nomic-test"},
+		},
+	}
+	cfg.RAG = config.RAGConfig{StoreDir: filepath.Join(t.TempDir(), "rag")}
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	tm.RegisterAllTools()
+
+	_, err := tm.CallTool(context.Background(), "rag_ingest", json.RawMessage(`{"corpus":"my docs","documents":[{"id":"guide/intro","title":"Intro","text":"The cat guide."}]}`))
+	require.NoError(t, err)
+
+	var uris []string
+	for _, resource := range tm.Resources() {
+		uris = append(uris, resource.URI)
+	}
+	assert.Equal(t, []string{"weave://rag/my%20docs", "weave://rag/my%20docs/guide%2Fintro"}, uris)
+
+	content, mimeType, ok, err := tm.ReadResource("weave://rag/my%20docs/guide%2Fintro")
+	require.True(t, ok)
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", mimeType)
+	assert.Equal(t, "The cat guide.", content)
+
+	content, _, ok, err = tm.ReadResource("weave://rag/my%20docs")
+	require.True(t, ok)
+	require.NoError(t, err)
+	assert.Contains(t, content, `"title": "Intro"`)
+	assert.NotContains(t, content, "The cat guide.")
+
+	_, _, ok, err = tm.ReadResource("weave://rag/other")
+	assert.True(t, ok)
+	assert.Error(t, err)
+	_, _, ok, _ = tm.ReadResource("weave://meta/runtime")
+	assert.False(t, ok)
+
+	// 重启后从存储目录加载语料库
+	reloaded := tools.NewToolManager(newTestLogger(t), cfg)
+	reloaded.RegisterAllTools()
+	content, _, ok, err = reloaded.ReadResource("weave://rag/my%20docs/guide%2Fintro")
+	require.True(t, ok)
+	require.NoError(t, err)
+	assert.Equal(t, "The cat guide.", content)
+}
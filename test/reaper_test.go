@@ -0,0 +1,118 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdleSessionReaper(t *testing.T) {
+	slow := testkit.NewMockTool("slow").Returns("done").Delays(time.Minute)
+	srv := testkit.NewServer(t, func(cfg *config.Config) {
+		cfg.APIKey = "secret"
+		cfg.SessionTTL = 150 * time.Millisecond
+		cfg.SessionReapInterval = 20 * time.Millisecond
+		cfg.ToolConfig.Global.EnableMetrics = true
+	}, slow)
+	httpSrv := httptest.NewServer(srv.Handler())
+	defer httpSrv.Close()
+	srv.SetHeader("X-API-Key", "secret")
+
+	resp, err := srv.Initialize("idle-client")
+	require.NoError(t, err)
+	sessionID := resp.Header.Get(mcp.SessionIDHeader)
+	require.NotEmpty(t, sessionID)
+
+	// 会话中提交的异步任务与打开的通知流
+	resp, err = srv.Call(mcp.MethodToolsCall, map[string]interface{}{"name": "slow", "arguments": map[string]string{}, "async": true})
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	var submitted struct {
+		JobID string `json:"jobId"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Result, &submitted))
+	require.NotEmpty(t, submitted.JobID)
+
+	req, err := http.NewRequest(http.MethodGet, httpSrv.URL+"/mcp", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("X-API-Key", "secret")
+	req.Header.Set(mcp.SessionIDHeader, sessionID)
+	stream, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+
+	// 空闲超过 TTL 后通知流结束
+	ended := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, stream.Body)
+		close(ended)
+	}()
+	select {
+	case <-ended:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification stream was not closed after the session expired")
+	}
+
+	resp, err = srv.Call(mcp.MethodToolsList, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.Status)
+
+	// 会话提交的未完成任务被取消，且只对原会话可见
+	_, err = srv.Initialize("idle-client")
+	require.NoError(t, err)
+	resp, err = srv.Call(mcp.MethodJobsGet, map[string]string{"jobId": submitted.JobID})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "job not found")
+	require.Eventually(t, func() bool {
+		req, err := http.NewRequest(http.MethodGet, httpSrv.URL+"/admin/jobs?status=cancelled", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-API-Key", "secret")
+		jobs, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false
+		}
+		defer jobs.Body.Close()
+		body, err := io.ReadAll(jobs.Body)
+		return err == nil && strings.Contains(string(body), submitted.JobID)
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// 新建的会话仍然活跃
+	metrics, err := http.Get(httpSrv.URL + mcp.MetricsPath)
+	require.NoError(t, err)
+	defer metrics.Body.Close()
+	body, err := io.ReadAll(metrics.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "weave_sessions_expired_total 1\n")
+	assert.Contains(t, string(body), "weave_sessions_active 1\n")
+}
+
+func TestSessionReapSkipsBusySessions(t *testing.T) {
+	store := mcp.NewSessionStore(0, 0, 50*time.Millisecond, newTestLogger(t))
+	idle := store.Create("idle")
+	active := store.Create("active")
+	time.Sleep(80 * time.Millisecond)
+	_, ok := store.Get(active.ID)
+	require.True(t, ok)
+
+	expired := store.Reap()
+	require.Len(t, expired, 1)
+	assert.Equal(t, idle.ID, expired[0].ID)
+	assert.Equal(t, int64(1), store.Expired())
+	_, ok = store.Get(idle.ID)
+	assert.False(t, ok)
+	assert.Empty(t, store.Reap())
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"Weave-Toolkit/internal/history"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedHistoryReceipts(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer := history.NewSigner(private)
+	dsn := filepath.Join(t.TempDir(), "history.db")
+
+	// record 以签名记录器执行 n 次调用，重新打开存储后签名链继续
+	record := func(n int) {
+		store, err := history.Open(history.DriverSQLite, dsn)
+		require.NoError(t, err)
+		recorder, err := history.NewSignedRecorder(store, signer, newTestLogger(t))
+		require.NoError(t, err)
+		tm := tools.NewToolManager(newTestLogger(t), newTestToolConfig())
+		tm.RegisterAllTools()
+		tm.AddCallObserver(recorder.Observe)
+		for i := 0; i < n; i++ {
+			args, _ := json.Marshal(tools.CalculatorArgs{Operation: "add", A: float64(i), B: 1})
+			_, err := tm.CallTool(context.Background(), "calculator", args)
+			require.NoError(t, err)
+		}
+		require.NoError(t, recorder.Close())
+	}
+	record(2)
+	record(2)
+
+	store, err := history.Open(history.DriverSQLite, dsn)
+	require.NoError(t, err)
+	defer store.Close()
+	count, head, err := history.VerifyReceipts(context.Background(), store, public)
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	entries, err := store.Query(context.Background(), history.Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	require.NotNil(t, entries[0].Receipt)
+	assert.Equal(t, head, entries[0].Receipt.Hash)
+	assert.Equal(t, signer.KeyID(), entries[0].Receipt.KeyID)
+
+	// 其他密钥签名的记录不在链上
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	count, _, err = history.VerifyReceipts(context.Background(), store, other)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	db, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 修改内容后哈希不匹配
+	_, err = db.Exec(`UPDATE tool_calls SET status = 'error' WHERE id = 2`)
+	require.NoError(t, err)
+	count, _, err = history.VerifyReceipts(context.Background(), store, public)
+	assert.True(t, errors.Is(err, history.ErrReceiptInvalid))
+	assert.Contains(t, err.Error(), "entry 2 content does not match")
+	assert.Equal(t, 1, count)
+
+	// 删除中间的记录后链接断开
+	_, err = db.Exec(`DELETE FROM tool_calls WHERE id = 2`)
+	require.NoError(t, err)
+	_, _, err = history.VerifyReceipts(context.Background(), store, public)
+	assert.True(t, errors.Is(err, history.ErrReceiptInvalid))
+	assert.Contains(t, err.Error(), "entry 3 does not follow")
+}
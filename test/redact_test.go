@@ -0,0 +1,94 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor(t *testing.T) {
+	redactor := tools.NewRedactor(config.RedactionConfig{
+		RedactionRule: config.RedactionRule{Patterns: []string{`sk-[A-Za-z0-9]+`}},
+		Tools: map[string]config.RedactionRule{
+			"http_fetch": {Arguments: []string{"$.headers.Cookie"}, Result: []string{"$..session"}},
+		},
+	})
+
+	// 默认按字段名脱敏，正则规则作用于所有字符串
+	args := redactor.Arguments("llm", json.RawMessage(`{"prompt":"key sk-abc123 here","auth":{"Password":"hunter2"},"n":1.50}`))
+	assert.JSONEq(t, `{"prompt":"key [REDACTED] here","auth":{"Password":"[REDACTED]"},"n":1.50}`, string(args))
+
+	// 工具规则只作用于该工具
+	raw := json.RawMessage(`{"url":"https://example.com","headers":{"Cookie":"sid=1"}}`)
+	assert.JSONEq(t, `{"url":"https://example.com","headers":{"Cookie":"[REDACTED]"}}`, string(redactor.Arguments("http_fetch", raw)))
+	assert.Equal(t, string(raw), string(redactor.Arguments("scrape", raw)))
+
+	// 结果中的 JSON 文本按 JSONPath 脱敏，其他文本只应用正则规则
+	result := &tools.ToolCallResult{Content: []tools.ToolCallContent{
+		{Type: "text", Text: `{"body":"ok","meta":{"session":"abc"}}`},
+		{Type: "text", Text: "plain sk-zzz9 text"},
+	}}
+	redacted := redactor.Result("http_fetch", result)
+	assert.JSONEq(t, `{"body":"ok","meta":{"session":"[REDACTED]"}}`, redacted.Content[0].Text)
+	assert.Equal(t, "plain [REDACTED] text", redacted.Content[1].Text)
+	assert.Equal(t, "plain sk-zzz9 text", result.Content[1].Text)
+
+	unchanged := &tools.ToolCallResult{Content: []tools.ToolCallContent{{Type: "text", Text: "3"}}}
+	assert.Same(t, unchanged, redactor.Result("calculator", unchanged))
+	assert.Equal(t, "upstream rejected [REDACTED]", redactor.Text("llm", "upstream rejected sk-abc"))
+}
+
+func TestRedactionInLogsAndRecords(t *testing.T) {
+	dir := t.TempDir()
+	log, err := logger.NewLogger(dir, "info")
+	require.NoError(t, err)
+	defer log.Close()
+	defer log.SetLevel("error")
+
+	cfg := newTestToolConfig()
+	cfg.Redaction = config.RedactionConfig{Replacement: "***", Tools: map[string]config.RedactionRule{
+		"login": {Arguments: []string{"$.otp"}, Result: []string{"$.session"}},
+	}}
+	var received json.RawMessage
+	login := testkit.NewMockTool("login").Handle(func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+		received = args
+		return json.RawMessage(`{"user":"alice","session":"s3cr3t-session"}`), nil
+	})
+	tm := tools.NewToolManager(log, cfg)
+	require.NoError(t, tm.RegisterTool(login))
+	var records []tools.CallRecord
+	tm.AddCallObserver(func(_ context.Context, record tools.CallRecord) {
+		records = append(records, record)
+	})
+
+	// 工具与调用方拿到原始数据
+	args := json.RawMessage(`{"user":"alice","password":"hunter2","otp":"424242"}`)
+	result, err := tm.CallTool(context.Background(), "login", args)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(args), string(received))
+	assert.Contains(t, result.Content[0].Text, "s3cr3t-session")
+
+	// 调用记录与日志中不出现敏感数据
+	require.Len(t, records, 1)
+	assert.JSONEq(t, `{"user":"alice","password":"***","otp":"***"}`, string(records[0].Arguments))
+	assert.JSONEq(t, `{"user":"alice","session":"***"}`, records[0].Result.Content[0].Text)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Tool call started")
+	assert.NotContains(t, string(data), "hunter2")
+	assert.NotContains(t, string(data), "424242")
+}
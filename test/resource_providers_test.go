@@ -0,0 +1,217 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/config"
+	werrors "Weave-Toolkit/internal/errors"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newProviderManager 按资源配置创建工具管理器并注册内置资源提供者
+func newProviderManager(t *testing.T, configure func(toolConfig *config.ToolManagerConfig)) *tools.ToolManager {
+	toolConfig := newTestToolConfig()
+	configure(toolConfig)
+	tm := tools.NewToolManager(newTestLogger(t), toolConfig)
+	tm.UseTools()
+	tm.RegisterAllTools()
+	t.Cleanup(func() { tm.Close() })
+	return tm
+}
+
+func TestEnvResourceProvider(t *testing.T) {
+	t.Setenv("WEAVE_TEST_REGION", "eu-west-1")
+	t.Setenv("WEAVE_TEST_SECRET", "hunter2")
+	tm := newProviderManager(t, func(toolConfig *config.ToolManagerConfig) {
+		toolConfig.Resources.Env = []string{"WEAVE_TEST_REGION", "WEAVE_TEST_UNSET"}
+	})
+
+	content, mimeType, ok, err := tm.ReadProviderResource(context.Background(), "env://WEAVE_TEST_REGION")
+	require.True(t, ok)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", string(content))
+	assert.Equal(t, "text/plain", mimeType)
+
+	// 白名单之外与未设置的变量均不存在
+	for _, uri := range []string{"env://WEAVE_TEST_SECRET", "env://WEAVE_TEST_UNSET"} {
+		_, _, ok, err = tm.ReadProviderResource(context.Background(), uri)
+		require.True(t, ok)
+		assert.True(t, werrors.Is(err, werrors.KindNotFound), uri)
+	}
+
+	providers := tm.ResourceProviders()
+	require.Len(t, providers, 1)
+	assert.Equal(t, []tools.ToolResource{{URI: "env://WEAVE_TEST_REGION", Name: "env/WEAVE_TEST_REGION", MimeType: "text/plain", Description: "Value of the WEAVE_TEST_REGION environment variable"}}, providers[0].Resources())
+
+	// 未配置的 scheme 不由提供者处理
+	_, _, ok, _ = tm.ReadProviderResource(context.Background(), "db://main/users")
+	assert.False(t, ok)
+}
+
+func TestDBResourceProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE users (id INTEGER, name TEXT, team TEXT);
+		INSERT INTO users VALUES (1, 'ada', 'core'), (2, 'grace', 'core'), (3, 'linus', NULL);
+		CREATE TABLE secrets (value TEXT)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	tm := newProviderManager(t, func(toolConfig *config.ToolManagerConfig) {
+		toolConfig.Resources.DB = map[string]config.ResourceDBConfig{
+			"main": {
+				Driver: "sqlite",
+				DSN:    path,
+				Tables: []string{"users"},
+				Queries: map[string]config.ResourceQueryConfig{
+					"team": {SQL: "SELECT name FROM users WHERE team = ? ORDER BY id", Params: []string{"team"}},
+				},
+				MaxRows: 2,
+			},
+		}
+	})
+	read := func(uri string) (string, string, error) {
+		content, mimeType, ok, err := tm.ReadProviderResource(context.Background(), uri)
+		require.True(t, ok, uri)
+		return string(content), mimeType, err
+	}
+
+	content, mimeType, err := read("db://main/users")
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", mimeType)
+	var result tools.DBResourceResult
+	require.NoError(t, json.Unmarshal([]byte(content), &result))
+	assert.Equal(t, []string{"id", "name", "team"}, result.Columns)
+	assert.Len(t, result.Rows, 2)
+	assert.True(t, result.Truncated, "max_rows caps the result")
+	assert.Equal(t, "ada", result.Rows[0]["name"])
+
+	content, mimeType, err = read("db://main/users?format=csv&limit=1")
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv", mimeType)
+	assert.Equal(t, "id,name,team\n1,ada,core\n", content)
+
+	// 预定义查询的参数以占位符传入
+	content, _, err = read("db://main/team?team=core&format=csv")
+	require.NoError(t, err)
+	assert.Equal(t, "name\nada\ngrace\n", content)
+	content, _, err = read("db://main/team?team=core'%20OR%20'1'='1&format=csv")
+	require.NoError(t, err)
+	assert.Equal(t, "name\n", content)
+
+	_, _, err = read("db://main/team")
+	assert.EqualError(t, err, "missing query parameter: team")
+	_, _, err = read("db://main/secrets")
+	assert.True(t, werrors.Is(err, werrors.KindNotFound), "tables must be listed")
+	_, _, err = read("db://other/users")
+	assert.True(t, werrors.Is(err, werrors.KindNotFound))
+	_, _, err = read("db://main/users?format=xml")
+	assert.EqualError(t, err, "unsupported format: xml")
+}
+
+func TestHTTPResourceProvider(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/doc":
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			w.Write([]byte("# Title"))
+		case "/away":
+			target := *r.URL
+			target.Scheme, target.Host = "https", strings.Replace(r.Host, "127.0.0.1", "localhost", 1)
+			target.Path = "/doc"
+			http.Redirect(w, r, target.String(), http.StatusFound)
+		case "/big":
+			w.Write([]byte(strings.Repeat("x", 64)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer upstream.Close()
+
+	tm := newProviderManager(t, func(toolConfig *config.ToolManagerConfig) {
+		toolConfig.HTTPClient.TLS.InsecureSkipVerify = true
+		toolConfig.Resources.HTTP = config.ResourceHTTPConfig{AllowHosts: []string{"127.0.0.1"}, MaxBytes: 32}
+	})
+	read := func(uri string) (string, string, error) {
+		content, mimeType, ok, err := tm.ReadProviderResource(context.Background(), uri)
+		require.True(t, ok, uri)
+		return string(content), mimeType, err
+	}
+
+	content, mimeType, err := read(upstream.URL + "/doc")
+	require.NoError(t, err)
+	assert.Equal(t, "# Title", content)
+	assert.Equal(t, "text/markdown", mimeType)
+
+	// 重定向到白名单之外的主机被拒绝
+	_, _, err = read(upstream.URL + "/away")
+	assert.True(t, werrors.Is(err, werrors.KindUnauthorized), "%v", err)
+	u, _ := url.Parse(upstream.URL)
+	_, _, err = read("https://localhost:" + u.Port() + "/doc")
+	assert.True(t, werrors.Is(err, werrors.KindUnauthorized))
+
+	_, _, err = read(upstream.URL + "/big")
+	assert.EqualError(t, err, "resource exceeds 32 bytes")
+	_, _, err = read(upstream.URL + "/missing")
+	assert.True(t, werrors.Is(err, werrors.KindUpstreamFailure))
+}
+
+// memoProvider 以 memo://<名称> 提供固定内容的测试资源提供者
+type memoProvider struct {
+	scheme string
+}
+
+func (mp memoProvider) Scheme() string { return mp.scheme }
+
+func (mp memoProvider) Resources() []tools.ToolResource {
+	return []tools.ToolResource{{URI: mp.scheme + "://greeting", Name: "greeting", MimeType: "text/plain"}}
+}
+
+func (mp memoProvider) ResourceTemplates() []tools.ResourceTemplate {
+	return []tools.ResourceTemplate{{URITemplate: mp.scheme + "://{name}", Name: mp.scheme}}
+}
+
+func (mp memoProvider) Read(ctx context.Context, uri *url.URL) ([]byte, string, error) {
+	if uri.Host == "binary" {
+		return []byte{0xff, 0x00}, "application/octet-stream", nil
+	}
+	return []byte("hello from " + uri.Host), "text/plain", nil
+}
+
+func TestServerResourceProviders(t *testing.T) {
+	srv, err := mcp.New(
+		mcp.WithConfig(testkit.Config(t)),
+		mcp.WithLogManager(testkit.Logger(t)),
+		mcp.WithTools(),
+		mcp.WithResourceProviders(memoProvider{scheme: "memo"}, memoProvider{scheme: "weave"}),
+	)
+	require.NoError(t, err)
+	httpSrv := httptest.NewServer(srv.Handler())
+	defer httpSrv.Close()
+
+	reply := decodeReply(t, postMCP(t, httpSrv.URL, `{"jsonrpc":"2.0","id":1,"method":"resources/list"}`, false))
+	assert.Contains(t, string(reply.Result), `"uri":"memo://greeting"`)
+	reply = decodeReply(t, postMCP(t, httpSrv.URL, `{"jsonrpc":"2.0","id":2,"method":"resources/templates/list"}`, false))
+	assert.Contains(t, string(reply.Result), `"uriTemplate":"memo://{name}"`)
+	assert.NotContains(t, string(reply.Result), `"name":"weave"`, "reserved schemes cannot be registered")
+
+	reply = decodeReply(t, postMCP(t, httpSrv.URL, `{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"memo://world"}}`, false))
+	require.Nil(t, reply.Error)
+	assert.Contains(t, string(reply.Result), `"text":"hello from world"`)
+	reply = decodeReply(t, postMCP(t, httpSrv.URL, `{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"memo://binary"}}`, false))
+	assert.Contains(t, string(reply.Result), `"blob":"/wA="`)
+}
@@ -0,0 +1,116 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/internal/uritemplate"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURITemplate(t *testing.T) {
+	tmpl, err := uritemplate.Parse("db://{table}/rows{?limit,offset}")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"table", "limit", "offset"}, tmpl.Vars())
+
+	vars, ok := tmpl.Match("db://order%20items/rows?offset=20&limit=10&other=1")
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"table": "order items", "limit": "10", "offset": "20"}, vars)
+	vars, ok = tmpl.Match("db://users/rows")
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"table": "users"}, vars)
+	_, ok = tmpl.Match("db://a/b/rows")
+	assert.False(t, ok, "simple variables do not span segments")
+	_, ok = tmpl.Match("db:///rows")
+	assert.False(t, ok, "variables are not empty")
+
+	uri, err := tmpl.Expand(map[string]string{"table": "order items", "limit": "5"})
+	require.NoError(t, err)
+	assert.Equal(t, "db://order%20items/rows?limit=5", uri)
+	_, err = tmpl.Expand(map[string]string{"limit": "5"})
+	assert.EqualError(t, err, "missing variable table for template db://{table}/rows{?limit,offset}")
+
+	reserved, err := uritemplate.Parse("file://{+path}")
+	require.NoError(t, err)
+	vars, ok = reserved.Match("file:///var/log/app.log")
+	require.True(t, ok)
+	assert.Equal(t, "/var/log/app.log", vars["path"])
+
+	for _, invalid := range []string{"logs://{date", "logs://{}", "logs://{a}/{a}", "logs://{?q}/{date}", "logs://{a,b}", "logs://{da-te}"} {
+		_, err := uritemplate.Parse(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// logTemplateTool 以 logs://{date}{?level} 模板公开日志的测试工具
+type logTemplateTool struct {
+	*testkit.MockTool
+	reads []map[string]string
+}
+
+func (lt *logTemplateTool) ResourceTemplates() []tools.ResourceTemplate {
+	return []tools.ResourceTemplate{
+		{URITemplate: "logs://{date}{?level}", Name: "logs", MimeType: "text/plain", Description: "Log lines of a day"},
+		{URITemplate: "logs://{{broken", Name: "broken"},
+	}
+}
+
+func (lt *logTemplateTool) ReadTemplate(ctx context.Context, uriTemplate string, vars map[string]string) (string, string, error) {
+	lt.reads = append(lt.reads, vars)
+	if vars["date"] == "1999-01-01" {
+		return "", "", fmt.Errorf("no logs for %s", vars["date"])
+	}
+	return fmt.Sprintf("%s lines at %s", vars["date"], vars["level"]), "", nil
+}
+
+func TestResourceTemplates(t *testing.T) {
+	logs := &logTemplateTool{MockTool: testkit.NewMockTool("logs")}
+	srv, err := mcp.New(
+		mcp.WithConfig(testkit.Config(t)),
+		mcp.WithLogManager(testkit.Logger(t)),
+		mcp.WithTools(logs),
+	)
+	require.NoError(t, err)
+	httpSrv := httptest.NewServer(srv.Handler())
+	defer httpSrv.Close()
+
+	reply := decodeReply(t, postMCP(t, httpSrv.URL, `{"jsonrpc":"2.0","id":1,"method":"resources/templates/list"}`, false))
+	require.Nil(t, reply.Error)
+	var list struct {
+		ResourceTemplates []mcp.ResourceTemplateInfo `json:"resourceTemplates"`
+	}
+	require.NoError(t, json.Unmarshal(reply.Result, &list))
+	templates := map[string]mcp.ResourceTemplateInfo{}
+	for _, template := range list.ResourceTemplates {
+		templates[template.Name] = template
+	}
+	assert.Equal(t, "logs://{date}{?level}", templates["logs"].URITemplate)
+	assert.Equal(t, "weave://meta/tools/{name}", templates["tools"].URITemplate)
+	assert.NotContains(t, templates, "broken", "templates that fail to parse are not advertised")
+
+	reply = decodeReply(t, postMCP(t, httpSrv.URL, `{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"logs://2024-05-01?level=warn"}}`, false))
+	require.Nil(t, reply.Error)
+	var read struct {
+		Contents []mcp.ResourceContent `json:"contents"`
+	}
+	require.NoError(t, json.Unmarshal(reply.Result, &read))
+	require.Len(t, read.Contents, 1)
+	assert.Equal(t, mcp.ResourceContent{URI: "logs://2024-05-01?level=warn", MimeType: "text/plain", Text: "2024-05-01 lines at warn"}, read.Contents[0])
+	assert.Equal(t, []map[string]string{{"date": "2024-05-01", "level": "warn"}}, logs.reads)
+
+	reply = decodeReply(t, postMCP(t, httpSrv.URL, `{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"logs://1999-01-01"}}`, false))
+	require.NotNil(t, reply.Error)
+	assert.Contains(t, reply.Error.Message, "no logs for 1999-01-01")
+
+	reply = decodeReply(t, postMCP(t, httpSrv.URL, `{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"logs://a/b"}}`, false))
+	require.NotNil(t, reply.Error)
+	assert.Contains(t, reply.Error.Message, "resource not found")
+}
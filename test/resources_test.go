@@ -0,0 +1,202 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readResourceRequest 发送 resources/read 请求，etag 不为空时携带 If-None-Match
+func readResourceRequest(t *testing.T, endpoint, uri, etag string) *http.Response {
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":%q}}`, uri)
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// resourceCacheStats 读取 /health/stats 中的文件资源缓存统计
+func resourceCacheStats(t *testing.T, endpoint string) map[string]float64 {
+	resp, err := http.Get(strings.TrimSuffix(endpoint, "/mcp") + "/health/stats")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var stats struct {
+		ResourceCache map[string]float64 `json:"resource_cache"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+	return stats.ResourceCache
+}
+
+func TestFileResourceETag(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("first version"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "image.bin"), []byte{0x89, 0x00, 0xff}, 0644))
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	require.NoError(t, os.WriteFile(outside, []byte("secret"), 0644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "link.txt")))
+
+	endpoint := newTestServer(t, func(cfg *config.Config) {
+		cfg.ToolConfig.Resources = config.ResourcesConfig{Roots: map[string]string{"docs": dir}}
+	})
+	uri := "file://" + filepath.ToSlash(path)
+
+	resp := readResourceRequest(t, endpoint, uri, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+	reply := decodeReply(t, resp)
+	require.Nil(t, reply.Error)
+	assert.Contains(t, string(reply.Result), `"text":"first version"`)
+
+	// 未变化的文件返回 304，第二次读取命中缓存
+	resp = readResourceRequest(t, endpoint, uri, etag)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	resp = readResourceRequest(t, endpoint, uri, "")
+	assert.Equal(t, etag, resp.Header.Get("ETag"))
+	stats := resourceCacheStats(t, endpoint)
+	assert.Equal(t, float64(1), stats["entries"])
+	assert.Equal(t, float64(2), stats["hits"])
+	assert.Equal(t, float64(1), stats["misses"])
+
+	// 修改后缓存失效并返回新内容与新的 ETag
+	require.NoError(t, os.WriteFile(path, []byte("second version!"), 0644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+	resp = readResourceRequest(t, endpoint, uri, etag)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+	assert.Contains(t, string(decodeReply(t, resp).Result), `"text":"second version!"`)
+
+	// 二进制文件以 base64 返回
+	resp = readResourceRequest(t, endpoint, "file://"+filepath.ToSlash(filepath.Join(dir, "image.bin")), "")
+	assert.Contains(t, string(decodeReply(t, resp).Result), `"blob":"iQD/"`)
+
+	// 根目录之外的文件与指向根目录之外的符号链接不可读取
+	for _, target := range []string{outside, filepath.Join(dir, "link.txt"), filepath.Join(dir, "missing.txt")} {
+		resp = readResourceRequest(t, endpoint, "file://"+filepath.ToSlash(target), "")
+		reply := decodeReply(t, resp)
+		require.NotNil(t, reply.Error, target)
+		assert.Empty(t, resp.Header.Get("ETag"))
+	}
+
+	// 根目录通过 roots/list 公开
+	resp = postMCP(t, strings.TrimSuffix(endpoint, "/mcp"), `{"jsonrpc":"2.0","id":2,"method":"roots/list"}`, false)
+	assert.Contains(t, string(decodeReply(t, resp).Result), `"name":"docs"`)
+}
+
+func TestResourceETagMetaResource(t *testing.T) {
+	endpoint := newTestServer(t, nil)
+
+	resp := readResourceRequest(t, endpoint, "weave://meta/limits", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	resp = readResourceRequest(t, endpoint, "weave://meta/limits", `"other", `+etag)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+}
+
+func TestMetaResources(t *testing.T) {
+	const apiKey = "api-key-do-not-leak"
+	const webhookSecret = "whsec-do-not-leak"
+	srv := testkit.NewServer(t, func(cfg *config.Config) {
+		cfg.APIKey = apiKey
+		cfg.MaxConnections = 7
+		cfg.MaxRequestSize = 12345
+		cfg.ToolTimeout = 9 * time.Second
+		cfg.ToolConfig.Global.MaxConcurrentCalls = 3
+		cfg.ToolConfig.Global.EnableMetrics = true
+		cfg.ToolConfig.Global.EnableTracing = false
+		cfg.ToolConfig.Aliases = map[string]string{"a": "alpha"}
+		cfg.ToolConfig.Webhooks = map[string]config.WebhookConfig{"deploy": {Provider: "generic", Tool: "alpha", Secret: webhookSecret}}
+	}, testkit.NewMockTool("alpha").Returns("a"), testkit.NewMockTool("beta").Returns("b"))
+	srv.SetHeader("X-API-Key", apiKey)
+
+	// 资源列表包含四个元信息资源与每个工具的资源
+	resp, err := srv.Call("resources/list", nil)
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	var list struct {
+		Resources []struct {
+			URI string `json:"uri"`
+		} `json:"resources"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Result, &list))
+	var uris []string
+	for _, resource := range list.Resources {
+		uris = append(uris, resource.URI)
+	}
+	for _, uri := range []string{"weave://meta/runtime", "weave://meta/features", "weave://meta/limits", "weave://meta/tools", "weave://meta/tools/alpha", "weave://meta/tools/beta"} {
+		assert.Contains(t, uris, uri)
+	}
+
+	// read 读取资源的 JSON 内容，同时确认其中不含密钥
+	read := func(uri string) map[string]interface{} {
+		resp, err := srv.Call("resources/read", map[string]interface{}{"uri": uri})
+		require.NoError(t, err)
+		require.Nil(t, resp.Error, uri)
+		var result struct {
+			Contents []struct {
+				URI      string `json:"uri"`
+				MimeType string `json:"mimeType"`
+				Text     string `json:"text"`
+			} `json:"contents"`
+		}
+		require.NoError(t, json.Unmarshal(resp.Result, &result))
+		require.Len(t, result.Contents, 1)
+		assert.Equal(t, uri, result.Contents[0].URI)
+		assert.Equal(t, "application/json", result.Contents[0].MimeType)
+		assert.NotContains(t, result.Contents[0].Text, apiKey, uri)
+		assert.NotContains(t, result.Contents[0].Text, webhookSecret, uri)
+		var data map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(result.Contents[0].Text), &data))
+		return data
+	}
+
+	runtimeInfo := read("weave://meta/runtime")
+	assert.Equal(t, runtime.Version(), runtimeInfo["go_version"])
+	assert.Equal(t, runtime.GOOS, runtimeInfo["os"])
+	assert.Contains(t, runtimeInfo, "started_at")
+
+	features := read("weave://meta/features")
+	assert.Equal(t, mcp.ProtocolVersion, features["protocol_version"])
+	assert.Equal(t, true, features["webhooks"])
+	assert.Equal(t, true, features["tool_aliases"])
+	assert.Equal(t, true, features["metrics"])
+	assert.Equal(t, false, features["tracing"])
+
+	limits := read("weave://meta/limits")
+	assert.Equal(t, float64(7), limits["max_connections"])
+	assert.Equal(t, float64(12345), limits["max_request_size"])
+	assert.Equal(t, "9s", limits["tool_timeout"])
+	assert.Equal(t, float64(3), limits["max_concurrent_calls"])
+
+	toolList := read("weave://meta/tools")
+	require.Len(t, toolList["tools"], 2)
+	assert.Equal(t, "alpha", toolList["tools"].([]interface{})[0].(map[string]interface{})["name"])
+	assert.Equal(t, "beta", read("weave://meta/tools/beta")["name"])
+
+	resp, err = srv.Call("resources/read", map[string]interface{}{"uri": "weave://meta/tools/missing"})
+	require.NoError(t, err)
+	assert.NotNil(t, resp.Error)
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultTruncation(t *testing.T) {
+	cfg := newTestToolConfig()
+	cfg.Global.MaxResultBytes = 100
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	full := strings.Repeat("结果数据", 30)
+	require.NoError(t, tm.RegisterTool(&largeResultTool{text: full}))
+
+	result, err := tm.CallTool(context.Background(), "large_result", json.RawMessage(`{}`))
+	require.NoError(t, err)
+	require.NotNil(t, result.Meta)
+	require.Len(t, result.Meta.Truncated, 1)
+	truncated := result.Meta.Truncated[0]
+	assert.Equal(t, 0, truncated.Index)
+	assert.Equal(t, len(full), truncated.Size)
+	assert.LessOrEqual(t, truncated.Returned, 100)
+	assert.True(t, strings.HasPrefix(truncated.URI, tools.ResultURIPrefix))
+	assert.True(t, strings.HasPrefix(result.Content[0].Text, full[:truncated.Returned]))
+	assert.Contains(t, result.Content[0].Text, fmt.Sprintf("%s?offset=%d", truncated.URI, truncated.Returned))
+
+	// 按截断标记中的 offset 依次读取，拼接后得到完整内容
+	text := full[:truncated.Returned]
+	offset := truncated.Returned
+	for offset < len(full) {
+		content, mimeType, ok, err := tm.ReadResource(fmt.Sprintf("%s?offset=%d", truncated.URI, offset))
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, "text/plain", mimeType)
+		part, _, _ := strings.Cut(content, "\n\n[truncated:")
+		require.NotEmpty(t, part)
+		assert.True(t, utf8.ValidString(part))
+		assert.LessOrEqual(t, len(part), 100)
+		text += part
+		offset += len(part)
+	}
+	assert.Equal(t, full, text)
+
+	_, _, _, err = tm.ReadResource(truncated.URI + "?offset=-1")
+	assert.Error(t, err)
+	_, _, _, err = tm.ReadResource(tools.ResultURIPrefix + "missing")
+	assert.Error(t, err)
+
+	// 单个工具的上限优先，0 为不限制
+	cfg.ToolResultLimits = map[string]int{"large_result": 1 << 20}
+	tm.ReloadConfig(cfg)
+	result, err = tm.CallTool(context.Background(), "large_result", json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.Nil(t, result.Meta)
+	assert.Equal(t, full, result.Content[0].Text)
+}
+
+func TestResultStoreExpiry(t *testing.T) {
+	now := time.Now()
+	store := tools.NewResultStore(time.Minute, 64)
+	store.SetClock(func() time.Time { return now })
+
+	first, err := store.Save(strings.Repeat("a", 40), 10)
+	require.NoError(t, err)
+	content, err := store.Read(first)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(content, strings.Repeat("a", 10)))
+	assert.Contains(t, content, first+"?offset=10")
+
+	// 总大小超过上限时淘汰最早保存的内容，单个结果超过上限时拒绝保存
+	second, err := store.Save(strings.Repeat("b", 40), 10)
+	require.NoError(t, err)
+	_, err = store.Read(first)
+	assert.Error(t, err)
+	_, err = store.Save(strings.Repeat("c", 65), 10)
+	assert.Error(t, err)
+
+	content, err = store.Read(second + "?offset=30")
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("b", 10), content)
+
+	// 超过保留时长后过期
+	now = now.Add(2 * time.Minute)
+	_, err = store.Read(second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeShopServer 三页商品列表，/private 被 robots.txt 禁止
+func newFakeShopServer(t *testing.T, robotsRequests *int32) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(robotsRequests, 1)
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	})
+	mux.HandleFunc("/products", func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, "<html><head><style>.x{}</style></head><body><ul>")
+		for i := 1; i <= 2; i++ {
+			fmt.Fprintf(w, `<li class="product"><h2>Item %s-%d</h2><p>Great <b>value</b></p><span class="price">%d.00</span><a href="/item/%s-%d">more</a><span class="tag">a</span><span class="tag">b</span></li>`, page, i, i*10, page, i)
+		}
+		fmt.Fprint(w, "</ul>")
+		if page != "3" {
+			fmt.Fprintf(w, `<a rel="next" href="?page=%d">Next</a>`, int(page[0]-'0')+1)
+		}
+		fmt.Fprint(w, "<script>var x = 1;</script></body></html>")
+	})
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("disallowed page fetched")
+	})
+	mux.HandleFunc("/data.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestScrapeTool(cfg config.ScrapeConfig) *tools.ScrapeTool {
+	return tools.NewScrapeTool(cfg, tools.NewHTTPFetchTool(config.HTTPFetchConfig{AllowPrivate: true}))
+}
+
+func runScrape(t *testing.T, tool *tools.ScrapeTool, args string) tools.ScrapeResult {
+	t.Helper()
+	raw, err := tool.Execute(context.Background(), json.RawMessage(args))
+	require.NoError(t, err)
+	var result tools.ScrapeResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result
+}
+
+func TestScrapeRecordsAndPagination(t *testing.T) {
+	var robotsRequests int32
+	server := newFakeShopServer(t, &robotsRequests)
+	tool := newTestScrapeTool(config.ScrapeConfig{})
+
+	result := runScrape(t, tool, `{"url":"`+server.URL+`/products","selector":"li.product","fields":{
+		"name":{"selector":"h2"},
+		"description":{"xpath":".//p"},
+		"price":{"selector":".price"},
+		"link":{"selector":"a","attr":"href"},
+		"tags":{"selector":".tag","all":true},
+		"missing":{"selector":".none"}
+	},"next":"a[rel=next]"}`)
+
+	require.Len(t, result.Pages, 3)
+	require.Len(t, result.Records, 6)
+	assert.Empty(t, result.Next)
+	assert.False(t, result.Truncated)
+	assert.Equal(t, server.URL+"/products?page=3", result.Pages[2].URL)
+	assert.Equal(t, 2, result.Pages[2].Records)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&robotsRequests), "robots.txt is cached per site")
+
+	first := result.Records[0]
+	assert.Equal(t, "Item 1-1", first["name"])
+	assert.Equal(t, "Great value", first["description"])
+	assert.Equal(t, "10.00", first["price"])
+	assert.Equal(t, server.URL+"/item/1-1", first["link"])
+	assert.Equal(t, []interface{}{"a", "b"}, first["tags"])
+	assert.Nil(t, first["missing"])
+	assert.Equal(t, "Item 3-2", result.Records[5]["name"])
+
+	t.Run("limits", func(t *testing.T) {
+		result := runScrape(t, tool, `{"url":"`+server.URL+`/products","xpath":"//li[@class='product']","next_xpath":"//a[@rel='next']","max_pages":2}`)
+		assert.Len(t, result.Pages, 2)
+		assert.Len(t, result.Records, 4)
+		assert.Equal(t, server.URL+"/products?page=3", result.Next)
+		assert.Contains(t, result.Records[0]["text"], "Item 1-1 Great value 10.00")
+
+		result = runScrape(t, tool, `{"url":"`+server.URL+`/products","selector":"li.product","next":"a[rel=next]","max_records":3}`)
+		assert.Len(t, result.Pages, 2)
+		assert.Len(t, result.Records, 3)
+		assert.True(t, result.Truncated)
+	})
+
+	t.Run("whole page", func(t *testing.T) {
+		result := runScrape(t, tool, `{"url":"`+server.URL+`/products?page=3"}`)
+		require.Len(t, result.Records, 1)
+		text := result.Records[0]["text"].(string)
+		assert.Contains(t, text, "Item 3-1 Great value 10.00")
+		assert.NotContains(t, text, "var x")
+	})
+}
+
+func TestScrapeRobotsAndValidation(t *testing.T) {
+	var robotsRequests int32
+	server := newFakeShopServer(t, &robotsRequests)
+	tool := newTestScrapeTool(config.ScrapeConfig{})
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"`+server.URL+`/private/page"}`))
+	assert.True(t, errors.Is(err, tools.ErrRobotsDisallowed))
+
+	ignoring := newTestScrapeTool(config.ScrapeConfig{IgnoreRobots: true})
+	_, err = ignoring.Execute(context.Background(), json.RawMessage(`{"url":"`+server.URL+`/data.json"}`))
+	assert.ErrorContains(t, err, "not HTML")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"url":"`+server.URL+`/products","selector":"li","xpath":"//li"}`))
+	assert.ErrorContains(t, err, "mutually exclusive")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"url":"`+server.URL+`/products","selector":"li[["}`))
+	assert.ErrorContains(t, err, "invalid selector")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"url":"`+server.URL+`/products","fields":{"x":{"xpath":"//["}}}`))
+	assert.ErrorContains(t, err, "invalid fields.x.xpath")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"url":"`+server.URL+`/missing"}`))
+	assert.ErrorContains(t, err, "status 404")
+
+	// 默认拒绝内网地址
+	blocked := tools.NewScrapeTool(config.ScrapeConfig{}, tools.NewHTTPFetchTool(config.HTTPFetchConfig{}))
+	_, err = blocked.Execute(context.Background(), json.RawMessage(`{"url":"`+server.URL+`/products"}`))
+	assert.True(t, errors.Is(err, tools.ErrFetchBlocked))
+}
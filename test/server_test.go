@@ -0,0 +1,394 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rpcReply JSON-RPC 响应体
+type rpcReply struct {
+	ID     interface{}       `json:"id"`
+	Result json.RawMessage   `json:"result"`
+	Error  *testkit.RPCError `json:"error"`
+}
+
+// postMCP 向 /mcp 发送请求体，stream 为 true 时声明接受 SSE
+func postMCP(t *testing.T, url, body string, stream bool) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, url+"/mcp", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// decodeReply 读取并解析 JSON-RPC 响应体
+func decodeReply(t *testing.T, resp *http.Response) rpcReply {
+	var reply rpcReply
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply))
+	return reply
+}
+
+// toolCall 构造 tools/call 请求体
+func toolCall(id int, name, arguments string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":%q,"arguments":%s}}`, id, name, arguments)
+}
+
+// readEvent 从 SSE 流中读取下一个事件
+func readEvent(t *testing.T, reader *bufio.Reader) testkit.Event {
+	var event testkit.Event
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err, "stream ended before the next event")
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			if event.Event != "" {
+				return event
+			}
+		case strings.HasPrefix(line, "id: "):
+			event.ID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event.Data = json.RawMessage(strings.TrimPrefix(line, "data: "))
+		}
+	}
+}
+
+// newHandlerServer 以 httptest 提供只包含指定工具的服务器，返回服务器与基础地址
+func newHandlerServer(t *testing.T, configure func(cfg *config.Config), toolList ...tools.Tool) (*testkit.Server, string) {
+	srv := testkit.NewServer(t, configure, toolList...)
+	httpSrv := httptest.NewServer(srv.Handler())
+	t.Cleanup(httpSrv.Close)
+	return srv, httpSrv.URL
+}
+
+func TestServerToolsCall(t *testing.T) {
+	echo := testkit.NewMockTool("echo").Handle(func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+		return args, nil
+	})
+	failing := testkit.NewMockTool("failing").Fails(errors.New("backend unavailable"))
+	crashy := testkit.NewMockTool("crashy").Panics("boom")
+	_, url := newHandlerServer(t, nil, echo, failing, crashy)
+
+	t.Run("tools/list", func(t *testing.T) {
+		reply := decodeReply(t, postMCP(t, url, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`, false))
+		require.Nil(t, reply.Error)
+		var result struct {
+			Tools []struct{ Name string } `json:"tools"`
+		}
+		require.NoError(t, json.Unmarshal(reply.Result, &result))
+		require.Len(t, result.Tools, 3)
+		assert.Equal(t, "crashy", result.Tools[0].Name)
+	})
+
+	t.Run("成功调用", func(t *testing.T) {
+		resp := postMCP(t, url, toolCall(2, "echo", `{"message":"hi"}`), false)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Type"), "application/json")
+		reply := decodeReply(t, resp)
+		require.Nil(t, reply.Error)
+		assert.EqualValues(t, 2, reply.ID)
+
+		var result tools.ToolCallResult
+		require.NoError(t, json.Unmarshal(reply.Result, &result))
+		require.Len(t, result.Content, 1)
+		assert.Equal(t, "text", result.Content[0].Type)
+		assert.JSONEq(t, `{"message":"hi"}`, result.Content[0].Text)
+		assert.False(t, result.IsError)
+	})
+
+	t.Run("字符串 ID 原样返回", func(t *testing.T) {
+		body := `{"jsonrpc":"2.0","id":"req-1","method":"tools/call","params":{"name":"echo","arguments":{}}}`
+		reply := decodeReply(t, postMCP(t, url, body, false))
+		require.Nil(t, reply.Error)
+		assert.Equal(t, "req-1", reply.ID)
+	})
+
+	t.Run("工具执行失败", func(t *testing.T) {
+		reply := decodeReply(t, postMCP(t, url, toolCall(3, "failing", `{}`), false))
+		require.NotNil(t, reply.Error)
+		assert.Equal(t, -32603, reply.Error.Code)
+		assert.Contains(t, reply.Error.Message, "backend unavailable")
+		assert.Equal(t, 1, failing.CallCount())
+	})
+
+	t.Run("工具 panic 返回错误结果", func(t *testing.T) {
+		reply := decodeReply(t, postMCP(t, url, toolCall(4, "crashy", `{}`), false))
+		require.Nil(t, reply.Error)
+		var result tools.ToolCallResult
+		require.NoError(t, json.Unmarshal(reply.Result, &result))
+		assert.True(t, result.IsError)
+	})
+
+	t.Run("未知工具", func(t *testing.T) {
+		reply := decodeReply(t, postMCP(t, url, toolCall(5, "missing", `{}`), false))
+		require.NotNil(t, reply.Error)
+		assert.Equal(t, -32602, reply.Error.Code)
+	})
+}
+
+func TestServerMalformedJSON(t *testing.T) {
+	echo := testkit.NewMockTool("echo")
+	_, url := newHandlerServer(t, nil, echo)
+
+	cases := map[string]struct {
+		body string
+		code int
+	}{
+		"截断的 JSON":            {`{"jsonrpc":"2.0","id":1,"method":`, -32700},
+		"空请求体":                {``, -32700},
+		"非对象":                 {`"tools/list"`, -32700},
+		"错误的版本":               {`{"jsonrpc":"1.0","id":1,"method":"tools/list"}`, -32600},
+		"方法不是字符串":             {`{"jsonrpc":"2.0","id":1,"method":42}`, -32600},
+		"ID 为对象":              {`{"jsonrpc":"2.0","id":{},"method":"tools/list"}`, -32600},
+		"params 类型错误":         {`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":[1,2]}`, -32602},
+		"arguments 非 JSON 对象": {toolCall(1, "echo", `"text"`), -32602},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resp := postMCP(t, url, tc.body, false)
+			reply := decodeReply(t, resp)
+			require.NotNil(t, reply.Error, "expected an error response")
+			assert.Equal(t, tc.code, reply.Error.Code)
+		})
+	}
+	assert.Zero(t, echo.CallCount(), "malformed requests must not reach the tool")
+}
+
+func TestServerStreamingSSE(t *testing.T) {
+	release := make(chan struct{})
+	writer := testkit.NewMockTool("writer").Then(testkit.Response{
+		Chunks: []string{"first", "second"},
+		Handler: func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+			select {
+			case <-release:
+				return json.RawMessage(`"first second"`), nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+	})
+	_, url := newHandlerServer(t, nil, writer)
+
+	resp := postMCP(t, url, toolCall(1, "writer", `{}`), true)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+
+	started := readEvent(t, reader)
+	assert.Equal(t, "tool/call", started.Event)
+	assert.JSONEq(t, `{"status":"started","tool":"writer"}`, string(started.Data))
+
+	// 工具仍在执行时片段已送达客户端
+	for i, text := range []string{"first", "second"} {
+		event := readEvent(t, reader)
+		assert.Equal(t, "content", event.Event)
+		var content struct {
+			Content string `json:"content"`
+			Index   int    `json:"index"`
+		}
+		require.NoError(t, json.Unmarshal(event.Data, &content))
+		assert.Equal(t, text, content.Content)
+		assert.Equal(t, i, content.Index)
+	}
+	close(release)
+
+	done := readEvent(t, reader)
+	assert.Equal(t, "done", done.Event)
+	assert.JSONEq(t, `{"result":{"content":[{"type":"text","text":"\"first second\""}]}}`, string(done.Data))
+
+	rest, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Empty(t, strings.TrimSpace(string(rest)), "no events after done")
+}
+
+func TestServerShutdownRejection(t *testing.T) {
+	running := make(chan struct{})
+	blocker := testkit.NewMockTool("blocker").Handle(func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+		close(running)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	srv, url := newHandlerServer(t, func(cfg *config.Config) {
+		cfg.ShutdownDrain = 200 * time.Millisecond
+	}, blocker)
+
+	resp := postMCP(t, url, toolCall(1, "blocker", `{}`), true)
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, "tool/call", readEvent(t, reader).Event)
+	<-running
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- srv.Stop() }()
+
+	// 开始关闭后活跃的流收到通知
+	notice := readEvent(t, reader)
+	assert.Equal(t, "shutdown", notice.Event)
+	assert.Contains(t, string(notice.Data), `"phase":"draining"`)
+
+	// 新请求被拒绝
+	rejected := postMCP(t, url, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, false)
+	assert.Equal(t, http.StatusServiceUnavailable, rejected.StatusCode)
+	health, err := http.Get(url + "/health")
+	require.NoError(t, err)
+	health.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, health.StatusCode)
+
+	// 排空窗口结束后流以终止事件结束
+	final := readEvent(t, reader)
+	assert.Equal(t, "shutdown", final.Event)
+	assert.Contains(t, string(final.Data), `"phase":"closed"`)
+
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+func TestServerAdminDrain(t *testing.T) {
+	running := make(chan struct{})
+	blocker := testkit.NewMockTool("blocker").Handle(func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+		close(running)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	_, url := newHandlerServer(t, func(cfg *config.Config) {
+		cfg.APIKey = "admin-secret"
+		cfg.ShutdownDrain = 300 * time.Millisecond
+	}, blocker, testkit.NewMockTool("quick").Returns("ok"))
+
+	// 配置 API 密钥后 /mcp 与管理接口都需要鉴权
+	post := func(body string, stream bool) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, url+"/mcp", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "admin-secret")
+		if stream {
+			req.Header.Set("Accept", "text/event-stream")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	admin := func(method string) map[string]interface{} {
+		req, err := http.NewRequest(method, url+"/admin/drain", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-API-Key", "admin-secret")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var status map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+		return status
+	}
+
+	resp := post(toolCall(1, "blocker", `{}`), true)
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, "tool/call", readEvent(t, reader).Event)
+	<-running
+
+	// 开始排空后活跃的流收到通知，状态报告排空进度
+	status := admin(http.MethodPost)
+	assert.Equal(t, true, status["draining"])
+	assert.Equal(t, "draining", status["phase"])
+	assert.Equal(t, float64(1), status["initial_operations"])
+	assert.Contains(t, status, "deadline")
+
+	notice := readEvent(t, reader)
+	assert.Equal(t, "shutdown", notice.Event)
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(notice.Data, &data))
+	assert.Equal(t, "draining", data["phase"])
+	assert.Equal(t, "server draining", data["message"])
+
+	rejected := post(toolCall(2, "quick", `{}`), false)
+	assert.Equal(t, http.StatusServiceUnavailable, rejected.StatusCode)
+
+	// 排空窗口结束后剩余的流被终止
+	final := readEvent(t, reader)
+	assert.Equal(t, "shutdown", final.Event)
+	assert.Contains(t, string(final.Data), `"phase":"closed"`)
+	assert.Eventually(t, func() bool {
+		return admin(http.MethodGet)["phase"] == "drained"
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// 取消排空后恢复接收请求，新的流不会立即收到通知
+	status = admin(http.MethodDelete)
+	assert.Equal(t, false, status["draining"])
+	assert.NotContains(t, status, "phase")
+
+	resp = post(toolCall(3, "quick", `{}`), true)
+	reader = bufio.NewReader(resp.Body)
+	assert.Equal(t, "tool/call", readEvent(t, reader).Event)
+	assert.Equal(t, "done", readEvent(t, reader).Event)
+}
+
+func TestServerConnectionPoolExhaustion(t *testing.T) {
+	held := make(chan struct{})
+	release := make(chan struct{})
+	holder := testkit.NewMockTool("holder").Then(testkit.Response{
+		Handler: func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+			close(held)
+			<-release
+			return json.RawMessage(`"released"`), nil
+		},
+	})
+	_, url := newHandlerServer(t, func(cfg *config.Config) {
+		cfg.MaxConnections = 1
+	}, holder)
+
+	first := make(chan rpcReply, 1)
+	go func() {
+		resp := postMCP(t, url, toolCall(1, "holder", `{}`), false)
+		var reply rpcReply
+		json.NewDecoder(resp.Body).Decode(&reply)
+		first <- reply
+	}()
+	<-held
+
+	// 唯一的连接被占用时其他请求返回连接数超限错误
+	reply := decodeReply(t, postMCP(t, url, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, false))
+	require.NotNil(t, reply.Error)
+	assert.Equal(t, -32000, reply.Error.Code)
+	assert.Contains(t, reply.Error.Message, "Connection limit exceeded")
+	assert.EqualValues(t, 2, reply.ID)
+
+	close(release)
+	select {
+	case reply := <-first:
+		require.Nil(t, reply.Error)
+		assert.Contains(t, string(reply.Result), "released")
+	case <-time.After(5 * time.Second):
+		t.Fatal("held request did not finish")
+	}
+
+	// 连接归还后恢复处理
+	reply = decodeReply(t, postMCP(t, url, `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`, false))
+	assert.Nil(t, reply.Error)
+}
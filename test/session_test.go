@@ -0,0 +1,149 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionQuotas(t *testing.T) {
+	t.Run("软配额淘汰最早资源", func(t *testing.T) {
+		store := mcp.NewSessionStore(100, 200, time.Minute, newTestLogger(t))
+		session := store.Create("quota-client")
+
+		for _, name := range []string{"a", "b", "c"} {
+			_, err := session.Publish(name, "text/plain", bytes.Repeat([]byte("x"), 40))
+			require.NoError(t, err)
+		}
+
+		info := session.Info()
+		assert.Equal(t, int64(80), info.Bytes)
+		assert.Equal(t, int64(1), info.Evicted)
+
+		_, exists := session.Resource(mcp.SessionURIPrefix + "a")
+		assert.False(t, exists)
+		_, exists = session.Resource(mcp.SessionURIPrefix + "c")
+		assert.True(t, exists)
+	})
+
+	t.Run("硬配额拒绝发布", func(t *testing.T) {
+		store := mcp.NewSessionStore(100, 200, time.Minute, newTestLogger(t))
+		session := store.Create("quota-client")
+
+		_, err := session.Publish("small", "text/plain", bytes.Repeat([]byte("x"), 50))
+		require.NoError(t, err)
+
+		_, err = session.Publish("huge", "application/octet-stream", bytes.Repeat([]byte("x"), 180))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "quota exceeded")
+
+		info := session.Info()
+		assert.Equal(t, int64(50), info.Bytes)
+		assert.Equal(t, int64(1), info.Rejected)
+	})
+
+	t.Run("同名资源替换", func(t *testing.T) {
+		store := mcp.NewSessionStore(100, 200, time.Minute, newTestLogger(t))
+		session := store.Create("quota-client")
+
+		_, err := session.Publish("report", "text/plain", bytes.Repeat([]byte("x"), 60))
+		require.NoError(t, err)
+		_, err = session.Publish("report", "text/plain", bytes.Repeat([]byte("y"), 70))
+		require.NoError(t, err)
+
+		info := session.Info()
+		assert.Equal(t, int64(70), info.Bytes)
+		assert.Equal(t, 1, info.Resources)
+	})
+
+	t.Run("会话资源相互隔离", func(t *testing.T) {
+		store := mcp.NewSessionStore(0, 0, time.Minute, newTestLogger(t))
+		first := store.Create("client-a")
+		second := store.Create("client-b")
+
+		_, err := first.Publish("shared", "text/plain", []byte("first"))
+		require.NoError(t, err)
+
+		_, exists := second.Resource(mcp.SessionURIPrefix + "shared")
+		assert.False(t, exists)
+		assert.Len(t, store.List(), 2)
+
+		assert.True(t, store.Delete(first.ID))
+		_, exists = store.Get(first.ID)
+		assert.False(t, exists)
+	})
+}
+
+func TestPublishResource(t *testing.T) {
+	_, err := tools.PublishResource(context.Background(), "orphan", "text/plain", []byte("data"))
+	assert.Error(t, err)
+
+	store := mcp.NewSessionStore(0, 0, time.Minute, newTestLogger(t))
+	session := store.Create("publisher")
+	ctx := tools.WithPublisher(context.Background(), session)
+
+	uri, err := tools.PublishResource(ctx, "result.json", "application/json", []byte(`{"ok":true}`))
+	require.NoError(t, err)
+	assert.Equal(t, mcp.SessionURIPrefix+"result.json", uri)
+
+	resources := session.Resources()
+	require.Len(t, resources, 1)
+	assert.Equal(t, "application/json", resources[0].MimeType)
+}
+
+func TestSessionTenantIsolation(t *testing.T) {
+	srv := testkit.NewServer(t, func(cfg *config.Config) {
+		cfg.ToolConfig.Tenants = map[string]config.TenantConfig{
+			"team-a": {APIKeys: []string{"key-a"}},
+			"team-b": {APIKeys: []string{"key-b"}},
+		}
+	}, testkit.NewMockTool("echo").Returns("ok"))
+
+	srv.SetHeader("X-API-Key", "key-a")
+	resp, err := srv.Initialize("tenant-client")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.Status)
+	sessionID := resp.Header.Get(mcp.SessionIDHeader)
+	assert.Regexp(t, `^session_[0-9a-f]{32}$`, sessionID)
+
+	resp, err = srv.Call(mcp.MethodToolsList, nil)
+	require.NoError(t, err)
+	assert.Nil(t, resp.Error)
+
+	// 其他租户持有会话ID也无法使用、订阅或结束该会话
+	srv.SetHeader("X-API-Key", "key-b")
+	resp, err = srv.Call(mcp.MethodToolsList, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.Status)
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "session not found")
+
+	request := func(method, apiKey string) int {
+		req := httptest.NewRequest(method, "/mcp", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set(mcp.SessionIDHeader, sessionID)
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "key-b"))
+	assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, "key-b"))
+
+	assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, "key-a"))
+	srv.SetHeader("X-API-Key", "key-a")
+	resp, err = srv.Call(mcp.MethodToolsList, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.Status)
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// largeResultTool 原样返回固定文本的非流式测试工具
+type largeResultTool struct {
+	text string
+}
+
+func (lt *largeResultTool) Name() string                 { return "large_result" }
+func (lt *largeResultTool) Description() string          { return "returns a fixed text" }
+func (lt *largeResultTool) Category() tools.ToolCategory { return tools.CategoryUtility }
+
+func (lt *largeResultTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	return json.RawMessage(lt.text), nil
+}
+
+func TestStreamChunkedFallback(t *testing.T) {
+	tm := tools.NewToolManager(newTestLogger(t), newTestToolConfig())
+	tool := &largeResultTool{text: strings.Repeat("第一行数据\n", 30) + strings.Repeat("数", 50)}
+	require.NoError(t, tm.RegisterTool(tool))
+	tm.SetStreamChunkSize(64)
+
+	var chunks []string
+	result, err := tm.CallToolStream(context.Background(), "large_result", json.RawMessage(`{}`), func(content string, index int) {
+		assert.Equal(t, len(chunks), index)
+		chunks = append(chunks, content)
+	})
+	require.NoError(t, err)
+
+	// 片段按顺序拼接为完整结果，且不超过片段大小、不拆分字符
+	require.Greater(t, len(chunks), 1)
+	assert.Equal(t, result.Content[0].Text, strings.Join(chunks, ""))
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, len(chunk), 64)
+		assert.True(t, utf8.ValidString(chunk))
+	}
+	assert.True(t, strings.HasSuffix(chunks[0], "\n"))
+
+	// 不超过一个片段的结果与关闭拆分时只返回结果
+	chunks = nil
+	tm.SetStreamChunkSize(0)
+	_, err = tm.CallToolStream(context.Background(), "large_result", json.RawMessage(`{}`), func(content string, index int) {
+		chunks = append(chunks, content)
+	})
+	require.NoError(t, err)
+	assert.Empty(t, chunks)
+
+	tm.SetStreamChunkSize(-1)
+	tool.text = strings.Repeat("x", tools.DefaultStreamChunkSize*2)
+	_, err = tm.CallToolStream(context.Background(), "large_result", json.RawMessage(`{}`), func(content string, index int) {
+		chunks = append(chunks, content)
+	})
+	require.NoError(t, err)
+	assert.Empty(t, chunks)
+}
@@ -0,0 +1,121 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emitterTool 以 Emitter 推送片段、进度与日志的测试工具
+type emitterTool struct{}
+
+func (emitterTool) Name() string                 { return "emitter" }
+func (emitterTool) Description() string          { return "emitter test tool" }
+func (emitterTool) Category() tools.ToolCategory { return tools.CategoryUtility }
+func (t emitterTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	return t.Stream(ctx, args, tools.CallbackEmitter(nil))
+}
+
+func (emitterTool) Stream(ctx context.Context, args json.RawMessage, emit tools.Emitter) (json.RawMessage, error) {
+	emit.Partial("a")
+	emit.Progress(1, 2, "half")
+	emit.Log(tools.LogWarning, "careful")
+	emit.Partial("b")
+	return json.RawMessage(`{"ok":true}`), nil
+}
+
+// collectEvents 以 CallToolEvents 调用工具并收集全部事件
+func collectEvents(t *testing.T, tm *tools.ToolManager, name, args string) ([]tools.StreamEvent, *tools.ToolCallResult) {
+	t.Helper()
+	var events []tools.StreamEvent
+	result, err := tm.CallToolEvents(context.Background(), name, json.RawMessage(args), func(event tools.StreamEvent) {
+		events = append(events, event)
+	})
+	require.NoError(t, err)
+	return events, result
+}
+
+func TestCallToolEvents(t *testing.T) {
+	legacy := testkit.NewMockTool("legacy").Streams(0, "x", "y").Returns(map[string]bool{"ok": true})
+	tm := testkit.NewToolManager(t, emitterTool{}, legacy)
+
+	events, result := collectEvents(t, tm, "emitter", `{}`)
+	require.Len(t, events, 5)
+	assert.Equal(t, tools.StreamEvent{Type: tools.EventPartial, Index: 0, Content: "a"}, events[0])
+	assert.Equal(t, tools.StreamEvent{Type: tools.EventProgress, Progress: 1, Total: 2, Message: "half"}, events[1])
+	assert.Equal(t, tools.StreamEvent{Type: tools.EventLog, Level: tools.LogWarning, Message: "careful"}, events[2])
+	assert.Equal(t, tools.StreamEvent{Type: tools.EventPartial, Index: 1, Content: "b"}, events[3])
+	assert.Equal(t, tools.EventFinal, events[4].Type)
+	assert.Same(t, result, events[4].Result)
+
+	// 旧式 StreamTool 经兼容层保留回调序号
+	events, _ = collectEvents(t, tm, "legacy", `{}`)
+	require.Len(t, events, 3)
+	assert.Equal(t, tools.StreamEvent{Type: tools.EventPartial, Index: 1, Content: "y"}, events[1])
+	assert.Equal(t, tools.EventFinal, events[2].Type)
+
+	// CallToolStream 只推送片段，进度交给上下文中的进度接收者
+	var chunks []string
+	var indexes []int
+	var reports []string
+	ctx := tools.WithProgress(context.Background(), tools.ProgressFunc(func(progress, total float64, message string) {
+		reports = append(reports, message)
+	}))
+	_, err := tm.CallToolStream(ctx, "emitter", json.RawMessage(`{}`), func(content string, index int) {
+		chunks = append(chunks, content)
+		indexes = append(indexes, index)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, chunks)
+	assert.Equal(t, []int{0, 1}, indexes)
+	assert.Equal(t, []string{"half"}, reports)
+}
+
+func TestStreamTextProcessorEvents(t *testing.T) {
+	tm := testkit.NewToolManager(t, &tools.StreamTextProcessor{})
+
+	events, _ := collectEvents(t, tm, "stream_text_processor", `{"text":"hello world","operation":"split"}`)
+	var partials []tools.StreamEvent
+	var progress []float64
+	for _, event := range events {
+		switch event.Type {
+		case tools.EventPartial:
+			partials = append(partials, event)
+		case tools.EventProgress:
+			progress = append(progress, event.Progress)
+		}
+	}
+	require.NotEmpty(t, partials)
+	for i, partial := range partials {
+		assert.Equal(t, i, partial.Index, "partials are numbered consecutively")
+	}
+	assert.Equal(t, "处理完成！", partials[len(partials)-1].Content)
+	assert.Equal(t, []float64{0, 1, 2, 3}, progress)
+	assert.Equal(t, tools.EventFinal, events[len(events)-1].Type)
+}
+
+func TestServerStreamingLogEvents(t *testing.T) {
+	srv := testkit.NewServer(t, nil, emitterTool{})
+
+	events, err := srv.StreamTool("emitter", map[string]interface{}{})
+	require.NoError(t, err)
+	var names []string
+	for _, event := range events {
+		names = append(names, event.Event)
+	}
+	assert.Equal(t, []string{"tool/call", "content", "progress", "log", "content", "done"}, names)
+
+	var log map[string]interface{}
+	require.NoError(t, json.Unmarshal(events[3].Data, &log))
+	assert.Equal(t, map[string]interface{}{"method": "notifications/message", "level": "warning", "data": "careful"}, log)
+
+	var content map[string]interface{}
+	require.NoError(t, json.Unmarshal(events[4].Data, &content))
+	assert.Equal(t, float64(1), content["index"])
+}
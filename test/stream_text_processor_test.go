@@ -1,126 +1,301 @@
-package test
-
-import (
-	"context"
-	"encoding/json"
-	"testing"
-
-	"Weave-Toolkit/internal/tools"
-
-	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/require"
-)
-
-func TestStreamTextProcessor(t *testing.T) {
-	processor := &tools.StreamTextProcessor{}
-
-	tests := []struct {
-		name      string
-		text      string
-		operation string
-		expected  interface{}
-		hasError  bool
-	}{
-		{
-			name:      "文本分割",
-			text:      "hello world test",
-			operation: "split",
-			expected:  []interface{}{"hello", "world", "test"},
-		},
-		{
-			name:      "文本反转",
-			text:      "hello",
-			operation: "reverse",
-			expected:  "olleh",
-		},
-		{
-			name:      "字符计数",
-			text:      "hello world",
-			operation: "count",
-			expected: map[string]interface{}{
-				"characters": float64(11),
-				"words":      float64(2),
-				"lines":      float64(1),
-			},
-		},
-		{
-			name:      "文本分析",
-			text:      "Hello World!",
-			operation: "analyze",
-			expected: map[string]interface{}{
-				"length":        float64(12),
-				"word_count":    float64(2),
-				"line_count":    float64(1),
-				"has_uppercase": true,
-				"has_lowercase": true,
-			},
-		},
-		{
-			name:      "无效操作",
-			text:      "test",
-			operation: "invalid",
-			hasError:  true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			args := map[string]interface{}{
-				"text":      tt.text,
-				"operation": tt.operation,
-			}
-
-			argsJSON, err := json.Marshal(args)
-			require.NoError(t, err)
-
-			result, err := processor.Execute(context.Background(), argsJSON)
-
-			if tt.hasError {
-				assert.Error(t, err)
-				return
-			}
-
-			require.NoError(t, err)
-
-			var textResult tools.StreamTextResult
-			err = json.Unmarshal(result, &textResult)
-			require.NoError(t, err)
-
-			assert.Equal(t, tt.text, textResult.OriginalText)
-			assert.Equal(t, tt.operation, textResult.Operation)
-
-			// 使用JSON序列化比较结果，避免类型不匹配问题
-			expectedJSON, _ := json.Marshal(tt.expected)
-			actualJSON, _ := json.Marshal(textResult.Result)
-			assert.JSONEq(t, string(expectedJSON), string(actualJSON))
-		})
-	}
-}
-
-func TestStreamTextProcessorDefaultOperation(t *testing.T) {
-	processor := &tools.StreamTextProcessor{}
-
-	// 测试默认操作（analyze）
-	args := map[string]interface{}{
-		"text": "Test Text",
-	}
-
-	argsJSON, err := json.Marshal(args)
-	require.NoError(t, err)
-
-	result, err := processor.Execute(context.Background(), argsJSON)
-	require.NoError(t, err)
-
-	var textResult tools.StreamTextResult
-	err = json.Unmarshal(result, &textResult)
-	require.NoError(t, err)
-
-	assert.Equal(t, "Test Text", textResult.OriginalText)
-	assert.Equal(t, "analyze", textResult.Operation)
-
-	// 验证分析结果
-	analysis, ok := textResult.Result.(map[string]interface{})
-	require.True(t, ok)
-	assert.Equal(t, float64(9), analysis["length"])
-	assert.Equal(t, float64(2), analysis["word_count"])
-}
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamTextProcessor(t *testing.T) {
+	processor := &tools.StreamTextProcessor{}
+
+	tests := []struct {
+		name      string
+		text      string
+		operation string
+		expected  interface{}
+		hasError  bool
+	}{
+		{
+			name:      "文本分割",
+			text:      "hello world test",
+			operation: "split",
+			expected:  []interface{}{"hello", "world", "test"},
+		},
+		{
+			name:      "文本反转",
+			text:      "hello",
+			operation: "reverse",
+			expected:  "olleh",
+		},
+		{
+			name:      "字符计数",
+			text:      "hello world",
+			operation: "count",
+			expected: map[string]interface{}{
+				"bytes":      float64(11),
+				"characters": float64(11),
+				"graphemes":  float64(11),
+				"words":      float64(2),
+				"lines":      float64(1),
+				"sentences":  float64(1),
+				"tokens":     float64(2),
+				"encoding":   "o200k_base",
+			},
+		},
+		{
+			name:      "文本分析",
+			text:      "Hello World!",
+			operation: "analyze",
+			expected: map[string]interface{}{
+				"length":         float64(12),
+				"word_count":     float64(2),
+				"line_count":     float64(1),
+				"sentence_count": float64(1),
+				"has_uppercase":  true,
+				"has_lowercase":  true,
+				"language":       map[string]interface{}{"code": "und", "name": "Undetermined", "script": "Latin", "confidence": float64(0)},
+				"top_words":      []interface{}{map[string]interface{}{"word": "hello", "count": float64(1)}, map[string]interface{}{"word": "world", "count": float64(1)}},
+			},
+		},
+		{
+			name:      "无效操作",
+			text:      "test",
+			operation: "invalid",
+			hasError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]interface{}{
+				"text":      tt.text,
+				"operation": tt.operation,
+			}
+
+			argsJSON, err := json.Marshal(args)
+			require.NoError(t, err)
+
+			result, err := processor.Execute(context.Background(), argsJSON)
+
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var textResult tools.StreamTextResult
+			err = json.Unmarshal(result, &textResult)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.text, textResult.OriginalText)
+			assert.Equal(t, tt.operation, textResult.Operation)
+
+			// 使用JSON序列化比较结果，避免类型不匹配问题
+			expectedJSON, _ := json.Marshal(tt.expected)
+			actualJSON, _ := json.Marshal(textResult.Result)
+			assert.JSONEq(t, string(expectedJSON), string(actualJSON))
+		})
+	}
+}
+
+func TestStreamTextProcessorDefaultOperation(t *testing.T) {
+	processor := &tools.StreamTextProcessor{}
+
+	// 测试默认操作（analyze）
+	args := map[string]interface{}{
+		"text": "Test Text",
+	}
+
+	argsJSON, err := json.Marshal(args)
+	require.NoError(t, err)
+
+	result, err := processor.Execute(context.Background(), argsJSON)
+	require.NoError(t, err)
+
+	var textResult tools.StreamTextResult
+	err = json.Unmarshal(result, &textResult)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Test Text", textResult.OriginalText)
+	assert.Equal(t, "analyze", textResult.Operation)
+
+	// 验证分析结果
+	analysis, ok := textResult.Result.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, float64(9), analysis["length"])
+	assert.Equal(t, float64(2), analysis["word_count"])
+}
+
+func TestStreamTextProcessorUnicodeCounts(t *testing.T) {
+	count := func(text, model string) map[string]interface{} {
+		t.Helper()
+		args, err := json.Marshal(map[string]interface{}{"text": text, "operation": "count", "model": model})
+		require.NoError(t, err)
+		result, err := (&tools.StreamTextProcessor{}).Execute(context.Background(), args)
+		require.NoError(t, err)
+		var textResult tools.StreamTextResult
+		require.NoError(t, json.Unmarshal(result, &textResult))
+		return textResult.Result.(map[string]interface{})
+	}
+
+	// é 由 e 与组合重音符组成，家庭 emoji 由 ZWJ 连接，国旗由两个区域指示符组成
+	counts := count("你好，世界。Cafe\u0301 👨\u200d👩\u200d👧 🇨🇳! Done?", "")
+	assert.Equal(t, float64(59), counts["bytes"])
+	assert.Equal(t, float64(27), counts["characters"])
+	assert.Equal(t, float64(21), counts["graphemes"])
+	assert.Equal(t, float64(3), counts["sentences"])
+	assert.Equal(t, "o200k_base", counts["encoding"])
+
+	assert.Equal(t, "cl100k_base", count("hello", "gpt-4")["encoding"])
+	assert.Equal(t, "p50k_base", count("hello", "p50k_base")["encoding"])
+	assert.Equal(t, "cl100k_base", count("hello", "some-local-model")["encoding"])
+}
+
+func processText(t *testing.T, ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	t.Helper()
+	data, err := json.Marshal(args)
+	require.NoError(t, err)
+	result, err := (&tools.StreamTextProcessor{}).Execute(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	var textResult tools.StreamTextResult
+	require.NoError(t, json.Unmarshal(result, &textResult))
+	return textResult.Result, nil
+}
+
+func TestStreamTextProcessorTransforms(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		operation string
+		text      string
+		expected  interface{}
+	}{
+		{"upper", "Hello, Wörld", "HELLO, WÖRLD"},
+		{"lower", "Hello, Wörld", "hello, wörld"},
+		{"title", "the quick brown fox", "The Quick Brown Fox"},
+		{"snake", "parseHTTPServer config-v2", "parse_http_server_config_v2"},
+		{"kebab", "UserID lookup", "user-id-lookup"},
+		{"camel", "max_retry count\nuser name", "maxRetryCount\nuserName"},
+		{"dedupe", "b\na\nb\nc\na", "b\na\nc"},
+		{"dedupe", "b\r\na\nb\r\nc\r\n", "b\na\nc"},
+		{"dedupe", "a\n\nb\n\n", "a\n\nb"},
+		{"sort", "pear\napple\nBanana\n", "Banana\napple\npear"},
+		{"sort", "pear\r\napple\r\nBanana\r\n", "Banana\napple\npear"},
+		{"extract_urls", "See https://example.com/a?b=1, (http://foo.org/x_(y)) and https://example.com/a?b=1.", []interface{}{"https://example.com/a?b=1", "http://foo.org/x_(y)"}},
+		{"extract_emails", "Mail Bob@Example.com or ops@corp.example.org; bob@example.com again", []interface{}{"Bob@Example.com", "ops@corp.example.org"}},
+		{"normalize_whitespace", "  a \t b  \n\n\n  c\r\n\n", "a b\n\nc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.operation, func(t *testing.T) {
+			result, err := processText(t, ctx, map[string]interface{}{"text": tt.text, "operation": tt.operation})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	// 指定区域设置时按其规则转换大小写与排序
+	result, err := processText(t, tools.WithLocale(ctx, "tr"), map[string]interface{}{"text": "istanbul", "operation": "upper"})
+	require.NoError(t, err)
+	assert.Equal(t, "İSTANBUL", result)
+	result, err = processText(t, tools.WithLocale(ctx, "de"), map[string]interface{}{"text": "Zebra\nÄpfel\nApfel", "operation": "sort"})
+	require.NoError(t, err)
+	assert.Equal(t, "Apfel\nÄpfel\nZebra", result)
+
+	_, err = processText(t, ctx, map[string]interface{}{"text": "abc", "operation": "truncate"})
+	assert.ErrorContains(t, err, "max_tokens")
+	result, err = processText(t, ctx, map[string]interface{}{"text": "one two three four five six", "operation": "truncate", "max_tokens": 3})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"text": "one two three", "tokens": float64(3), "truncated": true, "encoding": "o200k_base"}, result)
+	result, err = processText(t, ctx, map[string]interface{}{"text": "short", "operation": "truncate", "max_tokens": 10})
+	require.NoError(t, err)
+	assert.Equal(t, false, result.(map[string]interface{})["truncated"])
+}
+
+func TestStreamTextProcessorLanguageAnalysis(t *testing.T) {
+	analyze := func(text string, top int) map[string]interface{} {
+		t.Helper()
+		result, err := processText(t, context.Background(), map[string]interface{}{"text": text, "operation": "analyze", "top": top})
+		require.NoError(t, err)
+		return result.(map[string]interface{})
+	}
+	language := func(text string) string {
+		t.Helper()
+		return analyze(text, 0)["language"].(map[string]interface{})["code"].(string)
+	}
+
+	analysis := analyze("The cat sat on the mat. It was a sunny day and the cat was happy.", 2)
+	assert.Equal(t, map[string]interface{}{"code": "en", "name": "English", "script": "Latin", "confidence": 0.9}, analysis["language"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"word": "cat", "count": float64(2)},
+		map[string]interface{}{"word": "day", "count": float64(1)},
+	}, analysis["top_words"])
+	readability := analysis["readability"].(map[string]interface{})
+	assert.Equal(t, float64(8), readability["words_per_sentence"])
+	assert.Equal(t, "very_easy", readability["level"])
+	assert.Greater(t, readability["flesch_reading_ease"], float64(90))
+
+	hard := analyze("The comprehensive institutional accountability of the organization necessitates a sophisticated infrastructure.", 0)["readability"].(map[string]interface{})
+	assert.Equal(t, "very_difficult", hard["level"])
+	assert.Greater(t, hard["flesch_kincaid_grade"], float64(16))
+
+	assert.Equal(t, "es", language("El perro come la comida en la casa con su familia."))
+	assert.Equal(t, "fr", language("Le chat est sur la table et il dort dans la maison."))
+	assert.Equal(t, "de", language("Der Hund ist nicht in dem Haus, aber die Katze ist da."))
+	assert.Equal(t, "zh", language("今天天气很好，我们去公园散步吧。"))
+	assert.Equal(t, "ja", language("今日はいい天気ですね。公園に行きましょう。"))
+	assert.Equal(t, "ko", language("안녕하세요 반갑습니다"))
+	assert.Equal(t, "ru", language("Привет, как дела? Это тест."))
+	assert.Equal(t, "uk", language("Привіт, як справи? Це тест."))
+	assert.Equal(t, "und", language("12345 !!!"))
+
+	// 日文的词频不含平假名组成的助词，非英文不返回可读性
+	japanese := analyze("コーヒーとコーヒーとお茶", 5)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"word": "コーヒー", "count": float64(2)},
+		map[string]interface{}{"word": "茶", "count": float64(1)},
+	}, japanese["top_words"])
+	assert.NotContains(t, japanese, "readability")
+}
+
+func TestStreamTextProcessorPacing(t *testing.T) {
+	args := json.RawMessage(`{"text":"one two three","operation":"split"}`)
+
+	// 默认不等待
+	start := time.Now()
+	var partials []string
+	_, err := tools.NewStreamTextProcessor(config.StreamTextConfig{}).ExecuteStream(context.Background(), args, func(content string, _ int) {
+		partials = append(partials, content)
+	})
+	require.NoError(t, err)
+	assert.Contains(t, partials, "单词 3: three")
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+
+	// 相邻消息之间按配置的间隔等待
+	start = time.Now()
+	_, err = tools.NewStreamTextProcessor(config.StreamTextConfig{PacingMS: 20}).ExecuteStream(context.Background(), args, func(string, int) {})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 6*20*time.Millisecond)
+
+	// 等待期间取消时立即返回，不再推送后续消息
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	partials = nil
+	start = time.Now()
+	_, err = tools.NewStreamTextProcessor(config.StreamTextConfig{PacingMS: 60_000}).ExecuteStream(ctx, args, func(content string, _ int) {
+		partials = append(partials, content)
+		cancel()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, []string{"开始文本处理..."}, partials)
+}
@@ -0,0 +1,163 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSummarizer 模拟 OpenAI 兼容接口，按提示类型返回固定摘要并记录请求
+type fakeSummarizer struct {
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (f *fakeSummarizer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stream   bool `json:"stream"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		prompt := req.Messages[len(req.Messages)-1].Content
+
+		f.mu.Lock()
+		f.prompts = append(f.prompts, prompt)
+		f.mu.Unlock()
+
+		reply := "Final summary."
+		switch {
+		case strings.Contains(prompt, "Excerpt:"):
+			reply = "Part note."
+		case strings.Contains(prompt, "Merge them"):
+			reply = "Merged note."
+		}
+		if !req.Stream {
+			fmt.Fprintf(w, `{"model":"sum-test","choices":[{"message":{"content":%q},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":3}}`, reply)
+			return
+		}
+		for _, word := range strings.SplitAfter(reply, " ") {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", word)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}
+}
+
+func (f *fakeSummarizer) count(marker string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, prompt := range f.prompts {
+		if strings.Contains(prompt, marker) {
+			n++
+		}
+	}
+	return n
+}
+
+func newTestSummarizeTool(t *testing.T, cfg config.SummarizeConfig) (*tools.SummarizeTool, *fakeSummarizer) {
+	t.Helper()
+	fake := &fakeSummarizer{}
+	server := httptest.NewServer(fake.handler(t))
+	t.Cleanup(server.Close)
+	llmTool := tools.NewLLMTool(config.LLMConfig{
+		Providers: map[string]config.LLMProviderConfig{
+			"local": {Type: "openai_compatible", URL: server.URL, Model: "sum-test"},
+		},
+	})
+	return tools.NewSummarizeTool(cfg, llmTool), fake
+}
+
+func summarize(t *testing.T, tool *tools.SummarizeTool, args string) tools.SummarizeResult {
+	t.Helper()
+	raw, err := tool.Execute(context.Background(), json.RawMessage(args))
+	require.NoError(t, err)
+	var result tools.SummarizeResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result
+}
+
+func TestSummarizeShortText(t *testing.T) {
+	tool, fake := newTestSummarizeTool(t, config.SummarizeConfig{})
+
+	result := summarize(t, tool, `{"text":"The cat sat on the mat.","style":"bullets","max_words":20,"language":"German"}`)
+	assert.Equal(t, "Final summary.", result.Summary)
+	assert.Equal(t, 1, result.Chunks)
+	assert.Equal(t, 1, result.Passes)
+	assert.Equal(t, 1, result.Calls)
+	assert.Equal(t, 10, result.Usage.InputTokens)
+
+	require.Len(t, fake.prompts, 1)
+	assert.Contains(t, fake.prompts[0], "bulleted list")
+	assert.Contains(t, fake.prompts[0], "at most 20 words")
+	assert.Contains(t, fake.prompts[0], "in German")
+	assert.Contains(t, fake.prompts[0], "The cat sat on the mat.")
+}
+
+func TestSummarizeMapReduce(t *testing.T) {
+	// 每段约 104 个估算 token
+	paragraph := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 8)
+	text := strings.Repeat(paragraph+"\n\n", 6)
+
+	t.Run("map then combine", func(t *testing.T) {
+		tool, fake := newTestSummarizeTool(t, config.SummarizeConfig{ChunkTokens: 120, Concurrency: 2})
+
+		var deltas []string
+		raw, err := tool.ExecuteStream(context.Background(), json.RawMessage(mustJSON(t, map[string]interface{}{"text": text})), func(content string, index int) {
+			deltas = append(deltas, content)
+		})
+		require.NoError(t, err)
+		var result tools.SummarizeResult
+		require.NoError(t, json.Unmarshal(raw, &result))
+
+		assert.Equal(t, "Final summary.", result.Summary)
+		assert.Equal(t, "Final summary.", strings.Join(deltas, ""))
+		assert.Equal(t, 6, result.Chunks)
+		assert.Equal(t, 6, fake.count("Excerpt:"))
+		assert.Equal(t, 0, fake.count("Merge them"))
+		assert.Equal(t, 1, fake.count("Combine them"))
+		assert.Equal(t, 2, result.Passes)
+		assert.Equal(t, 7, result.Calls)
+		// 流式的最终请求未返回用量
+		assert.Equal(t, 18, result.Usage.OutputTokens)
+	})
+
+	t.Run("multiple reduce passes", func(t *testing.T) {
+		tool, fake := newTestSummarizeTool(t, config.SummarizeConfig{ChunkTokens: 50})
+
+		result := summarize(t, tool, mustJSON(t, map[string]interface{}{"text": text}))
+		assert.Equal(t, "Final summary.", result.Summary)
+		assert.Greater(t, fake.count("Merge them"), 0)
+		assert.Equal(t, 3, result.Passes)
+		assert.Equal(t, result.Chunks+fake.count("Merge them")+1, result.Calls)
+	})
+}
+
+func TestSummarizeSessionResource(t *testing.T) {
+	tool, fake := newTestSummarizeTool(t, config.SummarizeConfig{})
+	ctx := tools.WithResourceReader(context.Background(), fakeResourceReader{"session://resources/report.txt": "Quarterly revenue grew 12%."})
+
+	_, err := tool.Execute(ctx, json.RawMessage(`{"uri":"session://resources/report.txt"}`))
+	require.NoError(t, err)
+	assert.Contains(t, fake.prompts[0], "Quarterly revenue grew 12%.")
+
+	_, err = tool.Execute(ctx, json.RawMessage(`{"uri":"session://resources/missing.txt"}`))
+	assert.ErrorContains(t, err, "resource not found")
+	_, err = tool.Execute(ctx, json.RawMessage(`{"text":"a","uri":"session://resources/report.txt"}`))
+	assert.ErrorContains(t, err, "mutually exclusive")
+	_, err = tool.Execute(ctx, json.RawMessage(`{"text":"a","style":"haiku"}`))
+	assert.ErrorContains(t, err, "unsupported style")
+}
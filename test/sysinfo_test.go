@@ -0,0 +1,83 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runSysinfo(t *testing.T, tool *tools.SysinfoTool, args string) tools.SysinfoResult {
+	t.Helper()
+	raw, err := tool.Execute(context.Background(), json.RawMessage(args))
+	require.NoError(t, err)
+	var result tools.SysinfoResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result
+}
+
+func TestSysinfoSummary(t *testing.T) {
+	tool := tools.NewSysinfoTool(config.SysinfoConfig{})
+
+	result := runSysinfo(t, tool, `{}`)
+	require.NotNil(t, result.Host)
+	assert.NotEmpty(t, result.Host.Hostname)
+	assert.NotEmpty(t, result.Host.OS)
+	require.NotNil(t, result.CPU)
+	assert.Positive(t, result.CPU.Threads)
+	assert.GreaterOrEqual(t, result.CPU.Percent, 0.0)
+	require.NotNil(t, result.Memory)
+	assert.Positive(t, result.Memory.Total)
+	assert.LessOrEqual(t, result.Memory.Used, result.Memory.Total)
+	assert.Nil(t, result.Processes, "processes are only reported on request")
+
+	result = runSysinfo(t, tool, `{"sections":["memory"]}`)
+	assert.NotNil(t, result.Memory)
+	assert.Nil(t, result.Host)
+	assert.Nil(t, result.CPU)
+	assert.Empty(t, result.Disks)
+}
+
+func TestSysinfoDiskPaths(t *testing.T) {
+	tool := tools.NewSysinfoTool(config.SysinfoConfig{DiskPaths: []string{os.TempDir(), "/nonexistent-mount"}})
+
+	result := runSysinfo(t, tool, `{"sections":["disk"]}`)
+	require.Len(t, result.Disks, 1)
+	assert.Equal(t, os.TempDir(), result.Disks[0].Path)
+	assert.Positive(t, result.Disks[0].Total)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "/nonexistent-mount")
+}
+
+func TestSysinfoProcesses(t *testing.T) {
+	tool := tools.NewSysinfoTool(config.SysinfoConfig{MaxProcesses: 5})
+	result := runSysinfo(t, tool, `{"sections":["processes"],"sort_by":"memory","limit":50}`)
+	assert.LessOrEqual(t, len(result.Processes), 5, "limit is capped by max_processes")
+	assert.GreaterOrEqual(t, result.Matched, len(result.Processes))
+	for i := 1; i < len(result.Processes); i++ {
+		assert.GreaterOrEqual(t, result.Processes[i-1].MemoryRSS, result.Processes[i].MemoryRSS)
+	}
+	for _, p := range result.Processes {
+		assert.Empty(t, p.Cmdline, "command lines are hidden by default")
+	}
+
+	// 按名称过滤出当前测试进程
+	found := false
+	for _, p := range runSysinfo(t, tools.NewSysinfoTool(config.SysinfoConfig{ExposeCmdline: true}), `{"sections":["processes"],"name":"TEST.TEST","limit":200}`).Processes {
+		if p.PID == int32(os.Getpid()) {
+			found = true
+			assert.NotEmpty(t, p.Cmdline)
+			assert.Positive(t, p.MemoryRSS)
+		}
+	}
+	assert.True(t, found)
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"sections":["gpu"]}`))
+	assert.ErrorContains(t, err, "unsupported section")
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolTags(t *testing.T) {
+	cfg := newTestToolConfig()
+	cfg.ToolTags = map[string][]string{"calculator": {"pure"}, "http_fetch": {"network", "audited"}}
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	tm.RegisterAllTools()
+
+	byName := make(map[string]tools.ToolInfo)
+	for _, info := range tm.GetTools() {
+		byName[info.Name] = info
+	}
+	assert.Equal(t, []string{"pure"}, byName["calculator"].Tags)
+	assert.Equal(t, []string{"audited", "network"}, byName["http_fetch"].Tags)
+	assert.Equal(t, []string{"llm", "network", "slow"}, byName["llm"].Tags)
+
+	// 需要包含全部 tags，且不包含任何 excludeTags
+	filter := tools.ToolFilter{Tags: []string{"network"}, ExcludeTags: []string{"llm"}}
+	names := toolNames(filter.Filter(tm.GetTools()))
+	assert.Contains(t, names, "http_fetch")
+	assert.NotContains(t, names, "llm")
+	assert.NotContains(t, names, "calculator")
+
+	// 租户按标签禁用工具
+	tenant := &tools.Tenant{Name: "offline", Config: config.TenantConfig{DenyTags: []string{"network"}}}
+	names = toolNames(tm.TenantTools(tenant))
+	assert.Contains(t, names, "calculator")
+	assert.NotContains(t, names, "http_fetch")
+	_, err := tm.CallTool(tools.WithTenant(context.Background(), tenant), "http_fetch", json.RawMessage(`{"url":"http://127.0.0.1"}`))
+	assert.ErrorContains(t, err, "tool not found: http_fetch")
+}
+
+func TestToolsListFilter(t *testing.T) {
+	url := newTestServer(t, nil)
+	baseURL := strings.TrimSuffix(url, "/mcp")
+
+	reply := decodeReply(t, postMCP(t, baseURL, `{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{"filter":{"tags":["llm"],"excludeTags":["slow"]}}}`, false))
+	require.Nil(t, reply.Error)
+	var result struct {
+		Tools []struct {
+			Name string `json:"name"`
+			Meta struct {
+				Tags []string `json:"tags"`
+			} `json:"_meta"`
+		} `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(reply.Result, &result))
+	require.NotEmpty(t, result.Tools)
+	for _, tool := range result.Tools {
+		assert.Contains(t, tool.Meta.Tags, "llm", tool.Name)
+		assert.NotContains(t, tool.Meta.Tags, "slow", tool.Name)
+	}
+
+	reply = decodeReply(t, postMCP(t, baseURL, `{"jsonrpc":"2.0","id":2,"method":"tools/list","params":{"filter":{"tags":"llm"}}}`, false))
+	require.NotNil(t, reply.Error)
+	assert.Equal(t, -32602, reply.Error.Code)
+	assert.Contains(t, reply.Error.Message, "invalid filter")
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantToolCatalog(t *testing.T) {
+	tm := tools.NewToolManager(newTestLogger(t), newTestToolConfig())
+	tm.RegisterAllTools()
+	tenant := &tools.Tenant{Name: "team-a", Config: config.TenantConfig{
+		Categories:    []string{"math", "utility"},
+		DisabledTools: []string{"crypto"},
+		RateLimit:     2,
+	}}
+
+	names := toolNames(tm.TenantTools(tenant))
+	assert.Contains(t, names, "calculator")
+	assert.Contains(t, names, "stream_text_processor")
+	assert.NotContains(t, names, "crypto")
+	assert.NotContains(t, names, "llm")
+	assert.Len(t, tm.TenantTools(nil), len(tm.GetTools()))
+
+	var records []tools.CallRecord
+	tm.AddCallObserver(func(_ context.Context, record tools.CallRecord) {
+		records = append(records, record)
+	})
+
+	// 目录外的工具按不存在处理
+	ctx := tools.WithTenant(context.Background(), tenant)
+	_, err := tm.CallTool(ctx, "crypto", json.RawMessage(`{"op":"random"}`))
+	assert.ErrorContains(t, err, "tool not found: crypto")
+
+	// 每分钟 2 次，允许同等数量的突发调用
+	args, _ := json.Marshal(tools.CalculatorArgs{Operation: "add", A: 1, B: 2})
+	for i := 0; i < 2; i++ {
+		_, err = tm.CallTool(ctx, "calculator", args)
+		require.NoError(t, err)
+	}
+	_, err = tm.CallTool(ctx, "calculator", args)
+	assert.True(t, errors.Is(err, tools.ErrRateLimited))
+
+	// 其他租户与未关联租户的调用不受影响
+	other := tools.WithTenant(context.Background(), &tools.Tenant{Name: "team-b"})
+	_, err = tm.CallTool(other, "calculator", args)
+	assert.NoError(t, err)
+	_, err = tm.CallTool(context.Background(), "crypto", json.RawMessage(`{"op":"random"}`))
+	assert.NoError(t, err)
+
+	require.Len(t, records, 6)
+	assert.Equal(t, "team-a", records[0].Tenant)
+	assert.Equal(t, "team-b", records[4].Tenant)
+	assert.Empty(t, records[5].Tenant)
+
+	usage := mcp.NewTenantUsageTracker()
+	for _, record := range records {
+		usage.Observe(context.Background(), record)
+	}
+	teamA := usage.Usage("team-a")
+	assert.Equal(t, int64(4), teamA.Calls)
+	assert.Equal(t, int64(2), teamA.Errors)
+	assert.Equal(t, int64(1), teamA.RateLimited)
+	assert.Equal(t, int64(3), teamA.Tools["calculator"])
+	assert.Equal(t, int64(1), usage.Usage("team-b").Calls)
+	assert.Zero(t, usage.Usage("").Calls)
+}
+
+func TestTenantArchiveRoots(t *testing.T) {
+	shared, private := t.TempDir(), t.TempDir()
+	writeTestFile(t, filepath.Join(private, "docs", "a.txt"), "alpha")
+	tool := tools.NewArchiveTool(config.ArchiveConfig{Roots: map[string]string{"shared": shared}, Default: "shared"})
+
+	// 租户只能使用自己的根目录，不回退到全局默认根目录
+	ctx := tools.WithTenant(context.Background(), &tools.Tenant{Name: "team-a", Config: config.TenantConfig{
+		Roots: map[string]string{"private": private},
+	}})
+	raw, err := tool.Execute(ctx, json.RawMessage(`{"op":"create","archive":"docs.zip","sources":["docs"]}`))
+	require.NoError(t, err)
+	var result tools.ArchiveResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	assert.Equal(t, "private", result.Root)
+	assert.FileExists(t, filepath.Join(private, "docs.zip"))
+
+	_, err = tool.Execute(ctx, json.RawMessage(`{"op":"list","root":"shared","archive":"docs.zip"}`))
+	assert.ErrorContains(t, err, "unknown archive root: shared")
+
+	noRoots := tools.WithTenant(context.Background(), &tools.Tenant{Name: "team-b"})
+	_, err = tool.Execute(noRoots, json.RawMessage(`{"op":"list","archive":"docs.zip"}`))
+	assert.ErrorContains(t, err, "no archive root configured")
+}
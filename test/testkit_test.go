@@ -0,0 +1,115 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/schema"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockToolManager(t *testing.T) {
+	echo := testkit.NewMockTool("echo").Returns(map[string]string{"reply": "default"})
+	slow := testkit.NewMockTool("slow").Delays(time.Second)
+	crashy := testkit.NewMockTool("crashy").Panics("boom")
+	tm := testkit.NewToolManager(t, echo, slow, crashy)
+
+	t.Run("排队响应优先于默认响应", func(t *testing.T) {
+		echo.Then(
+			testkit.Response{Result: json.RawMessage(`"first"`)},
+			testkit.Response{Err: errors.New("second fails")},
+		)
+
+		result, err := tm.CallTool(context.Background(), "echo", json.RawMessage(`{"n":1}`))
+		require.NoError(t, err)
+		assert.Equal(t, `"first"`, result.Content[0].Text)
+
+		_, err = tm.CallTool(context.Background(), "echo", json.RawMessage(`{"n":2}`))
+		assert.EqualError(t, err, "second fails")
+
+		result, err = tm.CallTool(context.Background(), "echo", json.RawMessage(`{"n":3}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"reply":"default"}`, result.Content[0].Text)
+
+		calls := echo.Calls()
+		require.Len(t, calls, 3)
+		assert.JSONEq(t, `{"n":2}`, string(calls[1]))
+	})
+
+	t.Run("延迟遵循调用截止时间", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := tm.CallTool(ctx, "slow", json.RawMessage(`{}`))
+		assert.ErrorIs(t, err, tools.ErrToolTimeout)
+	})
+
+	t.Run("panic 转为错误结果", func(t *testing.T) {
+		result, err := tm.CallTool(context.Background(), "crashy", json.RawMessage(`{}`))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Equal(t, 1, crashy.CallCount())
+	})
+
+	t.Run("流式脚本", func(t *testing.T) {
+		echo.Reset()
+		echo.Then(testkit.Response{Chunks: []string{"a", "b", "c"}, Result: json.RawMessage(`"abc"`)})
+
+		var chunks []string
+		result, err := tm.CallToolStream(context.Background(), "echo", json.RawMessage(`{}`), func(content string, index int) {
+			chunks = append(chunks, content)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, chunks)
+		assert.Equal(t, `"abc"`, result.Content[0].Text)
+	})
+}
+
+func TestInMemoryServer(t *testing.T) {
+	greet := testkit.NewMockTool("greet").
+		WithSchema(schema.Object(map[string]*schema.Schema{"name": {Type: schema.TypeString}}, "name").Closed()).
+		Handle(func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+			var params struct{ Name string }
+			if err := json.Unmarshal(args, &params); err != nil {
+				return nil, err
+			}
+			return json.Marshal("hello " + params.Name)
+		}).
+		Streams(0, "hel", "lo")
+	srv := testkit.NewServer(t, nil, greet)
+
+	resp, err := srv.Initialize("testkit")
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	assert.NotEmpty(t, resp.Header.Get(mcp.SessionIDHeader))
+
+	list, err := srv.ListTools()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "greet", list[0].Name)
+
+	result, err := srv.CallTool("greet", map[string]string{"name": "weave"})
+	require.NoError(t, err)
+	assert.Equal(t, `"hello weave"`, result.Content[0].Text)
+
+	_, err = srv.CallTool("greet", map[string]int{"name": 1})
+	var rpcErr *testkit.RPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, mcp.ErrorCodeInvalidParams, rpcErr.Code)
+
+	events, err := srv.StreamTool("greet", map[string]string{"name": "stream"})
+	require.NoError(t, err)
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = event.Event
+	}
+	assert.Equal(t, []string{"tool/call", "content", "content", "done"}, names)
+	assert.JSONEq(t, `{"result":{"content":[{"type":"text","text":"\"hello stream\""}]}}`, string(events[3].Data))
+}
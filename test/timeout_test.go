@@ -0,0 +1,113 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolTimeoutHierarchy(t *testing.T) {
+	cfg := newTestToolConfig()
+	cfg.Categories["math"] = config.CategoryConfig{Enabled: true, MaxTools: 10, Timeout: 30}
+	cfg.ToolTimeouts = map[string]int{"sleep": 5}
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	tm.RegisterAllTools()
+	require.NoError(t, tm.RegisterTool(&sleepTool{delay: time.Second}))
+
+	// 未配置任何超时时不限制，服务器默认值作为最后的回退
+	assert.Zero(t, tm.ToolTimeout("crypto"))
+	tm.SetDefaultTimeout(10 * time.Second)
+	assert.Equal(t, 10*time.Second, tm.ToolTimeout("crypto"))
+
+	// 单个工具覆盖分类，分类覆盖全局默认
+	cfg.Global.DefaultTimeout = 60
+	tm.ReloadConfig(cfg)
+	assert.Equal(t, time.Minute, tm.ToolTimeout("crypto"))
+	assert.Equal(t, 30*time.Second, tm.ToolTimeout("calculator"))
+	assert.Equal(t, 5*time.Second, tm.ToolTimeout("sleep"))
+	assert.Zero(t, tm.ToolTimeout("missing"))
+
+	// 请求的截止时间更早时以其为准
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := tm.CallTool(ctx, "sleep", json.RawMessage(`{}`))
+	var timeoutErr *tools.TimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	assert.True(t, errors.Is(err, tools.ErrToolTimeout))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, "sleep", timeoutErr.Tool)
+	assert.LessOrEqual(t, timeoutErr.Timeout, 20*time.Millisecond)
+	assert.True(t, timeoutErr.Requested)
+
+	// 调用方取消不是超时
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	_, err = tm.CallToolStream(canceled, "sleep", json.RawMessage(`{}`), nil)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.False(t, errors.Is(err, tools.ErrToolTimeout))
+}
+
+func TestTimeoutHint(t *testing.T) {
+	slow := testkit.NewMockTool("slow").Returns("done").Delays(time.Minute)
+	srv := testkit.NewServer(t, func(cfg *config.Config) {
+		cfg.MaxRequestTimeout = 100 * time.Millisecond
+	}, slow, testkit.NewMockTool("fast").Returns("ok"))
+
+	call := func(name string, timeout interface{}) *testkit.RPCResponse {
+		resp, err := srv.Call(mcp.MethodToolsCall, map[string]interface{}{
+			"name":      name,
+			"arguments": map[string]string{},
+			"_meta":     map[string]interface{}{"timeout": timeout},
+		})
+		require.NoError(t, err)
+		return resp
+	}
+	timedOut := func(resp *testkit.RPCResponse) (timeout float64) {
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, mcp.ErrorCodeToolTimeout, resp.Error.Code)
+		var data struct {
+			Tool    string  `json:"tool"`
+			Timeout float64 `json:"timeout"`
+			Source  string  `json:"source"`
+		}
+		require.NoError(t, json.Unmarshal(resp.Error.Data, &data))
+		assert.Equal(t, "slow", data.Tool)
+		assert.Equal(t, "request", data.Source)
+		return data.Timeout
+	}
+
+	// 秒数与 Go 时长格式，超过服务器上限时按上限处理
+	start := time.Now()
+	assert.LessOrEqual(t, timedOut(call("slow", 0.02)), 0.02)
+	assert.LessOrEqual(t, timedOut(call("slow", "10s")), 0.1)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// 未超时的调用不受影响
+	resp := call("fast", "5s")
+	require.Nil(t, resp.Error)
+
+	for _, invalid := range []interface{}{"soon", -1, true} {
+		resp := call("fast", invalid)
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, mcp.ErrorCodeInvalidParams, resp.Error.Code)
+		assert.Contains(t, resp.Error.Message, "_meta.timeout")
+	}
+
+	// 流式调用的 error 事件同样包含错误码
+	srv.SetHeader(mcp.RequestTimeoutHeader, "10s")
+	events, err := srv.StreamTool("slow", map[string]string{})
+	require.NoError(t, err)
+	last := events[len(events)-1]
+	assert.Equal(t, mcp.StreamEventError, last.Event)
+	assert.Contains(t, string(last.Data), `"source":"request"`)
+}
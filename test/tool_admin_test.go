@@ -0,0 +1,81 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func toolNames(infos []tools.ToolInfo) []string {
+	names := []string{}
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+func TestToolEnableDisable(t *testing.T) {
+	tm := tools.NewToolManager(newTestLogger(t), newTestToolConfig())
+	tm.RegisterAllTools()
+	args, _ := json.Marshal(tools.CalculatorArgs{Operation: "add", A: 1, B: 2})
+
+	require.NoError(t, tm.DisableTool("calculator"))
+	assert.NotContains(t, toolNames(tm.GetTools()), "calculator")
+	_, err := tm.CallTool(context.Background(), "calculator", args)
+	assert.Error(t, err)
+
+	for _, info := range tm.GetAllTools() {
+		if info.Name == "calculator" {
+			assert.False(t, info.Enabled)
+		}
+	}
+
+	require.NoError(t, tm.EnableTool("calculator"))
+	_, err = tm.CallTool(context.Background(), "calculator", args)
+	assert.NoError(t, err)
+
+	assert.Error(t, tm.DisableTool("missing_tool"))
+}
+
+func TestToolReloadConfig(t *testing.T) {
+	cfg := &config.ToolManagerConfig{
+		Categories: map[string]config.CategoryConfig{
+			"math":    {Enabled: true, MaxTools: 10},
+			"utility": {Enabled: false, MaxTools: 10},
+		},
+	}
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	tm.RegisterAllTools()
+	assert.NotContains(t, toolNames(tm.GetTools()), "stream_text_processor")
+
+	// 重载后启用的分类注册工具，别名同时生效
+	tm.ReloadConfig(&config.ToolManagerConfig{
+		Categories: map[string]config.CategoryConfig{
+			"math":    {Enabled: false, MaxTools: 10},
+			"utility": {Enabled: true, MaxTools: 10},
+		},
+		Aliases: map[string]string{"text": "stream_text_processor"},
+	})
+
+	names := toolNames(tm.GetTools())
+	assert.Contains(t, names, "stream_text_processor")
+	assert.NotContains(t, names, "calculator")
+	assert.Equal(t, "stream_text_processor", tm.ResolveAlias("", "text"))
+}
+
+func TestLoggerSetLevel(t *testing.T) {
+	log := newTestLogger(t)
+
+	require.NoError(t, log.SetLevel("debug"))
+	assert.Equal(t, "debug", log.GetLevel())
+	assert.Error(t, log.SetLevel("loud"))
+	assert.Equal(t, "debug", log.GetLevel())
+
+	require.NoError(t, log.SetLevel("error"))
+}
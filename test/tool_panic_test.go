@@ -0,0 +1,73 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// panicTool 执行时 panic 的测试工具
+type panicTool struct{}
+
+func (pt *panicTool) Name() string                 { return "panicky" }
+func (pt *panicTool) Description() string          { return "panics on every call" }
+func (pt *panicTool) Category() tools.ToolCategory { return tools.CategoryUtility }
+
+func (pt *panicTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var m map[string]int
+	m["boom"]++ // nil map 写入触发 panic
+	return nil, nil
+}
+
+func (pt *panicTool) ExecuteStream(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	callback("before panic", 0)
+	panic("stream boom")
+}
+
+func TestToolPanicRecovery(t *testing.T) {
+	tm := tools.NewToolManager(newTestLogger(t), newTestToolConfig())
+	require.NoError(t, tm.RegisterTool(&panicTool{}))
+
+	var mu sync.Mutex
+	var records []tools.CallRecord
+	tm.AddCallObserver(func(ctx context.Context, record tools.CallRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		records = append(records, record)
+	})
+
+	result, err := tm.CallTool(context.Background(), "panicky", json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "internal error")
+
+	var chunks []string
+	result, err = tm.CallToolStream(context.Background(), "panicky", json.RawMessage(`{}`), func(content string, index int) {
+		chunks = append(chunks, content)
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, []string{"before panic"}, chunks)
+
+	mu.Lock()
+	require.Len(t, records, 2)
+	for _, record := range records {
+		assert.Equal(t, tools.CallStatusError, record.Status)
+		assert.Contains(t, record.Error, "tool panicked")
+	}
+	mu.Unlock()
+
+	// panic 后执行槽位已释放，其他工具正常调用
+	args, _ := json.Marshal(tools.CalculatorArgs{Operation: "add", A: 1, B: 2})
+	tm.RegisterAllTools()
+	result, err = tm.CallTool(context.Background(), "calculator", args)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, 0, tm.PoolStats()["in_use"])
+}
@@ -0,0 +1,120 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/internal/llm"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedSampler 返回固定内容的 tools.Sampler
+type fixedSampler struct {
+	requests []llm.Request
+}
+
+func (s *fixedSampler) CreateMessage(ctx context.Context, req llm.Request) (*tools.LLMResult, error) {
+	s.requests = append(s.requests, req)
+	return &tools.LLMResult{Content: "summary"}, nil
+}
+
+func TestToolContext(t *testing.T) {
+	var seen tools.ToolContext
+	var sampled string
+	probe := testkit.NewMockTool("probe").Handle(func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+		seen = tools.ToolContextFrom(ctx)
+		seen.Progress.Report(1, 2, "halfway") // 调用方不接收进度时忽略
+		if seen.Sampler != nil {
+			result, err := tools.Sample(ctx, llm.Request{Messages: []llm.Message{{Role: "user", Content: "hi"}}})
+			if err != nil {
+				return nil, err
+			}
+			sampled = result.Content
+		}
+		return json.RawMessage(`{}`), nil
+	})
+	tm := testkit.NewToolManager(t, probe)
+
+	ctx := tools.WithClient(context.Background(), "cli")
+	ctx = tools.WithRequestID(ctx, "req-1")
+	ctx = tools.WithSessionID(ctx, "session-1")
+	ctx = tools.WithTenant(ctx, &tools.Tenant{Name: "team-a"})
+	_, err := tm.CallTool(ctx, "probe", json.RawMessage(`{}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "cli", seen.Client)
+	assert.Equal(t, "req-1", seen.RequestID)
+	assert.Equal(t, "session-1", seen.SessionID)
+	require.NotNil(t, seen.Tenant)
+	assert.Equal(t, "team-a", seen.Tenant.Name)
+	require.NotNil(t, seen.Logger)
+	assert.Nil(t, seen.Sampler, "no llm providers are configured")
+
+	// 自定义补全接口优先
+	sampler := &fixedSampler{}
+	_, err = tm.CallTool(tools.WithSampler(ctx, sampler), "probe", json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, "summary", sampled)
+	require.Len(t, sampler.requests, 1)
+
+	_, err = tools.Sample(context.Background(), llm.Request{})
+	assert.EqualError(t, err, "no sampling provider available")
+
+	// 进度上报
+	var reports []float64
+	progressCtx := tools.WithProgress(context.Background(), tools.ProgressFunc(func(progress, total float64, message string) {
+		reports = append(reports, progress, total)
+	}))
+	tools.ProgressFromContext(progressCtx).Report(3, 4, "")
+	assert.Equal(t, []float64{3, 4}, reports)
+}
+
+func TestServerStreamingProgress(t *testing.T) {
+	var requestID string
+	worker := testkit.NewMockTool("worker").Handle(func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+		requestID = tools.RequestIDFromContext(ctx)
+		progress := tools.ProgressFromContext(ctx)
+		progress.Report(1, 2, "first")
+		progress.Report(2, 2, "")
+		return json.RawMessage(`{"ok":true}`), nil
+	})
+	_, url := newHandlerServer(t, nil, worker)
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"worker","arguments":{},"_meta":{"progressToken":"tok-1"}}}`
+	req, err := http.NewRequest(http.MethodPost, url+"/mcp", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("X-Request-ID", "req-42")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, "tool/call", readEvent(t, reader).Event)
+
+	var progress []map[string]interface{}
+	for i := 0; i < 2; i++ {
+		event := readEvent(t, reader)
+		require.Equal(t, "progress", event.Event)
+		var data map[string]interface{}
+		require.NoError(t, json.Unmarshal(event.Data, &data))
+		progress = append(progress, data)
+	}
+	assert.Equal(t, map[string]interface{}{
+		"method": "notifications/progress", "progressToken": "tok-1", "progress": float64(1), "total": float64(2), "message": "first",
+	}, progress[0])
+	assert.Equal(t, float64(2), progress[1]["progress"])
+	assert.NotContains(t, progress[1], "message")
+
+	assert.Equal(t, "done", readEvent(t, reader).Event)
+	assert.Equal(t, "req-42", requestID)
+}
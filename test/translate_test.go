@@ -0,0 +1,197 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeTranslationServer 模拟各翻译服务的接口，译文为原文转大写
+func newFakeTranslationServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/translate", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "DeepL-Auth-Key deepl-key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var req struct {
+			Text       []string `json:"text"`
+			TargetLang string   `json:"target_lang"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Contains(t, []string{"DE", "EN-US"}, req.TargetLang) // detect 通过翻译为英文获取源语言
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"translations": []map[string]string{{"detected_source_language": "EN", "text": strings.ToUpper(req.Text[0])}},
+		})
+	})
+	mux.HandleFunc("/language/translate/v2", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "google-key", r.URL.Query().Get("key"))
+		var req struct {
+			Q []string `json:"q"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"translations": []map[string]string{{"translatedText": strings.ToUpper(req.Q[0]), "detectedSourceLanguage": "en"}}},
+		})
+	})
+	mux.HandleFunc("/language/translate/v2/detect", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"detections":[[{"language":"fr","confidence":0.93}]]}}`))
+	})
+	mux.HandleFunc("/libre/translate", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Q      string `json:"q"`
+			Source string `json:"source"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "auto", req.Source)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"translatedText":   strings.ToUpper(req.Q),
+			"detectedLanguage": map[string]interface{}{"language": "en", "confidence": 90},
+		})
+	})
+	mux.HandleFunc("/libre/detect", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"language":"de","confidence":80}]`))
+	})
+	mux.HandleFunc("/llm/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "local-model", req.Model)
+		reply := strings.ToUpper(req.Messages[1].Content)
+		if strings.Contains(req.Messages[0].Content, "Identify the language") {
+			reply = "es."
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestTranslateTool(serverURL string, chunkBytes int) *tools.TranslateTool {
+	return tools.NewTranslateTool(config.TranslateConfig{
+		Default:    "deepl",
+		ChunkBytes: chunkBytes,
+		Providers: map[string]config.TranslateProviderConfig{
+			"deepl":  {Type: tools.TranslateProviderDeepL, URL: serverURL, APIKey: "deepl-key"},
+			"google": {Type: tools.TranslateProviderGoogle, URL: serverURL, APIKey: "google-key"},
+			"libre":  {Type: tools.TranslateProviderLibreTranslate, URL: serverURL + "/libre"},
+			"llm":    {Type: tools.TranslateProviderLLM, URL: serverURL + "/llm", Model: "local-model"},
+			"broken": {Type: tools.TranslateProviderDeepL, URL: serverURL, APIKey: "wrong"},
+		},
+	})
+}
+
+func translateCall(t *testing.T, tool *tools.TranslateTool, args map[string]interface{}) (tools.TranslateResult, error) {
+	t.Helper()
+	data, err := json.Marshal(args)
+	require.NoError(t, err)
+
+	var result tools.TranslateResult
+	raw, err := tool.Execute(context.Background(), data)
+	if err != nil {
+		return result, err
+	}
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result, nil
+}
+
+func TestTranslateProviders(t *testing.T) {
+	server := newFakeTranslationServer(t)
+	tool := newTestTranslateTool(server.URL, 0)
+
+	for _, provider := range []string{"", "google", "libre", "llm"} {
+		t.Run("translate "+provider, func(t *testing.T) {
+			result, err := translateCall(t, tool, map[string]interface{}{"text": "  hello\n", "target": "de", "provider": provider})
+			require.NoError(t, err)
+			assert.Equal(t, "  HELLO\n", result.Text)
+			assert.Equal(t, 1, result.Chunks)
+			if provider != "llm" {
+				assert.Equal(t, "en", result.Source)
+			}
+		})
+	}
+
+	tests := []struct {
+		provider   string
+		language   string
+		confidence *float64
+	}{
+		{"deepl", "en", nil},
+		{"google", "fr", floatPtr(0.93)},
+		{"libre", "de", floatPtr(0.8)},
+		{"llm", "es", nil},
+	}
+	for _, tt := range tests {
+		t.Run("detect "+tt.provider, func(t *testing.T) {
+			result, err := translateCall(t, tool, map[string]interface{}{"op": "detect", "text": "bonjour", "provider": tt.provider})
+			require.NoError(t, err)
+			assert.Equal(t, tt.language, result.Language)
+			assert.Equal(t, tt.confidence, result.Confidence)
+		})
+	}
+}
+
+func TestTranslateErrors(t *testing.T) {
+	server := newFakeTranslationServer(t)
+	tool := newTestTranslateTool(server.URL, 0)
+
+	_, err := translateCall(t, tool, map[string]interface{}{"text": "hello", "target": "de", "provider": "broken"})
+	assert.ErrorContains(t, err, "status 403")
+	_, err = translateCall(t, tool, map[string]interface{}{"text": "hello", "target": "de", "provider": "missing"})
+	assert.Error(t, err)
+	_, err = translateCall(t, tool, map[string]interface{}{"text": "hello"})
+	assert.ErrorContains(t, err, "target is required")
+
+	unconfigured := tools.NewTranslateTool(config.TranslateConfig{})
+	_, err = translateCall(t, unconfigured, map[string]interface{}{"text": "hello", "target": "de"})
+	assert.ErrorContains(t, err, "no translation provider")
+}
+
+func TestTranslateStream(t *testing.T) {
+	server := newFakeTranslationServer(t)
+	tool := newTestTranslateTool(server.URL, 16)
+
+	text := "first paragraph\n\nsecond paragraph\nthird line is rather long\n"
+	var chunks []string
+	raw, err := tool.ExecuteStream(context.Background(), json.RawMessage(`{"text":`+mustJSON(t, text)+`,"target":"de"}`), func(content string, index int) {
+		assert.Equal(t, len(chunks), index)
+		chunks = append(chunks, content)
+	})
+	require.NoError(t, err)
+
+	var result tools.TranslateResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	assert.Equal(t, strings.ToUpper(text), result.Text)
+	assert.Equal(t, len(chunks), result.Chunks)
+	assert.Greater(t, len(chunks), 2)
+	assert.Equal(t, result.Text, strings.Join(chunks, ""))
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/testkit"
+)
+
+func TestH2CStreamWithConcurrentCalls(t *testing.T) {
+	if !platform.SupportsUnixSockets() {
+		t.Skip("unix sockets are not supported")
+	}
+	socket := filepath.Join(t.TempDir(), "mcp.sock")
+	chunks := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	ticker := testkit.NewMockTool("ticker").Streams(100*time.Millisecond, chunks...).Returns(strings.Join(chunks, ""))
+	srv := testkit.NewServer(t, func(cfg *config.Config) {
+		cfg.ServerAddress = platform.UnixSocketPrefix + socket
+		cfg.H2CEnabled = true
+	}, ticker, testkit.NewMockTool("quick").Returns("ok"))
+
+	// 通过真实监听启动，使 configureHTTP2 的协议设置生效
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		srv.Start(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+
+	// 客户端只使用 prior-knowledge 的 h2c，所有请求复用同一条连接
+	var dials atomic.Int32
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{
+		Protocols: protocols,
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			dials.Add(1)
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}}
+	t.Cleanup(client.CloseIdleConnections)
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("unix", socket)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	post := func(body string, stream bool) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, "http://weave/mcp", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if stream {
+			req.Header.Set("Accept", "text/event-stream")
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := post(toolCall(1, "ticker", `{}`), true)
+	assert.Equal(t, 2, resp.ProtoMajor)
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream"))
+	assert.Empty(t, resp.Header.Get("Connection"), "HTTP/2 responses carry no connection-specific headers")
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, "tool/call", readEvent(t, reader).Event)
+
+	// 流仍在推送时，同一连接上的短调用不被阻塞
+	started := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			resp := post(toolCall(100+id, "quick", `{}`), false)
+			assert.Equal(t, 2, resp.ProtoMajor)
+			reply := decodeReply(t, resp)
+			assert.Nil(t, reply.Error)
+			assert.Contains(t, string(reply.Result), "ok")
+		}(i)
+	}
+	wg.Wait()
+	assert.Less(t, time.Since(started), time.Duration(len(chunks))*100*time.Millisecond/2)
+
+	// 短调用完成时流尚未结束，剩余片段按顺序到达
+	var received []string
+	for {
+		event := readEvent(t, reader)
+		if event.Event != "content" {
+			assert.Equal(t, "done", event.Event)
+			break
+		}
+		var content struct {
+			Index   int    `json:"index"`
+			Content string `json:"content"`
+		}
+		require.NoError(t, json.Unmarshal(event.Data, &content))
+		assert.Equal(t, len(received), content.Index)
+		received = append(received, content.Content)
+	}
+	assert.Equal(t, chunks, received)
+	assert.Equal(t, int32(1), dials.Load())
+}
@@ -0,0 +1,43 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"Weave-Toolkit/internal/mcp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageTracker(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracker := mcp.NewUsageTracker()
+
+	r := gin.New()
+	r.Use(tracker.Middleware())
+	r.GET("/items/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/items/1", "/items/2", "/missing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	data, err := json.Marshal(tracker.Stats())
+	require.NoError(t, err)
+
+	var stats struct {
+		Endpoints map[string]struct {
+			Requests int64 `json:"requests"`
+		} `json:"endpoints"`
+	}
+	require.NoError(t, json.Unmarshal(data, &stats))
+
+	// 按路由模板统计，未匹配的路径不计入
+	require.Len(t, stats.Endpoints, 1)
+	assert.Equal(t, int64(2), stats.Endpoints["GET /items/:id"].Requests)
+}
@@ -0,0 +1,157 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runValidate(t *testing.T, tool *tools.ValidateTool, args map[string]interface{}) tools.ValidateResult {
+	t.Helper()
+	raw, err := json.Marshal(args)
+	require.NoError(t, err)
+	out, err := tool.Execute(context.Background(), raw)
+	require.NoError(t, err)
+	var result tools.ValidateResult
+	require.NoError(t, json.Unmarshal(out, &result))
+	return result
+}
+
+const serverSchema = `{
+  "type": "object",
+  "required": ["server"],
+  "properties": {
+    "server": {
+      "type": "object",
+      "required": ["host", "port"],
+      "properties": {
+        "host": {"type": "string"},
+        "port": {"type": "integer", "minimum": 1, "maximum": 65535}
+      }
+    }
+  }
+}`
+
+func TestValidateYAMLAgainstSchema(t *testing.T) {
+	tool := tools.NewValidateTool(config.ValidateConfig{})
+
+	result := runValidate(t, tool, map[string]interface{}{
+		"document": "server:\n  host: example.com\n  port: 8080\n",
+		"schema":   json.RawMessage(serverSchema),
+	})
+	assert.True(t, result.Valid)
+	assert.Equal(t, "yaml", result.Format)
+	assert.Equal(t, 1, result.Documents)
+
+	result = runValidate(t, tool, map[string]interface{}{
+		"document": "server:\n  host: example.com\n  port: \"8080\"\n",
+		"schema":   json.RawMessage(serverSchema),
+	})
+	assert.False(t, result.Valid)
+	require.Len(t, result.Issues, 1)
+	issue := result.Issues[0]
+	assert.Equal(t, tools.ValidateIssueSchema, issue.Type)
+	assert.Equal(t, "/server/port", issue.Path)
+	assert.Equal(t, 3, issue.Line)
+	assert.Equal(t, 9, issue.Column)
+	assert.Equal(t, "/properties/server/properties/port/type", issue.Keyword)
+
+	// 多文档逐个校验
+	result = runValidate(t, tool, map[string]interface{}{
+		"document": "server: {host: a, port: 1}\n---\nserver: {host: b}\n",
+		"schema":   serverSchema,
+	})
+	assert.Equal(t, 2, result.Documents)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, 2, result.Issues[0].Document)
+	assert.Equal(t, "/server", result.Issues[0].Path)
+}
+
+func TestValidateJSONLint(t *testing.T) {
+	tool := tools.NewValidateTool(config.ValidateConfig{})
+
+	result := runValidate(t, tool, map[string]interface{}{"document": "{\n  \"a\": 1,\n  \"a\": 2\n}"})
+	assert.False(t, result.Valid)
+	assert.Equal(t, "json", result.Format)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, tools.ValidateIssueDuplicateKey, result.Issues[0].Type)
+	assert.Equal(t, "/a", result.Issues[0].Path)
+	assert.Equal(t, 3, result.Issues[0].Line)
+
+	result = runValidate(t, tool, map[string]interface{}{"document": "{\n  \"a\": 1,\n  \"b\": \n}"})
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, tools.ValidateIssueSyntax, result.Issues[0].Type)
+	assert.Equal(t, 4, result.Issues[0].Line)
+	assert.Zero(t, result.Documents)
+
+	result = runValidate(t, tool, map[string]interface{}{"document": `{"a": 1} {"b": 2}`})
+	require.Len(t, result.Issues, 1)
+	assert.Contains(t, result.Issues[0].Message, "after top-level value")
+	assert.Equal(t, 10, result.Issues[0].Column)
+}
+
+func TestValidateYAMLLint(t *testing.T) {
+	tool := tools.NewValidateTool(config.ValidateConfig{})
+
+	result := runValidate(t, tool, map[string]interface{}{"document": "a: 1\nb:\n  c: 2\n  c: 3\n"})
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, tools.ValidateIssueDuplicateKey, result.Issues[0].Type)
+	assert.Equal(t, "/b/c", result.Issues[0].Path)
+	assert.Equal(t, 4, result.Issues[0].Line)
+
+	result = runValidate(t, tool, map[string]interface{}{"document": "a: [1, 2\nb: 3\n"})
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, tools.ValidateIssueSyntax, result.Issues[0].Type)
+	assert.Positive(t, result.Issues[0].Line)
+
+	// 合并键不覆盖显式定义的键
+	result = runValidate(t, tool, map[string]interface{}{
+		"document": "base: &base {host: a, port: 1}\nserver:\n  <<: *base\n  port: 2\n",
+		"schema":   `{"properties": {"server": {"properties": {"port": {"const": 2}, "host": {"const": "a"}}}}}`,
+	})
+	assert.True(t, result.Valid, "%+v", result.Issues)
+
+	// 别名展开受节点数限制
+	bomb := "a: &a [x, x, x, x, x, x, x, x, x, x]\n"
+	for i := 'b'; i <= 'i'; i++ {
+		prev := string(i - 1)
+		bomb += string(i) + ": &" + string(i) + " [*" + prev + ", *" + prev + ", *" + prev + ", *" + prev + ", *" + prev + ", *" + prev + ", *" + prev + ", *" + prev + ", *" + prev + ", *" + prev + "]\n"
+	}
+	result = runValidate(t, tool, map[string]interface{}{"document": bomb})
+	require.Len(t, result.Issues, 1)
+	assert.Contains(t, result.Issues[0].Message, "nodes")
+}
+
+func TestValidateSchemaSources(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "port.json"), []byte(`{"type": "integer", "maximum": 65535}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "server.yaml"), []byte("type: object\nproperties:\n  port:\n    $ref: port.json\n"), 0644))
+	tool := tools.NewValidateTool(config.ValidateConfig{Schemas: map[string]string{"server": filepath.Join(dir, "server.yaml")}})
+
+	result := runValidate(t, tool, map[string]interface{}{"document": `{"port": 70000}`, "schema_name": "server"})
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, "/port", result.Issues[0].Path)
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"document": "{}", "schema_name": "missing"}`))
+	assert.ErrorContains(t, err, "unknown schema: missing, available: server")
+
+	// 内联 Schema 不能加载外部文件
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"document": "{}", "schema": {"$ref": "file:///etc/passwd"}}`))
+	assert.ErrorContains(t, err, "invalid schema")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"document": "{}", "schema": {"type": 5}}`))
+	assert.ErrorContains(t, err, "invalid schema")
+
+	result = runValidate(t, tool, map[string]interface{}{"document": `{"email": "nope"}`, "schema": `{"properties": {"email": {"format": "email"}}}`})
+	assert.True(t, result.Valid)
+	result = runValidate(t, tool, map[string]interface{}{"document": `{"email": "nope"}`, "schema": `{"properties": {"email": {"format": "email"}}}`, "assert_format": true})
+	assert.False(t, result.Valid)
+}
@@ -0,0 +1,184 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/tools"
+	"Weave-Toolkit/internal/vector"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEmbedding 按关键词出现次数生成三维向量：cat、dog、car
+func fakeEmbedding(text string) []float64 {
+	text = strings.ToLower(text)
+	return []float64{
+		float64(strings.Count(text, "cat")) + 0.01,
+		float64(strings.Count(text, "dog")) + 0.01,
+		float64(strings.Count(text, "car")) + 0.01,
+	}
+}
+
+// newFakeEmbeddingsServer 模拟 OpenAI /v1/embeddings 与 Ollama /api/embed 接口
+func newFakeEmbeddingsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	decode := func(r *http.Request) (string, []string) {
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		return req.Model, req.Input
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer openai-key", r.Header.Get("Authorization"))
+		model, inputs := decode(r)
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		}
+		// 倒序返回，验证客户端按 index 排序
+		data := make([]item, 0, len(inputs))
+		for i := len(inputs) - 1; i >= 0; i-- {
+			data = append(data, item{Index: i, Embedding: fakeEmbedding(inputs[i])})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"model": model, "data": data, "usage": map[string]int{"prompt_tokens": len(inputs)}})
+	})
+	mux.HandleFunc("/api/embed", func(w http.ResponseWriter, r *http.Request) {
+		model, inputs := decode(r)
+		vectors := make([][]float64, len(inputs))
+		for i, input := range inputs {
+			vectors[i] = fakeEmbedding(input)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"model": model, "embeddings": vectors, "prompt_eval_count": len(inputs)})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestEmbeddingsTool(serverURL string) *tools.EmbeddingsTool {
+	return tools.NewEmbeddingsTool(config.EmbeddingsConfig{
+		Default: "openai",
+		Providers: map[string]config.LLMProviderConfig{
+			"openai":    {Type: "openai", URL: serverURL + "/v1", APIKey: "openai-key", Model: "embed-test"},
+			"ollama":    {Type: "ollama", URL: serverURL, Model: "nomic-test"},
+			"anthropic": {Type: "anthropic", APIKey: "anthropic-key", Model: "claude-test"},
+		},
+		MaxInputs: 4,
+	})
+}
+
+func TestEmbeddingsProviders(t *testing.T) {
+	server := newFakeEmbeddingsServer(t)
+	tool := newTestEmbeddingsTool(server.URL)
+
+	for _, provider := range []string{"openai", "ollama"} {
+		t.Run(provider, func(t *testing.T) {
+			raw, err := tool.Execute(context.Background(), json.RawMessage(`{"provider":"`+provider+`","input":"cat","inputs":["dog dog","car"]}`))
+			require.NoError(t, err)
+			var result tools.EmbeddingsResult
+			require.NoError(t, json.Unmarshal(raw, &result))
+			assert.Equal(t, provider, result.Provider)
+			assert.Equal(t, 3, result.Dimensions)
+			require.Len(t, result.Embeddings, 3)
+			assert.Equal(t, fakeEmbedding("cat"), result.Embeddings[0])
+			assert.Equal(t, fakeEmbedding("dog dog"), result.Embeddings[1])
+			assert.Equal(t, fakeEmbedding("car"), result.Embeddings[2])
+			assert.Equal(t, 3, result.Usage.InputTokens)
+		})
+	}
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"provider":"anthropic","input":"cat"}`))
+	assert.ErrorContains(t, err, "does not support embeddings")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"inputs":["a","b","c","d","e"]}`))
+	assert.ErrorContains(t, err, "too many inputs")
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{}`))
+	assert.ErrorContains(t, err, "input or inputs")
+}
+
+func executeVectorSearch(t *testing.T, tool *tools.VectorSearchTool, args string) tools.VectorSearchResult {
+	t.Helper()
+	raw, err := tool.Execute(context.Background(), json.RawMessage(args))
+	require.NoError(t, err)
+	var result tools.VectorSearchResult
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result
+}
+
+func TestVectorSearch(t *testing.T) {
+	server := newFakeEmbeddingsServer(t)
+	tool := tools.NewVectorSearchTool(config.VectorSearchConfig{}, newTestEmbeddingsTool(server.URL))
+
+	result := executeVectorSearch(t, tool, `{"op":"upsert","collection":"pets","documents":[
+		{"id":"1","text":"a cat sleeping","metadata":{"kind":"cat"}},
+		{"id":"2","text":"a dog barking","metadata":{"kind":"dog"}},
+		{"id":"3","text":"a fast car"},
+		{"id":"4","text":"cat and dog","vector":[1,1,0]}
+	]}`)
+	assert.Equal(t, 4, result.Upserted)
+
+	result = executeVectorSearch(t, tool, `{"op":"query","collection":"pets","text":"my cat","top_k":2}`)
+	require.Len(t, result.Matches, 2)
+	assert.Equal(t, "1", result.Matches[0].ID)
+	assert.Equal(t, "a cat sleeping", result.Matches[0].Text)
+	assert.InDelta(t, 1.0, result.Matches[0].Score, 1e-6)
+	assert.Equal(t, "4", result.Matches[1].ID)
+
+	result = executeVectorSearch(t, tool, `{"op":"query","collection":"pets","vector":[0,1,0],"filter":{"kind":"dog"}}`)
+	require.Len(t, result.Matches, 1)
+	assert.Equal(t, "2", result.Matches[0].ID)
+	assert.Equal(t, map[string]string{"kind": "dog"}, result.Matches[0].Metadata)
+
+	result = executeVectorSearch(t, tool, `{"op":"query","collection":"pets","vector":[0,0,1],"min_score":0.9}`)
+	require.Len(t, result.Matches, 1)
+	assert.Equal(t, "3", result.Matches[0].ID)
+
+	result = executeVectorSearch(t, tool, `{"op":"delete","collection":"pets","ids":["3","missing"]}`)
+	assert.Equal(t, 1, result.Deleted)
+
+	result = executeVectorSearch(t, tool, `{"op":"list"}`)
+	assert.Equal(t, []vector.CollectionInfo{{Name: "pets", Dimension: 3, Documents: 3}}, result.Collections)
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"op":"query","collection":"pets","vector":[1,0]}`))
+	assert.True(t, errors.Is(err, vector.ErrDimensionMismatch))
+
+	executeVectorSearch(t, tool, `{"op":"drop","collection":"pets"}`)
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"op":"query","collection":"pets","text":"cat"}`))
+	assert.True(t, errors.Is(err, vector.ErrCollectionNotFound))
+}
+
+func TestVectorSearchLimitsAndPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+	cfg := config.VectorSearchConfig{PersistPath: path, MaxCollections: 1, MaxDocuments: 2}
+	tool := tools.NewVectorSearchTool(cfg, nil)
+
+	executeVectorSearch(t, tool, `{"op":"upsert","collection":"a","documents":[{"id":"x","text":"first","vector":[1,0]},{"id":"y","vector":[0,1]}]}`)
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"op":"upsert","collection":"a","documents":[{"id":"z","vector":[1,1]}]}`))
+	assert.True(t, errors.Is(err, vector.ErrLimitExceeded))
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"op":"upsert","collection":"b","documents":[{"id":"z","vector":[1,1]}]}`))
+	assert.True(t, errors.Is(err, vector.ErrLimitExceeded))
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"op":"upsert","collection":"a","documents":[{"id":"x","text":"needs embedding"}]}`))
+	assert.ErrorContains(t, err, "embeddings are not available")
+
+	// 新实例从快照加载
+	reloaded := tools.NewVectorSearchTool(cfg, nil)
+	result := executeVectorSearch(t, reloaded, `{"op":"query","collection":"a","vector":[2,0],"top_k":1}`)
+	require.Len(t, result.Matches, 1)
+	assert.Equal(t, "x", result.Matches[0].ID)
+	assert.Equal(t, "first", result.Matches[0].Text)
+}
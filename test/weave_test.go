@@ -0,0 +1,118 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/pkg/weave"
+	"Weave-Toolkit/testkit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeaveBuilder(t *testing.T) {
+	override := testkit.NewMockTool("calculator").WithCategory(weave.CategoryMath).Returns("overridden")
+	lookup := testkit.NewMockTool("lookup").Returns(map[string]string{"status": "ok"})
+
+	var mu sync.Mutex
+	var records []weave.CallRecord
+	srv, err := weave.New().
+		WithConfig(func(cfg *weave.Config) { cfg.JobStoreDir = t.TempDir() }).
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))).
+		WithBuiltinTools().
+		WithTool(override, lookup).
+		WithObserver(func(ctx context.Context, record weave.CallRecord) {
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, record)
+		}).
+		Build()
+	require.NoError(t, err)
+
+	httpSrv := httptest.NewServer(srv.Handler())
+	defer httpSrv.Close()
+
+	names := map[string]bool{}
+	for _, info := range srv.ToolManager().GetTools() {
+		names[info.Name] = true
+	}
+	assert.True(t, names["lookup"])
+	assert.True(t, names["stream_text_processor"], "builtin tools are registered")
+
+	reply := decodeReply(t, postMCP(t, httpSrv.URL, toolCall(1, "calculator", `{}`), false))
+	require.Nil(t, reply.Error)
+	assert.Contains(t, string(reply.Result), "overridden", "custom tool replaces the builtin with the same name")
+	assert.Equal(t, 1, override.CallCount())
+
+	mu.Lock()
+	require.Len(t, records, 1)
+	assert.Equal(t, "calculator", records[0].Tool)
+	mu.Unlock()
+}
+
+func TestWeaveRun(t *testing.T) {
+	if !platform.SupportsUnixSockets() {
+		t.Skip("unix sockets are not supported on this platform")
+	}
+	socket := filepath.Join(t.TempDir(), "weave.sock")
+	echo := testkit.NewMockTool("echo").Returns("pong")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- weave.New().
+			WithConfig(func(cfg *weave.Config) {
+				cfg.JobStoreDir = t.TempDir()
+				cfg.ShutdownDrain = 100 * time.Millisecond
+			}).
+			WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))).
+			WithTool(echo).
+			WithTransport(weave.HTTP(platform.UnixSocketPrefix + socket)).
+			Run(ctx)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	body := toolCall(1, "echo", `{}`)
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		var err error
+		resp, err = client.Post("http://weave/mcp", "application/json", strings.NewReader(body))
+		return err == nil
+	}, 5*time.Second, 20*time.Millisecond)
+	defer resp.Body.Close()
+
+	var reply rpcReply
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply))
+	require.Nil(t, reply.Error)
+	assert.Contains(t, string(reply.Result), "pong")
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+}
+
+func TestWeaveFromEnvError(t *testing.T) {
+	t.Setenv("TOOL_CONFIG_PATH", filepath.Join(t.TempDir(), "missing.json"))
+	_, err := weave.FromEnv().Build()
+	assert.ErrorContains(t, err, "tool config file not found")
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/internal/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookVerify(t *testing.T) {
+	secret := "s3cret"
+	body := []byte(`{"action":"opened"}`)
+
+	t.Run("GitHub 签名正确", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("X-Hub-Signature-256", webhook.Sign(secret, body))
+		assert.NoError(t, webhook.Verify(webhook.ProviderGitHub, secret, "", header, body))
+	})
+
+	t.Run("GitHub 签名错误", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("X-Hub-Signature-256", webhook.Sign("other", body))
+		assert.Error(t, webhook.Verify(webhook.ProviderGitHub, secret, "", header, body))
+	})
+
+	t.Run("generic 自定义签名头", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("X-Custom-Sig", webhook.Sign(secret, body))
+		assert.NoError(t, webhook.Verify(webhook.ProviderGeneric, secret, "X-Custom-Sig", header, body))
+		assert.Error(t, webhook.Verify(webhook.ProviderGeneric, secret, "", header, body))
+	})
+
+	t.Run("Stripe 签名正确", func(t *testing.T) {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		header := http.Header{}
+		header.Set("Stripe-Signature", fmt.Sprintf("t=%s,v1=%s", ts, stripeSign(secret, ts, body)))
+		assert.NoError(t, webhook.Verify(webhook.ProviderStripe, secret, "", header, body))
+	})
+
+	t.Run("Stripe 时间戳过期", func(t *testing.T) {
+		ts := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+		header := http.Header{}
+		header.Set("Stripe-Signature", fmt.Sprintf("t=%s,v1=%s", ts, stripeSign(secret, ts, body)))
+		assert.Error(t, webhook.Verify(webhook.ProviderStripe, secret, "", header, body))
+	})
+
+	t.Run("未配置密钥", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("X-Signature", webhook.Sign("", body))
+		assert.Error(t, webhook.Verify(webhook.ProviderGeneric, "", "", header, body))
+	})
+}
+
+func TestWebhookRenderArguments(t *testing.T) {
+	data := map[string]interface{}{
+		"event": "push",
+		"payload": map[string]interface{}{
+			"amount": float64(42),
+			"commits": []interface{}{
+				map[string]interface{}{"message": "fix bug"},
+			},
+		},
+	}
+
+	args, err := webhook.RenderArguments(map[string]interface{}{
+		"operation": "add",
+		"a":         "$.payload.amount",
+		"text":      "{{.event}}: {{(index .payload.commits 0).message}}",
+		"missing":   "$.payload.nope",
+	}, data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "add", args["operation"])
+	assert.Equal(t, float64(42), args["a"])
+	assert.Equal(t, "push: fix bug", args["text"])
+	assert.Nil(t, args["missing"])
+	assert.Equal(t, "fix bug", webhook.Lookup(data, "payload.commits.0.message"))
+}
+
+func stripeSign(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
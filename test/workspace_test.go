@@ -0,0 +1,112 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/internal/platform"
+	"Weave-Toolkit/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// workspaceTool 在工作区中写入文件并记录目录的测试工具
+type workspaceTool struct {
+	dir string
+}
+
+func (wt *workspaceTool) Name() string                 { return "workspace_writer" }
+func (wt *workspaceTool) Description() string          { return "writes into its workspace" }
+func (wt *workspaceTool) Category() tools.ToolCategory { return tools.CategoryUtility }
+
+func (wt *workspaceTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	ws, ok := tools.WorkspaceFromContext(ctx)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	if err := ws.WriteFile("step1/out.txt", []byte("intermediate")); err != nil {
+		return nil, err
+	}
+	path, err := ws.Path("step1/out.txt")
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	wt.dir, _ = ws.Dir()
+	return json.Marshal(map[string]string{"read": string(data)})
+}
+
+func TestToolWorkspace(t *testing.T) {
+	root := t.TempDir()
+	cfg := newTestToolConfig()
+	cfg.Global.WorkspaceDir = root
+	cfg.Global.WorkspaceMaxBytes = 16
+
+	tm := tools.NewToolManager(newTestLogger(t), cfg)
+	tool := &workspaceTool{}
+	require.NoError(t, tm.RegisterTool(tool))
+
+	t.Run("removed after call", func(t *testing.T) {
+		result, err := tm.CallTool(context.Background(), "workspace_writer", json.RawMessage(`{}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"read":"intermediate"}`, result.Content[0].Text)
+
+		require.NotEmpty(t, tool.dir)
+		assert.True(t, platform.IsWithin(root, tool.dir))
+		assert.NoDirExists(t, tool.dir)
+	})
+
+	t.Run("caller owned workspace is kept", func(t *testing.T) {
+		ws := tm.NewWorkspace()
+		_, err := tm.CallTool(tools.WithWorkspace(context.Background(), ws), "workspace_writer", json.RawMessage(`{}`))
+		require.NoError(t, err)
+		assert.DirExists(t, tool.dir)
+
+		ws.RemoveAfter(0)
+		assert.NoDirExists(t, tool.dir)
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		ws := tm.NewWorkspace()
+		defer ws.Remove()
+
+		require.NoError(t, ws.WriteFile("a", []byte("0123456789")))
+		assert.ErrorIs(t, ws.WriteFile("b", []byte("0123456789")), tools.ErrWorkspaceFull)
+
+		w, err := ws.Create("c")
+		require.NoError(t, err)
+		_, err = io.Copy(w, strings.NewReader("0123456789"))
+		assert.ErrorIs(t, err, tools.ErrWorkspaceFull)
+		require.NoError(t, w.Close())
+	})
+
+	t.Run("paths confined", func(t *testing.T) {
+		ws := tm.NewWorkspace()
+		defer ws.Remove()
+
+		_, err := ws.Path("../escape.txt")
+		assert.ErrorIs(t, err, platform.ErrOutsideRoot)
+	})
+
+	t.Run("sweep stale workspaces", func(t *testing.T) {
+		stale := filepath.Join(root, "call-stale")
+		require.NoError(t, os.MkdirAll(stale, 0700))
+		old := time.Now().Add(-2 * time.Hour)
+		require.NoError(t, os.Chtimes(stale, old, old))
+
+		removed, err := tm.SweepWorkspaces(time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 1, removed)
+		assert.NoDirExists(t, stale)
+	})
+}
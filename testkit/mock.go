@@ -0,0 +1,238 @@
+// Package testkit 为嵌入工具框架的应用提供测试替身：可编排结果的 MockTool，
+// 以及无需网络监听、在进程内处理 MCP 请求的工具管理器与服务器
+package testkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"Weave-Toolkit/internal/schema"
+	"Weave-Toolkit/internal/tools"
+)
+
+// Response MockTool 单次调用的行为
+type Response struct {
+	Result   json.RawMessage // 调用结果，为空时返回 null
+	Err      error           // 调用返回的错误，优先于 Result
+	Delay    time.Duration   // 返回前的等待时长，期间调用被取消时返回上下文错误
+	Panic    interface{}     // 非空时在返回前以该值 panic
+	Chunks   []string        // 流式调用依次推送的输出片段
+	Interval time.Duration   // 推送相邻片段之间的间隔
+	// Handler 非空时代替以上字段生成结果，Chunks 与 Delay 仍然生效
+	Handler func(ctx context.Context, args json.RawMessage) (json.RawMessage, error)
+}
+
+// MockTool 行为可编排的测试工具，同时实现流式与 Schema 工具接口
+//
+// 调用依次消耗 Then 排入的响应，队列为空时使用 Returns、Fails 等设置的默认响应。
+type MockTool struct {
+	name         string
+	description  string
+	category     tools.ToolCategory
+	inputSchema  *schema.Schema
+	outputSchema *schema.Schema
+
+	mu       sync.Mutex
+	fallback Response
+	queue    []Response
+	calls    []json.RawMessage
+}
+
+// NewMockTool 创建默认返回 null 的实用工具分类测试工具，参数 Schema 为任意对象
+func NewMockTool(name string) *MockTool {
+	return &MockTool{
+		name:        name,
+		description: fmt.Sprintf("mock tool %s", name),
+		category:    tools.CategoryUtility,
+		inputSchema: schema.Object(nil),
+	}
+}
+
+// WithDescription 设置工具描述
+func (m *MockTool) WithDescription(description string) *MockTool {
+	m.description = description
+	return m
+}
+
+// WithCategory 设置工具分类
+func (m *MockTool) WithCategory(category tools.ToolCategory) *MockTool {
+	m.category = category
+	return m
+}
+
+// WithSchema 设置参数 Schema，调用前由工具管理器校验
+func (m *MockTool) WithSchema(inputSchema *schema.Schema) *MockTool {
+	m.inputSchema = inputSchema
+	return m
+}
+
+// WithOutputSchema 设置结果 Schema，结果以 structuredContent 返回
+func (m *MockTool) WithOutputSchema(outputSchema *schema.Schema) *MockTool {
+	m.outputSchema = outputSchema
+	return m
+}
+
+// Returns 设置默认结果，value 按 JSON 编码（json.RawMessage 原样返回）
+func (m *MockTool) Returns(value interface{}) *MockTool {
+	result, err := json.Marshal(value)
+	if err != nil {
+		panic(fmt.Sprintf("testkit: failed to marshal result of %s: %v", m.name, err))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallback.Result = result
+	m.fallback.Err = nil
+	return m
+}
+
+// Fails 设置默认调用返回的错误
+func (m *MockTool) Fails(err error) *MockTool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallback.Err = err
+	return m
+}
+
+// Delays 设置默认调用返回前的等待时长
+func (m *MockTool) Delays(delay time.Duration) *MockTool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallback.Delay = delay
+	return m
+}
+
+// Panics 设置默认调用以 value panic
+func (m *MockTool) Panics(value interface{}) *MockTool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallback.Panic = value
+	return m
+}
+
+// Streams 设置默认流式调用推送的输出片段，interval 为相邻片段的间隔
+func (m *MockTool) Streams(interval time.Duration, chunks ...string) *MockTool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallback.Chunks = chunks
+	m.fallback.Interval = interval
+	return m
+}
+
+// Handle 设置生成默认结果的函数
+func (m *MockTool) Handle(handler func(ctx context.Context, args json.RawMessage) (json.RawMessage, error)) *MockTool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallback.Handler = handler
+	return m
+}
+
+// Then 排入一次性响应，按顺序用于接下来的调用
+func (m *MockTool) Then(responses ...Response) *MockTool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queue = append(m.queue, responses...)
+	return m
+}
+
+// Calls 返回已收到的调用参数，按调用顺序排列
+func (m *MockTool) Calls() []json.RawMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]json.RawMessage(nil), m.calls...)
+}
+
+// CallCount 返回已收到的调用次数
+func (m *MockTool) CallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls)
+}
+
+// Reset 清空调用记录与排队的响应，保留默认响应
+func (m *MockTool) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+	m.queue = nil
+}
+
+func (m *MockTool) Name() string                 { return m.name }
+func (m *MockTool) Description() string          { return m.description }
+func (m *MockTool) Category() tools.ToolCategory { return m.category }
+func (m *MockTool) InputSchema() *schema.Schema  { return m.inputSchema }
+func (m *MockTool) OutputSchema() *schema.Schema { return m.outputSchema }
+
+// Execute 按下一个响应执行，不推送输出片段
+func (m *MockTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	return m.run(ctx, args, nil)
+}
+
+// ExecuteStream 按下一个响应依次推送输出片段后返回结果
+func (m *MockTool) ExecuteStream(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	return m.run(ctx, args, callback)
+}
+
+// next 记录调用并取出本次使用的响应
+func (m *MockTool) next(args json.RawMessage) Response {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, append(json.RawMessage(nil), args...))
+	if len(m.queue) > 0 {
+		response := m.queue[0]
+		m.queue = m.queue[1:]
+		return response
+	}
+	return m.fallback
+}
+
+// run 执行一次调用，callback 为空时跳过输出片段
+func (m *MockTool) run(ctx context.Context, args json.RawMessage, callback func(content string, index int)) (json.RawMessage, error) {
+	response := m.next(args)
+
+	if callback != nil {
+		for i, chunk := range response.Chunks {
+			if i > 0 {
+				if err := sleep(ctx, response.Interval); err != nil {
+					return nil, err
+				}
+			}
+			callback(chunk, i)
+		}
+	}
+
+	if err := sleep(ctx, response.Delay); err != nil {
+		return nil, err
+	}
+	if response.Panic != nil {
+		panic(response.Panic)
+	}
+	if response.Handler != nil {
+		return response.Handler(ctx, args)
+	}
+	if response.Err != nil {
+		return nil, response.Err
+	}
+	if response.Result == nil {
+		return json.RawMessage("null"), nil
+	}
+	return response.Result, nil
+}
+
+// sleep 等待指定时长，上下文先取消时返回其错误
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,269 @@
+package testkit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Weave-Toolkit/config"
+	"Weave-Toolkit/internal/logger"
+	"Weave-Toolkit/internal/mcp"
+	"Weave-Toolkit/internal/tools"
+)
+
+// ToolConfig 启用全部分类的工具配置，每个分类最多注册 100 个工具
+func ToolConfig() *config.ToolManagerConfig {
+	return mcp.DefaultToolConfig()
+}
+
+// Logger 创建只输出错误日志的日志器，日志文件写入测试临时目录
+func Logger(tb testing.TB) *logger.Logger {
+	tb.Helper()
+	log, err := logger.NewLogger(tb.TempDir(), "error")
+	if err != nil {
+		tb.Fatalf("testkit: failed to create logger: %v", err)
+	}
+	tb.Cleanup(func() { log.Close() })
+	return log
+}
+
+// NewToolManager 创建只注册指定工具的工具管理器，测试结束时关闭
+func NewToolManager(tb testing.TB, toolList ...tools.Tool) *tools.ToolManager {
+	tb.Helper()
+	tm := tools.NewToolManager(Logger(tb), ToolConfig())
+	for _, tool := range toolList {
+		if err := tm.RegisterTool(tool); err != nil {
+			tb.Fatalf("testkit: failed to register tool %s: %v", tool.Name(), err)
+		}
+	}
+	tb.Cleanup(func() { tm.Close() })
+	return tm
+}
+
+// Config 进程内服务器的默认配置，异步任务存储在测试临时目录
+func Config(tb testing.TB) *config.Config {
+	return &config.Config{
+		LogLevel:       "error",
+		MaxConnections: 1024,
+		ToolTimeout:    30 * time.Second,
+		JobStoreDir:    tb.TempDir(),
+		ToolConfig:     *ToolConfig(),
+	}
+}
+
+// Server 在进程内处理 MCP 请求的服务器，请求直接交给 HTTP 处理器而不经过网络
+type Server struct {
+	*mcp.Server
+	tb      testing.TB
+	handler http.Handler
+
+	mu      sync.Mutex
+	nextID  int
+	session string
+	headers http.Header
+}
+
+// NewServer 创建只提供指定工具的进程内服务器，configure 可调整默认配置
+func NewServer(tb testing.TB, configure func(cfg *config.Config), toolList ...tools.Tool) *Server {
+	tb.Helper()
+	cfg := Config(tb)
+	if configure != nil {
+		configure(cfg)
+	}
+	srv, err := mcp.NewServerWithTools(cfg, Logger(tb), toolList...)
+	if err != nil {
+		tb.Fatalf("testkit: failed to create server: %v", err)
+	}
+	return &Server{Server: srv, tb: tb, handler: srv.Handler(), headers: make(http.Header)}
+}
+
+// SetHeader 设置之后每个请求携带的请求头，如 API Key 或租户头
+func (s *Server) SetHeader(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.headers.Set(key, value)
+}
+
+// RPCError JSON-RPC 错误响应
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("json-rpc error %d: %s", e.Code, e.Message)
+}
+
+// RPCResponse JSON-RPC 响应
+type RPCResponse struct {
+	Status int             // HTTP 状态码
+	Header http.Header     // HTTP 响应头
+	Result json.RawMessage // 成功时的结果
+	Error  *RPCError       // 失败时的错误
+}
+
+// Event 流式响应中的一个 SSE 事件
+type Event struct {
+	ID    string
+	Event string
+	Data  json.RawMessage
+}
+
+// Initialize 发送 initialize 请求，之后的请求携带返回的会话 ID
+func (s *Server) Initialize(clientName string) (*RPCResponse, error) {
+	resp, err := s.Call(mcp.MethodInitialize, map[string]interface{}{
+		"protocolVersion": mcp.ProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": clientName, "version": "0.0.0"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.session = resp.Header.Get(mcp.SessionIDHeader)
+	s.mu.Unlock()
+	return resp, nil
+}
+
+// Call 发送 JSON-RPC 请求，params 为空时不携带参数
+func (s *Server) Call(method string, params interface{}) (*RPCResponse, error) {
+	rec, err := s.do(method, params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &RPCResponse{Status: rec.Code, Header: rec.Header()}
+	var body struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			return nil, fmt.Errorf("invalid response body (status %d): %v", rec.Code, err)
+		}
+	}
+	resp.Result, resp.Error = body.Result, body.Error
+	return resp, nil
+}
+
+// ListTools 获取服务器提供的工具列表
+func (s *Server) ListTools() ([]tools.ToolInfo, error) {
+	resp, err := s.Call(mcp.MethodToolsList, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	var result struct {
+		Tools []tools.ToolInfo `json:"tools"`
+	}
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, err
+	}
+	return result.Tools, nil
+}
+
+// CallTool 调用工具，JSON-RPC 错误以 *RPCError 返回
+func (s *Server) CallTool(name string, arguments interface{}) (*tools.ToolCallResult, error) {
+	resp, err := s.Call(mcp.MethodToolsCall, toolParams(name, arguments))
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	var result tools.ToolCallResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// StreamTool 以 SSE 流式调用工具，返回收到的全部事件
+func (s *Server) StreamTool(name string, arguments interface{}) ([]Event, error) {
+	rec, err := s.do(mcp.MethodToolsCall, toolParams(name, arguments), true)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/event-stream") {
+		return nil, fmt.Errorf("unexpected non-stream response (status %d): %s", rec.Code, rec.Body.String())
+	}
+	return ParseEvents(rec.Body.Bytes())
+}
+
+// ParseEvents 解析 SSE 响应体中的事件
+func ParseEvents(body []byte) ([]Event, error) {
+	var events []Event
+	var current Event
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if current.Event != "" || current.Data != nil {
+				events = append(events, current)
+			}
+			current = Event{}
+		case strings.HasPrefix(line, "id: "):
+			current.ID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			current.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.Data = append(current.Data, strings.TrimPrefix(line, "data: ")...)
+		}
+	}
+	if current.Event != "" || current.Data != nil {
+		events = append(events, current)
+	}
+	return events, scanner.Err()
+}
+
+// do 构造请求并交给服务器处理
+func (s *Server) do(method string, params interface{}, stream bool) (*httptest.ResponseRecorder, error) {
+	s.mu.Lock()
+	s.nextID++
+	request := map[string]interface{}{"jsonrpc": "2.0", "id": s.nextID, "method": method}
+	header := s.headers.Clone()
+	session := s.session
+	s.mu.Unlock()
+
+	if params != nil {
+		request["params"] = params
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body))
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+	if session != "" {
+		req.Header.Set(mcp.SessionIDHeader, session)
+	}
+
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec, nil
+}
+
+// toolParams 构造 tools/call 的参数
+func toolParams(name string, arguments interface{}) map[string]interface{} {
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	return map[string]interface{}{"name": name, "arguments": arguments}
+}